@@ -0,0 +1,139 @@
+package imagemeta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Result is the parsed metadata of a file from ParseFiles.
+//
+// Exif is nil if the file has no Exif. Xmp is empty if the file has no XMP.
+// Err is the error from opening or parsing the file.
+type Result struct {
+	Path       string
+	ImageType  imagetype.ImageType
+	Dimensions meta.Dimensions
+	Exif       exif.Exif
+	Xmp        xmp.XMP
+	Err        error
+}
+
+// ParseFiles parses the metadata of paths with at most workers files open
+// at the same time, and sends a Result for each file on the returned channel.
+// Results are not in the order of paths. A file that can not be parsed returns
+// a Result with Err and does not stop the batch.
+//
+// The channel is closed after all files have been parsed or ctx is cancelled.
+// Files that have not been started when ctx is cancelled are skipped.
+func ParseFiles(ctx context.Context, paths []string, workers int) <-chan Result {
+	return processFiles(ctx, paths, workers, parseFile)
+}
+
+// processFiles runs fn for each of paths with at most workers running at the
+// same time, and sends the Results on the returned channel.
+func processFiles(ctx context.Context, paths []string, workers int, fn func(path string) Result) <-chan Result {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make(chan Result, workers)
+	jobs := make(chan string)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for path := range jobs {
+				select {
+				case results <- fn(path):
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, path := range paths {
+			select {
+			case jobs <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// parseFile opens, parses and closes the file at path.
+func parseFile(path string) (res Result) {
+	res.Path = path
+	defer func() {
+		if state := recover(); state != nil {
+			res.Err = fmt.Errorf("error parsing %s: %v", path, state)
+		}
+	}()
+
+	f, err := os.Open(path)
+	if err != nil {
+		res.Err = err
+		return
+	}
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil && !errors.Is(err, ErrNoExif) {
+		res.Err = err
+		return
+	}
+	if m == nil {
+		res.Err = ErrMetadataNotSupported
+		return
+	}
+	res.ImageType = m.ImageType()
+	res.Dimensions = m.Dimensions()
+
+	if err == nil {
+		if res.Exif, err = m.Exif(); err != nil {
+			res.Exif = nil
+			if !errors.Is(err, ErrNoExif) {
+				res.Err = err
+				return
+			}
+		}
+	}
+	if res.Xmp, err = m.Xmp(); err != nil {
+		res.Xmp = xmp.XMP{}
+		if !errors.Is(err, xmp.ErrNoXMP) && err != io.EOF {
+			res.Err = err
+		}
+	}
+	return
+}
+
+// rewriteFile replaces the file at path with the file written by fn
+// with meta.SafeWrite. fn reads the file at path from r.
+func rewriteFile(path string, fn func(r meta.Reader, w io.Writer) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return meta.SafeWrite(path, func(w io.Writer) error {
+		return fn(f, w)
+	}, meta.SafeWriteOptions{})
+}
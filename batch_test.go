@@ -0,0 +1,68 @@
+package imagemeta
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+)
+
+func TestParseFiles(t *testing.T) {
+	paths := []string{"assets/a1.jpg", "assets/a2.jpg", "assets/JPEG.jpg", "testImages/CR2.exif", "testImages/GIF.gif", "testImages/CRW.CRW", "assets/missing.jpg"}
+
+	results := make(map[string]Result)
+	for res := range ParseFiles(context.Background(), paths, 2) {
+		if _, ok := results[res.Path]; ok {
+			t.Errorf("ParseFiles returned %s more than once", res.Path)
+		}
+		results[res.Path] = res
+	}
+	if len(results) != len(paths) {
+		t.Fatalf("Incorrect number of results wanted %d got %d", len(paths), len(results))
+	}
+
+	for _, path := range []string{"assets/a1.jpg", "assets/JPEG.jpg", "testImages/CR2.exif"} {
+		res := results[path]
+		if res.Err != nil {
+			t.Errorf("%s: unexpected error %v", path, res.Err)
+		}
+		if res.Exif == nil {
+			t.Errorf("%s: Exif should not be nil", path)
+		}
+	}
+	if res := results["testImages/CR2.exif"]; res.ImageType != imagetype.ImageCR2 {
+		t.Errorf("Incorrect ImageType wanted %s got %s", imagetype.ImageCR2, res.ImageType)
+	}
+	// a2.jpg has no Exif which is not an error
+	if res := results["assets/a2.jpg"]; res.Err != nil || res.Exif != nil {
+		t.Errorf("assets/a2.jpg: wanted no Exif and no error got %v", res.Err)
+	}
+	// GIF images have no Exif
+	if res := results["testImages/GIF.gif"]; res.Err != nil || res.Exif != nil || res.ImageType != imagetype.ImageGIF {
+		t.Errorf("testImages/GIF.gif: wanted no Exif and no error got %v", res.Err)
+	}
+	if res := results["testImages/CRW.CRW"]; res.Err != ErrMetadataNotSupported {
+		t.Errorf("Incorrect error wanted %v got %v", ErrMetadataNotSupported, res.Err)
+	}
+	if res := results["assets/missing.jpg"]; !os.IsNotExist(res.Err) {
+		t.Errorf("Incorrect error wanted %v got %v", os.ErrNotExist, res.Err)
+	}
+}
+
+func TestParseFilesCancel(t *testing.T) {
+	paths := make([]string, 100)
+	for i := range paths {
+		paths[i] = "assets/a1.jpg"
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count := 0
+	for range ParseFiles(ctx, paths, 4) {
+		count++
+	}
+	if count == len(paths) {
+		t.Errorf("ParseFiles should stop when the context is cancelled")
+	}
+}
@@ -82,7 +82,8 @@ func (r *Reader) ReadFtypBox() (FileTypeBox, error) {
 	return ftyp, err
 }
 
-// ReadMetaBox reads a 'meta' box from a BMFF file.
+// ReadMetaBox reads a 'meta' box from a BMFF file. Boxes before
+// the 'meta' box, such as the 'mdat' box of some AVIF files, are skipped.
 //
 // This should be called in order. First call ReadFtypBox
 func (r *Reader) ReadMetaBox() (mb MetaBox, err error) {
@@ -92,12 +93,19 @@ func (r *Reader) ReadMetaBox() (mb MetaBox, err error) {
 	if r.noMoreBoxes {
 		return mb, ErrNoMoreBoxes
 	}
-	b, err := r.readBox()
-	if err != nil {
-		err = errors.Wrapf(err, "ReadMetaBox")
-		return mb, err
+	for {
+		b, err := r.readBox()
+		if err != nil {
+			err = errors.Wrapf(err, "ReadMetaBox")
+			return mb, err
+		}
+		if b.boxType == TypeMeta {
+			return parseMetaBox(&b)
+		}
+		if err = b.discard(b.remain); err != nil {
+			return mb, errors.Wrapf(err, "ReadMetaBox")
+		}
 	}
-	return parseMetaBox(&b)
 }
 
 // ReadMoovBox reads a 'moov' box from a BMFF file.
@@ -148,6 +148,216 @@ func TestParseHandler(t *testing.T) {
 	}
 }
 
+func TestParseItemTypeReferenceBox(t *testing.T) {
+	// iref version 0: Exif item 50 describes the primary item 49,
+	// item 52 is an auxiliary image of 49.
+	data := []byte{0, 0, 0, 40, 'i', 'r', 'e', 'f', 0, 0, 0, 0,
+		0, 0, 0, 14, 'c', 'd', 's', 'c', 0, 50, 0, 1, 0, 49,
+		0, 0, 0, 14, 'a', 'u', 'x', 'l', 0, 52, 0, 1, 0, 49}
+	outer := newTestBox(data)
+	inner, err := outer.readInnerBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iref, err := inner.parseItemTypeReferenceBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ItemReference{
+		{Type: TypeCdsc, FromID: 50, ToIDs: []uint16{49}},
+		{Type: TypeAuxl, FromID: 52, ToIDs: []uint16{49}},
+	}, iref.References)
+	assert.Equal(t, []uint16{50}, iref.ReferencesTo(TypeCdsc, 49))
+	assert.Empty(t, iref.ReferencesTo(TypeThmb, 49))
+
+	// iref version 1 has 32 bit item IDs
+	data = []byte{0, 0, 0, 30, 'i', 'r', 'e', 'f', 1, 0, 0, 0,
+		0, 0, 0, 18, 'c', 'd', 's', 'c', 0, 0, 0, 7, 0, 1, 0, 0, 0, 1}
+	outer = newTestBox(data)
+	if inner, err = outer.readInnerBox(); err != nil {
+		t.Fatal(err)
+	}
+	if iref, err = inner.parseItemTypeReferenceBox(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint16{7}, iref.ReferencesTo(TypeCdsc, 1))
+
+	// Item IDs larger than 16 bits are skipped
+	data = []byte{0, 0, 0, 48, 'i', 'r', 'e', 'f', 1, 0, 0, 0,
+		0, 0, 0, 18, 'c', 'd', 's', 'c', 0, 1, 0, 7, 0, 1, 0, 0, 0, 1,
+		0, 0, 0, 18, 'c', 'd', 's', 'c', 0, 0, 0, 8, 0, 1, 0, 0, 0, 1}
+	outer = newTestBox(data)
+	if inner, err = outer.readInnerBox(); err != nil {
+		t.Fatal(err)
+	}
+	if iref, err = inner.parseItemTypeReferenceBox(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint16{8}, iref.ReferencesTo(TypeCdsc, 1))
+
+	// Truncated references are skipped
+	data = []byte{0, 0, 0, 38, 'i', 'r', 'e', 'f', 0, 0, 0, 0,
+		0, 0, 0, 12, 'c', 'd', 's', 'c', 0, 50, 0, 1,
+		0, 0, 0, 14, 't', 'h', 'm', 'b', 0, 51, 0, 1, 0, 49}
+	outer = newTestBox(data)
+	if inner, err = outer.readInnerBox(); err != nil {
+		t.Fatal(err)
+	}
+	if iref, err = inner.parseItemTypeReferenceBox(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []ItemReference{{Type: TypeThmb, FromID: 51, ToIDs: []uint16{49}}}, iref.References)
+
+	v1 := newTestBox([]byte{0, 1, 0, 0})
+	_, err = v1.readItemID(1)
+	assert.ErrorIs(t, err, ErrItemIDTooLarge)
+}
+
+func TestParseImageTransforms(t *testing.T) {
+	for _, v := range []struct {
+		data     []byte
+		expected Box
+	}{
+		{[]byte{0, 0, 0, 9, 'i', 'r', 'o', 't', 1}, ImageRotation(1)},
+		{[]byte{0, 0, 0, 9, 'i', 'r', 'o', 't', 7}, ImageRotation(3)},
+		{[]byte{0, 0, 0, 9, 'i', 'm', 'i', 'r', 0}, MirrorVerticalAxis},
+		{[]byte{0, 0, 0, 9, 'i', 'm', 'i', 'r', 1}, MirrorHorizontalAxis},
+	} {
+		outer := newTestBox(v.data)
+		inner, err := outer.readInnerBox()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := inner.Parse()
+		if assert.NoError(t, err) {
+			assert.Equal(t, v.expected, b)
+			assert.Equal(t, v.expected.Type(), b.Type())
+		}
+	}
+	assert.NotEqual(t, MirrorVerticalAxis.String(), MirrorHorizontalAxis.String())
+}
+
+func TestParseMetaReferences(t *testing.T) {
+	f, err := os.Open("samples/iPhone12.sample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	bmr := NewReader(f)
+	if _, err = bmr.ReadFtypBox(); err != nil {
+		t.Fatal(err)
+	}
+	mb, err := bmr.ReadMetaBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Exif and XMP of the primary item, XMP of the auxiliary image
+	assert.Equal(t, []uint16{50, 51}, mb.References.ReferencesTo(TypeCdsc, mb.Primary.ItemID))
+	assert.Equal(t, []uint16{53}, mb.References.ReferencesTo(TypeCdsc, 52))
+	if infe, err := mb.ItemInfo.ItemByID(51); assert.NoError(t, err) {
+		assert.Equal(t, ItemTypeMime, infe.ItemType)
+		assert.Equal(t, "application/rdf+xml", infe.ContentType)
+	}
+}
+
+func TestParseColourAndHDRProperties(t *testing.T) {
+	alpha := append([]byte{0, 0, 0, 56, 'a', 'u', 'x', 'C', 0, 0, 0, 0}, "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha\x00"...)
+	for _, v := range []struct {
+		data     []byte
+		expected Box
+	}{
+		{[]byte{0, 0, 0, 19, 'c', 'o', 'l', 'r', 'n', 'c', 'l', 'x', 0, 9, 0, 16, 0, 9, 0x80},
+			ColourInformationBox{ColourType: ColourTypeNclx, ColourPrimaries: 9, TransferCharacteristics: 16, MatrixCoefficients: 9, FullRange: true}},
+		{[]byte{0, 0, 0, 16, 'c', 'o', 'l', 'r', 'p', 'r', 'o', 'f', 0, 0, 0, 0}, ColourInformationBox{ColourType: ColourTypeProf}},
+		{[]byte{0, 0, 0, 16, 'p', 'i', 'x', 'i', 0, 0, 0, 0, 3, 10, 10, 10}, PixelInformationProperty{BitsPerChannel: []uint8{10, 10, 10}}},
+		{alpha, AuxiliaryTypeProperty{AuxType: "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha"}},
+		{[]byte{0, 0, 0, 12, 'c', 'l', 'l', 'i', 0x03, 0xe8, 0, 200}, ContentLightLevelBox{MaxContentLightLevel: 1000, MaxPicAverageLightLevel: 200}},
+		{[]byte{0, 0, 0, 32, 'm', 'd', 'c', 'v', 0x21, 0x34, 0x9b, 0xaa, 0x19, 0x64, 0x0b, 0xb8, 0x84, 0xd0, 0x3e, 0x80, 0x3d, 0x13, 0x40, 0x42, 0, 0x98, 0x96, 0x80, 0, 0, 0, 0x32},
+			MasteringDisplayColourVolumeBox{DisplayPrimaries: [3][2]uint16{{8500, 39850}, {6500, 3000}, {34000, 16000}}, WhitePoint: [2]uint16{15635, 16450}, MaxLuminance: 10000000, MinLuminance: 50}},
+	} {
+		outer := newTestBox(v.data)
+		inner, err := outer.readInnerBox()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := inner.Parse()
+		if assert.NoError(t, err) {
+			assert.Equal(t, v.expected, b)
+			assert.Equal(t, v.expected.Type(), b.Type())
+		}
+	}
+	assert.True(t, ColourInformationBox{ColourType: ColourTypeNclx, TransferCharacteristics: TransferCharacteristicsHLG}.HDR())
+	assert.False(t, ColourInformationBox{ColourType: ColourTypeNclx, TransferCharacteristics: 13}.HDR())
+	assert.True(t, AuxiliaryTypeProperty{AuxType: "urn:mpeg:hevc:2015:auxid:1"}.IsAlpha())
+	assert.False(t, AuxiliaryTypeProperty{AuxType: "urn:com:apple:photo:2020:aux:hdrgainmap"}.IsAlpha())
+
+	// Truncated pixi
+	outer := newTestBox([]byte{0, 0, 0, 14, 'p', 'i', 'x', 'i', 0, 0, 0, 0, 3, 10})
+	inner, err := outer.readInnerBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = inner.Parse()
+	assert.Error(t, err)
+}
+
+func TestParseMalformedBoxes(t *testing.T) {
+	for _, v := range []struct {
+		name string
+		data []byte
+	}{
+		{"zero size box in meta", []byte{0, 0, 0, 24, 'm', 'e', 't', 'a', 0, 0, 0, 0, 0, 0, 0, 0, 'p', 'i', 't', 'm', 0, 0, 0, 0}},
+		{"zero size box in ipco", []byte{0, 0, 0, 20, 'i', 'p', 'c', 'o', 0, 0, 0, 0, 'i', 's', 'p', 'e', 0, 0, 0, 0}},
+		{"ipma count larger than box", []byte{0, 0, 0, 16, 'i', 'p', 'm', 'a', 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff}},
+		{"ipma count larger than box v1", []byte{0, 0, 0, 21, 'i', 'p', 'm', 'a', 1, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 1, 0}},
+	} {
+		outer := newTestBox(v.data)
+		inner, err := outer.readInnerBox()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = inner.Parse()
+		assert.ErrorIs(t, err, ErrBoxSize, v.name)
+	}
+
+	// ipma with one entry that fits the box
+	outer := newTestBox([]byte{0, 0, 0, 20, 'i', 'p', 'm', 'a', 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 1, 0x81})
+	inner, err := outer.readInnerBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ipma, err := inner.parseItemPropertyAssociation()
+	if assert.NoError(t, err) && assert.Len(t, ipma.Entries, 1) {
+		assert.Equal(t, uint32(1), ipma.Entries[0].ItemID)
+		assert.Equal(t, uint16(1), ipma.Entries[0].Associations[0])
+	}
+}
+
+func TestReadMetaBoxAfterMdat(t *testing.T) {
+	// The mdat box is before the meta box
+	f, err := os.Open("../testImages/AVIF.avif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	bmr := NewReader(f)
+	if _, err = bmr.ReadFtypBox(); err != nil {
+		t.Fatal(err)
+	}
+	mb, err := bmr.ReadMetaBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ispe ImageSpatialExtentsProperty
+	for _, b := range mb.Properties.PropertiesByID(mb.Primary.ItemID) {
+		if b.Type() == TypeIspe {
+			ispe = b.(ImageSpatialExtentsProperty)
+		}
+	}
+	assert.Equal(t, uint32(1280), ispe.W)
+	assert.Equal(t, uint32(720), ispe.H)
+}
+
 func TestItemType(t *testing.T) {
 	it := itemType([]byte("infe"))
 	if it.String() != "infe" {
@@ -19,6 +19,7 @@ const (
 	TypeCCTP            // 'CCTP'
 	TypeCdsc            // 'cdsc'
 	TypeClap            // 'clap'
+	TypeClli            // 'clli'
 	TypeCMT1            // 'CMT1'
 	TypeCMT2            // 'CMT2'
 	TypeCMT3            // 'CMT3'
@@ -41,6 +42,7 @@ const (
 	TypeHvcC            // 'hvcC'
 	TypeIdat            // 'idat'
 	TypeIinf            // 'iinf'
+	TypeIlst            // 'ilst'
 	TypeIloc            // 'iloc'
 	TypeImir            // 'imir'
 	TypeInfe            // 'infe'
@@ -51,14 +53,18 @@ const (
 	TypeIref            // 'iref'
 	TypeIrot            // 'irot'
 	TypeIspe            // 'ispe'
+	TypeKeys            // 'keys'
 	TypeLhvC            // 'lhvC'
 	TypeMdat            // 'mdat'
+	TypeMdcv            // 'mdcv'
 	TypeMdft            // 'mdft'
 	TypeMdhd            // 'mdhd'
 	TypeMdia            // 'mdia'
+	TypeMehd            // 'mehd'
 	TypeMeta            // 'meta'
 	TypeMinf            // 'minf'
 	TypeMoov            // 'moov'
+	TypeMvex            // 'mvex'
 	TypeMvhd            // 'mvhd'
 	TypeNmhd            // 'nmhd'
 	TypeOinf            // 'oinf'
@@ -76,6 +82,7 @@ const (
 	TypeTkhd            // 'tkhd'
 	TypeTols            // 'tols'
 	TypeTrak            // 'trak'
+	TypeUdta            // 'udta'
 	TypeUUID            // 'uuid'
 	TypeVmhd            // 'vmhd'
 )
@@ -90,6 +97,7 @@ var mapStringBoxType = map[string]BoxType{
 	"CCTP": TypeCCTP,
 	"cdsc": TypeCdsc,
 	"clap": TypeClap,
+	"clli": TypeClli,
 	"CMT1": TypeCMT1,
 	"CMT2": TypeCMT2,
 	"CMT3": TypeCMT3,
@@ -112,6 +120,7 @@ var mapStringBoxType = map[string]BoxType{
 	"hvcC": TypeHvcC,
 	"idat": TypeIdat,
 	"iinf": TypeIinf,
+	"ilst": TypeIlst,
 	"iloc": TypeIloc,
 	"imir": TypeImir,
 	"infe": TypeInfe,
@@ -122,14 +131,18 @@ var mapStringBoxType = map[string]BoxType{
 	"iref": TypeIref,
 	"irot": TypeIrot,
 	"ispe": TypeIspe,
+	"keys": TypeKeys,
 	"lhvC": TypeLhvC,
 	"mdat": TypeMdat,
+	"mdcv": TypeMdcv,
 	"mdft": TypeMdft,
 	"mdhd": TypeMdhd,
 	"mdia": TypeMdia,
+	"mehd": TypeMehd,
 	"meta": TypeMeta,
 	"minf": TypeMinf,
 	"moov": TypeMoov,
+	"mvex": TypeMvex,
 	"mvhd": TypeMvhd,
 	"nmhd": TypeNmhd,
 	"oinf": TypeOinf,
@@ -147,6 +160,7 @@ var mapStringBoxType = map[string]BoxType{
 	"tkhd": TypeTkhd,
 	"tols": TypeTols,
 	"trak": TypeTrak,
+	"udta": TypeUdta,
 	"uuid": TypeUUID,
 	"vmhd": TypeVmhd,
 }
@@ -161,6 +175,7 @@ var mapBoxTypeString = map[BoxType]string{
 	TypeCCTP: "CCTP",
 	TypeCdsc: "cdsc",
 	TypeClap: "clap",
+	TypeClli: "clli",
 	TypeCMT1: "CMT1",
 	TypeCMT2: "CMT2",
 	TypeCMT3: "CMT3",
@@ -183,6 +198,7 @@ var mapBoxTypeString = map[BoxType]string{
 	TypeHvcC: "hvcC",
 	TypeIdat: "idat",
 	TypeIinf: "iinf",
+	TypeIlst: "ilst",
 	TypeIloc: "iloc",
 	TypeImir: "imir",
 	TypeInfe: "infe",
@@ -193,14 +209,18 @@ var mapBoxTypeString = map[BoxType]string{
 	TypeIref: "iref",
 	TypeIrot: "irot",
 	TypeIspe: "ispe",
+	TypeKeys: "keys",
 	TypeLhvC: "lhvC",
 	TypeMdat: "mdat",
+	TypeMdcv: "mdcv",
 	TypeMdft: "mdft",
 	TypeMdhd: "mdhd",
 	TypeMdia: "mdia",
+	TypeMehd: "mehd",
 	TypeMeta: "meta",
 	TypeMinf: "minf",
 	TypeMoov: "moov",
+	TypeMvex: "mvex",
 	TypeMvhd: "mvhd",
 	TypeNmhd: "nmhd",
 	TypeOinf: "oinf",
@@ -218,6 +238,7 @@ var mapBoxTypeString = map[BoxType]string{
 	TypeTkhd: "tkhd",
 	TypeTols: "tols",
 	TypeTrak: "trak",
+	TypeUdta: "udta",
 	TypeUUID: "uuid",
 	TypeVmhd: "vmhd",
 }
@@ -320,6 +341,7 @@ func init() {
 		TypeIinf: parseIinf,
 		TypeInfe: parseInfe,
 		TypeIloc: parseIloc,
+		TypeImir: parseImir,
 		TypeIpco: parseIpco,
 		TypeIpma: parseIpma,
 		TypeIprp: parseIprp,
@@ -330,8 +352,11 @@ func init() {
 		TypeMoov: parseMoov,
 		TypePitm: parsePitm,
 		TypeHvcC: parseUnknownBox,
-		TypeColr: parseUnknownBox,
-		TypePixi: parseUnknownBox,
+		TypeAuxC: parseAuxC,
+		TypeClli: parseClli,
+		TypeColr: parseColr,
+		TypeMdcv: parseMdcv,
+		TypePixi: parsePixi,
 	}
 }
 
@@ -190,7 +190,8 @@ func (br *bufReader) readUUID() (u meta.UUID, err error) {
 	return u, br.discard(16)
 }
 
-// readBox reads the next Box
+// readBox reads the next Box. Returns ErrBoxSize if the size of
+// the Box is smaller than its header.
 func (br *bufReader) readInnerBox() (b box, err error) {
 	// set previous bufReader
 	b.bufReader = *br
@@ -217,6 +218,9 @@ func (br *bufReader) readInnerBox() (b box, err error) {
 			// than int64.
 			return b, errors.Wrapf(errLargeBox, "readBox '%s'", b.boxType)
 		}
+		if b.size < 16 {
+			return b, errors.Wrapf(ErrBoxSize, "readBox '%s'", b.boxType)
+		}
 		b.remain = int(b.size)
 		return b, b.discard(16)
 		//case 0:
@@ -224,6 +228,9 @@ func (br *bufReader) readInnerBox() (b box, err error) {
 		// r.noMoreBoxes = true
 		// TODO: error
 	}
+	if b.size < 8 {
+		return b, errors.Wrapf(ErrBoxSize, "readBox '%s'", b.boxType)
+	}
 	return b, b.discard(8)
 }
 
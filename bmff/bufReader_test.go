@@ -29,6 +29,9 @@ var testReadBoxData = []struct {
 	{"Large Box (64-bit) uint64", box{}, []byte{0, 0, 0, 1, 'm', 'e', 't', 'a', 255, 0, 0, 0, 0, 0, 240, 123}, errLargeBox, false},
 	{"Box too short", box{}, []byte{0, 0, 0, 120, 'm', 'e', 't'}, ErrBufLength, false},
 	{"Large Box too short", box{}, []byte{0, 0, 0, 1, 'm', 'e', 't', 'a'}, ErrBufLength, false},
+	{"Box size 0", box{}, []byte{0, 0, 0, 0, 'm', 'e', 't', 'a', 0, 0, 0, 0}, ErrBoxSize, false},
+	{"Box size smaller than header", box{}, []byte{0, 0, 0, 7, 'm', 'e', 't', 'a', 0, 0, 0, 0}, ErrBoxSize, false},
+	{"Large Box size smaller than header", box{}, []byte{0, 0, 0, 1, 'm', 'e', 't', 'a', 0, 0, 0, 0, 0, 0, 0, 8}, ErrBoxSize, false},
 }
 
 func TestBufReaderReadInnerBox(t *testing.T) {
@@ -3,6 +3,7 @@ package bmff
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/evanoberholster/imagemeta/exif/ifds"
@@ -13,6 +14,13 @@ import (
 
 const (
 	stsdHeaderSize = 16
+
+	// thmbHeaderSize is the size of the THMB box before the JPEG thumbnail.
+	thmbHeaderSize = 24
+
+	// prvwHeaderSize is the size of the uuid box with CR3PreviewDataUUID and
+	// the PRVW box before the JPEG preview.
+	prvwHeaderSize = 56
 )
 
 var (
@@ -35,29 +43,50 @@ var (
 	CR3CNOPUUID = meta.UUIDFromString("210f1687-9149-11e4-8111-00242131fce4")
 )
 
-// CrxMoovBox is a Canon Raw Moov Box
+// CrxMoovBox is a Canon Raw Moov Box.
+// Canon CR3 images and CRM raw movies share the same layout.
 type CrxMoovBox struct {
 	Meta CR3MetaBox
-	Trak [4]CR3Trak
+	Trak []CR3Trak
+}
+
+// IsMovie returns true when the moov box is from a Canon CRM raw movie.
+// A CRM movie has a sound track or a video track with more than one sample.
+func (cmb CrxMoovBox) IsMovie() bool {
+	for _, t := range cmb.Trak {
+		if t.Handler == handlerSoun || (t.Handler == handlerVide && t.SampleCount > 1) {
+			return true
+		}
+	}
+	return false
 }
 
 // CR3MetaBox is a uuidBox that contains Metadata for CR3 files
 type CR3MetaBox struct {
 	//CCTP CCTPBox
-	//THMB THMBBox
+	THMB CR3Preview
 	CNCV CNCVBox
 	CTBO CTBOBox
-	Exif [4]meta.ExifHeader
+	Exif []meta.ExifHeader
 }
 
 // CR3Trak is a Canon CR3 Trak box
 type CR3Trak struct {
+	Handler           HandlerType
 	Width, Height     uint16
 	Depth, ImageType  uint16
 	ImageSize, Offset uint32
+	SampleCount       uint32
+}
+
+// CR3Preview is a JPEG preview of a CR3 file from a THMB or PRVW box.
+// Offset is from the start of the file and Length is the size of the JPEG image.
+type CR3Preview struct {
+	Width, Height  uint16
+	Offset, Length uint32
 }
 
-// ReadCrxMoovBox is a performance focused method for parsing the moov box from a .CR3 file.
+// ReadCrxMoovBox is a performance focused method for parsing the moov box from a .CR3 or .CRM file.
 func (r *Reader) ReadCrxMoovBox() (cmb CrxMoovBox, err error) {
 	// Parse Moov Box
 	moovBox, err := r.readBox()
@@ -71,11 +100,13 @@ func (r *Reader) ReadCrxMoovBox() (cmb CrxMoovBox, err error) {
 		tracebox(moovBox)
 	}
 	var inner box
-	i := 0
 	for moovBox.anyRemain() {
 		if inner, err = moovBox.readInnerBox(); err != nil {
 			return cmb, errors.Wrapf(err, "Box 'moov' %s (readBox)", inner.boxType)
 		}
+		if inner.size < 8 {
+			return cmb, ErrBoxSize
+		}
 		switch inner.boxType {
 		case TypeUUID:
 			uuid, err := inner.readUUID()
@@ -88,11 +119,11 @@ func (r *Reader) ReadCrxMoovBox() (cmb CrxMoovBox, err error) {
 				}
 			}
 		case TypeTrak:
-			cmb.Trak[i], err = ParseCrxTrak(&inner)
-			if err != nil {
+			var t CR3Trak
+			if t, err = ParseCrxTrak(&inner); err != nil {
 				return
 			}
-			i++
+			cmb.Trak = append(cmb.Trak, t)
 			if debugFlag {
 				tracebox(inner)
 			}
@@ -110,60 +141,97 @@ func (r *Reader) ReadCrxMoovBox() (cmb CrxMoovBox, err error) {
 	return
 }
 
+// ParseCrxTrak parses a 'trak' box from a CR3 or CRM file.
+// The sample tables of a CRM movie can be larger than the buffer,
+// so only the beginning of the 'stsd', 'stsz' and 'co64' boxes is read.
 func ParseCrxTrak(trak *box) (t CR3Trak, err error) {
-	var bt BoxType
-	var size int
-	var buf []byte
-	if buf, err = trak.read(); err != nil {
-		return
-	}
+	err = trak.parseCrxTrak(&t)
+	return
+}
 
-	for remain := len(buf); remain > 0; remain -= size {
-		size = int(binary.BigEndian.Uint32(buf[:4]))
-		bt = boxType(buf[4:8])
-		switch bt {
+func (b *box) parseCrxTrak(t *CR3Trak) (err error) {
+	var inner box
+	var buf []byte
+	for b.anyRemain() {
+		if inner, err = b.readInnerBox(); err != nil {
+			return
+		}
+		if inner.size < 8 {
+			return ErrBoxSize
+		}
+		switch inner.boxType {
 		case TypeMdia, TypeMinf, TypeStbl: // open box
-			size = 8
+			if err = inner.parseCrxTrak(t); err != nil {
+				return
+			}
 		case TypeHdlr:
+			if buf, err = inner.peekCrx(12); err != nil {
+				return
+			}
+			if len(buf) == 12 {
+				t.Handler = handler(buf[8:12])
+			}
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: trak.offset}}, "hdlr | type: "+string(buf[16:20]))
+				traceBoxWithMsg(inner, "hdlr | type: "+t.Handler.String())
 			}
-			// skip any trak whose hdlr type is not "vide"
-			if string(buf[16:20]) != "vide" {
+		case TypeStsd:
+			if buf, err = inner.peekCrx(96); err != nil {
 				return
 			}
-		case TypeStsd:
-			if boxType(buf[4+stsdHeaderSize:8+stsdHeaderSize]) == TypeCRAW {
-				t.Width = crxBinaryOrder.Uint16(buf[32+stsdHeaderSize : 34+stsdHeaderSize])
-				t.Height = crxBinaryOrder.Uint16(buf[34+stsdHeaderSize : 36+stsdHeaderSize])
-				t.Depth = crxBinaryOrder.Uint16(buf[82+stsdHeaderSize : 84+stsdHeaderSize])
-				t.ImageType = crxBinaryOrder.Uint16(buf[86+stsdHeaderSize : 88+stsdHeaderSize])
+			// Only the first sample entry is read
+			if len(buf) == 96 && boxType(buf[12:16]) == TypeCRAW {
+				t.Width = crxBinaryOrder.Uint16(buf[40:42])
+				t.Height = crxBinaryOrder.Uint16(buf[42:44])
+				t.Depth = crxBinaryOrder.Uint16(buf[90:92])
+				t.ImageType = crxBinaryOrder.Uint16(buf[94:96])
 			}
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: trak.offset}}, fmt.Sprintf("stsd | width:%d, height:%d, depth:%d, imagetype:%d", t.Width, t.Height, t.Depth, t.ImageType))
+				traceBoxWithMsg(inner, fmt.Sprintf("stsd | width:%d, height:%d, depth:%d, imagetype:%d", t.Width, t.Height, t.Depth, t.ImageType))
 			}
 		case TypeStsz:
-			if size == 20 {
-				t.ImageSize = crxBinaryOrder.Uint32(buf[12+8+4 : 16+8+4])
-			} else if size == 24 {
-				t.ImageSize = crxBinaryOrder.Uint32(buf[12+8 : 16+8])
+			if buf, err = inner.peekCrx(16); err != nil {
+				return
+			}
+			if len(buf) >= 12 {
+				t.ImageSize = crxBinaryOrder.Uint32(buf[4:8])
+				t.SampleCount = crxBinaryOrder.Uint32(buf[8:12])
+				// A sample size of 0 is followed by the size of each sample
+				if t.ImageSize == 0 && len(buf) == 16 {
+					t.ImageSize = crxBinaryOrder.Uint32(buf[12:16])
+				}
 			}
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: trak.offset}}, fmt.Sprintf("stsz | imageSize:%d", t.ImageSize))
+				traceBoxWithMsg(inner, fmt.Sprintf("stsz | imageSize:%d, sampleCount:%d", t.ImageSize, t.SampleCount))
 			}
 		case TypeCo64:
-			t.Offset = crxBinaryOrder.Uint32(buf[12+8 : 16+8])
+			if buf, err = inner.peekCrx(16); err != nil {
+				return
+			}
+			if len(buf) == 16 {
+				t.Offset = uint32(crxBinaryOrder.Uint64(buf[8:16]))
+			}
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: trak.offset}}, fmt.Sprintf("co64 | imageOffset:%d", t.Offset))
+				traceBoxWithMsg(inner, fmt.Sprintf("co64 | imageOffset:%d", t.Offset))
 			}
 		default: // skip other types:
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: trak.offset}}, "discard")
+				traceBoxWithMsg(inner, "discard")
 			}
 		}
-		buf = buf[size:]
+		if err = b.closeInnerBox(&inner); err != nil {
+			return
+		}
 	}
-	return
+	return b.discard(b.remain)
+}
+
+// peekCrx peeks up to n bytes of the box. Fewer bytes are returned
+// when the box is smaller than n.
+func (b *box) peekCrx(n int) ([]byte, error) {
+	if b.remain < n {
+		n = b.remain
+	}
+	return b.peek(n)
 }
 
 // XPacketData returns CTBO[0] which corresponds to XPacket data
@@ -173,20 +241,69 @@ func (cr3 CR3MetaBox) XPacketData() (offset, length uint32, err error) {
 	return item.offset, item.length, nil
 }
 
+// PreviewData returns CTBO[1] which corresponds to the uuid box with the PRVW box.
+// First 24 bytes are a UUID box. uuid = eaf42b5e-1c98-4b88-b9fb-b7dc406e4d16
+func (cr3 CR3MetaBox) PreviewData() (offset, length uint32) {
+	item := cr3.CTBO.items[1]
+	return item.offset, item.length
+}
+
+// ReadCR3Preview reads the PRVW box of the uuid box with CR3PreviewDataUUID at
+// offset of r, which has length bytes.
+//
+// Returns ErrWrongBoxType if the box is not a PRVW box and ErrBoxSize if the
+// box is shorter than its header.
+func ReadCR3Preview(r io.ReaderAt, offset, length uint32) (p CR3Preview, err error) {
+	if length < prvwHeaderSize {
+		return p, ErrBoxSize
+	}
+	var buf [prvwHeaderSize]byte
+	if _, err = r.ReadAt(buf[:], int64(offset)); err != nil {
+		return p, err
+	}
+	// uuid box header, uuid and 8 unknown bytes, the PRVW box header,
+	// 6 unknown bytes, width, height, 2 unknown bytes and jpeg size
+	var uuid meta.UUID
+	if err = uuid.UnmarshalBinary(buf[8:24]); err != nil {
+		return p, err
+	}
+	if boxType(buf[4:8]) != TypeUUID || uuid != CR3PreviewDataUUID || boxType(buf[36:40]) != TypePRVW {
+		return p, ErrWrongBoxType
+	}
+	p = CR3Preview{
+		Width:  crxBinaryOrder.Uint16(buf[46:48]),
+		Height: crxBinaryOrder.Uint16(buf[48:50]),
+		Offset: offset + prvwHeaderSize,
+		Length: crxBinaryOrder.Uint32(buf[52:56]),
+	}
+	if p.Length > length-prvwHeaderSize {
+		p.Length = length - prvwHeaderSize
+	}
+	return p, nil
+}
+
 // parseCR3MetaBox parses a uuid box with the uuid of 85c0b687 820f 11e0 8111 f4ce462b6a48
+//
+// Each CMT box is added to the Exif headers in the order it is found.
+// Files with repeated or missing CMT boxes are accepted.
 func parseCR3MetaBox(outer *box) (m CR3MetaBox, err error) {
 	var size int
 	var bt BoxType
 	var buf []byte
-	for remain := outer.remain; remain > 8; remain -= size {
-		if buf, err = outer.peek(40); err != nil {
+	for outer.remain > 8 {
+		if buf, err = outer.peekCrx(40); err != nil {
 			return
 		}
 		size = int(binary.BigEndian.Uint32(buf[:4]))
+		if size < 8 || size > outer.remain {
+			return m, ErrBoxSize
+		}
 		bt = boxType(buf[4:8])
 		switch bt {
 		case TypeCNCV:
-			copy(m.CNCV.val[:], buf[8:38])
+			if len(buf) >= 38 {
+				copy(m.CNCV.val[:], buf[8:38])
+			}
 			if debugFlag {
 				traceBoxWithMsg(*outer, m.CNCV.String())
 			}
@@ -198,35 +315,62 @@ func parseCR3MetaBox(outer *box) (m CR3MetaBox, err error) {
 			if debugFlag {
 				traceBoxWithMsg(*outer, m.CTBO.String())
 			}
-		case TypeCMT1:
-			m.Exif[0], err = parseCMT(buf[8:16], ifds.IFD0, uint32(outer.offset+8), uint32(size-8))
-			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: outer.offset}}, "Exif Header: "+m.Exif[0].String())
+		case TypeTHMB:
+			// version and flags, width, height, jpeg size and 4 unknown bytes
+			if size < thmbHeaderSize || len(buf) < 20 {
+				break
 			}
-		case TypeCMT2:
-			m.Exif[1], err = parseCMT(buf[8:16], ifds.ExifIFD, uint32(outer.offset+8), uint32(size-8))
-			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: outer.offset}}, "Exif Header: "+m.Exif[1].String())
+			m.THMB = CR3Preview{
+				Width:  crxBinaryOrder.Uint16(buf[12:14]),
+				Height: crxBinaryOrder.Uint16(buf[14:16]),
+				Offset: uint32(outer.offset + thmbHeaderSize),
+				Length: crxBinaryOrder.Uint32(buf[16:20]),
+			}
+			if m.THMB.Length > uint32(size-thmbHeaderSize) {
+				m.THMB.Length = uint32(size - thmbHeaderSize)
 			}
-		case TypeCMT3:
-			m.Exif[2], err = parseCMT(buf[8:16], ifds.MknoteIFD, uint32(outer.offset+8), uint32(size-8))
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: outer.offset}}, "Exif Header: "+m.Exif[2].String())
+				traceBoxWithMsg(*outer, fmt.Sprintf("THMB | width:%d, height:%d, size:%d", m.THMB.Width, m.THMB.Height, m.THMB.Length))
+			}
+		case TypeCMT1, TypeCMT2, TypeCMT3, TypeCMT4:
+			if size < 16 {
+				break
+			}
+			var header meta.ExifHeader
+			if header, err = parseCMT(buf[8:16], cmtIfd(bt), uint32(outer.offset+8), uint32(size-8)); err != nil {
+				return
+			}
+			// CMT boxes without a valid tiff header are skipped
+			if header.IsValid() {
+				m.Exif = append(m.Exif, header)
 			}
-		case TypeCMT4:
-			m.Exif[3], err = parseCMT(buf[8:16], ifds.GPSIFD, uint32(outer.offset+8), uint32(size-8))
 			if debugFlag {
-				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: outer.offset}}, "Exif Header: "+m.Exif[3].String())
+				traceBoxWithMsg(box{size: int64(size), boxType: bt, bufReader: bufReader{offset: outer.offset}}, "Exif Header: "+header.String())
 			}
 		}
 		if err != nil {
 			return
 		}
-		err = outer.discard(size)
+		if err = outer.discard(size); err != nil {
+			return
+		}
 	}
 	return
 }
 
+// cmtIfd returns the IFD that is found in a CMT box.
+func cmtIfd(bt BoxType) ifds.IfdType {
+	switch bt {
+	case TypeCMT2:
+		return ifds.ExifIFD
+	case TypeCMT3:
+		return ifds.MknoteIFD
+	case TypeCMT4:
+		return ifds.GPSIFD
+	}
+	return ifds.IFD0
+}
+
 // CNCVBox is Canon Compressor Version box
 // CaNon Codec Version?
 type CNCVBox struct {
@@ -266,6 +410,9 @@ type IndexOffset struct {
 }
 
 func parseCTBO(buf []byte) (ctbo CTBOBox, err error) {
+	if len(buf) < 4 {
+		return ctbo, ErrBoxSize
+	}
 	// Item Count
 	ctbo.count = crxBinaryOrder.Uint32(buf[0:4])
 
@@ -284,6 +431,9 @@ func parseCTBO(buf []byte) (ctbo CTBOBox, err error) {
 
 func parseCMT(buf []byte, ifd ifds.IfdType, offset uint32, size uint32) (meta.ExifHeader, error) {
 	binaryOrder := meta.BinaryOrder(buf[:4])
+	if binaryOrder == nil {
+		return meta.ExifHeader{}, nil
+	}
 	header := meta.NewExifHeader(binaryOrder, binaryOrder.Uint32(buf[4:8]), offset, size, imagetype.ImageCR3)
 	header.FirstIfd = ifd
 	return header, nil
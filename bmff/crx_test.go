@@ -2,11 +2,98 @@ package bmff
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io/ioutil"
 	"os"
 	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
 )
 
+func TestReadCrxMoovBox(t *testing.T) {
+	newBox := func(bt string, data ...[]byte) []byte {
+		buf := make([]byte, 8)
+		copy(buf[4:], bt)
+		for _, d := range data {
+			buf = append(buf, d...)
+		}
+		binary.BigEndian.PutUint32(buf, uint32(len(buf)))
+		return buf
+	}
+	u32 := func(v ...uint32) []byte {
+		buf := make([]byte, 4*len(v))
+		for i := range v {
+			binary.BigEndian.PutUint32(buf[i*4:], v[i])
+		}
+		return buf
+	}
+	tiff := []byte{'I', 'I', 0x2A, 0, 8, 0, 0, 0}
+	uuid, _ := CR3MetaBoxUUID.MarshalBinary()
+	metaBox := newBox("uuid", uuid,
+		newBox("CMT1", tiff),
+		newBox("CMT2", tiff),
+		newBox("CMT2", tiff),
+		newBox("CMT4", make([]byte, 8)), // no tiff header
+		newBox("free"))
+
+	stsd := make([]byte, 88)
+	copy(stsd[4:], "CRAW")
+	binary.BigEndian.PutUint16(stsd[32:], 6000)
+	binary.BigEndian.PutUint16(stsd[34:], 4000)
+	binary.BigEndian.PutUint16(stsd[82:], 14)
+	binary.BigEndian.PutUint16(stsd[86:], 3)
+	newTrak := func(hdlr string, stsz []byte) []byte {
+		return newBox("trak", newBox("mdia",
+			newBox("hdlr", u32(0, 0), []byte(hdlr), make([]byte, 12)),
+			newBox("minf", newBox("stbl",
+				newBox("stsd", u32(0, 1), stsd),
+				newBox("stsz", stsz),
+				newBox("co64", u32(0, 1, 0, 1024))))))
+	}
+	moov := newBox("moov", metaBox,
+		newTrak("vide", u32(0, 0, 1, 2048)),
+		newTrak("vide", u32(0, 0, 3, 100, 200, 300)),
+		newTrak("soun", u32(0, 4, 10)),
+		newTrak("meta", u32(0, 0, 3, 10, 10, 10)),
+		newTrak("meta", u32(0, 0, 1, 20)))
+
+	bmr := NewReader(bytes.NewReader(moov))
+	cmb, err := bmr.ReadCrxMoovBox()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmb.Meta.Exif) != 3 {
+		t.Fatalf("Incorrect number of CMT boxes wanted %d got %d", 3, len(cmb.Meta.Exif))
+	}
+	for i, ifd := range []ifds.IfdType{ifds.IFD0, ifds.ExifIFD, ifds.ExifIFD} {
+		if cmb.Meta.Exif[i].FirstIfd != ifd {
+			t.Errorf("Incorrect CMT %d Ifd wanted %s got %s", i, ifd, cmb.Meta.Exif[i].FirstIfd)
+		}
+	}
+	want := []CR3Trak{
+		{Handler: handlerVide, Width: 6000, Height: 4000, Depth: 14, ImageType: 3, ImageSize: 2048, Offset: 1024, SampleCount: 1},
+		{Handler: handlerVide, Width: 6000, Height: 4000, Depth: 14, ImageType: 3, ImageSize: 100, Offset: 1024, SampleCount: 3},
+		{Handler: handlerSoun, Width: 6000, Height: 4000, Depth: 14, ImageType: 3, ImageSize: 4, Offset: 1024, SampleCount: 10},
+		{Handler: handlerMeta, Width: 6000, Height: 4000, Depth: 14, ImageType: 3, ImageSize: 10, Offset: 1024, SampleCount: 3},
+		{Handler: handlerMeta, Width: 6000, Height: 4000, Depth: 14, ImageType: 3, ImageSize: 20, Offset: 1024, SampleCount: 1},
+	}
+	if len(cmb.Trak) != len(want) {
+		t.Fatalf("Incorrect number of traks wanted %d got %d", len(want), len(cmb.Trak))
+	}
+	for i := range want {
+		if cmb.Trak[i] != want[i] {
+			t.Errorf("Incorrect trak %d wanted %+v got %+v", i, want[i], cmb.Trak[i])
+		}
+	}
+	if !cmb.IsMovie() {
+		t.Errorf("Incorrect IsMovie wanted %t got %t", true, false)
+	}
+	cmb.Trak = cmb.Trak[:1]
+	if cmb.IsMovie() {
+		t.Errorf("Incorrect IsMovie wanted %t got %t", false, true)
+	}
+}
+
 // BenchmarkCrx10-12    	  345901	      4140 ns/op	    4592 B/op	       2 allocs/op
 func BenchmarkCrx10(b *testing.B) {
 	f, err := os.Open("../../test/samples/CanonR6_1.CR3")
@@ -41,3 +128,33 @@ func BenchmarkCrx10(b *testing.B) {
 	}
 
 }
+
+func TestReadCR3Preview(t *testing.T) {
+	uuid, _ := CR3PreviewDataUUID.MarshalBinary()
+	buf := make([]byte, prvwHeaderSize, prvwHeaderSize+4)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(cap(buf)))
+	copy(buf[4:8], "uuid")
+	copy(buf[8:24], uuid)
+	binary.BigEndian.PutUint32(buf[32:36], uint32(cap(buf)-32))
+	copy(buf[36:40], "PRVW")
+	binary.BigEndian.PutUint16(buf[46:48], 1620)
+	binary.BigEndian.PutUint16(buf[48:50], 1080)
+	binary.BigEndian.PutUint32(buf[52:56], 8)
+	buf = append(buf, 0xFF, 0xD8, 0xFF, 0xD9)
+
+	p, err := ReadCR3Preview(bytes.NewReader(buf), 0, uint32(len(buf)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The jpeg size is limited to the box
+	if want := (CR3Preview{Width: 1620, Height: 1080, Offset: prvwHeaderSize, Length: 4}); p != want {
+		t.Errorf("Incorrect CR3Preview wanted %v got %v", want, p)
+	}
+	if _, err = ReadCR3Preview(bytes.NewReader(buf), 0, prvwHeaderSize-1); err != ErrBoxSize {
+		t.Errorf("Incorrect error wanted %v got %v", ErrBoxSize, err)
+	}
+	copy(buf[36:40], "THMB")
+	if _, err = ReadCR3Preview(bytes.NewReader(buf), 0, uint32(len(buf))); err != ErrWrongBoxType {
+		t.Errorf("Incorrect error wanted %v got %v", ErrWrongBoxType, err)
+	}
+}
@@ -13,6 +13,7 @@ const (
 	brandUnknown Brand = iota // unknown ISOBMFF brand
 	brandAvci                 // 'avci'
 	brandAvif                 // 'avif': AVIF
+	brandAvis                 // 'avis': AVIF image sequence
 	brandCrx                  // 'crx ' : Canon CR3
 	brandHeic                 // 'heic': the usual HEIF images
 	brandHeim                 // 'heim': multiview
@@ -39,12 +40,14 @@ const (
 	brandMp41                 // 'mp41'
 	brandMp42                 // 'mp42'
 	brandMsf1                 // 'msf1': sequence
+	brandQt                   // 'qt  ': QuickTime movie
 )
 
 var (
 	mapStringBrand = map[string]Brand{
 		"avci": brandAvci,
 		"avif": brandAvif,
+		"avis": brandAvis,
 		"crx ": brandCrx,
 		"heic": brandHeic,
 		"heim": brandHeim,
@@ -71,11 +74,13 @@ var (
 		"mp41": brandMp41,
 		"mp42": brandMp42,
 		"msf1": brandMsf1,
+		"qt  ": brandQt,
 	}
 
 	mapBrandString = map[Brand]string{
 		brandAvci: "avci",
 		brandAvif: "avif",
+		brandAvis: "avis",
 		brandCrx:  "crx ",
 		brandHeic: "heic",
 		brandHeim: "heim",
@@ -102,6 +107,7 @@ var (
 		brandMp41: "mp41",
 		brandMp42: "mp42",
 		brandMsf1: "msf1",
+		brandQt:   "qt  ",
 	}
 )
 
@@ -138,6 +144,11 @@ func (ftyp FileTypeBox) IsCR3() bool {
 	return ftyp.MajorBrand == brandCrx
 }
 
+// IsMOV returns true if major brand is qt (QuickTime movie)
+func (ftyp FileTypeBox) IsMOV() bool {
+	return ftyp.MajorBrand == brandQt
+}
+
 func (b *box) parseFileTypeBox() (ftyp FileTypeBox, err error) {
 	if b.boxType != TypeFtyp {
 		return ftyp, ErrWrongBoxType
@@ -2,6 +2,7 @@ package bmff
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/pkg/errors"
 )
@@ -14,6 +15,7 @@ const (
 	handlerPict
 	handlerVide
 	handlerMeta
+	handlerSoun
 )
 
 func (ht HandlerType) String() string {
@@ -24,6 +26,8 @@ func (ht HandlerType) String() string {
 		return "vide"
 	case handlerMeta:
 		return "meta"
+	case handlerSoun:
+		return "soun"
 	default:
 		return "nnnn"
 	}
@@ -39,6 +43,9 @@ func handler(buf []byte) HandlerType {
 	if isHandler(buf, "meta") {
 		return handlerMeta
 	}
+	if isHandler(buf, "soun") {
+		return handlerSoun
+	}
 	return handlerUnknown
 }
 
@@ -90,8 +97,9 @@ func (b *box) parseHandlerBox() (hdlr HandlerBox, err error) {
 // Item references are extensively used by HEIF. For instance, thumbnail images are recognized from a thumbnail
 // type reference which links from the thumbnail image to the master image.
 type ItemTypeReferenceBox struct {
-	Flags Flags
-	size  uint32
+	Flags      Flags
+	size       uint32
+	References []ItemReference
 }
 
 // Type returns TypeIref.
@@ -99,6 +107,40 @@ func (iref ItemTypeReferenceBox) Type() BoxType {
 	return TypeIref
 }
 
+// ReferencesTo returns the IDs of the items with a reference of refType to the item id.
+// For example the Exif and XMP items have a TypeCdsc reference to the primary item.
+func (iref ItemTypeReferenceBox) ReferencesTo(refType BoxType, id uint16) (ids []uint16) {
+	for _, ref := range iref.References {
+		if ref.Type != refType {
+			continue
+		}
+		for _, toID := range ref.ToIDs {
+			if toID == id {
+				ids = append(ids, ref.FromID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// ItemReference is a reference from the item FromID to the items ToIDs in an "iref" box.
+//
+// Type is the reference type:
+// dimg -> derived image
+// thmb -> thumbnail
+// cdsc -> content description (Exif and XMP)
+// auxl -> auxiliary image
+type ItemReference struct {
+	ToIDs  []uint16
+	FromID uint16
+	Type   BoxType
+}
+
+func (ref ItemReference) String() string {
+	return fmt.Sprintf("(%s) FromID:%d, ToIDs:%v", ref.Type, ref.FromID, ref.ToIDs)
+}
+
 func parseIref(outer *box) (Box, error) {
 	return outer.parseItemTypeReferenceBox()
 }
@@ -110,16 +152,25 @@ func (b *box) parseItemTypeReferenceBox() (iref ItemTypeReferenceBox, err error)
 		return
 	}
 	var inner box
+	var ref ItemReference
 	for b.anyRemain() {
 		if inner, err = b.readInnerBox(); err != nil {
-			// TODO: write error
+			if debugFlag {
+				log.Debug("(iref) error reading reference: %s", err.Error())
+			}
 			break
 		}
-		// dimg -> derived image
-		// thmb -> thumbnail
-		// cdsc -> context description ref / exif
-		//fmt.Println(inner, outer.r.remain)
-
+		// Malformed references are skipped
+		if ref, err = inner.parseItemReference(iref.Flags.Version()); err != nil {
+			if debugFlag {
+				log.Debug("(iref) error parsing reference %s: %s", inner.Type(), err.Error())
+			}
+		} else {
+			iref.References = append(iref.References, ref)
+			if debugFlag {
+				log.Debug("%s", ref)
+			}
+		}
 		if err = b.closeInnerBox(&inner); err != nil {
 			break
 		}
@@ -127,6 +178,44 @@ func (b *box) parseItemTypeReferenceBox() (iref ItemTypeReferenceBox, err error)
 	return iref, b.discard(b.remain)
 }
 
+// parseItemReference parses a SingleItemTypeReferenceBox. Item IDs are
+// 16 bits for version 0 of the "iref" box and 32 bits for version 1.
+func (b *box) parseItemReference(version uint8) (ref ItemReference, err error) {
+	ref.Type = b.boxType
+	if ref.FromID, err = b.readItemID(version); err != nil {
+		return
+	}
+	count, err := b.readUint16()
+	if err != nil {
+		return
+	}
+	ref.ToIDs = make([]uint16, 0, count)
+	for i := 0; i < int(count); i++ {
+		var id uint16
+		if id, err = b.readItemID(version); err != nil {
+			return
+		}
+		ref.ToIDs = append(ref.ToIDs, id)
+	}
+	return ref, nil
+}
+
+// readItemID reads a 16 bit item ID, or a 32 bit item ID when version is 1.
+// Returns ErrItemIDTooLarge for 32 bit item IDs larger than 0xFFFF.
+func (b *box) readItemID(version uint8) (uint16, error) {
+	if version == 0 {
+		return b.readUint16()
+	}
+	id, err := b.readUint32()
+	if err != nil {
+		return 0, err
+	}
+	if id > math.MaxUint16 {
+		return 0, ErrItemIDTooLarge
+	}
+	return uint16(id), nil
+}
+
 // ImageRotation is an "irot" - image rotation property.
 // Represents the Image Rotation Angle at 90 degree intervals.
 type ImageRotation uint8
@@ -163,6 +252,38 @@ func (b *box) parseImageRotation() (ImageRotation, error) {
 	return ImageRotation(v & 3), err
 }
 
+// ImageMirror is an "imir" - image mirror property.
+// Represents the axis of the mirroring: 0 is a vertical axis (left-right flip)
+// and 1 is a horizontal axis (top-bottom flip).
+type ImageMirror uint8
+
+// Image mirror axes
+const (
+	MirrorVerticalAxis   ImageMirror = 0
+	MirrorHorizontalAxis ImageMirror = 1
+)
+
+// Type returns TypeImir
+func (imir ImageMirror) Type() BoxType {
+	return TypeImir
+}
+
+func (imir ImageMirror) String() string {
+	if imir == MirrorHorizontalAxis {
+		return "(imir) Horizontal Axis"
+	}
+	return "(imir) Vertical Axis"
+}
+
+func parseImir(outer *box) (Box, error) {
+	return outer.parseImageMirror()
+}
+
+func (b *box) parseImageMirror() (ImageMirror, error) {
+	v, err := b.readUint8()
+	return ImageMirror(v & 1), err
+}
+
 // PrimaryItemBox is a "pitm" box.
 //
 // Primary Item Reference pitm allows setting one image as the primary item.
@@ -1,6 +1,7 @@
 package bmff
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"strings"
@@ -17,6 +18,9 @@ var (
 
 	// ErrInfeVersionNotSupported is returned when an infe box with an unsupported was found.
 	ErrInfeVersionNotSupported = errors.New("infe box version not supported")
+
+	// ErrItemIDTooLarge is returned when an item ID is larger than 16 bits.
+	ErrItemIDTooLarge = errors.New("item ID larger than 0xFFFF not supported")
 )
 
 // ItemType is always 4 bytes
@@ -135,6 +139,10 @@ func (b *box) parseItemInfoBox() (iinf ItemInfoBox, err error) {
 	return iinf, b.discard(b.remain)
 }
 
+// maxInfeStringsLength is the largest item name and content type of
+// an ItemInfoEntry that is read.
+const maxInfeStringsLength = 512
+
 // ItemInfoEntry represents an "infe" box.
 //
 // TODO: currently only parses Version 2 boxes.
@@ -146,7 +154,8 @@ type ItemInfoEntry struct {
 	//Name string
 
 	// If Type == "mime":
-	//ContentType     string
+	// ContentType is "application/rdf+xml" for XMP.
+	ContentType string
 	//ContentEncoding string
 
 	// If Type == "uri ":
@@ -198,20 +207,31 @@ func (b *box) parseItemInfoEntry() (ie ItemInfoEntry, err error) {
 		return
 	}
 
-	//switch ie.ItemType {
-	//case ItemTypeMime:
-	//	_, _ = b.readString()
-	//	if b.anyRemain() {
-	//		_, _ = b.readString()
-	//	}
-	//ie.ContentType, _ = outer.r.readString()
-	//if outer.r.anyRemain() {
-	//	ie.ContentEncoding, _ = outer.r.readString()
-	//}
+	if ie.ItemType == ItemTypeMime && b.anyRemain() {
+		// item_name (when it is not empty), content_type and content_encoding
+		n := b.remain
+		if n > maxInfeStringsLength {
+			n = maxInfeStringsLength
+		}
+		if buf, err = b.peek(n); err != nil {
+			err = errors.Wrap(err, "ParseItemInfoEntry")
+			return
+		}
+		if infeHeaderSize == 12 {
+			if i := bytes.IndexByte(buf, 0); i >= 0 {
+				buf = buf[i+1:]
+			} else {
+				buf = nil
+			}
+		}
+		if i := bytes.IndexByte(buf, 0); i >= 0 {
+			buf = buf[:i]
+		}
+		ie.ContentType = string(buf)
+	}
 	//case ItemTypeURI:
 	//	_, _ = b.readString()
 	//ie.ItemURIType, _ = outer.r.readString()
-	//}
 	if debugFlag {
 		traceBoxWithFlags(ie, *b, flags)
 	}
@@ -372,6 +392,22 @@ func (iprp ItemPropertiesBox) String() string {
 	return fmt.Sprintf("iprp | Properties: %d, Associations: %d", len(iprp.PropertyContainer.Properties), len(iprp.Associations.Entries))
 }
 
+// PropertiesByID returns the properties associated with the item id
+// in the order of association.
+func (iprp ItemPropertiesBox) PropertiesByID(id uint16) (boxes []Box) {
+	for _, entry := range iprp.Associations.Entries {
+		if entry.ItemID != uint32(id) {
+			continue
+		}
+		for _, index := range entry.Associations {
+			if index > 0 && int(index) <= len(iprp.PropertyContainer.Properties) {
+				boxes = append(boxes, iprp.PropertyContainer.Properties[index-1])
+			}
+		}
+	}
+	return boxes
+}
+
 // ContainerByID returns a Box for the given id and boxType.
 func (iprp ItemPropertiesBox) ContainerByID(id uint16, boxType BoxType) (Box, error) {
 	for _, entry := range iprp.Associations.Entries {
@@ -502,6 +538,16 @@ func (b *box) parseItemPropertyAssociation() (ipa ItemPropertyAssociation, err e
 	flags := Flags(heicByteOrder.Uint32(buf[:4]))
 	count := int(heicByteOrder.Uint32(buf[4:8]))
 
+	// Each entry has at least an ItemID and an association count
+	entrySize := 3
+	if flags.Version() >= 1 {
+		entrySize = 5
+	}
+	if count > b.remain/entrySize {
+		err = errors.Wrapf(ErrBoxSize, "parseItemPropertyAssociation count %d", count)
+		return
+	}
+
 	// Entries
 	//	ipa.EntryCount = uint32(count)
 	ipa.Entries = make([]ItemPropertyAssociationItem, count)
@@ -554,10 +600,14 @@ func (b *box) parseItemPropertyAssociation() (ipa ItemPropertyAssociation, err e
 	return ipa, nil
 }
 
+// maxItemPropertyAssociations is the maximum number of properties
+// associated with an item that are read.
+const maxItemPropertyAssociations = 12
+
 // ItemPropertyAssociationItem is not a box
 type ItemPropertyAssociationItem struct {
 	ItemID       uint32
-	Associations [6]uint16
+	Associations [maxItemPropertyAssociations]uint16
 	//AssociationsCount uint32 // as declared
 	//Associations      []ItemProperty // as parsed
 }
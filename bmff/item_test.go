@@ -29,7 +29,7 @@ func TestParseItemInfoBox(t *testing.T) {
 		{"Test1", []byte{0, 0, 0, 10, 'i', 'i', 'n', 'f'}, ItemInfoBox{}, io.EOF},
 		{"Test2", []byte{0, 0, 0, 18, 'i', 'i', 'n', 'f', 0, 0, 0, 3, 0, 5, 0, 0, 18, 'a', 'n'}, ItemInfoBox{array2}, ErrBufLength},
 		{"Test3", []byte{0, 0, 0, 18, 'i', 'i', 'n', 'f', 0, 0, 0, 3, 0, 5, 0, 0, 18, 'i', 'n', 'f', 'd', 0, 0, 0, 0, 0}, ItemInfoBox{array2}, io.EOF},                                                              // CloseInnerBox Error
-		{"Test4", []byte{0, 0, 0, 18, 'i', 'i', 'n', 'f', 0, 0, 0, 3, 0, 5, 0, 0, 0, 2, 'i', 'n', 'f', 'e', 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, ItemInfoBox{array2}, io.EOF},                     // io.EOF
+		{"Test4", []byte{0, 0, 0, 18, 'i', 'i', 'n', 'f', 0, 0, 0, 3, 0, 5, 0, 0, 0, 2, 'i', 'n', 'f', 'e', 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, ItemInfoBox{array2}, ErrBoxSize},                 // ErrBoxSize
 		{"Test5", []byte{0, 0, 0, 18, 'i', 'i', 'n', 'f', 0, 0, 0, 3, 0, 5, 0, 0, 1, 0, 'i', 'n', 'f', 'e', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, ItemInfoBox{array2}, ErrInfeVersionNotSupported}, // ErrVersionNotSupported
 		{"Test6", []byte{0, 0, 0, 39, 'i', 'i', 'n', 'f', 0, 0, 0, 3, 0, 1, 0, 0, 0, 21, 'i', 'n', 'f', 'e', 2, 0, 0, 0, 2, 1, 0, 0, 'm', 'i', 'm', 'e', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, ItemInfoBox{array1}, nil},
 	}
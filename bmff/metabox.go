@@ -15,6 +15,7 @@ type MetaBox struct {
 	ItemInfo   ItemInfoBox
 	Properties ItemPropertiesBox
 	Location   ItemLocationBox
+	References ItemTypeReferenceBox
 	Children   []Box
 }
 
@@ -67,7 +68,7 @@ func parseMetaBox(outer *box) (mb MetaBox, err error) {
 			return mb, err
 		}
 		switch inner.boxType {
-		case TypeIdat, TypeDinf, TypeUUID, TypeIref:
+		case TypeIdat, TypeDinf, TypeUUID:
 			// Do not parse
 		case TypePitm:
 			mb.Primary, err = inner.parsePrimaryItemBox()
@@ -79,6 +80,8 @@ func parseMetaBox(outer *box) (mb MetaBox, err error) {
 			mb.Properties, err = inner.parseItemPropertiesBox()
 		case TypeIloc:
 			mb.Location, err = inner.parseItemLocationBox()
+		case TypeIref:
+			mb.References, err = inner.parseItemTypeReferenceBox()
 		default:
 			//p, err := inner.Parse()
 			//if err == nil {
@@ -0,0 +1,555 @@
+package bmff
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBoxSize is returned when the size of a box is smaller than its header.
+var ErrBoxSize = errors.New("error box size smaller than box header")
+
+// QuickTime movie values are in BigEndian.
+var movBinaryOrder = binary.BigEndian
+
+// movEpoch is the epoch of QuickTime and ISOBMFF timestamps
+var movEpoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Well-known data types of a 'data' box
+const (
+	dataTypeUTF8     = 1
+	dataTypeUTF16    = 2
+	dataTypeSigned   = 21
+	dataTypeUnsigned = 22
+	dataTypeFloat32  = 23
+	dataTypeFloat64  = 24
+)
+
+// Lengths
+const (
+	mvhdLengthV0 = 4 + 4*5
+	mvhdLengthV1 = 4 + 8*3 + 4
+	tkhdLengthV0 = 4 + 80
+	tkhdLengthV1 = 4 + 92
+)
+
+// User data items
+const (
+	// userDataXMP is the 'XMP_' user data item with the XMP packet of a QuickTime movie
+	userDataXMP = "XMP_"
+
+	// userDataLocation is the 3GPP 'loci' location item of an MP4 movie
+	userDataLocation = "loci"
+)
+
+// MovMoovBox is a QuickTime Movie 'moov' box
+type MovMoovBox struct {
+	Header MovieHeader
+	Tracks []TrackHeader
+
+	// Items are the text and number metadata items of the 'udta' box and of the 'meta' box.
+	// The keys of 'udta' items are their box type such as "©day", the keys of 'meta'
+	// items are the names of the 'keys' box such as "com.apple.quicktime.make".
+	// The 3GPP 'loci' location is an ISO 6709 string with the key "loci".
+	Items []MetadataItem
+
+	// XmpOffset and XmpLength are the location of the 'XMP_' user data item
+	// from the start of the 'moov' box.
+	XmpOffset, XmpLength uint32
+}
+
+// Item returns the value of the metadata item key.
+func (moov MovMoovBox) Item(key string) (string, bool) {
+	for _, item := range moov.Items {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return "", false
+}
+
+// MovieHeader is a 'mvhd' box
+type MovieHeader struct {
+	Created   time.Time
+	Modified  time.Time
+	TimeScale uint32
+	Duration  time.Duration
+}
+
+// TrackHeader is a 'tkhd' box
+type TrackHeader struct {
+	TrackID uint32
+
+	// Width and Height of the track, 0 for tracks without visual content
+	Width, Height uint32
+
+	// Rotation in degrees of the track matrix
+	Rotation uint16
+}
+
+// MetadataItem is a metadata item of a QuickTime movie
+type MetadataItem struct {
+	Key   string
+	Value string
+}
+
+// ReadMovMoovBox reads the 'moov' box of a QuickTime movie. Boxes before
+// the 'moov' box are skipped.
+func (r *Reader) ReadMovMoovBox() (moov MovMoovBox, err error) {
+	for {
+		b, err := r.readBox()
+		if err != nil {
+			return moov, errors.Wrapf(err, "ReadMovMoovBox")
+		}
+		if b.boxType == TypeMoov {
+			if debugFlag {
+				tracebox(b)
+			}
+			return parseMovMoovBox(&b)
+		}
+		if b.size < 8 {
+			// Box extends to the end of the file or is invalid
+			return moov, ErrWrongBoxType
+		}
+		if err = b.discard(b.remain); err != nil {
+			return moov, errors.Wrapf(err, "ReadMovMoovBox")
+		}
+	}
+}
+
+func parseMovMoovBox(moovBox *box) (moov MovMoovBox, err error) {
+	var fragmentDuration uint64
+	var inner box
+	for moovBox.anyRemain() {
+		if inner, err = moovBox.readInnerBox(); err != nil {
+			return moov, errors.Wrapf(err, "Box 'moov' (readBox)")
+		}
+		if inner.size < 8 {
+			return moov, errors.Wrapf(ErrBoxSize, "Box 'moov' %s", inner.boxType)
+		}
+		switch inner.boxType {
+		case TypeMvhd:
+			moov.Header, err = inner.parseMovieHeader()
+		case TypeTrak:
+			var t TrackHeader
+			if t, err = inner.parseMovTrak(); err == nil {
+				moov.Tracks = append(moov.Tracks, t)
+			}
+		case TypeMvex:
+			fragmentDuration, err = inner.parseMovieExtends()
+		case TypeUdta:
+			err = inner.parseUserData(&moov)
+		case TypeMeta:
+			moov.Items, err = inner.parseMovMeta(moov.Items)
+		default:
+			if debugFlag {
+				traceBoxWithMsg(inner, "discard")
+			}
+		}
+		if err != nil {
+			return moov, errors.Wrapf(err, "Box 'moov' %s", inner.boxType)
+		}
+		if err = moovBox.closeInnerBox(&inner); err != nil {
+			return
+		}
+	}
+	// Fragmented movies have the duration of the fragments in the 'mehd' box
+	if moov.Header.Duration == 0 {
+		moov.Header.Duration = movDuration(fragmentDuration, moov.Header.TimeScale)
+	}
+	err = moovBox.discard(moovBox.remain)
+	return
+}
+
+// parseMovieExtends parses the fragment duration of the 'mehd' box of an 'mvex' box.
+func (b *box) parseMovieExtends() (duration uint64, err error) {
+	var inner box
+	for b.anyRemain() {
+		if inner, err = b.readInnerBox(); err != nil {
+			return
+		}
+		if inner.size < 8 {
+			return 0, ErrBoxSize
+		}
+		if inner.boxType == TypeMehd {
+			var buf []byte
+			if buf, err = inner.peek(8); err != nil {
+				return
+			}
+			if Flags(movBinaryOrder.Uint32(buf[:4])).Version() == 1 {
+				if buf, err = inner.peek(12); err != nil {
+					return
+				}
+				duration = movBinaryOrder.Uint64(buf[4:12])
+			} else {
+				duration = uint64(movBinaryOrder.Uint32(buf[4:8]))
+			}
+		}
+		if err = b.closeInnerBox(&inner); err != nil {
+			return
+		}
+	}
+	return duration, b.discard(b.remain)
+}
+
+// parseMovieHeader parses a 'mvhd' box: the creation time, the modification time,
+// the time scale and the duration.
+func (b *box) parseMovieHeader() (mvhd MovieHeader, err error) {
+	buf, err := b.peek(mvhdLengthV0)
+	if err != nil {
+		return
+	}
+	var created, modified, duration uint64
+	if Flags(movBinaryOrder.Uint32(buf[:4])).Version() == 1 {
+		if buf, err = b.peek(mvhdLengthV1); err != nil {
+			return
+		}
+		created, modified = movBinaryOrder.Uint64(buf[4:12]), movBinaryOrder.Uint64(buf[12:20])
+		mvhd.TimeScale, duration = movBinaryOrder.Uint32(buf[20:24]), movBinaryOrder.Uint64(buf[24:32])
+	} else {
+		created, modified = uint64(movBinaryOrder.Uint32(buf[4:8])), uint64(movBinaryOrder.Uint32(buf[8:12]))
+		mvhd.TimeScale, duration = movBinaryOrder.Uint32(buf[12:16]), uint64(movBinaryOrder.Uint32(buf[16:20]))
+	}
+	mvhd.Created, mvhd.Modified = movTime(created), movTime(modified)
+	// A duration of all 1s is an unknown duration
+	if duration != math.MaxUint32 && duration != math.MaxUint64 {
+		mvhd.Duration = movDuration(duration, mvhd.TimeScale)
+	}
+	if debugFlag {
+		traceBoxWithMsg(*b, "mvhd | created: "+mvhd.Created.String())
+	}
+	return mvhd, b.discard(b.remain)
+}
+
+// movDuration returns the duration in units of timeScale.
+func movDuration(duration uint64, timeScale uint32) time.Duration {
+	if timeScale == 0 || duration/uint64(timeScale) >= math.MaxInt64/uint64(time.Second) {
+		return 0
+	}
+	seconds := duration / uint64(timeScale)
+	rem := duration % uint64(timeScale)
+	return time.Duration(seconds)*time.Second + time.Duration(rem)*time.Second/time.Duration(timeScale)
+}
+
+// movTime returns the time of a timestamp in seconds since the movEpoch.
+// Returns the zero time for a zero timestamp.
+func movTime(t uint64) time.Time {
+	if t == 0 || t > math.MaxInt64/2 {
+		return time.Time{}
+	}
+	return movEpoch.Add(time.Duration(t) * time.Second)
+}
+
+// parseMovTrak parses the 'tkhd' box of a 'trak' box.
+func (b *box) parseMovTrak() (t TrackHeader, err error) {
+	var inner box
+	for b.anyRemain() {
+		if inner, err = b.readInnerBox(); err != nil {
+			return
+		}
+		if inner.size < 8 {
+			return t, ErrBoxSize
+		}
+		if inner.boxType == TypeTkhd {
+			if t, err = inner.parseTrackHeader(); err != nil {
+				return
+			}
+		}
+		if err = b.closeInnerBox(&inner); err != nil {
+			return
+		}
+	}
+	return t, b.discard(b.remain)
+}
+
+// parseTrackHeader parses a 'tkhd' box: the track ID, the matrix, the width and the height.
+func (b *box) parseTrackHeader() (t TrackHeader, err error) {
+	buf, err := b.peek(tkhdLengthV0)
+	if err != nil {
+		return
+	}
+	// Offset of the matrix
+	matrix := 40
+	if Flags(movBinaryOrder.Uint32(buf[:4])).Version() == 1 {
+		if buf, err = b.peek(tkhdLengthV1); err != nil {
+			return
+		}
+		t.TrackID = movBinaryOrder.Uint32(buf[20:24])
+		matrix = 52
+	} else {
+		t.TrackID = movBinaryOrder.Uint32(buf[12:16])
+	}
+	// Width and Height are 16.16 fixed point values after the matrix
+	t.Width = movBinaryOrder.Uint32(buf[matrix+36:matrix+40]) >> 16
+	t.Height = movBinaryOrder.Uint32(buf[matrix+40:matrix+44]) >> 16
+	t.Rotation = matrixRotation(buf[matrix : matrix+36])
+	return t, b.discard(b.remain)
+}
+
+// matrixRotation returns the rotation in degrees of the transformation matrix buf.
+// The matrix values a, b, c and d are 16.16 fixed point values.
+func matrixRotation(buf []byte) uint16 {
+	a, b := int32(movBinaryOrder.Uint32(buf[0:4])), int32(movBinaryOrder.Uint32(buf[4:8]))
+	c, d := int32(movBinaryOrder.Uint32(buf[12:16])), int32(movBinaryOrder.Uint32(buf[16:20]))
+	const one = 1 << 16
+	switch {
+	case a == 0 && b == one && c == -one && d == 0:
+		return 90
+	case a == -one && b == 0 && c == 0 && d == -one:
+		return 180
+	case a == 0 && b == -one && c == one && d == 0:
+		return 270
+	}
+	return 0
+}
+
+// parseUserData parses the text items, the 'meta' box and the 'XMP_' item of a 'udta' box.
+//
+// QuickTime text items have a box type that begins with '©'. Each text has
+// a 16 bit length and a 16 bit language code.
+func (b *box) parseUserData(moov *MovMoovBox) (err error) {
+	var inner box
+	for b.anyRemain() {
+		var header []byte
+		if header, err = b.peek(8); err != nil {
+			// 'udta' boxes can end with a 32 bit terminator
+			return b.discard(b.remain)
+		}
+		key := string(header[4:8])
+		if inner, err = b.readInnerBox(); err != nil {
+			return
+		}
+		if inner.size < 8 {
+			return ErrBoxSize
+		}
+		switch {
+		case inner.boxType == TypeMeta:
+			moov.Items, err = inner.parseMovMeta(moov.Items)
+		case key == userDataXMP:
+			moov.XmpOffset, moov.XmpLength = uint32(inner.offset), uint32(inner.remain)
+		case key == userDataLocation:
+			if buf, ok := inner.peekValue(); ok {
+				if value, ok := locationValue(buf); ok {
+					moov.Items = append(moov.Items, MetadataItem{Key: userDataLocation, Value: value})
+				}
+			}
+		case key[0] == 0xA9:
+			if buf, ok := inner.peekValue(); ok {
+				if value, ok := userDataText(buf); ok {
+					moov.Items = append(moov.Items, MetadataItem{Key: "©" + key[1:], Value: value})
+				}
+			}
+		}
+		if err != nil {
+			return
+		}
+		if err = b.closeInnerBox(&inner); err != nil {
+			return
+		}
+	}
+	return b.discard(b.remain)
+}
+
+// peekValue returns the remaining bytes of the box if they fit in the buffer
+// of the bufio.Reader.
+func (b *box) peekValue() ([]byte, bool) {
+	if b.remain > b.Reader.Size() {
+		return nil, false
+	}
+	buf, err := b.peek(b.remain)
+	return buf, err == nil
+}
+
+// userDataText returns the first text of a QuickTime text item, or the
+// value of the 'data' box of an iTunes style item.
+func userDataText(buf []byte) (string, bool) {
+	if len(buf) >= 16 && string(buf[4:8]) == "data" {
+		return dataValue(buf)
+	}
+	if len(buf) < 4 {
+		return "", false
+	}
+	n := int(movBinaryOrder.Uint16(buf[:2]))
+	if n > len(buf)-4 {
+		return "", false
+	}
+	return string(buf[4 : 4+n]), true
+}
+
+// locationValue returns the longitude, latitude and altitude of a 3GPP 'loci' box
+// as an ISO 6709 string.
+//
+// loci: version and flags, language, name, role, longitude, latitude and altitude
+// as 16.16 fixed point values, astronomical body and notes.
+func locationValue(buf []byte) (string, bool) {
+	if len(buf) < 6 {
+		return "", false
+	}
+	// Null terminated name
+	n := bytes.IndexByte(buf[6:], 0)
+	if n < 0 || len(buf) < 6+n+1+1+12 {
+		return "", false
+	}
+	buf = buf[6+n+1+1:]
+	lng := float64(int32(movBinaryOrder.Uint32(buf[0:4]))) / (1 << 16)
+	lat := float64(int32(movBinaryOrder.Uint32(buf[4:8]))) / (1 << 16)
+	alt := float64(int32(movBinaryOrder.Uint32(buf[8:12]))) / (1 << 16)
+	return fmt.Sprintf("%+.4f%+.4f%+.3f/", lat, lng, alt), true
+}
+
+// parseMovMeta parses the 'keys' and 'ilst' boxes of a 'meta' box and appends
+// the items to items.
+//
+// The QuickTime 'meta' box does not have the version and flags of the ISOBMFF 'meta' box.
+func (b *box) parseMovMeta(items []MetadataItem) ([]MetadataItem, error) {
+	buf, err := b.peek(8)
+	if err != nil {
+		return items, b.discard(b.remain)
+	}
+	if string(buf[4:8]) != "hdlr" {
+		if _, err = b.readFlags(); err != nil {
+			return items, err
+		}
+	}
+	var keys []string
+	var inner box
+	for b.anyRemain() {
+		if inner, err = b.readInnerBox(); err != nil {
+			return items, err
+		}
+		if inner.size < 8 {
+			return items, ErrBoxSize
+		}
+		switch inner.boxType {
+		case TypeKeys:
+			if buf, ok := inner.peekValue(); ok {
+				keys = parseKeys(buf)
+			}
+		case TypeIlst:
+			items, err = inner.parseItemList(keys, items)
+		}
+		if err != nil {
+			return items, err
+		}
+		if err = b.closeInnerBox(&inner); err != nil {
+			return items, err
+		}
+	}
+	return items, b.discard(b.remain)
+}
+
+// parseKeys parses a 'keys' box: the version and flags, the entry count and the
+// entries. Each entry has a size, a namespace and a name.
+func parseKeys(buf []byte) (keys []string) {
+	if len(buf) < 8 {
+		return nil
+	}
+	count := int(movBinaryOrder.Uint32(buf[4:8]))
+	buf = buf[8:]
+	for i := 0; i < count && len(buf) >= 8; i++ {
+		size := int(movBinaryOrder.Uint32(buf[:4]))
+		if size < 8 || size > len(buf) {
+			break
+		}
+		keys = append(keys, string(buf[8:size]))
+		buf = buf[size:]
+	}
+	return keys
+}
+
+// parseItemList parses the items of an 'ilst' box and appends them to items. The box type of
+// an item is the 1-based index of its key in keys, or the key of an iTunes style item.
+func (b *box) parseItemList(keys []string, items []MetadataItem) ([]MetadataItem, error) {
+	var inner box
+	for b.anyRemain() {
+		header, err := b.peek(8)
+		if err != nil {
+			break
+		}
+		key := string(header[4:8])
+		if index := int(movBinaryOrder.Uint32(header[4:8])); index > 0 && index <= len(keys) {
+			key = keys[index-1]
+		} else if key[0] == 0xA9 {
+			key = "©" + key[1:]
+		}
+		if inner, err = b.readInnerBox(); err != nil {
+			return items, err
+		}
+		if inner.size < 8 {
+			return items, ErrBoxSize
+		}
+		if buf, ok := inner.peekValue(); ok {
+			if value, ok := dataValue(buf); ok {
+				items = append(items, MetadataItem{Key: key, Value: value})
+			}
+		}
+		if err = b.closeInnerBox(&inner); err != nil {
+			return items, err
+		}
+	}
+	return items, b.discard(b.remain)
+}
+
+// dataValue returns the value of the 'data' box in buf as a string.
+//
+// data: size, type, well-known data type, locale and value.
+func dataValue(buf []byte) (string, bool) {
+	if len(buf) < 16 || string(buf[4:8]) != "data" {
+		return "", false
+	}
+	size := int(movBinaryOrder.Uint32(buf[:4]))
+	if size < 16 || size > len(buf) {
+		return "", false
+	}
+	value := buf[16:size]
+	switch movBinaryOrder.Uint32(buf[8:12]) & 0xFFFFFF {
+	case dataTypeUTF8:
+		return string(value), true
+	case dataTypeUTF16:
+		u := make([]uint16, len(value)/2)
+		for i := range u {
+			u[i] = movBinaryOrder.Uint16(value[i*2:])
+		}
+		return string(utf16.Decode(u)), true
+	case dataTypeSigned:
+		if v, ok := dataUint(value); ok {
+			// Sign extend the big endian integer
+			shift := 64 - 8*uint(len(value))
+			return strconv.FormatInt(int64(v<<shift)>>shift, 10), true
+		}
+	case dataTypeUnsigned:
+		if v, ok := dataUint(value); ok {
+			return strconv.FormatUint(v, 10), true
+		}
+	case dataTypeFloat32:
+		if len(value) == 4 {
+			return strconv.FormatFloat(float64(math.Float32frombits(movBinaryOrder.Uint32(value))), 'f', -1, 32), true
+		}
+	case dataTypeFloat64:
+		if len(value) == 8 {
+			return strconv.FormatFloat(math.Float64frombits(movBinaryOrder.Uint64(value)), 'f', -1, 64), true
+		}
+	}
+	return "", false
+}
+
+// dataUint returns the 1, 2, 4 or 8 byte big endian integer in buf.
+func dataUint(buf []byte) (uint64, bool) {
+	switch len(buf) {
+	case 1:
+		return uint64(buf[0]), true
+	case 2:
+		return uint64(movBinaryOrder.Uint16(buf)), true
+	case 4:
+		return uint64(movBinaryOrder.Uint32(buf)), true
+	case 8:
+		return movBinaryOrder.Uint64(buf), true
+	}
+	return 0, false
+}
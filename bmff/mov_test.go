@@ -0,0 +1,38 @@
+package bmff
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestDataValue(t *testing.T) {
+	newData := func(typ uint32, value []byte) []byte {
+		buf := make([]byte, 16, 16+len(value))
+		binary.BigEndian.PutUint32(buf[0:], uint32(16+len(value)))
+		copy(buf[4:], "data")
+		binary.BigEndian.PutUint32(buf[8:], typ)
+		return append(buf, value...)
+	}
+	float := make([]byte, 4)
+	binary.BigEndian.PutUint32(float, math.Float32bits(2.5))
+	tests := []struct {
+		name string
+		buf  []byte
+		want string
+		ok   bool
+	}{
+		{"utf8", newData(dataTypeUTF8, []byte("Canon")), "Canon", true},
+		{"utf16", newData(dataTypeUTF16, []byte{0, 'R', 0, '5'}), "R5", true},
+		{"signed", newData(dataTypeSigned, []byte{0xFF, 0xFE}), "-2", true},
+		{"unsigned", newData(dataTypeUnsigned, []byte{0xFF, 0xFE}), "65534", true},
+		{"float32", newData(dataTypeFloat32, float), "2.5", true},
+		{"unknown", newData(13, []byte{0xFF, 0xD8}), "", false},
+		{"short", []byte("data"), "", false},
+	}
+	for _, test := range tests {
+		if v, ok := dataValue(test.buf); v != test.want || ok != test.ok {
+			t.Errorf("Incorrect %s dataValue wanted %q %t got %q %t", test.name, test.want, test.ok, v, ok)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package bmff
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// Colour types of a "colr" property
+var (
+	ColourTypeNclx = [4]byte{'n', 'c', 'l', 'x'} // colour primaries, transfer characteristics and matrix
+	ColourTypeRICC = [4]byte{'r', 'I', 'C', 'C'} // restricted ICC profile
+	ColourTypeProf = [4]byte{'p', 'r', 'o', 'f'} // unrestricted ICC profile
+)
+
+// Transfer characteristics of HDR images (ITU-T H.273)
+const (
+	TransferCharacteristicsPQ  = 16 // SMPTE ST 2084
+	TransferCharacteristicsHLG = 18 // ARIB STD-B67
+)
+
+// Auxiliary types of alpha planes in an "auxC" property
+const (
+	auxTypeAlpha     = "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha"
+	auxTypeHevcAlpha = "urn:mpeg:hevc:2015:auxid:1"
+)
+
+// maxAuxTypeLength is the largest aux_type of an "auxC" property that is read.
+const maxAuxTypeLength = 256
+
+// ColourInformationBox is a "colr" property.
+//
+// The ColourPrimaries, TransferCharacteristics, MatrixCoefficients and FullRange
+// are only set for the ColourType "nclx". An ICC profile is not read.
+type ColourInformationBox struct {
+	ColourType              [4]byte
+	ColourPrimaries         uint16
+	TransferCharacteristics uint16
+	MatrixCoefficients      uint16
+	FullRange               bool
+}
+
+// Type returns TypeColr
+func (colr ColourInformationBox) Type() BoxType {
+	return TypeColr
+}
+
+// HDR returns true if the transfer characteristics are PQ or HLG.
+func (colr ColourInformationBox) HDR() bool {
+	return colr.ColourType == ColourTypeNclx &&
+		(colr.TransferCharacteristics == TransferCharacteristicsPQ || colr.TransferCharacteristics == TransferCharacteristicsHLG)
+}
+
+func (colr ColourInformationBox) String() string {
+	if colr.ColourType != ColourTypeNclx {
+		return fmt.Sprintf("(colr) ColourType:%s", colr.ColourType[:])
+	}
+	return fmt.Sprintf("(colr) ColourType:%s, Primaries:%d, Transfer:%d, Matrix:%d, FullRange:%t", colr.ColourType[:], colr.ColourPrimaries, colr.TransferCharacteristics, colr.MatrixCoefficients, colr.FullRange)
+}
+
+func parseColr(outer *box) (Box, error) {
+	return outer.parseColourInformationBox()
+}
+
+func (b *box) parseColourInformationBox() (colr ColourInformationBox, err error) {
+	buf, err := b.peek(4)
+	if err != nil {
+		return colr, errors.Wrap(err, "parseColourInformationBox")
+	}
+	copy(colr.ColourType[:], buf)
+	if colr.ColourType != ColourTypeNclx {
+		return colr, b.discard(b.remain)
+	}
+	if buf, err = b.peek(11); err != nil {
+		return colr, errors.Wrap(err, "parseColourInformationBox")
+	}
+	colr.ColourPrimaries = heicByteOrder.Uint16(buf[4:6])
+	colr.TransferCharacteristics = heicByteOrder.Uint16(buf[6:8])
+	colr.MatrixCoefficients = heicByteOrder.Uint16(buf[8:10])
+	colr.FullRange = buf[10]&0x80 != 0
+	return colr, b.discard(b.remain)
+}
+
+// PixelInformationProperty is a "pixi" property.
+// It has the number of bits of each channel of the image.
+type PixelInformationProperty struct {
+	BitsPerChannel []uint8
+}
+
+// Type returns TypePixi
+func (pixi PixelInformationProperty) Type() BoxType {
+	return TypePixi
+}
+
+func (pixi PixelInformationProperty) String() string {
+	return fmt.Sprintf("(pixi) BitsPerChannel:%v", pixi.BitsPerChannel)
+}
+
+func parsePixi(outer *box) (Box, error) {
+	return outer.parsePixelInformationProperty()
+}
+
+func (b *box) parsePixelInformationProperty() (pixi PixelInformationProperty, err error) {
+	if _, err = b.readFlags(); err != nil {
+		return pixi, errors.Wrap(err, "parsePixelInformationProperty")
+	}
+	count, err := b.readUint8()
+	if err != nil {
+		return pixi, errors.Wrap(err, "parsePixelInformationProperty")
+	}
+	buf, err := b.peek(int(count))
+	if err != nil {
+		return pixi, errors.Wrap(err, "parsePixelInformationProperty")
+	}
+	pixi.BitsPerChannel = append([]uint8(nil), buf...)
+	return pixi, b.discard(b.remain)
+}
+
+// AuxiliaryTypeProperty is an "auxC" property.
+// It has the type of an auxiliary image, for example an alpha plane or a depth map.
+type AuxiliaryTypeProperty struct {
+	AuxType string
+}
+
+// Type returns TypeAuxC
+func (auxC AuxiliaryTypeProperty) Type() BoxType {
+	return TypeAuxC
+}
+
+// IsAlpha returns true if the auxiliary image is an alpha plane.
+func (auxC AuxiliaryTypeProperty) IsAlpha() bool {
+	return auxC.AuxType == auxTypeAlpha || auxC.AuxType == auxTypeHevcAlpha
+}
+
+func (auxC AuxiliaryTypeProperty) String() string {
+	return fmt.Sprintf("(auxC) AuxType:%s", auxC.AuxType)
+}
+
+func parseAuxC(outer *box) (Box, error) {
+	return outer.parseAuxiliaryTypeProperty()
+}
+
+func (b *box) parseAuxiliaryTypeProperty() (auxC AuxiliaryTypeProperty, err error) {
+	if _, err = b.readFlags(); err != nil {
+		return auxC, errors.Wrap(err, "parseAuxiliaryTypeProperty")
+	}
+	n := b.remain
+	if n > maxAuxTypeLength {
+		n = maxAuxTypeLength
+	}
+	buf, err := b.peek(n)
+	if err != nil {
+		return auxC, errors.Wrap(err, "parseAuxiliaryTypeProperty")
+	}
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		buf = buf[:i]
+	}
+	auxC.AuxType = string(buf)
+	return auxC, b.discard(b.remain)
+}
+
+// ContentLightLevelBox is a "clli" property.
+// The light levels are in candelas per square meter.
+type ContentLightLevelBox struct {
+	MaxContentLightLevel    uint16
+	MaxPicAverageLightLevel uint16
+}
+
+// Type returns TypeClli
+func (clli ContentLightLevelBox) Type() BoxType {
+	return TypeClli
+}
+
+func (clli ContentLightLevelBox) String() string {
+	return fmt.Sprintf("(clli) MaxCLL:%d, MaxPALL:%d", clli.MaxContentLightLevel, clli.MaxPicAverageLightLevel)
+}
+
+func parseClli(outer *box) (Box, error) {
+	return outer.parseContentLightLevelBox()
+}
+
+func (b *box) parseContentLightLevelBox() (clli ContentLightLevelBox, err error) {
+	buf, err := b.peek(4)
+	if err != nil {
+		return clli, errors.Wrap(err, "parseContentLightLevelBox")
+	}
+	clli.MaxContentLightLevel = heicByteOrder.Uint16(buf[:2])
+	clli.MaxPicAverageLightLevel = heicByteOrder.Uint16(buf[2:4])
+	return clli, b.discard(b.remain)
+}
+
+// MasteringDisplayColourVolumeBox is a "mdcv" property.
+//
+// The DisplayPrimaries (x, y of green, blue and red) and the WhitePoint are in
+// increments of 0.00002. The luminances are in increments of 0.0001 candelas per square meter.
+type MasteringDisplayColourVolumeBox struct {
+	DisplayPrimaries [3][2]uint16
+	WhitePoint       [2]uint16
+	MaxLuminance     uint32
+	MinLuminance     uint32
+}
+
+// Type returns TypeMdcv
+func (mdcv MasteringDisplayColourVolumeBox) Type() BoxType {
+	return TypeMdcv
+}
+
+func (mdcv MasteringDisplayColourVolumeBox) String() string {
+	return fmt.Sprintf("(mdcv) Primaries:%v, WhitePoint:%v, MaxLuminance:%d, MinLuminance:%d", mdcv.DisplayPrimaries, mdcv.WhitePoint, mdcv.MaxLuminance, mdcv.MinLuminance)
+}
+
+func parseMdcv(outer *box) (Box, error) {
+	return outer.parseMasteringDisplayColourVolumeBox()
+}
+
+func (b *box) parseMasteringDisplayColourVolumeBox() (mdcv MasteringDisplayColourVolumeBox, err error) {
+	buf, err := b.peek(24)
+	if err != nil {
+		return mdcv, errors.Wrap(err, "parseMasteringDisplayColourVolumeBox")
+	}
+	for i := range mdcv.DisplayPrimaries {
+		mdcv.DisplayPrimaries[i][0] = heicByteOrder.Uint16(buf[i*4:])
+		mdcv.DisplayPrimaries[i][1] = heicByteOrder.Uint16(buf[i*4+2:])
+	}
+	mdcv.WhitePoint[0] = heicByteOrder.Uint16(buf[12:14])
+	mdcv.WhitePoint[1] = heicByteOrder.Uint16(buf[14:16])
+	mdcv.MaxLuminance = heicByteOrder.Uint32(buf[16:20])
+	mdcv.MinLuminance = heicByteOrder.Uint32(buf[20:24])
+	return mdcv, b.discard(b.remain)
+}
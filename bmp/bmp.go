@@ -0,0 +1,121 @@
+// Package bmp reads the dimensions and bit depth from the header of a BMP Image
+// without decoding the image.
+package bmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif      = meta.ErrNoExif
+	ErrNoBMPHeader = errors.New("no BMP Header")
+
+	// ErrUnsupportedHeader is returned when the DIB header has an unknown size.
+	ErrUnsupportedHeader = errors.New("unsupported BMP DIB header")
+)
+
+// Metadata from a BMP file
+type Metadata struct {
+	mr meta.Reader
+
+	width    uint32
+	height   uint32
+	bitDepth uint16
+	topDown  bool
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.width, m.height)
+}
+
+// ImageType returns imagetype.ImageBMP for BMP image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageBMP
+}
+
+// BitDepth returns the number of bits per pixel
+func (m Metadata) BitDepth() uint16 {
+	return m.bitDepth
+}
+
+// TopDown returns true if the rows of the image are stored from top to bottom.
+func (m Metadata) TopDown() bool {
+	return m.topDown
+}
+
+// PreviewImage returns a BMP preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns ErrNoExif, BMP images do not have Exif metadata
+func (m Metadata) Exif() (exif.Exif, error) {
+	return nil, ErrNoExif
+}
+
+// Xmp returns xmp.ErrNoXMP, BMP images do not have XMP metadata
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	return xmp.XMP{}, xmp.ErrNoXMP
+}
+
+// ScanBMP reads the BMP file header and DIB header from mr. Returns Metadata.
+//
+// Returns the error ErrNoBMPHeader if mr is not a BMP, and ErrUnsupportedHeader
+// if the DIB header has an unknown size.
+func ScanBMP(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [fileHeaderLength + 16]byte
+	n, _ := mr.ReadAt(buf[:], 0)
+	if n < fileHeaderLength+coreHeaderLength || buf[0] != 'B' || buf[1] != 'M' {
+		return m, ErrNoBMPHeader
+	}
+	dib := buf[fileHeaderLength:]
+	switch size := bmpByteOrder.Uint32(dib[:4]); {
+	case size > coreHeaderLength && n < len(buf):
+		return m, ErrNoBMPHeader
+	case size == coreHeaderLength:
+		// BITMAPCOREHEADER: 16bit width and height
+		m.width = uint32(bmpByteOrder.Uint16(dib[4:6]))
+		m.height = uint32(bmpByteOrder.Uint16(dib[6:8]))
+		m.bitDepth = bmpByteOrder.Uint16(dib[10:12])
+	case size == os2HeaderLength || size >= infoHeaderLength:
+		// OS/2 BITMAPINFOHEADER2, BITMAPINFOHEADER and later versions: signed 32bit width and height.
+		// A negative height is a top-down image.
+		width := int32(bmpByteOrder.Uint32(dib[4:8]))
+		height := int32(bmpByteOrder.Uint32(dib[8:12]))
+		if width < 0 {
+			return m, ErrUnsupportedHeader
+		}
+		if height < 0 {
+			m.topDown = true
+			height = -height
+		}
+		m.width, m.height = uint32(width), uint32(height)
+		m.bitDepth = bmpByteOrder.Uint16(dib[14:16])
+	default:
+		return m, ErrUnsupportedHeader
+	}
+	return m, nil
+}
+
+// Header lengths
+const (
+	fileHeaderLength = 14
+	coreHeaderLength = 12
+	os2HeaderLength  = 16
+	infoHeaderLength = 40
+)
+
+// bmpByteOrder BMP always uses a LittleEndian byteorder.
+var bmpByteOrder = binary.LittleEndian
@@ -0,0 +1,83 @@
+package bmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// bmpHeader returns a BMP file header followed by a DIB header of size
+// with the width, height and bit depth.
+func bmpHeader(size uint32, width, height int32, bitDepth uint16) []byte {
+	buf := make([]byte, fileHeaderLength+size)
+	buf[0], buf[1] = 'B', 'M'
+	dib := buf[fileHeaderLength:]
+	binary.LittleEndian.PutUint32(dib[:4], size)
+	if size == coreHeaderLength {
+		binary.LittleEndian.PutUint16(dib[4:6], uint16(width))
+		binary.LittleEndian.PutUint16(dib[6:8], uint16(height))
+		binary.LittleEndian.PutUint16(dib[10:12], bitDepth)
+		return buf
+	}
+	binary.LittleEndian.PutUint32(dib[4:8], uint32(width))
+	binary.LittleEndian.PutUint32(dib[8:12], uint32(height))
+	binary.LittleEndian.PutUint16(dib[14:16], bitDepth)
+	return buf
+}
+
+func TestScanBMP(t *testing.T) {
+	testBMPs := []struct {
+		name     string
+		buf      []byte
+		dim      meta.Dimensions
+		bitDepth uint16
+		topDown  bool
+	}{
+		{"BITMAPINFOHEADER", bmpHeader(infoHeaderLength, 640, 480, 24), meta.NewDimensions(640, 480), 24, false},
+		{"BITMAPV5HEADER", bmpHeader(124, 32, 16, 32), meta.NewDimensions(32, 16), 32, false},
+		{"TopDown", bmpHeader(infoHeaderLength, 100, -50, 8), meta.NewDimensions(100, 50), 8, true},
+		{"BITMAPCOREHEADER", bmpHeader(coreHeaderLength, 20, 10, 1), meta.NewDimensions(20, 10), 1, false},
+		{"OS2", bmpHeader(os2HeaderLength, 3, 4, 4), meta.NewDimensions(3, 4), 4, false},
+	}
+	for _, bmp := range testBMPs {
+		t.Run(bmp.name, func(t *testing.T) {
+			m, err := ScanBMP(bytes.NewReader(bmp.buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Dimensions() != bmp.dim || m.ImageType() != imagetype.ImageBMP {
+				t.Errorf("Incorrect Metadata wanted %s got %s %s", bmp.dim, m.Dimensions(), m.ImageType())
+			}
+			if m.BitDepth() != bmp.bitDepth || m.TopDown() != bmp.topDown {
+				t.Errorf("Incorrect BitDepth and TopDown wanted %d %t got %d %t", bmp.bitDepth, bmp.topDown, m.BitDepth(), m.TopDown())
+			}
+			if _, err = m.Exif(); err != ErrNoExif {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+			}
+		})
+	}
+}
+
+func TestScanBMPErrors(t *testing.T) {
+	testErrors := []struct {
+		name string
+		buf  []byte
+		err  error
+	}{
+		{"NoSignature", append([]byte("MB"), bmpHeader(infoHeaderLength, 1, 1, 24)[2:]...), ErrNoBMPHeader},
+		{"Short", bmpHeader(infoHeaderLength, 1, 1, 24)[:20], ErrNoBMPHeader},
+		{"Truncated", bmpHeader(infoHeaderLength, 1, 1, 24)[:28], ErrNoBMPHeader},
+		{"UnknownHeader", bmpHeader(20, 1, 1, 24), ErrUnsupportedHeader},
+		{"NegativeWidth", bmpHeader(infoHeaderLength, -1, 1, 24), ErrUnsupportedHeader},
+	}
+	for _, te := range testErrors {
+		t.Run(te.name, func(t *testing.T) {
+			if _, err := ScanBMP(bytes.NewReader(te.buf)); err != te.err {
+				t.Errorf("Incorrect error wanted %v got %v", te.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/evanoberholster/imagemeta"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// csvHeader is the column set of the csv subcommand
+var csvHeader = []string{
+	"filename", "make", "model", "lens", "iso", "aperture", "shutter_speed",
+	"focal_length", "datetime", "gps_latitude", "gps_longitude", "orientation", "dimensions",
+}
+
+// writeCSV parses each file and writes one row per file to w. Files that can
+// not be parsed or do not have Exif are logged to logw and written with the
+// values that were read, the Exif values are empty.
+//
+//	imagemeta csv <files...>
+func writeCSV(w io.Writer, logw io.Writer, files []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, filename := range files {
+		row, err := csvRow(filename)
+		if err != nil {
+			fmt.Fprintf(logw, "%s: %v\n", filename, err)
+		}
+		if err = cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRow returns the csv row of filename. Missing values are empty.
+// The row is returned with the filename and the values that were read
+// when an error occurs.
+func csvRow(filename string) ([]string, error) {
+	row := make([]string, len(csvHeader))
+	row[0] = filename
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return row, err
+	}
+	defer f.Close()
+
+	m, err := imagemeta.Parse(f)
+	if m == nil {
+		if err == nil {
+			err = imagemeta.ErrMetadataNotSupported
+		}
+		return row, err
+	}
+	if err == nil || errors.Is(err, imagemeta.ErrNoExif) {
+		row[12] = dimensions(m.Dimensions())
+	}
+	if err != nil {
+		return row, err
+	}
+	e, err := m.Exif()
+	if err != nil {
+		return row, err
+	}
+
+	row[1] = e.CameraMake()
+	row[2] = e.CameraModel()
+	row[3], _ = e.LensModel()
+	if iso, err := e.ISOSpeed(); err == nil {
+		row[4] = strconv.FormatUint(uint64(iso), 10)
+	}
+	if a, err := e.Aperture(); err == nil {
+		row[5] = a.String()
+	}
+	if ss, err := e.ShutterSpeed(); err == nil {
+		row[6] = ss.String()
+	}
+	if fl, err := e.FocalLength(); err == nil {
+		row[7] = strconv.FormatFloat(float64(fl), 'f', -1, 32)
+	}
+	if dt, err := e.DateTime(time.UTC); err == nil {
+		row[8] = dt.Format(time.RFC3339)
+	}
+	if lat, lng, err := e.GPSCoords(); err == nil {
+		row[9] = strconv.FormatFloat(lat, 'f', 6, 64)
+		row[10] = strconv.FormatFloat(lng, 'f', 6, 64)
+	}
+	row[11] = e.Orientation().String()
+	if row[12] == "" {
+		row[12] = dimensions(e.Dimensions())
+	}
+	return row, nil
+}
+
+// dimensions returns dim as "WxH", or an empty string if dim is unknown.
+func dimensions(dim meta.Dimensions) string {
+	width, height := dim.Size()
+	if width == 0 || height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", width, height)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	files := []string{"../testImages/JPEG.jpg", "../testImages/Unknown.exif", "../testImages/missing.jpg", "../testImages/GIF.gif", "../testImages/Hero8.GPR"}
+
+	var out, errOut bytes.Buffer
+	if err := writeCSV(&out, &errOut, files); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(files)+1 {
+		t.Fatalf("Incorrect number of csv rows wanted %d got %d", len(files)+1, len(rows))
+	}
+	if strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("Incorrect csv header wanted %v got %v", csvHeader, rows[0])
+	}
+
+	wanted := [][]string{
+		{"../testImages/JPEG.jpg", "GoPro", "HERO4 Silver", "", "113", "2.80", "1/60", "3", "2016-10-11T16:59:50Z", "", "", "Horizontal", "1000x563"},
+		{"../testImages/Unknown.exif", "", "", "", "", "", "", "", "", "", "", "", ""},
+		{"../testImages/missing.jpg", "", "", "", "", "", "", "", "", "", "", "", ""},
+		{"../testImages/GIF.gif", "", "", "", "", "", "", "", "", "", "", "", "1x1"},
+		{"../testImages/Hero8.GPR", "GoPro", "HERO8 Black", "", "317", "2.80", "1/240", "3", "2020-05-28T04:47:27Z", "56.298435", "10.146559", "Mirror horizontal", "4000x3000"},
+	}
+	for i, row := range wanted {
+		if strings.Join(rows[i+1], ",") != strings.Join(row, ",") {
+			t.Errorf("Incorrect csv row wanted %v got %v", row, rows[i+1])
+		}
+	}
+
+	// Errors are logged and the batch continues
+	for _, filename := range files[1:4] {
+		if !strings.Contains(errOut.String(), filename) {
+			t.Errorf("Incorrect csv error log wanted %s in %q", filename, errOut.String())
+		}
+	}
+}
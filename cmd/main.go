@@ -11,6 +11,19 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "csv" {
+		if err := writeCSV(os.Stdout, os.Stderr, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "shift" {
+		if err := shiftFiles(os.Stderr, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	f, err := os.Open("../testImages/Heic.exif")
 	if err != nil {
 		log.Fatal(err)
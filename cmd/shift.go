@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/evanoberholster/imagemeta"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// Modification times of shifted files set with the -mtime flag.
+const (
+	mtimeNow      = ""         // time of the shift
+	mtimeKeep     = "keep"     // modification time of the file
+	mtimeOriginal = "original" // shifted DateTimeOriginal in local time
+)
+
+// shiftFiles shifts the date and time values of each file by the duration
+// of the -by flag. Files are replaced with meta.SafeWrite after they are
+// written completely. Files that can not be shifted are logged to logw and skipped.
+//
+//	imagemeta shift -by <duration> [-mtime keep|original] <files...>
+func shiftFiles(logw io.Writer, args []string) error {
+	fs := flag.NewFlagSet("shift", flag.ContinueOnError)
+	fs.SetOutput(logw)
+	by := fs.Duration("by", 0, "duration to shift the date and time values by, e.g. -1h30m")
+	mtime := fs.String("mtime", mtimeNow, "modification time of shifted files: keep or original (DateTimeOriginal)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *by == 0 {
+		return errors.New("shift: -by duration is required")
+	}
+	switch *mtime {
+	case mtimeNow, mtimeKeep, mtimeOriginal:
+	default:
+		return fmt.Errorf("shift: -mtime %q is not keep or original", *mtime)
+	}
+	for _, filename := range fs.Args() {
+		if err := shiftFile(filename, *by, *mtime); err != nil {
+			fmt.Fprintf(logw, "%s: %v\n", filename, err)
+		}
+	}
+	return nil
+}
+
+// shiftFile shifts the date and time values of filename by d and sets
+// the modification time of filename according to mtime.
+func shiftFile(filename string, d time.Duration, mtime string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	opts := meta.SafeWriteOptions{PreserveModTime: mtime == mtimeKeep}
+	if mtime == mtimeOriginal {
+		m, err := imagemeta.Parse(f)
+		if err != nil {
+			return err
+		}
+		e, err := m.Exif()
+		if err != nil {
+			return err
+		}
+		dt, err := e.DateTime(time.Local)
+		if err != nil {
+			return err
+		}
+		opts.ModTime = dt.Add(d)
+		if _, err = f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	return meta.SafeWrite(filename, func(w io.Writer) error {
+		return imagemeta.ShiftTime(f, w, d)
+	}, opts)
+}
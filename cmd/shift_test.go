@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/jpeg"
+)
+
+func TestShiftFiles(t *testing.T) {
+	src, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "JPEG.jpg")
+	if err = os.WriteFile(filename, src, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing.jpg")
+
+	var errOut bytes.Buffer
+	if err = shiftFiles(&errOut, []string{"-by", "-2h", filename, missing}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(errOut.String(), missing) {
+		t.Errorf("Incorrect shift error log wanted %s in %q", missing, errOut.String())
+	}
+
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := jpeg.ScanJPEG(bytes.NewReader(buf), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := exif.ParseExif(bytes.NewReader(buf), m.ExifHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := time.Date(2016, 10, 11, 14, 59, 50, 0, time.UTC)
+	if dt, err := e.DateTime(time.UTC); err != nil || !dt.Equal(wanted) {
+		t.Errorf("Incorrect DateTime wanted %v got %v (%v)", wanted, dt, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Incorrect number of files wanted %d got %d", 1, len(entries))
+	}
+
+	if err = shiftFiles(&errOut, []string{filename}); err == nil {
+		t.Errorf("shift without -by should return an error")
+	}
+	if err = shiftFiles(&errOut, []string{"-by", "1h", "-mtime", "exif", filename}); err == nil {
+		t.Errorf("shift with an invalid -mtime should return an error")
+	}
+
+	// -mtime original sets the modification time to the shifted DateTimeOriginal
+	if err = shiftFiles(&errOut, []string{"-by", "2h", "-mtime", "original", filename}); err != nil {
+		t.Fatal(err)
+	}
+	wanted = time.Date(2016, 10, 11, 16, 59, 50, 0, time.Local)
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(wanted) {
+		t.Errorf("Incorrect modification time wanted %v got %v", wanted, fi.ModTime())
+	}
+
+	// -mtime keep keeps the modification time
+	if err = shiftFiles(&errOut, []string{"-by", "1h", "-mtime", "keep", filename}); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err = os.Stat(filename); err != nil {
+		t.Fatal(err)
+	}
+	if !fi.ModTime().Equal(wanted) {
+		t.Errorf("Incorrect modification time wanted %v got %v", wanted, fi.ModTime())
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Errorf("Incorrect file mode wanted %v got %v", os.FileMode(0o600), fi.Mode().Perm())
+	}
+}
@@ -0,0 +1,110 @@
+package imagemeta
+
+import (
+	"errors"
+	"io"
+	"math"
+	"reflect"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// CopyMetadata copies the Exif, XMP and ICC profile of the image in src into
+// the JPEG in dst and writes the result to w. The image data of dst is not re-encoded.
+//
+// The Exif of src is encoded again in the byte order of src so that its offsets
+// are valid in the new Exif segment, see exif.NewBuilderFromData for how MakerNotes
+// are copied. MakerNotes that do not fit in the Exif segment of a JPEG and the tags
+// that point to image data in src are not copied. The XMP and ICC profile embedded
+// in the Exif of Tiff based images are written to their own JPEG segments.
+// Metadata that src does not have is kept from dst.
+//
+// Returns ErrMetadataNotSupported if the image type of src is not supported
+// and jpeg.ErrSegmentTooLarge if the metadata of src does not fit in the JPEG segments.
+func CopyMetadata(src meta.Reader, dst io.Reader, w io.Writer) error {
+	opts, err := readMetadata(src)
+	if err != nil {
+		return err
+	}
+	return jpeg.Rewrite(dst, w, opts)
+}
+
+// readMetadata reads the Exif, XMP and ICC profile of src as JPEG segment data.
+func readMetadata(src meta.Reader) (opts jpeg.RewriteOptions, err error) {
+	m, err := Parse(src)
+	if err != nil && !errors.Is(err, ErrNoExif) {
+		return opts, err
+	}
+	if m == nil {
+		return opts, ErrMetadataNotSupported
+	}
+
+	if err == nil {
+		ex, err := m.Exif()
+		if err != nil && !errors.Is(err, ErrNoExif) {
+			return opts, err
+		}
+		if e, ok := ex.(*exif.Data); ok && e != nil {
+			if opts, err = readExifMetadata(e); err != nil {
+				return opts, err
+			}
+		}
+	}
+
+	if jm, ok := m.(jpeg.Metadata); ok {
+		if opts.XMP == nil && jm.XmpHeader.Length > 0 {
+			opts.XMP = make([]byte, jm.XmpHeader.Length)
+			if _, err = src.ReadAt(opts.XMP, int64(jm.XmpHeader.Offset)); err != nil {
+				return opts, err
+			}
+		}
+		if opts.ICC == nil {
+			opts.ICC, err = jpeg.ReadICCProfile(io.NewSectionReader(src, 0, math.MaxInt64))
+			if err != nil && err != jpeg.ErrNoICCProfile {
+				return opts, err
+			}
+		}
+	}
+
+	if opts.XMP == nil {
+		if x, err := m.Xmp(); err == nil && !reflect.DeepEqual(x, xmp.XMP{}) {
+			if opts.XMP, err = xmp.Marshal(x); err != nil {
+				return opts, err
+			}
+		}
+	}
+	return opts, nil
+}
+
+// readExifMetadata returns the Exif of e encoded as a Tiff/Exif block and the XMP and
+// ICC profile embedded in IFD0.
+func readExifMetadata(e *exif.Data) (opts jpeg.RewriteOptions, err error) {
+	b, err := exif.NewBuilderFromData(e)
+	if err != nil {
+		return opts, err
+	}
+	if t, err := e.GetTag(ifds.IFD0, 0, ifds.XMLPacket); err == nil {
+		if opts.XMP, err = e.RawTagBytes(t); err != nil {
+			return opts, err
+		}
+		b.RemoveTag(ifds.IFD0, 0, ifds.XMLPacket)
+	}
+	if t, err := e.GetTag(ifds.IFD0, 0, ifds.InterColorProfile); err == nil {
+		if opts.ICC, err = e.RawTagBytes(t); err != nil {
+			return opts, err
+		}
+		b.RemoveTag(ifds.IFD0, 0, ifds.InterColorProfile)
+	}
+	opts.Exif, err = b.Encode()
+	if err == nil && len(opts.Exif) > jpeg.MaxExifLength && b.HasTag(ifds.ExifIFD, 0, exififd.MakerNote) {
+		// MakerNotes with preview images do not fit in a JPEG segment
+		b.RemoveTag(ifds.ExifIFD, 0, exififd.MakerNote)
+		opts.Exif, err = b.Encode()
+	}
+	return opts, err
+}
@@ -0,0 +1,116 @@
+package imagemeta
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyMetadata(t *testing.T) {
+	dst, err := os.ReadFile("assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stripped bytes.Buffer
+	if err = jpeg.Strip(bytes.NewReader(dst), &stripped, jpeg.StripOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filename string
+		xmp      bool
+		icc      bool
+		mknote   bool
+	}{
+		{"testImages/JPEG.jpg", true, true, false},
+		{"testImages/CR2.exif", false, false, true},
+		{"testImages/NEF.exif", false, false, false},
+	}
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			src, err := os.Open(test.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer src.Close()
+
+			var out bytes.Buffer
+			if err = CopyMetadata(src, bytes.NewReader(stripped.Bytes()), &out); err != nil {
+				t.Fatal(err)
+			}
+			m, err := Parse(bytes.NewReader(src2Bytes(t, test.filename)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			jm, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := exif.ParseExif(bytes.NewReader(out.Bytes()), jm.ExifHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, want.CameraMake(), got.CameraMake())
+			assert.Equal(t, want.CameraModel(), got.CameraModel())
+			wantTime, _ := want.DateTime(nil)
+			gotTime, err := got.DateTime(nil)
+			assert.NoError(t, err)
+			assert.Equal(t, wantTime, gotTime)
+
+			// The Nikon MakerNote with its preview image does not fit in the Exif segment
+			if test.mknote {
+				wantSettings, _ := want.CanonCameraSettings()
+				gotSettings, err := got.CanonCameraSettings()
+				assert.NoError(t, err)
+				assert.Equal(t, wantSettings, gotSettings)
+			}
+
+			x, err := jm.Xmp()
+			if test.xmp {
+				wantXmp, _ := m.Xmp()
+				assert.NoError(t, err)
+				assert.Equal(t, wantXmp, x)
+			} else {
+				assert.Equal(t, xmp.XMP{}, x)
+			}
+
+			_, err = jpeg.ReadICCProfile(bytes.NewReader(out.Bytes()))
+			if test.icc {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, jpeg.ErrNoICCProfile)
+			}
+		})
+	}
+}
+
+func TestCopyMetadataNotSupported(t *testing.T) {
+	src, err := os.Open("testImages/CRW.CRW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	var out bytes.Buffer
+	if err = CopyMetadata(src, bytes.NewReader(nil), &out); err != ErrMetadataNotSupported {
+		t.Errorf("Incorrect error wanted %v got %v", ErrMetadataNotSupported, err)
+	}
+}
+
+func src2Bytes(t *testing.T, filename string) []byte {
+	t.Helper()
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
@@ -0,0 +1,200 @@
+// Package cr2 decodes (CR2) Canon Raw 2 Metadata. CR2 is a Tiff based format with
+// a Canon header that follows the Tiff header.
+//
+// The IFD0 chain of a CR2 has four Ifds: the JPEG preview (IFD0), the JPEG thumbnail (IFD1),
+// an uncompressed RGB image (IFD2) and the raw image (IFD3).
+//
+// Based on: Laurent Clévy's work on Canon CR2 file structure (http://lclevy.free.fr/cr2/)
+package cr2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/meta/canon"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoCR2Header = errors.New("no CR2 Header")
+	ErrNoPreview   = exif.ErrNoPreview
+	ErrNoRawImage  = errors.New("error CR2 does not have a raw image")
+)
+
+// Ifds of the IFD0 chain
+const (
+	previewIndex = 0
+	rawIndex     = 3
+)
+
+// tagCR2Slice is the Canon tag of the raw Ifd with the number and widths of the
+// vertical slices of the raw image.
+const tagCR2Slice tag.ID = 0xc640
+
+// headerLength is the length of the Tiff header and CR2 header
+const headerLength = 16
+
+// RawImage is the location of the lossless JPEG raw image of a CR2.
+//
+// The raw image is divided into Slices[0] slices of Slices[1] pixels wide,
+// followed by a last slice of Slices[2] pixels wide.
+type RawImage struct {
+	Offset uint32
+	Length uint32
+	Slices [3]uint16
+}
+
+// Metadata is a CR2 file's Metadata
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+
+	// Version is the major and minor version of the CR2 format
+	Version [2]uint8
+
+	// RawIfdOffset is the offset of the raw Ifd (IFD3) from the CR2 header
+	RawIfdOffset uint32
+
+	e *exif.Data
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return m.e.Dimensions()
+}
+
+// ImageType returns imagetype.ImageCR2 for Canon CR2 image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageCR2
+}
+
+// PreviewImage returns the largest JPEG preview image. Returns the
+// CR2 file if it does not have a JPEG preview.
+func (m Metadata) PreviewImage() io.Reader {
+	if p, err := m.LargestPreview(); err == nil {
+		return io.NewSectionReader(m.mr, int64(m.ExifHeader.TiffHeaderOffset+p.Offset), int64(p.Length))
+	}
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from CR2
+func (m Metadata) Exif() (exif.Exif, error) {
+	return m.e, nil
+}
+
+// Xmp returns parsed Xmp data from the "IFD" XMLPacket of a CR2.
+// Returns xmp.ErrNoXMP if the CR2 does not have XMP metadata.
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	t, err := m.e.GetTag(ifds.IFD0, previewIndex, ifds.XMLPacket)
+	if err != nil {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	buf, err := m.e.RawTagBytes(t)
+	if err != nil {
+		return xmp.XMP{}, err
+	}
+	return xmp.ParseXmp(bytes.NewReader(buf))
+}
+
+// SensorInfo returns the sensor dimensions and borders from the Canon Makernote
+func (m Metadata) SensorInfo() (canon.SensorInfo, error) {
+	return m.e.CanonSensorInfo()
+}
+
+// Previews returns the JPEG preview of IFD0 followed by the JPEG thumbnail of IFD1.
+func (m Metadata) Previews() []exif.Preview {
+	var previews []exif.Preview
+	if p, ok := m.preview(); ok {
+		previews = append(previews, p)
+	}
+	return append(previews, m.e.Previews()...)
+}
+
+// LargestPreview returns the JPEG preview with the largest dimensions, the
+// JPEG preview of IFD0 for most CR2 files.
+//
+// Returns ErrNoPreview if the CR2 does not have a JPEG preview.
+func (m Metadata) LargestPreview() (p exif.Preview, err error) {
+	previews := m.Previews()
+	if len(previews) == 0 {
+		return p, ErrNoPreview
+	}
+	p = previews[0]
+	for _, pp := range previews[1:] {
+		if uint64(pp.Width)*uint64(pp.Height) > uint64(p.Width)*uint64(p.Height) {
+			p = pp
+		}
+	}
+	return p, nil
+}
+
+// RawImage returns the location of the raw image of IFD3.
+//
+// Returns ErrNoRawImage if the CR2 does not have a raw Ifd.
+func (m Metadata) RawImage() (raw RawImage, err error) {
+	var ok bool
+	if raw.Offset, ok = m.uint32Value(rawIndex, ifds.StripOffsets); !ok {
+		return raw, ErrNoRawImage
+	}
+	raw.Length, _ = m.uint32Value(rawIndex, ifds.StripByteCounts)
+	if t, err := m.e.GetTag(ifds.IFD0, rawIndex, tagCR2Slice); err == nil {
+		if slices, err := m.e.ParseUint16Values(t); err == nil && len(slices) == 3 {
+			copy(raw.Slices[:], slices)
+		}
+	}
+	return raw, nil
+}
+
+// preview returns the JPEG preview of IFD0, a single strip with JPEG compression.
+func (m Metadata) preview() (p exif.Preview, ok bool) {
+	p = exif.Preview{Ifd: ifds.IFD0, Index: previewIndex}
+	p.Width, _ = m.uint32Value(previewIndex, ifds.ImageWidth)
+	p.Height, _ = m.uint32Value(previewIndex, ifds.ImageLength)
+	if p.Offset, ok = m.uint32Value(previewIndex, ifds.StripOffsets); !ok {
+		return p, false
+	}
+	p.Length, _ = m.uint32Value(previewIndex, ifds.StripByteCounts)
+	return p, p.Length > 0
+}
+
+func (m Metadata) uint32Value(index uint8, id tag.ID) (uint32, bool) {
+	t, err := m.e.GetTag(ifds.IFD0, index, id)
+	if err != nil {
+		return 0, false
+	}
+	v, err := m.e.ParseUint32Value(t)
+	return v, err == nil
+}
+
+// Parse reads the Tiff and CR2 headers and the Exif metadata of a CR2 from mr.
+// Returns Metadata.
+//
+// Returns the error ErrNoCR2Header if mr does not begin with a CR2 header.
+func Parse(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if _, err = mr.ReadAt(buf[:], 0); err != nil {
+		return m, ErrNoCR2Header
+	}
+	byteOrder := meta.BinaryOrder(buf[:])
+	if byteOrder == nil || buf[8] != 'C' || buf[9] != 'R' {
+		return m, ErrNoCR2Header
+	}
+	m.Version = [2]uint8{buf[10], buf[11]}
+	m.RawIfdOffset = byteOrder.Uint32(buf[12:16])
+	m.ExifHeader = meta.NewExifHeader(byteOrder, byteOrder.Uint32(buf[4:8]), 0, 0, imagetype.ImageCR2)
+
+	if m.e, err = exif.ParseExif(mr, m.ExifHeader); err != nil {
+		return m, err
+	}
+	return m, nil
+}
@@ -0,0 +1,93 @@
+package cr2
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/meta/canon"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("../testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ImageType() != imagetype.ImageCR2 || m.Dimensions() != meta.NewDimensions(5616, 3744) {
+		t.Errorf("Incorrect Metadata got %s %s", m.ImageType(), m.Dimensions())
+	}
+	if m.Version != [2]uint8{2, 0} || m.RawIfdOffset != 0x8eea {
+		t.Errorf("Incorrect CR2 Header got version %v raw ifd offset 0x%04x", m.Version, m.RawIfdOffset)
+	}
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraModel() != "Canon EOS-1Ds Mark III" {
+		t.Errorf("Incorrect camera model got %s", e.CameraModel())
+	}
+
+	// Sensor
+	si, err := m.SensorInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSensor := canon.SensorInfo{Width: 5712, Height: 3774, LeftBorder: 72, TopBorder: 25, RightBorder: 5687, BottomBorder: 3768}
+	if si != wantSensor || si.ImageWidth() != 5616 || si.ImageHeight() != 3744 {
+		t.Errorf("Incorrect SensorInfo wanted %+v got %+v", wantSensor, si)
+	}
+
+	// Previews
+	previews := m.Previews()
+	if len(previews) != 2 || previews[1].Ifd != ifds.IFD0 || previews[1].Index != 1 || previews[1].Length != 5622 {
+		t.Fatalf("Incorrect Previews got %+v", previews)
+	}
+	p, err := m.LargestPreview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Index != 0 || p.Width != 2784 || p.Height != 1856 || p.Offset != 42308 || p.Length != 296899 {
+		t.Errorf("Incorrect LargestPreview got %+v", p)
+	}
+	buf, err := io.ReadAll(m.PreviewImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buf) != 296899 || !bytes.HasPrefix(buf, []byte{0xFF, 0xD8}) {
+		t.Errorf("Incorrect PreviewImage got %d bytes", len(buf))
+	}
+
+	// Raw Image
+	raw, err := m.RawImage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Offset != 1232136 || raw.Length != 19627632 || raw.Slices != [3]uint16{2, 1920, 1872} {
+		t.Errorf("Incorrect RawImage got %+v", raw)
+	}
+
+	if _, err = m.Xmp(); err != xmp.ErrNoXMP {
+		t.Errorf("Incorrect error wanted %v got %v", xmp.ErrNoXMP, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	// Tiff without a CR2 header
+	buf := []byte{'I', 'I', 0x2a, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if _, err := Parse(bytes.NewReader(buf)); err != ErrNoCR2Header {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoCR2Header, err)
+	}
+	if _, err := Parse(bytes.NewReader(buf[:8])); err != ErrNoCR2Header {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoCR2Header, err)
+	}
+}
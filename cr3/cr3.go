@@ -1,10 +1,11 @@
 // Package cr3 decodes (CR3) Canon Raw 3 Metadata using the bmff package
 //
+// Canon (CRM) raw movies have the same structure and are decoded as well.
+//
 // Based on: Laurent Clévy's work on Canon CR3 file structure found at (@Lorenzo2472) (https://github.com/lclevy/canon_cr3)
 package cr3
 
 import (
-	"fmt"
 	"io"
 
 	"github.com/evanoberholster/imagemeta/bmff"
@@ -15,7 +16,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-// Metadata is an Heic file's Metadata
+// Errors
+var (
+	ErrNoExif = meta.ErrNoExif
+)
+
+// Metadata is a CR3 or CRM file's Metadata
 type Metadata struct {
 	mr         meta.Reader
 	ExifHeader meta.ExifHeader
@@ -28,22 +34,68 @@ type Metadata struct {
 }
 
 // Dimensions returns the dimensions (width and height) of the image
+// When there is no Exif data the dimensions of the largest raw track are returned.
 func (m Metadata) Dimensions() meta.Dimensions {
-	return m.e.Dimensions()
+	if m.e != nil {
+		return m.e.Dimensions()
+	}
+	var width, height uint16
+	for _, t := range m.CrxMoov.Trak {
+		if uint32(t.Width)*uint32(t.Height) > uint32(width)*uint32(height) {
+			width, height = t.Width, t.Height
+		}
+	}
+	return meta.NewDimensions(uint32(width), uint32(height))
 }
 
-// ImageType returns imagetype.ImageCR3 for Canon CR3 image
+// ImageType returns imagetype.ImageCR3 for Canon CR3 image and Canon CRM movie
 func (m Metadata) ImageType() imagetype.ImageType {
 	return imagetype.ImageCR3
 }
 
+// IsMovie returns true for a Canon CRM raw movie.
+func (m Metadata) IsMovie() bool {
+	return m.CrxMoov.IsMovie()
+}
+
+// Tracks returns the metadata of each trak in the moov box.
+func (m Metadata) Tracks() []bmff.CR3Trak {
+	return m.CrxMoov.Trak
+}
+
 // PreviewImage returns a JPEG preview image
+// from the first trak. Returns nil when there is no preview image.
 func (m Metadata) PreviewImage() io.Reader {
+	if len(m.CrxMoov.Trak) == 0 || m.CrxMoov.Trak[0].ImageSize == 0 {
+		return nil
+	}
 	return io.NewSectionReader(m.mr, int64(m.CrxMoov.Trak[0].Offset), int64(m.CrxMoov.Trak[0].ImageSize))
 }
 
+// Previews returns the JPEG previews of the CR3, largest first: the full size
+// preview of the first trak, the PRVW preview (1620x1080) of the uuid box found
+// with the CTBO box and the THMB thumbnail (160x120) of the metadata box.
+// Previews that are missing are not returned.
+func (m Metadata) Previews() (previews []bmff.CR3Preview) {
+	if t := m.CrxMoov.Trak; len(t) > 0 && t[0].ImageSize > 0 {
+		previews = append(previews, bmff.CR3Preview{Width: t[0].Width, Height: t[0].Height, Offset: t[0].Offset, Length: t[0].ImageSize})
+	}
+	if offset, length := m.CrxMoov.Meta.PreviewData(); length > 0 {
+		if p, err := bmff.ReadCR3Preview(m.mr, offset, length); err == nil && p.Length > 0 {
+			previews = append(previews, p)
+		}
+	}
+	if p := m.CrxMoov.Meta.THMB; p.Length > 0 {
+		previews = append(previews, p)
+	}
+	return previews
+}
+
 // Exif returns parsed Exif data from CR3
 func (m Metadata) Exif() (exif.Exif, error) {
+	if m.e == nil {
+		return nil, ErrNoExif
+	}
 	return m.e, nil
 }
 
@@ -75,12 +127,11 @@ func (m *Metadata) getMeta() (err error) {
 	if err != nil {
 		return errors.Wrapf(err, "ReadCrxMoovBox")
 	}
-	for i, header := range m.CrxMoov.Meta.Exif {
-		if i == 0 {
-			m.e, err = exif.ParseExif(m.mr, header)
-			if err != nil {
-				fmt.Println(err)
-			}
+	// Each CMT box is parsed in order. The first one creates the
+	// Exif data, errors from the first scan are not fatal.
+	for _, header := range m.CrxMoov.Meta.Exif {
+		if m.e == nil {
+			m.e, _ = exif.ParseExif(m.mr, header)
 			continue
 		}
 		if err = m.e.ParseIfd(header); err != nil {
@@ -1 +1,167 @@
 package cr3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/bmff"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+func newBox(typ string, data ...[]byte) []byte {
+	buf := make([]byte, 8)
+	copy(buf[4:], typ)
+	for _, d := range data {
+		buf = append(buf, d...)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)))
+	return buf
+}
+
+func u32(v ...uint32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i := range v {
+		binary.BigEndian.PutUint32(buf[i*4:], v[i])
+	}
+	return buf
+}
+
+// newTiff returns a little endian tiff header with an IFD0 that has a Make tag.
+func newTiff(cameraMake string) []byte {
+	buf := []byte{'I', 'I', 0x2A, 0, 8, 0, 0, 0, 1, 0}
+	entry := newEntry(0x010F, 2, uint32(len(cameraMake)+1), 26)
+	buf = append(buf, entry...)
+	buf = append(buf, 0, 0, 0, 0)
+	return append(append(buf, cameraMake...), 0)
+}
+
+func newEntry(tag, typ uint16, count, offset uint32) []byte {
+	buf := make([]byte, 12)
+	binary.LittleEndian.PutUint16(buf[0:], tag)
+	binary.LittleEndian.PutUint16(buf[2:], typ)
+	binary.LittleEndian.PutUint32(buf[4:], count)
+	binary.LittleEndian.PutUint32(buf[8:], offset)
+	return buf
+}
+
+func newTrak(hdlr string, width, height uint16, stsz []byte) []byte {
+	return newTrakAt(hdlr, width, height, stsz, 16)
+}
+
+// newTrakAt returns a trak with the first sample at offset.
+func newTrakAt(hdlr string, width, height uint16, stsz []byte, offset uint32) []byte {
+	stsd := make([]byte, 88)
+	copy(stsd[4:], "CRAW")
+	binary.BigEndian.PutUint16(stsd[32:], width)
+	binary.BigEndian.PutUint16(stsd[34:], height)
+	return newBox("trak", newBox("mdia",
+		newBox("hdlr", u32(0, 0), []byte(hdlr), make([]byte, 12)),
+		newBox("minf", newBox("stbl",
+			newBox("stsd", u32(0, 1), stsd),
+			newBox("stsz", stsz),
+			newBox("co64", u32(0, 1, 0, offset))))))
+}
+
+func newCR3(cmt ...[]byte) []byte {
+	uuid, _ := bmff.CR3MetaBoxUUID.MarshalBinary()
+	ftyp := newBox("ftyp", []byte("crx "), u32(1), []byte("crx isom"))
+	return append(ftyp, newBox("moov", newBox("uuid", append([][]byte{uuid}, cmt...)...),
+		newTrak("vide", 160, 120, u32(0, 0, 1, 32)),
+		newTrak("vide", 6000, 4000, u32(0, 0, 4, 100, 100, 100, 100)),
+		newTrak("soun", 0, 0, u32(0, 4, 100)),
+		newTrak("meta", 0, 0, u32(0, 0, 4, 10, 10, 10, 10)),
+		newTrak("meta", 0, 0, u32(0, 0, 1, 20)))...)
+}
+
+func TestParseCRM(t *testing.T) {
+	m, err := Parse(bytes.NewReader(newCR3(newBox("CMT1", newTiff("Canon")))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.IsMovie() {
+		t.Errorf("Incorrect IsMovie wanted %t got %t", true, m.IsMovie())
+	}
+	if len(m.Tracks()) != 5 {
+		t.Errorf("Incorrect number of tracks wanted %d got %d", 5, len(m.Tracks()))
+	}
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraMake() != "Canon" {
+		t.Errorf("Incorrect CameraMake wanted %s got %s", "Canon", e.CameraMake())
+	}
+	if m.PreviewImage() == nil {
+		t.Errorf("Incorrect PreviewImage wanted a reader got nil")
+	}
+
+	// Without CMT boxes the dimensions are from the largest raw track
+	m, err = Parse(bytes.NewReader(newCR3()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Exif(); err != ErrNoExif {
+		t.Errorf("Incorrect Exif error wanted %v got %v", ErrNoExif, err)
+	}
+	if dim := m.Dimensions(); dim != meta.NewDimensions(6000, 4000) {
+		t.Errorf("Incorrect Dimensions wanted %s got %s", meta.NewDimensions(6000, 4000), dim)
+	}
+}
+
+// newCR3Previews returns a CR3 with a full size JPEG in the first trak, a PRVW
+// preview found with the CTBO box and a THMB thumbnail.
+func newCR3Previews(full, prvw, thmb []byte) []byte {
+	metaUUID, _ := bmff.CR3MetaBoxUUID.MarshalBinary()
+	prvwUUID, _ := bmff.CR3PreviewDataUUID.MarshalBinary()
+	ftyp := newBox("ftyp", []byte("crx "), u32(1), []byte("crx isom"))
+	prvwBox := newBox("uuid", prvwUUID, make([]byte, 8), newBox("PRVW", u32(0), []byte{0, 1, 0x06, 0x54, 0x04, 0x38, 0, 1}, u32(uint32(len(prvw))), prvw))
+	build := func(prvwOffset, fullOffset uint32) []byte {
+		moov := newBox("moov",
+			newBox("uuid", metaUUID,
+				newBox("THMB", u32(0), []byte{0, 160, 0, 120}, u32(uint32(len(thmb)), 0), thmb),
+				newBox("CTBO", u32(1, 2, 0, prvwOffset, 0, uint32(len(prvwBox))))),
+			newTrakAt("vide", 6000, 4000, u32(0, uint32(len(full)), 1), fullOffset))
+		buf := append(append([]byte{}, ftyp...), moov...)
+		return append(append(buf, prvwBox...), full...)
+	}
+	buf := build(0, 0)
+	return build(uint32(len(buf)-len(prvwBox)-len(full)), uint32(len(buf)-len(full)))
+}
+
+func TestPreviews(t *testing.T) {
+	full, prvw, thmb := []byte("full size jpeg"), []byte("prvw jpeg"), []byte("thmb jpeg")
+	buf := newCR3Previews(full, prvw, thmb)
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previews := m.Previews()
+	if len(previews) != 3 {
+		t.Fatalf("Incorrect number of previews wanted %d got %d", 3, len(previews))
+	}
+	for i, want := range []struct {
+		width, height uint16
+		data          []byte
+	}{
+		{6000, 4000, full},
+		{1620, 1080, prvw},
+		{160, 120, thmb},
+	} {
+		p := previews[i]
+		if p.Width != want.width || p.Height != want.height {
+			t.Errorf("Incorrect preview %d dimensions wanted %dx%d got %dx%d", i, want.width, want.height, p.Width, p.Height)
+		}
+		if data := buf[p.Offset : p.Offset+p.Length]; !bytes.Equal(data, want.data) {
+			t.Errorf("Incorrect preview %d data wanted %q got %q", i, want.data, data)
+		}
+	}
+
+	// Only the first trak has a preview
+	if m, err = Parse(bytes.NewReader(newCR3())); err != nil {
+		t.Fatal(err)
+	}
+	if previews = m.Previews(); len(previews) != 1 {
+		t.Errorf("Incorrect number of previews wanted %d got %d", 1, len(previews))
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/evanoberholster/imagemeta/exif/ifds"
 	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
 	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/iopifd"
 	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 	"github.com/golang/geo/s2"
 	"github.com/pkg/errors"
@@ -128,6 +130,49 @@ func (e *Data) LensSerial() (serial string, err error) {
 	return e.ParseASCIIValue(t)
 }
 
+// Lens convenience func. "IFD/Exif" LensSpecification
+// Falls back to "IFD" LensInfo (DNG) when LensSpecification is absent.
+// Returns the focal length range and the maximum aperture range of the lens.
+// Values that are missing or have a zero denominator are left as 0 (unknown).
+func (e *Data) Lens() (li LensInfo, err error) {
+	t, err := e.GetTag(ifds.ExifIFD, 0, exififd.LensSpecification)
+	if err != nil {
+		if t, err = e.GetTag(ifds.IFD0, 0, ifds.LensInfo); err != nil {
+			return
+		}
+	}
+	r, err := e.ParseRationalValues(t)
+	if err != nil {
+		return
+	}
+	for i := 0; i < len(r) && i < 4; i++ {
+		if r[i].Denominator == 0 {
+			continue
+		}
+		switch i {
+		case 0:
+			li.MinFocalLength = meta.NewFocalLength(r[i].Numerator, r[i].Denominator)
+		case 1:
+			li.MaxFocalLength = meta.NewFocalLength(r[i].Numerator, r[i].Denominator)
+		case 2:
+			li.MinFocalLengthAperture = meta.NewAperture(r[i].Numerator, r[i].Denominator)
+		case 3:
+			li.MaxFocalLengthAperture = meta.NewAperture(r[i].Numerator, r[i].Denominator)
+		}
+	}
+	return li, nil
+}
+
+// InteroperabilityIndex convenience func. "IFD/Exif/Iop" InteroperabilityIndex
+// Identifies the Interoperability rule ie. "R98" for DCF basic files and "THM" for DCF thumbnail files.
+func (e *Data) InteroperabilityIndex() (index string, err error) {
+	t, err := e.GetTag(ifds.IopIFD, 0, iopifd.InteroperabilityIndex)
+	if err != nil {
+		return
+	}
+	return e.ParseASCIIValue(t)
+}
+
 // ImageHeight retturns the main image height
 func (e *Data) ImageHeight() uint16 {
 	return e.height
@@ -138,6 +183,12 @@ func (e *Data) ImageWidth() uint16 {
 	return e.width
 }
 
+// ImageType returns the imagetype of the Exif. A Tiff is updated to a DNG by the
+// DNGVersion tag, or to a camera raw type by the camera's Makernote.
+func (e *Data) ImageType() imagetype.ImageType {
+	return e.imageType
+}
+
 // Dimensions convenience func. "IFD" Dimensions
 func (e *Data) Dimensions() (dimensions meta.Dimensions) {
 	if e.width > 0 && e.height > 0 {
@@ -168,6 +219,92 @@ func (e *Data) Dimensions() (dimensions meta.Dimensions) {
 	return meta.NewDimensions(0, 0)
 }
 
+// DisplayDimensions convenience func. "IFD/Exif" PixelXDimension and PixelYDimension
+// Falls back to the main image width and height when absent.
+// Returns the width and height as displayed, width and height are swapped
+// for orientations 5-8 (rotated 90 or 270 degrees). A missing "IFD" Orientation
+// is treated as OrientationHorizontal (1).
+func (e *Data) DisplayDimensions() (w, h uint16, err error) {
+	var t tag.Tag
+	if t, err = e.GetTag(ifds.ExifIFD, 0, exififd.PixelXDimension); err == nil {
+		if w, err = e.ParseUint16Value(t); err == nil {
+			if t, err = e.GetTag(ifds.ExifIFD, 0, exififd.PixelYDimension); err == nil {
+				h, err = e.ParseUint16Value(t)
+			}
+		}
+	}
+	if err != nil || w == 0 || h == 0 {
+		w, h = e.width, e.height
+	}
+	if w == 0 || h == 0 {
+		return 0, 0, ErrEmptyTag
+	}
+
+	switch e.Orientation() {
+	case meta.OrientationMirrorHorizontalRotate270, meta.OrientationRotate90,
+		meta.OrientationMirrorHorizontalRotate90, meta.OrientationRotate270:
+		return h, w, nil
+	}
+	return w, h, nil
+}
+
+// Resolution convenience func. "IFD" XResolution, YResolution and ResolutionUnit.
+// Returns the number of pixels per ResolutionUnit in the width and height.
+// A missing "IFD" ResolutionUnit is treated as ResolutionUnitInch (2).
+// Returns ErrEmptyTag if XResolution and YResolution are absent.
+// A resolution with a zero denominator is returned as 0.
+func (e *Data) Resolution() (xDPI, yDPI float64, unit ResolutionUnit, err error) {
+	xt, err := e.GetTag(ifds.IFD0, 0, ifds.XResolution)
+	if err != nil {
+		return
+	}
+	yt, err := e.GetTag(ifds.IFD0, 0, ifds.YResolution)
+	if err != nil {
+		return
+	}
+	if xDPI, err = e.parseResolution(xt); err != nil {
+		return
+	}
+	if yDPI, err = e.parseResolution(yt); err != nil {
+		return
+	}
+
+	unit = ResolutionUnitInch
+	if t, err := e.GetTag(ifds.IFD0, 0, ifds.ResolutionUnit); err == nil {
+		if u, err := e.ParseUint16Value(t); err == nil {
+			unit = ResolutionUnit(u)
+		}
+	}
+	return xDPI, yDPI, unit, nil
+}
+
+// DPI convenience func. "IFD" XResolution, YResolution and ResolutionUnit.
+// Returns the Resolution normalized to dots per inch. Resolutions in
+// ResolutionUnitCentimeter are converted to inches, other units are returned as is.
+func (e *Data) DPI() (xDPI, yDPI float64, err error) {
+	xDPI, yDPI, unit, err := e.Resolution()
+	if err != nil {
+		return 0, 0, err
+	}
+	if unit == ResolutionUnitCentimeter {
+		xDPI *= centimetersPerInch
+		yDPI *= centimetersPerInch
+	}
+	return xDPI, yDPI, nil
+}
+
+// parseResolution parses a rational resolution. A zero denominator returns 0.
+func (e *Data) parseResolution(t tag.Tag) (float64, error) {
+	n, d, err := e.ParseRationalValue(t)
+	if err != nil {
+		return 0, err
+	}
+	if d == 0 {
+		return 0, nil
+	}
+	return float64(n) / float64(d), nil
+}
+
 // ExposureProgram convenience func. "IFD/Exif" ExposureProgram
 func (e *Data) ExposureProgram() (meta.ExposureProgram, error) {
 	t, err := e.GetTag(ifds.ExifIFD, 0, exififd.ExposureProgram)
@@ -419,7 +556,7 @@ func (e *Data) GPSAltitude() (alt float32, err error) {
 
 	t, err = e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSAltitudeRef)
 	if t.IsType(tag.TypeByte) && t.IsEmbedded() {
-		e.reader.byteOrder.PutUint32(e.reader.rawBuffer[:4], t.ValueOffset)
+		e.reader.byteOrder.PutUint32(e.reader.rawBuffer[:4], uint32(t.ValueOffset))
 		if e.reader.rawBuffer[0] == 1 {
 			alt *= -1
 		}
@@ -428,6 +565,62 @@ func (e *Data) GPSAltitude() (alt float32, err error) {
 	return alt, err
 }
 
+// GPSDOP convenience func. "IFD/GPS" GPSDOP
+// The degree of precision (Dilution of Precision) of the GPS measurement.
+func (e *Data) GPSDOP() (dop float64, err error) {
+	t, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSDOP)
+	if err != nil {
+		return
+	}
+	n, d, err := e.ParseRationalValue(t)
+	if err != nil || d == 0 {
+		return
+	}
+	return float64(n) / float64(d), nil
+}
+
+// GPSSatellites convenience func. "IFD/GPS" GPSSatellites
+// The satellites used for the GPS measurement.
+func (e *Data) GPSSatellites() (satellites string, err error) {
+	t, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSSatellites)
+	if err != nil {
+		return
+	}
+	return e.ParseASCIIValue(t)
+}
+
+// GPSProcessingMethod convenience func. "IFD/GPS" GPSProcessingMethod
+// The name of the method used for location finding (ie. "GPS", "NETWORK").
+// The character code prefix is removed.
+func (e *Data) GPSProcessingMethod() (method string, err error) {
+	t, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSProcessingMethod)
+	if err != nil {
+		return
+	}
+	return e.ParseEncodedString(t)
+}
+
+// GPSImgDirection convenience func. for "IFD/GPS" GPSImgDirection and GPSImgDirectionRef.
+// The direction of the image in degrees from 0 to 359.99. The reference is
+// "T" for true direction or "M" for magnetic direction, and empty if not present.
+func (e *Data) GPSImgDirection() (direction float64, ref string, err error) {
+	t, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSImgDirection)
+	if err != nil {
+		return
+	}
+	n, d, err := e.ParseRationalValue(t)
+	if err != nil {
+		return
+	}
+	if d != 0 {
+		direction = float64(n) / float64(d)
+	}
+	if t, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSImgDirectionRef); err == nil {
+		ref, _ = e.ParseASCIIValue(t)
+	}
+	return direction, ref, nil
+}
+
 // GPSCellID returns the S2 cellID of the geographic location on the earth.
 // A convenience func. that retrieves "IFD/GPS" GPSLatitude and GPSLongitude
 // and converts them into an S2 CellID and returns the CellID.
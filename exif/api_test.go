@@ -0,0 +1,269 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/iopifd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLens(t *testing.T) {
+	lensTests := []struct {
+		name   string
+		ifd    ifds.IfdType
+		tagID  tag.ID
+		buf    []byte
+		count  uint32
+		lens   LensInfo
+		err    error
+		noTags bool
+	}{
+		{"LensSpecification", ifds.ExifIFD, exififd.LensSpecification, []byte{0, 0, 0, 24, 0, 0, 0, 1, 0, 0, 0, 105, 0, 0, 0, 1, 0, 0, 0, 40, 0, 0, 0, 10, 0, 0, 0, 40, 0, 0, 0, 10}, 4, LensInfo{24, 105, 4, 4}, nil, false},
+		{"LensInfo", ifds.IFD0, ifds.LensInfo, []byte{0, 0, 0, 50, 0, 0, 0, 1, 0, 0, 0, 50, 0, 0, 0, 1, 0, 0, 0, 18, 0, 0, 0, 10, 0, 0, 0, 18, 0, 0, 0, 10}, 4, LensInfo{50, 50, 1.8, 1.8}, nil, false},
+		{"ZeroDenominator", ifds.ExifIFD, exififd.LensSpecification, []byte{0, 0, 0, 70, 0, 0, 0, 1, 0, 0, 0, 200, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 28, 0, 0, 0, 10}, 4, LensInfo{70, 200, 0, 2.8}, nil, false},
+		{"Partial", ifds.ExifIFD, exififd.LensSpecification, []byte{0, 0, 0, 35, 0, 0, 0, 1, 0, 0, 0, 35, 0, 0, 0, 1}, 2, LensInfo{35, 35, 0, 0}, nil, false},
+		{"Missing", ifds.ExifIFD, exififd.LensSpecification, nil, 0, LensInfo{}, ErrEmptyTag, true},
+	}
+
+	for _, lt := range lensTests {
+		t.Run(lt.name, func(t *testing.T) {
+			e := newData(newMockReader(lt.buf), imagetype.ImageUnknown)
+			if !lt.noTags {
+				tg, err := tag.NewTag(lt.tagID, tag.TypeRational, lt.count, 0, uint8(ifds.IFD0))
+				if err != nil {
+					t.Fatal(err)
+				}
+				e.tagMap[ifds.NewKey(lt.ifd, 0, lt.tagID)] = tg
+			}
+			li, err := e.Lens()
+			assert.ErrorIs(t, err, lt.err)
+			assert.InDelta(t, float64(lt.lens.MinFocalLength), float64(li.MinFocalLength), 0.001)
+			assert.InDelta(t, float64(lt.lens.MaxFocalLength), float64(li.MaxFocalLength), 0.001)
+			assert.InDelta(t, float64(lt.lens.MinFocalLengthAperture), float64(li.MinFocalLengthAperture), 0.001)
+			assert.InDelta(t, float64(lt.lens.MaxFocalLengthAperture), float64(li.MaxFocalLengthAperture), 0.001)
+		})
+	}
+}
+
+func TestInteroperabilityIndex(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseExif(bytes.NewReader(buf), meta.NewExifHeader(binary.LittleEndian, 16, 0, 0, imagetype.ImageCR2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := e.InteroperabilityIndex()
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, "R98", index)
+
+	found := false
+	for ta := range e.RangeTags() {
+		if ifds.IfdType(ta.Ifd) == ifds.IopIFD && ta.ID == iopifd.InteroperabilityVersion {
+			found = true
+		}
+	}
+	assert.True(t, found, "InteroperabilityVersion in RangeTags")
+}
+
+func TestDisplayDimensions(t *testing.T) {
+	buf := []byte{0, 0, 0, 0}
+	dimTests := []struct {
+		name        string
+		orientation uint32
+		pixelX      uint32
+		pixelY      uint32
+		width       uint16
+		height      uint16
+		w           uint16
+		h           uint16
+		err         error
+	}{
+		{"NoOrientation", 0, 400, 300, 0, 0, 400, 300, nil},
+		{"Horizontal", 1, 400, 300, 0, 0, 400, 300, nil},
+		{"Rotate180", 3, 400, 300, 0, 0, 400, 300, nil},
+		{"MirrorHorizontalRotate270", 5, 400, 300, 0, 0, 300, 400, nil},
+		{"Rotate90", 6, 400, 300, 0, 0, 300, 400, nil},
+		{"Rotate270", 8, 400, 300, 0, 0, 300, 400, nil},
+		{"Fallback", 6, 0, 0, 640, 480, 480, 640, nil},
+		{"Empty", 1, 0, 0, 0, 0, 0, 0, ErrEmptyTag},
+	}
+	for _, dt := range dimTests {
+		t.Run(dt.name, func(t *testing.T) {
+			e := newData(newMockReader(buf), imagetype.ImageUnknown)
+			e.width, e.height = dt.width, dt.height
+			if dt.orientation > 0 {
+				tg, _ := tag.NewTag(ifds.Orientation, tag.TypeShort, 1, dt.orientation<<16, uint8(ifds.IFD0))
+				e.tagMap[ifds.NewKey(ifds.IFD0, 0, ifds.Orientation)] = tg
+			}
+			if dt.pixelX > 0 {
+				tg, _ := tag.NewTag(exififd.PixelXDimension, tag.TypeLong, 1, dt.pixelX, uint8(ifds.ExifIFD))
+				e.tagMap[ifds.NewKey(ifds.ExifIFD, 0, exififd.PixelXDimension)] = tg
+				tg, _ = tag.NewTag(exififd.PixelYDimension, tag.TypeLong, 1, dt.pixelY, uint8(ifds.ExifIFD))
+				e.tagMap[ifds.NewKey(ifds.ExifIFD, 0, exififd.PixelYDimension)] = tg
+			}
+			w, h, err := e.DisplayDimensions()
+			assert.ErrorIs(t, err, dt.err)
+			assert.Equal(t, dt.w, w)
+			assert.Equal(t, dt.h, h)
+		})
+	}
+}
+
+func TestResolution(t *testing.T) {
+	resolutionTests := []struct {
+		name       string
+		buf        []byte
+		unit       uint16
+		noUnit     bool
+		noTags     bool
+		x, y       float64
+		wantedUnit ResolutionUnit
+		dpiX, dpiY float64
+		err        error
+	}{
+		{"Inch", []byte{0, 0, 1, 44, 0, 0, 0, 1, 0, 0, 0, 72, 0, 0, 0, 1}, 2, false, false, 300, 72, ResolutionUnitInch, 300, 72, nil},
+		{"Centimeter", []byte{0, 0, 0, 118, 0, 0, 0, 1, 0, 0, 0, 59, 0, 0, 0, 2}, 3, false, false, 118, 29.5, ResolutionUnitCentimeter, 299.72, 74.93, nil},
+		{"None", []byte{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1}, 1, false, false, 1, 1, ResolutionUnitNone, 1, 1, nil},
+		{"DefaultUnit", []byte{0, 0, 0, 72, 0, 0, 0, 1, 0, 0, 0, 72, 0, 0, 0, 1}, 0, true, false, 72, 72, ResolutionUnitInch, 72, 72, nil},
+		{"ZeroDenominator", []byte{0, 0, 0, 72, 0, 0, 0, 0, 0, 0, 0, 72, 0, 0, 0, 1}, 2, false, false, 0, 72, ResolutionUnitInch, 0, 72, nil},
+		{"Missing", nil, 0, true, true, 0, 0, 0, 0, 0, ErrEmptyTag},
+	}
+
+	for _, rt := range resolutionTests {
+		t.Run(rt.name, func(t *testing.T) {
+			e := newData(newMockReader(rt.buf), imagetype.ImageUnknown)
+			if !rt.noTags {
+				xt, _ := tag.NewTag(ifds.XResolution, tag.TypeRational, 1, 0, uint8(ifds.IFD0))
+				yt, _ := tag.NewTag(ifds.YResolution, tag.TypeRational, 1, 8, uint8(ifds.IFD0))
+				e.tagMap[ifds.NewKey(ifds.IFD0, 0, ifds.XResolution)] = xt
+				e.tagMap[ifds.NewKey(ifds.IFD0, 0, ifds.YResolution)] = yt
+			}
+			if !rt.noUnit {
+				ut, _ := tag.NewTag(ifds.ResolutionUnit, tag.TypeShort, 1, uint32(rt.unit)<<16, uint8(ifds.IFD0))
+				e.tagMap[ifds.NewKey(ifds.IFD0, 0, ifds.ResolutionUnit)] = ut
+			}
+
+			x, y, unit, err := e.Resolution()
+			assert.ErrorIs(t, err, rt.err)
+			assert.InDelta(t, rt.x, x, 0.001)
+			assert.InDelta(t, rt.y, y, 0.001)
+			assert.Equal(t, rt.wantedUnit, unit)
+
+			dpiX, dpiY, err := e.DPI()
+			assert.ErrorIs(t, err, rt.err)
+			assert.InDelta(t, rt.dpiX, dpiX, 0.001)
+			assert.InDelta(t, rt.dpiY, dpiY, 0.001)
+		})
+	}
+}
+
+func TestExposureEnums(t *testing.T) {
+	e := newData(newMockReader(nil), imagetype.ImageUnknown)
+
+	// Absent tags
+	_, err := e.Flash()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+	_, err = e.MeteringMode()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+	_, err = e.ExposureProgram()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+
+	ft, _ := tag.NewTag(exififd.Flash, tag.TypeShort, 1, uint32(89)<<16, uint8(ifds.ExifIFD))
+	mt, _ := tag.NewTag(exififd.MeteringMode, tag.TypeShort, 1, uint32(5)<<16, uint8(ifds.ExifIFD))
+	pt, _ := tag.NewTag(exififd.ExposureProgram, tag.TypeShort, 1, uint32(3)<<16, uint8(ifds.ExifIFD))
+	e.tagMap[ifds.NewKey(ifds.ExifIFD, 0, exififd.Flash)] = ft
+	e.tagMap[ifds.NewKey(ifds.ExifIFD, 0, exififd.MeteringMode)] = mt
+	e.tagMap[ifds.NewKey(ifds.ExifIFD, 0, exififd.ExposureProgram)] = pt
+
+	f, err := e.Flash()
+	if assert.NoError(t, err) {
+		assert.Equal(t, meta.Flash(89), f)
+		assert.True(t, f.Fired())
+		assert.True(t, f.Redeye())
+		assert.Equal(t, meta.FlashModeAuto, f.Mode())
+		assert.Equal(t, "Auto, Fired, Red-eye reduction", f.String())
+	}
+	mm, err := e.MeteringMode()
+	if assert.NoError(t, err) {
+		assert.Equal(t, meta.MeteringMode(5), mm)
+		assert.Equal(t, "Multi-segment", mm.String())
+	}
+	ep, err := e.ExposureProgram()
+	if assert.NoError(t, err) {
+		assert.Equal(t, meta.ExposureProgram(3), ep)
+		assert.Equal(t, "Aperture-priority AE", ep.String())
+	}
+}
+
+func TestGPSFields(t *testing.T) {
+	buf := []byte{0, 0, 0, 25, 0, 0, 0, 10, 0, 0, 0x46, 0x9b, 0, 0, 0, 100}
+	methods := [][]byte{
+		append([]byte("ASCII\x00\x00\x00"), "GPS\x00"...),
+		append([]byte("UNICODE\x00"), 0, 'N', 0, 'E', 0, 'T', 0, 'W', 0, 'O', 0, 'R', 0, 'K', 0, 0),
+		append([]byte("UNICODE\x00"), 0xff, 0xfe, 'W', 0, 'L', 0, 'A', 0, 'N', 0, 0xe9, 0),
+		append(make([]byte, 8), "CELLID"...),
+		[]byte("fused"),
+	}
+	wanted := []string{"GPS", "NETWORK", "WLANé", "CELLID", "fused"}
+	offsets := make([]uint32, len(methods))
+	for i, m := range methods {
+		offsets[i] = uint32(len(buf))
+		buf = append(buf, m...)
+	}
+
+	e := newData(newMockReader(buf), imagetype.ImageUnknown)
+	addTag := func(id tag.ID, tt tag.Type, count, valueOffset uint32) {
+		ta, _ := tag.NewTag(id, tt, count, valueOffset, uint8(ifds.GPSIFD))
+		e.tagMap[ifds.NewKey(ifds.GPSIFD, 0, id)] = ta
+	}
+
+	// Absent tags
+	_, err := e.GPSDOP()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+	_, err = e.GPSSatellites()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+	_, err = e.GPSProcessingMethod()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+	_, _, err = e.GPSImgDirection()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+
+	addTag(gpsifd.GPSDOP, tag.TypeRational, 1, 0)
+	dop, err := e.GPSDOP()
+	assert.NoError(t, err)
+	assert.Equal(t, 2.5, dop)
+
+	addTag(gpsifd.GPSSatellites, tag.TypeASCII, 3, uint32('0')<<24|uint32('8')<<16)
+	sats, err := e.GPSSatellites()
+	assert.NoError(t, err)
+	assert.Equal(t, "08", sats)
+
+	addTag(gpsifd.GPSImgDirection, tag.TypeRational, 1, 8)
+	dir, ref, err := e.GPSImgDirection()
+	assert.NoError(t, err)
+	assert.InDelta(t, 180.75, dir, 0.001)
+	assert.Equal(t, "", ref)
+	addTag(gpsifd.GPSImgDirectionRef, tag.TypeASCII, 2, uint32('M')<<24)
+	_, ref, err = e.GPSImgDirection()
+	assert.NoError(t, err)
+	assert.Equal(t, "M", ref)
+
+	for i := range methods {
+		t.Run(wanted[i], func(t *testing.T) {
+			addTag(gpsifd.GPSProcessingMethod, tag.TypeUndefined, uint32(len(methods[i])), offsets[i])
+			method, err := e.GPSProcessingMethod()
+			assert.NoError(t, err)
+			assert.Equal(t, wanted[i], method)
+		})
+	}
+}
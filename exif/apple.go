@@ -0,0 +1,28 @@
+package exif
+
+import (
+	"strings"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
+)
+
+// isApple returns true if the camera make is Apple
+func (e *Data) isApple() bool {
+	return strings.EqualFold(e.make, "Apple")
+}
+
+// AppleContentIdentifier convenience func. "IFD/Exif/Makernotes.Apple" ContentIdentifier
+// The identifier that pairs the image of a Live Photo with its movie.
+//
+// Returns ErrEmptyTag if the Makernote does not have a ContentIdentifier.
+func (e *Data) AppleContentIdentifier() (string, error) {
+	if !e.isApple() {
+		return "", ErrEmptyTag
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.AppleContentIdentifier)
+	if err != nil {
+		return "", err
+	}
+	return e.ParseASCIIValue(t)
+}
@@ -0,0 +1,111 @@
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// Errors
+var (
+	ErrBigTiffOffset = errors.New("error BigTiff value exceeds 4GiB")
+)
+
+// BigTiff Read Lengths
+const (
+	bigTiffTagByteLength    = 20
+	bigTiffHeaderByteLength = 16
+	uint64ByteLength        = 8
+
+	// maxBigTiffTagCount is the largest number of entries read from a
+	// BigTiff Ifd. Tag IDs are 16-bit so larger Ifds are not valid.
+	maxBigTiffTagCount = math.MaxUint16
+)
+
+// isBigTiffIfd returns true if the ifd is in the BigTiff format.
+// Makernotes keep their own format within a BigTiff file.
+func (r *reader) isBigTiffIfd(ifd ifds.Ifd) bool {
+	return r.bigTiff && !ifd.IsType(ifds.MknoteIFD)
+}
+
+// ReadUint64 reads a uint64 offset or count from an ifdTagEnumerator.
+func (r *reader) ReadUint64(byteOrder binary.ByteOrder, offset uint64) (val uint64, off uint64, err error) {
+	buf, err := r.ReadBufferAt(uint64ByteLength, int64(offset))
+	if err != nil {
+		return 0, offset + uint64ByteLength, err
+	}
+	return byteOrder.Uint64(buf), offset + uint64ByteLength, nil
+}
+
+// ReadBigTiffTag reads the tagID uint16, tagType uint16, unitCount uint64 and valueOffset uint64
+// of a BigTiff Ifd entry. Returns Tag and error. If the tagType is unsupported, returns tag.ErrTagTypeNotValid.
+// Returns ErrBigTiffOffset if the unitCount does not fit in a uint32.
+//
+// Values of 8 bytes or less are stored within the entry. Values of 4 bytes or less
+// are embedded in the Tag.ValueOffset like a classic Tiff entry, otherwise the Tag.ValueOffset
+// is set to the position of the value within the entry.
+func (r *reader) ReadBigTiffTag(ifd ifds.Ifd, byteOrder binary.ByteOrder, offset uint64) (tag.Tag, uint64, error) {
+	buf, err := r.ReadBufferAt(bigTiffTagByteLength, int64(offset))
+	if err != nil {
+		return tag.Tag{}, offset, err
+	}
+	tagID := tag.ID(byteOrder.Uint16(buf[:2]))          // TagID
+	tagType := tag.Type(byteOrder.Uint16(buf[2:4]))     // TagType
+	unitCount := byteOrder.Uint64(buf[4:12])            // UnitCount
+	value := byteOrder.Uint64(buf[12:20])               // ValueOffset
+	valueOffset := uint64(byteOrder.Uint32(buf[12:16])) // Embedded Value
+	next := offset + bigTiffTagByteLength
+
+	if unitCount > math.MaxUint32 {
+		return tag.Tag{}, next, ErrBigTiffOffset
+	}
+
+	rawType := tagType
+	tagType = tagIsIfd(ifd, tagID, tagType)
+	if tagType.Is(tag.TypeIfd) && rawType.Size() == uint64ByteLength {
+		// 64-bit Ifd offsets
+		if unitCount == 1 {
+			valueOffset = value
+		} else {
+			tagType = tag.TypeIfd8
+		}
+	}
+
+	size := uint64(tagType.Size()) * unitCount
+	if size > 4 {
+		if size <= uint64ByteLength {
+			// Value stored within the entry
+			valueOffset = offset + 12 - r.ifdExifOffset[ifd.Type]
+		} else {
+			valueOffset = value
+		}
+	}
+
+	t, err := tag.NewTag(tagID, tagType, uint32(unitCount), 0, uint8(ifd.Type)) // NewTag
+	t.ValueOffset = valueOffset
+	return t, next, err
+}
+
+// parseIfd8Values parses the IFD8 value of the tag as a uint64 array
+// and returns an error if it encounters one.
+func (e *Data) parseIfd8Values(t tag.Tag) (value []uint64, err error) {
+	if !t.IsType(tag.TypeIfd8) {
+		return nil, tag.ErrTagTypeNotValid
+	}
+	var buf []byte
+	if buf, err = e.reader.ReadValue(t); err != nil {
+		return nil, err
+	}
+
+	byteOrder := e.reader.byteOrder
+	count := int(t.UnitCount)
+
+	value = make([]uint64, count)
+	for i := 0; i < count; i++ {
+		value[i] = byteOrder.Uint64(buf[i*8:])
+	}
+	return value, nil
+}
@@ -0,0 +1,140 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/stretchr/testify/assert"
+)
+
+// bigTiffEntry is an entry of a BigTiff Ifd
+type bigTiffEntry struct {
+	id    tag.ID
+	t     tag.Type
+	count uint64
+	value []byte // up to 8 bytes
+}
+
+// newBigTiff returns a BigTiff file with IFD0 and an ExifIFD. The Ifds
+// and values that follow the 16 byte header are located at base+16.
+func newBigTiff(bo binary.ByteOrder, base uint64) []byte {
+	buf := make([]byte, 16)
+	if bo == binary.LittleEndian {
+		copy(buf, "II")
+	} else {
+		copy(buf, "MM")
+	}
+	bo.PutUint16(buf[2:], 0x002b)
+	bo.PutUint16(buf[4:], 8)
+	bo.PutUint64(buf[8:], base+16)
+
+	u16 := func(v uint16) []byte { b := make([]byte, 2); bo.PutUint16(b, v); return b }
+	u64 := func(v uint64) []byte { b := make([]byte, 8); bo.PutUint64(b, v); return b }
+
+	writeIfd := func(entries []bigTiffEntry) {
+		buf = append(buf, u64(uint64(len(entries)))...)
+		for _, e := range entries {
+			buf = append(buf, u16(uint16(e.id))...)
+			buf = append(buf, u16(uint16(e.t))...)
+			buf = append(buf, u64(e.count)...)
+			v := make([]byte, 8)
+			copy(v, e.value)
+			buf = append(buf, v...)
+		}
+		buf = append(buf, u64(0)...) // Next Ifd
+	}
+
+	// IFD0 at 16: 4 entries. ExifIFD follows at 16 + 8 + 4*20 + 8 = 112.
+	// Model follows ExifIFD at 112 + 8 + 2*20 + 8 = 168.
+	writeIfd([]bigTiffEntry{
+		{ifds.ImageWidth, tag.TypeShort, 1, u16(640)},
+		{ifds.Make, tag.TypeASCII, 6, []byte("Canon\x00")},
+		{ifds.Model, tag.TypeASCII, 16, u64(base + 168)},
+		{ifds.ExifTag, tag.TypeIfd8, 1, u64(base + 112)},
+	})
+	writeIfd([]bigTiffEntry{
+		{exififd.ISOSpeedRatings, tag.TypeShort, 1, u16(400)},
+		{exififd.PixelYDimension, tag.TypeLong8, 1, u64(480)},
+	})
+	buf = append(buf, []byte("Canon EOS R5\x00\x00\x00\x00")...)
+	return buf
+}
+
+// sparseReader is an io.ReaderAt of a BigTiff header followed by data at base.
+type sparseReader struct {
+	header []byte
+	base   int64
+	data   []byte
+}
+
+func newSparseReader(buf []byte, base int64) sparseReader {
+	return sparseReader{header: buf[:16], base: base + 16, data: buf[16:]}
+}
+
+func (sr sparseReader) ReadAt(p []byte, off int64) (n int, err error) {
+	switch {
+	case off < int64(len(sr.header)):
+		n = copy(p, sr.header[off:])
+	case off >= sr.base && off-sr.base < int64(len(sr.data)):
+		n = copy(p, sr.data[off-sr.base:])
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestParseBigTIFF(t *testing.T) {
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		t.Run(bo.String(), func(t *testing.T) {
+			e, err := ParseTIFF(bytes.NewReader(newBigTiff(bo, 0)))
+			if !assert.ErrorIs(t, err, nil) {
+				return
+			}
+			assert.True(t, e.reader.bigTiff)
+			assert.Equal(t, imagetype.ImageTiff, e.imageType)
+			assert.Equal(t, "Canon", e.CameraMake())
+			assert.Equal(t, "Canon EOS R5", e.CameraModel())
+			assert.Equal(t, uint16(640), e.width)
+
+			iso, err := e.ISOSpeed()
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, uint32(400), iso)
+
+			// LONG8 value stored within the entry
+			tg, err := e.GetTag(ifds.ExifIFD, 0, exififd.PixelYDimension)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, tag.TypeLong8, tg.Type())
+			raw, err := e.RawTagBytes(tg)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, uint64(480), bo.Uint64(raw))
+			v, err := e.ParseUint32Value(tg)
+			assert.ErrorIs(t, err, nil)
+			assert.Equal(t, uint32(480), v)
+		})
+	}
+
+	// Ifds and values located after 4GiB
+	const base = 1 << 33
+	buf := newBigTiff(binary.LittleEndian, base)
+	e, err := ParseTIFF(newSparseReader(buf, base))
+	if assert.ErrorIs(t, err, nil) {
+		assert.Equal(t, "Canon EOS R5", e.CameraModel())
+		iso, err := e.ISOSpeed()
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, uint32(400), iso)
+		tg, err := e.GetTag(ifds.IFD0, 0, ifds.Model)
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, uint64(base+168), tg.ValueOffset)
+	}
+
+	// Truncated BigTiff Header
+	_, err = ParseTIFF(bytes.NewReader(buf[:12]))
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
@@ -0,0 +1,84 @@
+package exif
+
+import (
+	"strings"
+)
+
+// cameraMakes maps known upper case corporate camera makes to canonical brand names.
+var cameraMakes = map[string]string{
+	"APPLE":                       "Apple",
+	"CANON":                       "Canon",
+	"CASIO COMPUTER CO.,LTD.":     "Casio",
+	"DJI":                         "DJI",
+	"EASTMAN KODAK COMPANY":       "Kodak",
+	"FUJIFILM":                    "Fujifilm",
+	"GOOGLE":                      "Google",
+	"GOPRO":                       "GoPro",
+	"HASSELBLAD":                  "Hasselblad",
+	"HUAWEI":                      "Huawei",
+	"KODAK":                       "Kodak",
+	"KONICA MINOLTA":              "Konica Minolta",
+	"KONICA MINOLTA CAMERA, INC.": "Konica Minolta",
+	"LEICA":                       "Leica",
+	"LEICA CAMERA AG":             "Leica",
+	"MINOLTA CO., LTD.":           "Minolta",
+	"NIKON":                       "Nikon",
+	"NIKON CORPORATION":           "Nikon",
+	"OLYMPUS CORPORATION":         "Olympus",
+	"OLYMPUS IMAGING CORP.":       "Olympus",
+	"OLYMPUS OPTICAL CO.,LTD":     "Olympus",
+	"OM DIGITAL SOLUTIONS":        "OM System",
+	"PANASONIC":                   "Panasonic",
+	"PENTAX":                      "Pentax",
+	"PENTAX CORPORATION":          "Pentax",
+	"PHASE ONE":                   "Phase One",
+	"RICOH":                       "Ricoh",
+	"RICOH IMAGING COMPANY, LTD.": "Ricoh",
+	"SAMSUNG":                     "Samsung",
+	"SAMSUNG TECHWIN":             "Samsung",
+	"SEIKO EPSON CORP.":           "Epson",
+	"SIGMA":                       "Sigma",
+	"SONY":                        "Sony",
+	"XIAOMI":                      "Xiaomi",
+}
+
+// NormalizedCamera returns the camera make and model normalized for grouping.
+// Nulls and surrounding or repeated whitespace are removed, known corporate
+// makes are mapped to their brand name ie. "NIKON CORPORATION" is "Nikon"
+// and the make is removed from the beginning of the model ie. "Canon EOS 6D"
+// is "EOS 6D".
+//
+// Use CameraMake and CameraModel for the values as they are stored.
+func (e *Data) NormalizedCamera() (make, model string) {
+	return normalizeCamera(e.make, e.model)
+}
+
+func normalizeCamera(rawMake, rawModel string) (make, model string) {
+	rawMake = cleanCameraString(rawMake)
+	model = cleanCameraString(rawModel)
+
+	make = rawMake
+	if brand, ok := cameraMakes[strings.ToUpper(rawMake)]; ok {
+		make = brand
+	}
+
+	// Remove the make from the beginning of the model
+	if make != "" {
+		for _, prefix := range []string{rawMake, make, strings.SplitN(rawMake, " ", 2)[0]} {
+			if len(model) > len(prefix) && model[len(prefix)] == ' ' && strings.EqualFold(model[:len(prefix)], prefix) {
+				model = model[len(prefix)+1:]
+				break
+			}
+		}
+	}
+	return make, model
+}
+
+// cleanCameraString removes values after the first null and
+// collapses whitespace.
+func cleanCameraString(s string) string {
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
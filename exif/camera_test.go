@@ -0,0 +1,56 @@
+package exif
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCamera(t *testing.T) {
+	cameraTests := []struct {
+		rawMake, rawModel string
+		make, model       string
+	}{
+		{"NIKON CORPORATION", "NIKON D7100", "Nikon", "D7100"},
+		{"NIKON", "E995", "Nikon", "E995"},
+		{"Canon", "Canon EOS 5D", "Canon", "EOS 5D"},
+		{"Canon", "Canon Canon EOS 5D", "Canon", "Canon EOS 5D"},
+		{"SONY", "ILCE-7M3", "Sony", "ILCE-7M3"},
+		{"OLYMPUS IMAGING CORP.  ", "E-M5\x00\x00\x00", "Olympus", "E-M5"},
+		{"  Canon\x00 ", "Canon  EOS   R5 ", "Canon", "EOS R5"},
+		{"GoPro", "HERO8 Black", "GoPro", "HERO8 Black"},
+		{"Unknown Maker", "Unknown Maker X1", "Unknown Maker", "X1"},
+		{"Apple", "iPhone 12", "Apple", "iPhone 12"},
+		{"", "Model", "", "Model"},
+		{"Canon", "Canon", "Canon", "Canon"},
+	}
+	for _, ct := range cameraTests {
+		make, model := normalizeCamera(ct.rawMake, ct.rawModel)
+		assert.Equal(t, ct.make, make, ct.rawMake)
+		assert.Equal(t, ct.model, model, ct.rawModel)
+	}
+}
+
+func TestNormalizedCamera(t *testing.T) {
+	for _, wantedExif := range exifTests {
+		t.Run(wantedExif.filename, func(t *testing.T) {
+			buf, err := os.ReadFile(wantedExif.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := ParseExif(bytes.NewReader(buf), wantedExif.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			make, _ := e.NormalizedCamera()
+			switch wantedExif.make {
+			case "NIKON CORPORATION":
+				assert.Equal(t, "Nikon", make)
+			case "Canon":
+				assert.Equal(t, "Canon", make)
+			}
+		})
+	}
+}
@@ -74,6 +74,30 @@ func (e *Data) CanonShotInfo() (canon.ShotInfo, error) {
 	}, nil
 }
 
+// CanonSensorInfo convenience func. "IFD/Exif/Makernotes.Canon" CanonSensorInfo
+// Canon Sensor dimensions and borders from the Makernote
+func (e *Data) CanonSensorInfo() (canon.SensorInfo, error) {
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.CanonSensorInfo)
+	if err != nil {
+		return canon.SensorInfo{}, err
+	}
+	si, err := e.ParseUint16Values(t)
+	if err != nil {
+		return canon.SensorInfo{}, err
+	}
+	if len(si) < 9 {
+		return canon.SensorInfo{}, ErrEmptyTag
+	}
+	return canon.SensorInfo{
+		Width:        si[1],
+		Height:       si[2],
+		LeftBorder:   si[5],
+		TopBorder:    si[6],
+		RightBorder:  si[7],
+		BottomBorder: si[8],
+	}, nil
+}
+
 // CanonAFInfo -
 // Canon Camera AutoFocus Information from the Makernote
 func (e *Data) CanonAFInfo() (afInfo canon.AFInfo, err error) {
@@ -0,0 +1,194 @@
+package exif
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// DNG errors
+var (
+	ErrNoPreview = errors.New("error exif does not have a jpeg preview")
+)
+
+// Compressions of a JPEG preview image
+const (
+	compressionOldJPEG = compressionJPEG
+	compressionNewJPEG = 7
+)
+
+// subfileReducedResolution is the reduced resolution flag of the NewSubfileType tag.
+const subfileReducedResolution = 0x01
+
+// DNGVersion is the version of the DNG specification of a DNG file.
+type DNGVersion [4]uint8
+
+func (v DNGVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v[0], v[1], v[2], v[3])
+}
+
+// DNGVersion convenience func. "IFD" DNGVersion
+//
+// Returns ErrEmptyTag if the file is not a DNG.
+func (e *Data) DNGVersion() (v DNGVersion, err error) {
+	t, err := e.GetTag(ifds.IFD0, 0, ifds.DNGVersion)
+	if err != nil {
+		return
+	}
+	buf, err := e.ParseUint8Values(t)
+	if err != nil {
+		return
+	}
+	copy(v[:], buf)
+	return v, nil
+}
+
+// UniqueCameraModel convenience func. "IFD" UniqueCameraModel
+// The camera model name used by raw converters to identify the camera.
+func (e *Data) UniqueCameraModel() (model string, err error) {
+	t, err := e.GetTag(ifds.IFD0, 0, ifds.UniqueCameraModel)
+	if err != nil {
+		return
+	}
+	return e.ParseASCIIValue(t)
+}
+
+// ColorMatrix1 convenience func. "IFD" ColorMatrix1
+// The matrix that converts XYZ values to the camera color space for CalibrationIlluminant1,
+// in row order.
+func (e *Data) ColorMatrix1() ([]float64, error) {
+	return e.colorMatrix(ifds.ColorMatrix1)
+}
+
+// ColorMatrix2 convenience func. "IFD" ColorMatrix2
+// The matrix that converts XYZ values to the camera color space for CalibrationIlluminant2,
+// in row order.
+func (e *Data) ColorMatrix2() ([]float64, error) {
+	return e.colorMatrix(ifds.ColorMatrix2)
+}
+
+func (e *Data) colorMatrix(id tag.ID) ([]float64, error) {
+	t, err := e.GetTag(ifds.IFD0, 0, id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := e.ParseSRationalValues(t)
+	if err != nil {
+		return nil, err
+	}
+	matrix := make([]float64, len(r))
+	for i := range r {
+		if r[i].Denominator != 0 {
+			matrix[i] = float64(r[i].Numerator) / float64(r[i].Denominator)
+		}
+	}
+	return matrix, nil
+}
+
+// OriginalRawFileName convenience func. "IFD" OriginalRawFileName
+// The file name of the raw file that the DNG was converted from.
+func (e *Data) OriginalRawFileName() (name string, err error) {
+	t, err := e.GetTag(ifds.IFD0, 0, ifds.OriginalRawFileName)
+	if err != nil {
+		return
+	}
+	if t.IsType(tag.TypeByte) {
+		var buf []byte
+		if buf, err = e.ParseUint8Values(t); err != nil {
+			return
+		}
+		return string(trim(buf)), nil
+	}
+	return e.ParseASCIIValue(t)
+}
+
+// Preview is a JPEG preview image of an Ifd of the IFD0 chain, of the SubIFDs or
+// of the Makernote.
+type Preview struct {
+	Ifd           ifds.IfdType
+	Index         uint8
+	Width, Height uint32
+	Offset        uint32
+	Length        uint32
+}
+
+// Previews returns the JPEG preview images of the IFD0 chain followed by the
+// JPEG preview images of the SubIFDs. DNG and camera raw files usually have
+// previews in several sizes.
+//
+// A preview is a reduced resolution Ifd with JPEG compression in a single strip,
+// or an Ifd with the JPEGInterchangeFormat and JPEGInterchangeFormatLength tags.
+func (e *Data) Previews() (previews []Preview) {
+	for _, ifdType := range []ifds.IfdType{ifds.IFD0, ifds.SubIFD} {
+		n := e.ifdCount(ifdType)
+		for i := 0; i < n; i++ {
+			if p, ok := e.preview(ifdType, uint8(i)); ok {
+				previews = append(previews, p)
+			}
+		}
+	}
+	return previews
+}
+
+// ifdCount returns the number of Ifds of ifdType with tags.
+func (e *Data) ifdCount(ifdType ifds.IfdType) (n int) {
+	for k := range e.tagMap {
+		if t, index, _ := k.Val(); t == ifdType && int(index) >= n {
+			n = int(index) + 1
+		}
+	}
+	return n
+}
+
+// preview returns the JPEG Preview of the Ifd, returns false if the Ifd
+// is not a JPEG preview.
+func (e *Data) preview(ifdType ifds.IfdType, index uint8) (p Preview, ok bool) {
+	uint32Value := func(id tag.ID) (uint32, bool) {
+		t, err := e.GetTag(ifdType, index, id)
+		if err != nil {
+			return 0, false
+		}
+		v, err := e.ParseUint32Value(t)
+		return v, err == nil
+	}
+	p = Preview{Ifd: ifdType, Index: index}
+	p.Width, _ = uint32Value(ifds.ImageWidth)
+	p.Height, _ = uint32Value(ifds.ImageLength)
+
+	if offset, ok := uint32Value(ifds.JPEGInterchangeFormat); ok {
+		p.Offset = offset
+		p.Length, _ = uint32Value(ifds.JPEGInterchangeFormatLength)
+		return p, p.Length > 0
+	}
+	subfileType, _ := uint32Value(ifds.NewSubfileType)
+	compression, _ := uint32Value(ifds.Compression)
+	if subfileType&subfileReducedResolution == 0 || (compression != compressionOldJPEG && compression != compressionNewJPEG) {
+		return p, false
+	}
+	// A single strip
+	if p.Offset, ok = uint32Value(ifds.StripOffsets); !ok {
+		return p, false
+	}
+	p.Length, _ = uint32Value(ifds.StripByteCounts)
+	return p, p.Length > 0
+}
+
+// PreviewImage returns the JPEG image of the Preview p.
+//
+// Returns ErrThumbnailNotJPEG if the image does not start with a JPEG SOI marker.
+func (e *Data) PreviewImage(p Preview) ([]byte, error) {
+	if p.Length == 0 {
+		return nil, ErrNoPreview
+	}
+	buf, err := e.reader.ReadBufferAt(int(p.Length), int64(e.reader.exifOffset+uint64(p.Offset)))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, jpegSOI) {
+		return nil, ErrThumbnailNotJPEG
+	}
+	return append([]byte(nil), buf...), nil
+}
@@ -0,0 +1,113 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDNG returns a DNG with a 256x171 thumbnail in IFD0, the raw image in SubIFD 0
+// and a JPEG preview in SubIFD 1 that follows the Ifds.
+func newDNG(t *testing.T, preview []byte) []byte {
+	t.Helper()
+	b := NewBuilder(binary.LittleEndian)
+	assert.NoError(t, b.SetByte(ifds.IFD0, 0, ifds.DNGVersion, 1, 4, 0, 0))
+	assert.NoError(t, b.SetASCII(ifds.IFD0, 0, ifds.UniqueCameraModel, "Canon EOS R5"))
+	assert.NoError(t, b.SetSRational(ifds.IFD0, 0, ifds.ColorMatrix1,
+		tag.SRational{Numerator: 9766, Denominator: 10000}, tag.SRational{Numerator: -4096, Denominator: 10000}, tag.SRational{Numerator: 0, Denominator: 10000},
+		tag.SRational{Numerator: -2959, Denominator: 10000}, tag.SRational{Numerator: 11000, Denominator: 10000}, tag.SRational{Numerator: 1944, Denominator: 10000},
+		tag.SRational{Numerator: -506, Denominator: 10000}, tag.SRational{Numerator: 1356, Denominator: 10000}, tag.SRational{Numerator: 5832, Denominator: 10000}))
+	assert.NoError(t, b.SetByte(ifds.IFD0, 0, ifds.OriginalRawFileName, []byte("IMG_0001.CR3\x00")...))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.NewSubfileType, 1))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 256))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 171))
+	assert.NoError(t, b.SetShort(ifds.IFD0, 0, ifds.Compression, 1))
+
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 0, ifds.NewSubfileType, 0))
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 0, ifds.ImageWidth, 8192))
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 0, ifds.ImageLength, 5464))
+
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.NewSubfileType, 1))
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.ImageWidth, 1024))
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.ImageLength, 683))
+	assert.NoError(t, b.SetShort(ifds.SubIFD, 1, ifds.Compression, compressionNewJPEG))
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.StripOffsets, 0))
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.StripByteCounts, uint32(len(preview))))
+
+	// The preview follows the Ifds
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.StripOffsets, uint32(len(buf))))
+	if buf, err = b.Encode(); err != nil {
+		t.Fatal(err)
+	}
+	return append(buf, preview...)
+}
+
+func TestDNG(t *testing.T) {
+	preview := []byte{0xFF, 0xD8, 0xFF, 0xDB, 0x00, 0x00, 0xFF, 0xD9}
+	e, err := ParseTIFF(bytes.NewReader(newDNG(t, preview)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, imagetype.ImageDNG, e.imageType)
+
+	v, err := e.DNGVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.4.0.0", v.String())
+	model, err := e.UniqueCameraModel()
+	assert.NoError(t, err)
+	assert.Equal(t, "Canon EOS R5", model)
+	name, err := e.OriginalRawFileName()
+	assert.NoError(t, err)
+	assert.Equal(t, "IMG_0001.CR3", name)
+	matrix, err := e.ColorMatrix1()
+	if assert.NoError(t, err) && assert.Len(t, matrix, 9) {
+		assert.InDelta(t, 0.9766, matrix[0], 0.00001)
+		assert.InDelta(t, -0.0506, matrix[6], 0.00001)
+	}
+	_, err = e.ColorMatrix2()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+
+	previews := e.Previews()
+	if assert.Len(t, previews, 1) {
+		p := previews[0]
+		assert.Equal(t, ifds.SubIFD, p.Ifd)
+		assert.Equal(t, uint8(1), p.Index)
+		assert.Equal(t, uint32(1024), p.Width)
+		assert.Equal(t, uint32(683), p.Height)
+		buf, err := e.PreviewImage(p)
+		assert.NoError(t, err)
+		assert.Equal(t, preview, buf)
+	}
+	_, err = e.PreviewImage(Preview{})
+	assert.ErrorIs(t, err, ErrNoPreview)
+
+	// Not a JPEG preview
+	e, err = ParseTIFF(bytes.NewReader(newDNG(t, []byte{0x00, 0x00})))
+	if assert.NoError(t, err) && assert.Len(t, e.Previews(), 1) {
+		_, err = e.PreviewImage(e.Previews()[0])
+		assert.ErrorIs(t, err, ErrThumbnailNotJPEG)
+	}
+
+	// Not a DNG
+	b := NewBuilder(binary.LittleEndian)
+	assert.NoError(t, b.SetASCII(ifds.IFD0, 0, ifds.Make, "Canon"))
+	buf, err := b.Encode()
+	if !assert.NoError(t, err) {
+		return
+	}
+	if e, err = ParseTIFF(bytes.NewReader(buf)); assert.NoError(t, err) {
+		assert.Equal(t, imagetype.ImageTiff, e.imageType)
+		_, err = e.DNGVersion()
+		assert.ErrorIs(t, err, ErrEmptyTag)
+		assert.Empty(t, e.Previews())
+	}
+}
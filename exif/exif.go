@@ -3,7 +3,9 @@ package exif
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"sort"
 
 	"github.com/evanoberholster/imagemeta/exif/ifds"
 	"github.com/evanoberholster/imagemeta/exif/tag"
@@ -23,23 +25,30 @@ var (
 //
 // If the header is invalid ParseExif will return ErrInvalidHeader.
 func ParseExif(r io.ReaderAt, header meta.ExifHeader) (*Data, error) {
-	var err error
-	if !header.IsValid() {
-		return nil, ErrInvalidHeader
-	}
+	return ParseExifWithOptions(r, header, Options{})
+}
 
-	if header.FirstIfd == ifds.NullIFD {
-		header.FirstIfd = ifds.IFD0
+// ParseTIFF parses Exif metadata from an io.ReaderAt of a Tiff file.
+// The Tiff Header is read from offset 0 with the byte order ("II" or "MM"),
+// the 0x002A magic number and the first IFD offset. BigTiff files with
+// the 0x002B magic number and 64-bit offsets are also supported.
+//
+// If the Tiff Header is invalid ParseTIFF will return ErrInvalidHeader.
+func ParseTIFF(r io.ReaderAt) (*Data, error) {
+	var buf [bigTiffHeaderByteLength]byte
+	n, err := r.ReadAt(buf[:], 0)
+	if n < 8 {
+		return nil, err
 	}
-
-	reader := newReader(r, header)
-
-	e := newData(reader, header.ImageType)
-
-	// Scan the FirstIfd with the FirstIfdOffset from the ExifReader
-	err = reader.scanIFD(e, ifds.NewIFD(header.FirstIfd, 0, header.FirstIfdOffset))
-
-	return e, err
+	if byteOrder := meta.BinaryOrder(buf[:]); byteOrder != nil {
+		firstIfdOffset := byteOrder.Uint32(buf[4:8])
+		return ParseExif(r, meta.NewExifHeader(byteOrder, firstIfdOffset, 0, 0, imagetype.ImageTiff))
+	}
+	if byteOrder := meta.BigTiffBinaryOrder(buf[:]); byteOrder != nil && n == bigTiffHeaderByteLength {
+		firstIfdOffset := byteOrder.Uint64(buf[8:16])
+		return ParseExif(r, meta.NewBigTiffExifHeader(byteOrder, firstIfdOffset, imagetype.ImageTiff))
+	}
+	return nil, ErrInvalidHeader
 }
 
 func (e *Data) ParseIfd(header meta.ExifHeader) error {
@@ -47,8 +56,9 @@ func (e *Data) ParseIfd(header meta.ExifHeader) error {
 		return ErrInvalidHeader
 	}
 	e.reader.exifLength = header.ExifLength
-	e.reader.exifOffset = header.TiffHeaderOffset
+	e.reader.exifOffset = uint64(header.TiffHeaderOffset)
 	e.reader.byteOrder = header.ByteOrder
+	e.reader.bigTiff = header.BigTiff
 	return e.reader.scanIFD(e, ifds.NewIFD(header.FirstIfd, 0, header.FirstIfdOffset))
 }
 
@@ -71,6 +81,8 @@ type Data struct {
 	height      uint16
 	exifVersion uint16
 	imageType   imagetype.ImageType
+	makerNote   tag.Tag
+	nikonMkNote bool // the MakerNote has a Nikon MakerNote header
 }
 
 // GetTag returns a tag from Exif and returns an error if tag doesn't exist
@@ -83,6 +95,9 @@ func (e *Data) GetTag(ifd ifds.IfdType, ifdIndex uint8, tagID tag.ID) (tag.Tag,
 
 // RangeTags returns a chan tag.Tag for the
 // ranging over tags in exif.Data
+//
+// Each call starts a goroutine that only exits once all tags have been
+// received. Prefer Tags when built with Go 1.23 or later.
 func (e *Data) RangeTags() chan tag.Tag {
 	c := make(chan tag.Tag)
 	go func() {
@@ -94,6 +109,43 @@ func (e *Data) RangeTags() chan tag.Tag {
 	return c
 }
 
+// Walk calls fn for each tag in exif.Data in IFD, IFD index and tag ID order.
+// The tag name can be resolved with ifd.TagName(t.ID).
+// Walk stops and returns the error if fn returns an error.
+func (e *Data) Walk(fn func(ifd ifds.IfdType, idx uint8, t tag.Tag) error) error {
+	keys := make([]ifds.Key, 0, len(e.tagMap))
+	for k := range e.tagMap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	for _, k := range keys {
+		ifd, idx, _ := k.Val()
+		if err := fn(ifd, idx, e.tagMap[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RawTagBytes returns the tag's value as stored without decoding.
+// Values of 4 bytes or less are returned from the tag's embedded value area,
+// otherwise they are read from the underlying reader at the tag's value offset.
+// The returned slice is a copy and safe to retain.
+func (e *Data) RawTagBytes(t tag.Tag) ([]byte, error) {
+	buf, err := e.reader.ReadValue(t)
+	if err != nil {
+		return nil, err
+	}
+	size := int(t.Size())
+	if size > len(buf) {
+		size = len(buf)
+	}
+	raw := make([]byte, size)
+	copy(raw, buf[:size])
+	return raw, nil
+}
+
 // GetTagValue returns the tag's value as an interface.
 //
 // For performance reasons its preferable to use the Parse* functions.
@@ -108,6 +160,12 @@ func (e *Data) GetTagValue(t tag.Tag) (value interface{}) {
 		} else {
 			value = str
 		}
+	case tag.TypeSignedByte:
+		if t.UnitCount > 1 {
+			value, _ = e.ParseInt8Values(t)
+		} else {
+			value, _ = e.ParseInt8Value(t)
+		}
 	case tag.TypeShort:
 		if t.UnitCount > 1 {
 			value, _ = e.ParseUint16Values(t)
@@ -120,6 +178,30 @@ func (e *Data) GetTagValue(t tag.Tag) (value interface{}) {
 		} else {
 			value, _ = e.ParseUint32Value(t)
 		}
+	case tag.TypeSignedShort:
+		if t.UnitCount > 1 {
+			value, _ = e.ParseInt16Values(t)
+		} else {
+			value, _ = e.ParseInt16Value(t)
+		}
+	case tag.TypeSignedLong:
+		if t.UnitCount > 1 {
+			value, _ = e.ParseInt32Values(t)
+		} else {
+			value, _ = e.ParseInt32Value(t)
+		}
+	case tag.TypeFloat:
+		if t.UnitCount > 1 {
+			value, _ = e.ParseFloatValues(t)
+		} else {
+			value, _ = e.ParseFloatValue(t)
+		}
+	case tag.TypeDouble:
+		if t.UnitCount > 1 {
+			value, _ = e.ParseDoubleValues(t)
+		} else {
+			value, _ = e.ParseDoubleValue(t)
+		}
 	case tag.TypeRational:
 		value, _ = e.ParseRationalValues(t)
 	case tag.TypeSignedRational:
@@ -127,3 +209,41 @@ func (e *Data) GetTagValue(t tag.Tag) (value interface{}) {
 	}
 	return
 }
+
+// DisplayValue returns the human-readable value of the tag as rendered by
+// exiftool, ie. "Program AE" for the ExposureProgram value 2, see
+// tag.Tag.DisplayValue. Tags without enumerated values are formatted with
+// their GetTagValue.
+func (e *Data) DisplayValue(t tag.Tag) string {
+	if v, err := e.enumValue(t); err == nil {
+		if name, ok := t.DisplayValue(v); ok {
+			return name
+		}
+	}
+	return fmt.Sprint(e.GetTagValue(t))
+}
+
+// enumValue returns the value of a tag with a single Byte, Undefined, Short
+// or Long value, or the first character of an ASCII value.
+func (e *Data) enumValue(t tag.Tag) (uint32, error) {
+	switch t.Type() {
+	case tag.TypeASCII, tag.TypeASCIINoNul:
+		str, err := e.ParseASCIIValue(t)
+		if err != nil || len(str) == 0 {
+			return 0, tag.ErrEmptyTag
+		}
+		return uint32(str[0]), nil
+	case tag.TypeByte, tag.TypeUndefined:
+		if t.UnitCount != 1 {
+			return 0, tag.ErrTagTypeNotValid
+		}
+		buf, err := e.reader.ReadValue(t)
+		if err != nil {
+			return 0, err
+		}
+		return uint32(buf[0]), nil
+	case tag.TypeShort, tag.TypeLong:
+		return e.ParseUint32Value(t)
+	}
+	return 0, tag.ErrTagTypeNotValid
+}
@@ -3,11 +3,14 @@ package exif
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"io/ioutil"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
 	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 	"github.com/stretchr/testify/assert"
@@ -183,3 +186,88 @@ func TestPreviouslyParsedExif(t *testing.T) {
 //}
 //
 // jsonExif for testing purposes.
+
+func TestParseTIFF(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/Hero8.GPR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseTIFF(bytes.NewReader(buf))
+	if !assert.ErrorIs(t, err, nil) {
+		return
+	}
+	assert.Equal(t, "GoPro", e.CameraMake())
+	assert.Equal(t, "HERO8 Black", e.CameraModel())
+	assert.Equal(t, imagetype.ImageDNG, e.imageType) // GPR is a DNG
+
+	// Invalid Tiff Header
+	_, err = ParseTIFF(bytes.NewReader([]byte{'I', 'I', 0x2b, 0x00, 0x08, 0x00, 0x00, 0x00}))
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+
+	// Short Tiff Header
+	_, err = ParseTIFF(bytes.NewReader([]byte{'M', 'M', 0x00}))
+	assert.Error(t, err)
+}
+
+func TestRawTagBytes(t *testing.T) {
+	buf := []byte{0, 0, 0, 0, 'a', 'b', 'c', 'd', 'e', 'f', 0x00}
+	e := newData(newMockReader(buf), imagetype.ImageUnknown)
+
+	// Value offset
+	t1, _ := tag.NewTag(0x1234, tag.TypeUndefined, 7, 4, 0)
+	raw, err := e.RawTagBytes(t1)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, []byte{'a', 'b', 'c', 'd', 'e', 'f', 0x00}, raw)
+
+	// Embedded value (BigEndian)
+	t2, _ := tag.NewTag(0x1235, tag.TypeShort, 1, 0x01020000, 0)
+	raw, err = e.RawTagBytes(t2)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, []byte{0x01, 0x02}, raw)
+
+	// Value out of range
+	t3, _ := tag.NewTag(0x1236, tag.TypeUndefined, 20, 4, 0)
+	_, err = e.RawTagBytes(t3)
+	assert.Error(t, err)
+}
+
+func TestWalk(t *testing.T) {
+	f, err := os.Open("../testImages/Hero8.GPR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	e, err := ParseTIFF(f)
+	if !assert.ErrorIs(t, err, nil) {
+		return
+	}
+
+	// Tags are walked in IFD, IFD index and tag ID order
+	var keys []ifds.Key
+	err = e.Walk(func(ifd ifds.IfdType, idx uint8, t tag.Tag) error {
+		keys = append(keys, ifds.NewKey(ifd, idx, t.ID))
+		return nil
+	})
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, len(e.tagMap), len(keys))
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] >= keys[i] {
+			t.Fatalf("Incorrect Walk order 0x%08x before 0x%08x", keys[i-1], keys[i])
+		}
+	}
+	ifd, _, id := keys[0].Val()
+	assert.Equal(t, ifds.IFD0, ifd)
+	assert.NotEqual(t, "", ifd.TagName(id))
+
+	// Stop early
+	errStop := errors.New("stop")
+	n := 0
+	err = e.Walk(func(ifd ifds.IfdType, idx uint8, t tag.Tag) error {
+		if n++; n == 3 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 3, n)
+}
@@ -31,6 +31,9 @@ var TagIDMap = map[tag.ID]string{
 	ExifVersion:               "ExifVersion",
 	DateTimeOriginal:          "DateTimeOriginal",
 	DateTimeDigitized:         "DateTimeDigitized",
+	OffsetTime:                "OffsetTime",
+	OffsetTimeOriginal:        "OffsetTimeOriginal",
+	OffsetTimeDigitized:       "OffsetTimeDigitized",
 	ComponentsConfiguration:   "ComponentsConfiguration",
 	CompressedBitsPerPixel:    "CompressedBitsPerPixel",
 	ShutterSpeedValue:         "ShutterSpeedValue",
@@ -49,6 +52,12 @@ var TagIDMap = map[tag.ID]string{
 	SubSecTime:                "SubSecTime",
 	SubSecTimeOriginal:        "SubSecTimeOriginal",
 	SubSecTimeDigitized:       "SubSecTimeDigitized",
+	Temperature:               "Temperature",
+	Humidity:                  "Humidity",
+	Pressure:                  "Pressure",
+	WaterDepth:                "WaterDepth",
+	Acceleration:              "Acceleration",
+	CameraElevationAngle:      "CameraElevationAngle",
 	FlashpixVersion:           "FlashpixVersion",
 	ColorSpace:                "ColorSpace",
 	PixelXDimension:           "PixelXDimension",
@@ -85,6 +94,186 @@ var TagIDMap = map[tag.ID]string{
 	LensMake:                  "LensMake",
 	LensModel:                 "LensModel",
 	LensSerialNumber:          "LensSerialNumber",
+	CompositeImage:            "CompositeImage",
+	Gamma:                     "Gamma",
+
+	// Exif 2.32 composite image tags
+	SourceImageNumberOfCompositeImage:   "SourceImageNumberOfCompositeImage",
+	SourceExposureTimesOfCompositeImage: "SourceExposureTimesOfCompositeImage",
+}
+
+// TagValueMap is a Map of tag.ID to the names of the enumerated values
+// of the ExifIfd tags
+var TagValueMap = tag.ValueMap{
+	ExposureProgram: {
+		0: "Not Defined",
+		1: "Manual",
+		2: "Program AE",
+		3: "Aperture-priority AE",
+		4: "Shutter speed priority AE",
+		5: "Creative (Slow speed)",
+		6: "Action (High speed)",
+		7: "Portrait",
+		8: "Landscape",
+		9: "Bulb",
+	},
+	SensitivityType: {
+		0: "Unknown",
+		1: "Standard Output Sensitivity",
+		2: "Recommended Exposure Index",
+		3: "ISO Speed",
+		4: "Standard Output Sensitivity and Recommended Exposure Index",
+		5: "Standard Output Sensitivity and ISO Speed",
+		6: "Recommended Exposure Index and ISO Speed",
+		7: "Standard Output Sensitivity, Recommended Exposure Index and ISO Speed",
+	},
+	MeteringMode: {
+		0:   "Unknown",
+		1:   "Average",
+		2:   "Center-weighted average",
+		3:   "Spot",
+		4:   "Multi-spot",
+		5:   "Multi-segment",
+		6:   "Partial",
+		255: "Other",
+	},
+	LightSource: {
+		0:   "Unknown",
+		1:   "Daylight",
+		2:   "Fluorescent",
+		3:   "Tungsten (Incandescent)",
+		4:   "Flash",
+		9:   "Fine Weather",
+		10:  "Cloudy",
+		11:  "Shade",
+		12:  "Daylight Fluorescent",
+		13:  "Day White Fluorescent",
+		14:  "Cool White Fluorescent",
+		15:  "White Fluorescent",
+		16:  "Warm White Fluorescent",
+		17:  "Standard Light A",
+		18:  "Standard Light B",
+		19:  "Standard Light C",
+		20:  "D55",
+		21:  "D65",
+		22:  "D75",
+		23:  "D50",
+		24:  "ISO Studio Tungsten",
+		255: "Other",
+	},
+	Flash: {
+		0x00: "No Flash",
+		0x01: "Fired",
+		0x05: "Fired, Return not detected",
+		0x07: "Fired, Return detected",
+		0x08: "On, Did not fire",
+		0x09: "On, Fired",
+		0x0d: "On, Return not detected",
+		0x0f: "On, Return detected",
+		0x10: "Off, Did not fire",
+		0x14: "Off, Did not fire, Return not detected",
+		0x18: "Auto, Did not fire",
+		0x19: "Auto, Fired",
+		0x1d: "Auto, Fired, Return not detected",
+		0x1f: "Auto, Fired, Return detected",
+		0x20: "No flash function",
+		0x30: "Off, No flash function",
+		0x41: "Fired, Red-eye reduction",
+		0x45: "Fired, Red-eye reduction, Return not detected",
+		0x47: "Fired, Red-eye reduction, Return detected",
+		0x49: "On, Red-eye reduction",
+		0x4d: "On, Red-eye reduction, Return not detected",
+		0x4f: "On, Red-eye reduction, Return detected",
+		0x50: "Off, Red-eye reduction",
+		0x58: "Auto, Did not fire, Red-eye reduction",
+		0x59: "Auto, Fired, Red-eye reduction",
+		0x5d: "Auto, Fired, Red-eye reduction, Return not detected",
+		0x5f: "Auto, Fired, Red-eye reduction, Return detected",
+	},
+	ColorSpace: {
+		0x1:    "sRGB",
+		0x2:    "Adobe RGB",
+		0xfffd: "Wide Gamut RGB",
+		0xfffe: "ICC Profile",
+		0xffff: "Uncalibrated",
+	},
+	FocalPlaneResolutionUnit: {
+		1: "None",
+		2: "inches",
+		3: "cm",
+		4: "mm",
+		5: "um",
+	},
+	SensingMethod: {
+		1: "Not defined",
+		2: "One-chip color area",
+		3: "Two-chip color area",
+		4: "Three-chip color area",
+		5: "Color sequential area",
+		7: "Trilinear",
+		8: "Color sequential linear",
+	},
+	FileSource: {
+		1: "Film Scanner",
+		2: "Reflection Print Scanner",
+		3: "Digital Camera",
+	},
+	SceneType: {
+		1: "Directly photographed",
+	},
+	CustomRendered: {
+		0: "Normal",
+		1: "Custom",
+	},
+	ExposureMode: {
+		0: "Auto",
+		1: "Manual",
+		2: "Auto bracket",
+	},
+	WhiteBalance: {
+		0: "Auto",
+		1: "Manual",
+	},
+	SceneCaptureType: {
+		0: "Standard",
+		1: "Landscape",
+		2: "Portrait",
+		3: "Night",
+	},
+	GainControl: {
+		0: "None",
+		1: "Low gain up",
+		2: "High gain up",
+		3: "Low gain down",
+		4: "High gain down",
+	},
+	Contrast: {
+		0: "Normal",
+		1: "Low",
+		2: "High",
+	},
+	Saturation: {
+		0: "Normal",
+		1: "Low",
+		2: "High",
+	},
+	Sharpness: {
+		0: "Normal",
+		1: "Soft",
+		2: "Hard",
+	},
+	SubjectDistanceRange: {
+		0: "Unknown",
+		1: "Macro",
+		2: "Close",
+		3: "Distant",
+	},
+	CompositeImage: {
+		0: "Unknown",
+		1: "Not a Composite Image",
+		2: "General Composite Image",
+		3: "Composite Image Captured While Shooting",
+	},
 }
 
 // ExifIFD TagIDs
@@ -104,6 +293,9 @@ const (
 	ExifVersion               tag.ID = 0x9000
 	DateTimeOriginal          tag.ID = 0x9003
 	DateTimeDigitized         tag.ID = 0x9004
+	OffsetTime                tag.ID = 0x9010 // time zone for ModifyDate
+	OffsetTimeOriginal        tag.ID = 0x9011 // time zone for DateTimeOriginal
+	OffsetTimeDigitized       tag.ID = 0x9012 // time zone for CreateDate
 	ComponentsConfiguration   tag.ID = 0x9101
 	CompressedBitsPerPixel    tag.ID = 0x9102
 	ShutterSpeedValue         tag.ID = 0x9201
@@ -122,6 +314,12 @@ const (
 	SubSecTime                tag.ID = 0x9290 // fractional seconds for ModifyDate
 	SubSecTimeOriginal        tag.ID = 0x9291 // fractional seconds for DateTimeOriginal
 	SubSecTimeDigitized       tag.ID = 0x9292 // fractional seconds for CreateDate
+	Temperature               tag.ID = 0x9400
+	Humidity                  tag.ID = 0x9401
+	Pressure                  tag.ID = 0x9402
+	WaterDepth                tag.ID = 0x9403
+	Acceleration              tag.ID = 0x9404
+	CameraElevationAngle      tag.ID = 0x9405
 	FlashpixVersion           tag.ID = 0xa000
 	ColorSpace                tag.ID = 0xa001
 	PixelXDimension           tag.ID = 0xa002
@@ -158,4 +356,10 @@ const (
 	LensMake                  tag.ID = 0xa433
 	LensModel                 tag.ID = 0xa434
 	LensSerialNumber          tag.ID = 0xa435
+	CompositeImage            tag.ID = 0xa460
+	Gamma                     tag.ID = 0xa500
+
+	// Exif 2.32 composite image tags
+	SourceImageNumberOfCompositeImage   tag.ID = 0xa461
+	SourceExposureTimesOfCompositeImage tag.ID = 0xa462
 )
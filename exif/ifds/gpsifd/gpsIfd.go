@@ -51,6 +51,61 @@ var TagIDMap = map[tag.ID]string{
 	GPSHPositioningError: "GPSHPositioningError",
 }
 
+// TagValueMap is a Map of tag.ID to the names of the enumerated values
+// of the GPSIfd tags. The values of the ASCII tags are their first character.
+var TagValueMap = tag.ValueMap{
+	GPSLatitudeRef: {
+		'N': "North",
+		'S': "South",
+	},
+	GPSLongitudeRef: {
+		'E': "East",
+		'W': "West",
+	},
+	GPSAltitudeRef: {
+		0: "Above Sea Level",
+		1: "Below Sea Level",
+	},
+	GPSStatus: {
+		'A': "Measurement Active",
+		'V': "Measurement Void",
+	},
+	GPSMeasureMode: {
+		'2': "2-Dimensional Measurement",
+		'3': "3-Dimensional Measurement",
+	},
+	GPSSpeedRef: {
+		'K': "km/h",
+		'M': "mph",
+		'N': "knots",
+	},
+	GPSTrackRef:        directionRef,
+	GPSImgDirectionRef: directionRef,
+	GPSDestLatitudeRef: {
+		'N': "North",
+		'S': "South",
+	},
+	GPSDestLongitudeRef: {
+		'E': "East",
+		'W': "West",
+	},
+	GPSDestBearingRef: directionRef,
+	GPSDestDistanceRef: {
+		'K': "Kilometers",
+		'M': "Miles",
+		'N': "Nautical Miles",
+	},
+	GPSDifferential: {
+		0: "No Correction",
+		1: "Differential Corrected",
+	},
+}
+
+var directionRef = map[uint32]string{
+	'M': "Magnetic North",
+	'T': "True North",
+}
+
 // GPSInfo Tags; GPSInfo Ifd
 const (
 	GPSVersionID         tag.ID = 0x0000
@@ -6,6 +6,7 @@ import (
 
 	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
 	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/iopifd"
 	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
 	"github.com/evanoberholster/imagemeta/exif/tag"
 )
@@ -50,6 +51,13 @@ const (
 	_IFDStringerString = "UnknownIfdIfdIfd/SubIfdIfd/ExifIfd/GPSIfd/IopIfd/Exif/MakernoteIfd/DNGAdobeDataIfd/Exif/MakernoteIfd/Exif/Makernote"
 )
 
+func init() {
+	tag.RegisterValues(uint8(IFD0), RootIfdTagValueMap)
+	tag.RegisterValues(uint8(SubIFD), RootIfdTagValueMap)
+	tag.RegisterValues(uint8(ExifIFD), exififd.TagValueMap)
+	tag.RegisterValues(uint8(GPSIFD), gpsifd.TagValueMap)
+}
+
 var (
 	// IFD Stringer Index
 	_IFDStringerIndex = [...]uint8{0, 10, 13, 23, 31, 38, 45, 63, 79, 97, 115}
@@ -80,6 +88,8 @@ func (ifdType IfdType) TagName(id tag.ID) string {
 		return exififd.TagString(id)
 	case GPSIFD:
 		return gpsifd.TagString(id)
+	case IopIFD:
+		return iopifd.TagString(id)
 	case MknoteIFD:
 		//case MkNoteCanonIFD:
 		return mknote.TagCanonString(id)
@@ -90,13 +100,13 @@ func (ifdType IfdType) TagName(id tag.ID) string {
 
 // Ifd is a Tiff Information directory. Contains Offset, Type, and Index.
 type Ifd struct {
-	Offset uint32
+	Offset uint64
 	Type   IfdType
 	Index  uint8
 }
 
 // NewIFD returns a new IFD from IfdType, index, and offset.
-func NewIFD(ifdType IfdType, index uint8, offset uint32) Ifd {
+func NewIFD(ifdType IfdType, index uint8, offset uint64) Ifd {
 	return Ifd{
 		Type:   ifdType,
 		Offset: offset,
@@ -143,6 +153,8 @@ func (ifd Ifd) ChildIfd(t tag.Tag) Ifd {
 		switch t.ID {
 		case exififd.MakerNote:
 			return NewIFD(MknoteIFD, 0, t.ValueOffset)
+		case exififd.InteroperabilityTag:
+			return NewIFD(IopIFD, 0, t.ValueOffset)
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
 	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/iopifd"
 	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
 	"github.com/evanoberholster/imagemeta/exif/tag"
 )
@@ -89,6 +90,7 @@ func TestIfdString(t *testing.T) {
 		tagTest(t, ifd, IFD0, ExifTag, "ExifTag")
 		tagTest(t, ifd, ExifIFD, exififd.ApertureValue, "ApertureValue")
 		tagTest(t, ifd, GPSIFD, gpsifd.GPSAltitude, "GPSAltitude")
+		tagTest(t, ifd, IopIFD, iopifd.InteroperabilityIndex, "InteroperabilityIndex")
 		tagTest(t, ifd, MknoteIFD, mknote.CanonAFInfo, "CanonAFInfo")
 		tagTest(t, ifd, 255, ExifTag, "0x8769")
 
@@ -102,6 +104,7 @@ func TestIfdString(t *testing.T) {
 		childIFDtest(t, ifd, NewIFD(SubIFD, 0, 0), IFD0, SubIFDs, true)
 
 		childIFDtest(t, ifd, NewIFD(MknoteIFD, 0, 0), ExifIFD, exififd.MakerNote, true)
+		childIFDtest(t, ifd, NewIFD(IopIFD, 0, 0), ExifIFD, exififd.InteroperabilityTag, true)
 		childIFDtest(t, ifd, NewIFD(v.exifIFD, 0, 0), NullIFD, ExifTag, false)
 	}
 }
@@ -113,6 +116,40 @@ func TestValidIfd(t *testing.T) {
 
 }
 
+func TestDisplayValue(t *testing.T) {
+	tests := []struct {
+		ifd     IfdType
+		id      tag.ID
+		tagType tag.Type
+		value   uint32
+		name    string
+		ok      bool
+	}{
+		{IFD0, Orientation, tag.TypeShort, 6, "Rotate 90 CW", true},
+		{SubIFD, Compression, tag.TypeShort, 7, "JPEG", true},
+		{ExifIFD, exififd.ExposureProgram, tag.TypeShort, 2, "Program AE", true},
+		{ExifIFD, exififd.Flash, tag.TypeShort, 0x19, "Auto, Fired", true},
+		{ExifIFD, exififd.CompositeImage, tag.TypeShort, 2, "General Composite Image", true},
+		{ExifIFD, exififd.FileSource, tag.TypeUndefined, 3, "Digital Camera", true},
+		{ExifIFD, exififd.ExposureProgram, tag.TypeShort, 12, "Unknown (12)", true},
+		{GPSIFD, gpsifd.GPSLatitudeRef, tag.TypeASCII, 'S', "South", true},
+		{GPSIFD, gpsifd.GPSDestBearingRef, tag.TypeASCII, 'T', "True North", true},
+		{GPSIFD, gpsifd.GPSLongitudeRef, tag.TypeASCII, 'X', "Unknown (X)", true},
+		{GPSIFD, gpsifd.GPSAltitudeRef, tag.TypeByte, 1, "Below Sea Level", true},
+		{ExifIFD, exififd.ISOSpeedRatings, tag.TypeShort, 100, "", false},
+		{ExifIFD, Orientation, tag.TypeShort, 1, "", false},
+	}
+	for _, v := range tests {
+		tg, err := tag.NewTag(v.id, v.tagType, 1, v.value, uint8(v.ifd))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name, ok := tg.DisplayValue(v.value); name != v.name || ok != v.ok {
+			t.Errorf("%s %s, Expected \"%s\" %t got \"%s\" %t", v.ifd, v.ifd.TagName(v.id), v.name, v.ok, name, ok)
+		}
+	}
+}
+
 func childIFDtest(t *testing.T, ifd Ifd, childIfd Ifd, testType IfdType, id tag.ID, a bool) {
 	if ifd.IsType(testType) {
 		if cIfd := ifd.ChildIfd(tag.Tag{ID: id}); cIfd != childIfd && a {
@@ -0,0 +1,31 @@
+// Package iopifd provides types for "RootIfd/ExifIfd/IopIfd"
+package iopifd
+
+import "github.com/evanoberholster/imagemeta/exif/tag"
+
+// TagString returns the string representation of a tag.ID
+func TagString(id tag.ID) string {
+	name, ok := TagIDMap[id]
+	if !ok {
+		return id.String()
+	}
+	return name
+}
+
+// TagIDMap is a Map of tag.ID to string for the InteroperabilityIfd tags
+var TagIDMap = map[tag.ID]string{
+	InteroperabilityIndex:   "InteroperabilityIndex",
+	InteroperabilityVersion: "InteroperabilityVersion",
+	RelatedImageFileFormat:  "RelatedImageFileFormat",
+	RelatedImageWidth:       "RelatedImageWidth",
+	RelatedImageHeight:      "RelatedImageHeight",
+}
+
+// Interoperability Tags; Interoperability Ifd
+const (
+	InteroperabilityIndex   tag.ID = 0x0001
+	InteroperabilityVersion tag.ID = 0x0002
+	RelatedImageFileFormat  tag.ID = 0x1000
+	RelatedImageWidth       tag.ID = 0x1001
+	RelatedImageHeight      tag.ID = 0x1002
+)
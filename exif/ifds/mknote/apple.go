@@ -0,0 +1,28 @@
+package mknote
+
+import (
+	"bytes"
+
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// AppleMkNoteHeader is followed by a 2 byte version, the byte order ("MM")
+// and the Makernote Ifd. Offsets are relative to the start of the Makernote.
+var AppleMkNoteHeader = []byte("Apple iOS\x00")
+
+// IsAppleMkNoteHeaderBytes returns true if buf begins with
+// "Apple iOS\0" the header of an Apple Makernote.
+func IsAppleMkNoteHeaderBytes(buf []byte) bool {
+	return bytes.HasPrefix(buf, AppleMkNoteHeader)
+}
+
+// Apple Makernote Tags
+const (
+	AppleMakerNoteVersion   tag.ID = 0x0001
+	AppleRunTime            tag.ID = 0x0003
+	AppleAccelerationVector tag.ID = 0x0008
+	AppleHDRImageType       tag.ID = 0x000a
+	AppleBurstUUID          tag.ID = 0x000b
+	AppleContentIdentifier  tag.ID = 0x0011
+	AppleImageUniqueID      tag.ID = 0x0015
+)
@@ -19,3 +19,23 @@ func TestString(t *testing.T) {
 		t.Errorf("Expected %s got %s", "0x1234", TagCanonString(0x1234))
 	}
 }
+
+func TestIsPentax(t *testing.T) {
+	for _, v := range []string{"AOC\x00MM", "PENTAX \x00II"} {
+		if !IsPentaxMkNoteHeaderBytes([]byte(v)) {
+			t.Errorf("Error identifying PentaxMkNoteHeaderBytes %q", v)
+		}
+	}
+	if IsPentaxMkNoteHeaderBytes([]byte("AOC")) {
+		t.Errorf("Error identifying PentaxMkNoteHeaderBytes %q", "AOC")
+	}
+}
+
+func TestIsApple(t *testing.T) {
+	if !IsAppleMkNoteHeaderBytes([]byte("Apple iOS\x00\x00\x01MM")) {
+		t.Errorf("Error identifying AppleMkNoteHeaderBytes %q", "Apple iOS")
+	}
+	if IsAppleMkNoteHeaderBytes([]byte("Apple")) {
+		t.Errorf("Error identifying AppleMkNoteHeaderBytes %q", "Apple")
+	}
+}
@@ -1,5 +1,7 @@
 package mknote
 
+import "github.com/evanoberholster/imagemeta/exif/tag"
+
 // IsNikonMkNoteHeaderBytes represents "Nikon" the first 5 bytes of the
 func IsNikonMkNoteHeaderBytes(buf []byte) bool {
 	return buf[0] == 'N' &&
@@ -8,3 +10,14 @@ func IsNikonMkNoteHeaderBytes(buf []byte) bool {
 		buf[3] == 'o' &&
 		buf[4] == 'n'
 }
+
+// Nikon Makernote Tags
+const (
+	NikonMakerNoteVersion tag.ID = 0x0001
+	NikonISO              tag.ID = 0x0002
+	NikonSerialNumber     tag.ID = 0x001d
+	NikonLensType         tag.ID = 0x0083
+	NikonLens             tag.ID = 0x0084
+	NikonShutterCount     tag.ID = 0x00a7
+	NikonLensData         tag.ID = 0x0098
+)
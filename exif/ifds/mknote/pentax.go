@@ -0,0 +1,35 @@
+package mknote
+
+import (
+	"bytes"
+
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// Pentax Makernote Headers
+var (
+	// PentaxMkNoteHeaderAOC is followed by the byte order ("MM", "II" or "  ")
+	// and the Makernote Ifd. Offsets are relative to the Exif Tiff Header.
+	PentaxMkNoteHeaderAOC = []byte("AOC\x00")
+
+	// PentaxMkNoteHeaderPentax is followed by the byte order and the Makernote Ifd.
+	// Offsets are relative to the start of the Makernote.
+	PentaxMkNoteHeaderPentax = []byte("PENTAX \x00")
+)
+
+// IsPentaxMkNoteHeaderBytes returns true if buf begins with
+// "AOC\0" or "PENTAX \0" the header of a Pentax Makernote.
+func IsPentaxMkNoteHeaderBytes(buf []byte) bool {
+	return bytes.HasPrefix(buf, PentaxMkNoteHeaderAOC) || bytes.HasPrefix(buf, PentaxMkNoteHeaderPentax)
+}
+
+// Pentax Makernote Tags
+const (
+	PentaxVersion            tag.ID = 0x0000
+	PentaxModelType          tag.ID = 0x0001
+	PentaxPreviewImageSize   tag.ID = 0x0002
+	PentaxPreviewImageLength tag.ID = 0x0003
+	PentaxPreviewImageStart  tag.ID = 0x0004
+	PentaxModelID            tag.ID = 0x0005
+	PentaxSerialNumber       tag.ID = 0x0229
+)
@@ -0,0 +1,17 @@
+package mknote
+
+import "github.com/evanoberholster/imagemeta/exif/tag"
+
+// SamsungMkNoteVersion is the value of the MakerNoteVersion tag, the first tag of
+// a Samsung Type2 Makernote.
+var SamsungMkNoteVersion = []byte("0100")
+
+// Samsung Type2 Makernote Tags
+const (
+	SamsungMakerNoteVersion tag.ID = 0x0001
+	SamsungDeviceType       tag.ID = 0x0002
+	SamsungModelID          tag.ID = 0x0003
+	SamsungFirmwareName     tag.ID = 0xa001
+	SamsungSerialNumber     tag.ID = 0xa002
+	SamsungLensType         tag.ID = 0xa003
+)
@@ -215,6 +215,67 @@ var RootIfdTagIDMap = map[tag.ID]string{
 	CacheVersion:                "CacheVersion",
 }
 
+// RootIfdTagValueMap is a Map of tag.ID to the names of the enumerated values
+// of the RootIfd tags
+var RootIfdTagValueMap = tag.ValueMap{
+	NewSubfileType: {
+		0: "Full-resolution image",
+		1: "Reduced-resolution image",
+		2: "Single page of multi-page image",
+		3: "Single page of multi-page reduced-resolution image",
+		4: "Transparency mask",
+	},
+	Compression: {
+		1:     "Uncompressed",
+		2:     "CCITT 1D",
+		3:     "T4/Group 3 Fax",
+		4:     "T6/Group 4 Fax",
+		5:     "LZW",
+		6:     "JPEG (old-style)",
+		7:     "JPEG",
+		8:     "Adobe Deflate",
+		32773: "PackBits",
+		34892: "Lossy JPEG",
+	},
+	PhotometricInterpretation: {
+		0:     "WhiteIsZero",
+		1:     "BlackIsZero",
+		2:     "RGB",
+		3:     "RGB Palette",
+		4:     "Transparency Mask",
+		5:     "CMYK",
+		6:     "YCbCr",
+		8:     "CIELab",
+		9:     "ICCLab",
+		10:    "ITULab",
+		32803: "Color Filter Array",
+		34892: "Linear Raw",
+	},
+	Orientation: {
+		1: "Horizontal (normal)",
+		2: "Mirror horizontal",
+		3: "Rotate 180",
+		4: "Mirror vertical",
+		5: "Mirror horizontal and rotate 270 CW",
+		6: "Rotate 90 CW",
+		7: "Mirror horizontal and rotate 90 CW",
+		8: "Rotate 270 CW",
+	},
+	PlanarConfiguration: {
+		1: "Chunky",
+		2: "Planar",
+	},
+	ResolutionUnit: {
+		1: "None",
+		2: "inches",
+		3: "cm",
+	},
+	YCbCrPositioning: {
+		1: "Centered",
+		2: "Co-sited",
+	},
+}
+
 // RootIFD TagIDs
 const (
 	ProcessingSoftware          tag.ID = 0x000b
@@ -0,0 +1,177 @@
+package exif
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// jsonValueLimit is the count of the largest non-ASCII value that is marshaled
+// as its values, larger values are marshaled as their size in bytes.
+const jsonValueLimit = 64
+
+// MarshalJSON implements the json.Marshaler interface. The tags are grouped by
+// IFD and keyed by tag name, with parsed and human-readable values. Enumerated
+// values are rendered with Tag.DisplayValue:
+//
+//	{"Ifd":{"Make":"Canon","Orientation":"Horizontal (normal)"},"Ifd/Exif":{"ExposureTime":"1/250","FNumber":2.8}}
+//
+// Groups of IFDs with an index other than 0 are named with the index, ie.
+// "Ifd-1" for the IFD of the thumbnail. Tags without a known name are keyed
+// by their ID, ie. "0xc4a5". Values with more than 64 elements, such as
+// makernote binary data, are marshaled as their size, ie. "(Binary data 252 bytes)".
+func (e *Data) MarshalJSON() ([]byte, error) {
+	groups := make(map[string]map[string]interface{})
+	err := e.Walk(func(ifd ifds.IfdType, idx uint8, t tag.Tag) error {
+		name := ifd.String()
+		if idx > 0 {
+			name = fmt.Sprintf("%s-%d", name, idx)
+		}
+		group, ok := groups[name]
+		if !ok {
+			group = make(map[string]interface{})
+			groups[name] = group
+		}
+		if v := e.jsonValue(ifd, t); v != nil {
+			group[ifd.TagName(t.ID)] = v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(groups)
+}
+
+// jsonValue returns the value of the tag t of ifd to be marshaled, or nil
+// if the value can not be parsed.
+func (e *Data) jsonValue(ifd ifds.IfdType, t tag.Tag) interface{} {
+	if t.UnitCount > jsonValueLimit && !t.IsType(tag.TypeASCII) && !t.IsType(tag.TypeASCIINoNul) {
+		return binaryValue(t.Size())
+	}
+	if v, err := e.enumValue(t); err == nil {
+		if name, ok := t.DisplayValue(v); ok {
+			return name
+		}
+	}
+	if ifd == ifds.ExifIFD {
+		if v, ok := e.exifJSONValue(t); ok {
+			return v
+		}
+	}
+
+	switch t.Type() {
+	case tag.TypeByte:
+		// GetTagValue reads BYTE values as ASCII, and []uint8 is marshaled as base64
+		if v, err := e.ParseUint8Values(t); err == nil {
+			if len(v) == 1 {
+				return v[0]
+			}
+			return bytesValue(v)
+		}
+		return nil
+	case tag.TypeUndefined:
+		b, err := e.RawTagBytes(t)
+		if err != nil {
+			return nil
+		}
+		if isPrintable(b) {
+			return string(trimNUL(b))
+		}
+		return bytesValue(b)
+	case tag.TypeRational:
+		if v, err := e.ParseRationalValues(t); err == nil {
+			f := make([]float64, len(v))
+			for i := range v {
+				f[i] = ratio(float64(v[i].Numerator), float64(v[i].Denominator))
+			}
+			return floatsValue(f)
+		}
+		return nil
+	case tag.TypeSignedRational:
+		if v, err := e.ParseSRationalValues(t); err == nil {
+			f := make([]float64, len(v))
+			for i := range v {
+				f[i] = ratio(float64(v[i].Numerator), float64(v[i].Denominator))
+			}
+			return floatsValue(f)
+		}
+		return nil
+	}
+	return e.GetTagValue(t)
+}
+
+// exifJSONValue returns the human-readable value of the rational tags of the
+// Exif IFD with a meta type, and true if t is one of them.
+func (e *Data) exifJSONValue(t tag.Tag) (interface{}, bool) {
+	switch t.ID {
+	case exififd.ExposureTime, exififd.FNumber, exififd.FocalLength, exififd.ExposureBiasValue:
+		n, d, err := e.ParseRationalValue(t)
+		if err != nil {
+			return nil, false
+		}
+		switch t.ID {
+		case exififd.ExposureTime:
+			return meta.NewShutterSpeed(n, d).String(), true
+		case exififd.FNumber:
+			return float32(meta.NewAperture(n, d)), true
+		case exififd.FocalLength:
+			return float32(meta.NewFocalLength(n, d)), true
+		default:
+			return meta.NewExposureBias(int16(n), int16(d)).String(), true
+		}
+	}
+	return nil, false
+}
+
+// bytesValue returns b as a slice of numbers.
+func bytesValue(b []byte) interface{} {
+	v := make([]uint16, len(b))
+	for i := range b {
+		v[i] = uint16(b[i])
+	}
+	return v
+}
+
+func binaryValue(size uint32) string {
+	return fmt.Sprintf("(Binary data %d bytes)", size)
+}
+
+func floatsValue(f []float64) interface{} {
+	if len(f) == 1 {
+		return f[0]
+	}
+	return f
+}
+
+func ratio(n, d float64) float64 {
+	if d == 0 {
+		return 0
+	}
+	return n / d
+}
+
+// isPrintable returns true if b is printable ASCII followed by optional NULs.
+func isPrintable(b []byte) bool {
+	b = trimNUL(b)
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func trimNUL(b []byte) []byte {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,72 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseExif(bytes.NewReader(buf), meta.NewExifHeader(binary.LittleEndian, 16, 0, 0, imagetype.ImageCR2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var groups map[string]map[string]interface{}
+	if err = json.Unmarshal(b, &groups); err != nil {
+		t.Fatal(err)
+	}
+
+	ifd0 := groups["Ifd"]
+	assert.Equal(t, "Canon", ifd0["Make"])
+	assert.Equal(t, "Canon EOS-1Ds Mark III", ifd0["Model"])
+	assert.Equal(t, "Horizontal (normal)", ifd0["Orientation"])
+	assert.Equal(t, "inches", ifd0["ResolutionUnit"])
+	assert.Equal(t, "JPEG (old-style)", ifd0["Compression"])
+	assert.Equal(t, 72.0, ifd0["XResolution"])
+	assert.Equal(t, []interface{}{8.0, 8.0, 8.0}, ifd0["BitsPerSample"])
+
+	exif := groups["Ifd/Exif"]
+	assert.Equal(t, "1/40", exif["ExposureTime"])
+	assert.Equal(t, 1.2, exif["FNumber"])
+	assert.Equal(t, 50.0, exif["FocalLength"])
+	assert.Equal(t, 100.0, exif["ISOSpeedRatings"])
+	assert.Equal(t, "Aperture-priority AE", exif["ExposureProgram"])
+	assert.Equal(t, "Multi-segment", exif["MeteringMode"])
+	assert.Equal(t, "No Flash", exif["Flash"])
+	assert.Equal(t, "Uncalibrated", exif["ColorSpace"])
+	assert.Equal(t, "Manual", exif["WhiteBalance"])
+	assert.Equal(t, "2007:10:18 13:44:32", exif["DateTimeOriginal"])
+	assert.Equal(t, "0221", exif["ExifVersion"])
+	assert.Equal(t, []interface{}{1.0, 2.0, 3.0, 0.0}, exif["ComponentsConfiguration"])
+	assert.Equal(t, "(Binary data 264 bytes)", exif["UserComment"])
+
+	assert.Equal(t, 36684.0, groups["Ifd-1"]["JPEGInterchangeFormat"])
+	assert.Equal(t, "R98", groups["Ifd/Iop"]["InteroperabilityIndex"])
+
+	tg, err := e.GetTag(ifds.ExifIFD, 0, exififd.ExposureProgram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Aperture-priority AE", e.DisplayValue(tg))
+	tg, err = e.GetTag(ifds.ExifIFD, 0, exififd.ISOSpeedRatings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "100", e.DisplayValue(tg))
+}
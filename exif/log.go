@@ -15,10 +15,10 @@ func isInfo() bool {
 	return InfoLogger != nil
 }
 
-func logTagInfo(ifd ifds.Ifd, t tag.Tag, offset uint32) {
+func logTagInfo(ifd ifds.Ifd, t tag.Tag, offset uint64) {
 	InfoLogger.Printf("Tag: %s\t Offset: x%.4x\t Name: %s\n", t, offset, ifd.TagName(t.ID))
 }
 
-func logIfdInfo(ifd ifds.Ifd, tagCount uint16, offset uint32) {
+func logIfdInfo(ifd ifds.Ifd, tagCount uint16, offset uint64) {
 	InfoLogger.Printf("Ifd: %s\t Offset: x%.4x\t TagCount: %d\n", ifd, offset, tagCount)
 }
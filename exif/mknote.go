@@ -1,11 +1,13 @@
 package exif
 
 import (
+	"bytes"
 	"encoding/binary"
 	"io"
 
 	"github.com/evanoberholster/imagemeta/exif/ifds"
 	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
+	"github.com/evanoberholster/imagemeta/exif/tag"
 	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 	"github.com/pkg/errors"
@@ -13,12 +15,34 @@ import (
 
 // Errors
 var (
-	ErrNikonMkNote = errors.New("error makernote is not a Nikon makernote")
+	ErrNikonMkNote    = errors.New("error makernote is not a Nikon makernote")
+	ErrNikonEncrypted = errors.New("error Nikon makernote value is encrypted")
+	ErrPentaxMkNote   = errors.New("error makernote is not a Pentax makernote")
+	ErrSamsungMkNote  = errors.New("error makernote is not a Samsung makernote")
+	ErrAppleMkNote    = errors.New("error makernote is not an Apple makernote")
 )
 
 const (
 	// Length of Nikon Makernote Header in bytes
 	lengthMkNoteHeaderNikon = 18
+
+	// Length of Nikon type 1 Makernote Header in bytes
+	lengthMkNoteHeaderNikonType1 = 8
+
+	// Offset of the embedded Tiff Header in a Nikon type 3 Makernote
+	lengthMkNoteNikonTiffHeader = 10
+
+	// Maximum number of entries of a Samsung Makernote Ifd
+	maxMkNoteSamsungEntries = 256
+
+	// Length of Pentax "AOC\0" Makernote Header in bytes
+	lengthMkNoteHeaderPentaxAOC = 6
+
+	// Length of Pentax "PENTAX \0" Makernote Header in bytes
+	lengthMkNoteHeaderPentax = 10
+
+	// Length of Apple "Apple iOS\0" Makernote Header with the version and byte order in bytes
+	lengthMkNoteHeaderApple = 14
 )
 
 // NikonMkNoteHeader parses the Nikon Makernote from reader and returns byteOrder and error
@@ -39,25 +63,123 @@ func NikonMkNoteHeader(reader io.Reader) (byteOrder binary.ByteOrder, err error)
 	return nil, ErrNikonMkNote
 }
 
-// isNikonMkNoteHeader parses the Nikon Makernote from reader and returns byteOrder and error
+// isNikonMkNoteHeader parses the Nikon Makernote from reader and returns byteOrder and error.
+//
+// Nikon type 3 makernotes ("Nikon\0" + version) embed a Tiff Header 10 bytes
+// into the makernote. The offsets of the makernote Ifd are relative to this
+// embedded Tiff Header and are rebased accordingly. Nikon type 1 makernotes
+// ("Nikon\0\x01\x00") are followed by an Ifd whose offsets are relative to the
+// Exif Tiff Header.
 func (r *reader) isNikonMkNoteHeader(ifd ifds.Ifd) (ifds.Ifd, binary.ByteOrder, error) {
 	// Nikon Makernotes header is 18 bytes. Move Reader up necessary bytes
-	mknoteHeader, err := r.ReadBufferAt(lengthMkNoteHeaderNikon, int(ifd.Offset))
+	mknoteHeader, err := r.ReadBufferAt(lengthMkNoteHeaderNikon, int64(ifd.Offset))
 	if err != nil {
 		return ifd, nil, errors.Wrapf(err, "error NikonMkNoteHeader at IFD %s", ifd.String())
 	}
 	// Nikon makernote header starts with "Nikon" with the first 5 bytes
 	if mknote.IsNikonMkNoteHeaderBytes(mknoteHeader[:5]) {
-		// Exif Header
+		// Type 3: Exif Header
 		if byteOrder := meta.BinaryOrder(mknoteHeader[10:14]); byteOrder != nil {
-			ifd.Offset += lengthMkNoteHeaderNikon
+			base := ifd.Offset + lengthMkNoteNikonTiffHeader
+			r.ifdExifOffset[ifd.Type] = base
+			ifd.Offset = base + uint64(byteOrder.Uint32(mknoteHeader[14:18]))
 			return ifd, byteOrder, nil
 		}
+		// Type 1: Ifd follows an 8 byte header
+		if mknoteHeader[5] == 0 && mknoteHeader[6] == 1 {
+			ifd.Offset += lengthMkNoteHeaderNikonType1
+			return ifd, r.byteOrder, nil
+		}
 	}
 
 	return ifd, nil, ErrNikonMkNote
 }
 
+// isPentaxMkNoteHeader parses the Pentax Makernote header and returns the Makernote Ifd and byteOrder.
+//
+// "AOC\0" Makernotes are followed by the byte order and an Ifd whose offsets are
+// relative to the Exif Tiff Header. "PENTAX \0" Makernotes are followed by the byte
+// order and an Ifd whose offsets are relative to the start of the Makernote.
+func (r *reader) isPentaxMkNoteHeader(ifd ifds.Ifd) (ifds.Ifd, binary.ByteOrder, error) {
+	mknoteHeader, err := r.ReadBufferAt(lengthMkNoteHeaderPentax, int64(ifd.Offset))
+	if err != nil {
+		return ifd, nil, errors.Wrapf(err, "error PentaxMkNoteHeader at IFD %s", ifd.String())
+	}
+	switch {
+	case bytes.HasPrefix(mknoteHeader, mknote.PentaxMkNoteHeaderAOC):
+		ifd.Offset += lengthMkNoteHeaderPentaxAOC
+		return ifd, mkNoteByteOrder(mknoteHeader[4:6], r.byteOrder), nil
+	case bytes.HasPrefix(mknoteHeader, mknote.PentaxMkNoteHeaderPentax):
+		r.ifdExifOffset[ifd.Type] = ifd.Offset
+		ifd.Offset += lengthMkNoteHeaderPentax
+		return ifd, mkNoteByteOrder(mknoteHeader[8:10], r.byteOrder), nil
+	}
+	return ifd, nil, ErrPentaxMkNote
+}
+
+// isAppleMkNoteHeader parses the Apple Makernote header and returns the Makernote Ifd and byteOrder.
+//
+// "Apple iOS\0" Makernotes are followed by a 2 byte version, the byte order and
+// an Ifd whose offsets are relative to the start of the Makernote.
+func (r *reader) isAppleMkNoteHeader(ifd ifds.Ifd) (ifds.Ifd, binary.ByteOrder, error) {
+	mknoteHeader, err := r.ReadBufferAt(lengthMkNoteHeaderApple, int64(ifd.Offset))
+	if err != nil {
+		return ifd, nil, errors.Wrapf(err, "error AppleMkNoteHeader at IFD %s", ifd.String())
+	}
+	if !mknote.IsAppleMkNoteHeaderBytes(mknoteHeader) {
+		return ifd, nil, ErrAppleMkNote
+	}
+	r.ifdExifOffset[ifd.Type] = ifd.Offset
+	ifd.Offset += lengthMkNoteHeaderApple
+	return ifd, mkNoteByteOrder(mknoteHeader[12:14], r.byteOrder), nil
+}
+
+// isSamsungMkNote parses the Samsung Type2 Makernote, an Ifd without a header that
+// begins with the MakerNoteVersion tag ("0100").
+//
+// Samsung uses offsets relative to the Exif Tiff Header for some models and relative
+// to the start of the Makernote for others. The offsets are relative to the Makernote
+// when a tag value is before the Makernote.
+func (r *reader) isSamsungMkNote(ifd ifds.Ifd) (ifds.Ifd, error) {
+	buf, err := r.ReadBufferAt(2, int64(ifd.Offset))
+	if err != nil {
+		return ifd, errors.Wrapf(err, "error SamsungMkNote at IFD %s", ifd.String())
+	}
+	count := uint32(r.byteOrder.Uint16(buf))
+	if count == 0 || count > maxMkNoteSamsungEntries {
+		return ifd, ErrSamsungMkNote
+	}
+	if buf, err = r.ReadBufferAt(int(count)*12, int64(ifd.Offset)+2); err != nil {
+		return ifd, errors.Wrapf(err, "error SamsungMkNote at IFD %s", ifd.String())
+	}
+	// MakerNoteVersion is UNDEFINED[4] with an embedded value
+	if tag.ID(r.byteOrder.Uint16(buf[0:2])) != mknote.SamsungMakerNoteVersion || !bytes.Equal(buf[8:12], mknote.SamsungMkNoteVersion) {
+		return ifd, ErrSamsungMkNote
+	}
+	mkNoteOffset := ifd.Offset - r.exifOffset
+	for i := uint32(0); i < count; i++ {
+		entry := buf[i*12 : i*12+12]
+		size := uint32(tag.Type(r.byteOrder.Uint16(entry[2:4])).Size()) * r.byteOrder.Uint32(entry[4:8])
+		if size > 4 && uint64(r.byteOrder.Uint32(entry[8:12])) < mkNoteOffset {
+			r.ifdExifOffset[ifd.Type] = ifd.Offset
+			break
+		}
+	}
+	return ifd, nil
+}
+
+// mkNoteByteOrder returns the byte order of "MM" or "II" in buf, or byteOrder
+// when buf has neither.
+func mkNoteByteOrder(buf []byte, byteOrder binary.ByteOrder) binary.ByteOrder {
+	switch string(buf) {
+	case "MM":
+		return binary.BigEndian
+	case "II":
+		return binary.LittleEndian
+	}
+	return byteOrder
+}
+
 func (r *reader) parseMknoteIFD(e *Data, ifd ifds.Ifd) (ifds.Ifd, binary.ByteOrder) {
 	if e.make == "" {
 		return ifd, nil
@@ -69,25 +191,93 @@ func (r *reader) parseMknoteIFD(e *Data, ifd ifds.Ifd) (ifds.Ifd, binary.ByteOrd
 		// ByteOrder is the same as RootIfd
 		return ifd, r.byteOrder
 	}
+	// Nikon makernotes are detected by their "Nikon\0" header, the camera
+	// make of Nikon cameras varies, ie. "NIKON" for Coolpix cameras.
+	// Nikon v3 maker note is a self-contained Ifd
+	// (offsets are relative to the embedded Tiff Header)
+	if ifd, byteOrder, err := r.isNikonMkNoteHeader(ifd); err == nil {
+		e.nikonMkNote = true
+		// update imagetype
+		if e.imageType == imagetype.ImageTiff {
+			e.imageType = imagetype.ImageNEF
+		}
+		return ifd, byteOrder
+	}
 	if e.make == "Nikon" || e.make == "NIKON CORPORATION" {
-		// Nikon v3 maker note is a self-contained Ifd
-		// (offsets are relative to the start of the maker note)
-
-		if ifd, byteOrder, err := r.isNikonMkNoteHeader(ifd); err == nil {
+		return ifd, nil
+	}
+	if e.make == "Sony" {
+		if e.imageType == imagetype.ImageTiff {
+			e.imageType = imagetype.ImageARW
+		}
+		return ifd, r.byteOrder
+	}
+	if e.isSamsung() {
+		if ifd, err := r.isSamsungMkNote(ifd); err == nil {
+			// update imagetype
+			if e.imageType == imagetype.ImageTiff {
+				e.imageType = imagetype.ImageSRW
+			}
+			return ifd, r.byteOrder
+		}
+		return ifd, nil
+	}
+	if e.isPentax() {
+		if ifd, byteOrder, err := r.isPentaxMkNoteHeader(ifd); err == nil {
 			// update imagetype
 			if e.imageType == imagetype.ImageTiff {
-				e.imageType = imagetype.ImageNEF
+				e.imageType = imagetype.ImagePEF
 			}
 			return ifd, byteOrder
 		}
 		return ifd, nil
 	}
-	if e.make == "Sony" {
-		if e.imageType == imagetype.ImageTiff {
-			e.imageType = imagetype.ImageARW
+	if e.isApple() {
+		if ifd, byteOrder, err := r.isAppleMkNoteHeader(ifd); err == nil {
+			return ifd, byteOrder
 		}
-		return ifd, r.byteOrder
+		return ifd, nil
 	}
 
 	return ifd, nil
 }
+
+// Length of the Sony Makernote Header ("SONY DSC \0\0\0") in bytes
+const lengthMkNoteHeaderSony = 12
+
+// makerNoteIfdOffset returns the offset of the Ifd in the makernote value of a
+// camera from make, for makernotes with offsets relative to the Exif Tiff Header.
+// Returns false for makernotes with their own Tiff Header, like Nikon type 3,
+// and for makernotes of other makes.
+func makerNoteIfdOffset(make string, value []byte) (uint32, bool) {
+	// Nikon Type 1: Ifd follows an 8 byte header
+	if len(value) >= lengthMkNoteHeaderNikonType1 && mknote.IsNikonMkNoteHeaderBytes(value[:5]) &&
+		value[5] == 0 && value[6] == 1 {
+		return lengthMkNoteHeaderNikonType1, true
+	}
+	switch make {
+	case "Canon":
+		return 0, true
+	case "Sony", "SONY":
+		if bytes.HasPrefix(value, []byte("SONY DSC ")) || bytes.HasPrefix(value, []byte("SONY CAM ")) {
+			return lengthMkNoteHeaderSony, true
+		}
+		return 0, true
+	}
+	if isPentaxMake(make) && bytes.HasPrefix(value, mknote.PentaxMkNoteHeaderAOC) {
+		return lengthMkNoteHeaderPentaxAOC, true
+	}
+	return 0, false
+}
+
+// MakerNote returns the offset from the start of the reader and the length
+// of the "IFD/Exif" MakerNote value, for reading makernotes that are not parsed.
+//
+// Returns ErrEmptyTag if there is no MakerNote.
+func (e *Data) MakerNote() (offset uint64, length uint32, err error) {
+	t := e.makerNote
+	if t.UnitCount == 0 {
+		return 0, 0, ErrEmptyTag
+	}
+	return e.reader.ifdExifOffset[ifds.ExifIFD] + t.ValueOffset, t.UnitCount, nil
+}
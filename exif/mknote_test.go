@@ -3,8 +3,16 @@ package exif
 import (
 	"bytes"
 	"encoding/binary"
+	"os"
 	"testing"
 
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/meta/nikon"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +36,117 @@ func TestNikonMkNoteHeader(t *testing.T) {
 		assert.Equal(t, v.bo, bo, v.name)
 	}
 }
+
+func TestNikonMakerNote(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/NEF.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseExif(bytes.NewReader(buf), meta.NewExifHeader(binary.LittleEndian, 8, 0, 0, imagetype.ImageNEF))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iso, err := e.NikonISO()
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, uint32(100), iso)
+
+	shutterCount, err := e.NikonShutterCount()
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, uint32(205), shutterCount)
+
+	// Out of line value relative to the embedded Tiff Header
+	serial, err := e.NikonSerialNumber()
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, "7302381", serial)
+
+	// LensData version "0204" is encrypted
+	_, err = e.NikonLensID()
+	assert.ErrorIs(t, err, ErrNikonEncrypted)
+}
+
+func TestNikonLensID(t *testing.T) {
+	lensTests := []struct {
+		name  string
+		nikon bool
+		buf   []byte
+		id    nikon.LensID
+		err   error
+	}{
+		{"0100", true, []byte{'0', '1', '0', '0', 0, 0, 0x06, 0x3c, 0x5c, 0x5c, 0x30, 0x30, 0x06, 0, 0}, nikon.LensID{0x06, 0x3c, 0x5c, 0x5c, 0x30, 0x30, 0x06, 0x06}, nil},
+		{"0101", true, []byte{'0', '1', '0', '1', 0, 0, 0, 0, 0, 0, 0, 0x78, 0x40, 0x37, 0x6e, 0x24, 0x24, 0x8e, 0}, nikon.LensID{0x78, 0x40, 0x37, 0x6e, 0x24, 0x24, 0x8e, 0x06}, nil},
+		{"Encrypted", true, []byte{'0', '2', '0', '4', 0x0f, 0x9c, 0xd6, 0xbd, 0x51, 0x92, 0x80, 0x4b, 0x63, 0x58, 0xfa, 0x49}, nikon.LensID{}, ErrNikonEncrypted},
+		{"Short", true, []byte{'0', '1', '0', '0', 0, 0, 0x06}, nikon.LensID{}, ErrEmptyTag},
+		{"NotNikon", false, []byte{'0', '1', '0', '0', 0, 0, 0x06, 0x3c, 0x5c, 0x5c, 0x30, 0x30, 0x06, 0, 0}, nikon.LensID{}, ErrEmptyTag},
+	}
+
+	for _, lt := range lensTests {
+		t.Run(lt.name, func(t *testing.T) {
+			// LensType is embedded in the ValueOffset
+			e := newData(newMockReader(lt.buf), imagetype.ImageUnknown)
+			e.nikonMkNote = lt.nikon
+			tg, err := tag.NewTag(mknote.NikonLensData, tag.TypeUndefined, uint32(len(lt.buf)), 0, uint8(ifds.IFD0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.tagMap[ifds.NewKey(ifds.MknoteIFD, 0, mknote.NikonLensData)] = tg
+			tg, err = tag.NewTag(mknote.NikonLensType, tag.TypeByte, 1, 0x06000000, uint8(ifds.IFD0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.tagMap[ifds.NewKey(ifds.MknoteIFD, 0, mknote.NikonLensType)] = tg
+
+			id, err := e.NikonLensID()
+			assert.ErrorIs(t, err, lt.err)
+			assert.Equal(t, lt.id, id)
+		})
+	}
+}
+
+func TestAppleMakerNote(t *testing.T) {
+	const id = "6A7B6B05-8E6B-4F6A-9C1B-2A3C4D5E6F70"
+	// Apple Makernote offsets are relative to the start of the Makernote
+	mkNote := append([]byte("Apple iOS\x00\x00\x01MM"), 0, 2)
+	entry := func(id tag.ID, t tag.Type, count, value uint32) []byte {
+		buf := make([]byte, 12)
+		binary.BigEndian.PutUint16(buf[0:], uint16(id))
+		binary.BigEndian.PutUint16(buf[2:], uint16(t))
+		binary.BigEndian.PutUint32(buf[4:], count)
+		binary.BigEndian.PutUint32(buf[8:], value)
+		return buf
+	}
+	mkNote = append(mkNote, entry(mknote.AppleMakerNoteVersion, tag.TypeLong, 1, 14)...)
+	mkNote = append(mkNote, entry(mknote.AppleContentIdentifier, tag.TypeASCII, uint32(len(id)+1), 44)...)
+	mkNote = append(append(append(mkNote, 0, 0, 0, 0), id...), 0)
+
+	for _, byteOrder := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		b := NewBuilder(byteOrder)
+		assert.NoError(t, b.SetCamera("Apple", "iPhone 12"))
+		assert.NoError(t, b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, mkNote))
+		buf, err := b.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := ParseTIFF(bytes.NewReader(buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		contentID, err := e.AppleContentIdentifier()
+		assert.ErrorIs(t, err, nil)
+		assert.Equal(t, id, contentID)
+	}
+
+	// Not an Apple camera
+	b := NewBuilder(binary.BigEndian)
+	assert.NoError(t, b.SetCamera("Canon", "Canon EOS 6D"))
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseTIFF(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.AppleContentIdentifier()
+	assert.ErrorIs(t, err, ErrEmptyTag)
+}
@@ -0,0 +1,109 @@
+package exif
+
+import (
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
+	"github.com/evanoberholster/imagemeta/meta/nikon"
+)
+
+// isNikon returns true if the MakerNote is a Nikon MakerNote
+func (e *Data) isNikon() bool {
+	return e.nikonMkNote
+}
+
+// IsNikonMakerNote returns true if the MakerNote has a Nikon MakerNote
+// header ("Nikon\0"), regardless of the camera make.
+func (e *Data) IsNikonMakerNote() bool {
+	return e.nikonMkNote
+}
+
+// NikonISO convenience func. "IFD/Exif/Makernotes.Nikon" ISO
+// ISO speed from the Makernote
+func (e *Data) NikonISO() (uint32, error) {
+	if !e.isNikon() {
+		return 0, ErrEmptyTag
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.NikonISO)
+	if err != nil {
+		return 0, err
+	}
+	ii, err := e.ParseUint16Values(t)
+	if len(ii) < 2 || err != nil {
+		return 0, ErrEmptyTag
+	}
+	return uint32(ii[1]), nil
+}
+
+// NikonShutterCount convenience func. "IFD/Exif/Makernotes.Nikon" ShutterCount
+// Camera shutter count from the Makernote
+func (e *Data) NikonShutterCount() (uint32, error) {
+	if !e.isNikon() {
+		return 0, ErrEmptyTag
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.NikonShutterCount)
+	if err != nil {
+		return 0, err
+	}
+	return e.ParseUint32Value(t)
+}
+
+// NikonSerialNumber convenience func. "IFD/Exif/Makernotes.Nikon" SerialNumber
+// Camera serial number from the Makernote
+func (e *Data) NikonSerialNumber() (string, error) {
+	if !e.isNikon() {
+		return "", ErrEmptyTag
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.NikonSerialNumber)
+	if err != nil {
+		return "", err
+	}
+	return e.ParseASCIIValue(t)
+}
+
+// NikonLensID convenience func. "IFD/Exif/Makernotes.Nikon" LensData and LensType
+// Composite Nikon Lens ID from the Makernote.
+//
+// LensData versions "0100" and "0101" are read directly. Later versions are
+// encrypted with the camera's SerialNumber and ShutterCount and return
+// ErrNikonEncrypted; decryption is not supported.
+func (e *Data) NikonLensID() (id nikon.LensID, err error) {
+	if !e.isNikon() {
+		return id, ErrEmptyTag
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.NikonLensData)
+	if err != nil {
+		return id, err
+	}
+	buf, err := e.RawTagBytes(t)
+	if err != nil {
+		return id, err
+	}
+	if len(buf) < 4 {
+		return id, ErrEmptyTag
+	}
+
+	// LensIDNumber, LensFStops, MinFocalLength, MaxFocalLength,
+	// MaxApertureAtMinFocal, MaxApertureAtMaxFocal and MCUVersion
+	var start int
+	switch string(buf[:4]) {
+	case "0100":
+		start = 0x06
+	case "0101":
+		start = 0x0b
+	default:
+		return id, ErrNikonEncrypted
+	}
+	if len(buf) < start+7 {
+		return id, ErrEmptyTag
+	}
+	copy(id[:7], buf[start:start+7])
+
+	// LensType
+	if t, err = e.GetTag(ifds.MknoteIFD, 0, mknote.NikonLensType); err == nil {
+		var lensType []byte
+		if lensType, err = e.RawTagBytes(t); err == nil && len(lensType) > 0 {
+			id[7] = lensType[0]
+		}
+	}
+	return id, nil
+}
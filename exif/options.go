@@ -0,0 +1,72 @@
+package exif
+
+import (
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// IfdMask is a bit mask of the Ifds to scan when parsing Exif.
+type IfdMask uint16
+
+// Ifds that can be selected with Options.IFDs
+const (
+	IFD0      IfdMask = 1 << ifds.IFD0
+	SubIFD    IfdMask = 1 << ifds.SubIFD
+	ExifIFD   IfdMask = 1 << ifds.ExifIFD
+	GPSIFD    IfdMask = 1 << ifds.GPSIFD
+	IopIFD    IfdMask = 1 << ifds.IopIFD
+	MknoteIFD IfdMask = 1 << ifds.MknoteIFD
+	// IFD1 is the Ifd that follows IFD0, usually the thumbnail.
+	IFD1 IfdMask = 1 << 15
+
+	// AllIFDs scans all Ifds. This is the default.
+	AllIFDs IfdMask = 0xffff
+)
+
+// Has returns true if the mask includes the Ifd of ifdType.
+func (m IfdMask) Has(ifdType ifds.IfdType) bool {
+	return m&(1<<ifdType) != 0
+}
+
+// Options for ParseExifWithOptions
+type Options struct {
+	// IFDs are the Ifds to scan. Pointers to Ifds that are not
+	// included are not followed. The first Ifd is always scanned.
+	// A zero value scans all Ifds.
+	IFDs IfdMask
+
+	// Strict returns a *ValidationError for malformed Exif data
+	// that is skipped by default. See ParseExifStrict.
+	Strict bool
+}
+
+// ParseExifWithOptions parses Exif metadata from an io.ReaderAt and a TiffHeader
+// only scanning the Ifds selected in opts.
+//
+//	e, err := exif.ParseExifWithOptions(r, header, exif.Options{IFDs: exif.IFD0 | exif.ExifIFD})
+//
+// If the header is invalid ParseExifWithOptions will return ErrInvalidHeader.
+func ParseExifWithOptions(r io.ReaderAt, header meta.ExifHeader, opts Options) (*Data, error) {
+	if !header.IsValid() {
+		return nil, ErrInvalidHeader
+	}
+
+	if header.FirstIfd == ifds.NullIFD {
+		header.FirstIfd = ifds.IFD0
+	}
+
+	reader := newReader(r, header)
+	if opts.IFDs != 0 {
+		reader.ifdMask = opts.IFDs
+	}
+	reader.strict = opts.Strict
+
+	e := newData(reader, header.ImageType)
+
+	// Scan the FirstIfd with the FirstIfdOffset from the ExifReader
+	err := reader.scanIFD(e, ifds.NewIFD(header.FirstIfd, 0, header.FirstIfdOffset))
+
+	return e, err
+}
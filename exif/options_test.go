@@ -0,0 +1,102 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExifWithOptions(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/NEF.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := meta.NewExifHeader(binary.LittleEndian, 8, 0, 0, imagetype.ImageNEF)
+
+	countIfds := func(e *Data) map[ifds.IfdType]int {
+		c := make(map[ifds.IfdType]int)
+		for k := range e.tagMap {
+			ifdType, ifdIndex, _ := k.Val()
+			if ifdType == ifds.IFD0 && ifdIndex > 0 {
+				c[ifds.NullIFD]++ // IFD1
+				continue
+			}
+			c[ifdType]++
+		}
+		return c
+	}
+
+	all, err := ParseExif(bytes.NewReader(buf), header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allCount := countIfds(all)
+
+	optionTests := []struct {
+		name    string
+		mask    IfdMask
+		include []ifds.IfdType
+		exclude []ifds.IfdType
+	}{
+		{"Default", 0, []ifds.IfdType{ifds.IFD0, ifds.ExifIFD, ifds.MknoteIFD, ifds.SubIFD, ifds.NullIFD}, nil},
+		{"IFD0", IFD0, []ifds.IfdType{ifds.IFD0}, []ifds.IfdType{ifds.ExifIFD, ifds.MknoteIFD, ifds.SubIFD, ifds.GPSIFD, ifds.NullIFD}},
+		{"IFD0|ExifIFD", IFD0 | ExifIFD, []ifds.IfdType{ifds.IFD0, ifds.ExifIFD}, []ifds.IfdType{ifds.MknoteIFD, ifds.SubIFD, ifds.NullIFD}},
+		{"IFD0|IFD1", IFD0 | IFD1, []ifds.IfdType{ifds.IFD0, ifds.NullIFD}, []ifds.IfdType{ifds.ExifIFD, ifds.MknoteIFD}},
+	}
+	for _, ot := range optionTests {
+		t.Run(ot.name, func(t *testing.T) {
+			e, err := ParseExifWithOptions(bytes.NewReader(buf), header, Options{IFDs: ot.mask})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := countIfds(e)
+			for _, ifdType := range ot.include {
+				assert.Equal(t, allCount[ifdType], c[ifdType], "included %s", ifdType)
+			}
+			for _, ifdType := range ot.exclude {
+				assert.Equal(t, 0, c[ifdType], "excluded %s", ifdType)
+			}
+		})
+	}
+
+	// Orientation and Make from IFD0
+	e, err := ParseExifWithOptions(bytes.NewReader(buf), header, Options{IFDs: IFD0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.GetTag(ifds.IFD0, 0, ifds.Orientation)
+	assert.ErrorIs(t, err, nil)
+	assert.Equal(t, "NIKON CORPORATION", e.make)
+}
+
+func BenchmarkParseExifWithOptions(b *testing.B) {
+	buf, err := os.ReadFile("../testImages/NEF.exif")
+	if err != nil {
+		b.Fatal(err)
+	}
+	header := meta.NewExifHeader(binary.LittleEndian, 8, 0, 0, imagetype.ImageNEF)
+	r := bytes.NewReader(buf)
+
+	for _, bm := range []struct {
+		name string
+		mask IfdMask
+	}{
+		{"AllIFDs", AllIFDs},
+		{"IFD0", IFD0},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseExifWithOptions(r, header, Options{IFDs: bm.mask}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
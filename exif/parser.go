@@ -1,7 +1,11 @@
 package exif
 
 import (
+	"bytes"
+	"encoding/binary"
+	"math"
 	"time"
+	"unicode/utf16"
 
 	"github.com/evanoberholster/imagemeta/exif/ifds"
 	"github.com/evanoberholster/imagemeta/exif/tag"
@@ -135,15 +139,93 @@ func (e *Data) ParseGPSCoord(refTag tag.Tag, coordTag tag.Tag) (coord float64, e
 }
 
 // ParseSubSec parses a Subsecond Tag and returns an int in Nanoseconds.
+// The digits are the decimal fraction of a second, ie. "25" is 250 milliseconds.
 // Returns ErrParseSubSecond if an err occurs
 func (e *Data) ParseSubSec(subSec tag.Tag) (int, error) {
 	if subSec.Type() == tag.TypeASCII && subSec.IsEmbedded() {
 		buf := e.reader.embeddedTagValue(subSec.ValueOffset)
-		return int(parseUint(buf) * 1000000), nil
+		if int(subSec.UnitCount) < len(buf) {
+			buf = buf[:subSec.UnitCount]
+		}
+		ns, digits := 0, 0
+		for _, c := range buf {
+			if c < '0' || c > '9' {
+				break
+			}
+			ns = ns*10 + int(c-'0')
+			digits++
+		}
+		for ; digits < 9; digits++ {
+			ns *= 10
+		}
+		return ns, nil
 	}
 	return 0, ErrParseSubSecond
 }
 
+// Character codes of encoded string tags
+var (
+	charCodeASCII     = []byte{'A', 'S', 'C', 'I', 'I', 0, 0, 0}
+	charCodeUnicode   = []byte{'U', 'N', 'I', 'C', 'O', 'D', 'E', 0}
+	charCodeJIS       = []byte{'J', 'I', 'S', 0, 0, 0, 0, 0}
+	charCodeUndefined = []byte{0, 0, 0, 0, 0, 0, 0, 0}
+)
+
+// ParseEncodedString parses a string that starts with an 8 byte character code
+// (ie. "IFD/GPS" GPSProcessingMethod and "IFD/Exif" UserComment). The character code is
+// removed and "UNICODE" values are decoded from UTF-16 in the byte order of the Exif
+// data unless a byte order mark is present. "ASCII", "JIS" and undefined values are
+// returned as is. Values without a character code are returned as is.
+func (e *Data) ParseEncodedString(t tag.Tag) (string, error) {
+	switch t.Type() {
+	case tag.TypeASCII, tag.TypeASCIINoNul:
+		return e.ParseASCIIValue(t)
+	case tag.TypeUndefined, tag.TypeByte:
+	default:
+		return "", tag.ErrTagTypeNotValid
+	}
+	buf, err := e.reader.ReadValue(t)
+	if err != nil {
+		return "", errors.Wrap(err, "ParseEncodedString")
+	}
+	if len(buf) > int(t.Size()) {
+		buf = buf[:t.Size()]
+	}
+	if len(buf) < len(charCodeASCII) {
+		return string(trim(buf)), nil
+	}
+
+	code, value := buf[:8], buf[8:]
+	switch {
+	case bytes.Equal(code, charCodeUnicode):
+		return decodeUTF16(value, e.reader.byteOrder), nil
+	case bytes.Equal(code, charCodeASCII), bytes.Equal(code, charCodeJIS), bytes.Equal(code, charCodeUndefined):
+		return string(trim(value)), nil
+	}
+	return string(trim(buf)), nil
+}
+
+// decodeUTF16 decodes a UTF-16 buffer with byteOrder or with its byte order mark.
+func decodeUTF16(buf []byte, byteOrder binary.ByteOrder) string {
+	if len(buf) >= 2 {
+		switch {
+		case buf[0] == 0xfe && buf[1] == 0xff:
+			byteOrder, buf = binary.BigEndian, buf[2:]
+		case buf[0] == 0xff && buf[1] == 0xfe:
+			byteOrder, buf = binary.LittleEndian, buf[2:]
+		}
+	}
+	u := make([]uint16, 0, len(buf)/2)
+	for i := 0; i+1 < len(buf); i += 2 {
+		u = append(u, byteOrder.Uint16(buf[i:]))
+	}
+	// Trim trailing spaces and null values
+	for len(u) > 0 && (u[len(u)-1] == 0 || u[len(u)-1] == 0x20) {
+		u = u[:len(u)-1]
+	}
+	return string(utf16.Decode(u))
+}
+
 ////
 // Low-Level Parsers
 ////
@@ -176,7 +258,8 @@ func (e *Data) ParseUint16Value(t tag.Tag) (uint16, error) {
 }
 
 // ParseUint32Value returns the Short or Long value of the tag as a uint32
-// and returns an error if it encounters one.
+// and returns an error if it encounters one. The BigTiff Long8 value is
+// returned when it fits in a uint32, otherwise returns ErrBigTiffOffset.
 //
 // Warning: it returns only the first value if there are more values
 // use Uint16Values (Short) or Unit32Values (Long) function
@@ -196,7 +279,13 @@ func (e *Data) ParseUint32Value(t tag.Tag) (value uint32, err error) {
 			value = byteOrder.Uint32(buf[:4])
 			return
 		}
-
+		if t.Type() == tag.TypeLong8 {
+			v := byteOrder.Uint64(buf[:8])
+			if v > math.MaxUint32 {
+				return 0, ErrBigTiffOffset
+			}
+			return uint32(v), nil
+		}
 	}
 	return 0, tag.ErrTagTypeNotValid
 }
@@ -247,6 +336,211 @@ func (e *Data) ParseUint32Values(t tag.Tag) (value []uint32, err error) {
 	return nil, tag.ErrTagTypeNotValid
 }
 
+// ParseUint8Values parses the Byte value of the tag as a uint8 array
+// and returns an error if it encounters one.
+func (e *Data) ParseUint8Values(t tag.Tag) (value []uint8, err error) {
+	if t.IsType(tag.TypeByte) {
+		var buf []byte
+		if buf, err = e.reader.ReadValue(t); err != nil {
+			return nil, err
+		}
+
+		value = make([]uint8, t.UnitCount)
+		copy(value, buf)
+		return
+	}
+	return nil, tag.ErrTagTypeNotValid
+}
+
+// ParseInt8Value returns the Signed Byte value of the tag as an int8
+// and returns an error if it encounters one.
+//
+// Warning: it returns an error if there are more values
+// use ParseInt8Values function
+func (e *Data) ParseInt8Value(t tag.Tag) (int8, error) {
+	if t.IsType(tag.TypeSignedByte) && t.UnitCount == 1 {
+		v, err := e.ParseInt8Values(t)
+		if err != nil {
+			return 0, err
+		}
+		return v[0], nil
+	}
+	return 0, tag.ErrTagTypeNotValid
+}
+
+// ParseInt8Values parses the Signed Byte value of the tag as an int8 array
+// and returns an error if it encounters one.
+func (e *Data) ParseInt8Values(t tag.Tag) (value []int8, err error) {
+	if t.IsType(tag.TypeSignedByte) {
+		var buf []byte
+		if buf, err = e.reader.ReadValue(t); err != nil {
+			return nil, err
+		}
+
+		count := int(t.UnitCount)
+
+		value = make([]int8, count)
+		for i := 0; i < count; i++ {
+			value[i] = int8(buf[i])
+		}
+
+		return
+	}
+	return nil, tag.ErrTagTypeNotValid
+}
+
+// ParseInt16Value returns the Signed Short value of the tag as an int16
+// and returns an error if it encounters one.
+//
+// Warning: it returns an error if there are more values
+// use ParseInt16Values function
+func (e *Data) ParseInt16Value(t tag.Tag) (int16, error) {
+	if t.IsType(tag.TypeSignedShort) && t.UnitCount == 1 {
+		v, err := e.ParseInt16Values(t)
+		if err != nil {
+			return 0, err
+		}
+		return v[0], nil
+	}
+	return 0, tag.ErrTagTypeNotValid
+}
+
+// ParseInt16Values parses the Signed Short value of the tag as an int16 array
+// and returns an error if it encounters one.
+func (e *Data) ParseInt16Values(t tag.Tag) (value []int16, err error) {
+	if t.IsType(tag.TypeSignedShort) {
+		var buf []byte
+		if buf, err = e.reader.ReadValue(t); err != nil {
+			return nil, err
+		}
+
+		byteOrder := e.reader.byteOrder
+		count := int(t.UnitCount)
+
+		value = make([]int16, count)
+		for i := 0; i < count; i++ {
+			value[i] = int16(byteOrder.Uint16(buf[i*2:]))
+		}
+
+		return
+	}
+	return nil, tag.ErrTagTypeNotValid
+}
+
+// ParseInt32Value returns the Signed Long value of the tag as an int32
+// and returns an error if it encounters one.
+//
+// Warning: it returns an error if there are more values
+// use ParseInt32Values function
+func (e *Data) ParseInt32Value(t tag.Tag) (int32, error) {
+	if t.IsType(tag.TypeSignedLong) && t.UnitCount == 1 {
+		v, err := e.ParseInt32Values(t)
+		if err != nil {
+			return 0, err
+		}
+		return v[0], nil
+	}
+	return 0, tag.ErrTagTypeNotValid
+}
+
+// ParseInt32Values parses the Signed Long value of the tag as an int32 array
+// and returns an error if it encounters one.
+func (e *Data) ParseInt32Values(t tag.Tag) (value []int32, err error) {
+	if t.IsType(tag.TypeSignedLong) {
+		var buf []byte
+		if buf, err = e.reader.ReadValue(t); err != nil {
+			return nil, err
+		}
+
+		byteOrder := e.reader.byteOrder
+		count := int(t.UnitCount)
+
+		value = make([]int32, count)
+		for i := 0; i < count; i++ {
+			value[i] = int32(byteOrder.Uint32(buf[i*4:]))
+		}
+
+		return
+	}
+	return nil, tag.ErrTagTypeNotValid
+}
+
+// ParseFloatValue returns the Float value of the tag as a float32
+// and returns an error if it encounters one.
+//
+// Warning: it returns an error if there are more values
+// use ParseFloatValues function
+func (e *Data) ParseFloatValue(t tag.Tag) (float32, error) {
+	if t.IsType(tag.TypeFloat) && t.UnitCount == 1 {
+		v, err := e.ParseFloatValues(t)
+		if err != nil {
+			return 0, err
+		}
+		return v[0], nil
+	}
+	return 0, tag.ErrTagTypeNotValid
+}
+
+// ParseFloatValues parses the Float value of the tag as a float32 array
+// and returns an error if it encounters one.
+func (e *Data) ParseFloatValues(t tag.Tag) (value []float32, err error) {
+	if t.IsType(tag.TypeFloat) {
+		var buf []byte
+		if buf, err = e.reader.ReadValue(t); err != nil {
+			return nil, err
+		}
+
+		byteOrder := e.reader.byteOrder
+		count := int(t.UnitCount)
+
+		value = make([]float32, count)
+		for i := 0; i < count; i++ {
+			value[i] = math.Float32frombits(byteOrder.Uint32(buf[i*4:]))
+		}
+
+		return
+	}
+	return nil, tag.ErrTagTypeNotValid
+}
+
+// ParseDoubleValue returns the Double value of the tag as a float64
+// and returns an error if it encounters one.
+//
+// Warning: it returns an error if there are more values
+// use ParseDoubleValues function
+func (e *Data) ParseDoubleValue(t tag.Tag) (float64, error) {
+	if t.IsType(tag.TypeDouble) && t.UnitCount == 1 {
+		v, err := e.ParseDoubleValues(t)
+		if err != nil {
+			return 0, err
+		}
+		return v[0], nil
+	}
+	return 0, tag.ErrTagTypeNotValid
+}
+
+// ParseDoubleValues parses the Double value of the tag as a float64 array
+// and returns an error if it encounters one.
+func (e *Data) ParseDoubleValues(t tag.Tag) (value []float64, err error) {
+	if t.IsType(tag.TypeDouble) {
+		var buf []byte
+		if buf, err = e.reader.ReadValue(t); err != nil {
+			return nil, err
+		}
+
+		byteOrder := e.reader.byteOrder
+		count := int(t.UnitCount)
+
+		value = make([]float64, count)
+		for i := 0; i < count; i++ {
+			value[i] = math.Float64frombits(byteOrder.Uint64(buf[i*8:]))
+		}
+
+		return
+	}
+	return nil, tag.ErrTagTypeNotValid
+}
+
 // ParseRationalValue parses the Rational value and returns a
 // numerator and denominator for a single Unsigned Rational
 func (e *Data) ParseRationalValue(t tag.Tag) (n, d uint32, err error) {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"math"
 	"testing"
 	"time"
 
@@ -51,6 +52,28 @@ func TestParseTimeStamp(t *testing.T) {
 	}
 
 }
+func TestParseSubSec(t *testing.T) {
+	tests := []struct {
+		value string
+		count uint32
+		ns    int
+	}{
+		{"250\x00", 4, 250000000},
+		{"25\x00\x00", 3, 250000000},
+		{"7\x00\x00\x00", 2, 700000000},
+		{"0123", 4, 12300000},
+	}
+	d := newData(newMockReader(nil), imagetype.ImageUnknown)
+	for _, test := range tests {
+		ta, _ := tag.NewTag(ifds.DateTimeDigitized, tag.TypeASCII, test.count, binary.BigEndian.Uint32([]byte(test.value)), 0)
+		ns, err := d.ParseSubSec(ta)
+		assert.NoError(t, err, test.value)
+		assert.Equal(t, test.ns, ns, test.value)
+	}
+	_, err := d.ParseSubSec(tag.Tag{})
+	assert.ErrorIs(t, err, ErrParseSubSecond)
+}
+
 func TestParseGPSTimeStamp(t *testing.T) {
 	parseGPSTimeStampTests := []struct {
 		ds  []byte
@@ -318,6 +341,69 @@ func TestParseSRationalValues(t *testing.T) {
 	}
 }
 
+func TestGetTagValue(t *testing.T) {
+	buf := make([]byte, 24)
+	binary.BigEndian.PutUint32(buf[0:], math.Float32bits(1.5))
+	binary.BigEndian.PutUint32(buf[4:], math.Float32bits(-0.25))
+	binary.BigEndian.PutUint64(buf[8:], math.Float64bits(3.25))
+	binary.BigEndian.PutUint64(buf[16:], math.Float64bits(-1e-3))
+	slong := []byte{255, 255, 255, 246, 0, 0, 0, 16}
+
+	tests := []struct {
+		name string
+		data []byte
+		tag  tag.Tag
+		val  interface{}
+	}{
+		{"SignedByte", nil, newTestTag(tag.TypeSignedByte, 1, 0xff000000), int8(-1)},
+		{"SignedBytes", nil, newTestTag(tag.TypeSignedByte, 3, 0x7f80fe00), []int8{127, -128, -2}},
+		{"SignedShort", nil, newTestTag(tag.TypeSignedShort, 1, 0xfffe0000), int16(-2)},
+		{"SignedShorts", nil, newTestTag(tag.TypeSignedShort, 2, 0xfffe0003), []int16{-2, 3}},
+		{"SignedLong", nil, newTestTag(tag.TypeSignedLong, 1, 0xfffffff6), int32(-10)},
+		{"SignedLongs", slong, newTestTag(tag.TypeSignedLong, 2, 0), []int32{-10, 16}},
+		{"Float", nil, newTestTag(tag.TypeFloat, 1, math.Float32bits(1.5)), float32(1.5)},
+		{"Floats", buf, newTestTag(tag.TypeFloat, 2, 0), []float32{1.5, -0.25}},
+		{"Double", buf, newTestTag(tag.TypeDouble, 1, 8), float64(3.25)},
+		{"Doubles", buf, newTestTag(tag.TypeDouble, 2, 8), []float64{3.25, -1e-3}},
+		{"ASCII", []byte("Hello"), newTestTag(tag.TypeASCII, 5, 0), "Hello"},
+		{"Double EOF", nil, newTestTag(tag.TypeDouble, 1, 8), float64(0)},
+		{"Doubles EOF", nil, newTestTag(tag.TypeDouble, 2, 8), []float64(nil)},
+	}
+	for _, v := range tests {
+		d := newData(newMockReader(v.data), imagetype.ImageUnknown)
+		assert.Equal(t, v.val, d.GetTagValue(v.tag), v.name)
+	}
+}
+
+func TestParseSignedAndFloatValue(t *testing.T) {
+	d := newData(newMockReader(nil), imagetype.ImageUnknown)
+	i8, err := d.ParseInt8Value(newTestTag(tag.TypeSignedByte, 1, 0xfe000000))
+	assert.NoError(t, err)
+	assert.Equal(t, int8(-2), i8)
+	i16, err := d.ParseInt16Value(newTestTag(tag.TypeSignedShort, 1, 0xfffe0000))
+	assert.NoError(t, err)
+	assert.Equal(t, int16(-2), i16)
+	i32, err := d.ParseInt32Value(newTestTag(tag.TypeSignedLong, 1, 0xfffffff6))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(-10), i32)
+	f, err := d.ParseFloatValue(newTestTag(tag.TypeFloat, 1, math.Float32bits(1.5)))
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1.5), f)
+
+	// More than one value or a different type
+	_, err = d.ParseInt16Value(newTestTag(tag.TypeSignedShort, 2, 0xfffe0003))
+	assert.ErrorIs(t, err, tag.ErrTagTypeNotValid)
+	_, err = d.ParseInt32Value(newTestTag(tag.TypeLong, 1, 1))
+	assert.ErrorIs(t, err, tag.ErrTagTypeNotValid)
+	_, err = d.ParseDoubleValue(newTestTag(tag.TypeDouble, 1, 8))
+	assert.Error(t, err)
+}
+
+func newTestTag(tagType tag.Type, unitCount uint32, valueOffset uint32) tag.Tag {
+	t, _ := tag.NewTag(ifds.ActiveArea, tagType, unitCount, valueOffset, 0)
+	return t
+}
+
 func TestTrim(t *testing.T) {
 	// Test Trim
 	a := []byte{'a', 'b', 'c', 'd', '.', ' '}
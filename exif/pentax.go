@@ -0,0 +1,52 @@
+package exif
+
+import (
+	"strings"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
+)
+
+// isPentax returns true if the camera make is Pentax
+func (e *Data) isPentax() bool {
+	return isPentaxMake(e.make)
+}
+
+// isPentaxMake returns true for the makes of Pentax cameras, ie. "PENTAX Corporation",
+// "PENTAX" and "RICOH IMAGING COMPANY, LTD.".
+func isPentaxMake(make string) bool {
+	make = strings.ToUpper(make)
+	return strings.HasPrefix(make, "PENTAX") || strings.HasPrefix(make, "RICOH IMAGING") || strings.HasPrefix(make, "ASAHI")
+}
+
+// PentaxPreview convenience func. "IFD/Exif/Makernotes.Pentax" PreviewImageStart and PreviewImageLength
+// The JPEG preview from the Makernote, the largest preview of a PEF. The Offset is
+// relative to the Exif Tiff Header.
+//
+// Returns ErrNoPreview if the Makernote does not have a JPEG preview.
+func (e *Data) PentaxPreview() (p Preview, err error) {
+	if !e.isPentax() {
+		return p, ErrNoPreview
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.PentaxPreviewImageStart)
+	if err != nil {
+		return p, ErrNoPreview
+	}
+	p = Preview{Ifd: ifds.MknoteIFD}
+	if p.Offset, err = e.ParseUint32Value(t); err != nil {
+		return p, ErrNoPreview
+	}
+	if t, err = e.GetTag(ifds.MknoteIFD, 0, mknote.PentaxPreviewImageLength); err == nil {
+		p.Length, _ = e.ParseUint32Value(t)
+	}
+	if p.Length == 0 {
+		return p, ErrNoPreview
+	}
+	// PreviewImageSize is the width and height of the preview
+	if t, err = e.GetTag(ifds.MknoteIFD, 0, mknote.PentaxPreviewImageSize); err == nil {
+		if size, err := e.ParseUint16Values(t); err == nil && len(size) == 2 {
+			p.Width, p.Height = uint32(size[0]), uint32(size[1])
+		}
+	}
+	return p, nil
+}
@@ -3,10 +3,12 @@ package exif
 import (
 	"encoding/binary"
 	"io"
+	"math"
 
 	"github.com/evanoberholster/imagemeta/exif/ifds"
 	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
 	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 	"github.com/pkg/errors"
 )
@@ -27,13 +29,22 @@ type reader struct {
 	byteOrder binary.ByteOrder
 
 	// Offsets for multiple Ifds
-	ifdExifOffset [8]uint32
+	ifdExifOffset [8]uint64
 
 	// rawBuffer for parsing Tags
 	rawBuffer [rawBufferSize]byte
 
-	exifOffset uint32
+	exifOffset uint64
 	exifLength uint32
+
+	// Ifds to scan
+	ifdMask IfdMask
+
+	// BigTiff format
+	bigTiff bool
+
+	// Strict mode returns errors for malformed Exif data
+	strict bool
 }
 
 // newReader returns a new Reader. It reads from reader according to byteOrder from exifOffset
@@ -42,7 +53,9 @@ func newReader(r io.ReaderAt, header meta.ExifHeader) *reader {
 		u:          r,
 		byteOrder:  header.ByteOrder,
 		exifLength: header.ExifLength,
-		exifOffset: header.TiffHeaderOffset,
+		exifOffset: uint64(header.TiffHeaderOffset),
+		ifdMask:    AllIFDs,
+		bigTiff:    header.BigTiff,
 	}
 }
 
@@ -54,23 +67,43 @@ func (r *reader) scanIFD(e *Data, ifd ifds.Ifd) (err error) {
 		}
 	}()
 
-	var nextIfdOffset uint32
+	var nextIfdOffset uint64
+	visited := []uint64{ifd.Offset}
 
 	for ifd.Index = 0; ; ifd.Index++ {
-		r.ifdExifOffset[ifd.Type] = uint32(r.exifOffset)
+		r.ifdExifOffset[ifd.Type] = r.exifOffset
 		ifd.Offset += r.exifOffset
 
 		if nextIfdOffset, err = r.parseIfd(e, ifd, true); err != nil {
 			return err
 		}
-		if nextIfdOffset == 0 {
+		if nextIfdOffset == 0 || ifd.Index == math.MaxUint8 {
 			break
 		}
+		// IFD1 follows IFD0
+		if ifd.IsType(ifds.IFD0) && r.ifdMask&IFD1 == 0 {
+			break
+		}
+		// Stop at an Ifd chain that loops back to an earlier Ifd
+		if containsOffset(visited, nextIfdOffset) {
+			break
+		}
+		visited = append(visited, nextIfdOffset)
 		ifd.Offset = nextIfdOffset
 	}
 	return
 }
 
+// containsOffset returns true if offsets contains offset.
+func containsOffset(offsets []uint64, offset uint64) bool {
+	for _, o := range offsets {
+		if o == offset {
+			return true
+		}
+	}
+	return false
+}
+
 // scanSubIFD scans through the subIfd at the specified offset and enumerates over their IfdTags
 func (r *reader) scanSubIFD(e *Data, t tag.Tag) (err error) {
 	defer func() {
@@ -79,14 +112,26 @@ func (r *reader) scanSubIFD(e *Data, t tag.Tag) (err error) {
 		}
 	}()
 
-	// Fetch SubIfd Values from []Uint32 (LongType)
-	offsets, err := e.ParseUint32Values(t)
+	// Fetch SubIfd Values from []Uint32 (LongType) or []Uint64 (Ifd8Type)
+	var offsets []uint64
+	if t.IsType(tag.TypeIfd8) {
+		offsets, err = e.parseIfd8Values(t)
+	} else {
+		var longs []uint32
+		longs, err = e.ParseUint32Values(t)
+		offsets = make([]uint64, len(longs))
+		for i := range longs {
+			offsets[i] = uint64(longs[i])
+		}
+	}
 	if err != nil {
 		return err
 	}
 
+	// SubIfd offsets are relative to the Tiff header
+	r.ifdExifOffset[ifds.SubIFD] = r.exifOffset
 	for ifdIndex := uint8(0); ifdIndex < uint8(len(offsets)); ifdIndex++ {
-		ifdOffset := offsets[ifdIndex]
+		ifdOffset := offsets[ifdIndex] + r.exifOffset
 		ifd := ifds.NewIFD(ifds.SubIFD, ifdIndex, ifdOffset)
 		if _, err = r.parseIfd(e, ifd, false); err != nil {
 			return errors.WithMessage(err, "ScanSubIfds: ParseIfd Error")
@@ -96,7 +141,7 @@ func (r *reader) scanSubIFD(e *Data, t tag.Tag) (err error) {
 }
 
 // ParseIfd - enumerates over the ifd using the enumerator.ifdReader
-func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset uint32, err error) {
+func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset uint64, err error) {
 	byteOrder := r.byteOrder
 
 	// Parse MakerNoteIfds
@@ -108,15 +153,29 @@ func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset
 	}
 
 	offset := ifd.Offset
+	if r.strict {
+		if err = r.validateIfd(ifd); err != nil {
+			return 0, err
+		}
+	}
 
 	var tagCount uint16
 	var t tag.Tag
+	bigTiff := r.isBigTiffIfd(ifd)
 
 	// Determine tagCount
-	if tagCount, offset, err = r.ReadUint16(byteOrder, offset); err != nil {
+	if bigTiff {
+		var count uint64
+		if count, offset, err = r.ReadUint64(byteOrder, offset); err != nil {
+			return 0, errors.Wrapf(err, "Tag Count: %d for %s", count, ifd.String())
+		}
+		if count > maxBigTiffTagCount {
+			return 0, errors.Errorf("Tagcount too high. Tag Count: %d for %s", count, ifd.String())
+		}
+		tagCount = uint16(count)
+	} else if tagCount, offset, err = r.ReadUint16(byteOrder, offset); err != nil {
 		return 0, errors.Wrapf(err, "Tag Count: %d for %s", tagCount, ifd.String())
-	}
-	if tagCount > 255 {
+	} else if tagCount > maxTagCount {
 		return 0, errors.Errorf("Tagcount too high. Tag Count: %d for %s", tagCount, ifd.String())
 	}
 
@@ -126,7 +185,16 @@ func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset
 	}
 
 	for i := 0; i < int(tagCount); i++ {
-		if t, offset, err = r.ReadTag(ifd, byteOrder, offset); err != nil {
+		tagOffset := offset
+		if bigTiff {
+			t, offset, err = r.ReadBigTiffTag(ifd, byteOrder, offset)
+		} else {
+			t, offset, err = r.ReadTag(ifd, byteOrder, offset)
+		}
+		if err != nil {
+			if err == tag.ErrTagTypeNotValid && r.strict && r.isUnknownTagType(byteOrder, tagOffset) {
+				return nextIfdOffset, &ValidationError{Ifd: ifd, TagID: t.ID, Offset: tagOffset, Err: ErrTagTypeNotSupported}
+			}
 			if err == tag.ErrTagTypeNotValid {
 				//if errors.Is(err, tag.ErrTagTypeNotValid) {
 				// Log TagNotValid Error
@@ -141,10 +209,22 @@ func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset
 			logTagInfo(ifd, t, offset)
 		}
 
+		if r.strict && !t.IsIfd() {
+			if err = r.validateTag(ifd, t); err != nil {
+				return nextIfdOffset, err
+			}
+		}
+
 		// Tag is an Ifd then descend
 		if t.IsIfd() {
 			// Descend into Child IFD
 			childIfd := ifd.ChildIfd(t)
+			if childIfd.IsType(ifds.MknoteIFD) {
+				e.makerNote = t // Kept for Encode
+			}
+			if !r.ifdMask.Has(childIfd.Type) {
+				continue
+			}
 			if childIfd.IsType(ifds.SubIFD) {
 				if err := r.scanSubIFD(e, t); err != nil {
 					return offset, err
@@ -160,8 +240,16 @@ func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset
 	}
 
 	// NextIfdOffset
-	if nextIfdOffset, _, err = r.ReadUint32(byteOrder, offset); err != nil {
-		return nextIfdOffset, err
+	if bigTiff {
+		if nextIfdOffset, _, err = r.ReadUint64(byteOrder, offset); err != nil {
+			return nextIfdOffset, err
+		}
+	} else {
+		var next uint32
+		if next, _, err = r.ReadUint32(byteOrder, offset); err != nil {
+			return nextIfdOffset, err
+		}
+		nextIfdOffset = uint64(next)
 	}
 
 	// Adjust for incorrect Makernotes NextIfd Offsets set nextIfdOffset to 0x0000.
@@ -172,8 +260,8 @@ func (r *reader) parseIfd(e *Data, ifd ifds.Ifd, doDescend bool) (nextIfdOffset
 	return
 }
 
-func (r *reader) embeddedTagValue(valueOffset uint32) []byte {
-	r.byteOrder.PutUint32(r.rawBuffer[:4], valueOffset)
+func (r *reader) embeddedTagValue(valueOffset uint64) []byte {
+	r.byteOrder.PutUint32(r.rawBuffer[:4], uint32(valueOffset))
 	return r.rawBuffer[:4]
 }
 
@@ -187,7 +275,7 @@ func (r *reader) ReadValue(t tag.Tag) (buf []byte, err error) {
 	valueOffset := t.ValueOffset          // Tag Value Offset
 	valueOffset += r.ifdExifOffset[t.Ifd] // Exif Offset for the given Tag's Ifd
 
-	return r.ReadBufferAt(byteLength, int(valueOffset))
+	return r.ReadBufferAt(byteLength, int64(valueOffset))
 }
 
 // Read Lengths
@@ -195,12 +283,15 @@ const (
 	tagByteLength    = 12
 	uint16ByteLength = 2
 	uint32ByteLength = 4
+
+	// maxTagCount is the largest number of entries read from an Ifd.
+	maxTagCount = 255
 )
 
 // ReadTag reads the tagID uint16, tagType uint16, unitCount uint32 and valueOffset uint32
 // from an ifdTagEnumerator. Returns Tag and error. If the tagType is unsupported, returns tag.ErrTagTypeNotValid.
-func (r *reader) ReadTag(ifd ifds.Ifd, byteOrder binary.ByteOrder, offset uint32) (tag.Tag, uint32, error) {
-	buf, err := r.ReadBufferAt(tagByteLength, int(offset))
+func (r *reader) ReadTag(ifd ifds.Ifd, byteOrder binary.ByteOrder, offset uint64) (tag.Tag, uint64, error) {
+	buf, err := r.ReadBufferAt(tagByteLength, int64(offset))
 	if err != nil {
 		return tag.Tag{}, offset, err
 	}
@@ -212,30 +303,33 @@ func (r *reader) ReadTag(ifd ifds.Ifd, byteOrder binary.ByteOrder, offset uint32
 	tagType = tagIsIfd(ifd, tagID, tagType)
 
 	t, err := tag.NewTag(tagID, tagType, unitCount, valueOffset, uint8(ifd.Type)) // NewTag
+	if err != nil {
+		t.ID = tagID
+	}
 	return t, offset + tagByteLength, err
 }
 
 // ReadUint16 reads a uint16 from an ifdTagEnumerator.
-func (r *reader) ReadUint16(byteOrder binary.ByteOrder, offset uint32) (val uint16, off uint32, err error) {
-	buf, err := r.ReadBufferAt(uint16ByteLength, int(offset))
+func (r *reader) ReadUint16(byteOrder binary.ByteOrder, offset uint64) (val uint16, off uint64, err error) {
+	buf, err := r.ReadBufferAt(uint16ByteLength, int64(offset))
 	return byteOrder.Uint16(buf), offset + uint16ByteLength, err
 }
 
 // ReadUint32 reads a uint32 from an ifdTagEnumerator.
-func (r *reader) ReadUint32(byteOrder binary.ByteOrder, offset uint32) (val uint32, off uint32, err error) {
-	buf, err := r.ReadBufferAt(uint32ByteLength, int(offset))
+func (r *reader) ReadUint32(byteOrder binary.ByteOrder, offset uint64) (val uint32, off uint64, err error) {
+	buf, err := r.ReadBufferAt(uint32ByteLength, int64(offset))
 	return byteOrder.Uint32(buf), offset + uint32ByteLength, err
 }
 
 // ReadBufferAt reads n at offset from the underlying reader.
-func (r *reader) ReadBufferAt(n int, offset int) (buf []byte, err error) {
+func (r *reader) ReadBufferAt(n int, offset int64) (buf []byte, err error) {
 	if n <= rawBufferSize {
 		buf = r.rawBuffer[:n]
 	} else {
 		buf = make([]byte, n)
 	}
 
-	nn, err := r.u.ReadAt(buf[:n], int64(offset))
+	nn, err := r.u.ReadAt(buf[:n], offset)
 	if nn < n {
 		return nil, errors.Wrapf(err, "ReadBufferAt error wanted %d bytes got %d bytes", n, nn)
 	}
@@ -243,7 +337,7 @@ func (r *reader) ReadBufferAt(n int, offset int) (buf []byte, err error) {
 }
 
 func tagIsIfd(ifd ifds.Ifd, tagID tag.ID, tagType tag.Type) tag.Type {
-	if tagType.Is(tag.TypeLong) {
+	if tagType.Is(tag.TypeLong) || tagType.Is(tag.TypeLong8) || tagType.Is(tag.TypeIfd8) {
 		// RootIfd Children
 		if ifd.IsType(ifds.IFD0) {
 			switch tagID {
@@ -255,6 +349,13 @@ func tagIsIfd(ifd ifds.Ifd, tagID tag.ID, tagType tag.Type) tag.Type {
 				return tag.TypeIfd
 			}
 		}
+		// ExifIfd Children
+		if ifd.IsType(ifds.ExifIFD) {
+			switch tagID {
+			case exififd.InteroperabilityTag:
+				return tag.TypeIfd
+			}
+		}
 	}
 	if tagType.Is(tag.TypeUndefined) {
 		// ExifIfd Children
@@ -292,6 +393,11 @@ func (e *Data) addTag(ifd ifds.Ifd, t tag.Tag) {
 			if ifd.Index == 0 {
 				e.height, _ = e.ParseUint16Value(t)
 			}
+		case ifds.DNGVersion: // update imagetype
+			// The Makernote of a Pentax or Samsung DNG is parsed before the DNGVersion tag
+			if e.imageType == imagetype.ImageTiff || e.imageType == imagetype.ImagePEF || e.imageType == imagetype.ImageSRW {
+				e.imageType = imagetype.ImageDNG
+			}
 		}
 	}
 	// Special ExifIfd Tags
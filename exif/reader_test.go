@@ -1,5 +1,16 @@
 package exif
 
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
 // TODO: Write tests for exifReader
 //func TestExifReader(t *testing.T) {
 //	exifOffset := uint32(0)
@@ -25,3 +36,41 @@ package exif
 //
 //	// TODO: test Reader
 //}
+
+func TestScanSubIFD(t *testing.T) {
+	// A Tiff header at offset 8 with a SubIFD in IFD0
+	le := binary.LittleEndian
+	buf := make([]byte, 8+64)
+	tiff := buf[8:]
+	copy(tiff, []byte{'I', 'I', 0x2a, 0, 8, 0, 0, 0})
+	putTag := func(b []byte, id tag.ID, tagType tag.Type, count, value uint32) {
+		le.PutUint16(b, uint16(id))
+		le.PutUint16(b[2:], uint16(tagType))
+		le.PutUint32(b[4:], count)
+		le.PutUint32(b[8:], value)
+	}
+	le.PutUint16(tiff[8:], 1)
+	putTag(tiff[10:], ifds.SubIFDs, tag.TypeLong, 1, 26)
+	le.PutUint16(tiff[26:], 2)
+	putTag(tiff[28:], ifds.ImageWidth, tag.TypeShort, 1, 640)
+	putTag(tiff[40:], ifds.Model, tag.TypeASCII, 8, 56)
+	copy(tiff[56:], "SubIfd!\x00")
+
+	e, err := ParseExif(bytes.NewReader(buf), meta.NewExifHeader(le, 8, 8, uint32(len(tiff)), imagetype.ImageTiff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ta, err := e.GetTag(ifds.SubIFD, 0, ifds.ImageWidth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width, _ := e.ParseUint16Value(ta); width != 640 {
+		t.Errorf("Incorrect SubIFD ImageWidth wanted %d got %d", 640, width)
+	}
+	if ta, err = e.GetTag(ifds.SubIFD, 0, ifds.Model); err != nil {
+		t.Fatal(err)
+	}
+	if model, _ := e.ParseASCIIValue(ta); model != "SubIfd!" {
+		t.Errorf("Incorrect SubIFD Model wanted %s got %s", "SubIfd!", model)
+	}
+}
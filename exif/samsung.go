@@ -0,0 +1,26 @@
+package exif
+
+import (
+	"strings"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/mknote"
+)
+
+// isSamsung returns true if the camera make is Samsung, ie. "SAMSUNG" and "samsung".
+func (e *Data) isSamsung() bool {
+	return strings.HasPrefix(strings.ToUpper(e.make), "SAMSUNG")
+}
+
+// SamsungSerialNumber convenience func. "IFD/Exif/Makernotes.Samsung" SerialNumber
+// Camera serial number from the Makernote
+func (e *Data) SamsungSerialNumber() (string, error) {
+	if !e.isSamsung() {
+		return "", ErrEmptyTag
+	}
+	t, err := e.GetTag(ifds.MknoteIFD, 0, mknote.SamsungSerialNumber)
+	if err != nil {
+		return "", err
+	}
+	return e.ParseASCIIValue(t)
+}
@@ -0,0 +1,112 @@
+package exif
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/pkg/errors"
+)
+
+// Strict mode errors
+var (
+	ErrIfdOffset           = errors.New("error ifd offset out of range")
+	ErrTagValueOverrun     = errors.New("error tag value overruns exif length")
+	ErrASCIINotTerminated  = errors.New("error ascii value not null terminated")
+	ErrTagTypeNotSupported = tag.ErrTagTypeNotValid
+)
+
+// ValidationError is returned by ParseExifStrict when the Exif data does not
+// follow the Exif specification. Err is one of ErrIfdOffset, ErrTagValueOverrun,
+// ErrASCIINotTerminated or ErrTagTypeNotSupported.
+type ValidationError struct {
+	Ifd    ifds.Ifd
+	TagID  tag.ID // zero for Ifd errors
+	Offset uint64 // offset of the Ifd, the tag entry or the tag value
+	Err    error
+}
+
+func (ve *ValidationError) Error() string {
+	if ve.TagID == 0 {
+		return fmt.Sprintf("exif: %s: %s", ve.Ifd, ve.Err)
+	}
+	return fmt.Sprintf("exif: %s tag %s at offset (0x%04x): %s", ve.Ifd, ve.TagID, ve.Offset, ve.Err)
+}
+
+// Unwrap returns the underlying error
+func (ve *ValidationError) Unwrap() error {
+	return ve.Err
+}
+
+// ParseExifStrict parses Exif metadata from an io.ReaderAt and a TiffHeader like
+// ParseExif, but returns a *ValidationError for an out of range Ifd offset, an
+// unknown tag type, a tag value that overruns the Exif length or an ASCII value
+// that is not null terminated instead of skipping them.
+//
+// If the header is invalid ParseExifStrict will return ErrInvalidHeader.
+func ParseExifStrict(r io.ReaderAt, header meta.ExifHeader) (*Data, error) {
+	return ParseExifWithOptions(r, header, Options{Strict: true})
+}
+
+// exifEnd returns the end of the Exif data. Returns math.MaxUint64 if the
+// Exif length is unknown.
+func (r *reader) exifEnd() uint64 {
+	if r.exifLength == 0 {
+		return math.MaxUint64
+	}
+	return r.exifOffset + uint64(r.exifLength)
+}
+
+// validateIfd returns a *ValidationError if the ifd offset is outside of the Exif data.
+func (r *reader) validateIfd(ifd ifds.Ifd) error {
+	if ifd.Offset < r.exifOffset || ifd.Offset+uint16ByteLength > r.exifEnd() {
+		return &ValidationError{Ifd: ifd, Offset: ifd.Offset, Err: ErrIfdOffset}
+	}
+	return nil
+}
+
+// isUnknownTagType returns true if the type of the tag entry at offset is not one of
+// the Tiff 6.0 or BigTiff types. Valid types that are not supported (ie. FLOAT and DOUBLE)
+// are skipped in strict mode.
+func (r *reader) isUnknownTagType(byteOrder binary.ByteOrder, offset uint64) bool {
+	buf, err := r.ReadBufferAt(4, int64(offset))
+	if err != nil {
+		return true
+	}
+	tagType := byteOrder.Uint16(buf[2:4])
+	return tagType == 0 || (tagType > 12 && tagType < 16) || tagType > 18
+}
+
+// validateTag returns a *ValidationError if the tag value is outside of the
+// Exif data or if an ASCII value is not null terminated.
+func (r *reader) validateTag(ifd ifds.Ifd, t tag.Tag) error {
+	if uint64(t.UnitCount)*uint64(t.Type().Size()) > math.MaxUint32 {
+		return &ValidationError{Ifd: ifd, TagID: t.ID, Offset: t.ValueOffset, Err: ErrTagValueOverrun}
+	}
+	if t.IsEmbedded() {
+		if t.IsType(tag.TypeASCII) && t.UnitCount > 0 {
+			if buf := r.embeddedTagValue(t.ValueOffset); buf[t.UnitCount-1] != 0 {
+				return &ValidationError{Ifd: ifd, TagID: t.ID, Offset: t.ValueOffset, Err: ErrASCIINotTerminated}
+			}
+		}
+		return nil
+	}
+	offset := uint64(r.ifdExifOffset[t.Ifd]) + uint64(t.ValueOffset)
+	if offset+uint64(t.Size()) > r.exifEnd() {
+		return &ValidationError{Ifd: ifd, TagID: t.ID, Offset: t.ValueOffset, Err: ErrTagValueOverrun}
+	}
+	if t.IsType(tag.TypeASCII) {
+		buf, err := r.ReadBufferAt(1, int64(offset)+int64(t.Size())-1)
+		if err != nil {
+			return &ValidationError{Ifd: ifd, TagID: t.ID, Offset: t.ValueOffset, Err: ErrTagValueOverrun}
+		}
+		if buf[0] != 0 {
+			return &ValidationError{Ifd: ifd, TagID: t.ID, Offset: t.ValueOffset, Err: ErrASCIINotTerminated}
+		}
+	}
+	return nil
+}
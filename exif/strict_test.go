@@ -0,0 +1,102 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStrictTiff returns a BigEndian Tiff with a single IFD0 of the given
+// 12 byte entries followed by data. Out of line values start at offset 8+2+12*n+4.
+func newStrictTiff(entries [][]byte, data []byte) []byte {
+	buf := []byte{'M', 'M', 0, 0x2a, 0, 0, 0, 8}
+	buf = append(buf, 0, byte(len(entries)))
+	for _, e := range entries {
+		buf = append(buf, e...)
+	}
+	buf = append(buf, 0, 0, 0, 0)
+	return append(buf, data...)
+}
+
+func strictEntry(id tag.ID, tagType tag.Type, count uint32, value []byte) []byte {
+	e := make([]byte, 12)
+	binary.BigEndian.PutUint16(e[0:], uint16(id))
+	binary.BigEndian.PutUint16(e[2:], uint16(tagType))
+	binary.BigEndian.PutUint32(e[4:], count)
+	copy(e[8:], value)
+	return e
+}
+
+func TestParseExifStrict(t *testing.T) {
+	// Out of line values of a 2 entry IFD start at 38
+	offset := func(o uint32) []byte { return []byte{byte(o >> 24), byte(o >> 16), byte(o >> 8), byte(o)} }
+	valid := strictEntry(ifds.Make, tag.TypeASCII, 4, []byte("abc\x00"))
+
+	strictTests := []struct {
+		name       string
+		entries    [][]byte
+		data       []byte
+		exifLength uint32
+		tagID      tag.ID
+		err        error
+	}{
+		{"Valid", [][]byte{valid, strictEntry(ifds.Model, tag.TypeASCII, 6, offset(38))}, []byte("Model\x00"), 44, 0, nil},
+		{"UnknownTagType", [][]byte{valid, strictEntry(ifds.Model, 0x0d, 1, nil)}, nil, 0, ifds.Model, ErrTagTypeNotSupported},
+		{"FloatTagType", [][]byte{valid, strictEntry(ifds.Model, 11, 1, nil)}, nil, 0, 0, nil},
+		{"EmbeddedASCII", [][]byte{valid, strictEntry(ifds.Model, tag.TypeASCII, 4, []byte("abcd"))}, nil, 0, ifds.Model, ErrASCIINotTerminated},
+		{"ASCII", [][]byte{valid, strictEntry(ifds.Model, tag.TypeASCII, 6, offset(38))}, []byte("Models"), 0, ifds.Model, ErrASCIINotTerminated},
+		{"ValueOverrun", [][]byte{valid, strictEntry(ifds.Model, tag.TypeASCII, 6, offset(38))}, []byte("Model\x00"), 40, ifds.Model, ErrTagValueOverrun},
+		{"CountOverrun", [][]byte{valid, strictEntry(ifds.Model, tag.TypeLong, 0x40000001, offset(38))}, nil, 0, ifds.Model, ErrTagValueOverrun},
+		{"IfdOffset", [][]byte{valid}, nil, 8, 0, ErrIfdOffset},
+	}
+	for _, st := range strictTests {
+		t.Run(st.name, func(t *testing.T) {
+			buf := newStrictTiff(st.entries, st.data)
+			header := meta.NewExifHeader(binary.BigEndian, 8, 0, st.exifLength, imagetype.ImageTiff)
+
+			// Lenient mode
+			_, err := ParseExif(bytes.NewReader(buf), header)
+			assert.NoError(t, err)
+
+			_, err = ParseExifStrict(bytes.NewReader(buf), header)
+			assert.ErrorIs(t, err, st.err)
+			if st.err == nil {
+				return
+			}
+			var ve *ValidationError
+			if assert.True(t, errors.As(err, &ve)) {
+				assert.Equal(t, ifds.IFD0, ve.Ifd.Type)
+				assert.Equal(t, st.tagID, ve.TagID)
+			}
+		})
+	}
+
+	// Test images
+	for _, wantedExif := range exifTests {
+		t.Run(wantedExif.filename, func(t *testing.T) {
+			buf, err := os.ReadFile(wantedExif.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = ParseExifStrict(bytes.NewReader(buf), wantedExif.header)
+			if wantedExif.imageType == imagetype.ImageHEIF {
+				// The Heic sample is truncated after the Exif GPS Ifd
+				var ve *ValidationError
+				if assert.True(t, errors.As(err, &ve)) {
+					assert.ErrorIs(t, err, ErrTagValueOverrun)
+					assert.Equal(t, ifds.GPSIFD, ve.Ifd.Type)
+				}
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
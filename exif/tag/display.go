@@ -0,0 +1,37 @@
+package tag
+
+import "fmt"
+
+// ValueMap is a Map of tag.ID to the names of the tag's enumerated values
+type ValueMap map[ID]map[uint32]string
+
+// displayValues are the ValueMaps of each Ifd
+var displayValues = map[uint8]ValueMap{}
+
+// RegisterValues registers the names of the enumerated values of the tags of
+// the Ifd, that are used by Tag.DisplayValue. It is not safe for concurrent
+// use and should be called from init.
+func RegisterValues(ifd uint8, values ValueMap) {
+	displayValues[ifd] = values
+}
+
+// DisplayValue returns the name of the value v of the Tag as rendered by
+// exiftool, ie. "Program AE" for the ExposureProgram value 2, and true if
+// the Tag has enumerated values. Values that are not enumerated are rendered
+// as "Unknown (v)".
+//
+// The value of ASCII tags is their first character, ie. 'N' for the
+// GPSLatitudeRef "North".
+func (t Tag) DisplayValue(v uint32) (string, bool) {
+	names, ok := displayValues[t.Ifd][t.ID]
+	if !ok {
+		return "", false
+	}
+	if name, ok := names[v]; ok {
+		return name, true
+	}
+	if t.t == TypeASCII || t.t == TypeASCIINoNul {
+		return fmt.Sprintf("Unknown (%c)", rune(v)), true
+	}
+	return fmt.Sprintf("Unknown (%d)", v), true
+}
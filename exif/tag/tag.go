@@ -37,7 +37,7 @@ type SRational struct {
 
 // Tag is an Exif Tag
 type Tag struct {
-	ValueOffset uint32 // 4 bytes
+	ValueOffset uint64 // 8 bytes
 	UnitCount   uint32 // 4 bytes
 	ID          ID     // 2 bytes
 	t           Type   // 1 byte
@@ -56,7 +56,7 @@ func NewTag(tagID ID, tagType Type, unitCount uint32, valueOffset uint32, ifd ui
 		ID:          tagID,
 		t:           tagType,
 		UnitCount:   unitCount,
-		ValueOffset: valueOffset,
+		ValueOffset: uint64(valueOffset),
 		Ifd:         ifd,
 	}, nil
 }
@@ -72,7 +72,7 @@ func (t Tag) IsEmbedded() bool {
 
 // IsIfd checks if the Tag's value is an IFD
 func (t Tag) IsIfd() bool {
-	return t.t == TypeIfd
+	return t.t == TypeIfd || t.t == TypeIfd8
 }
 
 // Size returns the size of the Tag's value
@@ -123,11 +123,14 @@ const (
 	// TypeRational describes an encoded list of rationals.
 	TypeRational Type = 5
 
+	// TypeSignedByte describes an encoded list of signed bytes.
+	TypeSignedByte Type = 6
+
 	// TypeUndefined describes an encoded value that has a complex/non-clearcut
 	// interpretation.
 	TypeUndefined Type = 7
 
-	// TypeSignedShort describes an encoded list of signed shorts. (experimental)
+	// TypeSignedShort describes an encoded list of signed shorts.
 	TypeSignedShort Type = 8
 
 	// TypeSignedLong describes an encoded list of signed longs.
@@ -136,6 +139,21 @@ const (
 	// TypeSignedRational describes an encoded list of signed rationals.
 	TypeSignedRational Type = 10
 
+	// TypeFloat describes an encoded list of single precision (4 byte) IEEE floats.
+	TypeFloat Type = 11
+
+	// TypeDouble describes an encoded list of double precision (8 byte) IEEE floats.
+	TypeDouble Type = 12
+
+	// TypeLong8 describes an encoded list of unsigned 64-bit integers. (BigTiff)
+	TypeLong8 Type = 16
+
+	// TypeSignedLong8 describes an encoded list of signed 64-bit integers. (BigTiff)
+	TypeSignedLong8 Type = 17
+
+	// TypeIfd8 describes an encoded list of 64-bit Ifd offsets. (BigTiff)
+	TypeIfd8 Type = 18
+
 	// PseudoTypes
 
 	// TypeASCIINoNul is just a pseudo-type, for our own purposes.
@@ -153,20 +171,28 @@ const (
 	TypeShortSize          = 2
 	TypeLongSize           = 4
 	TypeRationalSize       = 8
+	TypeSignedByteSize     = 1
+	TypeUndefinedSize      = 1
+	TypeSignedShortSize    = 2
 	TypeSignedLongSize     = 4
 	TypeSignedRationalSize = 8
+	TypeFloatSize          = 4
+	TypeDoubleSize         = 8
 	TypeIfdSize            = 4
+	TypeLong8Size          = 8
+	TypeSignedLong8Size    = 8
+	TypeIfd8Size           = 8
 
 	// TagType Stringer String
-	_TagTypeStringerString = "UnknownBYTEASCIISHORTLONGRATIONALUnknownUNDEFINEDSSHORTSLONGSRATIONAL"
+	_TagTypeStringerString = "UnknownBYTEASCIISHORTLONGRATIONALSBYTEUNDEFINEDSSHORTSLONGSRATIONALFLOATDOUBLE"
 )
 
 var (
 	//Tag sizes
-	_tagSize = [...]uint8{0, TypeByteSize, TypeASCIISize, TypeShortSize, TypeLongSize, TypeRationalSize, 0, 0, TypeShortSize, TypeSignedLongSize, TypeSignedRationalSize}
+	_tagSize = [...]uint8{0, TypeByteSize, TypeASCIISize, TypeShortSize, TypeLongSize, TypeRationalSize, TypeSignedByteSize, TypeUndefinedSize, TypeSignedShortSize, TypeSignedLongSize, TypeSignedRationalSize, TypeFloatSize, TypeDoubleSize, 0, 0, 0, TypeLong8Size, TypeSignedLong8Size, TypeIfd8Size}
 
 	// TagType Stringer Index
-	_TagTypeStringerIndex = [...]uint8{0, 7, 11, 16, 21, 25, 33, 40, 49, 55, 60, 69}
+	_TagTypeStringerIndex = [...]uint8{0, 7, 11, 16, 21, 25, 33, 38, 47, 53, 58, 67, 72, 78}
 )
 
 // Size returns the size of one atomic unit of the type.
@@ -188,6 +214,14 @@ func (tt Type) String() string {
 	if int(tt) < len(_TagTypeStringerIndex)-1 {
 		return _TagTypeStringerString[_TagTypeStringerIndex[tt]:_TagTypeStringerIndex[tt+1]]
 	}
+	switch tt {
+	case TypeLong8:
+		return "LONG8"
+	case TypeSignedLong8:
+		return "SLONG8"
+	case TypeIfd8:
+		return "IFD8"
+	}
 	if tt == TypeIfd {
 		return "IFD"
 	}
@@ -205,8 +239,15 @@ func (tt Type) IsValid() bool {
 		tt == TypeByte ||
 		tt == TypeASCII ||
 		tt == TypeASCIINoNul ||
+		tt == TypeSignedByte ||
+		tt == TypeSignedShort ||
 		tt == TypeSignedLong ||
 		tt == TypeSignedRational ||
+		tt == TypeFloat ||
+		tt == TypeDouble ||
 		tt == TypeUndefined ||
-		tt == TypeIfd
+		tt == TypeIfd ||
+		tt == TypeLong8 ||
+		tt == TypeSignedLong8 ||
+		tt == TypeIfd8
 }
@@ -16,9 +16,16 @@ var tagTypeTests = []struct {
 	{3, TypeShort, TypeShortSize, "SHORT", nil},
 	{4, TypeLong, TypeLongSize, "LONG", nil},
 	{5, TypeRational, TypeRationalSize, "RATIONAL", nil},
+	{6, TypeSignedByte, TypeSignedByteSize, "SBYTE", nil},
 	{7, TypeUndefined, 0, "UNDEFINED", nil},
+	{8, TypeSignedShort, TypeSignedShortSize, "SSHORT", nil},
 	{9, TypeSignedLong, TypeSignedLongSize, "SLONG", nil},
 	{10, TypeSignedRational, TypeSignedRationalSize, "SRATIONAL", nil},
+	{11, TypeFloat, TypeFloatSize, "FLOAT", nil},
+	{12, TypeDouble, TypeDoubleSize, "DOUBLE", nil},
+	{16, TypeLong8, TypeLong8Size, "LONG8", nil},
+	{17, TypeSignedLong8, TypeSignedLong8Size, "SLONG8", nil},
+	{18, TypeIfd8, TypeIfd8Size, "IFD8", nil},
 	{0xf0, TypeASCIINoNul, TypeASCIINoNulSize, "_ASCII_NO_NUL", nil},
 	{0, TypeUnknown, 0, "Unknown", ErrTagTypeNotValid},
 	{100, 100, 0, "Unknown", ErrTagTypeNotValid},
@@ -0,0 +1,22 @@
+//go:build go1.23
+
+package exif
+
+import (
+	"iter"
+
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// Tags returns an iterator over the tags in exif.Data.
+// It does not start a goroutine and the loop can be stopped with break.
+// The order of the tags is not specified.
+func (e *Data) Tags() iter.Seq[tag.Tag] {
+	return func(yield func(tag.Tag) bool) {
+		for _, t := range e.tagMap {
+			if !yield(t) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build go1.23
+
+package exif
+
+import (
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTagsData(n int) *Data {
+	e := newData(newMockReader(nil), imagetype.ImageUnknown)
+	for i := 0; i < n; i++ {
+		t, _ := tag.NewTag(tag.ID(i), tag.TypeShort, 1, uint32(i)<<16, uint8(ifds.IFD0))
+		e.tagMap[ifds.NewKey(ifds.IFD0, 0, t.ID)] = t
+	}
+	return e
+}
+
+func TestTags(t *testing.T) {
+	e := newTagsData(10)
+
+	ids := make(map[tag.ID]bool)
+	for ta := range e.Tags() {
+		ids[ta.ID] = true
+	}
+	assert.Len(t, ids, 10)
+
+	count := 0
+	for range e.Tags() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, count)
+}
+
+func BenchmarkTags(b *testing.B) {
+	e := newTagsData(200)
+
+	b.Run("RangeTags", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range e.RangeTags() {
+			}
+		}
+	})
+	b.Run("Tags", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for range e.Tags() {
+			}
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package exif
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// Thumbnail errors
+var (
+	ErrNoThumbnail      = errors.New("error exif does not have a jpeg thumbnail")
+	ErrThumbnailNotJPEG = errors.New("error thumbnail is not a jpeg image")
+
+	// ErrThumbnailOutOfBounds is returned when the thumbnail is not within
+	// the Exif data or the reader.
+	ErrThumbnailOutOfBounds = errors.New("error thumbnail exceeds the exif data")
+)
+
+// thumbnailIndex is the IFD0 index of IFD1, the thumbnail IFD.
+const thumbnailIndex = 1
+
+// compressionJPEG is the Compression of a JPEG thumbnail.
+const compressionJPEG = 6
+
+// jpegSOI is the Start of Image marker of a JPEG.
+var jpegSOI = []byte{0xFF, 0xD8}
+
+// Thumbnail returns the JPEG thumbnail of IFD1 referenced by the
+// JPEGInterchangeFormat and JPEGInterchangeFormatLength tags.
+//
+// Returns ErrNoThumbnail if IFD1 does not have a JPEG thumbnail and
+// ErrThumbnailOutOfBounds if the thumbnail is not within the Exif data.
+func (e *Data) Thumbnail() ([]byte, error) {
+	offsetTag, err := e.GetTag(ifds.IFD0, thumbnailIndex, ifds.JPEGInterchangeFormat)
+	if err != nil {
+		return nil, ErrNoThumbnail
+	}
+	lengthTag, err := e.GetTag(ifds.IFD0, thumbnailIndex, ifds.JPEGInterchangeFormatLength)
+	if err != nil {
+		return nil, ErrNoThumbnail
+	}
+	offset, err := e.ParseUint32Value(offsetTag)
+	if err != nil {
+		return nil, err
+	}
+	length, err := e.ParseUint32Value(lengthTag)
+	if err != nil {
+		return nil, err
+	}
+	if length == 0 {
+		return nil, ErrNoThumbnail
+	}
+	// The length is not trusted, the thumbnail is bounded by the Exif length
+	// when it is known and is read without allocating length bytes upfront.
+	if e.reader.exifLength > 0 && uint64(offset)+uint64(length) > uint64(e.reader.exifLength) {
+		return nil, ErrThumbnailOutOfBounds
+	}
+	sr := io.NewSectionReader(e.reader.u, int64(e.reader.ifdExifOffset[ifds.IFD0]+uint64(offset)), int64(length))
+	buf, err := io.ReadAll(sr)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < int(length) {
+		return nil, ErrThumbnailOutOfBounds
+	}
+	return buf, nil
+}
+
+// SetThumbnail sets the JPEG thumbnail of IFD1. The JPEGInterchangeFormat and
+// JPEGInterchangeFormatLength tags are set by Encode, the thumbnail is written
+// after the IFDs. The IFD1 Compression is set to JPEG, and XResolution,
+// YResolution and ResolutionUnit are set to 72 dpi if they are not set.
+//
+// Returns ErrThumbnailNotJPEG if buf does not start with a JPEG SOI marker.
+func (b *Builder) SetThumbnail(buf []byte) error {
+	if !bytes.HasPrefix(buf, jpegSOI) {
+		return ErrThumbnailNotJPEG
+	}
+	if err := b.SetShort(ifds.IFD0, thumbnailIndex, ifds.Compression, compressionJPEG); err != nil {
+		return err
+	}
+	for _, id := range []tag.ID{ifds.XResolution, ifds.YResolution} {
+		if !b.HasTag(ifds.IFD0, thumbnailIndex, id) {
+			if err := b.SetRational(ifds.IFD0, thumbnailIndex, id, tag.Rational{Numerator: 72, Denominator: 1}); err != nil {
+				return err
+			}
+		}
+	}
+	if !b.HasTag(ifds.IFD0, thumbnailIndex, ifds.ResolutionUnit) {
+		if err := b.SetShort(ifds.IFD0, thumbnailIndex, ifds.ResolutionUnit, uint16(ResolutionUnitInch)); err != nil {
+			return err
+		}
+	}
+	b.thumbnail = append([]byte(nil), buf...)
+	return nil
+}
+
+// Thumbnail returns the JPEG thumbnail set with SetThumbnail or
+// kept by NewBuilderFromData, or nil.
+func (b *Builder) Thumbnail() []byte {
+	return b.thumbnail
+}
+
+// RemoveThumbnail removes the JPEG thumbnail and IFD1.
+func (b *Builder) RemoveThumbnail() {
+	b.thumbnail = nil
+	b.RemoveIfd(ifds.IFD0, thumbnailIndex)
+}
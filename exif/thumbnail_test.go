@@ -0,0 +1,139 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnail(t *testing.T) {
+	buf, err := os.ReadFile(exifTests[0].filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseExif(bytes.NewReader(buf), exifTests[0].header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumbnail, err := e.Thumbnail()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.HasPrefix(thumbnail, jpegSOI), "thumbnail should start with a JPEG SOI marker")
+	assert.True(t, bytes.HasSuffix(thumbnail, []byte{0xFF, 0xD9}), "thumbnail should end with a JPEG EOI marker")
+
+	// The thumbnail is kept by NewBuilderFromData
+	b, err := NewBuilderFromData(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, thumbnail, b.Thumbnail())
+	e2 := encodeAndParse(t, b)
+	if tn, err := e2.Thumbnail(); assert.NoError(t, err) {
+		assert.Equal(t, thumbnail, tn)
+	}
+
+	// Replaced thumbnail
+	newThumbnail := []byte{0xFF, 0xD8, 0xFF, 0xDB, 1, 2, 3, 0xFF, 0xD9}
+	if err = b.SetThumbnail(newThumbnail); err != nil {
+		t.Fatal(err)
+	}
+	e2 = encodeAndParse(t, b)
+	if tn, err := e2.Thumbnail(); assert.NoError(t, err) {
+		assert.Equal(t, newThumbnail, tn)
+	}
+	assert.Equal(t, e.CameraModel(), e2.CameraModel())
+	if ta, err := e2.GetTag(ifds.IFD0, thumbnailIndex, ifds.Compression); assert.NoError(t, err) {
+		c, _ := e2.ParseUint16Value(ta)
+		assert.Equal(t, uint16(compressionJPEG), c)
+	}
+
+	b.RemoveThumbnail()
+	assert.Nil(t, b.Thumbnail())
+	e2 = encodeAndParse(t, b)
+	_, err = e2.Thumbnail()
+	assert.ErrorIs(t, err, ErrNoThumbnail)
+	_, err = e2.GetTag(ifds.IFD0, thumbnailIndex, ifds.Compression)
+	assert.ErrorIs(t, err, ErrEmptyTag)
+}
+
+func TestBuilderThumbnail(t *testing.T) {
+	thumbnail := []byte{0xFF, 0xD8, 0xFF, 0xD9}
+	b := NewBuilder(nil)
+	if err := b.SetThumbnail(thumbnail); err != nil {
+		t.Fatal(err)
+	}
+	e := encodeAndParse(t, b)
+	if tn, err := e.Thumbnail(); assert.NoError(t, err) {
+		assert.Equal(t, thumbnail, tn)
+	}
+	// Default resolution of IFD1
+	if ta, err := e.GetTag(ifds.IFD0, thumbnailIndex, ifds.ResolutionUnit); assert.NoError(t, err) {
+		u, _ := e.ParseUint16Value(ta)
+		assert.Equal(t, uint16(ResolutionUnitInch), u)
+	}
+
+	assert.ErrorIs(t, b.SetThumbnail([]byte("GIF89a")), ErrThumbnailNotJPEG)
+	assert.ErrorIs(t, b.SetThumbnail(nil), ErrThumbnailNotJPEG)
+	e = encodeAndParse(t, NewBuilder(nil))
+	_, err := e.Thumbnail()
+	assert.ErrorIs(t, err, ErrNoThumbnail)
+}
+
+// encodeAndParse returns the Exif encoded by b.
+func encodeAndParse(t *testing.T, b *Builder) *Data {
+	t.Helper()
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := ParseTIFF(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestThumbnailOutOfBounds(t *testing.T) {
+	thumbnail := []byte{0xFF, 0xD8, 0xFF, 0xD9}
+	b := NewBuilder(binary.LittleEndian)
+	if err := b.SetThumbnail(thumbnail); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JPEGInterchangeFormatLength entry of IFD1
+	entry := []byte{0x02, 0x02, 0x04, 0x00, 0x01, 0x00, 0x00, 0x00, byte(len(thumbnail)), 0x00, 0x00, 0x00}
+	i := bytes.Index(buf, entry)
+	if i < 0 {
+		t.Fatal("JPEGInterchangeFormatLength entry not found")
+	}
+	binary.LittleEndian.PutUint32(buf[i+8:], 0xFFFFFFF0)
+
+	// Exif length unknown
+	e, err := ParseTIFF(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Thumbnail()
+	assert.ErrorIs(t, err, ErrThumbnailOutOfBounds)
+	if b, err := NewBuilderFromData(e); assert.NoError(t, err) {
+		assert.Nil(t, b.Thumbnail())
+	}
+
+	// Exif length known
+	header := meta.NewExifHeader(binary.LittleEndian, binary.LittleEndian.Uint32(buf[4:8]), 0, uint32(len(buf)), imagetype.ImageTiff)
+	if e, err = ParseExif(bytes.NewReader(buf), header); err != nil {
+		t.Fatal(err)
+	}
+	_, err = e.Thumbnail()
+	assert.ErrorIs(t, err, ErrThumbnailOutOfBounds)
+}
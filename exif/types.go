@@ -3,8 +3,10 @@ package exif
 import (
 	"time"
 
+	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 	"github.com/evanoberholster/imagemeta/meta/canon"
+	"github.com/evanoberholster/imagemeta/meta/nikon"
 	"github.com/golang/geo/s2"
 )
 
@@ -25,12 +27,22 @@ type Exif interface {
 	// CameraModel convenience func. "IFD" Model
 	CameraModel() (model string)
 
+	// NormalizedCamera returns the camera make and model normalized for grouping.
+	NormalizedCamera() (make, model string)
+
 	// Copyright convenience func. "IFD" Copyright
 	Copyright() (copyright string, err error)
 
+	// ImageType returns the imagetype of the Exif
+	ImageType() imagetype.ImageType
+
 	// Dimensions convenience func. "IFD" Dimensions
 	Dimensions() (dimensions meta.Dimensions)
 
+	// DisplayDimensions convenience func. "IFD/Exif" PixelXDimension and PixelYDimension
+	// with width and height swapped for orientations 5-8
+	DisplayDimensions() (w, h uint16, err error)
+
 	// ExposureBias convenience func. "IFD/Exif" ExposureBiasValue
 	ExposureBias() (meta.ExposureBias, error)
 
@@ -63,6 +75,9 @@ type Exif interface {
 	// LensSerial convenience func. "IFD/Exif" LensSerialNumber
 	LensSerial() (serial string, err error)
 
+	// Lens convenience func. "IFD/Exif" LensSpecification
+	Lens() (LensInfo, error)
+
 	// MeteringMode convenience func. "IFD/Exif" MeteringMode
 	MeteringMode() (meta.MeteringMode, error)
 
@@ -98,9 +113,48 @@ type Exif interface {
 	// Altitude is expressed as one RATIONAL value. The reference unit is meters.
 	GPSAltitude() (alt float32, err error)
 
+	// GPSDOP convenience func. "IFD/GPS" GPSDOP
+	GPSDOP() (dop float64, err error)
+
+	// GPSSatellites convenience func. "IFD/GPS" GPSSatellites
+	GPSSatellites() (satellites string, err error)
+
+	// GPSProcessingMethod convenience func. "IFD/GPS" GPSProcessingMethod
+	GPSProcessingMethod() (method string, err error)
+
+	// GPSImgDirection convenience func. "IFD/GPS" GPSImgDirection and GPSImgDirectionRef
+	GPSImgDirection() (direction float64, ref string, err error)
+
+	// Resolution convenience func. "IFD" XResolution, YResolution and ResolutionUnit.
+	Resolution() (xDPI, yDPI float64, unit ResolutionUnit, err error)
+
+	// DPI convenience func. Resolution normalized to dots per inch.
+	DPI() (xDPI, yDPI float64, err error)
+
 	// ExposureValue convenience func. "IFD/Exif" ShutterSpeedValue
 	ExposureValue() (ev float32, err error)
 
+	// DNGVersion convenience func. "IFD" DNGVersion
+	DNGVersion() (DNGVersion, error)
+
+	// UniqueCameraModel convenience func. "IFD" UniqueCameraModel
+	UniqueCameraModel() (model string, err error)
+
+	// ColorMatrix1 convenience func. "IFD" ColorMatrix1
+	ColorMatrix1() ([]float64, error)
+
+	// ColorMatrix2 convenience func. "IFD" ColorMatrix2
+	ColorMatrix2() ([]float64, error)
+
+	// OriginalRawFileName convenience func. "IFD" OriginalRawFileName
+	OriginalRawFileName() (name string, err error)
+
+	// Previews returns the JPEG preview images of the IFD0 chain and SubIFDs.
+	Previews() []Preview
+
+	// PreviewImage returns the JPEG image of a Preview.
+	PreviewImage(p Preview) ([]byte, error)
+
 	// CanonCameraSettings convenience func. "IFD/Exif/Makernotes.Canon" CanonCameraSettings
 	// Canon Camera Settings from the Makernote
 	CanonCameraSettings() (canon.CameraSettings, error)
@@ -113,7 +167,72 @@ type Exif interface {
 	// Canon Camera Shot Info from the Makernote
 	CanonShotInfo() (canon.ShotInfo, error)
 
+	// CanonSensorInfo convenience func. "IFD/Exif/Makernotes.Canon" CanonSensorInfo
+	// Canon Sensor dimensions and borders from the Makernote
+	CanonSensorInfo() (canon.SensorInfo, error)
+
 	// CanonAFInfo convenience func. "IFD/Exif/Makernotes.Canon" CanonAFInfo
 	// Canon Camera AutoFocus Information from the Makernote
 	CanonAFInfo() (afInfo canon.AFInfo, err error)
+
+	// NikonISO convenience func. "IFD/Exif/Makernotes.Nikon" ISO
+	// ISO speed from the Makernote
+	NikonISO() (uint32, error)
+
+	// NikonShutterCount convenience func. "IFD/Exif/Makernotes.Nikon" ShutterCount
+	// Camera shutter count from the Makernote
+	NikonShutterCount() (uint32, error)
+
+	// NikonSerialNumber convenience func. "IFD/Exif/Makernotes.Nikon" SerialNumber
+	// Camera serial number from the Makernote
+	NikonSerialNumber() (string, error)
+
+	// NikonLensID convenience func. "IFD/Exif/Makernotes.Nikon" LensData and LensType
+	// Composite Nikon Lens ID from the Makernote
+	NikonLensID() (id nikon.LensID, err error)
+
+	// PentaxPreview convenience func. "IFD/Exif/Makernotes.Pentax" PreviewImageStart and PreviewImageLength
+	// The JPEG preview from the Makernote
+	PentaxPreview() (p Preview, err error)
+
+	// SamsungSerialNumber convenience func. "IFD/Exif/Makernotes.Samsung" SerialNumber
+	// Camera serial number from the Makernote
+	SamsungSerialNumber() (string, error)
+
+	// AppleContentIdentifier convenience func. "IFD/Exif/Makernotes.Apple" ContentIdentifier
+	// Live Photo pairing identifier from the Makernote
+	AppleContentIdentifier() (string, error)
+}
+
+// ResolutionUnit is the unit of "IFD" XResolution and YResolution.
+type ResolutionUnit uint16
+
+// Resolution Units
+const (
+	ResolutionUnitNone       ResolutionUnit = 1
+	ResolutionUnitInch       ResolutionUnit = 2
+	ResolutionUnitCentimeter ResolutionUnit = 3
+
+	centimetersPerInch = 2.54
+)
+
+func (ru ResolutionUnit) String() string {
+	switch ru {
+	case ResolutionUnitNone:
+		return "None"
+	case ResolutionUnitInch:
+		return "inches"
+	case ResolutionUnitCentimeter:
+		return "cm"
+	}
+	return "Unknown"
+}
+
+// LensInfo is the lens specification from "IFD/Exif" LensSpecification.
+// A value of 0 is unknown.
+type LensInfo struct {
+	MinFocalLength         meta.FocalLength
+	MaxFocalLength         meta.FocalLength
+	MinFocalLengthAperture meta.Aperture // Maximum aperture at minimum focal length
+	MaxFocalLengthAperture meta.Aperture // Maximum aperture at maximum focal length
 }
@@ -0,0 +1,615 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+)
+
+// Builder errors
+var (
+	ErrBuilderIfd        = errors.New("error ifd is not supported by the exif builder")
+	ErrBuilderIfdPointer = errors.New("error ifd pointer tags are set by the exif builder")
+	ErrBuilderValue      = errors.New("error tag value length does not match the tag type")
+	ErrBuilderSize       = errors.New("error exif is larger than 4GB")
+)
+
+// tiffHeaderLength is the length of a classic Tiff Header:
+// byte order (2), magic number (2) and first IFD offset (4).
+const tiffHeaderLength = 8
+
+// Builder builds a Tiff structured Exif block from tags.
+//
+// Tags are set per IFD and IFD index, like Data.GetTag. IFD0 index 1 is IFD1.
+// The ExifTag, GPSTag, SubIFDs and InteroperabilityTag pointers are set by
+// Encode for the IFDs that have tags.
+type Builder struct {
+	byteOrder binary.ByteOrder
+	tags      map[ifds.Key]builderTag
+	thumbnail []byte
+	makerNote *builderMakerNote
+}
+
+// builderMakerNote is the position of the MakerNote copied by NewBuilderFromData,
+// for MakerNotes with offsets relative to the Tiff Header.
+type builderMakerNote struct {
+	offset    uint32 // offset of the MakerNote from the Tiff Header it was read from
+	ifdOffset uint32 // offset of the MakerNote IFD in the MakerNote
+}
+
+// builderTag is a tag value encoded in the Builder's byte order.
+type builderTag struct {
+	t     tag.Type
+	count uint32
+	value []byte
+}
+
+// NewBuilder returns a new Builder that encodes with byteOrder.
+// If byteOrder is nil binary.BigEndian is used.
+func NewBuilder(byteOrder binary.ByteOrder) *Builder {
+	if byteOrder == nil {
+		byteOrder = binary.BigEndian
+	}
+	return &Builder{
+		byteOrder: byteOrder,
+		tags:      make(map[ifds.Key]builderTag, 50),
+	}
+}
+
+// NewBuilderFromData returns a new Builder with the tags of e, in the byte order of e.
+//
+// The tags that point to image data (StripOffsets, TileOffsets and JPEGInterchangeFormat
+// with their byte counts) are not copied. The JPEG thumbnail of IFD1 is kept if it can be
+// read, see SetThumbnail.
+//
+// The MakerNote is copied as an opaque ExifIFD tag if it can be read. The value offsets of
+// Canon, Sony and Nikon type 1 MakerNotes are relative to the Tiff Header and are updated
+// by Encode when the MakerNote is moved. Other MakerNotes are written unchanged.
+func NewBuilderFromData(e *Data) (*Builder, error) {
+	b := NewBuilder(e.reader.byteOrder)
+	for k, t := range e.tagMap {
+		ifd, idx, id := k.Val()
+		if !isBuilderIfd(ifd) || isImageDataTag(ifd, id) {
+			continue
+		}
+		buf, err := e.reader.ReadValue(t)
+		if err != nil {
+			return nil, err
+		}
+		if err = b.SetTag(ifd, idx, id, t.Type(), buf[:t.Size()]); err != nil {
+			return nil, err
+		}
+	}
+	if thumbnail, err := e.Thumbnail(); err == nil && bytes.HasPrefix(thumbnail, jpegSOI) {
+		b.thumbnail = thumbnail
+	}
+	if t := e.makerNote; t.UnitCount > 0 {
+		if buf, err := e.reader.ReadBufferAt(int(t.UnitCount), int64(t.ValueOffset+e.reader.exifOffset)); err == nil {
+			if err = b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, buf); err != nil {
+				return nil, err
+			}
+			if ifdOffset, ok := makerNoteIfdOffset(e.make, buf); ok {
+				b.makerNote = &builderMakerNote{offset: uint32(t.ValueOffset), ifdOffset: ifdOffset}
+			}
+		}
+	}
+	return b, nil
+}
+
+// Encode returns the Tiff structured Exif block of e, for read-modify-write flows.
+// The tags of e are encoded again with NewBuilderFromData, the Exif block has the same
+// tags and values but is not byte identical. See NewBuilderFromData for the tags that
+// are not kept.
+func (e *Data) Encode() ([]byte, error) {
+	b, err := NewBuilderFromData(e)
+	if err != nil {
+		return nil, err
+	}
+	return b.Encode()
+}
+
+// ByteOrder returns the byte order of the Builder.
+func (b *Builder) ByteOrder() binary.ByteOrder {
+	return b.byteOrder
+}
+
+// SetTag sets the tag id in ifd at ifdIndex with the raw value of tagType.
+// value must be encoded in the Builder's byte order.
+func (b *Builder) SetTag(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, tagType tag.Type, value []byte) error {
+	if !isBuilderIfd(ifd) || (ifdIndex > 0 && ifd != ifds.IFD0 && ifd != ifds.SubIFD) {
+		return ErrBuilderIfd
+	}
+	if isIfdPointer(ifd, id) {
+		return ErrBuilderIfdPointer
+	}
+	// Pseudo types are written as their Tiff types
+	switch tagType {
+	case tag.TypeASCIINoNul:
+		tagType = tag.TypeASCII
+	case tag.TypeIfd:
+		tagType = tag.TypeLong
+	case tag.TypeLong8, tag.TypeSignedLong8, tag.TypeIfd8:
+		return tag.ErrTagTypeNotValid
+	}
+	if !tagType.IsValid() {
+		return tag.ErrTagTypeNotValid
+	}
+	size := int(tagType.Size())
+	if len(value)%size != 0 || uint64(len(value)/size) > math.MaxUint32 {
+		return ErrBuilderValue
+	}
+	b.tags[ifds.NewKey(ifd, ifdIndex, id)] = builderTag{
+		t:     tagType,
+		count: uint32(len(value) / size),
+		value: append([]byte(nil), value...),
+	}
+	if ifd == ifds.ExifIFD && id == exififd.MakerNote {
+		// A new MakerNote is written unchanged
+		b.makerNote = nil
+	}
+	return nil
+}
+
+// SetASCII sets an ASCII tag. A NUL terminator is added to value.
+func (b *Builder) SetASCII(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, value string) error {
+	buf := make([]byte, len(value)+1)
+	copy(buf, value)
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeASCII, buf)
+}
+
+// SetByte sets a Byte tag.
+func (b *Builder) SetByte(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, values ...uint8) error {
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeByte, values)
+}
+
+// SetUndefined sets an Undefined tag.
+func (b *Builder) SetUndefined(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, value []byte) error {
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeUndefined, value)
+}
+
+// SetShort sets a Short tag.
+func (b *Builder) SetShort(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, values ...uint16) error {
+	buf := make([]byte, len(values)*tag.TypeShortSize)
+	for i, v := range values {
+		b.byteOrder.PutUint16(buf[i*2:], v)
+	}
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeShort, buf)
+}
+
+// SetLong sets a Long tag.
+func (b *Builder) SetLong(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, values ...uint32) error {
+	buf := make([]byte, len(values)*tag.TypeLongSize)
+	for i, v := range values {
+		b.byteOrder.PutUint32(buf[i*4:], v)
+	}
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeLong, buf)
+}
+
+// SetRational sets a Rational tag.
+func (b *Builder) SetRational(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, values ...tag.Rational) error {
+	buf := make([]byte, len(values)*tag.TypeRationalSize)
+	for i, v := range values {
+		b.byteOrder.PutUint32(buf[i*8:], v.Numerator)
+		b.byteOrder.PutUint32(buf[i*8+4:], v.Denominator)
+	}
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeRational, buf)
+}
+
+// SetSRational sets a Signed Rational tag.
+func (b *Builder) SetSRational(ifd ifds.IfdType, ifdIndex uint8, id tag.ID, values ...tag.SRational) error {
+	buf := make([]byte, len(values)*tag.TypeSignedRationalSize)
+	for i, v := range values {
+		b.byteOrder.PutUint32(buf[i*8:], uint32(v.Numerator))
+		b.byteOrder.PutUint32(buf[i*8+4:], uint32(v.Denominator))
+	}
+	return b.SetTag(ifd, ifdIndex, id, tag.TypeSignedRational, buf)
+}
+
+// gpsSecondsDenominator is the denominator of the seconds of GPS coordinates
+// set by SetGPSCoords. 1/10000 of a second is about 3mm.
+const gpsSecondsDenominator = 10000
+
+// SetGPSCoords sets the GPS IFD GPSLatitude and GPSLongitude with their references
+// from lat and lng in decimal degrees, and GPSVersionID if it is not set.
+// Coordinates are written as degrees, minutes and seconds.
+//
+// Returns ErrGpsCoordsNotValid if lat is not between -90 and 90 or
+// lng is not between -180 and 180.
+func (b *Builder) SetGPSCoords(lat, lng float64) error {
+	if !(lat >= -90 && lat <= 90) || !(lng >= -180 && lng <= 180) {
+		return ErrGpsCoordsNotValid
+	}
+	if !b.HasTag(ifds.GPSIFD, 0, gpsifd.GPSVersionID) {
+		if err := b.SetByte(ifds.GPSIFD, 0, gpsifd.GPSVersionID, 2, 3, 0, 0); err != nil {
+			return err
+		}
+	}
+	latRef, lngRef := "N", "E"
+	if lat < 0 {
+		latRef = "S"
+	}
+	if lng < 0 {
+		lngRef = "W"
+	}
+	if err := b.SetASCII(ifds.GPSIFD, 0, gpsifd.GPSLatitudeRef, latRef); err != nil {
+		return err
+	}
+	if err := b.SetRational(ifds.GPSIFD, 0, gpsifd.GPSLatitude, gpsDMS(lat)...); err != nil {
+		return err
+	}
+	if err := b.SetASCII(ifds.GPSIFD, 0, gpsifd.GPSLongitudeRef, lngRef); err != nil {
+		return err
+	}
+	return b.SetRational(ifds.GPSIFD, 0, gpsifd.GPSLongitude, gpsDMS(lng)...)
+}
+
+// SetGPSAltitude sets the GPS IFD GPSAltitude and GPSAltitudeRef from alt in meters.
+// Negative values are below sea level. The altitude is written in millimeters.
+func (b *Builder) SetGPSAltitude(alt float64) error {
+	ref := uint8(0)
+	if alt < 0 {
+		ref = 1
+		alt = -alt
+	}
+	if !(alt*1000 <= math.MaxUint32) {
+		return ErrGpsCoordsNotValid
+	}
+	if err := b.SetByte(ifds.GPSIFD, 0, gpsifd.GPSAltitudeRef, ref); err != nil {
+		return err
+	}
+	return b.SetRational(ifds.GPSIFD, 0, gpsifd.GPSAltitude, tag.Rational{Numerator: uint32(math.Round(alt * 1000)), Denominator: 1000})
+}
+
+// gpsDMS returns the degrees, minutes and seconds of the absolute value of coord.
+func gpsDMS(coord float64) []tag.Rational {
+	// coord in units of the seconds denominator
+	v := uint64(math.Round(math.Abs(coord) * 3600 * gpsSecondsDenominator))
+	deg := v / (3600 * gpsSecondsDenominator)
+	v -= deg * 3600 * gpsSecondsDenominator
+	min := v / (60 * gpsSecondsDenominator)
+	v -= min * 60 * gpsSecondsDenominator
+	return []tag.Rational{
+		{Numerator: uint32(deg), Denominator: 1},
+		{Numerator: uint32(min), Denominator: 1},
+		{Numerator: uint32(v), Denominator: gpsSecondsDenominator},
+	}
+}
+
+// HasTag returns true if the tag id is set in ifd at ifdIndex.
+func (b *Builder) HasTag(ifd ifds.IfdType, ifdIndex uint8, id tag.ID) bool {
+	_, ok := b.tags[ifds.NewKey(ifd, ifdIndex, id)]
+	return ok
+}
+
+// HasIfd returns true if ifd at ifdIndex has tags.
+func (b *Builder) HasIfd(ifd ifds.IfdType, ifdIndex uint8) bool {
+	for k := range b.tags {
+		if t, idx, _ := k.Val(); t == ifd && idx == ifdIndex {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveTag removes the tag id from ifd at ifdIndex.
+func (b *Builder) RemoveTag(ifd ifds.IfdType, ifdIndex uint8, id tag.ID) {
+	delete(b.tags, ifds.NewKey(ifd, ifdIndex, id))
+}
+
+// RemoveIfd removes all tags of ifd at ifdIndex.
+func (b *Builder) RemoveIfd(ifd ifds.IfdType, ifdIndex uint8) {
+	for k := range b.tags {
+		if t, idx, _ := k.Val(); t == ifd && idx == ifdIndex {
+			delete(b.tags, k)
+		}
+	}
+}
+
+// builderEntry is an IFD entry. children are the IFDs of an IFD pointer entry.
+type builderEntry struct {
+	id tag.ID
+	builderTag
+	children []*builderIfd
+}
+
+// builderIfd is an IFD with its entries and its offset from the Tiff Header.
+type builderIfd struct {
+	ifd     ifds.IfdType
+	index   uint8
+	entries []builderEntry
+	offset  uint32
+	next    *builderIfd
+}
+
+// size returns the length of the IFD and its out of line values.
+func (bi *builderIfd) size() (n uint64) {
+	n = 2 + uint64(len(bi.entries))*tagByteLength + 4
+	for _, e := range bi.entries {
+		if len(e.value) > 4 {
+			n += uint64(len(e.value) + len(e.value)%2)
+		}
+	}
+	return n
+}
+
+// Encode returns the Tiff structured Exif block starting with the Tiff Header.
+// IFDs are written in the order IFD0, ExifIFD, IopIFD, GPSIFD, SubIFDs and IFD1,
+// followed by the JPEG thumbnail.
+func (b *Builder) Encode() ([]byte, error) {
+	list := b.ifds()
+
+	// Offsets
+	offset := uint64(tiffHeaderLength)
+	for _, bi := range list {
+		bi.offset = uint32(offset)
+		offset += bi.size()
+		if offset > math.MaxUint32 {
+			return nil, ErrBuilderSize
+		}
+	}
+	thumbnailOffset := offset
+	if b.thumbnail != nil {
+		offset += uint64(len(b.thumbnail))
+		if offset > math.MaxUint32 {
+			return nil, ErrBuilderSize
+		}
+		for _, bi := range list {
+			if bi.ifd == ifds.IFD0 && bi.index == thumbnailIndex {
+				bi.setLong(b.byteOrder, ifds.JPEGInterchangeFormat, uint32(thumbnailOffset))
+			}
+		}
+	}
+
+	buf := make([]byte, offset)
+	if b.byteOrder == binary.LittleEndian {
+		copy(buf, "II")
+	} else {
+		copy(buf, "MM")
+	}
+	b.byteOrder.PutUint16(buf[2:], 0x002a)
+	b.byteOrder.PutUint32(buf[4:], tiffHeaderLength)
+
+	for _, bi := range list {
+		b.writeIfd(buf, bi)
+	}
+	b.fixMakerNote(buf, list)
+	copy(buf[thumbnailOffset:], b.thumbnail)
+	return buf, nil
+}
+
+// fixMakerNote adds the distance the MakerNote was moved to the value offsets
+// of the MakerNote IFD in buf.
+func (b *Builder) fixMakerNote(buf []byte, list []*builderIfd) {
+	if b.makerNote == nil {
+		return
+	}
+	for _, bi := range list {
+		if bi.ifd != ifds.ExifIFD {
+			continue
+		}
+		pos := bi.offset + 2
+		for _, e := range bi.entries {
+			if e.id == exififd.MakerNote && len(e.value) > 4 {
+				offset := b.byteOrder.Uint32(buf[pos+8:])
+				fixIfdOffsets(b.byteOrder, buf[offset:offset+uint32(len(e.value))], b.makerNote.ifdOffset, offset-b.makerNote.offset)
+			}
+			pos += tagByteLength
+		}
+	}
+}
+
+// fixIfdOffsets adds delta to the value offsets of the IFD at ifdOffset in buf.
+// Entries that do not fit in buf are not changed.
+func fixIfdOffsets(byteOrder binary.ByteOrder, buf []byte, ifdOffset uint32, delta uint32) {
+	if uint64(ifdOffset)+2 > uint64(len(buf)) {
+		return
+	}
+	count := int(byteOrder.Uint16(buf[ifdOffset:]))
+	pos := int(ifdOffset) + 2
+	for i := 0; i < count && pos+tagByteLength <= len(buf); i++ {
+		t := tag.Type(byteOrder.Uint16(buf[pos+2:]))
+		if t.IsValid() && uint64(t.Size())*uint64(byteOrder.Uint32(buf[pos+4:])) > 4 {
+			byteOrder.PutUint32(buf[pos+8:], byteOrder.Uint32(buf[pos+8:])+delta)
+		}
+		pos += tagByteLength
+	}
+}
+
+// setLong sets the value of the Long entry id.
+func (bi *builderIfd) setLong(byteOrder binary.ByteOrder, id tag.ID, value uint32) {
+	for _, e := range bi.entries {
+		if e.id == id {
+			byteOrder.PutUint32(e.value, value)
+		}
+	}
+}
+
+// writeIfd writes bi and its out of line values to buf.
+func (b *Builder) writeIfd(buf []byte, bi *builderIfd) {
+	pos := bi.offset
+	dataPos := pos + 2 + uint32(len(bi.entries))*tagByteLength + 4
+
+	b.byteOrder.PutUint16(buf[pos:], uint16(len(bi.entries)))
+	pos += 2
+	for _, e := range bi.entries {
+		for i, child := range e.children {
+			b.byteOrder.PutUint32(e.value[i*4:], child.offset)
+		}
+		b.byteOrder.PutUint16(buf[pos:], uint16(e.id))
+		b.byteOrder.PutUint16(buf[pos+2:], uint16(e.t))
+		b.byteOrder.PutUint32(buf[pos+4:], e.count)
+		if len(e.value) <= 4 {
+			copy(buf[pos+8:pos+12], e.value)
+		} else {
+			b.byteOrder.PutUint32(buf[pos+8:], dataPos)
+			copy(buf[dataPos:], e.value)
+			dataPos += uint32(len(e.value) + len(e.value)%2)
+		}
+		pos += tagByteLength
+	}
+	if bi.next != nil {
+		b.byteOrder.PutUint32(buf[pos:], bi.next.offset)
+	}
+}
+
+// ifds returns the IFDs of the Builder in the order they are written,
+// with their entries sorted by tag ID and the IFD pointer entries added.
+func (b *Builder) ifds() []*builderIfd {
+	m := make(map[ifds.Key]*builderIfd)
+	for k, t := range b.tags {
+		ifd, idx, id := k.Val()
+		if b.thumbnail != nil && ifd == ifds.IFD0 && idx == thumbnailIndex &&
+			(id == ifds.JPEGInterchangeFormat || id == ifds.JPEGInterchangeFormatLength) {
+			continue
+		}
+		key := ifds.NewKey(ifd, idx, 0)
+		bi, ok := m[key]
+		if !ok {
+			bi = &builderIfd{ifd: ifd, index: idx}
+			m[key] = bi
+		}
+		bi.entries = append(bi.entries, builderEntry{id: id, builderTag: t})
+	}
+	get := func(ifd ifds.IfdType, idx uint8) *builderIfd {
+		bi, ok := m[ifds.NewKey(ifd, idx, 0)]
+		if !ok && ifd == ifds.IFD0 && idx == 0 {
+			bi = &builderIfd{ifd: ifds.IFD0}
+		}
+		return bi
+	}
+	// indexes returns the IFDs of ifd from index min to the last index.
+	// Empty IFDs are added for missing indexes so that the IFD indexes are kept.
+	indexes := func(ifd ifds.IfdType, min uint8) (list []*builderIfd) {
+		last := -1
+		for _, bi := range m {
+			if bi.ifd == ifd && bi.index >= min && int(bi.index) > last {
+				last = int(bi.index)
+			}
+		}
+		for idx := int(min); idx <= last; idx++ {
+			bi, ok := m[ifds.NewKey(ifd, uint8(idx), 0)]
+			if !ok {
+				bi = &builderIfd{ifd: ifd, index: uint8(idx)}
+			}
+			list = append(list, bi)
+		}
+		return list
+	}
+
+	ifd0 := get(ifds.IFD0, 0)
+	exifIfd := get(ifds.ExifIFD, 0)
+	iopIfd := get(ifds.IopIFD, 0)
+	gpsIfd := get(ifds.GPSIFD, 0)
+	subIfds := indexes(ifds.SubIFD, 0)
+	if b.thumbnail != nil {
+		// The thumbnail offset is set by Encode
+		key := ifds.NewKey(ifds.IFD0, thumbnailIndex, 0)
+		bi, ok := m[key]
+		if !ok {
+			bi = &builderIfd{ifd: ifds.IFD0, index: thumbnailIndex}
+			m[key] = bi
+		}
+		bi.entries = append(bi.entries,
+			newLongEntry(b.byteOrder, ifds.JPEGInterchangeFormat, 0),
+			newLongEntry(b.byteOrder, ifds.JPEGInterchangeFormatLength, uint32(len(b.thumbnail))))
+	}
+	nextIfds := indexes(ifds.IFD0, 1)
+
+	if iopIfd != nil {
+		if exifIfd == nil {
+			exifIfd = &builderIfd{ifd: ifds.ExifIFD}
+		}
+		exifIfd.entries = append(exifIfd.entries, newPointerEntry(b.byteOrder, exififd.InteroperabilityTag, iopIfd))
+	}
+	if exifIfd != nil {
+		ifd0.entries = append(ifd0.entries, newPointerEntry(b.byteOrder, ifds.ExifTag, exifIfd))
+	}
+	if gpsIfd != nil {
+		ifd0.entries = append(ifd0.entries, newPointerEntry(b.byteOrder, ifds.GPSTag, gpsIfd))
+	}
+	if len(subIfds) > 0 {
+		ifd0.entries = append(ifd0.entries, newPointerEntry(b.byteOrder, ifds.SubIFDs, subIfds...))
+	}
+
+	list := []*builderIfd{ifd0}
+	for _, bi := range []*builderIfd{exifIfd, iopIfd, gpsIfd} {
+		if bi != nil {
+			list = append(list, bi)
+		}
+	}
+	list = append(list, subIfds...)
+	prev := ifd0
+	for _, bi := range nextIfds {
+		prev.next = bi
+		prev = bi
+		list = append(list, bi)
+	}
+
+	for _, bi := range list {
+		sort.Slice(bi.entries, func(i, j int) bool { return bi.entries[i].id < bi.entries[j].id })
+	}
+	return list
+}
+
+// newPointerEntry returns a Long IFD pointer entry to children.
+func newPointerEntry(byteOrder binary.ByteOrder, id tag.ID, children ...*builderIfd) builderEntry {
+	return builderEntry{
+		id: id,
+		builderTag: builderTag{
+			t:     tag.TypeLong,
+			count: uint32(len(children)),
+			value: make([]byte, len(children)*tag.TypeLongSize),
+		},
+		children: children,
+	}
+}
+
+// newLongEntry returns a Long entry with value.
+func newLongEntry(byteOrder binary.ByteOrder, id tag.ID, value uint32) builderEntry {
+	e := builderEntry{
+		id:         id,
+		builderTag: builderTag{t: tag.TypeLong, count: 1, value: make([]byte, tag.TypeLongSize)},
+	}
+	byteOrder.PutUint32(e.value, value)
+	return e
+}
+
+// isBuilderIfd returns true if ifd can be written by the Builder.
+func isBuilderIfd(ifd ifds.IfdType) bool {
+	switch ifd {
+	case ifds.IFD0, ifds.SubIFD, ifds.ExifIFD, ifds.GPSIFD, ifds.IopIFD:
+		return true
+	}
+	return false
+}
+
+// isIfdPointer returns true if id is an IFD pointer tag in ifd.
+func isIfdPointer(ifd ifds.IfdType, id tag.ID) bool {
+	switch ifd {
+	case ifds.IFD0:
+		return id == ifds.ExifTag || id == ifds.GPSTag || id == ifds.SubIFDs
+	case ifds.ExifIFD:
+		return id == exififd.InteroperabilityTag
+	}
+	return false
+}
+
+// isImageDataTag returns true if id points to image data in ifd.
+func isImageDataTag(ifd ifds.IfdType, id tag.ID) bool {
+	if ifd != ifds.IFD0 && ifd != ifds.SubIFD {
+		return false
+	}
+	switch id {
+	case ifds.StripOffsets, ifds.StripByteCounts, ifds.TileOffsets, ifds.TileByteCounts,
+		ifds.JPEGInterchangeFormat, ifds.JPEGInterchangeFormatLength:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package exif
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// exifTimeLayout is the layout of Exif date and time values.
+const exifTimeLayout = "2006:01:02 15:04:05"
+
+// exifVersion is the ExifVersion set by the Builder convenience funcs, Exif 2.32.
+var exifVersion = []byte("0232")
+
+// SetCamera sets the "IFD" Make and Model.
+func (b *Builder) SetCamera(cameraMake, model string) error {
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Make, cameraMake); err != nil {
+		return err
+	}
+	return b.SetASCII(ifds.IFD0, 0, ifds.Model, model)
+}
+
+// SetArtist sets the "IFD" Artist.
+func (b *Builder) SetArtist(artist string) error {
+	return b.SetASCII(ifds.IFD0, 0, ifds.Artist, artist)
+}
+
+// SetCopyright sets the "IFD" Copyright.
+func (b *Builder) SetCopyright(copyright string) error {
+	return b.SetASCII(ifds.IFD0, 0, ifds.Copyright, copyright)
+}
+
+// SetSoftware sets the "IFD" Software.
+func (b *Builder) SetSoftware(software string) error {
+	return b.SetASCII(ifds.IFD0, 0, ifds.Software, software)
+}
+
+// SetOrientation sets the "IFD" Orientation.
+func (b *Builder) SetOrientation(orientation meta.Orientation) error {
+	return b.SetShort(ifds.IFD0, 0, ifds.Orientation, uint16(orientation))
+}
+
+// SetDateTime sets the "IFD/Exif" DateTimeOriginal and DateTimeDigitized with
+// their SubSecTimeOriginal and SubSecTimeDigitized from tm, in the time zone of tm.
+// SubSec values are only set when tm has fractional seconds.
+func (b *Builder) SetDateTime(tm time.Time) error {
+	if err := b.setExifVersion(); err != nil {
+		return err
+	}
+	if err := b.setTimeStamp(ifds.ExifIFD, exififd.DateTimeOriginal, exififd.SubSecTimeOriginal, tm); err != nil {
+		return err
+	}
+	return b.setTimeStamp(ifds.ExifIFD, exififd.DateTimeDigitized, exififd.SubSecTimeDigitized, tm)
+}
+
+// SetModifyDate sets the "IFD" DateTime with the "IFD/Exif" SubSecTime from tm,
+// in the time zone of tm. SubSecTime is only set when tm has fractional seconds.
+func (b *Builder) SetModifyDate(tm time.Time) error {
+	return b.setTimeStamp(ifds.IFD0, ifds.DateTime, exififd.SubSecTime, tm)
+}
+
+// setTimeStamp sets the date tag id in ifd and the SubSec tag subSec in "IFD/Exif".
+// An existing subSec tag is removed when tm does not have fractional seconds.
+func (b *Builder) setTimeStamp(ifd ifds.IfdType, id tag.ID, subSec tag.ID, tm time.Time) error {
+	if err := b.SetASCII(ifd, 0, id, tm.Format(exifTimeLayout)); err != nil {
+		return err
+	}
+	if tm.Nanosecond() == 0 {
+		b.RemoveTag(ifds.ExifIFD, 0, subSec)
+		return nil
+	}
+	// Milliseconds
+	return b.SetASCII(ifds.ExifIFD, 0, subSec, fmt.Sprintf("%03d", tm.Nanosecond()/int(time.Millisecond)))
+}
+
+// SetShutterSpeed sets the "IFD/Exif" ExposureTime.
+func (b *Builder) SetShutterSpeed(ss meta.ShutterSpeed) error {
+	if err := b.setExifVersion(); err != nil {
+		return err
+	}
+	return b.SetRational(ifds.ExifIFD, 0, exififd.ExposureTime, tag.Rational{Numerator: ss[0], Denominator: ss[1]})
+}
+
+// SetAperture sets the "IFD/Exif" FNumber, with a precision of 1/100.
+func (b *Builder) SetAperture(aperture meta.Aperture) error {
+	if err := b.setExifVersion(); err != nil {
+		return err
+	}
+	return b.SetRational(ifds.ExifIFD, 0, exififd.FNumber, hundredths(float64(aperture)))
+}
+
+// SetFocalLength sets the "IFD/Exif" FocalLength in mm, with a precision of 1/100.
+func (b *Builder) SetFocalLength(fl meta.FocalLength) error {
+	if err := b.setExifVersion(); err != nil {
+		return err
+	}
+	return b.SetRational(ifds.ExifIFD, 0, exififd.FocalLength, hundredths(float64(fl)))
+}
+
+// SetISOSpeed sets the "IFD/Exif" ISOSpeedRatings. Values larger than
+// 65535 are written as 65535, as specified by Exif.
+func (b *Builder) SetISOSpeed(iso uint32) error {
+	if err := b.setExifVersion(); err != nil {
+		return err
+	}
+	if iso > math.MaxUint16 {
+		iso = math.MaxUint16
+	}
+	return b.SetShort(ifds.ExifIFD, 0, exififd.ISOSpeedRatings, uint16(iso))
+}
+
+// SetDimensions sets the "IFD/Exif" PixelXDimension and PixelYDimension.
+func (b *Builder) SetDimensions(width, height uint32) error {
+	if err := b.setExifVersion(); err != nil {
+		return err
+	}
+	if err := b.SetLong(ifds.ExifIFD, 0, exififd.PixelXDimension, width); err != nil {
+		return err
+	}
+	return b.SetLong(ifds.ExifIFD, 0, exififd.PixelYDimension, height)
+}
+
+// setExifVersion sets the "IFD/Exif" ExifVersion if it is not set.
+// ExifVersion is mandatory in the Exif IFD.
+func (b *Builder) setExifVersion() error {
+	if b.HasTag(ifds.ExifIFD, 0, exififd.ExifVersion) {
+		return nil
+	}
+	return b.SetUndefined(ifds.ExifIFD, 0, exififd.ExifVersion, exifVersion)
+}
+
+// hundredths returns v as a Rational with a denominator of 100.
+// Negative values are written as 0.
+func hundredths(v float64) tag.Rational {
+	v = math.Round(v * 100)
+	if !(v >= 0) {
+		v = 0
+	} else if v > math.MaxUint32 {
+		v = math.MaxUint32
+	}
+	return tag.Rational{Numerator: uint32(v), Denominator: 100}
+}
@@ -0,0 +1,88 @@
+package exif
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilderAPI(t *testing.T) {
+	tz := time.FixedZone("UTC+2", 2*60*60)
+	created := time.Date(2021, 6, 5, 14, 3, 2, 250*int(time.Millisecond), tz)
+	modified := time.Date(2022, 1, 2, 3, 4, 5, 0, tz)
+
+	b := NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("Canon", "Canon EOS 6D"))
+	assert.NoError(t, b.SetArtist("Artist"))
+	assert.NoError(t, b.SetCopyright("Copyright"))
+	assert.NoError(t, b.SetSoftware("imagemeta"))
+	assert.NoError(t, b.SetOrientation(meta.OrientationRotate90))
+	assert.NoError(t, b.SetDateTime(created))
+	assert.NoError(t, b.SetModifyDate(modified))
+	assert.NoError(t, b.SetShutterSpeed(meta.NewShutterSpeed(1, 250)))
+	assert.NoError(t, b.SetAperture(5.6))
+	assert.NoError(t, b.SetFocalLength(24.5))
+	assert.NoError(t, b.SetISOSpeed(800))
+	assert.NoError(t, b.SetDimensions(5472, 3648))
+	assert.NoError(t, b.SetGPSCoords(45.5, -73.25))
+
+	e := encodeAndParse(t, b)
+	assert.Equal(t, "Canon", e.CameraMake())
+	assert.Equal(t, "Canon EOS 6D", e.CameraModel())
+	artist, err := e.Artist()
+	assert.NoError(t, err)
+	assert.Equal(t, "Artist", artist)
+	copyright, err := e.Copyright()
+	assert.NoError(t, err)
+	assert.Equal(t, "Copyright", copyright)
+	assert.Equal(t, meta.OrientationRotate90, e.Orientation())
+
+	dt, err := e.DateTime(tz)
+	assert.NoError(t, err)
+	assert.True(t, created.Equal(dt), "wanted %s got %s", created, dt)
+	md, err := e.ModifyDate(tz)
+	assert.NoError(t, err)
+	assert.True(t, modified.Equal(md), "wanted %s got %s", modified, md)
+	_, err = e.GetTag(ifds.ExifIFD, 0, exififd.SubSecTime)
+	assert.ErrorIs(t, err, ErrEmptyTag)
+
+	ss, err := e.ShutterSpeed()
+	assert.NoError(t, err)
+	assert.Equal(t, meta.NewShutterSpeed(1, 250), ss)
+	aperture, err := e.Aperture()
+	assert.NoError(t, err)
+	assert.Equal(t, meta.Aperture(5.6), aperture)
+	fl, err := e.FocalLength()
+	assert.NoError(t, err)
+	assert.Equal(t, meta.FocalLength(24.5), fl)
+	iso, err := e.ISOSpeed()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(800), iso)
+	assert.Equal(t, meta.NewDimensions(5472, 3648), e.Dimensions())
+	lat, lng, err := e.GPSCoords()
+	assert.NoError(t, err)
+	assert.InDelta(t, 45.5, lat, 1e-7)
+	assert.InDelta(t, -73.25, lng, 1e-7)
+
+	if ta, err := e.GetTag(ifds.ExifIFD, 0, exififd.ExifVersion); assert.NoError(t, err) {
+		v, err := e.RawTagBytes(ta)
+		assert.NoError(t, err)
+		assert.Equal(t, exifVersion, v)
+	}
+
+	// ISO speeds larger than a Short
+	assert.NoError(t, b.SetISOSpeed(102400))
+	iso, err = encodeAndParse(t, b).ISOSpeed()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(65535), iso)
+}
+
+func TestHundredths(t *testing.T) {
+	assert.Equal(t, tag.Rational{Numerator: 280, Denominator: 100}, hundredths(2.8))
+	assert.Equal(t, tag.Rational{Numerator: 0, Denominator: 100}, hundredths(-1))
+}
@@ -0,0 +1,254 @@
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/iopifd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	for _, byteOrder := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		t.Run(byteOrder.String(), func(t *testing.T) {
+			b := NewBuilder(byteOrder)
+			assert.NoError(t, b.SetASCII(ifds.IFD0, 0, ifds.Make, "Canon"))
+			assert.NoError(t, b.SetASCII(ifds.IFD0, 0, ifds.Model, "Canon EOS 6D"))
+			assert.NoError(t, b.SetShort(ifds.IFD0, 0, ifds.Orientation, uint16(meta.OrientationRotate90)))
+			assert.NoError(t, b.SetShort(ifds.IFD0, 1, ifds.Orientation, 1))
+			assert.NoError(t, b.SetShort(ifds.ExifIFD, 0, exififd.ISOSpeedRatings, 800))
+			assert.NoError(t, b.SetRational(ifds.ExifIFD, 0, exififd.ExposureTime, tag.Rational{Numerator: 1, Denominator: 250}))
+			assert.NoError(t, b.SetASCII(ifds.IopIFD, 0, iopifd.InteroperabilityIndex, "R98"))
+			assert.NoError(t, b.SetASCII(ifds.GPSIFD, 0, gpsifd.GPSLatitudeRef, "S"))
+			assert.NoError(t, b.SetRational(ifds.GPSIFD, 0, gpsifd.GPSLatitude, tag.Rational{Numerator: 33, Denominator: 1}, tag.Rational{Numerator: 51, Denominator: 1}, tag.Rational{Numerator: 36, Denominator: 1}))
+			assert.NoError(t, b.SetASCII(ifds.GPSIFD, 0, gpsifd.GPSLongitudeRef, "E"))
+			assert.NoError(t, b.SetRational(ifds.GPSIFD, 0, gpsifd.GPSLongitude, tag.Rational{Numerator: 151, Denominator: 1}, tag.Rational{Numerator: 12, Denominator: 1}, tag.Rational{Numerator: 36, Denominator: 1}))
+			assert.NoError(t, b.SetLong(ifds.SubIFD, 0, ifds.ImageWidth, 6000))
+			assert.NoError(t, b.SetLong(ifds.SubIFD, 1, ifds.ImageWidth, 160))
+
+			buf, err := b.Encode()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, byteOrder, meta.BinaryOrder(buf))
+
+			e, err := ParseTIFF(bytes.NewReader(buf))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "Canon", e.CameraMake())
+			assert.Equal(t, "Canon EOS 6D", e.CameraModel())
+			assert.Equal(t, meta.OrientationRotate90, e.Orientation())
+			iso, err := e.ISOSpeed()
+			assert.NoError(t, err)
+			assert.Equal(t, uint32(800), iso)
+			ss, err := e.ShutterSpeed()
+			assert.NoError(t, err)
+			assert.Equal(t, meta.NewShutterSpeed(1, 250), ss)
+			index, err := e.InteroperabilityIndex()
+			assert.NoError(t, err)
+			assert.Equal(t, "R98", index)
+			lat, lng, err := e.GPSCoords()
+			assert.NoError(t, err)
+			assert.InDelta(t, -33.86, lat, 0.0001)
+			assert.InDelta(t, 151.21, lng, 0.0001)
+
+			for _, v := range []struct {
+				ifd   ifds.IfdType
+				index uint8
+				id    tag.ID
+				val   interface{}
+			}{
+				{ifds.IFD0, 1, ifds.Orientation, uint16(1)},
+				{ifds.SubIFD, 0, ifds.ImageWidth, uint32(6000)},
+				{ifds.SubIFD, 1, ifds.ImageWidth, uint32(160)},
+			} {
+				ta, err := e.GetTag(v.ifd, v.index, v.id)
+				if assert.NoError(t, err, "%s %d %s", v.ifd, v.index, v.id) {
+					assert.Equal(t, v.val, e.GetTagValue(ta))
+				}
+			}
+		})
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	b := NewBuilder(nil)
+	assert.Equal(t, binary.BigEndian, b.ByteOrder())
+	assert.ErrorIs(t, b.SetShort(ifds.MknoteIFD, 0, 0x0001, 1), ErrBuilderIfd)
+	assert.ErrorIs(t, b.SetShort(ifds.ExifIFD, 1, exififd.ISOSpeedRatings, 1), ErrBuilderIfd)
+	assert.ErrorIs(t, b.SetLong(ifds.IFD0, 0, ifds.ExifTag, 8), ErrBuilderIfdPointer)
+	assert.ErrorIs(t, b.SetLong(ifds.ExifIFD, 0, exififd.InteroperabilityTag, 8), ErrBuilderIfdPointer)
+	assert.ErrorIs(t, b.SetTag(ifds.IFD0, 0, ifds.Orientation, tag.TypeShort, []byte{0, 1, 0}), ErrBuilderValue)
+	assert.ErrorIs(t, b.SetTag(ifds.IFD0, 0, ifds.Orientation, tag.TypeUnknown, []byte{0, 1}), tag.ErrTagTypeNotValid)
+	assert.ErrorIs(t, b.SetTag(ifds.IFD0, 0, ifds.Orientation, tag.TypeLong8, make([]byte, 8)), tag.ErrTagTypeNotValid)
+
+	assert.NoError(t, b.SetShort(ifds.IFD0, 0, ifds.Orientation, 1))
+	assert.True(t, b.HasTag(ifds.IFD0, 0, ifds.Orientation))
+	b.RemoveTag(ifds.IFD0, 0, ifds.Orientation)
+	assert.False(t, b.HasTag(ifds.IFD0, 0, ifds.Orientation))
+
+	assert.NoError(t, b.SetShort(ifds.GPSIFD, 0, gpsifd.GPSAltitudeRef, 0))
+	b.RemoveIfd(ifds.GPSIFD, 0)
+	assert.False(t, b.HasTag(ifds.GPSIFD, 0, gpsifd.GPSAltitudeRef))
+
+	// An empty Builder encodes an empty IFD0
+	buf, err := b.Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{'M', 'M', 0, 42, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0}, buf)
+}
+
+func TestNewBuilderFromData(t *testing.T) {
+	for _, wantedExif := range exifTests {
+		t.Run(wantedExif.filename, func(t *testing.T) {
+			buf, err := os.ReadFile(wantedExif.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := ParseExif(bytes.NewReader(buf), wantedExif.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := NewBuilderFromData(e)
+			if wantedExif.imageType == imagetype.ImageHEIF {
+				// The Heic sample is truncated and has tag values past the end of the file.
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			if buf, err = b.Encode(); !assert.NoError(t, err) {
+				return
+			}
+			e2, err := ParseTIFF(bytes.NewReader(buf))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, wantedExif.header.ByteOrder, e2.reader.byteOrder)
+
+			for k, t1 := range e.tagMap {
+				ifd, idx, id := k.Val()
+				if !isBuilderIfd(ifd) || isImageDataTag(ifd, id) {
+					continue
+				}
+				t2, err := e2.GetTag(ifd, idx, id)
+				if assert.NoError(t, err, "%s %d %s", ifd, idx, id) {
+					assert.Equal(t, e.GetTagValue(t1), e2.GetTagValue(t2), "%s %d %s", ifd, idx, id)
+				}
+			}
+		})
+	}
+}
+
+func TestDataEncode(t *testing.T) {
+	for _, wantedExif := range exifTests {
+		if wantedExif.imageType == imagetype.ImageHEIF {
+			// The Heic sample is truncated and has tag values past the end of the file.
+			continue
+		}
+		t.Run(wantedExif.filename, func(t *testing.T) {
+			buf, err := os.ReadFile(wantedExif.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := ParseExif(bytes.NewReader(buf), wantedExif.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf, err = e.Encode(); !assert.NoError(t, err) {
+				return
+			}
+			e2, err := ParseTIFF(bytes.NewReader(buf))
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, e.makerNote.UnitCount, e2.makerNote.UnitCount)
+
+			// MakerNote tags are read from the moved MakerNote
+			for k, t1 := range e.tagMap {
+				ifd, idx, id := k.Val()
+				if isImageDataTag(ifd, id) {
+					continue
+				}
+				t2, err := e2.GetTag(ifd, idx, id)
+				if assert.NoError(t, err, "%s %d %s", ifd, idx, id) {
+					assert.Equal(t, e.GetTagValue(t1), e2.GetTagValue(t2), "%s %d %s", ifd, idx, id)
+				}
+			}
+
+			// Encoding again is stable
+			buf2, err := e2.Encode()
+			if assert.NoError(t, err) {
+				assert.Equal(t, buf, buf2)
+			}
+		})
+	}
+
+	// A MakerNote set with SetTag is written unchanged
+	b := NewBuilder(binary.LittleEndian)
+	b.makerNote = &builderMakerNote{}
+	mknote := []byte{1, 0, 1, 0, 4, 0, 2, 0, 0, 0, 100, 0, 0, 0, 0, 0, 0, 0}
+	assert.NoError(t, b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, mknote))
+	assert.Nil(t, b.makerNote)
+	buf, err := b.Encode()
+	if assert.NoError(t, err) {
+		assert.True(t, bytes.Contains(buf, mknote))
+	}
+}
+
+func TestBuilderGPS(t *testing.T) {
+	tests := []struct {
+		lat, lng, alt float64
+	}{
+		{-33.8568, 151.2153, 5.5},
+		{51.5007, -0.1246, -2.25},
+		{89.9999999, -179.9999999, 8848.86},
+		{0, 0, 0},
+	}
+	for _, test := range tests {
+		b := NewBuilder(nil)
+		assert.NoError(t, b.SetGPSCoords(test.lat, test.lng))
+		assert.NoError(t, b.SetGPSAltitude(test.alt))
+		buf, err := b.Encode()
+		if !assert.NoError(t, err) {
+			continue
+		}
+		e, err := ParseTIFF(bytes.NewReader(buf))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		lat, lng, err := e.GPSCoords()
+		assert.NoError(t, err)
+		assert.InDelta(t, test.lat, lat, 1e-7)
+		assert.InDelta(t, test.lng, lng, 1e-7)
+		alt, err := e.GPSAltitude()
+		assert.NoError(t, err)
+		assert.InDelta(t, test.alt, alt, 1e-3)
+		ta, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSVersionID)
+		if assert.NoError(t, err) {
+			v, err := e.ParseUint8Values(ta)
+			assert.NoError(t, err)
+			assert.Equal(t, []uint8{2, 3, 0, 0}, v)
+		}
+	}
+
+	// Minutes and seconds are not rounded up to 60
+	assert.Equal(t, []tag.Rational{{Numerator: 11, Denominator: 1}, {Numerator: 0, Denominator: 1}, {Numerator: 0, Denominator: gpsSecondsDenominator}}, gpsDMS(10.99999999999))
+
+	b := NewBuilder(nil)
+	assert.ErrorIs(t, b.SetGPSCoords(90.1, 0), ErrGpsCoordsNotValid)
+	assert.ErrorIs(t, b.SetGPSCoords(0, -180.1), ErrGpsCoordsNotValid)
+	assert.ErrorIs(t, b.SetGPSCoords(math.NaN(), 0), ErrGpsCoordsNotValid)
+	assert.ErrorIs(t, b.SetGPSAltitude(math.Inf(1)), ErrGpsCoordsNotValid)
+	assert.False(t, b.HasTag(ifds.GPSIFD, 0, gpsifd.GPSVersionID))
+}
@@ -0,0 +1,393 @@
+// Package exr reads the header attributes of an OpenEXR Image without decoding the image.
+//
+// The header is a list of attributes, each with a name, a type, a size and a value.
+// The standard attributes dataWindow and displayWindow have the dimensions of the image,
+// chromaticities, owner, comments and capDate are read with the other standard attributes.
+package exr
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif      = meta.ErrNoExif
+	ErrNoEXRHeader = errors.New("no OpenEXR Header")
+
+	// ErrCorruptHeader is returned when an attribute is truncated or too large.
+	ErrCorruptHeader = errors.New("corrupt OpenEXR header")
+)
+
+// exrByteOrder is the byte order of OpenEXR files
+var exrByteOrder = binary.LittleEndian
+
+// magicNumber is the first 4 bytes of an OpenEXR file
+var magicNumber = []byte{0x76, 0x2F, 0x31, 0x01}
+
+// Version flags
+const (
+	FlagTiled     uint32 = 1 << 9
+	FlagLongNames uint32 = 1 << 10
+	FlagDeep      uint32 = 1 << 11
+	FlagMultipart uint32 = 1 << 12
+)
+
+// Lengths
+const (
+	headerLength = 8
+
+	// maxNameLength is the longest attribute name and type name with the long names flag.
+	maxNameLength = 255
+
+	// maxAttributes is the largest number of attributes that is read
+	maxAttributes = 1024
+
+	// maxValueLength is the largest attribute value that is read, larger values are skipped.
+	maxValueLength = 1 << 16
+)
+
+// Box2i is an integer region of the image. Max is included in the region.
+type Box2i struct {
+	XMin, YMin, XMax, YMax int32
+}
+
+// Width returns the width of the region
+func (b Box2i) Width() uint32 {
+	if b.XMax < b.XMin {
+		return 0
+	}
+	return uint32(int64(b.XMax) - int64(b.XMin) + 1)
+}
+
+// Height returns the height of the region
+func (b Box2i) Height() uint32 {
+	if b.YMax < b.YMin {
+		return 0
+	}
+	return uint32(int64(b.YMax) - int64(b.YMin) + 1)
+}
+
+// Chromaticities are the CIE x,y coordinates of the red, green and blue
+// primaries and of the white point.
+type Chromaticities struct {
+	RedX, RedY     float32
+	GreenX, GreenY float32
+	BlueX, BlueY   float32
+	WhiteX, WhiteY float32
+}
+
+// Attribute is the name, type and location of the value of a header attribute
+type Attribute struct {
+	Name   string
+	Type   string
+	Offset uint32
+	Size   uint32
+}
+
+// Metadata from an OpenEXR file
+type Metadata struct {
+	mr meta.Reader
+
+	// Version is the file version and the version flags
+	Version uint32
+
+	// Attributes are the attributes of the header of the first part
+	Attributes []Attribute
+
+	DataWindow    Box2i
+	DisplayWindow Box2i
+
+	// Chromaticities are zero if the header does not have a chromaticities attribute
+	Chromaticities Chromaticities
+
+	// Owner is the owner of the image, Comments is the description of the image
+	Owner    string
+	Comments string
+
+	// CapDate is the time the image was captured, in the time zone of the utcOffset attribute
+	CapDate time.Time
+
+	// ExpTime is the exposure time in seconds, Aperture is the f-number of the lens
+	ExpTime  float32
+	Aperture float32
+	ISOSpeed float32
+
+	// Latitude and Longitude in degrees, Altitude in meters above sea level
+	Latitude, Longitude, Altitude float32
+
+	hasGPS      bool
+	hasAltitude bool
+}
+
+// Dimensions returns the dimensions (width and height) of the data window
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.DataWindow.Width(), m.DataWindow.Height())
+}
+
+// ImageType returns imagetype.ImageEXR for OpenEXR image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageEXR
+}
+
+// PreviewImage returns an OpenEXR preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns Exif data that is built from the owner, comments, capDate,
+// exposure and location attributes.
+//
+// Returns ErrNoExif if the header does not have these attributes.
+func (m Metadata) Exif() (exif.Exif, error) {
+	if m.Owner == "" && m.Comments == "" && m.CapDate.IsZero() && m.ExpTime == 0 && m.Aperture == 0 && m.ISOSpeed == 0 && !m.hasGPS && !m.hasAltitude {
+		return nil, ErrNoExif
+	}
+	buf, err := m.buildExif()
+	if err != nil {
+		return nil, err
+	}
+	return exif.ParseTIFF(bytes.NewReader(buf))
+}
+
+// Xmp returns xmp.ErrNoXMP, OpenEXR images do not have XMP metadata
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	return xmp.XMP{}, xmp.ErrNoXMP
+}
+
+// Attribute returns the header attribute name.
+func (m Metadata) Attribute(name string) (Attribute, bool) {
+	for _, a := range m.Attributes {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Attribute{}, false
+}
+
+// AttributeValue returns the value of the header attribute name.
+func (m Metadata) AttributeValue(name string) ([]byte, bool) {
+	a, ok := m.Attribute(name)
+	if !ok {
+		return nil, false
+	}
+	buf := make([]byte, a.Size)
+	if _, err := m.mr.ReadAt(buf, int64(a.Offset)); err != nil {
+		return nil, false
+	}
+	return buf, true
+}
+
+// buildExif returns a Tiff structured Exif block with the standard attributes.
+func (m Metadata) buildExif() ([]byte, error) {
+	b := exif.NewBuilder(binary.LittleEndian)
+	var errs []error
+	if m.Owner != "" {
+		errs = append(errs, b.SetCopyright(m.Owner))
+	}
+	if m.Comments != "" {
+		errs = append(errs, b.SetASCII(ifds.IFD0, 0, ifds.ImageDescription, m.Comments))
+	}
+	if !m.CapDate.IsZero() {
+		errs = append(errs, b.SetDateTime(m.CapDate))
+	}
+	if m.ExpTime > 0 {
+		errs = append(errs, b.SetShutterSpeed(shutterSpeed(m.ExpTime)))
+	}
+	if m.Aperture > 0 {
+		errs = append(errs, b.SetAperture(meta.Aperture(m.Aperture)))
+	}
+	if m.ISOSpeed > 0 {
+		errs = append(errs, b.SetISOSpeed(uint32(math.Round(float64(m.ISOSpeed)))))
+	}
+	if m.hasGPS {
+		errs = append(errs, b.SetGPSCoords(float64(m.Latitude), float64(m.Longitude)))
+	}
+	if m.hasAltitude {
+		errs = append(errs, b.SetGPSAltitude(float64(m.Altitude)))
+	}
+	errs = append(errs, b.SetDimensions(m.DataWindow.Width(), m.DataWindow.Height()))
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.Encode()
+}
+
+// shutterSpeed returns the exposure time t in seconds as a fraction. Exposure times
+// shorter than 1 second are 1/n seconds, longer exposure times are in 1/10 seconds.
+func shutterSpeed(t float32) meta.ShutterSpeed {
+	if t < 1 {
+		return meta.NewShutterSpeed(1, clampUint32(math.Round(1/float64(t))))
+	}
+	return meta.NewShutterSpeed(clampUint32(math.Round(float64(t)*10)), 10)
+}
+
+// clampUint32 returns v limited to the range of uint32
+func clampUint32(v float64) uint32 {
+	if v > math.MaxUint32 {
+		return math.MaxUint32
+	}
+	return uint32(v)
+}
+
+// ScanEXR reads the magic number, the version and the header attributes of the
+// first part of an OpenEXR from mr. Returns Metadata.
+//
+// Returns the error ErrNoEXRHeader if mr is not an OpenEXR and ErrCorruptHeader
+// if an attribute is truncated.
+func ScanEXR(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if n, _ := mr.ReadAt(buf[:], 0); n < headerLength || !bytes.Equal(buf[:4], magicNumber) {
+		return m, ErrNoEXRHeader
+	}
+	m.Version = exrByteOrder.Uint32(buf[4:8])
+
+	var utcOffset float32
+	var capDate string
+	var hasLatitude, hasLongitude bool
+	br := bufio.NewReader(io.NewSectionReader(mr, headerLength, math.MaxInt64-headerLength))
+	offset := uint32(headerLength)
+	for i := 0; i < maxAttributes; i++ {
+		var a Attribute
+		if a.Name, err = readName(br); err != nil {
+			return m, err
+		}
+		// The header ends with an empty attribute name
+		if a.Name == "" {
+			break
+		}
+		if a.Type, err = readName(br); err != nil {
+			return m, err
+		}
+		var size [4]byte
+		if _, err = io.ReadFull(br, size[:]); err != nil {
+			return m, ErrCorruptHeader
+		}
+		a.Size = exrByteOrder.Uint32(size[:])
+		a.Offset = offset + uint32(len(a.Name)+len(a.Type)+2+4)
+		if int32(a.Size) < 0 {
+			return m, ErrCorruptHeader
+		}
+		offset = a.Offset + a.Size
+		m.Attributes = append(m.Attributes, a)
+
+		if a.Size > maxValueLength {
+			if _, err = br.Discard(int(a.Size)); err != nil {
+				return m, ErrCorruptHeader
+			}
+			continue
+		}
+		value := make([]byte, a.Size)
+		if _, err = io.ReadFull(br, value); err != nil {
+			return m, ErrCorruptHeader
+		}
+		switch a.Name {
+		case "dataWindow":
+			m.DataWindow, _ = readBox2i(a.Type, value)
+		case "displayWindow":
+			m.DisplayWindow, _ = readBox2i(a.Type, value)
+		case "chromaticities":
+			m.Chromaticities, _ = readChromaticities(a.Type, value)
+		case "owner":
+			m.Owner, _ = readString(a.Type, value)
+		case "comments":
+			m.Comments, _ = readString(a.Type, value)
+		case "capDate":
+			capDate, _ = readString(a.Type, value)
+		case "utcOffset":
+			utcOffset, _ = readFloat(a.Type, value)
+		case "expTime":
+			m.ExpTime, _ = readFloat(a.Type, value)
+		case "aperture":
+			m.Aperture, _ = readFloat(a.Type, value)
+		case "isoSpeed":
+			m.ISOSpeed, _ = readFloat(a.Type, value)
+		case "latitude":
+			m.Latitude, hasLatitude = readFloat(a.Type, value)
+		case "longitude":
+			m.Longitude, hasLongitude = readFloat(a.Type, value)
+		case "altitude":
+			m.Altitude, m.hasAltitude = readFloat(a.Type, value)
+		}
+	}
+	m.hasGPS = hasLatitude && hasLongitude
+
+	// capDate is in local time, utcOffset is the offset of UTC from local time in seconds
+	if capDate != "" {
+		if t, err := time.ParseInLocation("2006:01:02 15:04:05", capDate, time.FixedZone("", -int(utcOffset))); err == nil {
+			m.CapDate = t
+		}
+	}
+	// The data window is the display window when it is missing
+	if _, ok := m.Attribute("dataWindow"); !ok {
+		m.DataWindow = m.DisplayWindow
+	}
+	return m, nil
+}
+
+// readName reads a null terminated attribute name or type name.
+func readName(br *bufio.Reader) (string, error) {
+	buf, err := br.ReadSlice(0)
+	if err != nil || len(buf) > maxNameLength+1 {
+		return "", ErrCorruptHeader
+	}
+	return string(buf[:len(buf)-1]), nil
+}
+
+// readBox2i reads a box2i attribute value: xMin, yMin, xMax and yMax.
+func readBox2i(typ string, buf []byte) (b Box2i, ok bool) {
+	if typ != "box2i" || len(buf) != 16 {
+		return b, false
+	}
+	return Box2i{
+		XMin: int32(exrByteOrder.Uint32(buf[0:4])),
+		YMin: int32(exrByteOrder.Uint32(buf[4:8])),
+		XMax: int32(exrByteOrder.Uint32(buf[8:12])),
+		YMax: int32(exrByteOrder.Uint32(buf[12:16])),
+	}, true
+}
+
+// readChromaticities reads a chromaticities attribute value: the x and y of red, green,
+// blue and white.
+func readChromaticities(typ string, buf []byte) (c Chromaticities, ok bool) {
+	if typ != "chromaticities" || len(buf) != 32 {
+		return c, false
+	}
+	v := make([]float32, 8)
+	for i := range v {
+		v[i] = math.Float32frombits(exrByteOrder.Uint32(buf[i*4:]))
+	}
+	return Chromaticities{v[0], v[1], v[2], v[3], v[4], v[5], v[6], v[7]}, true
+}
+
+// readString reads a string attribute value, the size of the attribute is the length of the string.
+func readString(typ string, buf []byte) (string, bool) {
+	if typ != "string" {
+		return "", false
+	}
+	return string(bytes.TrimRight(buf, "\x00")), true
+}
+
+// readFloat reads a float attribute value.
+func readFloat(typ string, buf []byte) (float32, bool) {
+	if typ != "float" || len(buf) != 4 {
+		return 0, false
+	}
+	return math.Float32frombits(exrByteOrder.Uint32(buf)), true
+}
@@ -0,0 +1,168 @@
+package exr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+func newAttribute(name, typ string, value []byte) []byte {
+	buf := append(append([]byte(name), 0), typ...)
+	buf = append(buf, 0)
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(value)))
+	return append(append(buf, size[:]...), value...)
+}
+
+func newBox2i(v ...int32) []byte {
+	buf := make([]byte, 16)
+	for i := range v {
+		binary.LittleEndian.PutUint32(buf[i*4:], uint32(v[i]))
+	}
+	return buf
+}
+
+func newFloats(v ...float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i := range v {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v[i]))
+	}
+	return buf
+}
+
+func newEXR(attributes ...[]byte) []byte {
+	buf := append([]byte{}, magicNumber...)
+	buf = append(buf, 2, 0, 0, 0)
+	for _, a := range attributes {
+		buf = append(buf, a...)
+	}
+	// End of header followed by an empty offset table
+	return append(buf, make([]byte, 17)...)
+}
+
+func TestScanEXR(t *testing.T) {
+	rec709 := Chromaticities{0.64, 0.33, 0.3, 0.6, 0.15, 0.06, 0.3127, 0.329}
+	buf := newEXR(
+		newAttribute("channels", "chlist", []byte("B\x00\x01\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x01\x00\x00\x00\x00")),
+		newAttribute("compression", "compression", []byte{3}),
+		newAttribute("dataWindow", "box2i", newBox2i(-10, 5, 1909, 1084)),
+		newAttribute("displayWindow", "box2i", newBox2i(0, 0, 1919, 1079)),
+		newAttribute("chromaticities", "chromaticities", newFloats(0.64, 0.33, 0.3, 0.6, 0.15, 0.06, 0.3127, 0.329)),
+		newAttribute("owner", "string", []byte("Studio")),
+		newAttribute("comments", "string", []byte("Shot 42")),
+		newAttribute("capDate", "string", []byte("2021:06:14 10:30:00")),
+		newAttribute("utcOffset", "float", newFloats(-7200)),
+		newAttribute("expTime", "float", newFloats(0.004)),
+		newAttribute("aperture", "float", newFloats(2.8)),
+		newAttribute("isoSpeed", "float", newFloats(400)),
+		newAttribute("latitude", "float", newFloats(48.8584)),
+		newAttribute("longitude", "float", newFloats(2.2945)),
+		newAttribute("altitude", "float", newFloats(35)),
+	)
+	if it, err := imagetype.ReadAt(bytes.NewReader(buf)); err != nil || it != imagetype.ImageEXR {
+		t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageEXR, it, err)
+	}
+	m, err := ScanEXR(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions() != meta.NewDimensions(1920, 1080) {
+		t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 1920, 1080, m.Dimensions())
+	}
+	if m.DisplayWindow != (Box2i{0, 0, 1919, 1079}) {
+		t.Errorf("Incorrect DisplayWindow got %+v", m.DisplayWindow)
+	}
+	if m.Chromaticities != rec709 {
+		t.Errorf("Incorrect Chromaticities wanted %+v got %+v", rec709, m.Chromaticities)
+	}
+	if m.Owner != "Studio" || m.Comments != "Shot 42" {
+		t.Errorf("Incorrect Owner and Comments got %q %q", m.Owner, m.Comments)
+	}
+	// capDate is 2 hours ahead of UTC
+	if want := time.Date(2021, 6, 14, 8, 30, 0, 0, time.UTC); !m.CapDate.Equal(want) {
+		t.Errorf("Incorrect CapDate wanted %s got %s", want, m.CapDate)
+	}
+	if len(m.Attributes) != 15 {
+		t.Errorf("Incorrect number of Attributes wanted %d got %d", 15, len(m.Attributes))
+	}
+	if v, ok := m.AttributeValue("compression"); !ok || !bytes.Equal(v, []byte{3}) {
+		t.Errorf("Incorrect compression Attribute got %v", v)
+	}
+
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copyright, err := e.Copyright(); err != nil || copyright != "Studio" {
+		t.Errorf("Incorrect Copyright wanted %s got %s (%v)", "Studio", copyright, err)
+	}
+	if tm, err := e.DateTime(time.FixedZone("", 7200)); err != nil || !tm.Equal(m.CapDate) {
+		t.Errorf("Incorrect DateTime wanted %s got %s (%v)", m.CapDate, tm, err)
+	}
+	if ss, err := e.ShutterSpeed(); err != nil || ss != meta.NewShutterSpeed(1, 250) {
+		t.Errorf("Incorrect ShutterSpeed wanted %s got %s (%v)", meta.NewShutterSpeed(1, 250), ss, err)
+	}
+	if a, err := e.Aperture(); err != nil || a != 2.8 {
+		t.Errorf("Incorrect Aperture wanted %v got %v (%v)", 2.8, a, err)
+	}
+	if iso, err := e.ISOSpeed(); err != nil || iso != 400 {
+		t.Errorf("Incorrect ISOSpeed wanted %d got %d (%v)", 400, iso, err)
+	}
+	if lat, lng, err := e.GPSCoords(); err != nil || math.Abs(lat-48.8584) > 1e-4 || math.Abs(lng-2.2945) > 1e-4 {
+		t.Errorf("Incorrect GPSCoords got %f %f (%v)", lat, lng, err)
+	}
+	if alt, err := e.GPSAltitude(); err != nil || alt != 35 {
+		t.Errorf("Incorrect GPSAltitude wanted %d got %f (%v)", 35, alt, err)
+	}
+	if _, err = m.Xmp(); err != xmp.ErrNoXMP {
+		t.Errorf("Incorrect error wanted %v got %v", xmp.ErrNoXMP, err)
+	}
+}
+
+func TestScanEXRDisplayWindow(t *testing.T) {
+	// Without a dataWindow the dimensions are those of the displayWindow
+	buf := newEXR(newAttribute("displayWindow", "box2i", newBox2i(0, 0, 63, 31)))
+	m, err := ScanEXR(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions() != meta.NewDimensions(64, 32) {
+		t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 64, 32, m.Dimensions())
+	}
+	if _, err = m.Exif(); err != ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+}
+
+func TestScanEXRErrors(t *testing.T) {
+	if _, err := ScanEXR(bytes.NewReader([]byte{0x76, 0x2F, 0x31, 0x02, 2, 0, 0, 0})); err != ErrNoEXRHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoEXRHeader, err)
+	}
+	// Truncated attribute value
+	buf := newEXR(newAttribute("dataWindow", "box2i", newBox2i(0, 0, 63, 31)))
+	if _, err := ScanEXR(bytes.NewReader(buf[:30])); err != ErrCorruptHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptHeader, err)
+	}
+}
+
+func TestShutterSpeed(t *testing.T) {
+	tests := []struct {
+		t    float32
+		want meta.ShutterSpeed
+	}{
+		{0.004, meta.NewShutterSpeed(1, 250)},
+		{0.5, meta.NewShutterSpeed(1, 2)},
+		{2.5, meta.NewShutterSpeed(25, 10)},
+	}
+	for _, test := range tests {
+		if ss := shutterSpeed(test.t); ss != test.want {
+			t.Errorf("Incorrect ShutterSpeed for %v wanted %s got %s", test.t, test.want, ss)
+		}
+	}
+}
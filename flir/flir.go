@@ -0,0 +1,318 @@
+// Package flir decodes the FLIR File Format (FFF) of radiometric thermal images,
+// as embedded in the APP1 FLIR segments of JPEG images. The raw thermal image
+// and the calibration parameters of the camera are decoded, so that the raw
+// values can be converted to temperatures.
+package flir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Errors
+var (
+	// ErrNoFFF is returned when the data does not start with an FFF header.
+	ErrNoFFF = errors.New("flir: no FFF header")
+
+	// ErrCorruptRecord is returned when the record directory or a record
+	// extends past the end of the data.
+	ErrCorruptRecord = errors.New("flir: corrupt record")
+)
+
+// FFF header and record directory lengths
+const (
+	headerLength      = 0x40
+	recordEntryLength = 0x20
+)
+
+// RecordType is the type of a record of the FFF record directory.
+type RecordType uint16
+
+// Record Types
+const (
+	RecordRawData          RecordType = 0x01
+	RecordEmbeddedImage    RecordType = 0x0e
+	RecordCameraInfo       RecordType = 0x20
+	RecordMeasurementInfo  RecordType = 0x21
+	RecordPaletteInfo      RecordType = 0x22
+	RecordTextInfo         RecordType = 0x23
+	RecordPictureInPicture RecordType = 0x2a
+	RecordGPSInfo          RecordType = 0x2b
+)
+
+func (rt RecordType) String() string {
+	switch rt {
+	case RecordRawData:
+		return "RawData"
+	case RecordEmbeddedImage:
+		return "EmbeddedImage"
+	case RecordCameraInfo:
+		return "CameraInfo"
+	case RecordMeasurementInfo:
+		return "MeasurementInfo"
+	case RecordPaletteInfo:
+		return "PaletteInfo"
+	case RecordTextInfo:
+		return "TextInfo"
+	case RecordPictureInPicture:
+		return "PictureInPicture"
+	case RecordGPSInfo:
+		return "GPSInfo"
+	}
+	return "Unknown"
+}
+
+// Record is a record of the FFF record directory.
+type Record struct {
+	Type RecordType
+
+	// Offset from the start of the FFF data and Length of the record in bytes
+	Offset, Length uint32
+
+	// Data of the record, a slice of the FFF data
+	Data []byte
+}
+
+// ImageFormat is the format of a raw thermal image.
+type ImageFormat uint8
+
+// Image Formats
+const (
+	ImageUnknown ImageFormat = iota
+	ImagePNG
+	ImageTIFF
+)
+
+func (f ImageFormat) String() string {
+	switch f {
+	case ImagePNG:
+		return "PNG"
+	case ImageTIFF:
+		return "TIFF"
+	}
+	return "Unknown"
+}
+
+// RawData is the raw thermal image of the RawData record.
+type RawData struct {
+	Width, Height uint16
+
+	Format ImageFormat
+
+	// Image is the raw thermal image of 16 bit values. The values of a PNG
+	// image are little endian, contrary to the PNG specification.
+	Image []byte
+}
+
+// CameraInfo are the calibration parameters of the CameraInfo record.
+// Temperatures are in Kelvin.
+type CameraInfo struct {
+	Emissivity                   float32
+	ObjectDistance               float32 // meters
+	ReflectedApparentTemperature float32
+	AtmosphericTemperature       float32
+	IRWindowTemperature          float32
+	IRWindowTransmission         float32
+	RelativeHumidity             float32 // 0 to 1
+
+	// Planck constants of the calibration of the raw values
+	PlanckR1, PlanckB, PlanckF float32
+	PlanckO                    int32
+	PlanckR2                   float32
+
+	// Atmospheric transmission constants
+	AtmosphericTransAlpha1, AtmosphericTransAlpha2 float32
+	AtmosphericTransBeta1, AtmosphericTransBeta2   float32
+	AtmosphericTransX                              float32
+
+	CameraTemperatureRangeMax, CameraTemperatureRangeMin float32
+
+	CameraModel        string
+	CameraPartNumber   string
+	CameraSerialNumber string
+	CameraSoftware     string
+	LensModel          string
+
+	RawValueMedian, RawValueRange uint16
+}
+
+// FLIR is the decoded FFF data of a radiometric thermal image.
+type FLIR struct {
+	// Creator of the FFF data and Version of the file format
+	Creator string
+	Version uint32
+
+	// Records of the record directory in directory order
+	Records []Record
+
+	// Raw and Camera are decoded from the RawData and CameraInfo records,
+	// HasRaw and HasCamera are true if the records are present.
+	Raw       RawData
+	Camera    CameraInfo
+	HasRaw    bool
+	HasCamera bool
+}
+
+// Record returns the first record of type rt and true if the record is present.
+func (f FLIR) Record(rt RecordType) (Record, bool) {
+	for _, r := range f.Records {
+		if r.Type == rt {
+			return r, true
+		}
+	}
+	return Record{}, false
+}
+
+// Decode decodes the FFF data of a radiometric thermal image. The records are
+// slices of fff.
+//
+// Returns ErrNoFFF if fff does not start with an FFF header, and
+// ErrCorruptRecord if a record extends past the end of fff.
+func Decode(fff []byte) (f FLIR, err error) {
+	if len(fff) < headerLength || (string(fff[0:4]) != "FFF\x00" && string(fff[0:4]) != "AFF\x00") {
+		return f, ErrNoFFF
+	}
+	// The version is 100 to 199, otherwise the header is little endian
+	var bo binary.ByteOrder = binary.BigEndian
+	if v := bo.Uint32(fff[0x14:]); v < 100 || v >= 200 {
+		bo = binary.LittleEndian
+	}
+	f.Creator = cString(fff[0x04:0x14])
+	f.Version = bo.Uint32(fff[0x14:])
+	dirOffset := uint64(bo.Uint32(fff[0x18:]))
+	count := uint64(bo.Uint32(fff[0x1c:]))
+	if dirOffset+count*recordEntryLength > uint64(len(fff)) {
+		return f, ErrCorruptRecord
+	}
+	for i := uint64(0); i < count; i++ {
+		entry := fff[dirOffset+i*recordEntryLength:]
+		rt := RecordType(bo.Uint16(entry[0:2]))
+		if rt == 0 {
+			// Free directory entry
+			continue
+		}
+		offset, length := bo.Uint32(entry[0x0c:]), bo.Uint32(entry[0x10:])
+		if uint64(offset)+uint64(length) > uint64(len(fff)) {
+			return f, ErrCorruptRecord
+		}
+		rec := Record{Type: rt, Offset: offset, Length: length, Data: fff[offset : offset+length]}
+		f.Records = append(f.Records, rec)
+
+		switch rt {
+		case RecordRawData:
+			if !f.HasRaw && len(rec.Data) >= 0x20 {
+				f.Raw, f.HasRaw = decodeRawData(rec.Data), true
+			}
+		case RecordCameraInfo:
+			if !f.HasCamera && len(rec.Data) >= 0x20 {
+				f.Camera, f.HasCamera = decodeCameraInfo(rec.Data), true
+			}
+		}
+	}
+	return f, nil
+}
+
+// Temperature returns the temperature in Kelvin of a raw value of the raw
+// thermal image. The radiation of the reflected apparent temperature is
+// subtracted with the emissivity of the object. The transmission of the
+// atmosphere and the IR window is not compensated.
+func (c CameraInfo) Temperature(raw uint16) float64 {
+	r1, r2 := float64(c.PlanckR1), float64(c.PlanckR2)
+	b, f, o := float64(c.PlanckB), float64(c.PlanckF), float64(c.PlanckO)
+	e := float64(c.Emissivity)
+	if e <= 0 || e > 1 {
+		e = 1
+	}
+	value := float64(raw)
+	if e < 1 && c.ReflectedApparentTemperature > 0 {
+		reflected := r1/(r2*(math.Exp(b/float64(c.ReflectedApparentTemperature))-f)) - o
+		value = (value - (1-e)*reflected) / e
+	}
+	return b / math.Log(r1/(r2*(value+o))+f)
+}
+
+// decodeRawData decodes a RawData record.
+func decodeRawData(d []byte) (raw RawData) {
+	bo := recordByteOrder(d)
+	raw.Width = bo.Uint16(d[0x02:])
+	raw.Height = bo.Uint16(d[0x04:])
+	raw.Image = d[0x20:]
+	switch {
+	case bytes.HasPrefix(raw.Image, []byte("\x89PNG\r\n\x1a\n")):
+		raw.Format = ImagePNG
+	case bytes.HasPrefix(raw.Image, []byte("II*\x00")) || bytes.HasPrefix(raw.Image, []byte("MM\x00*")):
+		raw.Format = ImageTIFF
+	}
+	return raw
+}
+
+// decodeCameraInfo decodes a CameraInfo record. Fields past the end of
+// the record are zero.
+func decodeCameraInfo(d []byte) (c CameraInfo) {
+	bo := recordByteOrder(d)
+	float := func(offset int) float32 {
+		if offset+4 > len(d) {
+			return 0
+		}
+		return math.Float32frombits(bo.Uint32(d[offset:]))
+	}
+	str := func(offset, length int) string {
+		if offset+length > len(d) {
+			return ""
+		}
+		return cString(d[offset : offset+length])
+	}
+	c.Emissivity = float(0x20)
+	c.ObjectDistance = float(0x24)
+	c.ReflectedApparentTemperature = float(0x28)
+	c.AtmosphericTemperature = float(0x2c)
+	c.IRWindowTemperature = float(0x30)
+	c.IRWindowTransmission = float(0x34)
+	if c.RelativeHumidity = float(0x3c); c.RelativeHumidity > 2 {
+		// Percent
+		c.RelativeHumidity /= 100
+	}
+	c.PlanckR1 = float(0x58)
+	c.PlanckB = float(0x5c)
+	c.PlanckF = float(0x60)
+	c.AtmosphericTransAlpha1 = float(0x70)
+	c.AtmosphericTransAlpha2 = float(0x74)
+	c.AtmosphericTransBeta1 = float(0x78)
+	c.AtmosphericTransBeta2 = float(0x7c)
+	c.AtmosphericTransX = float(0x80)
+	c.CameraTemperatureRangeMax = float(0x90)
+	c.CameraTemperatureRangeMin = float(0x94)
+	c.CameraModel = str(0xd4, 32)
+	c.CameraPartNumber = str(0xf4, 16)
+	c.CameraSerialNumber = str(0x104, 16)
+	c.CameraSoftware = str(0x114, 16)
+	c.LensModel = str(0x170, 32)
+	if len(d) >= 0x310 {
+		c.PlanckO = int32(bo.Uint32(d[0x308:]))
+		c.PlanckR2 = float(0x30c)
+	}
+	if len(d) >= 0x33e {
+		c.RawValueMedian = bo.Uint16(d[0x338:])
+		c.RawValueRange = bo.Uint16(d[0x33c:])
+	}
+	return c
+}
+
+// recordByteOrder returns the byte order of a record. The first value
+// of a record is 2.
+func recordByteOrder(d []byte) binary.ByteOrder {
+	if binary.BigEndian.Uint16(d[0:2]) >= 0x100 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
+
+// cString returns b up to the first null byte as a string.
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,153 @@
+package flir
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+var testCamera = CameraInfo{
+	Emissivity:                   0.95,
+	ObjectDistance:               1,
+	ReflectedApparentTemperature: 293.15,
+	AtmosphericTemperature:       293.15,
+	IRWindowTemperature:          293.15,
+	IRWindowTransmission:         1,
+	RelativeHumidity:             0.5,
+	PlanckR1:                     21106.77,
+	PlanckB:                      1501,
+	PlanckF:                      1,
+	PlanckO:                      -7340,
+	PlanckR2:                     0.012545258,
+	CameraTemperatureRangeMax:    423.15,
+	CameraTemperatureRangeMin:    253.15,
+	CameraModel:                  "FLIR E8",
+	CameraPartNumber:             "63909-0604",
+	CameraSerialNumber:           "12345678",
+	CameraSoftware:               "1.2.3",
+	LensModel:                    "FOL7",
+	RawValueMedian:               13000,
+	RawValueRange:                3000,
+}
+
+// testFFF returns FFF data with a header in byte order hbo, and a RawData
+// record and a CameraInfo record in byte order rbo.
+func testFFF(hbo, rbo binary.ByteOrder, image []byte) []byte {
+	raw := make([]byte, 0x20)
+	rbo.PutUint16(raw[0:], 2)
+	rbo.PutUint16(raw[2:], 4)
+	rbo.PutUint16(raw[4:], 3)
+	raw = append(raw, image...)
+
+	c := testCamera
+	cam := make([]byte, 0x340)
+	rbo.PutUint16(cam[0:], 2)
+	for offset, v := range map[int]float32{
+		0x20: c.Emissivity, 0x24: c.ObjectDistance, 0x28: c.ReflectedApparentTemperature,
+		0x2c: c.AtmosphericTemperature, 0x30: c.IRWindowTemperature, 0x34: c.IRWindowTransmission,
+		0x3c: c.RelativeHumidity * 100, 0x58: c.PlanckR1, 0x5c: c.PlanckB, 0x60: c.PlanckF,
+		0x90: c.CameraTemperatureRangeMax, 0x94: c.CameraTemperatureRangeMin, 0x30c: c.PlanckR2,
+	} {
+		rbo.PutUint32(cam[offset:], math.Float32bits(v))
+	}
+	for offset, s := range map[int]string{0xd4: c.CameraModel, 0xf4: c.CameraPartNumber, 0x104: c.CameraSerialNumber, 0x114: c.CameraSoftware, 0x170: c.LensModel} {
+		copy(cam[offset:], s)
+	}
+	rbo.PutUint32(cam[0x308:], uint32(c.PlanckO))
+	rbo.PutUint16(cam[0x338:], c.RawValueMedian)
+	rbo.PutUint16(cam[0x33c:], c.RawValueRange)
+
+	// Header and a record directory with a free entry
+	fff := make([]byte, headerLength+3*recordEntryLength)
+	copy(fff, "FFF\x00Test")
+	hbo.PutUint32(fff[0x14:], 100)
+	hbo.PutUint32(fff[0x18:], headerLength)
+	hbo.PutUint32(fff[0x1c:], 3)
+	for i, rec := range [][]byte{raw, nil, cam} {
+		entry := fff[headerLength+i*recordEntryLength:]
+		if rec == nil {
+			continue
+		}
+		hbo.PutUint16(entry[0:], uint16(RecordRawData))
+		if i == 2 {
+			hbo.PutUint16(entry[0:], uint16(RecordCameraInfo))
+		}
+		hbo.PutUint32(entry[0x0c:], uint32(len(fff)))
+		hbo.PutUint32(entry[0x10:], uint32(len(rec)))
+		fff = append(fff, rec...)
+	}
+	return fff
+}
+
+func TestDecode(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x01")
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tests := []struct {
+		name     string
+		hbo, rbo binary.ByteOrder
+		image    []byte
+		format   ImageFormat
+	}{
+		{"BigEndian", binary.BigEndian, binary.BigEndian, png, ImagePNG},
+		{"LittleEndianRecords", binary.BigEndian, binary.LittleEndian, png, ImagePNG},
+		{"LittleEndian", binary.LittleEndian, binary.LittleEndian, tiff, ImageTIFF},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Decode(testFFF(test.hbo, test.rbo, test.image))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Creator != "Test" || f.Version != 100 {
+				t.Errorf("Incorrect header wanted %s %d got %s %d", "Test", 100, f.Creator, f.Version)
+			}
+			if len(f.Records) != 2 || f.Records[0].Type != RecordRawData || f.Records[1].Type != RecordCameraInfo {
+				t.Fatalf("Incorrect records got %v", f.Records)
+			}
+			if !f.HasRaw || f.Raw.Width != 4 || f.Raw.Height != 3 || f.Raw.Format != test.format || string(f.Raw.Image) != string(test.image) {
+				t.Errorf("Incorrect raw data got %t %dx%d %s", f.HasRaw, f.Raw.Width, f.Raw.Height, f.Raw.Format)
+			}
+			if !f.HasCamera || f.Camera != testCamera {
+				t.Errorf("Incorrect camera info wanted %+v got %+v", testCamera, f.Camera)
+			}
+			if rec, ok := f.Record(RecordCameraInfo); !ok || rec.Length != 0x340 || rec.Type.String() != "CameraInfo" {
+				t.Errorf("Incorrect CameraInfo record got %v", rec.Type)
+			}
+			if _, ok := f.Record(RecordGPSInfo); ok {
+				t.Errorf("Incorrect GPSInfo record wanted %t got %t", false, ok)
+			}
+		})
+	}
+
+	fff := testFFF(binary.BigEndian, binary.BigEndian, png)
+	if _, err := Decode(fff[:headerLength]); err != ErrCorruptRecord {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptRecord, err)
+	}
+	if _, err := Decode(fff[:len(fff)-1]); err != ErrCorruptRecord {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptRecord, err)
+	}
+	if _, err := Decode(fff[1:]); err != ErrNoFFF {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoFFF, err)
+	}
+}
+
+func TestTemperature(t *testing.T) {
+	c := testCamera
+	// rawValue is the inverse of the Planck function of the camera
+	rawValue := func(temp float64) float64 {
+		return float64(c.PlanckR1)/(float64(c.PlanckR2)*(math.Exp(float64(c.PlanckB)/temp)-float64(c.PlanckF))) - float64(c.PlanckO)
+	}
+	for _, temp := range []float64{263.15, 300, 373.15} {
+		e := float64(c.Emissivity)
+		raw := e*rawValue(temp) + (1-e)*rawValue(float64(c.ReflectedApparentTemperature))
+		if got := c.Temperature(uint16(math.Round(raw))); math.Abs(got-temp) > 0.1 {
+			t.Errorf("Incorrect temperature wanted %.2f got %.2f", temp, got)
+		}
+	}
+
+	// Without the emissivity of the object
+	c.Emissivity = 0
+	if got := c.Temperature(uint16(math.Round(rawValue(300)))); math.Abs(got-300) > 0.1 {
+		t.Errorf("Incorrect temperature wanted %.2f got %.2f", 300.0, got)
+	}
+}
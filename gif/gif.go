@@ -0,0 +1,309 @@
+// Package gif reads metadata information (dimensions, XMP and loop count) from a GIF Image,
+// including animated GIF images.
+package gif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif      = meta.ErrNoExif
+	ErrNoGIFHeader = errors.New("no GIF Header")
+
+	// ErrCorruptBlock is returned when a GIF block has an unknown introducer.
+	ErrCorruptBlock = errors.New("corrupt GIF block")
+)
+
+// Metadata from a GIF file
+type Metadata struct {
+	mr        meta.Reader
+	XmpHeader meta.XmpHeader
+
+	// Decode Function for XMP metadata
+	xmpFn func(r io.Reader, header meta.XmpHeader) error
+
+	width  uint16
+	height uint16
+
+	// Animation
+	frames    uint32
+	loopCount uint16
+	looped    bool
+
+	// Reader
+	br        *bufio.Reader
+	discarded uint32
+}
+
+// Dimensions returns the dimensions (width and height) of the logical screen
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(uint32(m.width), uint32(m.height))
+}
+
+// ImageType returns imagetype.ImageGIF for GIF image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageGIF
+}
+
+// PreviewImage returns a GIF preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Animated returns true if the GIF has more than one frame.
+func (m Metadata) Animated() bool {
+	return m.frames > 1
+}
+
+// Frames returns the number of frames (image descriptors) of the GIF.
+func (m Metadata) Frames() int {
+	return int(m.frames)
+}
+
+// LoopCount returns the loop count of the NETSCAPE2.0 application extension,
+// 0 means the animation loops forever. Returns false if the GIF does not have
+// a loop count, an animation without a loop count is shown once.
+func (m Metadata) LoopCount() (int, bool) {
+	return int(m.loopCount), m.looped
+}
+
+// Exif returns ErrNoExif, GIF images do not have Exif metadata
+func (m Metadata) Exif() (exif.Exif, error) {
+	return nil, ErrNoExif
+}
+
+// Xmp returns parsed Xmp data from GIF
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+// ScanGIF scans a reader for GIF blocks. xmpFn is run with the XMP packet of the
+// XMP application extension after the extension was read. Returns Metadata.
+//
+// Returns the error ErrNoGIFHeader if the GIF header was not found, and ErrCorruptBlock
+// if a block has an unknown introducer. A GIF without a trailer is not an error.
+func ScanGIF(mr meta.Reader, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = Metadata{mr: mr, br: bufio.NewReader(mr), xmpFn: xmpFn}
+
+	var buf []byte
+	if buf, err = m.br.Peek(headerLength); err != nil || !isGIFHeader(buf) {
+		err = ErrNoGIFHeader
+		return
+	}
+	// Logical Screen Descriptor
+	m.width = gifByteOrder.Uint16(buf[6:8])
+	m.height = gifByteOrder.Uint16(buf[8:10])
+	flags := buf[10]
+	if err = m.discard(headerLength); err != nil {
+		return
+	}
+	if err = m.discardColorTable(flags); err != nil {
+		return
+	}
+
+	for err == nil {
+		var introducer byte
+		if introducer, err = m.readByte(); err != nil {
+			break
+		}
+		switch introducer {
+		case introducerExtension:
+			err = m.readExtension()
+		case introducerImage:
+			err = m.readImage()
+		case introducerTrailer:
+			return m, nil
+		default:
+			err = ErrCorruptBlock
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	return
+}
+
+// readExtension reads an extension block. Application extensions are read,
+// other extensions are discarded.
+func (m *Metadata) readExtension() (err error) {
+	var label byte
+	if label, err = m.readByte(); err != nil {
+		return
+	}
+	if label != labelApplication {
+		return m.discardSubBlocks()
+	}
+	// The first sub-block is the application identifier and authentication code
+	var buf []byte
+	if buf, err = m.br.Peek(1 + appIdentifierLength); err != nil {
+		return
+	}
+	if buf[0] != appIdentifierLength {
+		return m.discardSubBlocks()
+	}
+	identifier := buf[1:]
+	switch {
+	case bytes.Equal(identifier, appXMP):
+		if err = m.discard(1 + appIdentifierLength); err != nil {
+			return
+		}
+		return m.readXMP()
+	case bytes.Equal(identifier, appNetscape) || bytes.Equal(identifier, appAnimExts):
+		if err = m.discard(1 + appIdentifierLength); err != nil {
+			return
+		}
+		return m.readLoopCount()
+	}
+	return m.discardSubBlocks()
+}
+
+// readXMP reads the XMP packet of the XMP application extension with the attached
+// metadata xmpFn.
+//
+// The XMP packet is not divided into sub-blocks, it is followed by a 258 byte "magic trailer"
+// (0x01, 0xFF ... 0x00, 0x00) so that GIF readers skip the packet as sub-blocks.
+func (m *Metadata) readXMP() (err error) {
+	start := m.discarded
+	if err = m.discardSubBlocks(); err != nil {
+		return
+	}
+	if m.discarded-start < xmpTrailerLength {
+		return nil
+	}
+	length := m.discarded - start - xmpTrailerLength
+	var b [1]byte
+	if _, err = m.mr.ReadAt(b[:], int64(start+length)); err != nil || b[0] != 0x01 {
+		// Not followed by the magic trailer
+		return err
+	}
+	m.XmpHeader = meta.NewXMPHeader(start, length)
+
+	// Read XMP Decode Function here
+	if m.xmpFn != nil {
+		return m.xmpFn(io.NewSectionReader(m.mr, int64(start), int64(length)), m.XmpHeader)
+	}
+	return nil
+}
+
+// readLoopCount reads the loop count sub-block of the NETSCAPE2.0 application extension.
+func (m *Metadata) readLoopCount() (err error) {
+	var buf []byte
+	if buf, err = m.br.Peek(4); err != nil {
+		return
+	}
+	// Sub-block size 3, sub-block ID 1 and the 16bit loop count
+	if buf[0] == 3 && buf[1] == 1 {
+		m.loopCount = gifByteOrder.Uint16(buf[2:4])
+		m.looped = true
+	}
+	return m.discardSubBlocks()
+}
+
+// readImage reads an image descriptor and discards its image data.
+func (m *Metadata) readImage() (err error) {
+	var buf []byte
+	if buf, err = m.br.Peek(imageDescriptorLength); err != nil {
+		return
+	}
+	flags := buf[8]
+	m.frames++
+	if err = m.discard(imageDescriptorLength); err != nil {
+		return
+	}
+	if err = m.discardColorTable(flags); err != nil {
+		return
+	}
+	// LZW minimum code size
+	if err = m.discard(1); err != nil {
+		return
+	}
+	return m.discardSubBlocks()
+}
+
+// discardColorTable discards the global or local color table when
+// it is present in flags.
+func (m *Metadata) discardColorTable(flags byte) error {
+	if flags&flagColorTable == 0 {
+		return nil
+	}
+	return m.discard(3 << ((flags & 0x07) + 1))
+}
+
+// discardSubBlocks discards data sub-blocks up to and including the block terminator.
+func (m *Metadata) discardSubBlocks() (err error) {
+	var size byte
+	for {
+		if size, err = m.readByte(); err != nil || size == 0 {
+			return
+		}
+		if err = m.discard(int(size)); err != nil {
+			return
+		}
+	}
+}
+
+// readByte reads a byte and adds to m.discarded
+func (m *Metadata) readByte() (b byte, err error) {
+	if b, err = m.br.ReadByte(); err == nil {
+		m.discarded++
+	}
+	return
+}
+
+// discard adds to m.discarded and discards from the underlying bufio.Reader
+func (m *Metadata) discard(i int) (err error) {
+	if i == 0 {
+		return
+	}
+	i, err = m.br.Discard(i)
+	m.discarded += uint32(i)
+	return
+}
+
+// Block introducers and extension labels
+const (
+	introducerExtension = 0x21
+	introducerImage     = 0x2C
+	introducerTrailer   = 0x3B
+	labelApplication    = 0xFF
+)
+
+// flagColorTable is the color table flag of the Logical Screen
+// Descriptor and Image Descriptor flags.
+const flagColorTable = 0x80
+
+// Header and block lengths
+const (
+	headerLength          = 13 // Header and Logical Screen Descriptor
+	imageDescriptorLength = 9
+	appIdentifierLength   = 11
+	xmpTrailerLength      = 258
+)
+
+// Application identifiers and authentication codes
+var (
+	appXMP      = []byte("XMP DataXMP")
+	appNetscape = []byte("NETSCAPE2.0")
+	appAnimExts = []byte("ANIMEXTS1.0")
+)
+
+// gifByteOrder GIF always uses a LittleEndian byteorder.
+var gifByteOrder = binary.LittleEndian
+
+// isGIFHeader returns true if buf begins with the GIF87a or GIF89a signature.
+func isGIFHeader(buf []byte) bool {
+	return len(buf) >= 6 && string(buf[:3]) == "GIF" &&
+		(string(buf[3:6]) == "87a" || string(buf[3:6]) == "89a")
+}
@@ -0,0 +1,131 @@
+package gif
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	stdgif "image/gif"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// xmpExtension returns an XMP application extension with packet
+// followed by the magic trailer.
+func xmpExtension(packet []byte) []byte {
+	buf := append([]byte{introducerExtension, labelApplication, appIdentifierLength}, appXMP...)
+	buf = append(buf, packet...)
+	buf = append(buf, 0x01)
+	for i := 0xFF; i >= 0; i-- {
+		buf = append(buf, byte(i))
+	}
+	return append(buf, 0x00)
+}
+
+// animatedGIF returns an animated GIF with frames frames and the loopCount
+// with the extra blocks before the trailer.
+func animatedGIF(t *testing.T, frames int, loopCount int, extra ...[]byte) []byte {
+	t.Helper()
+	palette := color.Palette{color.Black, color.White}
+	g := &stdgif.GIF{LoopCount: loopCount}
+	for i := 0; i < frames; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 16, 8), palette))
+		g.Delay = append(g.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := stdgif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	b = b[:len(b)-1] // trailer
+	for _, e := range extra {
+		b = append(b, e...)
+	}
+	return append(b, introducerTrailer)
+}
+
+func TestScanGIF(t *testing.T) {
+	f, err := os.Open("../testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := ScanGIF(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions() != meta.NewDimensions(1, 1) || m.ImageType() != imagetype.ImageGIF {
+		t.Errorf("Incorrect Metadata got %s %s", m.Dimensions(), m.ImageType())
+	}
+	if _, looped := m.LoopCount(); m.Frames() != 1 || m.Animated() || looped {
+		t.Errorf("Incorrect animation got frames %d looped %t", m.Frames(), looped)
+	}
+	if _, err = m.Exif(); err != ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+
+	// Not a GIF
+	if _, err = ScanGIF(bytes.NewReader([]byte("GIF90a..........")), nil); err != ErrNoGIFHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoGIFHeader, err)
+	}
+	// Unknown block
+	if _, err = ScanGIF(bytes.NewReader([]byte("GIF89a\x01\x00\x01\x00\x00\x00\x00\x99")), nil); err != ErrCorruptBlock {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptBlock, err)
+	}
+}
+
+func TestScanGIFAnimated(t *testing.T) {
+	packet, err := xmp.Marshal(xmp.XMP{DC: xmp.DublinCore{Creator: []string{"Evan Oberholster"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := animatedGIF(t, 3, 0, xmpExtension(packet))
+
+	var xmpPacket []byte
+	m, err := ScanGIF(bytes.NewReader(buf), func(r io.Reader, header meta.XmpHeader) (err error) {
+		xmpPacket, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions() != meta.NewDimensions(16, 8) {
+		t.Errorf("Incorrect Dimensions wanted %s got %s", meta.NewDimensions(16, 8), m.Dimensions())
+	}
+	if loopCount, looped := m.LoopCount(); m.Frames() != 3 || !m.Animated() || !looped || loopCount != 0 {
+		t.Errorf("Incorrect animation got frames %d loop count %d looped %t", m.Frames(), loopCount, looped)
+	}
+	if !bytes.Equal(packet, xmpPacket) {
+		t.Errorf("Incorrect XMP packet wanted %q got %q", packet, xmpPacket)
+	}
+	if int(m.XmpHeader.Length) != len(packet) || !bytes.Equal(buf[m.XmpHeader.Offset:m.XmpHeader.Offset+m.XmpHeader.Length], packet) {
+		t.Errorf("Incorrect Xmp Header got %v", m.XmpHeader)
+	}
+	x, err := m.Xmp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.DC.Creator) != 1 || x.DC.Creator[0] != "Evan Oberholster" {
+		t.Errorf("Incorrect XMP Creator got %v", x.DC.Creator)
+	}
+
+	// Loop count 2 is written as the NETSCAPE2.0 loop count 2
+	if m, err = ScanGIF(bytes.NewReader(animatedGIF(t, 2, 2)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if loopCount, looped := m.LoopCount(); !looped || loopCount != 2 || m.XmpHeader.Length != 0 {
+		t.Errorf("Incorrect loop count wanted %d got %d looped %t", 2, loopCount, looped)
+	}
+
+	// A truncated GIF
+	if m, err = ScanGIF(bytes.NewReader(buf[:len(buf)/2]), nil); err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions() != meta.NewDimensions(16, 8) {
+		t.Errorf("Incorrect Dimensions wanted %s got %s", meta.NewDimensions(16, 8), m.Dimensions())
+	}
+}
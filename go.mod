@@ -3,6 +3,7 @@ module github.com/evanoberholster/imagemeta
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.1.0
 	github.com/golang/geo v0.0.0-20210211234256-740aa86cb551
 	github.com/lucasb-eyer/go-colorful v1.2.0
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
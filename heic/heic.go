@@ -1,13 +1,28 @@
-// Package heic decodes Heic Metadata using the bmff package
+// Package heic decodes Heic Metadata using the bmff package.
+//
+// The Exif and XMP items of the primary image are located with the item
+// references ("iref") of the meta box. The dimensions ("ispe"), rotation ("irot"),
+// mirror ("imir"), colour ("colr"), bit depth ("pixi") and HDR ("clli", "mdcv")
+// properties of the primary image are read from its item properties.
+//
+// Items enumerates every item of a file, such as the images of a burst,
+// thumbnails, auxiliary images and grid tiles, with their role, dimensions
+// and Exif header.
+//
+// AVIF files (brands "avif" and "avis") have the same box structure.
 package heic
 
 import (
-	"fmt"
+	"encoding/binary"
 	"io"
+	"math"
 
 	"github.com/evanoberholster/imagemeta/bmff"
+	"github.com/evanoberholster/imagemeta/exif"
 	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
 	"github.com/pkg/errors"
 )
 
@@ -20,6 +35,9 @@ var (
 	ErrItemNotFound = errors.New("error item not found")
 )
 
+// xmpContentType is the content type of a "mime" item with XMP metadata.
+const xmpContentType = "application/rdf+xml"
+
 // Metadata is an Heic file's Metadata
 type Metadata struct {
 	*meta.Metadata
@@ -28,6 +46,11 @@ type Metadata struct {
 	Meta     bmff.MetaBox
 	//Thumbnail []byte
 	n uint16 // Num Images
+
+	mr meta.Reader
+
+	// properties of the primary item
+	properties []bmff.Box
 }
 
 // NewMetadata returns a new heic.Metadata
@@ -46,14 +69,40 @@ func NewMetadata(r io.Reader, m *meta.Metadata) (hm Metadata, err error) {
 	}
 
 	// Find PITM and set Dimensions
-	box, err := hm.Meta.Properties.ContainerByID(hm.Meta.Primary.ItemID, bmff.TypeIspe)
-	if err != nil {
-		err = errors.Wrap(err, "Heic getMeta")
-	}
-	if ispe, ok := box.(bmff.ImageSpatialExtentsProperty); ok {
+	hm.properties = hm.Meta.Properties.PropertiesByID(hm.Meta.Primary.ItemID)
+	if box, ok := hm.property(bmff.TypeIspe); ok {
+		ispe := box.(bmff.ImageSpatialExtentsProperty)
 		hm.Dim = meta.NewDimensions(ispe.W, ispe.H)
+	} else {
+		err = errors.Wrap(bmff.ErrItemNotFound, "Heic getMeta")
 	}
+	return hm, err
+}
 
+// property returns the first property of boxType of the primary item.
+func (hm Metadata) property(boxType bmff.BoxType) (bmff.Box, bool) {
+	for _, box := range hm.properties {
+		if box.Type() == boxType {
+			return box, true
+		}
+	}
+	return nil, false
+}
+
+// Parse parses the Heic/Heif box structure from r and reads the Exif and XMP
+// headers of the primary item. t is the ImageType of r (imagetype.ImageHEIF
+// or imagetype.ImageAVIF).
+//
+// Returns meta.ErrNoExif if the Exif item was not found.
+func Parse(r meta.Reader, t imagetype.ImageType) (hm Metadata, err error) {
+	sr := io.NewSectionReader(r, 0, math.MaxInt64)
+	hm, err = NewMetadata(sr, &meta.Metadata{It: t})
+	hm.mr = r
+	if err != nil && len(hm.Meta.ItemInfo.ItemInfos) == 0 {
+		return hm, err
+	}
+	_, _ = hm.ReadXmpHeader(r)
+	_, err = hm.ReadExifHeader(r)
 	return hm, err
 }
 
@@ -62,34 +111,160 @@ func (hm Metadata) Images() uint16 {
 	return hm.n
 }
 
+// Rotation returns the "irot" rotation of the primary image
+// in steps of 90 degrees counter-clockwise.
+func (hm Metadata) Rotation() bmff.ImageRotation {
+	box, _ := hm.property(bmff.TypeIrot)
+	irot, _ := box.(bmff.ImageRotation)
+	return irot
+}
+
+// Mirror returns the "imir" mirror axis of the primary image.
+// Returns false if the primary image is not mirrored.
+func (hm Metadata) Mirror() (bmff.ImageMirror, bool) {
+	box, _ := hm.property(bmff.TypeImir)
+	imir, ok := box.(bmff.ImageMirror)
+	return imir, ok
+}
+
+// Colour returns the "nclx" colour information of the primary image.
+// Returns false if the primary image only has an ICC profile or no colour information.
+func (hm Metadata) Colour() (bmff.ColourInformationBox, bool) {
+	for _, box := range hm.properties {
+		if colr, ok := box.(bmff.ColourInformationBox); ok && colr.ColourType == bmff.ColourTypeNclx {
+			return colr, true
+		}
+	}
+	return bmff.ColourInformationBox{}, false
+}
+
+// HDR returns true if the transfer characteristics of the primary image are PQ or HLG.
+func (hm Metadata) HDR() bool {
+	colr, ok := hm.Colour()
+	return ok && colr.HDR()
+}
+
+// BitDepth returns the bits per channel of the primary image from its "pixi" property.
+// Returns 0 if the bit depth is unknown.
+func (hm Metadata) BitDepth() uint8 {
+	box, _ := hm.property(bmff.TypePixi)
+	if pixi, ok := box.(bmff.PixelInformationProperty); ok && len(pixi.BitsPerChannel) > 0 {
+		return pixi.BitsPerChannel[0]
+	}
+	return 0
+}
+
+// ContentLightLevel returns the "clli" content light level of the primary image.
+// Returns false if the primary image does not have a content light level.
+func (hm Metadata) ContentLightLevel() (bmff.ContentLightLevelBox, bool) {
+	box, _ := hm.property(bmff.TypeClli)
+	clli, ok := box.(bmff.ContentLightLevelBox)
+	return clli, ok
+}
+
+// MasteringDisplay returns the "mdcv" mastering display colour volume of the primary image.
+// Returns false if the primary image does not have a mastering display colour volume.
+func (hm Metadata) MasteringDisplay() (bmff.MasteringDisplayColourVolumeBox, bool) {
+	box, _ := hm.property(bmff.TypeMdcv)
+	mdcv, ok := box.(bmff.MasteringDisplayColourVolumeBox)
+	return mdcv, ok
+}
+
+// Alpha returns true if the primary image has an alpha plane: an auxiliary
+// image ("auxl" reference) with an alpha "auxC" property.
+func (hm Metadata) Alpha() bool {
+	for _, id := range hm.Meta.References.ReferencesTo(bmff.TypeAuxl, hm.Meta.Primary.ItemID) {
+		for _, box := range hm.Meta.Properties.PropertiesByID(id) {
+			if auxC, ok := box.(bmff.AuxiliaryTypeProperty); ok && auxC.IsAlpha() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PreviewImage returns the Heic image
+func (hm Metadata) PreviewImage() io.Reader {
+	_, _ = hm.mr.Seek(0, 0)
+	return hm.mr
+}
+
+// Exif returns parsed Exif data from Heic
+func (hm Metadata) Exif() (exif.Exif, error) {
+	return exif.ParseExif(hm.mr, hm.ExifHeader)
+}
+
+// Xmp returns parsed Xmp data from Heic
+func (hm Metadata) Xmp() (xmp.XMP, error) {
+	sr := io.NewSectionReader(hm.mr, int64(hm.XmpHeader.Offset), int64(hm.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
 // Item represents an item in a HEIF file.
 type Item struct {
 	ID         uint16
 	Info       bmff.ItemInfoEntry
 	Location   bmff.ItemLocationBoxEntry
 	Properties bmff.ItemPropertyAssociationItem
+
+	// Role of the item and the ID of the item that a thumbnail,
+	// auxiliary image, tile or metadata item refers to.
+	Role  ItemRole
+	RefID uint16
+
+	// Dimensions from the "ispe" property and the Exif header of an image item.
+	Dimensions meta.Dimensions
+	ExifHeader meta.ExifHeader
 }
 
-func (hm *Metadata) itemByType(it bmff.ItemType) (item Item, err error) {
-	item.Info, err = hm.Meta.ItemInfo.LastItemByType(it)
-	if err == bmff.ErrItemNotFound {
-		err = meta.ErrNoExif
-		return
+// itemByType returns the item of type it with a "cdsc" reference to the primary item.
+// If none of the items of type it describes the primary item, the last item of type
+// it is returned. Items of type "mime" must have the contentType.
+//
+// Returns ErrItemNotFound if the item was not found.
+func (hm *Metadata) itemByType(it bmff.ItemType, contentType string) (item Item, err error) {
+	matches := func(infe bmff.ItemInfoEntry) bool {
+		return infe.ItemType == it && (it != bmff.ItemTypeMime || infe.ContentType == contentType)
+	}
+	found := false
+	for _, id := range hm.Meta.References.ReferencesTo(bmff.TypeCdsc, hm.Meta.Primary.ItemID) {
+		if item.Info, err = hm.Meta.ItemInfo.ItemByID(id); err == nil && matches(item.Info) {
+			found = true
+			break
+		}
+	}
+	for i := len(hm.Meta.ItemInfo.ItemInfos) - 1; !found && i >= 0; i-- {
+		if item.Info = hm.Meta.ItemInfo.ItemInfos[i]; matches(item.Info) {
+			found = true
+		}
+	}
+	if !found {
+		return Item{}, ErrItemNotFound
 	}
 	item.ID = item.Info.ItemID
-	item.Location, err = hm.Meta.Location.EntryByID(item.Info.ItemID)
-	if err == bmff.ErrItemNotFound {
+	if item.Location, err = hm.Meta.Location.EntryByID(item.ID); err != nil {
+		return Item{}, ErrItemNotFound
+	}
+	return item, nil
+}
+
+// exifItem returns the Exif item of the primary image.
+// Returns meta.ErrNoExif if the Exif item was not found.
+func (hm *Metadata) exifItem() (Item, error) {
+	item, err := hm.itemByType(bmff.ItemTypeExif, "")
+	if err == ErrItemNotFound {
 		err = meta.ErrNoExif
-		return
 	}
-	return
+	return item, err
 }
 
 // ReadXmpHeader reads Xmp Header from the Heic Metadata and returns an Xmp Header.
 // If an error occurs returns the error.
 //
+// The XMP is the "mime" item with the content type "application/rdf+xml".
+// Returns ErrItemNotFound if the XMP item was not found.
 func (hm *Metadata) ReadXmpHeader(r meta.Reader) (header meta.XmpHeader, err error) {
-	item, err := hm.itemByType(bmff.ItemTypeMime)
+	item, err := hm.itemByType(bmff.ItemTypeMime, xmpContentType)
 	if err != nil {
 		return
 	}
@@ -101,35 +276,65 @@ func (hm *Metadata) ReadXmpHeader(r meta.Reader) (header meta.XmpHeader, err err
 // If an error occurs returns the error.
 //
 func (hm *Metadata) ReadExifHeader(r meta.Reader) (header meta.ExifHeader, err error) {
-	item, err := hm.itemByType(bmff.ItemTypeExif)
+	item, err := hm.exifItem()
 	if err != nil {
 		return
 	}
-	offset := item.Location.FirstExtent.Offset
-	length := item.Location.FirstExtent.Length
+	hm.ExifHeader, err = readExifHeader(r, item.Location.FirstExtent.Offset, item.Location.FirstExtent.Length, hm.It)
+	return hm.ExifHeader, err
+}
 
-	var buf [18]byte
-	if _, err = r.ReadAt(buf[:], int64(offset)); err != nil {
+// readExifHeader reads the Exif Header of an Exif item at offset with length.
+// The Exif item begins with a 4 byte offset to the Tiff Header that
+// follows the offset (usually 6 for "Exif\0\0").
+func readExifHeader(r io.ReaderAt, offset, length uint64, it imagetype.ImageType) (header meta.ExifHeader, err error) {
+	var buf [12]byte
+	if _, err = r.ReadAt(buf[:4], int64(offset)); err != nil {
 		return
 	}
-
-	// Read BoxType
-	if !(string(buf[4:8]) == "Exif") {
-		err = fmt.Errorf("error wrong Box Type: %d", buf[4:8])
-		return
+	tiffOffset := uint64(binary.BigEndian.Uint32(buf[:4])) + 4
+	if tiffOffset+8 > length {
+		return header, meta.ErrInvalidHeader
 	}
 
 	// Read Tiff header
-	if _, err = r.Read(buf[:8]); err != nil {
+	tiffHeaderOffset := offset + tiffOffset
+	if _, err = r.ReadAt(buf[4:12], int64(tiffHeaderOffset)); err != nil {
 		return
 	}
-	byteOrder := meta.BinaryOrder(buf[10:14])
-	firstIfdOffset := byteOrder.Uint32(buf[14:18])
-	tiffHeaderOffset := int64(offset) + 10
-	hm.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, uint32(tiffHeaderOffset), uint32(length), hm.It)
-	hm.ExifHeader.FirstIfd = ifds.IFD0
+	byteOrder := meta.BinaryOrder(buf[4:8])
+	if byteOrder == nil {
+		return header, meta.ErrInvalidHeader
+	}
+	firstIfdOffset := byteOrder.Uint32(buf[8:12])
+	header = meta.NewExifHeader(byteOrder, firstIfdOffset, uint32(tiffHeaderOffset), uint32(length-tiffOffset), it)
+	header.FirstIfd = ifds.IFD0
+	return header, nil
+}
 
-	return hm.ExifHeader, err
+// Scan reads the Heic/Heif box structure (ftyp, meta, iinf, iloc and iref) from r,
+// locates the Exif item of the primary image and runs exifFn with a reader positioned at the Tiff Header
+// and the Exif Header. Image data is not decoded.
+//
+// Returns meta.ErrNoExif if the Exif item was not found.
+func Scan(r io.ReaderAt, exifFn func(r io.Reader, header meta.ExifHeader) error) (err error) {
+	sr := io.NewSectionReader(r, 0, math.MaxInt64)
+	hm, err := NewMetadata(sr, &meta.Metadata{It: imagetype.ImageHEIF})
+	if err != nil && len(hm.Meta.ItemInfo.ItemInfos) == 0 {
+		return err
+	}
+	item, err := hm.exifItem()
+	if err != nil {
+		return err
+	}
+	header, err := readExifHeader(r, item.Location.FirstExtent.Offset, item.Location.FirstExtent.Length, imagetype.ImageHEIF)
+	if err != nil {
+		return err
+	}
+	if exifFn == nil {
+		return nil
+	}
+	return exifFn(io.NewSectionReader(r, int64(header.TiffHeaderOffset), int64(header.ExifLength)), header)
 }
 
 // ReadXmp reads XMP metadata from the meta.Reader
@@ -156,3 +361,124 @@ func (hm *Metadata) ReadExif(r meta.Reader) (err error) {
 	}
 	return hm.ExifFn(r, hm.Metadata)
 }
+
+// ItemRole is the role of an item in a HEIF file.
+type ItemRole uint8
+
+// Item Roles
+const (
+	RoleUnknown   ItemRole = iota
+	RolePrimary            // primary image ("pitm")
+	RoleImage              // another image such as an image of a burst
+	RoleThumbnail          // thumbnail ("thmb" reference) of an image
+	RoleAuxiliary          // auxiliary image ("auxl" reference) such as an alpha plane or a depth map
+	RoleTile               // input image ("dimg" reference) of a derived image such as a grid
+	RoleMetadata           // Exif or XMP item ("cdsc" reference)
+)
+
+func (ir ItemRole) String() string {
+	switch ir {
+	case RolePrimary:
+		return "Primary"
+	case RoleImage:
+		return "Image"
+	case RoleThumbnail:
+		return "Thumbnail"
+	case RoleAuxiliary:
+		return "Auxiliary"
+	case RoleTile:
+		return "Tile"
+	case RoleMetadata:
+		return "Metadata"
+	}
+	return "Unknown"
+}
+
+// Items returns all of the items of the HEIF file in the order of the "iinf" box
+// with their role, dimensions and Exif header. r is used to read the Exif header
+// of each image that has an Exif item.
+func (hm Metadata) Items(r io.ReaderAt) []Item {
+	items := make([]Item, 0, len(hm.Meta.ItemInfo.ItemInfos))
+	for _, infe := range hm.Meta.ItemInfo.ItemInfos {
+		item := Item{ID: infe.ItemID, Info: infe}
+		item.Location, _ = hm.Meta.Location.EntryByID(item.ID)
+		item.Role, item.RefID = hm.itemRole(infe)
+		for _, box := range hm.Meta.Properties.PropertiesByID(item.ID) {
+			if ispe, ok := box.(bmff.ImageSpatialExtentsProperty); ok {
+				item.Dimensions = meta.NewDimensions(ispe.W, ispe.H)
+				break
+			}
+		}
+		if item.Role != RoleMetadata {
+			if exifItem, err := hm.itemExif(item.ID); err == nil {
+				item.ExifHeader, _ = readExifHeader(r, exifItem.Location.FirstExtent.Offset, exifItem.Location.FirstExtent.Length, hm.It)
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// itemRole returns the role of the item infe and the ID of the item
+// that it refers to.
+func (hm Metadata) itemRole(infe bmff.ItemInfoEntry) (ItemRole, uint16) {
+	if infe.ItemID == hm.Meta.Primary.ItemID {
+		return RolePrimary, 0
+	}
+	for _, ref := range hm.Meta.References.References {
+		if ref.FromID != infe.ItemID || len(ref.ToIDs) == 0 {
+			continue
+		}
+		switch ref.Type {
+		case bmff.TypeCdsc:
+			return RoleMetadata, ref.ToIDs[0]
+		case bmff.TypeThmb:
+			return RoleThumbnail, ref.ToIDs[0]
+		case bmff.TypeAuxl:
+			return RoleAuxiliary, ref.ToIDs[0]
+		}
+	}
+	for _, ref := range hm.Meta.References.References {
+		if ref.Type != bmff.TypeDimg {
+			continue
+		}
+		for _, id := range ref.ToIDs {
+			if id == infe.ItemID {
+				return RoleTile, ref.FromID
+			}
+		}
+	}
+	if infe.ItemType == bmff.ItemTypeExif || infe.ItemType == bmff.ItemTypeMime {
+		return RoleMetadata, 0
+	}
+	return RoleImage, 0
+}
+
+// itemExif returns the Exif item with a "cdsc" reference to the item id.
+// The Exif item of the primary image does not require a reference.
+//
+// Returns meta.ErrNoExif if the Exif item was not found.
+func (hm *Metadata) itemExif(id uint16) (item Item, err error) {
+	if id == hm.Meta.Primary.ItemID {
+		return hm.exifItem()
+	}
+	for _, exifID := range hm.Meta.References.ReferencesTo(bmff.TypeCdsc, id) {
+		if item.Info, err = hm.Meta.ItemInfo.ItemByID(exifID); err != nil || item.Info.ItemType != bmff.ItemTypeExif {
+			continue
+		}
+		if item.Location, err = hm.Meta.Location.EntryByID(exifID); err == nil {
+			item.ID = exifID
+			return item, nil
+		}
+	}
+	return Item{}, meta.ErrNoExif
+}
+
+// Exif returns the parsed Exif data of the item from r.
+// Returns meta.ErrNoExif if the item does not have an Exif header.
+func (item Item) Exif(r io.ReaderAt) (exif.Exif, error) {
+	if !item.ExifHeader.IsValid() {
+		return nil, meta.ErrNoExif
+	}
+	return exif.ParseExif(r, item.ExifHeader)
+}
@@ -2,9 +2,15 @@ package heic
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
 	"os"
 	"testing"
 
+	"github.com/evanoberholster/imagemeta/bmff"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 )
 
@@ -58,3 +64,275 @@ func BenchmarkHeicDecodeExif(b *testing.B) {
 		})
 	}
 }
+
+func TestScan(t *testing.T) {
+	f, err := os.Open("../testImages/Heic.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var header meta.ExifHeader
+	err = Scan(f, func(r io.Reader, h meta.ExifHeader) error {
+		header = h
+		// Reader is positioned at the Tiff Header
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return err
+		}
+		if meta.BinaryOrder(buf) != h.ByteOrder {
+			t.Errorf("Incorrect Tiff Header wanted %s got %v", h.ByteOrder, buf)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.ByteOrder != binary.BigEndian || header.FirstIfdOffset != 8 || header.TiffHeaderOffset != 4472 || header.ImageType != imagetype.ImageHEIF {
+		t.Errorf("Incorrect Exif Header got %s", header)
+	}
+
+	e, err := exif.ParseExif(f, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraMake() != "Canon" || e.CameraModel() != "Canon EOS 6D" {
+		t.Errorf("Incorrect Camera wanted %s %s got %s %s", "Canon", "Canon EOS 6D", e.CameraMake(), e.CameraModel())
+	}
+
+	// No Exif item
+	f2, err := os.Open("../testImages/AVIF2.avif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	if err = Scan(f2, nil); err != meta.ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", meta.ErrNoExif, err)
+	}
+
+	// Not a Heif file
+	if err = Scan(bytes.NewReader(make([]byte, 64)), nil); err == nil {
+		t.Errorf("Incorrect error wanted error got nil")
+	}
+}
+
+func TestParse(t *testing.T) {
+	f, err := os.Open("../testImages/Heic.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	hm, err := Parse(f, imagetype.ImageHEIF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.ImageType() != imagetype.ImageHEIF || hm.Dimensions() != meta.NewDimensions(3648, 5472) {
+		t.Errorf("Incorrect Metadata got %s %s", hm.ImageType(), hm.Dimensions())
+	}
+	if hm.ExifHeader.TiffHeaderOffset != 4472 || hm.ExifHeader.ImageType != imagetype.ImageHEIF {
+		t.Errorf("Incorrect Exif Header got %s", hm.ExifHeader)
+	}
+	// The XMP item is past the end of the truncated sample
+	if hm.XmpHeader != meta.NewXMPHeader(5550, 3054) {
+		t.Errorf("Incorrect Xmp Header got %v", hm.XmpHeader)
+	}
+	if _, mirrored := hm.Mirror(); hm.Rotation() != 0 || mirrored {
+		t.Errorf("Incorrect transforms got %s mirrored %t", hm.Rotation(), mirrored)
+	}
+	e, err := hm.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraModel() != "Canon EOS 6D" {
+		t.Errorf("Incorrect Camera Model wanted %s got %s", "Canon EOS 6D", e.CameraModel())
+	}
+
+	// No Exif item
+	f2, err := os.Open("../testImages/AVIF2.avif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	hm, err = Parse(f2, imagetype.ImageAVIF)
+	if err != meta.ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", meta.ErrNoExif, err)
+	}
+	if hm.ImageType() != imagetype.ImageAVIF || hm.Dimensions() != meta.NewDimensions(2000, 1333) {
+		t.Errorf("Incorrect Metadata got %s %s", hm.ImageType(), hm.Dimensions())
+	}
+}
+
+func TestPrimaryItem(t *testing.T) {
+	// Rotated image
+	f, err := os.Open("../bmff/samples/4.sample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	hm, err := NewMetadata(f, &meta.Metadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm.Rotation() != 3 {
+		t.Errorf("Incorrect Rotation wanted %d got %d", 3, hm.Rotation())
+	}
+
+	// The XMP of the primary image is item 51, item 53 is the XMP of the depth image.
+	f2, err := os.Open("../bmff/samples/iPhone12.sample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	if hm, err = NewMetadata(f2, &meta.Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	item, err := hm.itemByType(bmff.ItemTypeMime, xmpContentType)
+	if err != nil || item.ID != 51 {
+		t.Errorf("Incorrect XMP item wanted %d got %d (%v)", 51, item.ID, err)
+	}
+	if item, err = hm.exifItem(); err != nil || item.ID != 50 {
+		t.Errorf("Incorrect Exif item wanted %d got %d (%v)", 50, item.ID, err)
+	}
+}
+
+func TestParseAVIF(t *testing.T) {
+	for _, v := range []struct {
+		filename string
+		dim      meta.Dimensions
+		bitDepth uint8
+		colour   bool
+	}{
+		{"../testImages/AVIF.avif", meta.NewDimensions(1280, 720), 0, false}, // mdat box before the meta box
+		{"../testImages/AVIF2.avif", meta.NewDimensions(2000, 1333), 8, true},
+		{"../bmff/samples/avif.sample", meta.NewDimensions(1204, 800), 10, false},
+	} {
+		f, err := os.Open(v.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hm, err := Parse(f, imagetype.ImageAVIF)
+		f.Close()
+		if err != meta.ErrNoExif {
+			t.Errorf("Incorrect error for %s wanted %v got %v", v.filename, meta.ErrNoExif, err)
+		}
+		if hm.Dimensions() != v.dim || hm.BitDepth() != v.bitDepth {
+			t.Errorf("Incorrect Metadata for %s wanted %s %d got %s %d", v.filename, v.dim, v.bitDepth, hm.Dimensions(), hm.BitDepth())
+		}
+		if _, ok := hm.Colour(); ok != v.colour || hm.HDR() || hm.Alpha() {
+			t.Errorf("Incorrect colour information for %s got %t HDR %t Alpha %t", v.filename, ok, hm.HDR(), hm.Alpha())
+		}
+	}
+}
+
+func TestPrimaryItemProperties(t *testing.T) {
+	// Primary item 1 with an alpha plane item 2
+	hm := Metadata{Metadata: &meta.Metadata{}}
+	hm.Meta.Primary.ItemID = 1
+	hm.Meta.References.References = []bmff.ItemReference{{Type: bmff.TypeAuxl, FromID: 2, ToIDs: []uint16{1}}}
+	hm.Meta.Properties.PropertyContainer.Properties = []bmff.Box{
+		bmff.ColourInformationBox{ColourType: bmff.ColourTypeProf},
+		bmff.ColourInformationBox{ColourType: bmff.ColourTypeNclx, ColourPrimaries: 9, TransferCharacteristics: bmff.TransferCharacteristicsPQ, MatrixCoefficients: 9},
+		bmff.ContentLightLevelBox{MaxContentLightLevel: 1000, MaxPicAverageLightLevel: 400},
+		bmff.AuxiliaryTypeProperty{AuxType: "urn:mpeg:mpegB:cicp:systems:auxiliary:alpha"},
+	}
+	hm.Meta.Properties.Associations.Entries = []bmff.ItemPropertyAssociationItem{
+		{ItemID: 1, Associations: [12]uint16{1, 2, 3}},
+		{ItemID: 2, Associations: [12]uint16{4}},
+	}
+	hm.properties = hm.Meta.Properties.PropertiesByID(1)
+
+	if colr, ok := hm.Colour(); !ok || colr.TransferCharacteristics != bmff.TransferCharacteristicsPQ {
+		t.Errorf("Incorrect Colour got %s", colr)
+	}
+	if !hm.HDR() {
+		t.Errorf("Incorrect HDR wanted %t got %t", true, hm.HDR())
+	}
+	if clli, ok := hm.ContentLightLevel(); !ok || clli.MaxContentLightLevel != 1000 {
+		t.Errorf("Incorrect ContentLightLevel got %s", clli)
+	}
+	if _, ok := hm.MasteringDisplay(); ok {
+		t.Errorf("Incorrect MasteringDisplay wanted %t got %t", false, ok)
+	}
+	if !hm.Alpha() {
+		t.Errorf("Incorrect Alpha wanted %t got %t", true, hm.Alpha())
+	}
+}
+
+func TestItems(t *testing.T) {
+	f, err := os.Open("../testImages/Heic.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	hm, err := NewMetadata(f, &meta.Metadata{It: imagetype.ImageHEIF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := hm.Items(f)
+	if len(items) != 91 {
+		t.Fatalf("Incorrect number of items wanted %d got %d", 91, len(items))
+	}
+	if items[0].Role != RoleTile || items[0].RefID != 89 || items[0].Dimensions != meta.NewDimensions(512, 512) {
+		t.Errorf("Incorrect tile item got %s %d %s", items[0].Role, items[0].RefID, items[0].Dimensions)
+	}
+	primary := items[88]
+	if primary.ID != 89 || primary.Role != RolePrimary || primary.Dimensions != meta.NewDimensions(3648, 5472) {
+		t.Errorf("Incorrect primary item got %d %s %s", primary.ID, primary.Role, primary.Dimensions)
+	}
+	e, err := primary.Exif(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraModel() != "Canon EOS 6D" {
+		t.Errorf("Incorrect Camera Model wanted %s got %s", "Canon EOS 6D", e.CameraModel())
+	}
+	if items[89].Role != RoleMetadata || items[89].RefID != 89 {
+		t.Errorf("Incorrect Exif item got %s %d", items[89].Role, items[89].RefID)
+	}
+	if _, err = items[89].Exif(f); err != meta.ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", meta.ErrNoExif, err)
+	}
+
+	// Thumbnail and Auxiliary images
+	for _, v := range []struct {
+		filename string
+		id       uint16
+		role     ItemRole
+		dim      meta.Dimensions
+	}{
+		{"../bmff/samples/4.sample", 50, RoleThumbnail, meta.NewDimensions(320, 240)},
+		{"../bmff/samples/iPhone12.sample", 52, RoleAuxiliary, meta.NewDimensions(2016, 1512)},
+	} {
+		f, err := os.Open(v.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hm, err := NewMetadata(f, &meta.Metadata{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range hm.Items(f) {
+			if item.ID == v.id && (item.Role != v.role || item.RefID != 49 || item.Dimensions != v.dim) {
+				t.Errorf("Incorrect item %d for %s got %s %d %s", v.id, v.filename, item.Role, item.RefID, item.Dimensions)
+			}
+		}
+		f.Close()
+	}
+
+	// Burst of two images with an Exif item for the second image
+	hm = Metadata{Metadata: &meta.Metadata{}}
+	hm.Meta.Primary.ItemID = 1
+	hm.Meta.ItemInfo.ItemInfos = []bmff.ItemInfoEntry{
+		{ItemID: 1, ItemType: bmff.ItemTypeHvc1},
+		{ItemID: 2, ItemType: bmff.ItemTypeHvc1},
+		{ItemID: 3, ItemType: bmff.ItemTypeExif},
+	}
+	hm.Meta.References.References = []bmff.ItemReference{{Type: bmff.TypeCdsc, FromID: 3, ToIDs: []uint16{2}}}
+	roles := []ItemRole{RolePrimary, RoleImage, RoleMetadata}
+	for i, item := range hm.Items(bytes.NewReader(nil)) {
+		if item.Role != roles[i] {
+			t.Errorf("Incorrect role for item %d wanted %s got %s", item.ID, roles[i], item.Role)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+// Package ico reads the sizes of the images of an ICO icon or a CUR cursor
+// without decoding the images. Both BMP and PNG encoded images are supported.
+package ico
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif      = meta.ErrNoExif
+	ErrNoICOHeader = errors.New("no ICO Header")
+)
+
+// Entry is an image of an ICO or CUR file.
+type Entry struct {
+	Width, Height uint32
+
+	// BitDepth is the number of bits per pixel of the image.
+	BitDepth uint16
+
+	// PNG is true if the image is PNG encoded, otherwise it is a BMP
+	// without a file header.
+	PNG bool
+
+	// HotspotX and HotspotY are the hotspot of a cursor image.
+	HotspotX, HotspotY uint16
+
+	// Offset from the start of the file and Length of the image in bytes
+	Offset, Length uint32
+}
+
+// Metadata from an ICO or CUR file
+type Metadata struct {
+	mr meta.Reader
+
+	entries []Entry
+	cursor  bool
+}
+
+// Dimensions returns the dimensions (width and height) of the largest image
+func (m Metadata) Dimensions() meta.Dimensions {
+	var width, height uint32
+	for _, e := range m.entries {
+		if uint64(e.Width)*uint64(e.Height) > uint64(width)*uint64(height) {
+			width, height = e.Width, e.Height
+		}
+	}
+	return meta.NewDimensions(width, height)
+}
+
+// ImageType returns imagetype.ImageICO for ICO and CUR images
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageICO
+}
+
+// Entries returns the images of the file in directory order.
+func (m Metadata) Entries() []Entry {
+	return m.entries
+}
+
+// Cursor returns true if the file is a CUR cursor.
+func (m Metadata) Cursor() bool {
+	return m.cursor
+}
+
+// HasSize returns true if the file has an image with the width and height.
+func (m Metadata) HasSize(width, height uint32) bool {
+	for _, e := range m.entries {
+		if e.Width == width && e.Height == height {
+			return true
+		}
+	}
+	return false
+}
+
+// EntryReader returns a reader of the image data of e.
+func (m Metadata) EntryReader(e Entry) io.Reader {
+	return io.NewSectionReader(m.mr, int64(e.Offset), int64(e.Length))
+}
+
+// PreviewImage returns an ICO preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns ErrNoExif, ICO images do not have Exif metadata
+func (m Metadata) Exif() (exif.Exif, error) {
+	return nil, ErrNoExif
+}
+
+// Xmp returns xmp.ErrNoXMP, ICO images do not have XMP metadata
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	return xmp.XMP{}, xmp.ErrNoXMP
+}
+
+// ScanICO reads the icon directory of an ICO or CUR file from mr. The size and
+// bit depth of each image are read from its BMP or PNG header. Returns Metadata.
+//
+// Returns the error ErrNoICOHeader if mr is not an ICO or CUR file.
+func ScanICO(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if n, _ := mr.ReadAt(buf[:], 0); n < headerLength ||
+		icoByteOrder.Uint16(buf[0:2]) != 0 {
+		return m, ErrNoICOHeader
+	}
+	switch icoByteOrder.Uint16(buf[2:4]) {
+	case typeIcon:
+	case typeCursor:
+		m.cursor = true
+	default:
+		return m, ErrNoICOHeader
+	}
+	count := int(icoByteOrder.Uint16(buf[4:6]))
+	if count == 0 {
+		return m, ErrNoICOHeader
+	}
+	dir := make([]byte, count*entryLength)
+	if n, _ := mr.ReadAt(dir, headerLength); n < len(dir) {
+		return m, ErrNoICOHeader
+	}
+	m.entries = make([]Entry, count)
+	for i := range m.entries {
+		m.entries[i] = m.readEntry(dir[i*entryLength : (i+1)*entryLength])
+	}
+	return m, nil
+}
+
+// readEntry reads an entry of the icon directory and the header of its image.
+func (m Metadata) readEntry(buf []byte) (e Entry) {
+	// A width or height of 0 is 256 pixels
+	e.Width, e.Height = uint32(buf[0]), uint32(buf[1])
+	if e.Width == 0 {
+		e.Width = 256
+	}
+	if e.Height == 0 {
+		e.Height = 256
+	}
+	if m.cursor {
+		e.HotspotX = icoByteOrder.Uint16(buf[4:6])
+		e.HotspotY = icoByteOrder.Uint16(buf[6:8])
+	} else {
+		e.BitDepth = icoByteOrder.Uint16(buf[6:8])
+	}
+	e.Length = icoByteOrder.Uint32(buf[8:12])
+	e.Offset = icoByteOrder.Uint32(buf[12:16])
+
+	var header [pngHeaderLength]byte
+	if n, _ := m.mr.ReadAt(header[:], int64(e.Offset)); n < len(header) {
+		return e
+	}
+	if string(header[:8]) == pngSignature {
+		// PNG IHDR chunk: width, height, bit depth and color type
+		e.PNG = true
+		e.Width = binary.BigEndian.Uint32(header[16:20])
+		e.Height = binary.BigEndian.Uint32(header[20:24])
+		e.BitDepth = uint16(header[24]) * pngChannels(header[25])
+		return e
+	}
+	if size := icoByteOrder.Uint32(header[0:4]); size >= bmpInfoHeaderLength {
+		// BMP BITMAPINFOHEADER: the height includes the AND mask
+		width := int32(icoByteOrder.Uint32(header[4:8]))
+		height := int32(icoByteOrder.Uint32(header[8:12])) / 2
+		if width > 0 && height > 0 {
+			e.Width, e.Height = uint32(width), uint32(height)
+		}
+		e.BitDepth = icoByteOrder.Uint16(header[14:16])
+	}
+	return e
+}
+
+// pngChannels returns the number of channels of a PNG color type.
+func pngChannels(colorType uint8) uint16 {
+	switch colorType {
+	case 2:
+		return 3 // RGB
+	case 4:
+		return 2 // Grayscale and alpha
+	case 6:
+		return 4 // RGBA
+	}
+	return 1 // Grayscale and palette
+}
+
+// Header lengths
+const (
+	headerLength        = 6
+	entryLength         = 16
+	bmpInfoHeaderLength = 40
+
+	// pngHeaderLength is the length of the PNG signature and the IHDR chunk
+	// up to the color type.
+	pngHeaderLength = 26
+)
+
+// Image types
+const (
+	typeIcon   = 1
+	typeCursor = 2
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// icoByteOrder ICO always uses a LittleEndian byteorder.
+var icoByteOrder = binary.LittleEndian
@@ -0,0 +1,131 @@
+package ico
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// testImage is an image of an icon directory entry
+type testImage struct {
+	width, height uint8
+	x, y          uint16
+	data          []byte
+}
+
+// bmpImage returns a BITMAPINFOHEADER of an icon image with the width, height and bit depth.
+func bmpImage(width, height int32, bitDepth uint16) []byte {
+	buf := make([]byte, 40)
+	binary.LittleEndian.PutUint32(buf[0:4], 40)
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(width))
+	binary.LittleEndian.PutUint32(buf[8:12], uint32(height*2))
+	binary.LittleEndian.PutUint16(buf[14:16], bitDepth)
+	return buf
+}
+
+// pngImage returns a PNG signature and IHDR chunk with the width, height, bit depth and color type.
+func pngImage(width, height uint32, bitDepth, colorType uint8) []byte {
+	buf := append([]byte(pngSignature), 0, 0, 0, 13, 'I', 'H', 'D', 'R')
+	buf = append(buf, make([]byte, 8)...)
+	binary.BigEndian.PutUint32(buf[16:20], width)
+	binary.BigEndian.PutUint32(buf[20:24], height)
+	return append(buf, bitDepth, colorType, 0, 0, 0)
+}
+
+// icoFile returns an ICO or CUR file with the images.
+func icoFile(typ uint16, images ...testImage) []byte {
+	buf := make([]byte, headerLength+len(images)*entryLength)
+	binary.LittleEndian.PutUint16(buf[2:4], typ)
+	binary.LittleEndian.PutUint16(buf[4:6], uint16(len(images)))
+	for i, img := range images {
+		e := buf[headerLength+i*entryLength:]
+		e[0], e[1] = img.width, img.height
+		binary.LittleEndian.PutUint16(e[4:6], img.x)
+		binary.LittleEndian.PutUint16(e[6:8], img.y)
+		binary.LittleEndian.PutUint32(e[8:12], uint32(len(img.data)))
+		binary.LittleEndian.PutUint32(e[12:16], uint32(len(buf)))
+		buf = append(buf, img.data...)
+	}
+	return buf
+}
+
+func TestScanICO(t *testing.T) {
+	buf := icoFile(typeIcon,
+		testImage{16, 16, 1, 32, bmpImage(16, 16, 32)},
+		testImage{48, 48, 1, 8, bmpImage(48, 48, 8)},
+		testImage{0, 0, 1, 32, pngImage(256, 256, 8, 6)},
+	)
+	m, err := ScanICO(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ImageType() != imagetype.ImageICO || m.Cursor() {
+		t.Errorf("Incorrect Metadata got %s cursor %t", m.ImageType(), m.Cursor())
+	}
+	if m.Dimensions() != meta.NewDimensions(256, 256) {
+		t.Errorf("Incorrect Dimensions wanted %s got %s", meta.NewDimensions(256, 256), m.Dimensions())
+	}
+	want := []Entry{
+		{Width: 16, Height: 16, BitDepth: 32, Offset: 54, Length: 40},
+		{Width: 48, Height: 48, BitDepth: 8, Offset: 94, Length: 40},
+		{Width: 256, Height: 256, BitDepth: 32, PNG: true, Offset: 134, Length: 29},
+	}
+	entries := m.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("Incorrect number of entries wanted %d got %d", len(want), len(entries))
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("Incorrect entry %d wanted %+v got %+v", i, want[i], entries[i])
+		}
+	}
+	if !m.HasSize(48, 48) || m.HasSize(32, 32) {
+		t.Errorf("Incorrect HasSize")
+	}
+	b, err := io.ReadAll(m.EntryReader(entries[2]))
+	if err != nil || !bytes.Equal(b, pngImage(256, 256, 8, 6)) {
+		t.Errorf("Incorrect EntryReader data %v", err)
+	}
+	if _, err = m.Exif(); err != ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+}
+
+func TestScanCUR(t *testing.T) {
+	m, err := ScanICO(bytes.NewReader(icoFile(typeCursor, testImage{32, 32, 5, 7, bmpImage(32, 32, 1)})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Cursor() {
+		t.Errorf("Incorrect Cursor wanted %t got %t", true, m.Cursor())
+	}
+	want := Entry{Width: 32, Height: 32, BitDepth: 1, HotspotX: 5, HotspotY: 7, Offset: 22, Length: 40}
+	if e := m.Entries()[0]; e != want {
+		t.Errorf("Incorrect entry wanted %+v got %+v", want, e)
+	}
+}
+
+func TestScanICOErrors(t *testing.T) {
+	valid := icoFile(typeIcon, testImage{16, 16, 1, 32, bmpImage(16, 16, 32)})
+	testErrors := []struct {
+		name string
+		buf  []byte
+	}{
+		{"Short", valid[:4]},
+		{"Reserved", append([]byte{1}, valid[1:]...)},
+		{"Type", append([]byte{0, 0, 3}, valid[3:]...)},
+		{"NoImages", icoFile(typeIcon)},
+		{"TruncatedDirectory", valid[:20]},
+	}
+	for _, te := range testErrors {
+		t.Run(te.name, func(t *testing.T) {
+			if _, err := ScanICO(bytes.NewReader(te.buf)); err != ErrNoICOHeader {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoICOHeader, err)
+			}
+		})
+	}
+}
@@ -22,26 +22,30 @@ import (
 // Implementation follows
 // http://www.hackerfactor.com/blog/index.php?/archives/432-Looks-Like-It.html
 func newPHash(img image.Image) (phash PHash64, err error) {
-	var size image.Point
-	v := 16
-	if img != nil {
-		size = img.Bounds().Size()
+	if img == nil {
+		err = ErrImageObject
+		return
 	}
+	v := 16
+	size := img.Bounds().Size()
 	if size.X == 64 {
 		v = 8
 	}
 
-	pixels := transforms.Rgb2Gray(img)
-	dct := transforms.DCT2D(pixels, size.X, size.X)
+	pixels := transforms.Grayscale(img, size.X)
+	dct := transforms.DCT2D(grayRows(pixels, size.X), size.X, size.X)
 	flattens := transforms.FlattenPixels(dct, v, v)
-	median := transforms.MedianOfPixels(flattens)
 
-	for idx, p := range flattens {
-		if p > median {
-			phash |= 1 << uint(len(flattens)-idx-1) // leftShiftSet
-		}
+	return hashFromDCT(flattens), nil
+}
+
+// grayRows returns the rows of size x size grayscale pixels in row-major order.
+func grayRows(pixels []float64, size int) [][]float64 {
+	rows := make([][]float64, size)
+	for i := range rows {
+		rows[i] = pixels[i*size : (i+1)*size]
 	}
-	return phash, nil
+	return rows
 }
 
 // NewPHash64 is a Perception Hash function returns a hash computation of phash.
@@ -60,37 +64,62 @@ func NewPHash64(img image.Image) (phash PHash64, err error) {
 	pixels := pixelsPool64.Get().(*[]float64)
 
 	transforms.Rgb2GrayFast(img, pixels)
-	flattens := transforms.DCT2DHash64(pixels)
-	//flattens := transforms.FlattenPixelsHash64(pixels)
+	phash = phash64(pixels)
 	pixelsPool64.Put(pixels)
 
-	median := transforms.MedianOfPixels64(flattens[:])
+	return phash, nil
+}
 
-	for idx, p := range flattens {
+// phash64 returns the PHash64 of 64x64 grayscale pixels. The pixels are overwritten.
+func phash64(pixels *[]float64) PHash64 {
+	flattens := transforms.DCT2DHash64(pixels)
+	return hashFromDCT(flattens[:])
+}
+
+// hashFromDCT returns the Hash64 of an 8x8 block of DCT coefficients in row-major order.
+// The median is computed without the [0][0] DC coefficient, which only holds the
+// average brightness of the image. A bit is set for each coefficient that is strictly
+// greater than the median, starting from the most significant bit.
+func hashFromDCT(coeffs []float64) (hash Hash64) {
+	if len(coeffs) > 64 {
+		coeffs = coeffs[:64]
+	}
+	median := dctMedian(coeffs)
+
+	for idx, p := range coeffs {
 		if p > median {
-			phash |= 1 << uint(len(flattens)-idx-1) // leftShiftSet
+			hash |= 1 << uint(63-idx) // leftShiftSet
 		}
 	}
-	return phash, nil
+	return hash
+}
+
+// dctMedian returns the median of DCT coefficients without the DC coefficient.
+func dctMedian(coeffs []float64) float64 {
+	if len(coeffs) < 2 {
+		return 0
+	}
+	return transforms.MedianOfPixels(coeffs[1:])
 }
 
 // newPHashExt is a Perception Hash function returns a hash computation of phash.
 // Implementation follows
 // http://www.hackerfactor.com/blog/index.php?/archives/432-Looks-Like-It.html
 func newPHashExt(img image.Image) (phash PHash256, err error) {
-	var size image.Point
-	v := 16
-	if img != nil {
-		size = img.Bounds().Size()
+	if img == nil {
+		err = ErrImageObject
+		return
 	}
+	v := 16
+	size := img.Bounds().Size()
 	if size.X == 64 {
 		v = 8
 	}
 
-	pixels := transforms.Rgb2Gray(img)
-	dct := transforms.DCT2D(pixels, size.X, size.X)
+	pixels := transforms.Grayscale(img, size.X)
+	dct := transforms.DCT2D(grayRows(pixels, size.X), size.X, size.X)
 	flattens := transforms.FlattenPixels(dct, v, v)
-	median := transforms.MedianOfPixels(flattens)
+	median := dctMedian(flattens)
 
 	for idx, p := range flattens {
 		indexOfArray := idx / 64
@@ -117,11 +146,16 @@ func NewPHash256(img image.Image) (phash PHash256, err error) {
 	pixels := pixelsPool256.Get().(*[]float64)
 
 	transforms.Rgb2GrayFast(img, pixels)
-	flattens := transforms.DCT2DHash256(pixels)
-	//flattens := transforms.FlattenPixelsHash256(pixels)
+	phash = phash256(pixels)
 	pixelsPool256.Put(pixels)
 
-	median := transforms.MedianOfPixels256(flattens[:])
+	return phash, nil
+}
+
+// phash256 returns the PHash256 of 256x256 grayscale pixels. The pixels are overwritten.
+func phash256(pixels *[]float64) (phash PHash256) {
+	flattens := transforms.DCT2DHash256(pixels)
+	median := dctMedian(flattens[:])
 
 	for idx, p := range flattens {
 		indexOfArray := idx / 64
@@ -129,8 +163,7 @@ func NewPHash256(img image.Image) (phash PHash256, err error) {
 			phash[indexOfArray] |= 1 << uint(64-idx%64-1) // leftShiftSet
 		}
 	}
-
-	return phash, nil
+	return phash
 }
 
 // NewAHash is an Average Hash fuction that returns a hash computation of average hash.
@@ -143,20 +176,54 @@ func NewAHash(img image.Image) (ahash Ahash, err error) {
 	}
 
 	// Create 64bits hash.
-	//resized := resize.Resize(8, 8, img, resize.Bilinear)
-	pixels := transforms.Rgb2Gray(img)
-	flattens := transforms.FlattenPixels(pixels, 8, 8)
-	avg := transforms.MeanOfPixels(flattens)
+	pixels := transforms.Grayscale(img, 8)
+	avg := transforms.MeanOfPixels(pixels)
 
-	for idx, p := range flattens {
+	for idx, p := range pixels {
 		if p > avg {
-			ahash |= 1 << uint(len(flattens)-idx-1)
+			ahash |= 1 << uint(len(pixels)-idx-1)
 		}
 	}
 
 	return ahash, nil
 }
 
+// WHash is a Wavelet Hash function that returns a 64bit hash computation of whash.
+// The image is transformed with a 3 level 2D Haar wavelet to an 8x8 low frequency block.
+// The DC coefficient is dropped and the remaining coefficients are thresholded against their median.
+// Requires a 64x64 image.
+func WHash(img image.Image) (whash Hash64, err error) {
+	if img == nil {
+		err = ErrImageObject
+		return
+	}
+	size := img.Bounds().Size()
+	if size.X != 64 || size.Y != 64 {
+		err = errors.New("error image size incompatible. WHash requires 64x64 image")
+		return
+	}
+
+	pixels := pixelsPool64.Get().(*[]float64)
+	transforms.Rgb2GrayFast(img, pixels)
+	transforms.HaarWavelet2D(*pixels, 64, 64, 3)
+
+	var flattens [64]float64
+	for i := 0; i < 8; i++ {
+		copy(flattens[i*8:i*8+8], (*pixels)[i*64:i*64+8])
+	}
+	pixelsPool64.Put(pixels)
+
+	// Drop the DC coefficient
+	median := transforms.MedianOfPixels(flattens[1:])
+
+	for idx := 1; idx < len(flattens); idx++ {
+		if flattens[idx] > median {
+			whash |= 1 << uint(len(flattens)-idx-1) // leftShiftSet
+		}
+	}
+	return whash, nil
+}
+
 // Pixel Pools
 
 // Pixel pool 64bit
@@ -189,6 +256,9 @@ type Ahash uint64
 // Phash is a type alias for PHash64
 type Phash = PHash64
 
+// Hash64 is a type alias for PHash64, used by 64bit image hashes
+type Hash64 = PHash64
+
 // PHash64 is a 64bit Perception Hash
 type PHash64 uint64
 
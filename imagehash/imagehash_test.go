@@ -268,3 +268,38 @@ func TestPhash256(t *testing.T) {
 	}
 
 }
+
+func TestWHash(t *testing.T) {
+	f, err := os.Open("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resized := resize.Resize(64, 64, img, resize.Bilinear)
+	// Lightly blurred by downscaling and upscaling
+	blurred := resize.Resize(64, 64, resize.Resize(32, 32, img, resize.Bilinear), resize.Bilinear)
+
+	h1, err := WHash(resized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h2, err := WHash(blurred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h1 == 0 {
+		t.Errorf("WHash should not be empty")
+	}
+	if d := h1.Distance(h2); d > 6 {
+		t.Errorf("WHash distance between original and blurred image wanted <= %d got %d (%s, %s)", 6, d, h1, h2)
+	}
+
+	if _, err = WHash(resize.Resize(32, 32, img, resize.Bilinear)); err == nil {
+		t.Errorf("WHash expected error for incompatible image size")
+	}
+}
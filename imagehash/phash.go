@@ -0,0 +1,98 @@
+package imagehash
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/evanoberholster/imagemeta/imagehash/transforms"
+)
+
+// Errors
+var (
+	ErrHashSize   = errors.New("error hash size incompatible. PHash requires hashSize*highFreqFactor to be a power of 2")
+	ErrHashLength = errors.New("error hash lengths are not equal")
+)
+
+// Hash is a Perception Hash of hashSize*hashSize bits.
+// Bits are stored from the most significant bit of each uint64.
+type Hash struct {
+	bits int
+	hash []uint64
+}
+
+// newHash returns a Hash of n bits
+func newHash(n int) Hash {
+	return Hash{bits: n, hash: make([]uint64, (n+63)/64)}
+}
+
+// Bits returns the number of bits of the Hash
+func (h Hash) Bits() int {
+	return h.bits
+}
+
+// Distance between Hash values. Returns ErrHashLength
+// if the Hashes do not have the same number of bits.
+func (h Hash) Distance(hash Hash) (int, error) {
+	if h.bits != hash.bits {
+		return -1, ErrHashLength
+	}
+	var i int
+	for idx := range h.hash {
+		i += popcnt(h.hash[idx] ^ hash.hash[idx])
+	}
+	return i, nil
+}
+
+func (h Hash) String() string {
+	var sb strings.Builder
+	sb.WriteString("p:")
+	for _, v := range h.hash {
+		fmt.Fprintf(&sb, "%016x", v)
+	}
+	return sb.String()[:2+(h.bits+3)/4]
+}
+
+// PHash is a Perception Hash function that returns a hash of hashSize*hashSize bits.
+// The image is resized to a square of hashSize*highFreqFactor, transformed with a 2D DCT
+// and the top-left hashSize*hashSize block is thresholded against its median like hashFromDCT.
+// Implementation follows: http://www.hackerfactor.com/blog/index.php?/archives/432-Looks-Like-It.html
+//
+// hashSize*highFreqFactor must be a power of 2. The 8x8 (64bit) hash of a 64x64 image
+// and the 16x16 (256bit) hash of a 256x256 image share the NewPHash64 and NewPHash256 transforms.
+func PHash(img image.Image, hashSize, highFreqFactor int) (Hash, error) {
+	if img == nil {
+		return Hash{}, ErrImageObject
+	}
+	size := hashSize * highFreqFactor
+	if hashSize < 1 || highFreqFactor < 1 || size&(size-1) != 0 {
+		return Hash{}, ErrHashSize
+	}
+	pixels := transforms.Grayscale(img, size)
+
+	// Fast paths
+	if hashSize == 8 && size == 64 {
+		return Hash{bits: 64, hash: []uint64{uint64(phash64(&pixels))}}, nil
+	}
+	if hashSize == 16 && size == 256 {
+		phash := phash256(&pixels)
+		return Hash{bits: 256, hash: phash[:]}, nil
+	}
+
+	rows := make([][]float64, size)
+	for i := range rows {
+		rows[i] = pixels[i*size : (i+1)*size]
+	}
+	dct := transforms.DCT2D(rows, size, size)
+	flattens := transforms.FlattenPixels(dct, hashSize, hashSize)
+	median := dctMedian(flattens)
+
+	phash := newHash(hashSize * hashSize)
+	for idx, p := range flattens {
+		if p > median {
+			phash.hash[idx/64] |= 1 << uint(64-idx%64-1) // leftShiftSet
+		}
+	}
+	return phash, nil
+}
@@ -0,0 +1,158 @@
+package imagehash
+
+import (
+	"image"
+	"image/jpeg"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/imagehash/transforms"
+	"github.com/nfnt/resize"
+)
+
+func TestPHash(t *testing.T) {
+	f, err := os.Open("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	phashTests := []struct {
+		hashSize       int
+		highFreqFactor int
+		bits           int
+		str            int
+		err            error
+	}{
+		{4, 4, 16, 6, nil},
+		{8, 4, 64, 18, nil},
+		{8, 8, 64, 18, nil},
+		{16, 4, 256, 66, nil},
+		{16, 16, 256, 66, nil},
+		{3, 4, 0, 2, ErrHashSize},
+		{0, 4, 0, 2, ErrHashSize},
+	}
+	for _, pt := range phashTests {
+		h, err := PHash(img, pt.hashSize, pt.highFreqFactor)
+		if err != pt.err {
+			t.Fatalf("Incorrect PHash(%d,%d) error wanted %v got %v", pt.hashSize, pt.highFreqFactor, pt.err, err)
+		}
+		if h.Bits() != pt.bits {
+			t.Errorf("Incorrect PHash(%d,%d) bits wanted %d got %d", pt.hashSize, pt.highFreqFactor, pt.bits, h.Bits())
+		}
+		if len(h.String()) != pt.str {
+			t.Errorf("Incorrect PHash(%d,%d) string length wanted %d got %d (%s)", pt.hashSize, pt.highFreqFactor, pt.str, len(h.String()), h)
+		}
+	}
+
+	// Fast paths match the fixed size hashes
+	h64, _ := PHash(img, 8, 8)
+	p64, _ := NewPHash64(resize.Resize(64, 64, img, resize.Bilinear))
+	if h64.hash[0] != uint64(p64) {
+		t.Errorf("PHash should equal NewPHash64, wanted %v, got %v", p64, h64)
+	}
+	h256, _ := PHash(img, 16, 16)
+	p256, _ := NewPHash256(resize.Resize(256, 256, img, resize.Bilinear))
+	for i := range p256 {
+		if h256.hash[i] != p256[i] {
+			t.Errorf("PHash should equal NewPHash256, wanted %v, got %v", p256, h256)
+		}
+	}
+
+	// Distance
+	blurred := resize.Resize(16, 16, img, resize.Bilinear)
+	h1, _ := PHash(img, 4, 4)
+	h2, _ := PHash(blurred, 4, 4)
+	if d, err := h1.Distance(h2); err != nil || d > 4 {
+		t.Errorf("Incorrect PHash distance wanted <= %d got %d (%v)", 4, d, err)
+	}
+	if _, err := h1.Distance(h64); err != ErrHashLength {
+		t.Errorf("Incorrect PHash distance error wanted %v got %v", ErrHashLength, err)
+	}
+
+	if _, err = PHash(nil, 8, 8); err != ErrImageObject {
+		t.Errorf("Incorrect PHash error wanted %v got %v", ErrImageObject, err)
+	}
+}
+
+func TestHashFromDCT(t *testing.T) {
+	// The DC coefficient is excluded from the median
+	coeffs := make([]float64, 64)
+	coeffs[0] = 10000
+	for i := 1; i < 64; i++ {
+		coeffs[i] = float64(i)
+	}
+	// Median of 1..63 is 32, bits are set for 0 and 33..63
+	wanted := Hash64(1<<63 | (1<<31 - 1))
+	if h := hashFromDCT(coeffs); h != wanted {
+		t.Errorf("Incorrect hashFromDCT wanted %064b got %064b", wanted, h)
+	}
+
+	// Coefficients equal to the median are not set
+	for i := range coeffs {
+		coeffs[i] = 1
+	}
+	if h := hashFromDCT(coeffs); h != 0 {
+		t.Errorf("Incorrect hashFromDCT wanted %d got %064b", 0, h)
+	}
+
+	// Hashes of goimagehash.PerceptionHash, which resizes to 64x64 with bilinear
+	// interpolation and thresholds against the median of all 64 coefficients.
+	// Both medians select the same bits while the DC coefficient is above them.
+	hashTests := []struct {
+		filename string
+		hash     Hash64
+	}{
+		{"../assets/a1.jpg", 0xbea4c5c322be8ccc},
+		{"../assets/a2.jpg", 0xc3d83c65c5d3962c},
+		{"../assets/JPEG.jpg", 0x93b3071c583cf4d6},
+	}
+	for _, ht := range hashTests {
+		t.Run(ht.filename, func(t *testing.T) {
+			f, err := os.Open(ht.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			img, err := jpeg.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resized := resize.Resize(64, 64, img, resize.Bilinear)
+			if h := goimagehashPHash(resized); h != ht.hash {
+				t.Errorf("Incorrect goimagehash PerceptionHash wanted 0x%016x got 0x%016x", uint64(ht.hash), uint64(h))
+			}
+			h, err := NewPHash64(resized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h != ht.hash {
+				t.Errorf("Incorrect PHash64 wanted 0x%016x got 0x%016x", uint64(ht.hash), uint64(h))
+			}
+			ph, _ := PHash(img, 8, 8)
+			if ph.hash[0] != uint64(ht.hash) {
+				t.Errorf("Incorrect PHash wanted 0x%016x got 0x%016x", uint64(ht.hash), ph.hash[0])
+			}
+		})
+	}
+}
+
+// goimagehashPHash returns the hash of goimagehash.PerceptionHash of a 64x64 image,
+// with the median of all coefficients including the DC coefficient.
+func goimagehashPHash(img image.Image) (hash Hash64) {
+	pixels := transforms.Rgb2Gray(img)
+	dct := transforms.DCT2D(pixels, 64, 64)
+	flattens := transforms.FlattenPixels(dct, 8, 8)
+	median := transforms.MedianOfPixels(flattens)
+	for idx, p := range flattens {
+		if p > median {
+			hash |= 1 << uint(len(flattens)-idx-1)
+		}
+	}
+	return hash
+}
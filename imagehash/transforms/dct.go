@@ -7,6 +7,7 @@ package transforms
 
 import (
 	"math"
+	"runtime"
 	"sync"
 )
 
@@ -46,33 +47,144 @@ func DCT2D(input [][]float64, w int, h int) [][]float64 {
 	for i := range output {
 		output[i] = make([]float64, w)
 	}
+	DCT2DInto(output, input, w, h)
+	return output
+}
 
-	wg := new(sync.WaitGroup)
+// parallelDCTSize is the minimum w*h size for which DCT2DInto
+// spreads the transform across goroutines. Smaller inputs are transformed serially.
+const parallelDCTSize = 128 * 128
+
+// dctBufferPool is a pool of scratch buffers used by DCT2DInto.
+var dctBufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]float64, 512)
+		return &b
+	},
+}
+
+// getDCTBuffer returns a scratch buffer of length n from dctBufferPool.
+func getDCTBuffer(n int) *[]float64 {
+	b := dctBufferPool.Get().(*[]float64)
+	if cap(*b) < n {
+		*b = make([]float64, n)
+	}
+	*b = (*b)[:n]
+	return b
+}
+
+// DCT2DInto writes the result of DCT2D of src into dst by using the seperable property.
+// dst must have h rows of at least w values and may be the same as src.
+// Scratch buffers are reused from a sync.Pool and inputs smaller than
+// parallelDCTSize are transformed serially.
+func DCT2DInto(dst, src [][]float64, w int, h int) {
 	for i := 0; i < h; i++ {
-		wg.Add(1)
-		go func(i int) {
-			output[i] = DCT1D(input[i])
-			wg.Done()
-		}(i)
+		copy(dst[i][:w], src[i][:w])
+	}
+	if w*h < parallelDCTSize {
+		dct2DRows(dst, w, 0, h)
+		dct2DCols(dst, h, 0, w)
+		return
 	}
 
-	wg.Wait()
-	for i := 0; i < w; i++ {
-		wg.Add(1)
-		in := make([]float64, h)
-		go func(i int) {
-			for j := 0; j < h; j++ {
-				in[j] = output[j][i]
-			}
-			rows := DCT1D(in)
-			for j := 0; j < len(rows); j++ {
-				output[j][i] = rows[j]
+	workers := runtime.GOMAXPROCS(0)
+	wg := new(sync.WaitGroup)
+	parallel := func(n int, fn func(start, end int)) {
+		step := (n + workers - 1) / workers
+		for start := 0; start < n; start += step {
+			end := start + step
+			if end > n {
+				end = n
 			}
-			wg.Done()
-		}(i)
+			wg.Add(1)
+			go func(start, end int) {
+				fn(start, end)
+				wg.Done()
+			}(start, end)
+		}
+		wg.Wait()
+	}
+	parallel(h, func(start, end int) { dct2DRows(dst, w, start, end) })
+	parallel(w, func(start, end int) { dct2DCols(dst, h, start, end) })
+}
+
+// dct2DRows transforms rows [start,end) of pixels in place.
+func dct2DRows(pixels [][]float64, w int, start, end int) {
+	temp := getDCTBuffer(w)
+	for i := start; i < end; i++ {
+		forwardTransform(pixels[i][:w], *temp, w)
+	}
+	dctBufferPool.Put(temp)
+}
+
+// dct2DCols transforms columns [start,end) of pixels in place.
+func dct2DCols(pixels [][]float64, h int, start, end int) {
+	buf := getDCTBuffer(h * 2)
+	col, temp := (*buf)[:h], (*buf)[h:]
+	for i := start; i < end; i++ {
+		for j := 0; j < h; j++ {
+			col[j] = pixels[j][i]
+		}
+		forwardTransform(col, temp, h)
+		for j := 0; j < h; j++ {
+			pixels[j][i] = col[j]
+		}
+	}
+	dctBufferPool.Put(buf)
+}
+
+// IDCT1D function returns result of the inverse of DCT1D.
+// DCT type III, scaled by 2/N so that IDCT1D(DCT1D(x)) returns x.
+// Algorithm by Byeong Gi Lee, 1984.
+func IDCT1D(input []float64) []float64 {
+	temp := make([]float64, len(input))
+	input[0] /= 2
+	inverseTransform(input, temp, len(input))
+	scale := 2 / float64(len(input))
+	for i := range input {
+		input[i] *= scale
+	}
+	return input
+}
+
+func inverseTransform(input, temp []float64, Len int) {
+	if Len == 1 {
+		return
+	}
+
+	halfLen := Len / 2
+
+	temp[0], temp[halfLen] = input[0], input[1]
+	for i := 1; i < halfLen; i++ {
+		temp[i] = input[i*2]
+		temp[i+halfLen] = input[i*2-1] + input[i*2+1]
+	}
+	inverseTransform(temp, input, halfLen)
+	inverseTransform(temp[halfLen:], input, halfLen)
+	for i := 0; i < halfLen; i++ {
+		x, y := temp[i], temp[i+halfLen]/(math.Cos((float64(i)+0.5)*math.Pi/float64(Len))*2)
+		input[i] = x + y
+		input[Len-1-i] = x - y
+	}
+}
+
+// IDCT2D function returns a result of the inverse of DCT2D by using the seperable property.
+func IDCT2D(input [][]float64, w int, h int) [][]float64 {
+	output := make([][]float64, h)
+	for i := range output {
+		output[i] = IDCT1D(input[i][:w])
 	}
 
-	wg.Wait()
+	in := make([]float64, h)
+	for i := 0; i < w; i++ {
+		for j := 0; j < h; j++ {
+			in[j] = output[j][i]
+		}
+		IDCT1D(in)
+		for j := 0; j < h; j++ {
+			output[j][i] = in[j]
+		}
+	}
 	return output
 }
 
@@ -5,6 +5,7 @@
 package transforms
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 )
@@ -74,6 +75,93 @@ func TestDCT2D(t *testing.T) {
 	}
 }
 
+func TestIDCT1D(t *testing.T) {
+	for _, size := range []int{1, 2, 4, 8, 64} {
+		input := make([]float64, size)
+		for i := range input {
+			input[i] = rand.Float64() * 255
+		}
+		out := DCT1D(append([]float64(nil), input...))
+		out = IDCT1D(out)
+		for i := range out {
+			if (out[i]-input[i]) > EPSILON || (input[i]-out[i]) > EPSILON {
+				t.Errorf("IDCT1D(DCT1D(%v)) is expected %v but got %v.", input, input, out)
+				break
+			}
+		}
+	}
+}
+
+func TestIDCT2D(t *testing.T) {
+	for _, size := range [][2]int{{4, 4}, {8, 4}, {64, 64}} {
+		w, h := size[0], size[1]
+		input := make([][]float64, h)
+		arr := make([][]float64, h)
+		for i := 0; i < h; i++ {
+			input[i] = make([]float64, w)
+			for j := 0; j < w; j++ {
+				input[i][j] = rand.Float64() * 255
+			}
+			arr[i] = append([]float64(nil), input[i]...)
+		}
+		out := IDCT2D(DCT2D(arr, w, h), w, h)
+		pass := true
+		for i := 0; i < h; i++ {
+			for j := 0; j < w; j++ {
+				if (out[i][j]-input[i][j]) > EPSILON || (input[i][j]-out[i][j]) > EPSILON {
+					pass = false
+				}
+			}
+		}
+		if !pass {
+			t.Errorf("IDCT2D(DCT2D()) %dx%d did not round-trip", w, h)
+		}
+	}
+}
+
+func TestDCT2DInto(t *testing.T) {
+	for _, size := range []int{4, 64, 256} {
+		src := make([][]float64, size)
+		dst := make([][]float64, size)
+		for i := 0; i < size; i++ {
+			src[i] = make([]float64, size)
+			dst[i] = make([]float64, size)
+			for j := 0; j < size; j++ {
+				src[i][j] = rand.Float64() * 255
+			}
+		}
+		DCT2DInto(dst, src, size, size)
+
+		// Reference: DCT1D on rows then columns
+		ref := make([][]float64, size)
+		for i := 0; i < size; i++ {
+			ref[i] = DCT1D(append([]float64(nil), src[i]...))
+		}
+		col := make([]float64, size)
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				col[j] = ref[j][i]
+			}
+			DCT1D(col)
+			for j := 0; j < size; j++ {
+				ref[j][i] = col[j]
+			}
+		}
+
+		pass := true
+		for i := 0; i < size; i++ {
+			for j := 0; j < size; j++ {
+				if math.Abs(dst[i][j]-ref[i][j]) > 1e-6 {
+					pass = false
+				}
+			}
+		}
+		if !pass {
+			t.Errorf("DCT2DInto %dx%d does not match DCT1D reference", size, size)
+		}
+	}
+}
+
 func TestFastDCT2D(t *testing.T) {
 	size := 64
 	arr := make([]float64, size*size)
@@ -140,3 +228,29 @@ func BenchmarkForwardDCT64(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkDCT2D(b *testing.B) {
+	size := 64
+	src := make([][]float64, size)
+	dst := make([][]float64, size)
+	for i := 0; i < size; i++ {
+		src[i] = make([]float64, size)
+		dst[i] = make([]float64, size)
+		for j := 0; j < size; j++ {
+			src[i][j] = rand.Float64() * 255
+		}
+	}
+
+	b.Run("DCT2D", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DCT2D(src, size, size)
+		}
+	})
+	b.Run("DCT2DInto", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			DCT2DInto(dst, src, size, size)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package transforms
+
+import (
+	"image"
+
+	"github.com/nfnt/resize"
+)
+
+// Grayscale resizes img to size x size and returns its luminance values
+// in row-major order. Images that are already size x size are not resized.
+//
+// *image.YCbCr, *image.Gray, *image.Paletted and *image.RGBA images are read
+// directly from their pixel buffers, other images use the image.Image interface.
+func Grayscale(img image.Image, size int) []float64 {
+	if img == nil || size < 1 {
+		return nil
+	}
+	if s := img.Bounds().Size(); s.X != size || s.Y != size {
+		img = resize.Resize(uint(size), uint(size), img, resize.Bilinear)
+	}
+	pixels := make([]float64, size*size)
+	grayscale(img, pixels, size)
+	return pixels
+}
+
+// grayscale writes the luminance values of the top-left s x s block
+// of img to pixels.
+func grayscale(img image.Image, pixels []float64, s int) {
+	switch c := img.(type) {
+	case *image.YCbCr:
+		ycbcr2Gray(c, pixels, s)
+	case *image.Gray:
+		gray2Gray(c, pixels, s)
+	case *image.Paletted:
+		paletted2Gray(c, pixels, s)
+	case *image.RGBA:
+		rgba2Gray(c, pixels, s)
+	default:
+		image2Gray(c, pixels, s)
+	}
+}
+
+// ycbcr2Gray converts the Y, Cb and Cr planes without creating an intermediate RGBA image.
+func ycbcr2Gray(img *image.YCbCr, pixels []float64, s int) {
+	min := img.Rect.Min
+	for y := 0; y < s; y++ {
+		for x := 0; x < s; x++ {
+			yi := img.YOffset(min.X+x, min.Y+y)
+			ci := img.COffset(min.X+x, min.Y+y)
+			r, g, b := ycbcrToRGB(img.Y[yi], img.Cb[ci], img.Cr[ci])
+			pixels[(y*s)+x] = pixel2Gray(r, g, b, 0)
+		}
+	}
+}
+
+// ycbcrToRGB returns the 16bit RGB values of a YCbCr pixel.
+// Equivalent to color.YCbCr.RGBA
+func ycbcrToRGB(yy, cb, cr uint8) (uint32, uint32, uint32) {
+	yy1 := int32(yy) * 0x10101
+	cb1 := int32(cb) - 128
+	cr1 := int32(cr) - 128
+
+	r := clampRGB(yy1 + 91881*cr1)
+	g := clampRGB(yy1 - 22554*cb1 - 46802*cr1)
+	b := clampRGB(yy1 + 116130*cb1)
+	return r, g, b
+}
+
+func clampRGB(v int32) uint32 {
+	if uint32(v)&0xff000000 == 0 {
+		return uint32(v >> 8)
+	}
+	return uint32(^(v >> 31) & 0xffff)
+}
+
+func gray2Gray(img *image.Gray, pixels []float64, s int) {
+	min := img.Rect.Min
+	for y := 0; y < s; y++ {
+		i := img.PixOffset(min.X, min.Y+y)
+		for x := 0; x < s; x++ {
+			v := uint32(img.Pix[i+x]) * 0x101
+			pixels[(y*s)+x] = pixel2Gray(v, v, v, 0)
+		}
+	}
+}
+
+// paletted2Gray converts each palette color once and looks up the pixel indexes.
+func paletted2Gray(img *image.Paletted, pixels []float64, s int) {
+	lum := make([]float64, len(img.Palette))
+	for i, c := range img.Palette {
+		lum[i] = pixel2Gray(c.RGBA())
+	}
+	min := img.Rect.Min
+	for y := 0; y < s; y++ {
+		i := img.PixOffset(min.X, min.Y+y)
+		for x := 0; x < s; x++ {
+			if idx := int(img.Pix[i+x]); idx < len(lum) {
+				pixels[(y*s)+x] = lum[idx]
+			} else {
+				pixels[(y*s)+x] = 0
+			}
+		}
+	}
+}
+
+func rgba2Gray(img *image.RGBA, pixels []float64, s int) {
+	min := img.Rect.Min
+	for y := 0; y < s; y++ {
+		i := img.PixOffset(min.X, min.Y+y)
+		for x := 0; x < s; x++ {
+			p := img.Pix[i+x*4 : i+x*4+3 : i+x*4+3]
+			pixels[(y*s)+x] = pixel2Gray(uint32(p[0])*0x101, uint32(p[1])*0x101, uint32(p[2])*0x101, 0)
+		}
+	}
+}
+
+func image2Gray(img image.Image, pixels []float64, s int) {
+	min := img.Bounds().Min
+	for y := 0; y < s; y++ {
+		for x := 0; x < s; x++ {
+			pixels[(y*s)+x] = pixel2Gray(img.At(min.X+x, min.Y+y).RGBA())
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package transforms
+
+import (
+	"image"
+	"image/color/palette"
+	"image/jpeg"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestGrayscale(t *testing.T) {
+	f, err := os.Open("../../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ycc, ok := img.(*image.YCbCr)
+	if !ok {
+		t.Fatalf("Incorrect decoded image type wanted %T got %T", &image.YCbCr{}, img)
+	}
+
+	// Build test images with the same content
+	rect := image.Rect(0, 0, 64, 64)
+	gray := image.NewGray(rect)
+	rgba := image.NewRGBA(rect)
+	nrgba := image.NewNRGBA(rect)
+	paletted := image.NewPaletted(rect, palette.Plan9)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			c := ycc.At(x, y)
+			gray.Set(x, y, c)
+			rgba.Set(x, y, c)
+			nrgba.Set(x, y, c)
+			paletted.Set(x, y, c)
+		}
+	}
+
+	grayTests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"YCbCr", ycc.SubImage(rect)},
+		{"YCbCrOffset", ycc.SubImage(image.Rect(100, 50, 164, 114))},
+		{"Gray", gray},
+		{"RGBA", rgba},
+		{"NRGBA", nrgba},
+		{"Paletted", paletted},
+		{"GrayOffset", gray.SubImage(image.Rect(8, 8, 40, 40))},
+	}
+	for _, gt := range grayTests {
+		t.Run(gt.name, func(t *testing.T) {
+			size := gt.img.Bounds().Dx()
+			pixels := Grayscale(gt.img, size)
+			if len(pixels) != size*size {
+				t.Fatalf("Incorrect Grayscale length wanted %d got %d", size*size, len(pixels))
+			}
+			min := gt.img.Bounds().Min
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					wanted := pixel2Gray(gt.img.At(min.X+x, min.Y+y).RGBA())
+					if got := pixels[y*size+x]; got != wanted {
+						t.Fatalf("Incorrect Grayscale pixel (%d,%d) wanted %0.6f got %0.6f", x, y, wanted, got)
+					}
+				}
+			}
+		})
+	}
+
+	// Resize
+	pixels := Grayscale(img, 32)
+	if len(pixels) != 32*32 {
+		t.Fatalf("Incorrect Grayscale length wanted %d got %d", 32*32, len(pixels))
+	}
+	for i, p := range pixels {
+		if p < 0 || p > 255 || math.IsNaN(p) {
+			t.Fatalf("Incorrect Grayscale luminance at %d got %0.6f", i, p)
+		}
+	}
+
+	if Grayscale(nil, 8) != nil || Grayscale(img, 0) != nil {
+		t.Errorf("Grayscale should return nil for a nil image or an empty size")
+	}
+}
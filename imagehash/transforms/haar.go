@@ -0,0 +1,57 @@
+package transforms
+
+import "math"
+
+// HaarWavelet1D performs a single level of the orthonormal Haar wavelet
+// transform on input. The approximation coefficients are written to the first
+// half of input and the detail coefficients to the second half.
+// The length of input must be even.
+func HaarWavelet1D(input []float64) []float64 {
+	temp := make([]float64, len(input))
+	haarForward(input, temp, len(input))
+	return input
+}
+
+// haarForward performs a single level Haar transform on input[:n] using temp as scratch.
+func haarForward(input, temp []float64, n int) {
+	half := n / 2
+	for i := 0; i < half; i++ {
+		a, b := input[2*i], input[2*i+1]
+		temp[i] = (a + b) / math.Sqrt2
+		temp[i+half] = (a - b) / math.Sqrt2
+	}
+	copy(input[:n], temp[:n])
+}
+
+// HaarWavelet2D performs a multi-level 2D Haar wavelet transform on a flattened
+// row-major input of w*h pixels by using the separable property. Each level
+// transforms the rows and then the columns of the remaining low frequency (LL) block.
+// After the transform the top-left (w>>levels)x(h>>levels) block holds the low frequency
+// approximation coefficients. w and h must be divisible by 2^levels.
+func HaarWavelet2D(input []float64, w, h, levels int) {
+	if len(input) != w*h {
+		panic("Incorrect wavelet transform size")
+	}
+	n := w
+	if h > n {
+		n = h
+	}
+	temp := make([]float64, n)
+	col := make([]float64, n)
+
+	for l := 0; l < levels; l++ {
+		lw, lh := w>>l, h>>l
+		for i := 0; i < lh; i++ { // rows
+			haarForward(input[i*w:i*w+lw], temp, lw)
+		}
+		for i := 0; i < lw; i++ { // columns
+			for j := 0; j < lh; j++ {
+				col[j] = input[j*w+i]
+			}
+			haarForward(col, temp, lh)
+			for j := 0; j < lh; j++ {
+				input[j*w+i] = col[j]
+			}
+		}
+	}
+}
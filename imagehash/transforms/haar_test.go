@@ -0,0 +1,55 @@
+package transforms
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaarWavelet1D(t *testing.T) {
+	for _, tt := range []struct {
+		input  []float64
+		output []float64
+	}{
+		{[]float64{1.0, 1.0, 1.0, 1.0}, []float64{math.Sqrt2, math.Sqrt2, 0, 0}},
+		{[]float64{1.0, 2.0, 3.0, 4.0}, []float64{3 / math.Sqrt2, 7 / math.Sqrt2, -1 / math.Sqrt2, -1 / math.Sqrt2}},
+	} {
+		out := HaarWavelet1D(tt.input)
+		for i := range out {
+			if math.Abs(out[i]-tt.output[i]) > EPSILON {
+				t.Errorf("HaarWavelet1D is expected %v but got %v.", tt.output, out)
+				break
+			}
+		}
+	}
+}
+
+func TestHaarWavelet2D(t *testing.T) {
+	for _, tt := range []struct {
+		input  []float64
+		output []float64
+		w      int
+		h      int
+		levels int
+	}{
+		{[]float64{1, 2, 3, 4}, []float64{5, -1, -2, 0}, 2, 2, 1},
+		{[]float64{
+			1, 2, 3, 4,
+			5, 6, 7, 8,
+			9, 10, 11, 12,
+			13, 14, 15, 16},
+			[]float64{
+				34, -4, -1, -1,
+				-16, 0, -1, -1,
+				-4, -4, 0, 0,
+				-4, -4, 0, 0},
+			4, 4, 2},
+	} {
+		HaarWavelet2D(tt.input, tt.w, tt.h, tt.levels)
+		for i := range tt.input {
+			if math.Abs(tt.input[i]-tt.output[i]) > EPSILON {
+				t.Errorf("HaarWavelet2D is expected %v but got %v.", tt.output, tt.input)
+				break
+			}
+		}
+	}
+}
@@ -9,21 +9,15 @@ import (
 	"math"
 )
 
-// Rgb2GrayFast function converts RGB to a gray scale array.
+// Rgb2GrayFast function converts a square image to a gray scale array.
+// It uses the same conversion as Grayscale without resizing.
 func Rgb2GrayFast(colorImg image.Image, pixels *[]float64) {
 	bounds := colorImg.Bounds()
 	w, h := bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y
 	if w != h {
 		return
 	}
-	switch c := colorImg.(type) {
-	case *image.YCbCr:
-		PixelYCnCRGray(c, *pixels)
-	case *image.RGBA:
-		rgb2GrayRGBA(c, *pixels, w)
-	default:
-		rgb2GrayDefault(c, *pixels, w)
-	}
+	grayscale(colorImg, *pixels, w)
 }
 
 // pixel2Gray converts a pixel to grayscale value base on luminosity
@@ -31,15 +25,6 @@ func pixel2Gray(r, g, b, a uint32) float64 {
 	return 0.299*float64(r/257) + 0.587*float64(g/257) + 0.114*float64(b/256)
 }
 
-// rgb2GrayDefault uses the image.Image interface
-func rgb2GrayDefault(colorImg image.Image, pixels []float64, s int) {
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			pixels[(i*s)+j] = pixel2Gray(colorImg.At(j, i).RGBA())
-		}
-	}
-}
-
 // YCbCR2Gray uses *image.YCbCr which is signifiantly faster than the image.Image interface.
 func YCbCR2Gray(colorImg *image.YCbCr, pixels []float64) {
 	s := colorImg.Rect.Dx()
@@ -103,15 +88,6 @@ func OldYCbCR2Gray(colorImg *image.YCbCr, pixels []float64, s int) {
 	}
 }
 
-// rgb2GrayYCbCR uses *image.RGBA which is signifiantly faster than the image.Image interface.
-func rgb2GrayRGBA(colorImg *image.RGBA, pixels []float64, s int) {
-	for i := 0; i < s; i++ {
-		for j := 0; j < s; j++ {
-			pixels[(i*s)+j] = pixel2Gray(colorImg.At(j, i).RGBA())
-		}
-	}
-}
-
 // Rgb2Gray function converts RGB to a gray scale array.
 func Rgb2Gray(colorImg image.Image) [][]float64 {
 	bounds := colorImg.Bounds()
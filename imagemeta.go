@@ -1,5 +1,7 @@
 // Package imagemeta provides functions for parsing and extracting Metadata from Images.
-// Different image types such as JPEG, Camera Raw, DNG, TIFF, HEIF, and AVIF.
+// Different image types such as JPEG, Camera Raw, DNG, ORF, PEF, SRW, X3F, TIFF, HEIF, AVIF, JPEG XL, JPEG 2000, PSD, OpenEXR, WebP, PNG and GIF.
+// QuickTime (MOV) and MPEG-4 (MP4) movies are read for their metadata.
+// The dimensions of BMP and TGA images are read from their headers, and the sizes of the images of ICO and CUR files from their icon directory.
 package imagemeta
 
 import (
@@ -7,13 +9,29 @@ import (
 	"errors"
 	"io"
 
+	"github.com/evanoberholster/imagemeta/bmp"
+	"github.com/evanoberholster/imagemeta/cr2"
 	"github.com/evanoberholster/imagemeta/cr3"
 	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exr"
+	"github.com/evanoberholster/imagemeta/gif"
 	"github.com/evanoberholster/imagemeta/heic"
+	"github.com/evanoberholster/imagemeta/ico"
 	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/jp2"
 	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/jxl"
 	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/mov"
+	"github.com/evanoberholster/imagemeta/orf"
+	"github.com/evanoberholster/imagemeta/pef"
+	"github.com/evanoberholster/imagemeta/png"
+	"github.com/evanoberholster/imagemeta/psd"
+	"github.com/evanoberholster/imagemeta/srw"
+	"github.com/evanoberholster/imagemeta/tga"
 	"github.com/evanoberholster/imagemeta/tiff"
+	"github.com/evanoberholster/imagemeta/webp"
+	"github.com/evanoberholster/imagemeta/x3f"
 	"github.com/evanoberholster/imagemeta/xmp"
 )
 
@@ -24,6 +42,7 @@ var (
 	ErrNoXmpDecodeFn        = errors.New("error no Xmp Decode Func set")
 	ErrImageTypeNotFound    = imagetype.ErrImageTypeNotFound
 	ErrMetadataNotSupported = errors.New("error metadata reading not supported for this imagetype")
+	ErrNoLivePhotoID        = errors.New("error no Live Photo content identifier")
 )
 
 // ImageMeta interface for Image Metadata
@@ -37,8 +56,16 @@ type ImageMeta interface {
 
 // Parse meta.Reader for Image Metadata returns ImageMeta corresponding
 // to identified image type.
+//
+// TGA images do not have a signature, a TGA header is only read when
+// no other image type was identified.
 func Parse(r meta.Reader) (ImageMeta, error) {
 	t, err := imagetype.ReadAt(r)
+	if err == ErrImageTypeNotFound || err == io.EOF {
+		if m, tgaErr := tga.ScanTGA(r); tgaErr == nil {
+			return m, nil
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -46,14 +73,87 @@ func Parse(r meta.Reader) (ImageMeta, error) {
 
 	case imagetype.ImageJPEG:
 		return jpeg.ScanJPEG(r, nil, nil)
+	case imagetype.ImageCR2:
+		return cr2.Parse(r)
 	case imagetype.ImageCR3:
 		return cr3.Parse(r)
-	case imagetype.ImageTiff, imagetype.ImageCR2, imagetype.ImageARW, imagetype.ImageHEIF, imagetype.ImageNEF, imagetype.ImagePanaRAW:
-		return tiff.Parse(r, t)
+	case imagetype.ImageORF:
+		return orf.Parse(r)
+	case imagetype.ImageWebP:
+		return webp.ScanWebP(r, nil, nil)
+	case imagetype.ImagePNG:
+		return png.ScanPNG(r, nil, nil)
+	case imagetype.ImageGIF:
+		return gif.ScanGIF(r, nil)
+	case imagetype.ImageBMP:
+		return bmp.ScanBMP(r)
+	case imagetype.ImageICO:
+		return ico.ScanICO(r)
+	case imagetype.ImageHEIF, imagetype.ImageAVIF:
+		return heic.Parse(r, t)
+	case imagetype.ImagePEF:
+		return pef.Parse(r)
+	case imagetype.ImageSRW:
+		return srw.Parse(r)
+	case imagetype.ImageX3F:
+		return x3f.Parse(r)
+	case imagetype.ImageMOV, imagetype.ImageMP4:
+		return mov.Parse(r)
+	case imagetype.ImageEXR:
+		return exr.ScanEXR(r)
+	case imagetype.ImagePSD:
+		return psd.ScanPSD(r, nil, nil)
+	case imagetype.ImageJP2K:
+		return jp2.ScanJP2(r, nil, nil)
+	case imagetype.ImageJXL:
+		return jxl.ScanJXL(r, nil, nil)
+	case imagetype.ImageTiff, imagetype.ImageDNG, imagetype.ImageARW, imagetype.ImageNEF, imagetype.ImagePanaRAW:
+		m, err := tiff.Parse(r, t)
+		if err != nil {
+			return m, err
+		}
+		// PEF and SRW have a Tiff Header and are identified by their Makernote
+		switch m.ImageType() {
+		case imagetype.ImagePEF:
+			return pef.Parse(r)
+		case imagetype.ImageSRW:
+			return srw.Parse(r)
+		}
+		return m, nil
 	}
 	return nil, nil
 }
 
+// LivePhotoID returns the content identifier that pairs the image and the movie
+// of an Apple Live Photo. The identifier is read from the Apple Makernote of a
+// HEIC or JPEG image, or from the QuickTime metadata of a MOV movie.
+//
+// Returns ErrNoLivePhotoID if r does not have a content identifier.
+func LivePhotoID(r meta.Reader) (string, error) {
+	m, err := Parse(r)
+	if err != nil {
+		return "", err
+	}
+	if m == nil {
+		return "", ErrMetadataNotSupported
+	}
+	if mv, ok := m.(mov.Metadata); ok {
+		if mv.ContentIdentifier == "" {
+			return "", ErrNoLivePhotoID
+		}
+		return mv.ContentIdentifier, nil
+	}
+	e, err := m.Exif()
+	if err != nil {
+		return "", err
+	}
+	id, err := e.AppleContentIdentifier()
+	if err != nil || id == "" {
+		return "", ErrNoLivePhotoID
+	}
+	return id, nil
+}
+
 // Metadata from an Image. The ExifDecodeFn and XmpDecodeFn
 // are responsible for decoding their respective data.
 type Metadata struct {
@@ -85,8 +185,7 @@ func NewMetadata(r meta.Reader, xmpFn meta.DecodeFn, exifFn meta.DecodeFn) (m *M
 func (m *Metadata) parse(br *bufio.Reader) (err error) {
 	switch m.It {
 	case imagetype.ImageWebP:
-		err = ErrMetadataNotSupported
-		return
+		return m.parseWebP()
 	case imagetype.ImageNEF:
 		return m.parseTiff(br)
 	case imagetype.ImageCR2:
@@ -95,7 +194,7 @@ func (m *Metadata) parse(br *bufio.Reader) (err error) {
 		return m.parseHeic(br)
 	case imagetype.ImageAVIF:
 		return m.parseHeic(br)
-	case imagetype.ImagePNG, imagetype.ImageBMP, imagetype.ImageGIF:
+	case imagetype.ImagePNG, imagetype.ImageBMP, imagetype.ImageGIF, imagetype.ImageICO:
 		err = ErrMetadataNotSupported
 		return
 	case imagetype.ImageCRW:
@@ -134,6 +233,35 @@ func (m *Metadata) parseHeic(br *bufio.Reader) (err error) {
 	return err
 }
 
+// parseWebP uses the 'webp' package to identify the metadata and
+// the 'exif' and 'xmp' packages to parse the metadata.
+//
+// Will use the custom decode functions: XmpDecodeFn and
+// ExifDecodeFn if they are not nil.
+func (m *Metadata) parseWebP() (err error) {
+	if _, err = m.r.Seek(0, io.SeekStart); err != nil {
+		return
+	}
+	var exifFn func(r io.Reader, header meta.ExifHeader) error
+	if m.ExifFn != nil {
+		exifFn = func(r io.Reader, header meta.ExifHeader) error {
+			m.ExifHeader = header
+			return m.ExifFn(m.r, m.Metadata)
+		}
+	}
+	var xmpFn func(r io.Reader, header meta.XmpHeader) error
+	if m.XmpFn != nil {
+		xmpFn = func(r io.Reader, header meta.XmpHeader) error {
+			m.XmpHeader = header
+			return m.XmpFn(r, m.Metadata)
+		}
+	}
+	wm, err := webp.ScanWebP(m.r, exifFn, xmpFn)
+	m.ExifHeader, m.XmpHeader = wm.ExifHeader, wm.XmpHeader
+	m.Dim = wm.Dimensions()
+	return err
+}
+
 // parseTiff uses the 'tiff' package to identify the metadata and
 // the 'exif' and 'xmp' packages to parse the metadata.
 //
@@ -0,0 +1,416 @@
+package imagemeta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	stdpng "image/png"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/cr2"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/ico"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/pef"
+	"github.com/evanoberholster/imagemeta/png"
+	"github.com/evanoberholster/imagemeta/srw"
+	"github.com/evanoberholster/imagemeta/webp"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePNG(t *testing.T) {
+	var src bytes.Buffer
+	if err := stdpng.Encode(&src, image.NewGray(image.Rect(0, 0, 20, 10))); err != nil {
+		t.Fatal(err)
+	}
+	b := exif.NewBuilder(nil)
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Model, "Canon EOS 6D"); err != nil {
+		t.Fatal(err)
+	}
+	exifData, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, err := xmp.Marshal(xmp.XMP{DC: xmp.DublinCore{Creator: []string{"Evan Oberholster"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = png.Rewrite(bytes.NewReader(src.Bytes()), &buf, png.RewriteOptions{Exif: exifData, XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Parse(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImagePNG, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(20, 10), m.Dimensions())
+	e, err := m.Exif()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Canon EOS 6D", e.CameraModel())
+	}
+	x, err := m.Xmp()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"Evan Oberholster"}, x.DC.Creator)
+	}
+
+	// A PNG without Exif
+	m, err = Parse(bytes.NewReader(src.Bytes()))
+	assert.ErrorIs(t, err, ErrNoExif)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, meta.NewDimensions(20, 10), m.Dimensions())
+	}
+}
+
+func TestNewMetadataWebP(t *testing.T) {
+	// A lossless 64x64 WebP
+	src := []byte{'R', 'I', 'F', 'F', 26, 0, 0, 0, 'W', 'E', 'B', 'P', 'V', 'P', '8', 'L', 5, 0, 0, 0, 0x2f, 0x3f, 0xc0, 0x0f, 0x00, 0}
+	b := exif.NewBuilder(nil)
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Model, "Canon EOS 6D"); err != nil {
+		t.Fatal(err)
+	}
+	exifData, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, err := xmp.Marshal(xmp.XMP{DC: xmp.DublinCore{Creator: []string{"Evan Oberholster"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err = webp.Rewrite(bytes.NewReader(src), &buf, webp.RewriteOptions{Exif: exifData, XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+
+	var e *exif.Data
+	var x xmp.XMP
+	m, err := NewMetadata(bytes.NewReader(buf.Bytes()), func(r io.Reader, m *meta.Metadata) (err error) {
+		x, err = xmp.ParseXmp(r)
+		return err
+	}, func(r io.Reader, m *meta.Metadata) (err error) {
+		e, err = exif.ParseExif(r.(io.ReaderAt), m.ExifHeader)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageWebP, m.It)
+	assert.Equal(t, meta.NewDimensions(64, 64), m.Dim)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "Canon EOS 6D", e.CameraModel())
+	}
+	assert.Equal(t, []string{"Evan Oberholster"}, x.DC.Creator)
+
+	// A WebP without Exif
+	m, err = NewMetadata(bytes.NewReader(src), nil, nil)
+	assert.ErrorIs(t, err, ErrNoExif)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, meta.NewDimensions(64, 64), m.Dim)
+	}
+}
+
+func TestParseHEIF(t *testing.T) {
+	f, err := os.Open("testImages/Heic.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageHEIF, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(3648, 5472), m.Dimensions())
+	e, err := m.Exif()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Canon EOS 6D", e.CameraModel())
+	}
+
+	// An AVIF without Exif
+	f2, err := os.Open("testImages/AVIF2.avif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f2.Close()
+	m, err = Parse(f2)
+	assert.ErrorIs(t, err, ErrNoExif)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, imagetype.ImageAVIF, m.ImageType())
+		assert.Equal(t, meta.NewDimensions(2000, 1333), m.Dimensions())
+	}
+
+	// An AVIF with a corrupt 'iloc' box size, that is followed by a box of size 0
+	buf, err := os.ReadFile("testImages/AVIF.avif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(buf, []byte("iloc"))
+	binary.BigEndian.PutUint32(buf[i-4:], 38)
+	_, err = Parse(bytes.NewReader(buf))
+	assert.Error(t, err)
+}
+
+func TestParseGIF(t *testing.T) {
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageGIF, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(1, 1), m.Dimensions())
+	_, err = m.Exif()
+	assert.ErrorIs(t, err, ErrNoExif)
+}
+
+func TestParseBMPAndTGA(t *testing.T) {
+	// BMP with a BITMAPINFOHEADER
+	b := make([]byte, 54)
+	b[0], b[1] = 'B', 'M'
+	b[14], b[18], b[22], b[28] = 40, 3, 2, 24
+	m, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageBMP, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(3, 2), m.Dimensions())
+
+	// 1x1 true color TGA is shorter than the imagetype search header
+	b = make([]byte, 21)
+	b[2], b[12], b[14], b[16] = 2, 1, 1, 24
+	m, err = Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageTGA, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(1, 1), m.Dimensions())
+	_, err = m.Exif()
+	assert.ErrorIs(t, err, ErrNoExif)
+
+	// Not a TGA
+	f, err := os.Open("testImages/Unknown.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = Parse(f)
+	assert.ErrorIs(t, err, ErrImageTypeNotFound)
+}
+
+func TestParseICO(t *testing.T) {
+	// ICO with a 16x16 and a 32x32 BMP image
+	b := make([]byte, 6+2*16+2*40)
+	b[2], b[4] = 1, 2
+	for i, size := range []byte{16, 32} {
+		e := b[6+i*16:]
+		e[0], e[1], e[8], e[12] = size, size, 40, byte(38+i*40)
+		dib := b[38+i*40:]
+		dib[0], dib[4], dib[8], dib[14] = 40, size, size*2, 32
+	}
+	m, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageICO, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(32, 32), m.Dimensions())
+	if i, ok := m.(ico.Metadata); assert.True(t, ok) {
+		assert.Len(t, i.Entries(), 2)
+		assert.True(t, i.HasSize(16, 16))
+	}
+}
+
+func TestParseCR2(t *testing.T) {
+	f, err := os.Open("testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageCR2, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(5616, 3744), m.Dimensions())
+	_, ok := m.(cr2.Metadata)
+	assert.True(t, ok)
+}
+
+func TestParsePEF(t *testing.T) {
+	b := exif.NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("PENTAX", "PENTAX K-3"))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 6080))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 4064))
+	assert.NoError(t, b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, []byte("AOC\x00MM\x00\x00\x00\x00\x00\x00")))
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A PEF has a Tiff Header
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImagePEF, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(6080, 4064), m.Dimensions())
+	_, ok := m.(pef.Metadata)
+	assert.True(t, ok)
+}
+
+func TestParseSRW(t *testing.T) {
+	b := exif.NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("SAMSUNG", "NX500"))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 6496))
+	assert.NoError(t, b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 4336))
+	// Samsung Type2 Makernote with the MakerNoteVersion tag
+	assert.NoError(t, b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, []byte{0, 1, 0, 1, 0, 7, 0, 0, 0, 4, '0', '1', '0', '0', 0, 0, 0, 0}))
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A SRW has a Tiff Header
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageSRW, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(6496, 4336), m.Dimensions())
+	_, ok := m.(srw.Metadata)
+	assert.True(t, ok)
+}
+
+func TestParseJXL(t *testing.T) {
+	// Bare codestream with a 64x64 size header
+	buf := append([]byte{0xFF, 0x0A, 0x4F, 0x00}, make([]byte, 60)...)
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageJXL, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(64, 64), m.Dimensions())
+}
+
+func TestParseMOV(t *testing.T) {
+	// QuickTime movie with a 640x480 track header
+	tkhd := make([]byte, 92)
+	copy(tkhd, []byte{0, 0, 0, 92, 't', 'k', 'h', 'd'})
+	copy(tkhd[84:], []byte{0x02, 0x80, 0, 0, 0x01, 0xE0, 0, 0})
+	buf := append([]byte{0, 0, 0, 108, 'm', 'o', 'o', 'v', 0, 0, 0, 100, 't', 'r', 'a', 'k'}, tkhd...)
+	buf = append(buf, make([]byte, 16)...)
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageMOV, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(640, 480), m.Dimensions())
+}
+
+func TestParseMP4(t *testing.T) {
+	// MP4 movie with a 1280x720 track header
+	tkhd := make([]byte, 92)
+	copy(tkhd, []byte{0, 0, 0, 92, 't', 'k', 'h', 'd'})
+	copy(tkhd[84:], []byte{0x05, 0x00, 0, 0, 0x02, 0xD0, 0, 0})
+	buf := append([]byte{0, 0, 0, 16, 'f', 't', 'y', 'p', 'm', 'p', '4', '2', 0, 0, 0, 0}, 0, 0, 0, 108, 'm', 'o', 'o', 'v', 0, 0, 0, 100, 't', 'r', 'a', 'k')
+	buf = append(buf, tkhd...)
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageMP4, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(1280, 720), m.Dimensions())
+}
+
+func TestParseEXR(t *testing.T) {
+	// OpenEXR header with a 640x480 dataWindow
+	buf := append([]byte{0x76, 0x2F, 0x31, 0x01, 0x02, 0x00, 0x00, 0x00}, "dataWindow\x00box2i\x00"...)
+	buf = append(buf, 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x7F, 0x02, 0, 0, 0xDF, 0x01, 0, 0, 0)
+	buf = append(buf, make([]byte, 16)...)
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageEXR, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(640, 480), m.Dimensions())
+}
+
+func TestParsePSD(t *testing.T) {
+	// PSD header with a 640x480 RGB image and empty sections
+	buf := make([]byte, 64)
+	copy(buf, []byte{'8', 'B', 'P', 'S', 0x00, 0x01, 0, 0, 0, 0, 0, 0, 0x00, 0x03, 0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, 0x02, 0x80, 0x00, 0x08, 0x00, 0x03})
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImagePSD, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(640, 480), m.Dimensions())
+}
+
+func TestParseJP2(t *testing.T) {
+	// Bare codestream with a 640x480 SIZ marker segment
+	buf := make([]byte, 64)
+	copy(buf, []byte{0xFF, 0x4F, 0xFF, 0x51, 0x00, 0x29, 0x00, 0x00, 0x00, 0x00, 0x02, 0x80, 0x00, 0x00, 0x01, 0xE0})
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, imagetype.ImageJP2K, m.ImageType())
+	assert.Equal(t, meta.NewDimensions(640, 480), m.Dimensions())
+}
+
+func TestLivePhotoID(t *testing.T) {
+	const id = "6A7B6B05-8E6B-4F6A-9C1B-2A3C4D5E6F70"
+	newBox := func(typ string, data ...[]byte) []byte {
+		buf := append([]byte{0, 0, 0, 0}, typ...)
+		for _, d := range data {
+			buf = append(buf, d...)
+		}
+		binary.BigEndian.PutUint32(buf, uint32(len(buf)))
+		return buf
+	}
+
+	// JPEG with an Apple Makernote
+	mkNote := append([]byte("Apple iOS\x00\x00\x01MM"), 0, 1, 0, 0x11, 0, 2, 0, 0, 0, byte(len(id)+1), 0, 0, 0, 32, 0, 0, 0, 0)
+	mkNote = append(append(mkNote, id...), 0)
+	b := exif.NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("Apple", "iPhone 12"))
+	assert.NoError(t, b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, mkNote))
+	tiff, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app1 := append([]byte{0xFF, 0xE1, byte((len(tiff) + 8) >> 8), byte(len(tiff) + 8)}, "Exif\x00\x00"...)
+	jpg := append(append([]byte{0xFF, 0xD8}, app1...), tiff...)
+	// 16x16 SOF0 segment followed by the SOS segment
+	jpg = append(jpg, 0xFF, 0xC0, 0, 17, 8, 0, 16, 0, 16, 3, 1, 0x11, 0, 2, 0x11, 1, 3, 0x11, 1, 0xFF, 0xDA)
+	jpg = append(jpg, make([]byte, 16)...)
+	contentID, err := LivePhotoID(bytes.NewReader(jpg))
+	assert.NoError(t, err)
+	assert.Equal(t, id, contentID)
+
+	// QuickTime movie with the content identifier key
+	hdlr := newBox("hdlr", make([]byte, 8), []byte("mdta"), make([]byte, 13))
+	keys := newBox("keys", []byte{0, 0, 0, 0, 0, 0, 0, 1}, newBox("mdta", []byte("com.apple.quicktime.content.identifier")))
+	ilst := newBox("ilst", newBox("\x00\x00\x00\x01", newBox("data", []byte{0, 0, 0, 1, 0, 0, 0, 0}, []byte(id))))
+	movie := newBox("moov", newBox("meta", hdlr, keys, ilst))
+	contentID, err = LivePhotoID(bytes.NewReader(movie))
+	assert.NoError(t, err)
+	assert.Equal(t, id, contentID)
+
+	// Image without an Apple Makernote
+	b = exif.NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("Canon", "Canon EOS 6D"))
+	if tiff, err = b.Encode(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LivePhotoID(bytes.NewReader(tiff))
+	assert.ErrorIs(t, err, ErrNoLivePhotoID)
+}
@@ -34,7 +34,7 @@ var (
 		{".RW2", "4.RW2", "image/x-panasonic-raw"},
 		{".XMP", "test.xmp", "application/rdf+xml"},
 		{".PSD", "0.psd", "image/vnd.adobe.photoshop"},
-		{".JP2/JPEG2000", "0.jp2", "image/jpeg"},
+		{".JP2/JPEG2000", "0.jp2", "image/jp2"},
 		{".BMP", "0.bmp", "image/bmp"},
 	}
 )
@@ -11,18 +11,18 @@ var (
 	ErrDataLength = errors.New("error the data is not long enough")
 
 	// ImageType stringer Index
-	_ImageTypeIndex = [...]uint{0, 24, 34, 43, 52, 61, 71, 81, 90, 100, 117, 134, 155, 171, 188, 205, 222, 239, 264, 283, 293, 316, 325, 338, 350}
+	_ImageTypeIndex = [...]uint{0, 24, 34, 43, 52, 61, 71, 81, 90, 100, 117, 134, 155, 171, 188, 205, 222, 239, 264, 283, 293, 316, 325, 338, 350, 361, 380, 398, 417, 434, 443, 454, 469, 478, 490}
 
 	// ImageType extension Index
-	_ImageTypeExtIndex = [...]uint{0, 0, 3, 6, 9, 12, 16, 20, 23, 27, 30, 33, 36, 39, 42, 45, 48, 51, 54, 57, 61, 64, 67, 70, 76}
+	_ImageTypeExtIndex = [...]uint{0, 0, 3, 6, 9, 12, 16, 20, 23, 27, 30, 33, 36, 39, 42, 45, 48, 51, 54, 57, 61, 64, 67, 70, 76, 79, 82, 85, 88, 91, 94, 97, 100, 103, 106}
 )
 
 const (
 	// ImageType stringer Names
-	_ImageTypeString = "application/octet-streamimage/jpegimage/pngimage/gifimage/bmpimage/webpimage/heifimage/rawimage/tiffimage/x-adobe-dngimage/x-nikon-nefimage/x-panasonic-rawimage/x-sony-arwimage/x-canon-crwimage/x-gopro-gprimage/x-canon-cr3image/x-canon-cr2image/vnd.adobe.photoshopapplication/rdf+xmlimage/avifimage/x-portable-pixmapimage/jp2image/svg+xmlimage/magick"
+	_ImageTypeString = "application/octet-streamimage/jpegimage/pngimage/gifimage/bmpimage/webpimage/heifimage/rawimage/tiffimage/x-adobe-dngimage/x-nikon-nefimage/x-panasonic-rawimage/x-sony-arwimage/x-canon-crwimage/x-gopro-gprimage/x-canon-cr3image/x-canon-cr2image/vnd.adobe.photoshopapplication/rdf+xmlimage/avifimage/x-portable-pixmapimage/jp2image/svg+xmlimage/magickimage/x-tgaimage/x-olympus-orfimage/x-pentax-pefimage/x-samsung-srwimage/x-sigma-x3fimage/jxlimage/x-exrvideo/quicktimevideo/mp4image/x-icon"
 
 	// ImageType extension Names
-	_ImageTypeExtString = "jpgpnggifbmpwebpheifRAWTIFFDNGNEFRW2ARWCRWGPRCR3CR2PSDXMPavifppmjp2svgmagick"
+	_ImageTypeExtString = "jpgpnggifbmpwebpheifRAWTIFFDNGNEFRW2ARWCRWGPRCR3CR2PSDXMPavifppmjp2svgmagicktgaorfpefsrwx3fjxlexrmovmp4ico"
 )
 
 //go:generate msgp
@@ -49,6 +49,19 @@ const (
 //		ImageXMP:     "application/rdf+xml"
 //		ImageAVIF:    "image/avif"
 //		ImagePPM:     "image/x-portable-pixmap"
+//		ImageJP2K:    "image/jp2"
+//		ImageSVG:     "image/svg+xml"
+//		ImageMAGICK:  "image/magick"
+//		ImageTGA:     "image/x-tga"
+//		ImageORF:     "image/x-olympus-orf"
+//		ImagePEF:     "image/x-pentax-pef"
+//		ImageSRW:     "image/x-samsung-srw"
+//		ImageX3F:     "image/x-sigma-x3f"
+//		ImageJXL:     "image/jxl"
+//		ImageEXR:     "image/x-exr"
+//		ImageMOV:     "video/quicktime"
+//		ImageMP4:     "video/mp4"
+//		ImageICO:     "image/x-icon"
 type ImageType uint8
 
 // IsUnknown returns true if the Image Type is unknown
@@ -123,12 +136,26 @@ const (
 	ImageJP2K   // JP2K represents the JPEG 2000 image type.
 	ImageSVG    // SVG represents the SVG image type.
 	ImageMAGICK // MAGICK represents the libmagick compatible genetic image type.
+	ImageTGA    // TGA represents the Truevision TGA image type. It has no signature and is not identified by Scan.
+	ImageORF    // ORF represents the Olympus and OM System raw image type.
+	ImagePEF    // PEF represents the Pentax raw image type. It has a Tiff Header and is identified by its Makernote.
+	ImageSRW    // SRW represents the Samsung raw image type. It has a Tiff Header and is identified by its Makernote.
+	ImageX3F    // X3F represents the Sigma Foveon raw image type.
+	ImageJXL    // JXL represents the JPEG XL image type.
+	ImageEXR    // EXR represents the OpenEXR image type.
+	ImageMOV    // MOV represents the QuickTime movie type.
+	ImageMP4    // MP4 represents the MPEG-4 and CMAF movie type.
+	ImageICO    // ICO represents the Windows icon and cursor (CUR) image type.
 )
 
 // ImageTypeValues maps a content-type string with an imagetype.
 var imageTypeValues = map[string]ImageType{
 	"application/octet-stream":  ImageUnknown,
 	"image/jpeg":                ImageJPEG,
+	"image/mpo":                 ImageJPEG,
+	"image/x-mpo":               ImageJPEG,
+	"image/jps":                 ImageJPEG,
+	"image/x-jps":               ImageJPEG,
 	"image/png":                 ImagePNG,
 	"image/gif":                 ImageGIF,
 	"image/bmp":                 ImageBMP,
@@ -151,12 +178,25 @@ var imageTypeValues = map[string]ImageType{
 	"image/jp2":                 ImageJP2K,
 	"image/svg+xml":             ImageSVG,
 	"image/magick":              ImageMAGICK,
+	"image/x-tga":               ImageTGA,
+	"image/x-olympus-orf":       ImageORF,
+	"image/x-pentax-pef":        ImagePEF,
+	"image/x-samsung-srw":       ImageSRW,
+	"image/x-sigma-x3f":         ImageX3F,
+	"image/jxl":                 ImageJXL,
+	"image/x-exr":               ImageEXR,
+	"video/quicktime":           ImageMOV,
+	"video/mp4":                 ImageMP4,
+	"image/x-icon":              ImageICO,
+	"image/vnd.microsoft.icon":  ImageICO,
 }
 
 // ImageTypeExtensions maps filename extensions with an imagetype.
 var imageTypeExtensions = map[string]ImageType{
 	"":        ImageUnknown,
 	".jpg":    ImageJPEG,
+	".mpo":    ImageJPEG,
+	".jps":    ImageJPEG,
 	".png":    ImagePNG,
 	".gif":    ImageGIF,
 	".bmp":    ImageBMP,
@@ -171,14 +211,28 @@ var imageTypeExtensions = map[string]ImageType{
 	".crw":    ImageCRW,
 	".gpr":    ImageGPR,
 	".cr3":    ImageCR3,
+	".crm":    ImageCR3,
 	".cr2":    ImageCR2,
 	".psd":    ImagePSD,
+	".psb":    ImagePSD,
 	".xmp":    ImageXMP,
 	".avif":   ImageAVIF,
 	".ppm":    ImagePPM,
 	".jp2":    ImageJP2K,
 	".svg":    ImageSVG,
 	".magick": ImageMAGICK,
+	".tga":    ImageTGA,
+	".orf":    ImageORF,
+	".pef":    ImagePEF,
+	".srw":    ImageSRW,
+	".x3f":    ImageX3F,
+	".jxl":    ImageJXL,
+	".exr":    ImageEXR,
+	".mov":    ImageMOV,
+	".mp4":    ImageMP4,
+	".m4v":    ImageMP4,
+	".ico":    ImageICO,
+	".cur":    ImageICO,
 }
 
 // isTiff() Checks to see if an Image has the tiff format header.
@@ -188,7 +242,15 @@ func isTiff(buf []byte) bool {
 		// BigEndian Tiff Image Header
 		IsTiffBigEndian(buf[:4]) ||
 		// LittleEndian Tiff Image Header
-		IsTiffLittleEndian(buf[:4])
+		IsTiffLittleEndian(buf[:4]) ||
+		// BigTiff Image Header
+		isBigTiff(buf[:4])
+}
+
+// isBigTiff checks the buf for the BigTiff (version 0x002B) Signature
+func isBigTiff(buf []byte) bool {
+	return (buf[0] == 0x49 && buf[1] == 0x49 && buf[2] == 0x2b && buf[3] == 0x00) ||
+		(buf[0] == 0x4d && buf[1] == 0x4d && buf[2] == 0x00 && buf[3] == 0x2b)
 }
 
 // IsTiffLittleEndian checks the buf for the Tiff LittleEndian Signature
@@ -238,6 +300,46 @@ func isCR2(buf []byte) bool {
 		buf[11] == 0x00
 }
 
+// isORF returns true if it matches an image/x-olympus-orf.
+//
+// The Olympus ORF Header is a Tiff Header with the magic number "RO" or "RS"
+// (LittleEndian) or "OR" (BigEndian) instead of 0x002A.
+func isORF(buf []byte) bool {
+	return (buf[0] == 0x49 && buf[1] == 0x49 && buf[2] == 0x52 && (buf[3] == 0x4f || buf[3] == 0x53)) ||
+		(buf[0] == 0x4d && buf[1] == 0x4d && buf[2] == 0x4f && buf[3] == 0x52)
+}
+
+// isJXL returns true if it matches an image/jxl.
+//
+// A JPEG XL bare codestream begins with 0xFF0A, a JPEG XL container begins
+// with the "JXL " signature box.
+func isJXL(buf []byte) bool {
+	return (buf[0] == 0xFF && buf[1] == 0x0A) ||
+		(buf[0] == 0x00 && buf[1] == 0x00 && buf[2] == 0x00 && buf[3] == 0x0C &&
+			buf[4] == 'J' && buf[5] == 'X' && buf[6] == 'L' && buf[7] == ' ' &&
+			buf[8] == 0x0D && buf[9] == 0x0A && buf[10] == 0x87 && buf[11] == 0x0A)
+}
+
+// isEXR returns true if it matches an image/x-exr.
+//
+// The OpenEXR Header begins with the magic number 0x762F3101.
+func isEXR(buf []byte) bool {
+	return buf[0] == 0x76 &&
+		buf[1] == 0x2F &&
+		buf[2] == 0x31 &&
+		buf[3] == 0x01
+}
+
+// isX3F returns true if it matches an image/x-sigma-x3f.
+//
+// The Sigma X3F Header begins with the file type identifier "FOVb".
+func isX3F(buf []byte) bool {
+	return buf[0] == 'F' &&
+		buf[1] == 'O' &&
+		buf[2] == 'V' &&
+		buf[3] == 'b'
+}
+
 // isCR3 returns true if it matches an image/x-canon-cr3.
 //
 // ftyp box with major_brand: 'crx ' and compatible_brands: 'crx ' 'isom'
@@ -277,13 +379,48 @@ func isFTYPBox(buf []byte) bool {
 		buf[7] == 0x70
 }
 
+// isMOV returns true if the header matches a QuickTime movie.
+//
+// An ftyp box with major_brand: 'qt  ', or a QuickTime movie without
+// an ftyp box that begins with a 'moov', 'mdat', 'wide' or 'pnot' atom.
+func isMOV(buf []byte) bool {
+	if isFTYPBox(buf) {
+		return isFTYPBrand(buf[8:12], "qt  ")
+	}
+	return buf[0] == 0x0 &&
+		(isFTYPBrand(buf[4:8], "moov") ||
+			isFTYPBrand(buf[4:8], "mdat") ||
+			isFTYPBrand(buf[4:8], "wide") ||
+			isFTYPBrand(buf[4:8], "pnot"))
+}
+
+// isMP4 returns true if the header matches an ftyp box with an MPEG-4 or CMAF major brand.
+//
+// HEIF, AVIF and CR3 brands are identified before MP4 brands.
+func isMP4(buf []byte) bool {
+	if !isFTYPBox(buf) {
+		return false
+	}
+	for _, brand := range mp4Brands {
+		if isFTYPBrand(buf[8:12], brand) {
+			return true
+		}
+	}
+	return false
+}
+
+// mp4Brands are the major brands of MPEG-4 and CMAF movies
+var mp4Brands = []string{"isom", "iso2", "iso4", "iso5", "iso6", "mp41", "mp42", "avc1", "dash", "cmfc", "cmf2", "M4V ", "f4v ", "3gp4", "3gp5", "3gp6", "3g2a", "MSNV"}
+
 // isAVIF returns true if the header matches an ftyp box and
-// an avif box.
+// an avif (image) or avis (image sequence) brand.
 //
 func isAVIF(buf []byte) bool {
 	return isFTYPBox(buf) &&
 		(isFTYPBrand(buf[8:12], "avif") ||
-			(isFTYPBrand(buf[8:12], "mif1") && isFTYPBrand(buf[20:24], "avif")))
+			isFTYPBrand(buf[8:12], "avis") ||
+			(isFTYPBrand(buf[8:12], "mif1") && (isFTYPBrand(buf[16:20], "avif") || isFTYPBrand(buf[20:24], "avif"))) ||
+			(isFTYPBrand(buf[8:12], "msf1") && (isFTYPBrand(buf[16:20], "avis") || isFTYPBrand(buf[20:24], "avis"))))
 }
 
 // isBMP returns true if the header matches the start of a BMP file
@@ -338,8 +475,14 @@ func isWebP(buf []byte) bool {
 }
 
 // isJPEG2000 returns true if the first 12 bytes match a JPEG2000 file header
-//
+// (the "jP  " signature box) or the first 4 bytes match a JPEG2000 codestream
+// (the SOC and SIZ markers).
 func isJPEG2000(buf []byte) bool {
+	return isJP2(buf) || (buf[0] == 0xFF && buf[1] == 0x4F && buf[2] == 0xFF && buf[3] == 0x51)
+}
+
+// isJP2 returns true if the first 12 bytes match a JP2 file signature box.
+func isJP2(buf []byte) bool {
 	return buf[0] == 0x0 &&
 		buf[1] == 0x0 &&
 		buf[2] == 0x0 &&
@@ -389,6 +532,23 @@ func isGIF(buf []byte) bool {
 		buf[5] == 'a'
 }
 
+// isICO returns true if the header matches the header of an ICO icon or
+// a CUR cursor with at least one image, and the directory entry of the
+// first image.
+func isICO(buf []byte) bool {
+	count := uint16(buf[4]) | uint16(buf[5])<<8
+	offset := uint32(buf[18]) | uint32(buf[19])<<8 | uint32(buf[20])<<16 | uint32(buf[21])<<24
+	return buf[0] == 0x00 &&
+		buf[1] == 0x00 &&
+		(buf[2] == 0x01 || buf[2] == 0x02) &&
+		buf[3] == 0x00 &&
+		count > 0 &&
+		// Reserved byte of the directory entry
+		buf[9] == 0x00 &&
+		// Image data follows the directory
+		offset >= 6+16*uint32(count)
+}
+
 func isPPM(buf []byte) bool {
 	return buf[0] == 'P' &&
 		(buf[1] == '3' || buf[1] == '6') &&
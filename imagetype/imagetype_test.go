@@ -64,6 +64,85 @@ func TestScan(t *testing.T) {
 	}
 }
 
+// nonSeekReader hides all methods except Read
+type nonSeekReader struct {
+	io.Reader
+}
+
+func TestDetect(t *testing.T) {
+	detectTests := []struct {
+		filename  string
+		imageType ImageType
+		err       error
+	}{
+		{"../testImages/ARW.exif", ImageTiff, nil},
+		{"../testImages/CR2.exif", ImageCR2, nil},
+		{"../testImages/Heic.exif", ImageHEIF, nil},
+		{"../testImages/JPEG.jpg", ImageJPEG, nil},
+		{"../testImages/AVIF.avif", ImageAVIF, nil},
+		{"../testImages/Unknown.exif", ImageUnknown, ErrImageTypeNotFound},
+	}
+	for _, dt := range detectTests {
+		t.Run(dt.filename, func(t *testing.T) {
+			buf, err := os.ReadFile(dt.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			imageType, r, err := Detect(nonSeekReader{bytes.NewReader(buf)})
+			if err != dt.err {
+				t.Errorf("Incorrect error wanted %v got %v", dt.err, err)
+			}
+			if imageType != dt.imageType {
+				t.Errorf("Incorrect Imagetype wanted %s got %s", dt.imageType, imageType)
+			}
+			// Returned reader replays the peeked bytes
+			replay, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf, replay) {
+				t.Errorf("Incorrect replayed data wanted %d bytes got %d bytes", len(buf), len(replay))
+			}
+		})
+	}
+
+	// Short JPEG
+	imageType, r, err := Detect(bytes.NewReader([]byte{0xff, 0xd8, 0xff, 0xe1}))
+	if err != nil || imageType != ImageJPEG {
+		t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", ImageJPEG, imageType, err)
+	}
+	if replay, _ := io.ReadAll(r); len(replay) != 4 {
+		t.Errorf("Incorrect replayed data wanted %d bytes got %d bytes", 4, len(replay))
+	}
+
+	// Empty
+	if _, _, err = Detect(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("Incorrect error wanted %v got %v", io.EOF, err)
+	}
+}
+
+func TestIsAVIF(t *testing.T) {
+	ftyp := func(major, compatible1, compatible2 string) []byte {
+		return []byte("\x00\x00\x00\x1cftyp" + major + "\x00\x00\x00\x00" + compatible1 + compatible2)
+	}
+	tests := []struct {
+		buf       []byte
+		imageType ImageType
+	}{
+		{ftyp("avif", "mif1", "miaf"), ImageAVIF},
+		{ftyp("avis", "msf1", "miaf"), ImageAVIF},
+		{ftyp("mif1", "avif", "miaf"), ImageAVIF},
+		{ftyp("mif1", "miaf", "avif"), ImageAVIF},
+		{ftyp("msf1", "avis", "miaf"), ImageAVIF},
+		{ftyp("mif1", "miaf", "heic"), ImageHEIF},
+	}
+	for _, v := range tests {
+		if imageType, err := Buf(v.buf); err != nil || imageType != v.imageType {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s (%v)", v.buf, v.imageType, imageType, err)
+		}
+	}
+}
+
 func TestImageTypeIndices(t *testing.T) {
 	cases := map[ImageType]struct {
 		ext string
@@ -90,6 +169,19 @@ func TestImageTypeIndices(t *testing.T) {
 		ImageXMP:     {"XMP", "application/rdf+xml"},
 		ImageAVIF:    {"avif", "image/avif"},
 		ImagePPM:     {"ppm", "image/x-portable-pixmap"},
+		ImageJP2K:    {"jp2", "image/jp2"},
+		ImageSVG:     {"svg", "image/svg+xml"},
+		ImageMAGICK:  {"magick", "image/magick"},
+		ImageTGA:     {"tga", "image/x-tga"},
+		ImageORF:     {"orf", "image/x-olympus-orf"},
+		ImagePEF:     {"pef", "image/x-pentax-pef"},
+		ImageSRW:     {"srw", "image/x-samsung-srw"},
+		ImageX3F:     {"x3f", "image/x-sigma-x3f"},
+		ImageJXL:     {"jxl", "image/jxl"},
+		ImageEXR:     {"exr", "image/x-exr"},
+		ImageMOV:     {"mov", "video/quicktime"},
+		ImageMP4:     {"mp4", "video/mp4"},
+		ImageICO:     {"ico", "image/x-icon"},
 	}
 
 	for it, exp := range cases {
@@ -154,6 +246,12 @@ func TestImageType(t *testing.T) {
 		t.Errorf("Incorrect Imagetype wanted %s got %s", ImageJPEG, it)
 	}
 
+	for _, str := range []string{".mpo", ".jps", "image/x-mpo"} {
+		if it = FromString(str); it != ImageJPEG {
+			t.Errorf("Incorrect Imagetype for %s wanted %s got %s", str, ImageJPEG, it)
+		}
+	}
+
 	it = FromString("hello")
 	if it != ImageUnknown {
 		t.Errorf("Incorrect Imagetype wanted %s got %s", ImageUnknown, it)
@@ -196,7 +294,7 @@ func TestScanImageType(t *testing.T) {
 		{".RW2", "4.RW2", "image/x-panasonic-raw"},
 		{".XMP", "test.xmp", "application/rdf+xml"},
 		{".PSD", "0.psd", "image/vnd.adobe.photoshop"},
-		{".JP2/JPEG2000", "0.jp2", "image/jpeg"},
+		{".JP2/JPEG2000", "0.jp2", "image/jp2"},
 		{".BMP", "0.bmp", "image/bmp"},
 	}
 
@@ -289,3 +387,88 @@ func TestMsgp(t *testing.T) {
 	}
 
 }
+
+func TestIsJPEG2000(t *testing.T) {
+	for _, h := range []string{"\xff\x4f\xff\x51", "\x00\x00\x00\x0cjP  \x0d\x0a\x87\x0a"} {
+		buf := make([]byte, searchHeaderLength)
+		copy(buf, h)
+		if it, err := Buf(buf); err != nil || it != ImageJP2K {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s (%v)", h, ImageJP2K, it, err)
+		}
+	}
+}
+
+func TestIsJXL(t *testing.T) {
+	for _, h := range []string{"\xff\x0a\xfa\x7f", "\x00\x00\x00\x0cJXL \x0d\x0a\x87\x0a"} {
+		buf := make([]byte, searchHeaderLength)
+		copy(buf, h)
+		if it, err := Buf(buf); err != nil || it != ImageJXL {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s (%v)", h, ImageJXL, it, err)
+		}
+	}
+}
+
+func TestIsMOV(t *testing.T) {
+	for _, h := range []string{"\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00qt  ", "\x00\x00\x00\x08wide", "\x00\x00\x10\x00moov"} {
+		buf := make([]byte, searchHeaderLength)
+		copy(buf, h)
+		if it, err := Buf(buf); err != nil || it != ImageMOV {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s (%v)", h, ImageMOV, it, err)
+		}
+	}
+}
+
+func TestIsMP4(t *testing.T) {
+	for _, h := range []string{"\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isomiso2", "\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom", "\x00\x00\x00\x18ftypcmfc\x00\x00\x00\x00cmfciso6"} {
+		buf := make([]byte, searchHeaderLength)
+		copy(buf, h)
+		if it, err := Buf(buf); err != nil || it != ImageMP4 {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s (%v)", h, ImageMP4, it, err)
+		}
+	}
+}
+
+func TestIsICO(t *testing.T) {
+	tests := []struct {
+		header string
+		it     ImageType
+	}{
+		{"\x00\x00\x01\x00\x01\x00\x10\x10\x00\x00\x01\x00\x20\x00\x68\x04\x00\x00\x16\x00\x00\x00\x00\x00", ImageICO},
+		{"\x00\x00\x02\x00\x02\x00\x20\x20\x00\x00\x05\x00\x05\x00\x30\x01\x00\x00\x26\x00\x00\x00\x00\x00", ImageICO},
+		// No images
+		{"\x00\x00\x01\x00\x00\x00\x10\x10\x00\x00\x01\x00\x20\x00\x68\x04\x00\x00\x16\x00\x00\x00\x00\x00", ImageUnknown},
+		// Image data within the directory
+		{"\x00\x00\x01\x00\x02\x00\x10\x10\x00\x00\x01\x00\x20\x00\x68\x04\x00\x00\x16\x00\x00\x00\x00\x00", ImageUnknown},
+	}
+	for _, test := range tests {
+		if it, _ := Buf([]byte(test.header)); it != test.it {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s", test.header, test.it, it)
+		}
+	}
+}
+
+func TestIsEXR(t *testing.T) {
+	buf := make([]byte, searchHeaderLength)
+	copy(buf, "\x76\x2f\x31\x01\x02\x00\x00\x00")
+	if it, err := Buf(buf); err != nil || it != ImageEXR {
+		t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", ImageEXR, it, err)
+	}
+}
+
+func TestIsX3F(t *testing.T) {
+	buf := make([]byte, searchHeaderLength)
+	copy(buf, "FOVb\x03\x00\x02\x00")
+	if it, err := Buf(buf); err != nil || it != ImageX3F {
+		t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", ImageX3F, it, err)
+	}
+}
+
+func TestIsORF(t *testing.T) {
+	for _, h := range []string{"IIRO", "IIRS", "MMOR"} {
+		buf := make([]byte, searchHeaderLength)
+		copy(buf, h)
+		if it, err := Buf(buf); err != nil || it != ImageORF {
+			t.Errorf("Incorrect Imagetype for %q wanted %s got %s (%v)", h, ImageORF, it, err)
+		}
+	}
+}
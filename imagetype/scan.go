@@ -28,6 +28,32 @@ func Scan(r io.Reader) (imageType ImageType, err error) {
 	return ScanBuf(br)
 }
 
+// Detect peeks at the first bytes of the reader and returns an imageType based on
+// underlying rules, and an io.Reader that yields all of the bytes of r, including
+// the bytes that were peeked. The reader r should not be used after calling Detect.
+//
+// Returns ImageUnknown and ErrImageTypeNotFound if imageType was not identified.
+func Detect(r io.Reader) (imageType ImageType, rd io.Reader, err error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok || br.Size() < searchHeaderLength {
+		br = bufio.NewReader(r)
+	}
+
+	buf, err := br.Peek(searchHeaderLength)
+	if err != nil {
+		if err != io.EOF || len(buf) == 0 {
+			return ImageUnknown, br, err
+		}
+		// Input is shorter than searchHeaderLength
+		var header [searchHeaderLength]byte
+		copy(header[:], buf)
+		buf = header[:]
+	}
+
+	imageType, err = Buf(buf)
+	return imageType, br, err
+}
+
 // ScanBuf peeks at a bufio.Reader and returns an imageType based on
 // underlying rules. Returns ImageUnknown and ErrImageTypeNotFound if imageType was not
 // identified.
@@ -83,7 +109,7 @@ func parseBuffer(buf []byte) ImageType {
 
 	// JPEG2000 Header
 	if isJPEG2000(buf) {
-		return ImageJPEG
+		return ImageJP2K
 	}
 
 	// Canon CRW Header
@@ -110,6 +136,14 @@ func parseBuffer(buf []byte) ImageType {
 		if isHeif(buf) {
 			return ImageHEIF
 		}
+		// QuickTime Header
+		if isMOV(buf) {
+			return ImageMOV
+		}
+		// MPEG-4 Header
+		if isMP4(buf) {
+			return ImageMP4
+		}
 	}
 
 	// Panasonic/Leica Raw Header
@@ -117,6 +151,26 @@ func parseBuffer(buf []byte) ImageType {
 		return ImagePanaRAW
 	}
 
+	// Olympus Raw Header
+	if isORF(buf) {
+		return ImageORF
+	}
+
+	// JPEG XL Header
+	if isJXL(buf) {
+		return ImageJXL
+	}
+
+	// OpenEXR Header
+	if isEXR(buf) {
+		return ImageEXR
+	}
+
+	// Sigma X3F Header
+	if isX3F(buf) {
+		return ImageX3F
+	}
+
 	// Tiff Header
 	if isTiff(buf) {
 		return ImageTiff
@@ -156,5 +210,15 @@ func parseBuffer(buf []byte) ImageType {
 		return ImagePPM
 	}
 
+	// ICO and CUR Header
+	if isICO(buf) {
+		return ImageICO
+	}
+
+	// QuickTime Header without an ftyp box
+	if isMOV(buf) {
+		return ImageMOV
+	}
+
 	return ImageUnknown
 }
@@ -0,0 +1,129 @@
+package iptc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"unicode/utf8"
+)
+
+// ErrCorruptDataSet is returned when a DataSet is truncated or does not start with the tag marker.
+var ErrCorruptDataSet = errors.New("iptc: error corrupt DataSet")
+
+// Decode returns the Application Record DataSets of the IIM record in buf.
+// DataSets that are not in IPTC are skipped.
+//
+// Values are decoded as UTF-8. Without the UTF-8 Coded Character Set, values
+// that are not valid UTF-8 are decoded as ISO 8859-1.
+// Returns ErrCorruptDataSet if a DataSet is truncated.
+func Decode(buf []byte) (i IPTC, err error) {
+	sets, err := readDataSets(buf)
+	if err != nil {
+		return i, err
+	}
+	// The Coded Character Set may follow the Application Record
+	isUTF8 := isUTF8Record(sets)
+	for _, v := range sets {
+		if v.record != ApplicationRecord {
+			continue
+		}
+		value := decodeString(v.value, isUTF8)
+		switch v.ds {
+		case ObjectName:
+			i.ObjectName = value
+		case Keywords:
+			i.Keywords = append(i.Keywords, value)
+		case SpecialInstructions:
+			i.SpecialInstructions = value
+		case Byline:
+			i.Byline = append(i.Byline, value)
+		case City:
+			i.City = value
+		case ProvinceState:
+			i.ProvinceState = value
+		case CountryName:
+			i.CountryName = value
+		case Headline:
+			i.Headline = value
+		case Credit:
+			i.Credit = value
+		case Source:
+			i.Source = value
+		case CopyrightNotice:
+			i.Copyright = value
+		case Contact:
+			i.Contact = append(i.Contact, value)
+		case CaptionAbstract:
+			i.Caption = value
+		case WriterEditor:
+			i.WriterEditor = value
+		}
+	}
+	return i, nil
+}
+
+// dataSet is a DataSet of an IIM record. raw is the DataSet with its header.
+type dataSet struct {
+	record Record
+	ds     DataSet
+	value  []byte
+	raw    []byte
+}
+
+// readDataSets returns the DataSets of the IIM record in buf.
+// Returns ErrCorruptDataSet if a DataSet is truncated.
+func readDataSets(buf []byte) (sets []dataSet, err error) {
+	// Trailing zero padding is ignored
+	for len(bytes.TrimRight(buf, "\x00")) > 0 {
+		if len(buf) < 5 || buf[0] != tagMarker {
+			return nil, ErrCorruptDataSet
+		}
+		raw := buf
+		record, ds := buf[1], buf[2]
+		n := int(binary.BigEndian.Uint16(buf[3:5]))
+		buf = buf[5:]
+		// Extended DataSet, the length is in the next n bytes
+		if n > maxDataSetLength {
+			n &= maxDataSetLength
+			if n > 4 || len(buf) < n {
+				return nil, ErrCorruptDataSet
+			}
+			size := 0
+			for _, b := range buf[:n] {
+				size = size<<8 | int(b)
+			}
+			buf = buf[n:]
+			n = size
+		}
+		if n < 0 || len(buf) < n {
+			return nil, ErrCorruptDataSet
+		}
+		sets = append(sets, dataSet{Record(record), DataSet(ds), buf[:n], raw[:len(raw)-len(buf)+n]})
+		buf = buf[n:]
+	}
+	return sets, nil
+}
+
+// isUTF8Record returns true if the Coded Character Set of the
+// Envelope Record is UTF-8.
+func isUTF8Record(sets []dataSet) bool {
+	for _, v := range sets {
+		if v.record == EnvelopeRecord && v.ds == CodedCharacterSet {
+			return bytes.Equal(v.value, utf8CharacterSet)
+		}
+	}
+	return false
+}
+
+// decodeString returns buf as a string. buf is decoded as ISO 8859-1
+// if it is not UTF-8.
+func decodeString(buf []byte, isUTF8 bool) string {
+	if isUTF8 || utf8.Valid(buf) {
+		return string(buf)
+	}
+	runes := make([]rune, len(buf))
+	for i, b := range buf {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
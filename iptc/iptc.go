@@ -0,0 +1,217 @@
+// Package iptc provides functions for encoding and decoding IPTC-IIM (Information Interchange Model)
+// metadata, as embedded in the Photoshop APP13 segment of JPEG images.
+package iptc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"unicode/utf8"
+)
+
+// Errors
+var (
+	// ErrDataSetTooLarge is returned when the value of a DataSet is
+	// larger than 32767 bytes.
+	ErrDataSetTooLarge = errors.New("iptc: error DataSet value too large")
+)
+
+const (
+	// tagMarker starts every DataSet
+	tagMarker = 0x1C
+
+	// maxDataSetLength is the largest value of a standard DataSet.
+	// Larger values require an extended DataSet.
+	maxDataSetLength = 0x7FFF
+
+	// recordVersion is the IIM version 4 of the Application Record
+	recordVersion = 4
+)
+
+// Record is an IIM record number.
+type Record uint8
+
+// Records
+const (
+	EnvelopeRecord    Record = 1
+	ApplicationRecord Record = 2
+)
+
+// DataSet is an IIM DataSet number of a Record.
+type DataSet uint8
+
+// Envelope Record DataSets
+const (
+	CodedCharacterSet DataSet = 90
+)
+
+// Application Record DataSets
+const (
+	RecordVersion       DataSet = 0
+	ObjectName          DataSet = 5
+	Keywords            DataSet = 25
+	SpecialInstructions DataSet = 40
+	Byline              DataSet = 80
+	City                DataSet = 90
+	ProvinceState       DataSet = 95
+	CountryName         DataSet = 101
+	Headline            DataSet = 105
+	Credit              DataSet = 110
+	Source              DataSet = 115
+	CopyrightNotice     DataSet = 116
+	Contact             DataSet = 118
+	CaptionAbstract     DataSet = 120
+	WriterEditor        DataSet = 122
+)
+
+// utf8CharacterSet is the ISO 2022 escape sequence of UTF-8.
+var utf8CharacterSet = []byte{0x1B, '%', 'G'}
+
+// IPTC contains the common DataSets of the IPTC-IIM Application Record.
+// Values are UTF-8. The maximum lengths of the IIM spec are not enforced.
+type IPTC struct {
+	ObjectName          string   // 2:05 Object Name, the title
+	Keywords            []string // 2:25 Keywords, repeatable
+	SpecialInstructions string   // 2:40 Special Instructions
+	Byline              []string // 2:80 By-line, the creators, repeatable
+	City                string   // 2:90 City
+	ProvinceState       string   // 2:95 Province/State
+	CountryName         string   // 2:101 Country/Primary Location Name
+	Headline            string   // 2:105 Headline
+	Credit              string   // 2:110 Credit
+	Source              string   // 2:115 Source
+	Copyright           string   // 2:116 Copyright Notice
+	Contact             []string // 2:118 Contact, repeatable
+	Caption             string   // 2:120 Caption/Abstract
+	WriterEditor        string   // 2:122 Writer/Editor, the writer of the caption
+}
+
+// Encode returns the IIM DataSets of i. The Coded Character Set of the
+// Envelope Record is set to UTF-8. Empty values are not written.
+//
+// Returns ErrDataSetTooLarge if a value is larger than 32767 bytes.
+func (i IPTC) Encode() ([]byte, error) {
+	var e encoder
+	e.dataSet(EnvelopeRecord, CodedCharacterSet, utf8CharacterSet)
+	e.dataSet(ApplicationRecord, RecordVersion, []byte{0, recordVersion})
+	e.string(ObjectName, i.ObjectName)
+	for _, keyword := range i.Keywords {
+		e.string(Keywords, keyword)
+	}
+	e.string(SpecialInstructions, i.SpecialInstructions)
+	for _, byline := range i.Byline {
+		e.string(Byline, byline)
+	}
+	e.string(City, i.City)
+	e.string(ProvinceState, i.ProvinceState)
+	e.string(CountryName, i.CountryName)
+	e.string(Headline, i.Headline)
+	e.string(Credit, i.Credit)
+	e.string(Source, i.Source)
+	e.string(CopyrightNotice, i.Copyright)
+	for _, contact := range i.Contact {
+		e.string(Contact, contact)
+	}
+	e.string(CaptionAbstract, i.Caption)
+	e.string(WriterEditor, i.WriterEditor)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// Merge returns the IIM record with the DataSets of i set in record. The DataSets
+// of the Application Record that are in IPTC are replaced by the values of i, and
+// empty values of i remove them. DataSets that are not in IPTC, such as 2:55 Date
+// Created and 2:15 Category, and the other Records are kept.
+//
+// The Coded Character Set of the Envelope Record is set to UTF-8, and kept values
+// of the Application Record that were ISO 8859-1 are converted to UTF-8.
+// Returns ErrCorruptDataSet if a DataSet of record is truncated and
+// ErrDataSetTooLarge if a value of i is larger than 32767 bytes.
+func Merge(record []byte, i IPTC) ([]byte, error) {
+	sets, err := readDataSets(record)
+	if err != nil {
+		return nil, err
+	}
+	isUTF8 := isUTF8Record(sets)
+	buf, err := i.Encode()
+	if err != nil {
+		return nil, err
+	}
+	merged, err := readDataSets(buf)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range sets {
+		if v.record == EnvelopeRecord && v.ds == CodedCharacterSet {
+			continue
+		}
+		if v.record == ApplicationRecord {
+			if isIPTCDataSet(v.ds) {
+				continue
+			}
+			if !isUTF8 && !utf8.Valid(v.value) {
+				var e encoder
+				e.dataSet(ApplicationRecord, v.ds, []byte(decodeString(v.value, false)))
+				if e.err != nil {
+					return nil, e.err
+				}
+				v.raw = e.buf.Bytes()
+			}
+		}
+		merged = append(merged, v)
+	}
+	// Records are in ascending order, as are the DataSets of the Application Record
+	sort.SliceStable(merged, func(a, b int) bool {
+		if merged[a].record != merged[b].record {
+			return merged[a].record < merged[b].record
+		}
+		return merged[a].record == ApplicationRecord && merged[a].ds < merged[b].ds
+	})
+	var out bytes.Buffer
+	for _, v := range merged {
+		out.Write(v.raw)
+	}
+	return out.Bytes(), nil
+}
+
+// isIPTCDataSet returns true if ds is a DataSet of the Application Record that
+// is written by IPTC.Encode.
+func isIPTCDataSet(ds DataSet) bool {
+	switch ds {
+	case RecordVersion, ObjectName, Keywords, SpecialInstructions, Byline,
+		City, ProvinceState, CountryName, Headline, Credit, Source,
+		CopyrightNotice, Contact, CaptionAbstract, WriterEditor:
+		return true
+	}
+	return false
+}
+
+// encoder writes DataSets to buf. The first error is kept in err.
+type encoder struct {
+	buf bytes.Buffer
+	err error
+}
+
+// string writes an Application Record DataSet with a string value.
+// Empty values are not written.
+func (e *encoder) string(ds DataSet, value string) {
+	if value != "" {
+		e.dataSet(ApplicationRecord, ds, []byte(value))
+	}
+}
+
+// dataSet writes a DataSet with value.
+func (e *encoder) dataSet(record Record, ds DataSet, value []byte) {
+	if len(value) > maxDataSetLength {
+		e.err = ErrDataSetTooLarge
+		return
+	}
+	var header [5]byte
+	header[0], header[1], header[2] = tagMarker, byte(record), byte(ds)
+	binary.BigEndian.PutUint16(header[3:], uint16(len(value)))
+	e.buf.Write(header[:])
+	e.buf.Write(value)
+}
@@ -0,0 +1,109 @@
+package iptc
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	buf, err := IPTC{
+		Keywords:  []string{"a", "bc"},
+		Copyright: "©",
+		Caption:   "Caption",
+	}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x1C, 1, 90, 0, 3, 0x1B, '%', 'G',
+		0x1C, 2, 0, 0, 2, 0, 4,
+		0x1C, 2, 25, 0, 1, 'a',
+		0x1C, 2, 25, 0, 2, 'b', 'c',
+		0x1C, 2, 116, 0, 2, 0xC2, 0xA9,
+		0x1C, 2, 120, 0, 7, 'C', 'a', 'p', 't', 'i', 'o', 'n',
+	}
+	if !bytes.Equal(want, buf) {
+		t.Errorf("Incorrect IPTC wanted %x got %x", want, buf)
+	}
+
+	if _, err = (IPTC{Caption: strings.Repeat("a", maxDataSetLength+1)}).Encode(); err != ErrDataSetTooLarge {
+		t.Errorf("Incorrect error wanted %v got %v", ErrDataSetTooLarge, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	want := IPTC{
+		ObjectName: "Title",
+		Keywords:   []string{"a", "bc"},
+		Byline:     []string{"Photographer"},
+		Copyright:  "©",
+		Contact:    []string{"studio@example.com", "+1 555 0100"},
+		Caption:    "Caption",
+	}
+	buf, err := want.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, i) {
+		t.Errorf("Incorrect IPTC wanted %+v got %+v", want, i)
+	}
+
+	// ISO 8859-1 without the Coded Character Set and an extended DataSet
+	buf = []byte{
+		0x1C, 2, 116, 0, 1, 0xA9,
+		0x1C, 2, 120, 0x80, 2, 0, 3, 'a', 'b', 'c',
+		0x1C, 3, 10, 0, 1, 'x',
+		0, 0,
+	}
+	if i, err = Decode(buf); err != nil {
+		t.Fatal(err)
+	}
+	if i.Copyright != "©" || i.Caption != "abc" {
+		t.Errorf("Incorrect IPTC wanted %q %q got %q %q", "©", "abc", i.Copyright, i.Caption)
+	}
+
+	for _, buf := range [][]byte{{0x1C, 2, 5, 0}, {0x1C, 2, 5, 0, 2, 'a'}, {0x1D, 2, 5, 0, 0}, {0x1C, 2, 5, 0x80, 5, 0, 0, 0, 0, 1}} {
+		if _, err = Decode(buf); err != ErrCorruptDataSet {
+			t.Errorf("Incorrect error for %x wanted %v got %v", buf, ErrCorruptDataSet, err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	record := []byte{
+		0x1C, 2, 0, 0, 2, 0, 2,
+		0x1C, 2, 5, 0, 5, 'T', 'i', 't', 'l', 'e',
+		0x1C, 2, 15, 0, 3, 'A', 'B', 'C',
+		0x1C, 2, 55, 0, 8, '2', '0', '2', '4', '0', '1', '0', '2',
+		0x1C, 2, 65, 0, 1, 0xA9,
+		0x1C, 2, 120, 0, 3, 'O', 'l', 'd',
+		0x1C, 3, 10, 0, 1, 'x',
+	}
+	buf, err := Merge(record, IPTC{Keywords: []string{"k"}, Caption: "New"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x1C, 1, 90, 0, 3, 0x1B, '%', 'G',
+		0x1C, 2, 0, 0, 2, 0, 4,
+		0x1C, 2, 15, 0, 3, 'A', 'B', 'C',
+		0x1C, 2, 25, 0, 1, 'k',
+		0x1C, 2, 55, 0, 8, '2', '0', '2', '4', '0', '1', '0', '2',
+		0x1C, 2, 65, 0, 2, 0xC2, 0xA9,
+		0x1C, 2, 120, 0, 3, 'N', 'e', 'w',
+		0x1C, 3, 10, 0, 1, 'x',
+	}
+	if !bytes.Equal(want, buf) {
+		t.Errorf("Incorrect IPTC wanted %x got %x", want, buf)
+	}
+
+	if _, err = Merge([]byte{0x1C, 2, 5, 0, 2, 'a'}, IPTC{}); err != ErrCorruptDataSet {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptDataSet, err)
+	}
+}
@@ -0,0 +1,444 @@
+// Package jp2 reads the dimensions, the color specification and the Exif and XMP
+// metadata of a JPEG 2000 Image.
+//
+// A JP2 file is a sequence of boxes. The "jp2h" header box has the image header ("ihdr")
+// and the color specification ("colr") boxes, Exif and XMP metadata are in "uuid" boxes.
+// A J2K file is a bare codestream, its dimensions are read from the SIZ marker segment.
+package jp2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif         = meta.ErrNoExif
+	ErrNoJP2Signature = errors.New("no JPEG 2000 Signature")
+	ErrNoICCProfile   = errors.New("error JPEG 2000 does not have an ICC profile")
+
+	// ErrCorruptBox is returned when a box is truncated or its size is invalid.
+	ErrCorruptBox = errors.New("corrupt JPEG 2000 box")
+
+	// ErrCorruptCodestream is returned when the SIZ marker segment is truncated.
+	ErrCorruptCodestream = errors.New("corrupt JPEG 2000 codestream")
+)
+
+// Signatures
+var (
+	// jp2Signature is the "jP  " signature box of a JP2 file
+	jp2Signature = []byte{0x00, 0x00, 0x00, 0x0C, 'j', 'P', ' ', ' ', 0x0D, 0x0A, 0x87, 0x0A}
+
+	// codestreamSignature are the SOC and SIZ markers of a codestream
+	codestreamSignature = []byte{0xFF, 0x4F, 0xFF, 0x51}
+)
+
+// jp2ByteOrder is the byte order of JPEG 2000 boxes and marker segments
+var jp2ByteOrder = binary.BigEndian
+
+// Box types
+var (
+	boxJP2H = [4]byte{'j', 'p', '2', 'h'}
+	boxIHDR = [4]byte{'i', 'h', 'd', 'r'}
+	boxCOLR = [4]byte{'c', 'o', 'l', 'r'}
+	boxJP2C = [4]byte{'j', 'p', '2', 'c'}
+	boxUUID = [4]byte{'u', 'u', 'i', 'd'}
+	boxXML  = [4]byte{'x', 'm', 'l', ' '}
+)
+
+// UUIDs of the "uuid" metadata boxes
+var (
+	// uuidExif is "JpgTiffExif->JP2"
+	uuidExif = []byte("JpgTiffExif->JP2")
+	uuidXMP  = []byte{0xBE, 0x7A, 0xCF, 0xCB, 0x97, 0xA9, 0x42, 0xE8, 0x9C, 0x71, 0x99, 0x94, 0x91, 0xE3, 0xAF, 0xAC}
+)
+
+// exifPrefix is the JPEG APP1 Exif prefix written before the Tiff Header by some encoders.
+var exifPrefix = []byte("Exif\x00\x00")
+
+// Lengths
+const (
+	boxHeaderLength      = 8
+	boxLargeHeaderLength = 16
+	uuidLength           = 16
+	ihdrLength           = 14
+	colrLength           = 3
+	sizLength            = 2 + 2 + 2 + 8*4 + 2
+
+	// maxBoxLength is the largest XMP box that is read
+	maxBoxLength = 16 << 20
+)
+
+// Color specification methods
+const (
+	// MethodEnumerated is an enumerated color space
+	MethodEnumerated = 1
+	// MethodICC is a restricted ICC profile
+	MethodICC = 2
+	// MethodAnyICC is any ICC profile (JPX)
+	MethodAnyICC = 3
+)
+
+// ColorSpace is an enumerated color space of a color specification
+type ColorSpace uint32
+
+// Enumerated color spaces
+const (
+	ColorSpaceCMYK      ColorSpace = 12
+	ColorSpaceSRGB      ColorSpace = 16
+	ColorSpaceGreyscale ColorSpace = 17
+	ColorSpaceSYCC      ColorSpace = 18
+)
+
+func (cs ColorSpace) String() string {
+	switch cs {
+	case ColorSpaceCMYK:
+		return "CMYK"
+	case ColorSpaceSRGB:
+		return "sRGB"
+	case ColorSpaceGreyscale:
+		return "Greyscale"
+	case ColorSpaceSYCC:
+		return "sYCC"
+	}
+	return "Unknown"
+}
+
+// ColorSpec is the color specification of the "colr" box. The color space is an
+// enumerated ColorSpace or an ICC profile at ICCOffset with ICCLength.
+type ColorSpec struct {
+	Method        uint8
+	Precedence    int8
+	Approximation uint8
+
+	ColorSpace ColorSpace
+
+	ICCOffset, ICCLength uint32
+}
+
+// Metadata from a JPEG 2000 file
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+	XmpHeader  meta.XmpHeader
+
+	// ColorSpec is the first color specification of the header box
+	ColorSpec ColorSpec
+
+	// Components is the number of components of the image
+	Components uint16
+
+	// BitsPerComponent is the bit depth of the components, 0xFF if they
+	// have different bit depths.
+	BitsPerComponent uint8
+
+	// Codestream is true for a bare codestream (J2K) and false for a JP2 file
+	Codestream bool
+
+	// Decode Functions for EXIF and XMP metadata
+	exifFn func(r io.Reader, header meta.ExifHeader) error
+	xmpFn  func(r io.Reader, header meta.XmpHeader) error
+
+	width  uint32
+	height uint32
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.width, m.height)
+}
+
+// ImageType returns imagetype.ImageJP2K for JPEG 2000 image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageJP2K
+}
+
+// PreviewImage returns a JPEG 2000 preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from JPEG 2000
+func (m Metadata) Exif() (exif.Exif, error) {
+	if !m.ExifHeader.IsValid() {
+		return nil, ErrNoExif
+	}
+	return exif.ParseExif(m.mr, m.ExifHeader)
+}
+
+// Xmp returns parsed Xmp data from JPEG 2000
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	if m.XmpHeader.Length == 0 {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+// ICCProfile returns the ICC profile of the color specification.
+//
+// Returns ErrNoICCProfile if the color space is not an ICC profile.
+func (m Metadata) ICCProfile() ([]byte, error) {
+	if m.ColorSpec.ICCLength == 0 {
+		return nil, ErrNoICCProfile
+	}
+	buf := make([]byte, m.ColorSpec.ICCLength)
+	if _, err := m.mr.ReadAt(buf, int64(m.ColorSpec.ICCOffset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// ScanJP2 scans a reader for the JPEG 2000 header box and metadata boxes. xmpFn and exifFn
+// are run at their respective positions during the scan. Returns Metadata.
+//
+// Returns the error ErrNoJP2Signature if r is not a JPEG 2000 Image, ErrCorruptBox if a box
+// is truncated and ErrCorruptCodestream if the SIZ marker segment of a J2K is truncated.
+func ScanJP2(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = Metadata{mr: mr, exifFn: exifFn, xmpFn: xmpFn}
+
+	buf := make([]byte, len(jp2Signature))
+	n, _ := mr.ReadAt(buf, 0)
+	switch {
+	case bytes.HasPrefix(buf[:n], codestreamSignature):
+		m.Codestream = true
+		err = m.readSIZ(0)
+	case bytes.Equal(buf[:n], jp2Signature):
+		err = m.readBoxes(int64(len(jp2Signature)), -1)
+	default:
+		err = ErrNoJP2Signature
+	}
+	return
+}
+
+// boxHeader is the type, data offset and data length of a box
+type boxHeader struct {
+	typ    [4]byte
+	offset int64
+	length int64
+}
+
+// readBoxHeader reads the header of the box at offset. Returns io.EOF at the end of the file.
+func (m *Metadata) readBoxHeader(offset int64) (h boxHeader, err error) {
+	var buf [boxLargeHeaderLength]byte
+	n, err := m.mr.ReadAt(buf[:], offset)
+	if n == 0 && err == io.EOF {
+		return h, io.EOF
+	}
+	if n < boxHeaderLength {
+		return h, ErrCorruptBox
+	}
+	copy(h.typ[:], buf[4:8])
+	size := int64(jp2ByteOrder.Uint32(buf[:4]))
+	h.offset = offset + boxHeaderLength
+	switch size {
+	case 0:
+		// The last box extends to the end of the file
+		end, err := m.mr.Seek(0, io.SeekEnd)
+		if err != nil || end < h.offset {
+			return h, ErrCorruptBox
+		}
+		h.length = end - h.offset
+	case 1:
+		// 64 bit size
+		if n < boxLargeHeaderLength {
+			return h, ErrCorruptBox
+		}
+		large := jp2ByteOrder.Uint64(buf[8:16])
+		if large < boxLargeHeaderLength || large > 1<<62 {
+			return h, ErrCorruptBox
+		}
+		h.offset = offset + boxLargeHeaderLength
+		h.length = int64(large) - boxLargeHeaderLength
+	default:
+		if size < boxHeaderLength {
+			return h, ErrCorruptBox
+		}
+		h.length = size - boxHeaderLength
+	}
+	return h, nil
+}
+
+// readBoxes reads the boxes from offset to end, or to the end of the file when end is -1.
+// The boxes of the "jp2h" header box are read for the image header and color specification.
+func (m *Metadata) readBoxes(offset int64, end int64) (err error) {
+	for end < 0 || offset < end {
+		var h boxHeader
+		if h, err = m.readBoxHeader(offset); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		if end >= 0 && h.offset+h.length > end {
+			return ErrCorruptBox
+		}
+		switch h.typ {
+		case boxJP2H:
+			err = m.readBoxes(h.offset, h.offset+h.length)
+		case boxIHDR:
+			err = m.readIHDR(h)
+		case boxCOLR:
+			err = m.readCOLR(h)
+		case boxUUID:
+			err = m.readUUID(h)
+		case boxXML:
+			if m.XmpHeader.Length == 0 {
+				err = m.readXMP(h.offset, h.length)
+			}
+		case boxJP2C:
+			// The dimensions of the codestream when the header box is missing
+			if m.width == 0 && m.height == 0 {
+				err = m.readSIZ(h.offset)
+			}
+		}
+		if err != nil {
+			return
+		}
+		offset = h.offset + h.length
+	}
+	return nil
+}
+
+// readIHDR reads the image dimensions, the number of components and their bit depth.
+//
+// ihdr: height, width, components, bits per component, compression type,
+// colorspace unknown and intellectual property.
+func (m *Metadata) readIHDR(h boxHeader) error {
+	if h.length < ihdrLength {
+		return ErrCorruptBox
+	}
+	var buf [ihdrLength]byte
+	if _, err := m.mr.ReadAt(buf[:], h.offset); err != nil {
+		return ErrCorruptBox
+	}
+	m.height = jp2ByteOrder.Uint32(buf[0:4])
+	m.width = jp2ByteOrder.Uint32(buf[4:8])
+	m.Components = jp2ByteOrder.Uint16(buf[8:10])
+	m.BitsPerComponent = buf[10]
+	if m.BitsPerComponent != 0xFF {
+		// The bit depth minus one, the high bit is set for signed values
+		m.BitsPerComponent = m.BitsPerComponent&0x7F + 1
+	}
+	return nil
+}
+
+// readCOLR reads the first color specification.
+//
+// colr: method, precedence, approximation followed by the enumerated color space
+// or the ICC profile.
+func (m *Metadata) readCOLR(h boxHeader) error {
+	if m.ColorSpec.Method != 0 {
+		return nil
+	}
+	if h.length < colrLength {
+		return ErrCorruptBox
+	}
+	var buf [colrLength + 4]byte
+	n, _ := m.mr.ReadAt(buf[:], h.offset)
+	if n < colrLength {
+		return ErrCorruptBox
+	}
+	m.ColorSpec = ColorSpec{Method: buf[0], Precedence: int8(buf[1]), Approximation: buf[2]}
+	switch m.ColorSpec.Method {
+	case MethodEnumerated:
+		if h.length >= colrLength+4 && n == len(buf) {
+			m.ColorSpec.ColorSpace = ColorSpace(jp2ByteOrder.Uint32(buf[3:7]))
+		}
+	case MethodICC, MethodAnyICC:
+		m.ColorSpec.ICCOffset = uint32(h.offset + colrLength)
+		m.ColorSpec.ICCLength = uint32(h.length - colrLength)
+	}
+	return nil
+}
+
+// readUUID reads the Exif or XMP metadata of a "uuid" box.
+func (m *Metadata) readUUID(h boxHeader) error {
+	if h.length < uuidLength {
+		return nil
+	}
+	uuid := make([]byte, uuidLength)
+	if _, err := m.mr.ReadAt(uuid, h.offset); err != nil {
+		return ErrCorruptBox
+	}
+	switch {
+	case bytes.Equal(uuid, uuidExif):
+		return m.readExif(h.offset+uuidLength, h.length-uuidLength)
+	case bytes.Equal(uuid, uuidXMP):
+		return m.readXMP(h.offset+uuidLength, h.length-uuidLength)
+	}
+	return nil
+}
+
+// readExif reads the Exif header at offset with the attached metadata exifFn.
+func (m *Metadata) readExif(offset, length int64) error {
+	if length < int64(len(exifPrefix))+8 {
+		return nil
+	}
+	buf := make([]byte, len(exifPrefix)+8)
+	if _, err := m.mr.ReadAt(buf, offset); err != nil {
+		return ErrCorruptBox
+	}
+	// Some encoders write the JPEG APP1 "Exif\0\0" prefix before the Tiff Header
+	if bytes.HasPrefix(buf, exifPrefix) {
+		offset += int64(len(exifPrefix))
+		length -= int64(len(exifPrefix))
+		buf = buf[len(exifPrefix):]
+	}
+
+	// Create a TiffHeader from the Tiff directory ByteOrder, root IFD Offset,
+	// the tiff Header Offset, and the length of the exif information.
+	byteOrder := meta.BinaryOrder(buf)
+	if byteOrder == nil {
+		return nil
+	}
+	firstIfdOffset := byteOrder.Uint32(buf[4:8])
+	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, uint32(offset), uint32(length), imagetype.ImageJP2K)
+
+	// Read Exif
+	if m.exifFn != nil {
+		return m.exifFn(io.NewSectionReader(m.mr, offset, length), m.ExifHeader)
+	}
+	return nil
+}
+
+// readXMP reads the XMP packet at offset with the attached metadata xmpFn.
+func (m *Metadata) readXMP(offset, length int64) error {
+	if length > maxBoxLength {
+		return nil
+	}
+	m.XmpHeader = meta.NewXMPHeader(uint32(offset), uint32(length))
+	if m.xmpFn != nil {
+		return m.xmpFn(io.NewSectionReader(m.mr, offset, length), m.XmpHeader)
+	}
+	return nil
+}
+
+// readSIZ reads the image dimensions and the number of components from the SIZ
+// marker segment of the codestream at offset.
+//
+// SIZ: Lsiz, Rsiz, Xsiz, Ysiz, XOsiz, YOsiz, XTsiz, YTsiz, XTOsiz, YTOsiz and Csiz.
+// The image is the area from (XOsiz, YOsiz) to (Xsiz, Ysiz).
+func (m *Metadata) readSIZ(offset int64) error {
+	buf := make([]byte, len(codestreamSignature)+sizLength)
+	if _, err := m.mr.ReadAt(buf, offset); err != nil || !bytes.HasPrefix(buf, codestreamSignature) {
+		return ErrCorruptCodestream
+	}
+	siz := buf[len(codestreamSignature):]
+	x, y := jp2ByteOrder.Uint32(siz[4:8]), jp2ByteOrder.Uint32(siz[8:12])
+	xo, yo := jp2ByteOrder.Uint32(siz[12:16]), jp2ByteOrder.Uint32(siz[16:20])
+	if xo > x || yo > y {
+		return ErrCorruptCodestream
+	}
+	m.width, m.height = x-xo, y-yo
+	m.Components = jp2ByteOrder.Uint16(siz[36:38])
+	return nil
+}
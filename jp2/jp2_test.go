@@ -0,0 +1,180 @@
+package jp2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+func newBox(typ string, data []byte) []byte {
+	buf := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(8+len(data)))
+	copy(buf[4:], typ)
+	return append(buf, data...)
+}
+
+func newIHDR(width, height uint32, nc uint16, bpc uint8) []byte {
+	buf := make([]byte, ihdrLength)
+	binary.BigEndian.PutUint32(buf[0:], height)
+	binary.BigEndian.PutUint32(buf[4:], width)
+	binary.BigEndian.PutUint16(buf[8:], nc)
+	buf[10] = bpc - 1
+	buf[11] = 7
+	return newBox("ihdr", buf)
+}
+
+// newCodestream returns the SOC and SIZ markers of a codestream followed by empty image data.
+func newCodestream(width, height, xo, yo uint32, nc uint16) []byte {
+	buf := append([]byte{}, codestreamSignature...)
+	siz := make([]byte, sizLength)
+	binary.BigEndian.PutUint16(siz[0:], uint16(sizLength+3*int(nc)))
+	binary.BigEndian.PutUint32(siz[4:], width+xo)
+	binary.BigEndian.PutUint32(siz[8:], height+yo)
+	binary.BigEndian.PutUint32(siz[12:], xo)
+	binary.BigEndian.PutUint32(siz[16:], yo)
+	binary.BigEndian.PutUint32(siz[20:], width+xo)
+	binary.BigEndian.PutUint32(siz[24:], height+yo)
+	binary.BigEndian.PutUint16(siz[36:], nc)
+	return append(append(buf, siz...), make([]byte, 64)...)
+}
+
+func newExif(t *testing.T, prefix bool) []byte {
+	t.Helper()
+	b := exif.NewBuilder(nil)
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Model, "Canon EOS R5"); err != nil {
+		t.Fatal(err)
+	}
+	e, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := append([]byte{}, uuidExif...)
+	if prefix {
+		buf = append(buf, exifPrefix...)
+	}
+	return newBox("uuid", append(buf, e...))
+}
+
+func newJP2(boxes ...[]byte) []byte {
+	buf := append([]byte{}, jp2Signature...)
+	buf = append(buf, newBox("ftyp", []byte("jp2 \x00\x00\x00\x00jp2 "))...)
+	for _, box := range boxes {
+		buf = append(buf, box...)
+	}
+	return buf
+}
+
+func TestScanJP2(t *testing.T) {
+	packet, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 4, Label: "Red"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	icc := []byte("icc profile")
+	srgb := newBox("colr", []byte{MethodEnumerated, 0, 0, 0, 0, 0, 16})
+	codestream := newBox("jp2c", newCodestream(4000, 3000, 0, 0, 3))
+
+	tests := []struct {
+		name  string
+		buf   []byte
+		color ColorSpec
+	}{
+		{"enumerated", newJP2(newBox("jp2h", append(newIHDR(4000, 3000, 3, 8), srgb...)), newExif(t, false), newBox("uuid", append(append([]byte{}, uuidXMP...), packet...)), codestream), ColorSpec{Method: MethodEnumerated, ColorSpace: ColorSpaceSRGB}},
+		{"icc", newJP2(newBox("jp2h", append(newIHDR(4000, 3000, 3, 8), newBox("colr", append([]byte{MethodICC, 0, 0}, icc...))...)), newExif(t, true), newBox("xml ", packet), codestream), ColorSpec{Method: MethodICC, ICCOffset: 12 + 20 + 8 + 22 + 8 + 3, ICCLength: uint32(len(icc))}},
+		// The last box has a size of 0 and extends to the end of the file
+		{"last box", newJP2(newExif(t, true), newBox("xml ", packet), newBox("jp2h", append(newIHDR(4000, 3000, 3, 8), srgb...)), append([]byte{0, 0, 0, 0, 'j', 'p', '2', 'c'}, codestream[8:]...)), ColorSpec{Method: MethodEnumerated, ColorSpace: ColorSpaceSRGB}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if it, err := imagetype.ReadAt(bytes.NewReader(test.buf)); err != nil || it != imagetype.ImageJP2K {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageJP2K, it, err)
+			}
+			m, err := ScanJP2(bytes.NewReader(test.buf), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Codestream || m.Dimensions() != meta.NewDimensions(4000, 3000) {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 4000, 3000, m.Dimensions())
+			}
+			if m.Components != 3 || m.BitsPerComponent != 8 {
+				t.Errorf("Incorrect Components wanted %d@%d got %d@%d", 3, 8, m.Components, m.BitsPerComponent)
+			}
+			if m.ColorSpec != test.color {
+				t.Errorf("Incorrect ColorSpec wanted %+v got %+v", test.color, m.ColorSpec)
+			}
+			if profile, err := m.ICCProfile(); test.color.Method == MethodICC && !bytes.Equal(profile, icc) {
+				t.Errorf("Incorrect ICCProfile wanted %q got %q (%v)", icc, profile, err)
+			} else if test.color.Method != MethodICC && err != ErrNoICCProfile {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoICCProfile, err)
+			}
+			e, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.CameraModel() != "Canon EOS R5" {
+				t.Errorf("Incorrect CameraModel wanted %s got %s", "Canon EOS R5", e.CameraModel())
+			}
+			x, err := m.Xmp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Basic.Rating != 4 || x.Basic.Label != "Red" {
+				t.Errorf("Incorrect Xmp got %+v", x.Basic)
+			}
+		})
+	}
+}
+
+func TestCodestream(t *testing.T) {
+	tests := []struct {
+		name string
+		buf  []byte
+	}{
+		{"j2k", newCodestream(640, 480, 16, 8, 1)},
+		// JP2 without a header box
+		{"jp2c", newJP2(newBox("jp2c", newCodestream(640, 480, 16, 8, 1)))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if it, err := imagetype.ReadAt(bytes.NewReader(test.buf)); err != nil || it != imagetype.ImageJP2K {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageJP2K, it, err)
+			}
+			m, err := ScanJP2(bytes.NewReader(test.buf), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Dimensions() != meta.NewDimensions(640, 480) || m.Components != 1 {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 640, 480, m.Dimensions())
+			}
+			if _, err = m.Exif(); err != ErrNoExif {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+			}
+			if _, err = m.Xmp(); err != xmp.ErrNoXMP {
+				t.Errorf("Incorrect error wanted %v got %v", xmp.ErrNoXMP, err)
+			}
+		})
+	}
+}
+
+func TestScanJP2Errors(t *testing.T) {
+	if _, err := ScanJP2(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF}), nil, nil); err != ErrNoJP2Signature {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoJP2Signature, err)
+	}
+	// Truncated SIZ marker segment
+	if _, err := ScanJP2(bytes.NewReader(append(append([]byte{}, codestreamSignature...), 0, 41)), nil, nil); err != ErrCorruptCodestream {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptCodestream, err)
+	}
+	// Box size smaller than the box header
+	if _, err := ScanJP2(bytes.NewReader(newJP2([]byte{0, 0, 0, 4, 'j', 'p', '2', 'h'})), nil, nil); err != ErrCorruptBox {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptBox, err)
+	}
+	// Image header box larger than the header box
+	if _, err := ScanJP2(bytes.NewReader(newJP2(newBox("jp2h", newIHDR(1, 1, 1, 8)[:16]))), nil, nil); err != ErrCorruptBox {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptBox, err)
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// maxQuantizationTables is the number of quantization table destinations.
+const maxQuantizationTables = 4
+
+// QuantizationTable is a quantization table of a DQT segment.
+type QuantizationTable struct {
+	// ID is the destination identifier (0-3) of the table.
+	ID uint8
+
+	// Precision is the precision of the values in bits (8 or 16).
+	Precision uint8
+
+	// Values are the quantization values in natural (row-major) order
+	// of the 8x8 block.
+	Values [64]uint16
+}
+
+// ScanJPEGWithQuantization scans a reader for JPEG Image markers like ScanJPEG,
+// and keeps the quantization tables of the DQT segments of the image. The tables
+// are returned by Metadata.QuantizationTables.
+func ScanJPEGWithQuantization(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.readDQTs = true
+	err = m.scan()
+	return
+}
+
+// QuantizationTables returns the quantization tables of the image ordered by
+// their destination identifier. A table that is defined again replaces the
+// previous table with the same identifier. Returns nil if the image was not
+// scanned with ScanJPEGWithQuantization.
+func (m Metadata) QuantizationTables() []QuantizationTable {
+	var tables []QuantizationTable
+	for i := range m.dqt {
+		if m.dqt[i].Precision != 0 {
+			tables = append(tables, m.dqt[i])
+		}
+	}
+	return tables
+}
+
+// readDQT reads the quantization tables of a DQT segment of the image.
+// If the tables are not kept it discards the segment.
+func (m *Metadata) readDQT(buf []byte) (err error) {
+	if !m.readDQTs || m.pos != 1 {
+		return m.ignoreMarker(buf)
+	}
+	length := int(jpegByteOrder.Uint16(buf[2:4])) - 2
+	if length < 0 {
+		return m.discard(4)
+	}
+
+	// Discard Marker bytes and header length bytes
+	if err = m.discard(4); err != nil {
+		return err
+	}
+	b := make([]byte, length)
+	n, err := io.ReadFull(m.br, b)
+	m.discarded += uint32(n)
+	if err != nil {
+		return err
+	}
+
+	// Each table: precision and destination, followed by 64 values in zigzag order
+	for len(b) > 0 {
+		id, size := b[0]&0x0f, 1
+		t := QuantizationTable{ID: id, Precision: 8}
+		if b[0]>>4 != 0 {
+			t.Precision, size = 16, 2
+		}
+		if id >= maxQuantizationTables || len(b) < 1+64*size {
+			// Ignore invalid tables
+			return nil
+		}
+		for i := 0; i < 64; i++ {
+			if size == 2 {
+				t.Values[unzig[i]] = jpegByteOrder.Uint16(b[1+2*i:])
+			} else {
+				t.Values[unzig[i]] = uint16(b[1+i])
+			}
+		}
+		m.dqt[id] = t
+		b = b[1+64*size:]
+	}
+	return nil
+}
+
+// EstimateQuality returns the libjpeg quality factor (1-100) of the quantization
+// tables, and false if there are no tables. The quality is the quality of the
+// scaled standard tables of the JPEG specification (Annex K) that are closest to
+// the tables: table 0 is compared to the luminance table, and the other tables to
+// the chrominance table. The quality of tables of other encoders is approximate.
+func EstimateQuality(tables []QuantizationTable) (quality int, ok bool) {
+	if len(tables) == 0 {
+		return 0, false
+	}
+	best := -1
+	for q := 1; q <= 100; q++ {
+		scale := qualityScale(q)
+		diff := 0
+		for _, t := range tables {
+			std := &stdChrominanceTable
+			if t.ID == 0 {
+				std = &stdLuminanceTable
+			}
+			for i, v := range t.Values {
+				d := int(v) - scaleQuantizationValue(std[i], scale, t.Precision)
+				if d < 0 {
+					d = -d
+				}
+				diff += d
+			}
+		}
+		if best < 0 || diff <= best {
+			best, quality = diff, q
+		}
+	}
+	return quality, true
+}
+
+// qualityScale returns the libjpeg scale factor in percent of a quality factor.
+func qualityScale(quality int) int {
+	if quality < 50 {
+		return 5000 / quality
+	}
+	return 200 - quality*2
+}
+
+// scaleQuantizationValue scales a value of a standard table like libjpeg.
+// Values of 8 bit tables are limited to 255.
+func scaleQuantizationValue(value uint16, scale int, precision uint8) int {
+	v := (int(value)*scale + 50) / 100
+	if v < 1 {
+		return 1
+	}
+	if precision == 8 && v > 255 {
+		return 255
+	}
+	if v > 32767 {
+		return 32767
+	}
+	return v
+}
+
+// unzig maps the zigzag order of the values of a DQT segment to natural order.
+var unzig = [64]uint8{
+	0, 1, 8, 16, 9, 2, 3, 10,
+	17, 24, 32, 25, 18, 11, 4, 5,
+	12, 19, 26, 33, 40, 48, 41, 34,
+	27, 20, 13, 6, 7, 14, 21, 28,
+	35, 42, 49, 56, 57, 50, 43, 36,
+	29, 22, 15, 23, 30, 37, 44, 51,
+	58, 59, 52, 45, 38, 31, 39, 46,
+	53, 60, 61, 54, 47, 55, 62, 63,
+}
+
+// Standard quantization tables of the JPEG specification (Annex K) in natural order.
+var (
+	stdLuminanceTable = [64]uint16{
+		16, 11, 10, 16, 24, 40, 51, 61,
+		12, 12, 14, 19, 26, 58, 60, 55,
+		14, 13, 16, 24, 40, 57, 69, 56,
+		14, 17, 22, 29, 51, 87, 80, 62,
+		18, 22, 37, 56, 68, 109, 103, 77,
+		24, 35, 55, 64, 81, 104, 113, 92,
+		49, 64, 78, 87, 103, 121, 120, 101,
+		72, 92, 95, 98, 112, 100, 103, 99,
+	}
+	stdChrominanceTable = [64]uint16{
+		17, 18, 24, 47, 99, 99, 99, 99,
+		18, 21, 26, 66, 99, 99, 99, 99,
+		24, 26, 56, 99, 99, 99, 99, 99,
+		47, 66, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+		99, 99, 99, 99, 99, 99, 99, 99,
+	}
+)
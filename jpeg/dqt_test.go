@@ -0,0 +1,98 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// dqtTestSegment returns a DQT segment with the standard tables scaled to quality
+// like libjpeg. 16 bit tables are used when precision is 16.
+func dqtTestSegment(quality int, precision uint8) []byte {
+	seg := []byte{markerFirstByte, markerDQT, 0, 0}
+	scale := qualityScale(quality)
+	for id, std := range []*[64]uint16{&stdLuminanceTable, &stdChrominanceTable} {
+		if precision == 16 {
+			seg = append(seg, 0x10|byte(id))
+		} else {
+			seg = append(seg, byte(id))
+		}
+		for i := 0; i < 64; i++ {
+			v := scaleQuantizationValue(std[unzig[i]], scale, precision)
+			if precision == 16 {
+				seg = append(seg, byte(v>>8))
+			}
+			seg = append(seg, byte(v))
+		}
+	}
+	seg[2], seg[3] = byte((len(seg)-2)>>8), byte(len(seg)-2)
+	return seg
+}
+
+func TestScanJPEGQuantization(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		precision uint8
+		quality   int
+	}{
+		{"a2.jpg", readFile(t, "../assets/a2.jpg"), 8, 85},
+		{"Quality10", mpfTestImage(dqtTestSegment(10, 8), 64, 48), 8, 10},
+		{"Quality50", mpfTestImage(dqtTestSegment(50, 8), 64, 48), 8, 50},
+		{"Quality75", mpfTestImage(dqtTestSegment(75, 8), 64, 48), 8, 75},
+		{"Quality92", mpfTestImage(dqtTestSegment(92, 8), 64, 48), 8, 92},
+		{"Quality100", mpfTestImage(dqtTestSegment(100, 8), 64, 48), 8, 100},
+		{"16Bit", mpfTestImage(dqtTestSegment(5, 16), 64, 48), 16, 5},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []meta.Reader{bytes.NewReader(test.data), readerOnly{bytes.NewReader(test.data)}} {
+				m, err := ScanJPEGWithQuantization(r, nil, nil)
+				if err != nil && err != ErrNoExif {
+					t.Fatal(err)
+				}
+				tables := m.QuantizationTables()
+				if len(tables) != 2 {
+					t.Fatalf("Incorrect number of quantization tables wanted %d got %d", 2, len(tables))
+				}
+				for i, table := range tables {
+					if table.ID != uint8(i) || table.Precision != test.precision {
+						t.Errorf("Incorrect quantization table wanted %d %d got %d %d", i, test.precision, table.ID, table.Precision)
+					}
+				}
+				if q, ok := EstimateQuality(tables); !ok || q != test.quality {
+					t.Errorf("Incorrect quality wanted %d got %d %t", test.quality, q, ok)
+				}
+				if w, h := m.Dimensions().Size(); w == 0 || h == 0 {
+					t.Errorf("Incorrect Jpeg Image size got %dx%d", w, h)
+				}
+			}
+		})
+	}
+
+	// Values are in natural order
+	m, err := ScanJPEGWithQuantization(bytes.NewReader(mpfTestImage(dqtTestSegment(50, 8), 64, 48)), nil, nil)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	if tables := m.QuantizationTables(); tables[0].Values != stdLuminanceTable || tables[1].Values != stdChrominanceTable {
+		t.Errorf("Incorrect quantization table values got %v", tables)
+	}
+
+	// Tables are not kept by ScanJPEG
+	m, err = ScanJPEG(bytes.NewReader(readFile(t, "../assets/a2.jpg")), nil, nil)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	if m.QuantizationTables() != nil {
+		t.Errorf("Incorrect quantization tables wanted nil got %v", m.QuantizationTables())
+	}
+	if _, ok := EstimateQuality(nil); ok {
+		t.Errorf("Incorrect quality of no tables wanted %t got %t", false, ok)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// flirSegmentPrefix is the identifier of an APP1 FLIR segment. It is followed
+// by a version byte, the chunk number from 0 and the number of the last chunk.
+const flirSegmentPrefix = "FLIR\x00"
+
+// ScanJPEGWithFLIR scans a reader for JPEG Image markers like ScanJPEG. flirFn is run
+// after the scan with the FLIR File Format (FFF) data of a radiometric thermal image,
+// which is assembled from the chunks of the APP1 FLIR segments in the order of their
+// chunk numbers. The data can be decoded with flir.Decode. flirFn is not run when
+// chunks of the data are missing.
+func ScanJPEGWithFLIR(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error, flirFn func(fff []byte) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.flirFn = flirFn
+	err = m.scan()
+	return
+}
+
+// readFLIR reads the chunk of an APP1 FLIR segment when flirFn is set,
+// otherwise the segment is discarded. Chunks with an invalid chunk number are ignored.
+func (m *Metadata) readFLIR(buf []byte) (err error) {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if m.flirFn == nil || m.pos != 1 || length < 2+len(flirSegmentPrefix)+3 {
+		return m.ignoreMarker(buf)
+	}
+	// Version, chunk number and the number of the last chunk
+	seq, last := int(buf[4+len(flirSegmentPrefix)+1]), int(buf[4+len(flirSegmentPrefix)+2])
+
+	// Discard App Marker bytes, header length bytes, the FLIR prefix and the chunk header
+	if err = m.discard(4 + len(flirSegmentPrefix) + 3); err != nil {
+		return err
+	}
+	size := length - 2 - len(flirSegmentPrefix) - 3
+	if seq > last || (m.flirChunks != nil && len(m.flirChunks) != last+1) {
+		return m.discard(size)
+	}
+	if m.flirChunks == nil {
+		m.flirChunks = make([][]byte, last+1)
+	}
+	chunk := make([]byte, size)
+	n, err := io.ReadFull(m.br, chunk)
+	m.discarded += uint32(n)
+	m.flirChunks[seq] = chunk
+	return err
+}
+
+// readFLIRData joins the chunks of the FFF data and runs flirFn
+// with the data. flirFn is not run when chunks are missing.
+func (m *Metadata) readFLIRData() error {
+	if m.flirChunks == nil {
+		return nil
+	}
+	var fff []byte
+	for _, chunk := range m.flirChunks {
+		if chunk == nil {
+			return nil
+		}
+		fff = append(fff, chunk...)
+	}
+	return m.flirFn(fff)
+}
+
+// isFLIRPrefix returns true if buf[4:9] equals "FLIR\0",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isFLIRPrefix(buf []byte) bool {
+	return len(buf) >= 4+len(flirSegmentPrefix) && string(buf[4:4+len(flirSegmentPrefix)]) == flirSegmentPrefix
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// flirTestSegment returns an APP1 FLIR segment with a chunk of FFF data.
+func flirTestSegment(seq, last byte, chunk []byte) []byte {
+	length := 2 + len(flirSegmentPrefix) + 3 + len(chunk)
+	seg := []byte{markerFirstByte, markerAPP1, byte(length >> 8), byte(length)}
+	seg = append(seg, flirSegmentPrefix...)
+	seg = append(seg, 1, seq, last)
+	return append(seg, chunk...)
+}
+
+func TestScanJPEGWithFLIR(t *testing.T) {
+	fff := append([]byte("FFF\x00"), bytes.Repeat([]byte("thermal"), 40)...)
+
+	// The chunks are assembled in the order of their chunk numbers
+	app1 := append(flirTestSegment(1, 1, fff[100:]), flirTestSegment(0, 1, fff[:100])...)
+	data := mpfTestImage(app1, 64, 48)
+	for _, r := range []meta.Reader{bytes.NewReader(data), readerOnly{bytes.NewReader(data)}} {
+		var got []byte
+		m, err := ScanJPEGWithFLIR(r, nil, nil, func(b []byte) error {
+			got = b
+			return nil
+		})
+		if err != ErrNoExif {
+			t.Fatalf("Incorrect error wanted %v got %v", ErrNoExif, err)
+		}
+		if !bytes.Equal(got, fff) {
+			t.Errorf("Incorrect FFF data wanted %d bytes got %d bytes", len(fff), len(got))
+		}
+		if w, h := m.Dimensions().Size(); w != 64 || h != 48 {
+			t.Errorf("Incorrect Jpeg Image size wanted %dx%d got %dx%d", 64, 48, w, h)
+		}
+	}
+
+	// A missing chunk
+	called := false
+	if _, err := ScanJPEGWithFLIR(bytes.NewReader(mpfTestImage(flirTestSegment(1, 1, fff[100:]), 64, 48)), nil, nil, func(b []byte) error {
+		called = true
+		return nil
+	}); err != ErrNoExif {
+		t.Fatalf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+	if called {
+		t.Errorf("Incorrect flirFn wanted not called with a missing chunk")
+	}
+
+	// Without a FLIR function
+	m, err := ScanJPEG(bytes.NewReader(data), nil, nil)
+	if err != ErrNoExif {
+		t.Fatalf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+	if w, h := m.Dimensions().Size(); w != 64 || h != 48 {
+		t.Errorf("Incorrect Jpeg Image size wanted %dx%d got %dx%d", 64, 48, w, h)
+	}
+}
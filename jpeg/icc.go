@@ -0,0 +1,151 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"errors"
+	"io"
+)
+
+// maxICCChunkLength is the largest part of an ICC profile in an APP2 segment.
+// The segment has the ICC_PROFILE prefix, the chunk number and the number of chunks.
+var maxICCChunkLength = maxSegmentLength - 2 - len(iccSegmentPrefix) - 2
+
+// iccSegments returns the APP2 segments of an ICC profile. Chunks are numbered from 1.
+func iccSegments(profile []byte) ([][]byte, error) {
+	count := (len(profile) + maxICCChunkLength - 1) / maxICCChunkLength
+	if count == 0 {
+		count = 1
+	}
+	if count > 255 {
+		return nil, ErrSegmentTooLarge
+	}
+	segs := make([][]byte, 0, count)
+	prefix := make([]byte, len(iccSegmentPrefix)+2)
+	copy(prefix, iccSegmentPrefix)
+	for i := 0; i < count; i++ {
+		chunk := profile[i*maxICCChunkLength:]
+		if len(chunk) > maxICCChunkLength {
+			chunk = chunk[:maxICCChunkLength]
+		}
+		prefix[len(iccSegmentPrefix)] = byte(i + 1)
+		prefix[len(iccSegmentPrefix)+1] = byte(count)
+		seg, err := newSegment(markerAPP2, prefix, chunk)
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+// WriteICCProfile copies the JPEG from r to w with profile as its ICC profile.
+// The profile is split across APP2 ICC_PROFILE segments numbered from 1 that
+// replace the existing ICC profile segments, or are inserted after the SOI marker
+// and the APP0, Exif and XMP segments. The rest of the JPEG is copied unchanged.
+//
+// Returns ErrNoICCProfile if profile is empty and ErrSegmentTooLarge if
+// profile does not fit in 255 segments.
+func WriteICCProfile(r io.Reader, w io.Writer, profile []byte) error {
+	if len(profile) == 0 {
+		return ErrNoICCProfile
+	}
+	return Rewrite(r, w, RewriteOptions{ICC: profile})
+}
+
+// errEndOfHeader stops copySegments at the SOS marker.
+var errEndOfHeader = errors.New("end of JPEG header")
+
+// ReadICCProfile reads the ICC profile from the APP2 segments of the JPEG in r.
+// The chunks of the profile are joined in the order of their chunk numbers.
+//
+// Returns ErrNoICCProfile if the JPEG does not have an ICC profile and
+// ErrCorruptSegment if chunks are missing.
+func ReadICCProfile(r io.Reader) ([]byte, error) {
+	var chunks [][]byte
+	err := copySegments(r, io.Discard, func(w io.Writer, marker byte, seg []byte) error {
+		if marker == markerSOS {
+			return errEndOfHeader
+		}
+		if !isICCSegment(seg) {
+			return nil
+		}
+		n := 4 + len(iccSegmentPrefix)
+		if len(seg) < n+2 || seg[n] == 0 || seg[n] > seg[n+1] {
+			return ErrCorruptSegment
+		}
+		if chunks == nil {
+			chunks = make([][]byte, seg[n+1])
+		}
+		if int(seg[n+1]) != len(chunks) {
+			return ErrCorruptSegment
+		}
+		chunks[seg[n]-1] = seg[n+2:]
+		return nil
+	}, false)
+	if err != nil && err != errEndOfHeader {
+		return nil, err
+	}
+	if chunks == nil {
+		return nil, ErrNoICCProfile
+	}
+	var profile []byte
+	for _, chunk := range chunks {
+		if chunk == nil {
+			return nil, ErrCorruptSegment
+		}
+		profile = append(profile, chunk...)
+	}
+	return profile, nil
+}
+
+// readICC reads the chunk of an APP2 ICC_PROFILE segment when iccFn is set,
+// otherwise the segment is discarded. Chunks with an invalid chunk number are ignored.
+func (m *Metadata) readICC(buf []byte) (err error) {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if m.iccFn == nil || m.pos != 1 || length < 2+len(iccSegmentPrefix)+2 {
+		return m.ignoreMarker(buf)
+	}
+	// Discard App Marker bytes, header length bytes and the ICC_PROFILE prefix
+	if err = m.discard(4 + len(iccSegmentPrefix)); err != nil {
+		return err
+	}
+	// Chunk number from 1 and the number of chunks
+	if buf, err = m.br.Peek(2); err != nil {
+		return err
+	}
+	seq, count := int(buf[0]), int(buf[1])
+	if err = m.discard(2); err != nil {
+		return err
+	}
+	size := length - 2 - len(iccSegmentPrefix) - 2
+	if seq == 0 || seq > count || (m.iccChunks != nil && len(m.iccChunks) != count) {
+		return m.discard(size)
+	}
+	if m.iccChunks == nil {
+		m.iccChunks = make([][]byte, count)
+	}
+	chunk := make([]byte, size)
+	n, err := io.ReadFull(m.br, chunk)
+	m.discarded += uint32(n)
+	m.iccChunks[seq-1] = chunk
+	return err
+}
+
+// readICCProfile joins the chunks of the ICC profile and runs iccFn
+// with the profile. iccFn is not run when chunks are missing.
+func (m *Metadata) readICCProfile() error {
+	if m.iccChunks == nil {
+		return nil
+	}
+	var profile []byte
+	for _, chunk := range m.iccChunks {
+		if chunk == nil {
+			return nil
+		}
+		profile = append(profile, chunk...)
+	}
+	return m.iccFn(profile)
+}
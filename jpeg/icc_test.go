@@ -0,0 +1,207 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+func TestReadICCProfile(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := ReadICCProfile(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ICC profile header: profile size and "acsp" signature
+	if size := int(jpegByteOrder.Uint32(profile)); size != len(profile) {
+		t.Errorf("Incorrect ICC profile size wanted %d got %d", size, len(profile))
+	}
+	if string(profile[36:40]) != "acsp" {
+		t.Errorf("Incorrect ICC profile signature got %q", profile[36:40])
+	}
+
+	buf, err = os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadICCProfile(bytes.NewReader(buf)); err != ErrNoICCProfile {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoICCProfile, err)
+	}
+}
+
+func TestScanJPEGWithICC(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ReadICCProfile(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A profile larger than a segment is split in chunks
+	large := make([]byte, maxICCChunkLength*2+100)
+	for i := range large {
+		large[i] = byte(i)
+	}
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{ICC: large}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name    string
+		buf     []byte
+		profile []byte
+	}{
+		{"JPEG", buf, want},
+		{"Chunks", out.Bytes(), large},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []meta.Reader{bytes.NewReader(test.buf), readerOnly{bytes.NewReader(test.buf)}} {
+				calls := 0
+				m, err := ScanJPEGWithICC(r, nil, nil, func(profile []byte) error {
+					calls++
+					if !bytes.Equal(profile, test.profile) {
+						t.Errorf("Incorrect ICC profile length wanted %d got %d", len(test.profile), len(profile))
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if calls != 1 {
+					t.Errorf("Incorrect number of iccFn calls wanted %d got %d", 1, calls)
+				}
+				if !m.ExifHeader.IsValid() {
+					t.Errorf("Exif was not read")
+				}
+			}
+		})
+	}
+
+	// iccFn is not run without an ICC profile
+	buf, err = os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ScanJPEGWithICC(bytes.NewReader(buf), nil, nil, func(profile []byte) error {
+		t.Errorf("iccFn should not be called without an ICC profile")
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRewriteICC(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A profile larger than a segment is split in chunks
+	profile := make([]byte, maxICCChunkLength*2+100)
+	for i := range profile {
+		profile[i] = byte(i)
+	}
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{ICC: profile}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{markerSOI, markerAPP1, markerAPP1, markerAPP13, markerAPP2, markerAPP2, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}
+	if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers, want) {
+		t.Errorf("Incorrect markers wanted %x got %x", want, markers)
+	}
+	icc, err := ReadICCProfile(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(profile, icc) {
+		t.Errorf("ICC profile should be read unchanged")
+	}
+
+	// Inserted after the Exif and XMP segments
+	var stripped bytes.Buffer
+	if err = Strip(bytes.NewReader(buf), &stripped, StripOptions{KeepExif: true}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err = Rewrite(bytes.NewReader(stripped.Bytes()), &out, RewriteOptions{ICC: profile[:100]}); err != nil {
+		t.Fatal(err)
+	}
+	want = []byte{markerSOI, markerAPP1, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}
+	if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers, want) {
+		t.Errorf("Incorrect markers wanted %x got %x", want, markers)
+	}
+
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{ICC: make([]byte, maxICCChunkLength*255+1)}); err != ErrSegmentTooLarge {
+		t.Errorf("Incorrect error wanted %v got %v", ErrSegmentTooLarge, err)
+	}
+}
+
+func TestICCSegments(t *testing.T) {
+	profile := make([]byte, maxICCChunkLength*2+1)
+	segs, err := iccSegments(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segs) != 3 {
+		t.Fatalf("Incorrect number of segments wanted %d got %d", 3, len(segs))
+	}
+	n := 4 + len(iccSegmentPrefix)
+	for i, seg := range segs {
+		if !isICCSegment(seg) {
+			t.Errorf("Segment %d should be an ICC segment", i)
+		}
+		if seg[n] != byte(i+1) || seg[n+1] != 3 {
+			t.Errorf("Incorrect chunk numbering of segment %d got %d of %d", i, seg[n], seg[n+1])
+		}
+		if length := int(jpegByteOrder.Uint16(seg[2:4])); length != len(seg)-2 {
+			t.Errorf("Incorrect length of segment %d wanted %d got %d", i, len(seg)-2, length)
+		}
+	}
+	if len(segs[2]) != n+2+1 {
+		t.Errorf("Incorrect length of the last segment got %d", len(segs[2]))
+	}
+}
+
+func TestWriteICCProfile(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile, err = ReadICCProfile(bytes.NewReader(profile)); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = WriteICCProfile(bytes.NewReader(buf), &out, profile); err != nil {
+		t.Fatal(err)
+	}
+	icc, err := ReadICCProfile(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(profile, icc) {
+		t.Errorf("ICC profile should be read unchanged")
+	}
+	// Inserted after the APP0 and APP1 segments
+	want := []byte{markerSOI, markerAPP0, markerAPP1, markerAPP1, markerAPP2, markerAPP13, markerSOF0}
+	if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers[:len(want)], want) {
+		t.Errorf("Incorrect markers wanted %x got %x", want, markers[:len(want)])
+	}
+
+	if err = WriteICCProfile(bytes.NewReader(buf), &out, nil); err != ErrNoICCProfile {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoICCProfile, err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+// jfifHeaderLength is the length of the APP0 marker, the segment length, "JFIF\0",
+// version (2), density units (1), density (4) and thumbnail size (2).
+const jfifHeaderLength = 4 + 5 + 2 + 1 + 4 + 2
+
+// DensityUnits is the unit of the pixel density of a JFIF segment.
+type DensityUnits uint8
+
+// Density Units
+const (
+	// DensityUnitsNone is a pixel aspect ratio without units
+	DensityUnitsNone       DensityUnits = 0
+	DensityUnitsInch       DensityUnits = 1
+	DensityUnitsCentimeter DensityUnits = 2
+)
+
+func (du DensityUnits) String() string {
+	switch du {
+	case DensityUnitsNone:
+		return "None"
+	case DensityUnitsInch:
+		return "Inch"
+	case DensityUnitsCentimeter:
+		return "Centimeter"
+	}
+	return "Unknown"
+}
+
+// JFIF is the header of an APP0 JFIF segment.
+type JFIF struct {
+	// Version is the major and minor version, ie. 0x0102 for 1.02
+	Version uint16
+
+	Units              DensityUnits
+	XDensity, YDensity uint16
+
+	// ThumbnailWidth and ThumbnailHeight are the size of the uncompressed
+	// thumbnail of the JFIF segment.
+	ThumbnailWidth, ThumbnailHeight uint8
+
+	// Thumbnail is true if the JFIF segment or an APP0 JFXX extension
+	// segment has a thumbnail.
+	Thumbnail bool
+}
+
+// DPI returns the horizontal and vertical resolution in dots per inch.
+// Returns 0 if the density does not have units.
+func (j JFIF) DPI() (x, y float64) {
+	switch j.Units {
+	case DensityUnitsInch:
+		return float64(j.XDensity), float64(j.YDensity)
+	case DensityUnitsCentimeter:
+		return float64(j.XDensity) * 2.54, float64(j.YDensity) * 2.54
+	}
+	return 0, 0
+}
+
+// JFIF returns the header of the APP0 JFIF segment
+// and true if the segment is present.
+func (m Metadata) JFIF() (JFIF, bool) {
+	return m.jfif, m.hasJFIF
+}
+
+// readAPP0 reads the header of an APP0 JFIF segment and the
+// thumbnail of an APP0 JFXX segment, and discards the segment.
+func (m *Metadata) readAPP0(buf []byte) error {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if m.pos != 1 {
+		return m.discard(length + 2)
+	}
+	switch {
+	case isJFIFPrefix(buf) && length+2 >= jfifHeaderLength:
+		// Peek may move the buffer of the peekReader, buf is not used after Peek
+		b, err := m.br.Peek(jfifHeaderLength)
+		if err != nil {
+			return err
+		}
+		m.jfif = JFIF{
+			Version:         jpegByteOrder.Uint16(b[9:11]),
+			Units:           DensityUnits(b[11]),
+			XDensity:        jpegByteOrder.Uint16(b[12:14]),
+			YDensity:        jpegByteOrder.Uint16(b[14:16]),
+			ThumbnailWidth:  b[16],
+			ThumbnailHeight: b[17],
+			Thumbnail:       m.jfif.Thumbnail || (b[16] > 0 && b[17] > 0),
+		}
+		m.hasJFIF = true
+	case isJFXXPrefix(buf) && length > 2+5:
+		// JFXX extension code and thumbnail
+		m.jfif.Thumbnail = true
+	}
+	return m.discard(length + 2)
+}
+
+// isJFIFPrefix returns true if
+// buf[4:9] equals "JFIF\000",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isJFIFPrefix(buf []byte) bool {
+	return buf[4] == 0x4a &&
+		buf[5] == 0x46 &&
+		buf[6] == 0x49 &&
+		buf[7] == 0x46 &&
+		buf[8] == 0x00
+}
+
+// isJFXXPrefix returns true if
+// buf[4:9] equals "JFXX\000",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isJFXXPrefix(buf []byte) bool {
+	return buf[4] == 0x4a &&
+		buf[5] == 0x46 &&
+		buf[6] == 0x58 &&
+		buf[7] == 0x58 &&
+		buf[8] == 0x00
+}
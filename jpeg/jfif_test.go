@@ -0,0 +1,66 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+func TestScanJPEGJFIF(t *testing.T) {
+	// APP0 JFIF with 118x118 dots per centimeter and a 2x1 thumbnail,
+	// followed by an APP0 JFXX extension with a JPEG thumbnail.
+	jfif := []byte{markerFirstByte, markerAPP0, 0, 22, 'J', 'F', 'I', 'F', 0, 1, 2, 2, 0, 118, 0, 118, 2, 1, 0, 0, 0, 0, 0, 0}
+	jfxx := []byte{markerFirstByte, markerAPP0, 0, 10, 'J', 'F', 'X', 'X', 0, 0x10, markerFirstByte, markerSOI}
+	noThumbnail := []byte{markerFirstByte, markerAPP0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 2, 2, 0, 118, 0, 118, 0, 0}
+
+	tests := []struct {
+		name  string
+		data  []byte
+		jfif  JFIF
+		ok    bool
+		dpiX  float64
+		dpiY  float64
+		units string
+	}{
+		{"NoExif.jpg", readFile(t, "../assets/NoExif.jpg"), JFIF{Version: 0x0101, Units: DensityUnitsInch, XDensity: 72, YDensity: 72}, true, 72, 72, "Inch"},
+		{"a2.jpg", readFile(t, "../assets/a2.jpg"), JFIF{Version: 0x0101, XDensity: 1, YDensity: 1}, true, 0, 0, "None"},
+		{"JPEG.jpg", readFile(t, "../assets/JPEG.jpg"), JFIF{}, false, 0, 0, "None"},
+		{"Thumbnail", mpfTestImage(jfif, 64, 48), JFIF{Version: 0x0102, Units: DensityUnitsCentimeter, XDensity: 118, YDensity: 118, ThumbnailWidth: 2, ThumbnailHeight: 1, Thumbnail: true}, true, 299.72, 299.72, "Centimeter"},
+		{"JFXX", mpfTestImage(append(noThumbnail, jfxx...), 64, 48), JFIF{Version: 0x0102, Units: DensityUnitsCentimeter, XDensity: 118, YDensity: 118, Thumbnail: true}, true, 299.72, 299.72, "Centimeter"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []meta.Reader{bytes.NewReader(test.data), readerOnly{bytes.NewReader(test.data)}} {
+				m, err := ScanJPEG(r, nil, nil)
+				if err != nil && err != ErrNoExif {
+					t.Fatal(err)
+				}
+				jfif, ok := m.JFIF()
+				if jfif != test.jfif || ok != test.ok {
+					t.Errorf("Incorrect JFIF wanted %+v %t got %+v %t", test.jfif, test.ok, jfif, ok)
+				}
+				if x, y := jfif.DPI(); x != test.dpiX || y != test.dpiY {
+					t.Errorf("Incorrect DPI wanted %v %v got %v %v", test.dpiX, test.dpiY, x, y)
+				}
+				if jfif.Units.String() != test.units {
+					t.Errorf("Incorrect DensityUnits wanted %s got %s", test.units, jfif.Units)
+				}
+			}
+		})
+	}
+}
+
+func readFile(t *testing.T, name string) []byte {
+	t.Helper()
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
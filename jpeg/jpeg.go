@@ -6,9 +6,9 @@
 package jpeg
 
 import (
-	"bufio"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/evanoberholster/imagemeta/exif"
@@ -22,6 +22,28 @@ var (
 	ErrNoExif       = meta.ErrNoExif
 	ErrNoJPEGMarker = errors.New("no JPEG Marker")
 	ErrEndOfImage   = errors.New("end of Image")
+
+	// ErrUnexpectedEOF is returned when the JPEG ends in the middle of
+	// a segment after a valid SOI marker was read (ie. truncated file).
+	ErrUnexpectedEOF = fmt.Errorf("truncated JPEG: %w", io.ErrUnexpectedEOF)
+
+	// ErrCorruptSegment is returned when the length of an Exif or XMP
+	// segment is smaller than its header.
+	ErrCorruptSegment = errors.New("corrupt JPEG segment: length too short")
+
+	// ErrInvalidMarker is a warning of ScanJPEGTolerant for bytes that
+	// were skipped because they are not a JPEG marker.
+	ErrInvalidMarker = errors.New("invalid JPEG marker")
+
+	// ErrSegmentTooLarge is returned when metadata does not fit in
+	// a single JPEG segment.
+	ErrSegmentTooLarge = errors.New("JPEG segment too large")
+
+	// ErrNoICCProfile is returned when a JPEG does not have an ICC profile.
+	ErrNoICCProfile = errors.New("no ICC profile")
+
+	// ErrNoIPTC is returned when a JPEG does not have an IPTC-NAA record.
+	ErrNoIPTC = errors.New("no IPTC record")
 )
 
 // Metadata from a JPEG file
@@ -34,11 +56,65 @@ type Metadata struct {
 	exifFn func(r io.Reader, header meta.ExifHeader) error
 	xmpFn  func(r io.Reader, header meta.XmpHeader) error
 
+	// Comment Function and the text of the COM segments
+	commentFn func(comment string) error
+	comments  []string
+
+	// ICC Profile Function and the chunks of the APP2 ICC_PROFILE segments
+	iccFn     func(profile []byte) error
+	iccChunks [][]byte
+
+	// FLIR Function and the chunks of the APP1 FLIR segments
+	flirFn     func(fff []byte) error
+	flirChunks [][]byte
+
+	// APP Function for APPn segments
+	appFn func(seg Segment) error
+
+	// IPTC Function and the Image Resource Blocks of the APP13 Photoshop segments
+	iptcFn    func(record []byte) error
+	photoshop []byte
+
 	// SOF Header and Tiff Header
 	sofHeader
 
+	// Adobe APP14 color transform
+	adobeTransform ColorTransform
+	adobe          bool
+
+	// Tolerant scan warnings and the bytes skipped before the next marker
+	warnings   []error
+	tolerant   bool
+	skipped    uint32
+	skipOffset uint32
+
+	// DQT quantization tables
+	dqt      [maxQuantizationTables]QuantizationTable
+	readDQTs bool
+
+	// DRI restart interval
+	restartInterval uint16
+
+	// APP0 JFIF header
+	jfif    JFIF
+	hasJFIF bool
+
+	// Images of the APP2 Multi-Picture Format segment
+	mpImages []MPImage
+
+	// Chunks of the APP1 XMP Extension segments and the Extended XMP
+	// packet of the main XMP packet
+	xmpExtensions   []extendedXMP
+	extendedXmp     []byte
+	extendedXmpGUID string
+
+	// Stereo descriptor of the APP3 JPS segment
+	jps    JPSDescriptor
+	hasJPS bool
+
 	// Reader
-	br        *bufio.Reader
+	br        peekReader
+	start     uint32
 	discarded uint32
 	pos       uint8
 }
@@ -48,6 +124,24 @@ func (m Metadata) Dimensions() meta.Dimensions {
 	return meta.NewDimensions(uint32(m.width), uint32(m.height))
 }
 
+// Components returns the number of color components of the image.
+// 1 for grayscale, 3 for YCbCr or RGB and 4 for CMYK or YCCK images.
+func (m Metadata) Components() uint8 {
+	return m.components
+}
+
+// AdobeTransform returns the color transform of the Adobe APP14 segment
+// and true if the segment is present.
+func (m Metadata) AdobeTransform() (ColorTransform, bool) {
+	return m.adobeTransform, m.adobe
+}
+
+// RestartInterval returns the restart interval of the DRI segment
+// in MCUs, or 0 if restart markers are not used.
+func (m Metadata) RestartInterval() uint16 {
+	return m.restartInterval
+}
+
 // ImageType returns imagetype.ImageJPEG for JPEG image
 func (m Metadata) ImageType() imagetype.ImageType {
 	return imagetype.ImageJPEG
@@ -61,6 +155,9 @@ func (m Metadata) PreviewImage() io.Reader {
 
 // Exif returns parsed Exif data from JPEG
 func (m Metadata) Exif() (exif.Exif, error) {
+	if _, ok := m.mr.(*streamReader); ok {
+		return nil, ErrStreamNotSeekable
+	}
 	return exif.ParseExif(m.mr, m.ExifHeader)
 }
 
@@ -71,47 +168,173 @@ func (m Metadata) Xmp() (xmp.XMP, error) {
 }
 
 func newMetdata(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) Metadata {
-	br := bufio.NewReaderSize(mr, 64)
-
-	return Metadata{mr: mr, br: br, exifFn: exifFn, xmpFn: xmpFn}
+	m := Metadata{mr: mr, br: newPeekReader(mr), exifFn: exifFn, xmpFn: xmpFn}
+	if pos, err := mr.Seek(0, io.SeekCurrent); err == nil {
+		m.start = uint32(pos)
+	}
+	return m
 }
 
 // ScanJPEG scans a reader for JPEG Image markers. xmpDecodeFn and exifDecodeFn are run at their respective
 // positions during the scan. Returns Metadata.
 //
-// Returns the error ErrNoJPEGMarker if a JPEG SOF was not found.
+// The chunks of APP1 XMP Extension segments are assembled into an Extended XMP
+// packet. xmpDecodeFn is run a second time with the Extended XMP packet after
+// the scan when its GUID matches the main XMP packet.
+//
+// Returns the error ErrNoJPEGMarker if a JPEG SOF was not found, ErrUnexpectedEOF
+// if the reader ended before the end of the image after a valid SOI marker was found,
+// and ErrCorruptSegment if an Exif or XMP segment is shorter than its header.
 func ScanJPEG(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	return ScanJPEGWithComments(mr, exifFn, xmpFn, nil)
+}
+
+// ScanJPEGWithComments scans a reader for JPEG Image markers like ScanJPEG. commentFn is run
+// with the text of each JPEG comment (COM) segment. An error returned by commentFn
+// stops the scan and is returned.
+func ScanJPEGWithComments(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error, commentFn func(comment string) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.commentFn = commentFn
+	err = m.scan()
+	return
+}
+
+// ScanJPEGWithICC scans a reader for JPEG Image markers like ScanJPEG. iccFn is run
+// after the scan with the ICC profile that is assembled from the chunks of the
+// APP2 ICC_PROFILE segments in the order of their chunk numbers. iccFn is not run
+// when chunks of the profile are missing.
+func ScanJPEGWithICC(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error, iccFn func(profile []byte) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.iccFn = iccFn
+	err = m.scan()
+	return
+}
+
+// ScanJPEGWithIPTC scans a reader for JPEG Image markers like ScanJPEG. iptcFn is run
+// after the scan with the IPTC-NAA record (resource 0x0404) of the Image Resource Blocks
+// of the APP13 Photoshop segments. The record can be decoded with iptc.Decode.
+// iptcFn is not run when the JPEG does not have an IPTC-NAA record. Returns
+// ErrCorruptSegment when an Image Resource Block is truncated.
+func ScanJPEGWithIPTC(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error, iptcFn func(record []byte) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.iptcFn = iptcFn
+	err = m.scan()
+	return
+}
+
+// ScanJPEGWithAPP scans a reader for JPEG Image markers like ScanJPEG. appFn is run
+// with each APPn segment (APP0 to APP15) of the image before the segment is scanned,
+// which includes segments with unknown prefixes. The reader of the Segment reads
+// the payload from mr without copying the segment, and does not change the position
+// of the scan. An error returned by appFn stops the scan and is returned.
+func ScanJPEGWithAPP(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error, appFn func(seg Segment) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.appFn = appFn
+	err = m.scan()
+	return
+}
+
+// scan scans the reader of m for JPEG Image markers.
+func (m *Metadata) scan() (err error) {
 	defer func() {
 		if state := recover(); state != nil {
 			err = state.(error)
 		}
 	}()
-	m = newMetdata(mr, exifFn, xmpFn)
 
 	var buf []byte
 	for {
 		if buf, err = m.br.Peek(16); err != nil {
+			m.resync()
+			if m.pos > 0 && !isEOIMarker(buf) {
+				if m.tolerant {
+					m.warn(ErrUnexpectedEOF)
+					err = nil
+					break
+				}
+				err = ErrUnexpectedEOF
+				return
+			}
+			if m.tolerant && m.pos > 0 {
+				err = nil
+				break
+			}
 			err = ErrNoJPEGMarker
 			return
 		}
 
 		if !isMarkerFirstByte(buf) {
+			if m.tolerant && m.pos > 0 {
+				m.skip()
+			}
 			_ = m.discard(1)
 			continue
 		}
+		m.resync()
 		if isSOIMarker(buf) {
 			m.pos++
 			_ = m.discard(2)
 			continue
 		}
 		if m.pos > 0 {
-			if err := m.scanMarkers(buf); err == nil {
+			if m.tolerant && !m.validSegment(buf) {
+				// Resynchronize to the next marker after the invalid segment marker
+				_ = m.discard(2)
 				continue
 			}
+			if err = m.scanMarkers(buf); err == nil {
+				continue
+			}
+			if ce, ok := err.(callbackError); ok {
+				err = ce.err
+				return
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				if m.tolerant {
+					m.warn(ErrUnexpectedEOF)
+					err = nil
+					break
+				}
+				err = ErrUnexpectedEOF
+				return
+			}
+			if err == ErrCorruptSegment {
+				if !m.tolerant {
+					return
+				}
+				// Skip the segment, the reader has not moved since the marker
+				m.warn(err)
+				if buf, err = m.br.Peek(4); err == nil {
+					if err = m.ignoreMarker(buf); err == nil {
+						continue
+					}
+				}
+			}
+			err = nil
 		}
 
 		break
 	}
+	if err = m.readExtendedXMP(); err != nil {
+		return
+	}
+	if err = m.readICCProfile(); err != nil {
+		if !m.tolerant || err != ErrCorruptSegment {
+			return
+		}
+		m.warn(err)
+		err = nil
+	}
+	if err = m.readIPTC(); err != nil {
+		if !m.tolerant || err != ErrCorruptSegment {
+			return
+		}
+		m.warn(err)
+		err = nil
+	}
+	if err = m.readFLIRData(); err != nil {
+		return
+	}
 	if !m.ExifHeader.IsValid() {
 		err = ErrNoExif
 		return
@@ -119,20 +342,39 @@ func ScanJPEG(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) e
 	return
 }
 
+// callbackError is an error returned by a function of the caller during the
+// scan of the markers. It stops the scan and is returned unchanged by scan.
+type callbackError struct {
+	err error
+}
+
+func (ce callbackError) Error() string {
+	return ce.err.Error()
+}
+
 func (m *Metadata) scanMarkers(buf []byte) (err error) {
+	if m.appFn != nil && isAPPMarker(buf[1]) && m.pos == 1 {
+		if length := jpegByteOrder.Uint16(buf[2:4]); length >= 2 {
+			if err = m.appFn(Segment{Marker: buf[1], Offset: m.offset(), Length: uint32(length - 2), r: m.mr}); err != nil {
+				return callbackError{err}
+			}
+		}
+	}
 	switch buf[1] {
 	case markerSOF0, markerSOF1,
 		markerSOF2, markerSOF3,
 		markerSOF5, markerSOF6,
 		markerSOF7, markerSOF9,
-		markerSOF10:
+		markerSOF10, markerSOF11:
 		return m.readSOF(buf)
-	case markerDHT:
-		// Artificial End Of Image for DHT Marker.
-		// This is done to improve performance.
+	case markerSOS:
+		// Artificial End Of Image for SOS Marker. The image data
+		// follows and is not scanned to improve performance.
 		if m.pos == 1 {
 			return ErrEndOfImage
 		}
+		return m.discard(2)
+	case markerDHT:
 		// Ignore DHT Markers
 		return m.ignoreMarker(buf)
 	case markerSOI:
@@ -146,16 +388,22 @@ func (m *Metadata) scanMarkers(buf []byte) (err error) {
 		}
 		return m.discard(2)
 	case markerDQT:
-		// Ignore DQT Markers
-		return m.ignoreMarker(buf)
+		return m.readDQT(buf)
 	case markerDRI:
-		return m.discard(6)
+		return m.readDRI(buf)
 	case markerAPP0:
-		return m.ignoreMarker(buf)
+		return m.readAPP0(buf)
 	case markerAPP2:
 		if isICCProfilePrefix(buf) {
-			// Ignore ICC Profile Marker
-			return m.ignoreMarker(buf)
+			return m.readICC(buf)
+		}
+		if isMPFPrefix(buf) {
+			return m.readMPF(buf)
+		}
+		return m.ignoreMarker(buf)
+	case markerAPP3:
+		if isJPSPrefix(buf) {
+			return m.readJPS(buf)
 		}
 		return m.ignoreMarker(buf)
 	case markerAPP7, markerAPP8,
@@ -163,19 +411,24 @@ func (m *Metadata) scanMarkers(buf []byte) (err error) {
 		return m.ignoreMarker(buf)
 	case markerAPP13:
 		if isPhotoshopPrefix(buf) {
-			// Ignore Photoshop Profile Marker
-			return m.ignoreMarker(buf)
+			return m.readPhotoshop(buf)
 		}
 		return m.ignoreMarker(buf)
 	case markerAPP14:
-		return m.ignoreMarker(buf)
+		return m.readAPP14(buf)
 	case markerAPP1:
 		return m.readAPP1(buf)
+	case markerCOM:
+		return m.readComment(buf)
+	}
+	if isAPPMarker(buf[1]) {
+		// Ignore other APP Markers
+		return m.ignoreMarker(buf)
 	}
 	return m.discard(1)
 }
 
-// discard adds to m.discarded and discards from the underlying bufio.Reader
+// discard adds to m.discarded and discards from the underlying peekReader
 func (m *Metadata) discard(i int) (err error) {
 	if i == 0 {
 		return
@@ -185,30 +438,58 @@ func (m *Metadata) discard(i int) (err error) {
 	return
 }
 
+// offset returns the position of the reader from the start of mr.
+func (m *Metadata) offset() uint32 {
+	if ra, ok := m.br.(*readerAt); ok {
+		return uint32(ra.pos)
+	}
+	return m.start + m.discarded
+}
+
 // readAPP1
 func (m *Metadata) readAPP1(buf []byte) (err error) {
 	// APP1 XML Marker
 	if isXMPPrefix(buf) {
+		// APP1 XMP Extension Marker has the same prefix
+		if b, err := m.br.Peek(4 + xmpExtensionPrefixLength); err == nil && isXMPExtensionPrefix(b) {
+			return m.readXMPExtension(b)
+		}
+		// Peek may have moved the buffer of the peekReader
+		if buf, err = m.br.Peek(16); err != nil {
+			return err
+		}
 		return m.readXMP(buf)
 	}
 	// APP1 Exif Marker
 	if isJpegExifPrefix(buf) {
 		return m.readExif(buf)
 	}
-	return nil
+	// APP1 FLIR Marker
+	if isFLIRPrefix(buf) {
+		return m.readFLIR(buf)
+	}
+	return m.ignoreMarker(buf)
 }
 
 // readExif reads the Exif header/component with the addtached metadata
 // ExifDecodeFn. If the function is nil it discards the exif length.
 func (m *Metadata) readExif(buf []byte) (err error) {
 	// Read the length of the Exif Information
-	remain := int(jpegByteOrder.Uint16(buf[2:4]) - exifPrefixLength)
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if length < exifPrefixLength {
+		return ErrCorruptSegment
+	}
+	remain := length - exifPrefixLength
 
 	// Discard App Marker bytes and Exif header bytes
 	if err = m.discard(2 + exifPrefixLength); err != nil {
 		return err
 	}
 
+	// The Tiff Header offset is taken from the reader position after the
+	// Exif prefix, not from the segments that preceded it.
+	tiffHeaderOffset := m.offset()
+
 	// Peek at TiffHeader information
 	if buf, err = m.br.Peek(exifPrefixLength); err != nil {
 		return err
@@ -217,20 +498,26 @@ func (m *Metadata) readExif(buf []byte) (err error) {
 	// Create a TiffHeader from the Tiff directory ByteOrder, root IFD Offset,
 	// the tiff Header Offset, and the length of the exif information.
 	byteOrder := meta.BinaryOrder(buf)
+	if byteOrder == nil {
+		// Not a Tiff Header, ignore the segment
+		return m.discard(remain)
+	}
 	firstIfdOffset := byteOrder.Uint32(buf[4:8])
 	exifLength := uint32(remain)
 
 	// Set Tiff Header
-	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, m.discarded, exifLength, imagetype.ImageJPEG)
+	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, tiffHeaderOffset, exifLength, imagetype.ImageJPEG)
 
 	// Read Exif
 	if m.exifFn != nil {
 		r := io.LimitReader(m.br, int64(exifLength))
-		if err = m.exifFn(r, m.ExifHeader); err != nil {
+		err = m.exifFn(r, m.ExifHeader)
+		// Bytes read by exifFn are no longer in the reader
+		remain = int(r.(*io.LimitedReader).N)
+		m.discarded += exifLength - uint32(remain)
+		if err != nil {
 			return err
 		}
-		// Discard remaining bytes
-		remain = int(r.(*io.LimitedReader).N)
 	}
 
 	// Discard remaining bytes
@@ -241,39 +528,132 @@ func (m *Metadata) readExif(buf []byte) (err error) {
 // XmpDecodeFn. If the function is nil it discards the exif length.
 func (m *Metadata) readXMP(buf []byte) (err error) {
 	// Read the length of the XMPHeader
-	remain := int(jpegByteOrder.Uint16(buf[2:4])) - 2 - xmpPrefixLength
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if length < 2+xmpPrefixLength {
+		return ErrCorruptSegment
+	}
+	remain := length - 2 - xmpPrefixLength
 
 	// Discard App Marker bytes and header length bytes
 	if err = m.discard(4 + xmpPrefixLength); err != nil {
 		return err
 	}
-	m.XmpHeader = meta.NewXMPHeader(m.discarded, uint32(remain))
+	m.XmpHeader = meta.NewXMPHeader(m.offset(), uint32(remain))
 
 	// Read XMP Decode Function here
 	if m.xmpFn != nil {
 		r := io.LimitReader(m.br, int64(remain))
-		if err = m.xmpFn(r, m.XmpHeader); err != nil {
+		err = m.xmpFn(r, m.XmpHeader)
+		// Bytes read by xmpFn are no longer in the reader
+		n := int(r.(*io.LimitedReader).N)
+		m.discarded += uint32(remain - n)
+		remain = n
+		if err != nil {
 			return err
 		}
-		// Discard remaining bytes
-		remain = int(r.(*io.LimitedReader).N)
 	}
 
 	// Discard remaining bytes
 	return m.discard(remain)
 }
 
+// Comments returns the text of the JPEG comment (COM) segments of the
+// image in file order. Comments of embedded thumbnails are not included.
+func (m Metadata) Comments() []string {
+	return m.comments
+}
+
+// readComment reads the text of a JPEG comment (COM) segment and runs the
+// attached commentFn. Comments of the image are kept, comments of embedded
+// images are discarded if the function is nil.
+func (m *Metadata) readComment(buf []byte) (err error) {
+	if m.commentFn == nil && m.pos != 1 {
+		return m.ignoreMarker(buf)
+	}
+	// Read the length of the Comment
+	length := int(jpegByteOrder.Uint16(buf[2:4])) - 2
+	if length < 0 {
+		return m.discard(4)
+	}
+
+	// Discard Marker bytes and header length bytes
+	if err = m.discard(4); err != nil {
+		return err
+	}
+	comment := make([]byte, length)
+	n, err := io.ReadFull(m.br, comment)
+	m.discarded += uint32(n)
+	if err != nil {
+		return err
+	}
+
+	// A null terminator is not guaranteed
+	for len(comment) > 0 && comment[len(comment)-1] == 0 {
+		comment = comment[:len(comment)-1]
+	}
+	text := string(comment)
+	if m.pos == 1 {
+		m.comments = append(m.comments, text)
+	}
+	if m.commentFn == nil {
+		return nil
+	}
+	if err = m.commentFn(text); err != nil {
+		return callbackError{err}
+	}
+	return nil
+}
+
+// readAPP14 reads the color transform of an Adobe APP14 segment
+// and discards the segment.
+func (m *Metadata) readAPP14(buf []byte) error {
+	if m.pos == 1 && isAdobePrefix(buf) && jpegByteOrder.Uint16(buf[2:4]) >= adobeSegmentLength {
+		m.adobeTransform = ColorTransform(buf[15])
+		m.adobe = true
+	}
+	return m.ignoreMarker(buf)
+}
+
+// readDRI reads the restart interval of a JPEG DRI segment
+// and discards the segment.
+func (m *Metadata) readDRI(buf []byte) error {
+	if m.pos == 1 {
+		m.restartInterval = jpegByteOrder.Uint16(buf[4:6])
+	}
+	return m.discard(6)
+}
+
 // readSOF reads a JPEG Start of file with the uint16
-// width, height, and components of the JPEG image.
+// width, height, components, the sample precision and the
+// sampling factors of the components of the JPEG image.
 func (m *Metadata) readSOF(buf []byte) error {
 	length := int(jpegByteOrder.Uint16(buf[2:4]))
-	height := jpegByteOrder.Uint16(buf[5:7])
-	width := jpegByteOrder.Uint16(buf[7:9])
-	comp := uint8(buf[9])
-	header := sofHeader{height, width, comp}
-	if m.pos == 1 {
-		m.sofHeader = header
+	if m.pos != 1 {
+		return m.discard(length + 2)
 	}
+	header := sofHeader{
+		height:     jpegByteOrder.Uint16(buf[5:7]),
+		width:      jpegByteOrder.Uint16(buf[7:9]),
+		components: uint8(buf[9]),
+		sofMarker:  buf[1],
+		precision:  buf[4],
+	}
+	// Component: identifier, sampling factors and quantization table
+	n := int(header.components)
+	if n > maxSOFComponents {
+		n = maxSOFComponents
+	}
+	if length >= 8+3*n {
+		// Peek may move the buffer of the peekReader, buf is not used after Peek
+		b, err := m.br.Peek(10 + 3*n)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			header.sampling[i] = b[11+3*i]
+		}
+	}
+	m.sofHeader = header
 	return m.discard(length + 2)
 }
 
@@ -310,34 +690,123 @@ const (
 	markerEOI = 0xD9
 	markerDQT = 0xDB
 	markerDRI = 0xDD
+	markerSOS = 0xDA
 
 	// APP Markers
 	markerAPP0  = 0xE0
 	markerAPP1  = 0xE1
 	markerAPP2  = 0xE2
+	markerAPP3  = 0xE3
 	markerAPP7  = 0xE7
 	markerAPP8  = 0xE8
 	markerAPP9  = 0xE9
 	markerAPP10 = 0xEA
 	markerAPP13 = 0xED
 	markerAPP14 = 0xEE
+
+	// Comment Marker
+	markerCOM = 0xFE
 )
 
 // Prefix lengths
 const (
 	xmpPrefixLength  = 29
 	exifPrefixLength = 8
+
+	// adobeSegmentLength is the length of an Adobe APP14 segment: length (2), "Adobe" (5),
+	// version (2), flags0 (2), flags1 (2) and color transform (1)
+	adobeSegmentLength = 14
 )
 
+// ColorTransform is the color transform of an Adobe APP14 segment.
+type ColorTransform uint8
+
+// Color Transforms
+const (
+	// ColorTransformUnknown is RGB for 3 components and CMYK for 4 components
+	ColorTransformUnknown ColorTransform = 0
+	ColorTransformYCbCr   ColorTransform = 1
+	ColorTransformYCCK    ColorTransform = 2
+)
+
+func (ct ColorTransform) String() string {
+	switch ct {
+	case ColorTransformUnknown:
+		return "Unknown"
+	case ColorTransformYCbCr:
+		return "YCbCr"
+	case ColorTransformYCCK:
+		return "YCCK"
+	}
+	return "Invalid"
+}
+
+// ColorSpace is the color space of the components of a JPEG image.
+type ColorSpace uint8
+
+// Color Spaces
+const (
+	ColorSpaceUnknown ColorSpace = iota
+	ColorSpaceGray
+	ColorSpaceYCbCr
+	ColorSpaceRGB
+	ColorSpaceCMYK
+	ColorSpaceYCCK
+)
+
+func (cs ColorSpace) String() string {
+	switch cs {
+	case ColorSpaceGray:
+		return "Gray"
+	case ColorSpaceYCbCr:
+		return "YCbCr"
+	case ColorSpaceRGB:
+		return "RGB"
+	case ColorSpaceCMYK:
+		return "CMYK"
+	case ColorSpaceYCCK:
+		return "YCCK"
+	}
+	return "Unknown"
+}
+
+// ColorSpace returns the color space of the image from the number of
+// components and the color transform of the Adobe APP14 segment.
+//
+// 3 component images are YCbCr unless the Adobe color transform is 0 (RGB).
+// 4 component images are CMYK unless the Adobe color transform is 2 (YCCK).
+func (m Metadata) ColorSpace() ColorSpace {
+	switch m.components {
+	case 1:
+		return ColorSpaceGray
+	case 3:
+		if m.adobe && m.adobeTransform == ColorTransformUnknown {
+			return ColorSpaceRGB
+		}
+		return ColorSpaceYCbCr
+	case 4:
+		if m.adobe && m.adobeTransform == ColorTransformYCCK {
+			return ColorSpaceYCCK
+		}
+		return ColorSpaceCMYK
+	}
+	return ColorSpaceUnknown
+}
+
 // jpegByteOrder JPEG always uses a BigEndian byteorder inside the JPEG image.
 // Can use either byteorder for Exif Information inside the JPEG image.
 var jpegByteOrder = binary.BigEndian
 
-// sofHeader contains height, width and number of components.
+// sofHeader contains height, width and number of components,
+// the SOF marker, the sample precision and the sampling factors
+// of the components.
 type sofHeader struct {
 	height     uint16
 	width      uint16
 	components uint8
+	sofMarker  uint8
+	precision  uint8
+	sampling   [maxSOFComponents]uint8
 }
 
 // isSOIMarker returns true if the first 2 bytes match an SOI marker
@@ -346,12 +815,24 @@ func isSOIMarker(buf []byte) bool {
 		buf[1] == markerSOI
 }
 
+// isEOIMarker returns true if the first 2 bytes match an EOI marker
+func isEOIMarker(buf []byte) bool {
+	return len(buf) >= 2 &&
+		buf[0] == markerFirstByte &&
+		buf[1] == markerEOI
+}
+
+// isAPPMarker returns true if marker is an APPn marker (APP0 to APP15).
+func isAPPMarker(marker byte) bool {
+	return marker >= markerAPP0 && marker <= 0xEF
+}
+
 func isMarkerFirstByte(buf []byte) bool {
 	return buf[0] == markerFirstByte
 }
 
 // PhotoshopPrefix returns true if
-// buf[4:14] equals "Photoshop 3.0\000",
+// buf[4:16] equals "Photoshop 3." of "Photoshop 3.0\000",
 // buf[0:2] is AppMarker, buf[2:4] is HeaderLength
 func isPhotoshopPrefix(buf []byte) bool {
 	return buf[4] == 0x50 &&
@@ -359,12 +840,24 @@ func isPhotoshopPrefix(buf []byte) bool {
 		buf[6] == 0x6f &&
 		buf[7] == 0x74 &&
 		buf[8] == 0x6f &&
-		buf[9] == 0x70 &&
-		buf[10] == 0x20 &&
-		buf[11] == 0x33 &&
-		buf[12] == 0x2e &&
-		buf[13] == 0x30 &&
-		buf[14] == 0x00
+		buf[9] == 0x73 &&
+		buf[10] == 0x68 &&
+		buf[11] == 0x6f &&
+		buf[12] == 0x70 &&
+		buf[13] == 0x20 &&
+		buf[14] == 0x33 &&
+		buf[15] == 0x2e
+}
+
+// isAdobePrefix returns true if
+// buf[4:9] equals "Adobe",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isAdobePrefix(buf []byte) bool {
+	return buf[4] == 0x41 &&
+		buf[5] == 0x64 &&
+		buf[6] == 0x6f &&
+		buf[7] == 0x62 &&
+		buf[8] == 0x65
 }
 
 // isICCProfilePrefix returns true if
@@ -384,6 +877,23 @@ func isICCProfilePrefix(buf []byte) bool {
 		buf[14] == 0x45
 }
 
+// isMPFPrefix returns true if
+// buf[4:8] equals "MPF\000",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isMPFPrefix(buf []byte) bool {
+	return buf[4] == 0x4d &&
+		buf[5] == 0x50 &&
+		buf[6] == 0x46 &&
+		buf[7] == 0x00
+}
+
+// isJPSPrefix returns true if
+// buf[4:12] equals "_JPSJPS_",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isJPSPrefix(buf []byte) bool {
+	return string(buf[4:12]) == jpsPrefix
+}
+
 // isXMPPrefix returns true if
 // buf[4:15] equals "http://ns.adobe.com/xap/1.0/\000",
 // buf[0:2] is AppMarker, buf[2:4] is HeaderLength
@@ -8,11 +8,14 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/evanoberholster/imagemeta/exif"
 	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 )
@@ -177,3 +180,341 @@ func TestScanMarkers(t *testing.T) {
 		t.Errorf("Incorrect JPEG error at discarded %d wanted %s got %s", m.discarded, ErrNoJPEGMarker, err.Error())
 	}
 }
+
+func TestScanJPEGTruncated(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTruncated := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"NotJPEG", []byte("not a jpeg image, just some text"), ErrNoJPEGMarker},
+		{"ShortFile", buf[:10], ErrNoJPEGMarker},
+		{"APP1Header", buf[:20], ErrUnexpectedEOF},
+		{"MidExif", buf[:400], ErrUnexpectedEOF},
+	}
+	for _, tt := range testTruncated {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ScanJPEG(bytes.NewReader(tt.data), nil, nil)
+			if err != tt.err {
+				t.Errorf("Incorrect JPEG error wanted %v got %v", tt.err, err)
+			}
+			if tt.err == ErrUnexpectedEOF && !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("Incorrect JPEG error wanted wrapped %v got %v", io.ErrUnexpectedEOF, err)
+			}
+		})
+	}
+}
+
+func TestScanJPEGComments(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := []string{"Provenance: legacy archive", string(bytes.Repeat([]byte("0123456789"), 20))}
+
+	// Insert COM segments after the SOI marker. The first comment is null terminated.
+	data := append([]byte{}, buf[:2]...)
+	for i, c := range comments {
+		seg := []byte(c)
+		if i == 0 {
+			seg = append(seg, 0)
+		}
+		data = append(data, markerFirstByte, markerCOM, 0, 0)
+		binary.BigEndian.PutUint16(data[len(data)-2:], uint16(len(seg)+2))
+		data = append(data, seg...)
+	}
+	data = append(data, buf[2:]...)
+
+	readers := []struct {
+		name string
+		r    meta.Reader
+	}{
+		{"ReaderAt", bytes.NewReader(data)},
+		{"Bufio", readerOnly{bytes.NewReader(data)}},
+	}
+	for _, rt := range readers {
+		t.Run(rt.name, func(t *testing.T) {
+			var got []string
+			m, err := ScanJPEGWithComments(rt.r, nil, nil, func(comment string) error {
+				got = append(got, comment)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(comments) {
+				t.Fatalf("Incorrect number of comments wanted %d got %d", len(comments), len(got))
+			}
+			for i := range comments {
+				if got[i] != comments[i] {
+					t.Errorf("Incorrect comment wanted %q got %q", comments[i], got[i])
+				}
+			}
+			if w, h := m.Dimensions().Size(); w != 389 || h != 259 {
+				t.Errorf("Incorrect Jpeg Image size wanted %dx%d got %dx%d", 389, 259, w, h)
+			}
+		})
+	}
+
+	// An error of commentFn is returned
+	errStop := errors.New("stop")
+	if _, err := ScanJPEGWithComments(bytes.NewReader(data), nil, nil, func(comment string) error {
+		return errStop
+	}); err != errStop {
+		t.Errorf("Incorrect error wanted %v got %v", errStop, err)
+	}
+
+	// Without a comment function
+	m, err := ScanJPEG(bytes.NewReader(data), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Comments(); len(got) != len(comments) || got[0] != comments[0] || got[1] != comments[1] {
+		t.Errorf("Incorrect comments wanted %q got %q", comments, got)
+	}
+
+	// Without COM segments
+	if m, err = ScanJPEG(bytes.NewReader(buf), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m.Comments() != nil {
+		t.Errorf("Incorrect comments wanted nil got %q", m.Comments())
+	}
+}
+
+func TestScanJPEGMultipleAPP1(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a1.jpg has an APP0 segment at 2 and an APP1 Exif segment at 20 with a length of 760.
+	app1 := buf[20 : 20+2+760]
+
+	// Repeat the APP1 Exif segment after the first one.
+	data := append([]byte{}, buf[:20+len(app1)]...)
+	data = append(data, app1...)
+	data = append(data, buf[20+len(app1):]...)
+	wanted := []uint32{30, 30 + uint32(len(app1))}
+
+	readers := []struct {
+		name string
+		r    func() meta.Reader
+	}{
+		{"ReaderAt", func() meta.Reader { return bytes.NewReader(data) }},
+		{"Bufio", func() meta.Reader { return readerOnly{bytes.NewReader(data)} }},
+	}
+	for _, rt := range readers {
+		t.Run(rt.name, func(t *testing.T) {
+			var headers []meta.ExifHeader
+			// exifFn reads the Exif data from the reader
+			exifFn := func(r io.Reader, header meta.ExifHeader) error {
+				headers = append(headers, header)
+				b, err := io.ReadAll(r)
+				if uint32(len(b)) != header.ExifLength {
+					t.Errorf("Incorrect Exif length wanted %d got %d", header.ExifLength, len(b))
+				}
+				return err
+			}
+			m, err := ScanJPEG(rt.r(), exifFn, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(headers) != len(wanted) {
+				t.Fatalf("Incorrect number of Exif headers wanted %d got %d", len(wanted), len(headers))
+			}
+			for i, h := range headers {
+				if h.TiffHeaderOffset != wanted[i] {
+					t.Errorf("Incorrect tiff Header Offset wanted %d got %d", wanted[i], h.TiffHeaderOffset)
+				}
+				if meta.BinaryOrder(data[h.TiffHeaderOffset:]) == nil {
+					t.Errorf("Incorrect tiff Header at offset %d", h.TiffHeaderOffset)
+				}
+			}
+			if w, h := m.Dimensions().Size(); w != 389 || h != 259 {
+				t.Errorf("Incorrect Jpeg Image size wanted %dx%d got %dx%d", 389, 259, w, h)
+			}
+
+			// The last Exif header can be parsed
+			e, err := exif.ParseExif(bytes.NewReader(data), m.ExifHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = e.DateTime(nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	// Reader that does not start at the beginning of the file
+	r := bytes.NewReader(append(make([]byte, 16), data...))
+	if _, err = r.Seek(16, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	m, err := ScanJPEG(readerOnly{r}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ExifHeader.TiffHeaderOffset != 16+wanted[1] {
+		t.Errorf("Incorrect tiff Header Offset wanted %d got %d", 16+wanted[1], m.ExifHeader.TiffHeaderOffset)
+	}
+}
+
+func TestScanJPEGComponents(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ScanJPEG(bytes.NewReader(buf), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Components() != 3 {
+		t.Errorf("Incorrect Jpeg Components wanted %d got %d", 3, m.Components())
+	}
+	if _, ok := m.AdobeTransform(); ok {
+		t.Errorf("Incorrect Jpeg Adobe APP14 segment wanted %v got %v", false, ok)
+	}
+	if m.ColorSpace() != ColorSpaceYCbCr {
+		t.Errorf("Incorrect Jpeg ColorSpace wanted %s got %s", ColorSpaceYCbCr, m.ColorSpace())
+	}
+
+	// Insert an Adobe APP14 segment after the SOI marker and set the number of
+	// components in the SOF segment (at 4678 in a1.jpg) to 4.
+	app14 := []byte{markerFirstByte, markerAPP14, 0, 14, 'A', 'd', 'o', 'b', 'e', 0, 100, 0, 0, 0, 0, byte(ColorTransformYCCK)}
+	data := append([]byte{}, buf[:2]...)
+	data = append(data, app14...)
+	data = append(data, buf[2:]...)
+	data[4678+len(app14)+9] = 4
+
+	m, err = ScanJPEG(bytes.NewReader(data), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Components() != 4 {
+		t.Errorf("Incorrect Jpeg Components wanted %d got %d", 4, m.Components())
+	}
+	ct, ok := m.AdobeTransform()
+	if !ok || ct != ColorTransformYCCK {
+		t.Errorf("Incorrect Jpeg Adobe Transform wanted %s got %s (%v)", ColorTransformYCCK, ct, ok)
+	}
+	if m.ColorSpace() != ColorSpaceYCCK {
+		t.Errorf("Incorrect Jpeg ColorSpace wanted %s got %s", ColorSpaceYCCK, m.ColorSpace())
+	}
+
+	// An Adobe color transform of 0 is CMYK for 4 components and RGB for 3 components
+	data[2+15] = byte(ColorTransformUnknown)
+	for _, test := range []struct {
+		components byte
+		cs         ColorSpace
+	}{{4, ColorSpaceCMYK}, {3, ColorSpaceRGB}, {1, ColorSpaceGray}} {
+		data[4678+len(app14)+9] = test.components
+		if m, err = ScanJPEG(bytes.NewReader(data), nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		if m.ColorSpace() != test.cs || m.ColorSpace().String() != test.cs.String() {
+			t.Errorf("Incorrect Jpeg ColorSpace wanted %s got %s", test.cs, m.ColorSpace())
+		}
+	}
+	if ct.String() != "YCCK" || ColorTransformUnknown.String() != "Unknown" || ColorTransformYCbCr.String() != "YCbCr" || ColorTransform(5).String() != "Invalid" {
+		t.Errorf("Incorrect ColorTransform String")
+	}
+}
+
+func TestScanJPEGRestartInterval(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ScanJPEG(bytes.NewReader(buf), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a1.jpg has a DRI segment at 5267 after the DHT segments.
+	if m.RestartInterval() != 25 {
+		t.Errorf("Incorrect Jpeg RestartInterval wanted %d got %d", 25, m.RestartInterval())
+	}
+
+	// Insert a DRI segment before the APP1 Exif segment (at 20 in a1.jpg)
+	// and set the restart interval of the DRI segment of a1.jpg to 320.
+	dri := []byte{markerFirstByte, markerDRI, 0, 4, 0x01, 0x40}
+	data := append([]byte{}, buf[:20]...)
+	data = append(data, dri...)
+	data = append(data, buf[20:]...)
+	copy(data[5267+len(dri)+4:], dri[4:])
+
+	exifFound := false
+	exifFn := func(r io.Reader, header meta.ExifHeader) error {
+		exifFound = true
+		return nil
+	}
+	m, err = ScanJPEG(bytes.NewReader(data), exifFn, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.RestartInterval() != 320 {
+		t.Errorf("Incorrect Jpeg RestartInterval wanted %d got %d", 320, m.RestartInterval())
+	}
+	if !exifFound {
+		t.Errorf("Exif after DRI segment was not read")
+	}
+}
+
+func TestScanJPEGAppSegmentBeforeExif(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	markers := []byte{markerAPP7, markerAPP8, markerAPP9, markerAPP10, markerAPP14}
+	for _, marker := range markers {
+		t.Run(fmt.Sprintf("0x%02x", marker), func(t *testing.T) {
+			// Insert an APP segment before the APP1 Exif segment (at 20 in a1.jpg).
+			app := []byte{markerFirstByte, marker, 0, 8, 'T', 'e', 's', 't', 0, 0}
+			data := append([]byte{}, buf[:20]...)
+			data = append(data, app...)
+			data = append(data, buf[20:]...)
+
+			m, err := ScanJPEG(bytes.NewReader(data), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.ExifHeader.TiffHeaderOffset != 30+uint32(len(app)) {
+				t.Errorf("Incorrect Exif TiffHeaderOffset wanted %d got %d", 30+len(app), m.ExifHeader.TiffHeaderOffset)
+			}
+		})
+	}
+}
+
+func TestScanJPEGCorruptSegment(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		segment []byte
+	}{
+		{"XMP", append([]byte{markerFirstByte, markerAPP1, 0, 10}, "http://ns.adobe.com/xap/1.0/\x00"...)},
+		{"Exif", []byte{markerFirstByte, markerAPP1, 0, 4, 'E', 'x', 'i', 'f', 0, 0, 'M', 'M', 0, 42, 0, 0, 0, 8}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Insert the segment before the APP1 Exif segment (at 20 in a1.jpg).
+			data := append([]byte{}, buf[:20]...)
+			data = append(data, test.segment...)
+			data = append(data, buf[20:]...)
+
+			xmpFn := func(r io.Reader, header meta.XmpHeader) error {
+				t.Errorf("xmpFn should not be called for a corrupt segment")
+				return nil
+			}
+			if _, err := ScanJPEG(bytes.NewReader(data), nil, xmpFn); err != ErrCorruptSegment {
+				t.Errorf("Incorrect error wanted %v got %v", ErrCorruptSegment, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// ErrNoMotionPhoto is returned when a JPEG does not have the video of a motion photo.
+var ErrNoMotionPhoto = errors.New("no motion photo video")
+
+// MotionPhotoVideo returns a reader of the MP4 video of a Google or Samsung motion
+// photo in r, which has size bytes. The position of the video is read from the XMP
+// of the JPEG: the GCamera:MicroVideoOffset from the end of the file, or the item
+// of the Container directory with the "MotionPhoto" semantic. Without XMP, or when
+// the XMP does not point to an MP4 video, the first video trailer after the EOI
+// marker of the image is returned, see Trailers.
+//
+// Returns the errors of ScanJPEG and Trailers, and ErrNoMotionPhoto if the JPEG
+// does not have a video.
+func MotionPhotoVideo(r io.ReaderAt, size int64) (*io.SectionReader, error) {
+	var gc xmp.GCamera
+	_, err := ScanJPEG(io.NewSectionReader(r, 0, size), nil, func(xr io.Reader, header meta.XmpHeader) error {
+		if x, err := xmp.ParseXmp(xr); err == nil && (x.GCamera.MicroVideo != 0 || x.GCamera.MotionPhoto != 0) {
+			gc = x.GCamera
+		}
+		return nil
+	})
+	if err != nil && err != ErrNoExif {
+		return nil, err
+	}
+	if offset, length, ok := motionPhotoOffset(gc, size); ok && isVideo(r, offset) {
+		return io.NewSectionReader(r, offset, length), nil
+	}
+
+	trailers, err := Trailers(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trailers {
+		if t.Kind == TrailerVideo {
+			return io.NewSectionReader(r, int64(t.Offset), int64(t.Length)), nil
+		}
+	}
+	return nil, ErrNoMotionPhoto
+}
+
+// motionPhotoOffset returns the offset and the length of the video of the
+// GCamera metadata of a file of size bytes.
+func motionPhotoOffset(gc xmp.GCamera, size int64) (offset, length int64, ok bool) {
+	if gc.MotionPhoto != 0 {
+		// The items are concatenated up to the end of the file
+		end := size
+		for i := len(gc.Items) - 1; i > 0; i-- {
+			item := gc.Items[i]
+			offset = end - item.Length - item.Padding
+			if item.Semantic == "MotionPhoto" {
+				return offset, item.Length, offset > 0 && item.Length > 0
+			}
+			end = offset
+		}
+	}
+	if gc.MicroVideo != 0 && gc.MicroVideoOffset > 0 && gc.MicroVideoOffset < size {
+		return size - gc.MicroVideoOffset, gc.MicroVideoOffset, true
+	}
+	return 0, 0, false
+}
+
+// isVideo returns true if an MP4 or QuickTime "ftyp" box is at offset of r.
+func isVideo(r io.ReaderAt, offset int64) bool {
+	var buf [8]byte
+	n, _ := r.ReadAt(buf[:], offset)
+	return n == len(buf) && string(buf[4:8]) == "ftyp"
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// motionPhotoXMP returns an XMP packet with the GCamera properties.
+func motionPhotoXMP(properties string) []byte {
+	return []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:GCamera="http://ns.google.com/photos/1.0/camera/"
+    xmlns:Container="http://ns.google.com/photos/1.0/container/"
+    xmlns:Item="http://ns.google.com/photos/1.0/container/item/"
+    ` + properties + `
+ </rdf:RDF>
+</x:xmpmeta>`)
+}
+
+func TestMotionPhotoVideo(t *testing.T) {
+	video := []byte{0, 0, 0, 16, 'f', 't', 'y', 'p', 'm', 'p', '4', '2', 0, 0, 0, 0}
+	video = append(video, 0, 0, 0, 16, 'm', 'd', 'a', 't', 1, 2, 3, 4, 5, 6, 7, 8)
+	gainMap := mpfTestImage(nil, 32, 24)
+	sef := []byte("SEFH\x01\x00\x00\x00\x08\x00\x00\x00SEFT")
+
+	motionPhoto := fmt.Sprintf(`GCamera:MotionPhoto="1" GCamera:MotionPhotoVersion="1">
+   <Container:Directory>
+    <rdf:Seq>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="image/jpeg" Item:Semantic="Primary" Item:Length="0" Item:Padding="4"/>
+     </rdf:li>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="video/mp4" Item:Semantic="MotionPhoto" Item:Length="%d" Item:Padding="0"/>
+     </rdf:li>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="image/jpeg" Item:Semantic="GainMap" Item:Length="%d"/>
+     </rdf:li>
+    </rdf:Seq>
+   </Container:Directory>
+  </rdf:Description>`, len(video), len(gainMap))
+	microVideo := fmt.Sprintf(`GCamera:MicroVideo="1" GCamera:MicroVideoVersion="1" GCamera:MicroVideoOffset="%d"/>`, len(video))
+
+	tests := []struct {
+		name  string
+		xmp   []byte
+		parts [][]byte
+		err   error
+	}{
+		// The padding after the primary image is not a trailer
+		{"MotionPhoto", motionPhotoXMP(motionPhoto), [][]byte{[]byte("junk"), video, gainMap}, nil},
+		{"MicroVideo", motionPhotoXMP(microVideo), [][]byte{video}, nil},
+		{"Samsung", nil, [][]byte{[]byte(samsungMotionPhotoTag), video, sef}, nil},
+		{"IncorrectOffset", motionPhotoXMP(`GCamera:MicroVideo="1" GCamera:MicroVideoOffset="20"/>`), [][]byte{video}, nil},
+		{"NoVideo", motionPhotoXMP(microVideo), nil, ErrNoMotionPhoto},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var app1 []byte
+			if test.xmp != nil {
+				app1 = xmpSegment(xmpSegmentPrefix, test.xmp)
+			}
+			data := mpfTestImage(app1, 64, 48)
+			for _, p := range test.parts {
+				data = append(data, p...)
+			}
+			sr, err := MotionPhotoVideo(bytes.NewReader(data), int64(len(data)))
+			if err != test.err {
+				t.Fatalf("Incorrect error wanted %v got %v", test.err, err)
+			}
+			if err != nil {
+				return
+			}
+			b, err := io.ReadAll(sr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, video) {
+				t.Errorf("Incorrect motion photo video wanted %d bytes got %d bytes", len(video), len(b))
+			}
+		})
+	}
+
+	if _, err := MotionPhotoVideo(bytes.NewReader(video), int64(len(video))); err != ErrNoJPEGMarker {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoJPEGMarker, err)
+	}
+}
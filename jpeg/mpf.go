@@ -0,0 +1,165 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// mpfPrefixLength is the length of the "MPF\0" prefix of an APP2 Multi-Picture Format segment.
+const mpfPrefixLength = 4
+
+// MP Index IFD Tags
+const (
+	mpfNumberOfImages = 0xB001
+	mpfEntry          = 0xB002
+)
+
+// mpEntryLength is the length of an MP Entry in bytes
+const mpEntryLength = 16
+
+// maxMPImages is the maximum number of MP Entries that are read.
+const maxMPImages = 64
+
+// MPType is the MP Type Code of an image of a Multi-Picture Format JPEG.
+type MPType uint32
+
+// MP Type Codes
+const (
+	MPTypeUndefined            MPType = 0x000000
+	MPTypeLargeThumbnailVGA    MPType = 0x010001
+	MPTypeLargeThumbnailFullHD MPType = 0x010002
+	MPTypePanorama             MPType = 0x020001
+	MPTypeDisparity            MPType = 0x020002
+	MPTypeMultiAngle           MPType = 0x020003
+	MPTypeBaseline             MPType = 0x030000
+)
+
+func (t MPType) String() string {
+	switch t {
+	case MPTypeUndefined:
+		return "Undefined"
+	case MPTypeLargeThumbnailVGA:
+		return "Large Thumbnail (VGA)"
+	case MPTypeLargeThumbnailFullHD:
+		return "Large Thumbnail (Full HD)"
+	case MPTypePanorama:
+		return "Multi-Frame Panorama"
+	case MPTypeDisparity:
+		return "Multi-Frame Disparity"
+	case MPTypeMultiAngle:
+		return "Multi-Frame Multi-Angle"
+	case MPTypeBaseline:
+		return "Baseline MP Primary Image"
+	}
+	return fmt.Sprintf("0x%06x", uint32(t))
+}
+
+// MPImage is an image of a Multi-Picture Format (MPF) JPEG, such as the
+// second frame of a stereo camera or the depth image of a phone camera.
+type MPImage struct {
+	Type MPType
+
+	// Representative is true for the image that represents the file.
+	Representative bool
+
+	// Offset from the start of the reader and Length of the JPEG image in bytes
+	Offset, Length uint32
+
+	// Dependent are the entry numbers (from 1) of the dependent images, 0 if none.
+	Dependent [2]uint16
+}
+
+// MPImages returns the images of the APP2 Multi-Picture Format segment.
+// The first image is the primary image. Returns nil if the JPEG does not
+// have an MPF segment.
+func (m Metadata) MPImages() []MPImage {
+	return m.mpImages
+}
+
+// MPImageReader returns a reader of the JPEG image of img.
+func (m Metadata) MPImageReader(img MPImage) io.Reader {
+	return io.NewSectionReader(m.mr, int64(img.Offset), int64(img.Length))
+}
+
+// readMPF reads the MP Entries of an APP2 Multi-Picture Format segment.
+// MPF segments that can not be parsed are ignored.
+func (m *Metadata) readMPF(buf []byte) (err error) {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if length < 2+mpfPrefixLength || m.pos != 1 {
+		return m.ignoreMarker(buf)
+	}
+	// Discard App Marker bytes, header length bytes and the MPF prefix
+	if err = m.discard(4 + mpfPrefixLength); err != nil {
+		return err
+	}
+	// Offsets are relative to the MP Endian field that follows the prefix
+	mpfOffset := m.offset()
+
+	seg := make([]byte, length-2-mpfPrefixLength)
+	n, err := io.ReadFull(m.br, seg)
+	m.discarded += uint32(n)
+	if err != nil {
+		return err
+	}
+	m.mpImages = parseMPF(seg, mpfOffset, m.start)
+	return nil
+}
+
+// parseMPF returns the MPImages of the MP Index IFD in buf. mpfOffset is the
+// offset of buf and start is the offset of the primary image in the reader.
+func parseMPF(buf []byte, mpfOffset, start uint32) []MPImage {
+	byteOrder := meta.BinaryOrder(buf)
+	if byteOrder == nil || len(buf) < 8 {
+		return nil
+	}
+	ifdOffset := byteOrder.Uint32(buf[4:8])
+	if uint64(ifdOffset)+2 > uint64(len(buf)) {
+		return nil
+	}
+	count := int(byteOrder.Uint16(buf[ifdOffset:]))
+	var numberOfImages, entryCount, entryOffset uint32
+	for i, pos := 0, int(ifdOffset)+2; i < count && pos+12 <= len(buf); i, pos = i+1, pos+12 {
+		switch byteOrder.Uint16(buf[pos:]) {
+		case mpfNumberOfImages:
+			numberOfImages = byteOrder.Uint32(buf[pos+8:])
+		case mpfEntry:
+			entryCount = byteOrder.Uint32(buf[pos+4:]) / mpEntryLength
+			entryOffset = byteOrder.Uint32(buf[pos+8:])
+		}
+	}
+	if numberOfImages < entryCount {
+		entryCount = numberOfImages
+	}
+	if entryCount > maxMPImages {
+		entryCount = maxMPImages
+	}
+	images := make([]MPImage, 0, entryCount)
+	for i := uint32(0); i < entryCount; i++ {
+		pos := uint64(entryOffset) + uint64(i)*mpEntryLength
+		if pos+mpEntryLength > uint64(len(buf)) {
+			break
+		}
+		entry := buf[pos : pos+mpEntryLength]
+		attr := byteOrder.Uint32(entry[0:4])
+		img := MPImage{
+			Type:           MPType(attr & 0xFFFFFF),
+			Representative: attr&(1<<29) != 0,
+			Length:         byteOrder.Uint32(entry[4:8]),
+			Dependent:      [2]uint16{byteOrder.Uint16(entry[12:14]), byteOrder.Uint16(entry[14:16])},
+		}
+		// The primary image has an offset of 0
+		if offset := byteOrder.Uint32(entry[8:12]); offset == 0 {
+			img.Offset = start
+		} else {
+			img.Offset = mpfOffset + offset
+		}
+		images = append(images, img)
+	}
+	return images
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+// mpfTestImage returns a JPEG image with the given width and height followed by padding.
+func mpfTestImage(app2 []byte, width, height uint16) []byte {
+	img := []byte{markerFirstByte, markerSOI}
+	img = append(img, app2...)
+	img = append(img, markerFirstByte, markerSOF0, 0, 11, 8, byte(height>>8), byte(height), byte(width>>8), byte(width), 1, 1, 0x11, 0)
+	img = append(img, markerFirstByte, markerSOS, 0, 8, 1, 1, 0, 0, 0x3f, 0)
+	img = append(img, make([]byte, 16)...)
+	return append(img, markerFirstByte, markerEOI)
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// mpfTestSegment returns an APP2 MPF segment with a primary image and a disparity image.
+func mpfTestSegment(primaryLength, secondLength, secondOffset uint32) []byte {
+	mpf := []byte{'M', 'M', 0, 0x2a, 0, 0, 0, 8}
+	mpf = append(mpf, 0, 3)
+	mpf = append(mpf, 0xb0, 0x00, 0, 7, 0, 0, 0, 4, '0', '1', '0', '0')
+	mpf = append(mpf, 0xb0, 0x01, 0, 4, 0, 0, 0, 1, 0, 0, 0, 2)
+	mpf = append(mpf, 0xb0, 0x02, 0, 7, 0, 0, 0, 32, 0, 0, 0, 50)
+	mpf = appendUint32(mpf, 0)
+	// MP Entries
+	mpf = appendUint32(mpf, 1<<29|uint32(MPTypeBaseline))
+	mpf = appendUint32(mpf, primaryLength)
+	mpf = appendUint32(mpf, 0)
+	mpf = append(mpf, 0, 2, 0, 0)
+	mpf = appendUint32(mpf, uint32(MPTypeDisparity))
+	mpf = appendUint32(mpf, secondLength)
+	mpf = appendUint32(mpf, secondOffset)
+	mpf = append(mpf, 0, 0, 0, 0)
+
+	seg := []byte{markerFirstByte, markerAPP2, 0, 0, 'M', 'P', 'F', 0}
+	seg = append(seg, mpf...)
+	binary.BigEndian.PutUint16(seg[2:4], uint16(len(seg)-2))
+	return seg
+}
+
+func TestScanJPEGMPF(t *testing.T) {
+	second := mpfTestImage(nil, 64, 48)
+	primaryLength := uint32(len(mpfTestImage(mpfTestSegment(0, 0, 0), 640, 480)))
+	// Offsets are relative to the MP Endian field after SOI, APP2 header and MPF prefix
+	app2 := mpfTestSegment(primaryLength, uint32(len(second)), primaryLength-10)
+	data := append(mpfTestImage(app2, 640, 480), second...)
+
+	m, err := ScanJPEG(bytes.NewReader(data), nil, nil)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	images := m.MPImages()
+	if len(images) != 2 {
+		t.Fatalf("Incorrect number of MP images wanted %d got %d", 2, len(images))
+	}
+	want := []MPImage{
+		{Type: MPTypeBaseline, Representative: true, Offset: 0, Length: primaryLength, Dependent: [2]uint16{2, 0}},
+		{Type: MPTypeDisparity, Offset: primaryLength, Length: uint32(len(second))},
+	}
+	for i := range want {
+		if images[i] != want[i] {
+			t.Errorf("Incorrect MP image %d wanted %+v got %+v", i, want[i], images[i])
+		}
+	}
+	if images[1].Type.String() != "Multi-Frame Disparity" {
+		t.Errorf("Incorrect MPType string got %s", images[1].Type)
+	}
+
+	b, err := io.ReadAll(m.MPImageReader(images[1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, second) {
+		t.Errorf("Incorrect MP image data")
+	}
+	m2, err := ScanJPEG(bytes.NewReader(b), nil, nil)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	if dim := m2.Dimensions(); dim.Width != 64 || dim.Height != 48 {
+		t.Errorf("Incorrect MP image dimensions got %dx%d", dim.Width, dim.Height)
+	}
+
+	// A JPEG without an MPF segment has no MP images
+	m, _ = ScanJPEG(bytes.NewReader(second), nil, nil)
+	if m.MPImages() != nil {
+		t.Errorf("Incorrect MP images wanted nil got %v", m.MPImages())
+	}
+}
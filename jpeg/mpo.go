@@ -0,0 +1,176 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// jpsPrefix is the identifier of an APP3 JPS stereoscopic segment
+const jpsPrefix = "_JPSJPS_"
+
+// jpsHeaderLength is the length of the APP3 marker, the segment length,
+// the identifier, the descriptor block length and the descriptor.
+const jpsHeaderLength = 4 + len(jpsPrefix) + 2 + 4
+
+// JPSLayout is the stereoscopic layout of a JPS image.
+type JPSLayout uint8
+
+// JPS Layouts
+const (
+	JPSLayoutMono        JPSLayout = 0
+	JPSLayoutInterleaved JPSLayout = 1
+	JPSLayoutSideBySide  JPSLayout = 2
+	JPSLayoutOverUnder   JPSLayout = 3
+	JPSLayoutAnaglyph    JPSLayout = 4
+)
+
+func (l JPSLayout) String() string {
+	switch l {
+	case JPSLayoutMono:
+		return "Mono"
+	case JPSLayoutInterleaved:
+		return "Interleaved"
+	case JPSLayoutSideBySide:
+		return "Side By Side"
+	case JPSLayoutOverUnder:
+		return "Over Under"
+	case JPSLayoutAnaglyph:
+		return "Anaglyph"
+	}
+	return "Unknown"
+}
+
+// JPSDescriptor is the stereoscopic descriptor of the APP3 segment of a JPS image.
+// Both frames of a JPS image are stored in a single JPEG image.
+type JPSDescriptor struct {
+	Layout JPSLayout
+
+	// LeftFirst is true if the left frame is stored first (on the left or on top).
+	LeftFirst bool
+
+	// HalfWidth and HalfHeight are true if the frames are stored at half
+	// of their width or height.
+	HalfWidth, HalfHeight bool
+
+	// Separation is the separation between the frames in pixels.
+	Separation uint8
+}
+
+// JPS returns the stereoscopic descriptor of the APP3 JPS segment
+// and true if the segment is present.
+func (m Metadata) JPS() (JPSDescriptor, bool) {
+	return m.jps, m.hasJPS
+}
+
+// Offset returns the offset of the SOI marker of the JPEG image in the reader.
+func (m Metadata) Offset() uint32 {
+	return m.start
+}
+
+// readJPS reads the stereoscopic descriptor of an APP3 JPS segment
+// and discards the segment.
+func (m *Metadata) readJPS(buf []byte) error {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if m.pos == 1 && length+2 >= jpsHeaderLength {
+		// Peek may move the buffer of the peekReader, buf is not used after Peek
+		b, err := m.br.Peek(jpsHeaderLength)
+		if err != nil {
+			return err
+		}
+		if jpegByteOrder.Uint16(b[12:14]) >= 4 {
+			// Descriptor: MEDIA_TYPE, flags, LAYOUT and SEPARATION
+			m.jps = JPSDescriptor{
+				Layout:     JPSLayout(b[16]),
+				LeftFirst:  b[15]&0x04 != 0,
+				HalfWidth:  b[15]&0x02 != 0,
+				HalfHeight: b[15]&0x01 != 0,
+				Separation: b[17],
+			}
+			if b[14] == 0 {
+				m.jps.Layout = JPSLayoutMono
+			}
+			m.hasJPS = true
+		}
+	}
+	return m.discard(length + 2)
+}
+
+// ScanFrames scans a reader for the JPEG images of a stereoscopic MPO file,
+// and returns the Metadata of each image with its SOF header and Exif header.
+// exifFn and xmpFn are run for each image.
+//
+// The images are read from the MP Entries of the APP2 Multi-Picture Format
+// segment of the first image. Without an MPF segment, JPEG images that follow
+// the End Of Image marker of the previous image are read.
+//
+// A JPS file has both frames in a single JPEG image and returns a single
+// Metadata, see Metadata.JPS for its stereoscopic layout.
+//
+// Returns the error of ScanJPEG for the first image. Images after the first
+// image that can not be read are not returned.
+func ScanFrames(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) ([]Metadata, error) {
+	m, err := ScanJPEG(mr, exifFn, xmpFn)
+	if err != nil && err != ErrNoExif {
+		return nil, err
+	}
+	frames := []Metadata{m}
+	if images := m.MPImages(); len(images) > 0 {
+		for _, img := range images {
+			if img.Offset == m.start || img.Length == 0 {
+				continue
+			}
+			if f, ok := scanFrame(mr, int64(img.Offset), exifFn, xmpFn); ok {
+				frames = append(frames, f)
+			}
+		}
+		return frames, err
+	}
+	for offset := int64(m.offset()); ; {
+		if offset = nextSOI(mr, offset); offset < 0 {
+			break
+		}
+		f, ok := scanFrame(mr, offset, exifFn, xmpFn)
+		if !ok {
+			break
+		}
+		frames = append(frames, f)
+		offset = int64(f.offset())
+	}
+	return frames, err
+}
+
+// scanFrame scans the JPEG image at offset of mr.
+func scanFrame(mr meta.Reader, offset int64, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (Metadata, bool) {
+	if _, err := mr.Seek(offset, io.SeekStart); err != nil {
+		return Metadata{}, false
+	}
+	m, err := ScanJPEG(mr, exifFn, xmpFn)
+	if err != nil && err != ErrNoExif {
+		return Metadata{}, false
+	}
+	return m, true
+}
+
+// nextSOI returns the offset of the SOI marker of the next JPEG image
+// that follows an End Of Image marker after offset, or -1 if none was found.
+func nextSOI(r io.ReaderAt, offset int64) int64 {
+	// EOI marker, SOI marker and the first byte of the next marker
+	sep := []byte{markerFirstByte, markerEOI, markerFirstByte, markerSOI, markerFirstByte}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.ReadAt(buf, offset)
+		if i := bytes.Index(buf[:n], sep); i >= 0 {
+			return offset + int64(i) + 2
+		}
+		if err != nil || n < len(sep) {
+			return -1
+		}
+		offset += int64(n - len(sep) + 1)
+	}
+}
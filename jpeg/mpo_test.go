@@ -0,0 +1,96 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// mpoTestExif returns an APP1 Exif segment with an empty IFD.
+func mpoTestExif() []byte {
+	return []byte{markerFirstByte, markerAPP1, 0, 22, 'E', 'x', 'i', 'f', 0, 0, 'M', 'M', 0, 0x2a, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0}
+}
+
+func TestScanFrames(t *testing.T) {
+	second := mpfTestImage(mpoTestExif(), 64, 48)
+	primaryLength := uint32(len(mpfTestImage(mpfTestSegment(0, 0, 0), 640, 480)))
+	app2 := mpfTestSegment(primaryLength, uint32(len(second)), primaryLength-10)
+	mpo := append(mpfTestImage(app2, 640, 480), second...)
+
+	// Concatenated JPEG images without an MPF segment
+	first := mpfTestImage(nil, 320, 240)
+	concat := append(append([]byte{}, first...), second...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		offsets []uint32
+	}{
+		{"MPF", mpo, []uint32{0, primaryLength}},
+		{"Concatenated", concat, []uint32{0, uint32(len(first))}},
+		{"Single", first, []uint32{0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []meta.Reader{bytes.NewReader(test.data), readerOnly{bytes.NewReader(test.data)}} {
+				frames, err := ScanFrames(r, nil, nil)
+				if err != nil && err != ErrNoExif {
+					t.Fatal(err)
+				}
+				if len(frames) != len(test.offsets) {
+					t.Fatalf("Incorrect number of frames wanted %d got %d", len(test.offsets), len(frames))
+				}
+				for i, f := range frames {
+					if f.Offset() != test.offsets[i] {
+						t.Errorf("Incorrect frame %d offset wanted %d got %d", i, test.offsets[i], f.Offset())
+					}
+				}
+				if len(frames) < 2 {
+					continue
+				}
+				f := frames[1]
+				if dim := f.Dimensions(); dim.Width != 64 || dim.Height != 48 {
+					t.Errorf("Incorrect frame dimensions got %dx%d", dim.Width, dim.Height)
+				}
+				// Tiff Header after SOI, APP1 header and Exif prefix
+				if want := test.offsets[1] + 12; f.ExifHeader.TiffHeaderOffset != want {
+					t.Errorf("Incorrect frame Exif TiffHeaderOffset wanted %d got %d", want, f.ExifHeader.TiffHeaderOffset)
+				}
+				if _, err = f.Exif(); err != nil {
+					t.Errorf("Incorrect frame Exif error %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestScanJPEGJPS(t *testing.T) {
+	app3 := []byte{markerFirstByte, markerAPP3, 0, 16, '_', 'J', 'P', 'S', 'J', 'P', 'S', '_', 0, 4, 1, 0x04, byte(JPSLayoutSideBySide), 0}
+	m, err := ScanJPEG(bytes.NewReader(mpfTestImage(app3, 1280, 480)), nil, nil)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	jps, ok := m.JPS()
+	if !ok {
+		t.Fatal("JPS descriptor was not read")
+	}
+	if want := (JPSDescriptor{Layout: JPSLayoutSideBySide, LeftFirst: true}); jps != want {
+		t.Errorf("Incorrect JPS descriptor wanted %+v got %+v", want, jps)
+	}
+	if jps.Layout.String() != "Side By Side" {
+		t.Errorf("Incorrect JPSLayout string got %s", jps.Layout)
+	}
+	if dim := m.Dimensions(); dim.Width != 1280 || dim.Height != 480 {
+		t.Errorf("Incorrect dimensions got %dx%d", dim.Width, dim.Height)
+	}
+
+	m, _ = ScanJPEG(bytes.NewReader(mpfTestImage(nil, 64, 48)), nil, nil)
+	if _, ok = m.JPS(); ok {
+		t.Errorf("Incorrect JPS descriptor for JPEG without APP3 segment")
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// Photoshop Image Resource Block
+var (
+	photoshopSegmentPrefix = []byte("Photoshop 3.0\x00")
+	irbSignature           = []byte("8BIM")
+)
+
+// Image Resource IDs
+const (
+	irbIPTC       uint16 = 0x0404 // IPTC-NAA record
+	irbIPTCDigest uint16 = 0x0425 // MD5 digest of the IPTC-NAA record
+)
+
+// imageResource is a Photoshop Image Resource Block.
+// name is the padded Pascal string of the resource name.
+// sig is the signature of resources of older applications, nil for "8BIM".
+type imageResource struct {
+	id   uint16
+	sig  []byte
+	name []byte
+	data []byte
+}
+
+// parseImageResources returns the Image Resource Blocks in buf.
+// Returns ErrCorruptSegment if a block is truncated.
+func parseImageResources(buf []byte) (irbs []imageResource, err error) {
+	for len(buf) > 0 {
+		if len(buf) < 7 || !meta.IsImageResourceSignature(buf[:4]) {
+			return nil, ErrCorruptSegment
+		}
+		irb := imageResource{id: binary.BigEndian.Uint16(buf[4:6])}
+		if !bytes.Equal(buf[:4], irbSignature) {
+			irb.sig = buf[:4]
+		}
+		// Pascal string padded to an even length
+		n := 1 + int(buf[6])
+		n += n % 2
+		if len(buf) < 6+n+4 {
+			return nil, ErrCorruptSegment
+		}
+		irb.name = buf[6 : 6+n]
+		buf = buf[6+n:]
+		size := int(binary.BigEndian.Uint32(buf[:4]))
+		if size < 0 || len(buf) < 4+size {
+			return nil, ErrCorruptSegment
+		}
+		irb.data = buf[4 : 4+size]
+		buf = buf[4+size:]
+		// Data padded to an even length
+		if size%2 == 1 && len(buf) > 0 {
+			buf = buf[1:]
+		}
+		irbs = append(irbs, irb)
+	}
+	return irbs, nil
+}
+
+// appendImageResource appends irb to buf.
+func appendImageResource(buf []byte, irb imageResource) []byte {
+	if irb.sig == nil {
+		buf = append(buf, irbSignature...)
+	} else {
+		buf = append(buf, irb.sig...)
+	}
+	buf = append(buf, byte(irb.id>>8), byte(irb.id))
+	if len(irb.name) == 0 {
+		buf = append(buf, 0, 0)
+	} else {
+		buf = append(buf, irb.name...)
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(irb.data)))
+	buf = append(buf, size[:]...)
+	buf = append(buf, irb.data...)
+	if len(irb.data)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// photoshopSegment returns the APP13 segment with the Image Resource Blocks of the
+// segments segs and the IPTC-NAA record set to iptc. The IPTC digest is updated.
+// Other resources are kept in their order.
+func photoshopSegment(segs [][]byte, iptc []byte) ([]byte, error) {
+	var data []byte
+	for _, seg := range segs {
+		data = append(data, seg[4+len(photoshopSegmentPrefix):]...)
+	}
+	irbs, err := parseImageResources(data)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := md5.Sum(iptc)
+	updated := map[uint16][]byte{irbIPTC: iptc, irbIPTCDigest: digest[:]}
+	buf := make([]byte, 0, len(data)+len(iptc)+32)
+	for _, irb := range irbs {
+		if v, ok := updated[irb.id]; ok {
+			if v == nil {
+				continue
+			}
+			irb.data = v
+			updated[irb.id] = nil
+		}
+		buf = appendImageResource(buf, irb)
+	}
+	for _, id := range []uint16{irbIPTC, irbIPTCDigest} {
+		if v := updated[id]; v != nil {
+			buf = appendImageResource(buf, imageResource{id: id, data: v})
+		}
+	}
+	return newSegment(markerAPP13, photoshopSegmentPrefix, buf)
+}
+
+// isPhotoshopSegment returns true if seg is an APP13 Photoshop segment.
+func isPhotoshopSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP13 && segmentHasPrefix(seg, photoshopSegmentPrefix)
+}
+
+// ReadIPTC reads the IPTC-NAA record from the Photoshop APP13 segments of the JPEG in r.
+// The record can be decoded with iptc.Decode.
+//
+// Returns ErrNoIPTC if the JPEG does not have an IPTC-NAA record and
+// ErrCorruptSegment if an Image Resource Block is truncated.
+func ReadIPTC(r io.Reader) ([]byte, error) {
+	var data []byte
+	err := copySegments(r, io.Discard, func(w io.Writer, marker byte, seg []byte) error {
+		if marker == markerSOS {
+			return errEndOfHeader
+		}
+		if isPhotoshopSegment(seg) {
+			data = append(data, seg[4+len(photoshopSegmentPrefix):]...)
+		}
+		return nil
+	}, false)
+	if err != nil && err != errEndOfHeader {
+		return nil, err
+	}
+	irbs, err := parseImageResources(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, irb := range irbs {
+		if irb.id == irbIPTC {
+			return irb.data, nil
+		}
+	}
+	return nil, ErrNoIPTC
+}
+
+// readPhotoshop reads the Image Resource Blocks of an APP13 Photoshop segment
+// when iptcFn is set, otherwise the segment is discarded.
+func (m *Metadata) readPhotoshop(buf []byte) (err error) {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if m.iptcFn == nil || m.pos != 1 || length < 2+len(photoshopSegmentPrefix) {
+		return m.ignoreMarker(buf)
+	}
+	// Discard App Marker bytes, header length bytes and the Photoshop prefix
+	if err = m.discard(4 + len(photoshopSegmentPrefix)); err != nil {
+		return err
+	}
+	data := make([]byte, length-2-len(photoshopSegmentPrefix))
+	n, err := io.ReadFull(m.br, data)
+	m.discarded += uint32(n)
+	// Image Resource Blocks can continue in the next segment
+	m.photoshop = append(m.photoshop, data[:n]...)
+	return err
+}
+
+// readIPTC runs iptcFn with the IPTC-NAA record of the Image Resource Blocks.
+// Returns ErrCorruptSegment if an Image Resource Block is truncated.
+func (m *Metadata) readIPTC() error {
+	if m.photoshop == nil {
+		return nil
+	}
+	irbs, err := parseImageResources(m.photoshop)
+	if err != nil {
+		return err
+	}
+	for _, irb := range irbs {
+		if irb.id == irbIPTC {
+			return m.iptcFn(irb.data)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"crypto/md5"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+// photoshopResources returns the Image Resource Blocks of the Photoshop segments of a JPEG.
+func photoshopResources(t *testing.T, buf []byte) (irbs []imageResource) {
+	t.Helper()
+	var segs [][]byte
+	err := copySegments(bytes.NewReader(buf), io.Discard, func(w io.Writer, marker byte, seg []byte) error {
+		if isPhotoshopSegment(seg) {
+			segs = append(segs, seg)
+		}
+		return nil
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range segs {
+		r, err := parseImageResources(seg[4+len(photoshopSegmentPrefix):])
+		if err != nil {
+			t.Fatal(err)
+		}
+		irbs = append(irbs, r...)
+	}
+	return irbs
+}
+
+func TestRewriteIPTC(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	iptcData, err := iptc.IPTC{
+		Caption:   "A caption",
+		Keywords:  []string{"one", "two"},
+		Credit:    "Credit",
+		Copyright: "Copyright",
+	}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := md5.Sum(iptcData)
+
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{IPTC: iptcData}); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{markerSOI, markerAPP1, markerAPP1, markerAPP13, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}
+	if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers, want) {
+		t.Errorf("Incorrect markers wanted %x got %x", want, markers)
+	}
+
+	// Other image resources are kept in their order
+	wantIRBs := photoshopResources(t, buf)
+	irbs := photoshopResources(t, out.Bytes())
+	if assert.Len(t, irbs, len(wantIRBs)) {
+		for i, irb := range irbs {
+			assert.Equal(t, wantIRBs[i].id, irb.id)
+			switch irb.id {
+			case irbIPTC:
+				assert.Equal(t, iptcData, irb.data)
+			case irbIPTCDigest:
+				assert.Equal(t, digest[:], irb.data)
+			default:
+				assert.Equal(t, wantIRBs[i].data, irb.data)
+			}
+		}
+	}
+
+	// Inserted after the Exif and XMP segments
+	var stripped bytes.Buffer
+	if err = Strip(bytes.NewReader(buf), &stripped, StripOptions{KeepExif: true, KeepXMP: true}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err = Rewrite(bytes.NewReader(stripped.Bytes()), &out, RewriteOptions{IPTC: iptcData}); err != nil {
+		t.Fatal(err)
+	}
+	want = []byte{markerSOI, markerAPP1, markerAPP1, markerAPP13, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}
+	if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers, want) {
+		t.Errorf("Incorrect markers wanted %x got %x", want, markers)
+	}
+	assert.Equal(t, []imageResource{
+		{id: irbIPTC, name: []byte{0, 0}, data: iptcData},
+		{id: irbIPTCDigest, name: []byte{0, 0}, data: digest[:]},
+	}, photoshopResources(t, out.Bytes()))
+}
+
+func TestReadIPTC(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := iptc.IPTC{Caption: "A caption", Byline: []string{"Photographer"}}
+	iptcData, err := want.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{IPTC: iptcData}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadIPTC(bytes.NewReader(out.Bytes()))
+	if assert.NoError(t, err) {
+		assert.Equal(t, iptcData, data)
+		i, err := iptc.Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, want, i)
+	}
+
+	var stripped bytes.Buffer
+	if err = Strip(bytes.NewReader(buf), &stripped, StripOptions{KeepExif: true}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadIPTC(bytes.NewReader(stripped.Bytes()))
+	assert.ErrorIs(t, err, ErrNoIPTC)
+}
+
+func TestScanJPEGWithIPTC(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := iptc.IPTC{Caption: "A caption", Keywords: []string{"one", "two"}}
+	iptcData, err := want.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{IPTC: iptcData}); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []meta.Reader{bytes.NewReader(out.Bytes()), readerOnly{bytes.NewReader(out.Bytes())}} {
+		var record []byte
+		m, err := ScanJPEGWithIPTC(r, nil, nil, func(b []byte) error {
+			record = b
+			return nil
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, iptcData, record)
+			assert.True(t, m.ExifHeader.IsValid())
+			i, err := iptc.Decode(record)
+			assert.NoError(t, err)
+			assert.Equal(t, want, i)
+		}
+	}
+
+	// iptcFn is not run without an IPTC-NAA record
+	var stripped bytes.Buffer
+	if err = Strip(bytes.NewReader(buf), &stripped, StripOptions{KeepExif: true}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ScanJPEGWithIPTC(bytes.NewReader(stripped.Bytes()), nil, nil, func(b []byte) error {
+		t.Errorf("iptcFn should not be called without an IPTC-NAA record")
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// A truncated Image Resource Block
+	seg, err := newSegment(markerAPP13, photoshopSegmentPrefix, []byte("8BIM\x04\x04\x00\x00\x00\x00\x00\x10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	corrupt := append(append(append([]byte(nil), buf[:2]...), seg...), buf[2:]...)
+	iptcFn := func(b []byte) error {
+		t.Errorf("iptcFn should not be called with a truncated Image Resource Block")
+		return nil
+	}
+	_, err = ScanJPEGWithIPTC(bytes.NewReader(corrupt), nil, nil, iptcFn)
+	assert.ErrorIs(t, err, ErrCorruptSegment)
+
+	// Tolerant scans record a warning
+	m := newMetdata(bytes.NewReader(corrupt), nil, nil)
+	m.iptcFn = iptcFn
+	m.tolerant = true
+	if assert.NoError(t, m.scan()) && assert.Len(t, m.Warnings(), 1) {
+		assert.ErrorIs(t, m.Warnings()[0], ErrCorruptSegment)
+	}
+}
+
+func TestPhotoshopSegmentErrors(t *testing.T) {
+	seg, err := newSegment(markerAPP13, photoshopSegmentPrefix, []byte("8BIM\x04\x04\x00\x00\x00\x00\x00\x10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = photoshopSegment([][]byte{seg}, nil); err != ErrCorruptSegment {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptSegment, err)
+	}
+	if _, err = photoshopSegment(nil, make([]byte, maxSegmentLength)); err != ErrSegmentTooLarge {
+		t.Errorf("Incorrect error wanted %v got %v", ErrSegmentTooLarge, err)
+	}
+}
+
+func TestPhotoshopSegmentSignatures(t *testing.T) {
+	// A resource of an older application with the "MeSa" signature is kept
+	mesa := []byte("MeSa\x03\xE8\x00\x00\x00\x00\x00\x02ab")
+	seg, err := newSegment(markerAPP13, photoshopSegmentPrefix, mesa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := photoshopSegment([][]byte{seg}, []byte{0x1C, 2, 5, 0, 1, 'T'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	irbs, err := parseImageResources(out[4+len(photoshopSegmentPrefix):])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, irbs, 3) {
+		assert.Equal(t, []byte("MeSa"), irbs[0].sig)
+		assert.Equal(t, uint16(0x03E8), irbs[0].id)
+		assert.Equal(t, []byte("ab"), irbs[0].data)
+		assert.Equal(t, irbIPTC, irbs[1].id)
+	}
+}
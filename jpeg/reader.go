@@ -0,0 +1,91 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// bufReaderSize is the size of the bufio.Reader used when
+// the underlying reader can not be read from directly.
+const bufReaderSize = 64
+
+// peekReader is a reader that can Peek and Discard bytes without
+// an additional buffer, ie. *bufio.Reader.
+type peekReader interface {
+	io.Reader
+	Peek(n int) ([]byte, error)
+	Discard(n int) (discarded int, err error)
+}
+
+// sizeReaderAt is an io.ReaderAt with a known size, ie. *bytes.Reader,
+// *strings.Reader and *io.SectionReader.
+type sizeReaderAt interface {
+	io.ReaderAt
+	Size() int64
+}
+
+// newPeekReader returns a peekReader for mr. If mr is a peekReader
+// it is used directly, if mr is a sizeReaderAt it is read from with ReadAt
+// without copying, otherwise mr is wrapped in a bufio.Reader.
+func newPeekReader(mr meta.Reader) peekReader {
+	switch r := mr.(type) {
+	case peekReader:
+		return r
+	case sizeReaderAt:
+		if pos, err := mr.Seek(0, io.SeekCurrent); err == nil {
+			return &readerAt{r: r, pos: pos, size: r.Size()}
+		}
+	}
+	return bufio.NewReaderSize(mr, bufReaderSize)
+}
+
+// readerAt is a peekReader that reads from an io.ReaderAt
+// of a known size. Peek is limited to bufReaderSize.
+type readerAt struct {
+	r    io.ReaderAt
+	pos  int64
+	size int64
+	buf  [bufReaderSize]byte
+}
+
+// Peek returns the next n bytes without advancing the reader.
+// If Peek returns fewer than n bytes, it also returns an error.
+func (ra *readerAt) Peek(n int) ([]byte, error) {
+	if n > len(ra.buf) {
+		return nil, bufio.ErrBufferFull
+	}
+	i, err := ra.r.ReadAt(ra.buf[:n], ra.pos)
+	if i < n && err == nil {
+		err = io.EOF
+	}
+	return ra.buf[:i], err
+}
+
+// Discard skips the next n bytes, returning the number of bytes discarded.
+// If Discard skips fewer than n bytes, it also returns io.EOF.
+func (ra *readerAt) Discard(n int) (discarded int, err error) {
+	if remain := ra.size - ra.pos; int64(n) > remain {
+		n, err = int(remain), io.EOF
+	}
+	ra.pos += int64(n)
+	return n, err
+}
+
+// Read reads up to len(p) bytes into p.
+func (ra *readerAt) Read(p []byte) (n int, err error) {
+	if ra.pos >= ra.size {
+		return 0, io.EOF
+	}
+	n, err = ra.r.ReadAt(p, ra.pos)
+	ra.pos += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return
+}
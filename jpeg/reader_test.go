@@ -0,0 +1,87 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestNewPeekReader(t *testing.T) {
+	data := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+
+	if _, ok := newPeekReader(bytes.NewReader(data)).(*readerAt); !ok {
+		t.Errorf("Incorrect peekReader for *bytes.Reader wanted %T", &readerAt{})
+	}
+	f, err := os.Open("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, ok := newPeekReader(f).(*bufio.Reader); !ok {
+		t.Errorf("Incorrect peekReader for *os.File wanted %T", &bufio.Reader{})
+	}
+
+	// Start at the current offset
+	br := bytes.NewReader(data)
+	_, _ = br.Seek(2, io.SeekStart)
+	r := newPeekReader(br)
+
+	buf, err := r.Peek(4)
+	if err != nil || !bytes.Equal(buf, data[2:6]) {
+		t.Errorf("Incorrect Peek wanted %v got %v (%v)", data[2:6], buf, err)
+	}
+	if n, err := r.Discard(3); n != 3 || err != nil {
+		t.Errorf("Incorrect Discard wanted %d got %d (%v)", 3, n, err)
+	}
+	p := make([]byte, 2)
+	if n, err := r.Read(p); n != 2 || err != nil || !bytes.Equal(p, data[5:7]) {
+		t.Errorf("Incorrect Read wanted %v got %v (%v)", data[5:7], p, err)
+	}
+	if buf, err = r.Peek(4); err != io.EOF || !bytes.Equal(buf, data[7:]) {
+		t.Errorf("Incorrect Peek at end wanted %v got %v (%v)", data[7:], buf, err)
+	}
+	if n, err := r.Discard(4); n != 3 || err != io.EOF {
+		t.Errorf("Incorrect Discard at end wanted %d got %d (%v)", 3, n, err)
+	}
+	if _, err = r.Peek(bufReaderSize + 1); err != bufio.ErrBufferFull {
+		t.Errorf("Incorrect Peek error wanted %v got %v", bufio.ErrBufferFull, err)
+	}
+}
+
+func BenchmarkScanJPEGReader(b *testing.B) {
+	buf, err := os.ReadFile(dir + "a1.jpg")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("bytes.Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ScanJPEG(bytes.NewReader(buf), nil, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("bufio", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := ScanJPEG(readerOnly{bytes.NewReader(buf)}, nil, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// readerOnly is a meta.Reader without a Size method
+type readerOnly struct {
+	r *bytes.Reader
+}
+
+func (ro readerOnly) Read(p []byte) (int, error)                   { return ro.r.Read(p) }
+func (ro readerOnly) ReadAt(p []byte, off int64) (int, error)      { return ro.r.ReadAt(p, off) }
+func (ro readerOnly) Seek(offset int64, whence int) (int64, error) { return ro.r.Seek(offset, whence) }
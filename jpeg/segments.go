@@ -0,0 +1,101 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"io"
+)
+
+// Segment is a marker segment of a JPEG image.
+type Segment struct {
+	// Marker is the second byte of the marker, ie. 0xE1 for APP1
+	Marker byte
+
+	// Offset of the marker from the start of the reader
+	Offset uint32
+
+	// Length of the payload after the marker and length bytes. Markers
+	// without a length, such as SOI, have a Length of 0.
+	Length uint32
+
+	r io.ReaderAt
+}
+
+// PayloadOffset returns the offset of the payload from the start of the reader.
+func (s Segment) PayloadOffset() uint32 {
+	if s.hasLength() {
+		return s.Offset + 4
+	}
+	return s.Offset + 2
+}
+
+// Reader returns a reader of the payload of the segment.
+func (s Segment) Reader() io.Reader {
+	return io.NewSectionReader(s.r, int64(s.PayloadOffset()), int64(s.Length))
+}
+
+// hasLength returns true if the marker of the segment is followed by length bytes.
+func (s Segment) hasLength() bool {
+	return !isStandaloneMarker(s.Marker)
+}
+
+// Segments returns the marker segments of the JPEG image in r from the SOI
+// marker to the first SOS segment, or to the EOI marker of an image without
+// image data. Fill bytes (0xFF) before a marker are skipped. The entropy
+// coded image data after the SOS segment is not read.
+//
+// Returns ErrNoJPEGMarker if r does not start with a SOI marker, ErrUnexpectedEOF
+// if r ends before the SOS marker and ErrCorruptSegment for an invalid segment length.
+// The segments that were read before the error are returned with the error.
+func Segments(r io.ReaderAt) ([]Segment, error) {
+	var buf [4]byte
+	if n, _ := r.ReadAt(buf[:2], 0); n < 2 || !isSOIMarker(buf[:2]) {
+		return nil, ErrNoJPEGMarker
+	}
+	segments := []Segment{{Marker: markerSOI, r: r}}
+	var pos int64 = 2
+	for {
+		// Marker and fill bytes
+		if n, _ := r.ReadAt(buf[:1], pos); n < 1 {
+			return segments, ErrUnexpectedEOF
+		}
+		if buf[0] != markerFirstByte {
+			return segments, ErrNoJPEGMarker
+		}
+		for buf[0] == markerFirstByte {
+			pos++
+			if n, _ := r.ReadAt(buf[:1], pos); n < 1 {
+				return segments, ErrUnexpectedEOF
+			}
+		}
+		seg := Segment{Marker: buf[0], Offset: uint32(pos - 1), r: r}
+		pos++
+		if seg.hasLength() {
+			if n, _ := r.ReadAt(buf[:2], pos); n < 2 {
+				return segments, ErrUnexpectedEOF
+			}
+			length := jpegByteOrder.Uint16(buf[:2])
+			if length < 2 {
+				return segments, ErrCorruptSegment
+			}
+			seg.Length = uint32(length - 2)
+			pos += int64(length)
+			// The last byte of the payload
+			if n, _ := r.ReadAt(buf[:1], pos-1); n < 1 {
+				return segments, ErrUnexpectedEOF
+			}
+		}
+		segments = append(segments, seg)
+		if seg.Marker == markerSOS || seg.Marker == markerEOI {
+			return segments, nil
+		}
+	}
+}
+
+// isStandaloneMarker returns true for markers without length bytes:
+// TEM, RST0-RST7, SOI and EOI.
+func isStandaloneMarker(marker byte) bool {
+	return marker == 0x01 || (marker >= 0xD0 && marker <= 0xD9)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+func TestSegments(t *testing.T) {
+	buf := readFile(t, "../testImages/JPEG.jpg")
+	segments, err := Segments(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := segmentMarkers(t, buf)
+	if len(segments) != len(want) {
+		t.Fatalf("Incorrect number of segments wanted %d got %d", len(want), len(segments))
+	}
+	for i, seg := range segments {
+		if seg.Marker != want[i] {
+			t.Errorf("Incorrect segment %d marker wanted %x got %x", i, want[i], seg.Marker)
+		}
+		if buf[seg.Offset] != markerFirstByte || buf[seg.Offset+1] != seg.Marker {
+			t.Errorf("Incorrect segment %d offset %d", i, seg.Offset)
+		}
+		payload, err := io.ReadAll(seg.Reader())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if uint32(len(payload)) != seg.Length || !bytes.Equal(payload, buf[seg.PayloadOffset():seg.PayloadOffset()+seg.Length]) {
+			t.Errorf("Incorrect segment %d payload", i)
+		}
+	}
+	// The payload of the first APP1 segment is the Exif segment
+	if seg := segments[1]; seg.Offset != 2 || seg.PayloadOffset() != 6 || !bytes.HasPrefix(buf[seg.PayloadOffset():], exifSegmentPrefix) {
+		t.Errorf("Incorrect APP1 segment %+v", seg)
+	}
+
+	// Fill bytes before a marker and an image without image data
+	data := []byte{markerFirstByte, markerSOI, markerFirstByte, markerFirstByte, markerAPP9, 0, 5, 'a', 'b', 'c', markerFirstByte, markerEOI}
+	segments, err = Segments(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 3 || segments[1].Marker != markerAPP9 || segments[1].Offset != 3 || segments[1].Length != 3 || segments[2].Marker != markerEOI {
+		t.Errorf("Incorrect segments %+v", segments)
+	}
+}
+
+func TestSegmentsErrors(t *testing.T) {
+	buf := readFile(t, "../testImages/JPEG.jpg")
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"NoSOI", buf[2:], ErrNoJPEGMarker},
+		{"Truncated", buf[:100], ErrUnexpectedEOF},
+		{"NoMarker", []byte{markerFirstByte, markerSOI, 0, 0}, ErrNoJPEGMarker},
+		{"Length", []byte{markerFirstByte, markerSOI, markerFirstByte, markerAPP1, 0, 1}, ErrCorruptSegment},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Segments(bytes.NewReader(test.data)); err != test.err {
+				t.Errorf("Incorrect error wanted %v got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestScanJPEGWithAPP(t *testing.T) {
+	buf := readFile(t, "../testImages/JPEG.jpg")
+	all, err := Segments(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []Segment
+	for _, seg := range all {
+		if isAPPMarker(seg.Marker) {
+			want = append(want, seg)
+		}
+	}
+	for _, r := range []meta.Reader{bytes.NewReader(buf), readerOnly{bytes.NewReader(buf)}} {
+		var got []Segment
+		m, err := ScanJPEGWithAPP(r, nil, nil, func(seg Segment) error {
+			payload, err := io.ReadAll(seg.Reader())
+			if err != nil || !bytes.Equal(payload, buf[seg.PayloadOffset():seg.PayloadOffset()+seg.Length]) {
+				t.Errorf("Incorrect payload of APP segment %x at %d (%v)", seg.Marker, seg.Offset, err)
+			}
+			got = append(got, seg)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !m.ExifHeader.IsValid() {
+			t.Errorf("Exif was not read")
+		}
+		if len(got) != len(want) {
+			t.Fatalf("Incorrect number of APP segments wanted %d got %d", len(want), len(got))
+		}
+		for i := range want {
+			if got[i].Marker != want[i].Marker || got[i].Offset != want[i].Offset || got[i].Length != want[i].Length {
+				t.Errorf("Incorrect APP segment wanted %+v got %+v", want[i], got[i])
+			}
+		}
+	}
+
+	// A vendor APP11 segment with marker bytes in its payload
+	app11 := []byte{markerFirstByte, 0xEB, 0, 8, 'V', 'N', 'D', markerFirstByte, markerAPP1, 0}
+	var markers []byte
+	m, err := ScanJPEGWithAPP(bytes.NewReader(mpfTestImage(app11, 64, 48)), nil, nil, func(seg Segment) error {
+		markers = append(markers, seg.Marker)
+		return nil
+	})
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(markers, []byte{0xEB}) {
+		t.Errorf("Incorrect APP markers got %x", markers)
+	}
+	if dim := m.Dimensions(); dim.Width != 64 || dim.Height != 48 {
+		t.Errorf("Incorrect dimensions after APP11 segment got %s", dim)
+	}
+
+	// An error of appFn is returned
+	errStop := errors.New("stop")
+	if _, err = ScanJPEGWithAPP(bytes.NewReader(buf), nil, nil, func(seg Segment) error {
+		return errStop
+	}); err != errStop {
+		t.Errorf("Incorrect error wanted %v got %v", errStop, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+// maxSOFComponents is the number of components of the SOF header that are read.
+const maxSOFComponents = 4
+
+// Process is the coding process of the SOF marker of a JPEG image.
+type Process uint8
+
+// Coding Processes
+const (
+	ProcessUnknown Process = iota
+	ProcessBaseline
+	ProcessExtended
+	ProcessProgressive
+	ProcessLossless
+)
+
+func (p Process) String() string {
+	switch p {
+	case ProcessBaseline:
+		return "Baseline"
+	case ProcessExtended:
+		return "Extended Sequential"
+	case ProcessProgressive:
+		return "Progressive"
+	case ProcessLossless:
+		return "Lossless"
+	}
+	return "Unknown"
+}
+
+// Subsampling is the chroma subsampling of a JPEG image.
+type Subsampling uint8
+
+// Chroma Subsampling
+const (
+	SubsamplingUnknown Subsampling = iota
+	Subsampling400                 // Grayscale image without chroma components
+	Subsampling444
+	Subsampling422
+	Subsampling420
+	Subsampling440
+	Subsampling411
+	Subsampling410
+)
+
+func (s Subsampling) String() string {
+	switch s {
+	case Subsampling400:
+		return "4:0:0"
+	case Subsampling444:
+		return "4:4:4"
+	case Subsampling422:
+		return "4:2:2"
+	case Subsampling420:
+		return "4:2:0"
+	case Subsampling440:
+		return "4:4:0"
+	case Subsampling411:
+		return "4:1:1"
+	case Subsampling410:
+		return "4:1:0"
+	}
+	return "Unknown"
+}
+
+// Process returns the coding process of the SOF marker.
+func (m Metadata) Process() Process {
+	switch m.sofMarker {
+	case markerSOF0:
+		return ProcessBaseline
+	case markerSOF1, markerSOF5, markerSOF9:
+		return ProcessExtended
+	case markerSOF2, markerSOF6, markerSOF10:
+		return ProcessProgressive
+	case markerSOF3, markerSOF7, markerSOF11:
+		return ProcessLossless
+	}
+	return ProcessUnknown
+}
+
+// Progressive returns true if the image is progressive.
+func (m Metadata) Progressive() bool {
+	return m.Process() == ProcessProgressive
+}
+
+// Arithmetic returns true if the image uses arithmetic coding instead of Huffman coding.
+func (m Metadata) Arithmetic() bool {
+	return m.sofMarker == markerSOF9 || m.sofMarker == markerSOF10 || m.sofMarker == markerSOF11
+}
+
+// Precision returns the sample precision of the image in bits, ie. 8 or 12.
+func (m Metadata) Precision() uint8 {
+	return m.precision
+}
+
+// Subsampling returns the chroma subsampling of the image derived from the
+// sampling factors of the components of the SOF header.
+func (m Metadata) Subsampling() Subsampling {
+	switch {
+	case m.components == 1:
+		return Subsampling400
+	case m.components < 3 || m.components > maxSOFComponents:
+		return SubsamplingUnknown
+	}
+	// The chroma components have the same sampling factors
+	if m.sampling[1] != m.sampling[2] {
+		return SubsamplingUnknown
+	}
+	h, v := m.sampling[0]>>4, m.sampling[0]&0x0f
+	ch, cv := m.sampling[1]>>4, m.sampling[1]&0x0f
+	if ch == 0 || cv == 0 || h%ch != 0 || v%cv != 0 {
+		return SubsamplingUnknown
+	}
+	switch [2]uint8{h / ch, v / cv} {
+	case [2]uint8{1, 1}:
+		return Subsampling444
+	case [2]uint8{2, 1}:
+		return Subsampling422
+	case [2]uint8{2, 2}:
+		return Subsampling420
+	case [2]uint8{1, 2}:
+		return Subsampling440
+	case [2]uint8{4, 1}:
+		return Subsampling411
+	case [2]uint8{4, 2}:
+		return Subsampling410
+	}
+	return SubsamplingUnknown
+}
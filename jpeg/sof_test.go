@@ -0,0 +1,65 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// sofTestImage returns a JPEG image with a SOF segment of marker with the
+// precision and the sampling factors of the components.
+func sofTestImage(marker, precision uint8, sampling ...uint8) []byte {
+	sof := []byte{markerFirstByte, marker, 0, byte(8 + 3*len(sampling)), precision, 0, 48, 0, 64, byte(len(sampling))}
+	for i, s := range sampling {
+		sof = append(sof, byte(i+1), s, 0)
+	}
+	img := []byte{markerFirstByte, markerSOI}
+	img = append(img, sof...)
+	img = append(img, markerFirstByte, markerSOS, 0, 8, 1, 1, 0, 0, 0x3f, 0)
+	img = append(img, make([]byte, 16)...)
+	return append(img, markerFirstByte, markerEOI)
+}
+
+func TestScanJPEGSOF(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		process     Process
+		arithmetic  bool
+		precision   uint8
+		subsampling Subsampling
+	}{
+		{"JPEG.jpg", readFile(t, "../assets/JPEG.jpg"), ProcessBaseline, false, 8, Subsampling444},
+		{"a1.jpg", readFile(t, "../assets/a1.jpg"), ProcessBaseline, false, 8, Subsampling422},
+		{"a2.jpg", readFile(t, "../assets/a2.jpg"), ProcessProgressive, false, 8, Subsampling420},
+		{"Extended", sofTestImage(markerSOF1, 12, 0x12, 0x11, 0x11), ProcessExtended, false, 12, Subsampling440},
+		{"Arithmetic", sofTestImage(markerSOF10, 8, 0x41, 0x11, 0x11), ProcessProgressive, true, 8, Subsampling411},
+		{"Lossless", sofTestImage(markerSOF3, 16, 0x11), ProcessLossless, false, 16, Subsampling400},
+		{"CMYK", sofTestImage(markerSOF0, 8, 0x42, 0x11, 0x11, 0x42), ProcessBaseline, false, 8, Subsampling410},
+		{"MixedChroma", sofTestImage(markerSOF0, 8, 0x22, 0x21, 0x11), ProcessBaseline, false, 8, SubsamplingUnknown},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, r := range []meta.Reader{bytes.NewReader(test.data), readerOnly{bytes.NewReader(test.data)}} {
+				m, err := ScanJPEG(r, nil, nil)
+				if err != nil && err != ErrNoExif {
+					t.Fatal(err)
+				}
+				if m.Process() != test.process || m.Progressive() != (test.process == ProcessProgressive) || m.Arithmetic() != test.arithmetic {
+					t.Errorf("Incorrect Process wanted %s %t got %s %t", test.process, test.arithmetic, m.Process(), m.Arithmetic())
+				}
+				if m.Precision() != test.precision {
+					t.Errorf("Incorrect Precision wanted %d got %d", test.precision, m.Precision())
+				}
+				if m.Subsampling() != test.subsampling {
+					t.Errorf("Incorrect Subsampling wanted %s got %s", test.subsampling, m.Subsampling())
+				}
+			}
+		})
+	}
+}
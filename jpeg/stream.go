@@ -0,0 +1,144 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// ErrStreamNotSeekable is returned by the reader of the Metadata of a Stream,
+// the images of a Stream can only be read in order.
+var ErrStreamNotSeekable = errors.New("JPEG stream is not seekable")
+
+// Stream reads successive JPEG images from a reader, ie. a Motion JPEG (MJPEG)
+// stream or concatenated JPEG images. Bytes between the images, such as the
+// boundaries of a multipart HTTP response, are skipped.
+type Stream struct {
+	sr *streamReader
+}
+
+// NewStream returns a Stream that reads JPEG images from r.
+func NewStream(r io.Reader) *Stream {
+	return &Stream{sr: &streamReader{br: bufio.NewReader(r)}}
+}
+
+// Offset returns the number of bytes read from the reader of the Stream.
+func (s *Stream) Offset() int64 {
+	return s.sr.pos
+}
+
+// Next scans the next JPEG image of the stream like ScanJPEG, and reads the image
+// data up to and including the EOI marker of the image, so that the next call
+// starts with the following image. exifFn and xmpFn are run during the scan.
+//
+// The Metadata can not read from the stream after the scan: Exif and Xmp return
+// ErrStreamNotSeekable, use exifFn and xmpFn to decode the metadata.
+//
+// Returns io.EOF when the stream does not have another JPEG image, and
+// ErrUnexpectedEOF if the stream ends before the EOI marker of the image.
+func (s *Stream) Next(exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m, err = ScanJPEG(s.sr, exifFn, xmpFn)
+	switch err {
+	case nil, ErrNoExif:
+	case ErrNoJPEGMarker:
+		if m.pos == 0 {
+			// No SOI marker before the end of the stream
+			return m, io.EOF
+		}
+		return m, err
+	default:
+		return m, err
+	}
+	if skipErr := s.sr.skipToEOI(); skipErr != nil {
+		return m, skipErr
+	}
+	return m, err
+}
+
+// streamReader is a meta.Reader and a peekReader of a stream
+// that does not support ReadAt and Seek.
+type streamReader struct {
+	br  *bufio.Reader
+	pos int64
+}
+
+func (sr *streamReader) Read(p []byte) (n int, err error) {
+	n, err = sr.br.Read(p)
+	sr.pos += int64(n)
+	return
+}
+
+func (sr *streamReader) Peek(n int) ([]byte, error) {
+	return sr.br.Peek(n)
+}
+
+func (sr *streamReader) Discard(n int) (discarded int, err error) {
+	discarded, err = sr.br.Discard(n)
+	sr.pos += int64(discarded)
+	return
+}
+
+// ReadAt returns ErrStreamNotSeekable.
+func (sr *streamReader) ReadAt(p []byte, off int64) (int, error) {
+	return 0, ErrStreamNotSeekable
+}
+
+// Seek returns the position of the stream for Seek(0, io.SeekCurrent),
+// otherwise it returns ErrStreamNotSeekable.
+func (sr *streamReader) Seek(offset int64, whence int) (int64, error) {
+	if offset == 0 && whence == io.SeekCurrent {
+		return sr.pos, nil
+	}
+	return sr.pos, ErrStreamNotSeekable
+}
+
+func (sr *streamReader) readByte() (b byte, err error) {
+	if b, err = sr.br.ReadByte(); err != nil {
+		return 0, ErrUnexpectedEOF
+	}
+	sr.pos++
+	return b, nil
+}
+
+// skipToEOI reads the segments and the entropy coded image data that
+// follow the SOS marker up to and including the EOI marker.
+func (sr *streamReader) skipToEOI() error {
+	for {
+		b, err := sr.readByte()
+		if err != nil {
+			return err
+		}
+		if b != markerFirstByte {
+			continue
+		}
+		// Fill bytes before a marker
+		for b == markerFirstByte {
+			if b, err = sr.readByte(); err != nil {
+				return err
+			}
+		}
+		switch {
+		case b == markerEOI:
+			return nil
+		case b == 0x00 || isStandaloneMarker(b):
+			// Stuffed zero byte of the image data and restart markers
+		default:
+			// Segments between scans of progressive images
+			var length [2]byte
+			if _, err = io.ReadFull(sr, length[:]); err != nil {
+				return ErrUnexpectedEOF
+			}
+			if n := int(jpegByteOrder.Uint16(length[:])) - 2; n > 0 {
+				if _, err = sr.Discard(n); err != nil {
+					return ErrUnexpectedEOF
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// onlyReader hides the other methods of a reader.
+type onlyReader struct {
+	r io.Reader
+}
+
+func (or onlyReader) Read(p []byte) (int, error) { return or.r.Read(p) }
+
+func TestStream(t *testing.T) {
+	images := [][]byte{
+		readFile(t, "../assets/JPEG.jpg"),
+		readFile(t, "../assets/a2.jpg"),
+		readFile(t, "../assets/NoExif.jpg"),
+	}
+	dims := []meta.Dimensions{meta.NewDimensions(1000, 563), meta.NewDimensions(1024, 1280), meta.NewDimensions(50, 50)}
+
+	// Multipart MJPEG stream
+	var stream []byte
+	var ends []int64
+	for _, img := range images {
+		stream = append(stream, "--boundary\r\nContent-Type: image/jpeg\r\n\r\n"...)
+		stream = append(stream, img...)
+		ends = append(ends, int64(len(stream)))
+		stream = append(stream, "\r\n"...)
+	}
+	stream = append(stream, "--boundary--\r\n"...)
+
+	s := NewStream(onlyReader{bytes.NewReader(stream)})
+	for i := range images {
+		exifFound := false
+		m, err := s.Next(func(r io.Reader, header meta.ExifHeader) error {
+			exifFound = true
+			return nil
+		}, nil)
+		if err != nil && err != ErrNoExif {
+			t.Fatalf("image %d: %v", i, err)
+		}
+		if m.Dimensions() != dims[i] {
+			t.Errorf("Incorrect image %d dimensions wanted %s got %s", i, dims[i], m.Dimensions())
+		}
+		if exifFound != m.ExifHeader.IsValid() {
+			t.Errorf("Incorrect image %d exifFn", i)
+		}
+		if _, err = m.Exif(); err != ErrStreamNotSeekable {
+			t.Errorf("Incorrect Exif error wanted %v got %v", ErrStreamNotSeekable, err)
+		}
+		if s.Offset() != ends[i] {
+			t.Errorf("Incorrect image %d end offset wanted %d got %d", i, ends[i], s.Offset())
+		}
+	}
+	if _, err := s.Next(nil, nil); err != io.EOF {
+		t.Errorf("Incorrect error wanted %v got %v", io.EOF, err)
+	}
+	if _, err := s.Next(nil, nil); err != io.EOF {
+		t.Errorf("Incorrect error wanted %v got %v", io.EOF, err)
+	}
+
+	// Truncated image data
+	s = NewStream(bytes.NewReader(images[0][:len(images[0])-100]))
+	if _, err := s.Next(nil, nil); err != ErrUnexpectedEOF {
+		t.Errorf("Incorrect error wanted %v got %v", ErrUnexpectedEOF, err)
+	}
+}
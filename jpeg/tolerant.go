@@ -0,0 +1,73 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// ScanJPEGTolerant scans a reader for JPEG Image markers like ScanJPEG, and
+// recovers from truncated and corrupt JPEGs instead of stopping the scan:
+//   - The marker of a segment with a length smaller than 2 is skipped.
+//   - Bytes that are not a marker, such as the remainder of a segment with an
+//     invalid length, are skipped up to the next 0xFF marker.
+//   - An Exif or XMP segment that is shorter than its header is skipped.
+//   - The metadata read before the end of a truncated JPEG is returned.
+//
+// Each recovery is recorded as a warning, see Metadata.Warnings.
+//
+// Returns the error ErrNoJPEGMarker if a JPEG SOI marker was not found and
+// ErrNoExif if the JPEG does not have an Exif segment.
+func ScanJPEGTolerant(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = newMetdata(mr, exifFn, xmpFn)
+	m.tolerant = true
+	err = m.scan()
+	return
+}
+
+// Warnings returns the problems that ScanJPEGTolerant recovered from in the
+// order they were found. The warnings wrap ErrCorruptSegment, ErrInvalidMarker
+// or ErrUnexpectedEOF and include the offset where the problem was found.
+func (m Metadata) Warnings() []error {
+	return m.warnings
+}
+
+// warn adds a warning for err at the offset of the reader.
+func (m *Metadata) warn(err error) {
+	m.warnings = append(m.warnings, fmt.Errorf("%w at offset %d", err, m.offset()))
+}
+
+// skip counts a byte that is skipped because it is not a marker.
+func (m *Metadata) skip() {
+	if m.skipped == 0 {
+		m.skipOffset = m.offset()
+	}
+	m.skipped++
+}
+
+// resync adds a warning for the bytes that were skipped before a marker.
+func (m *Metadata) resync() {
+	if m.skipped > 0 {
+		m.warnings = append(m.warnings, fmt.Errorf("%w: %d bytes skipped at offset %d", ErrInvalidMarker, m.skipped, m.skipOffset))
+		m.skipped = 0
+	}
+}
+
+// validSegment returns false and adds a warning if the length of the segment
+// of buf is smaller than the length of its length field.
+func (m *Metadata) validSegment(buf []byte) bool {
+	marker := buf[1]
+	if marker < markerSOF0 || marker == markerFirstByte || isStandaloneMarker(marker) {
+		return true
+	}
+	if length := jpegByteOrder.Uint16(buf[2:4]); length < 2 {
+		m.warnings = append(m.warnings, fmt.Errorf("%w: segment 0x%02X with length %d at offset %d", ErrCorruptSegment, marker, length, m.offset()))
+		return false
+	}
+	return true
+}
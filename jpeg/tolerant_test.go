@@ -0,0 +1,85 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+func TestScanJPEGTolerant(t *testing.T) {
+	a1 := readFile(t, "../assets/a1.jpg")
+	img := mpfTestImage(nil, 64, 48)
+
+	// a1.jpg has an APP0 segment at 2 and an APP1 Exif segment at 20 with a length of 760.
+	garbage := append(append(append([]byte{}, a1[:20]...), 0x00, 0x12, 0x34), a1[20:]...)
+	truncated := a1[:20+2+760+100]
+	// A COM segment with an invalid length
+	invalidLength := append(append([]byte{}, img[:2]...), markerFirstByte, markerCOM, 0, 1, 'a', 'b')
+	invalidLength = append(invalidLength, img[2:]...)
+	// An APP1 Exif segment that is shorter than its header
+	corruptExif := append(append([]byte{}, img[:2]...), markerFirstByte, markerAPP1, 0, 6, 'E', 'x', 'i', 'f', 0, 0)
+	corruptExif = append(corruptExif, img[2:]...)
+
+	tests := []struct {
+		name     string
+		data     []byte
+		err      error
+		scanErr  error
+		warnings []error
+		exif     bool
+		w, h     uint32
+	}{
+		{"a1.jpg", a1, nil, nil, nil, true, 389, 259},
+		{"a2.jpg", readFile(t, "../assets/a2.jpg"), ErrNoExif, ErrNoExif, nil, false, 1024, 1280},
+		{"Garbage", garbage, nil, nil, []error{ErrInvalidMarker}, true, 389, 259},
+		{"Truncated", truncated, nil, ErrUnexpectedEOF, []error{ErrUnexpectedEOF}, true, 0, 0},
+		{"InvalidLength", invalidLength, ErrNoExif, ErrNoExif, []error{ErrCorruptSegment, ErrInvalidMarker}, false, 64, 48},
+		{"CorruptExif", corruptExif, ErrNoExif, ErrCorruptSegment, []error{ErrCorruptSegment, ErrInvalidMarker}, false, 64, 48},
+		{"NoSOI", bytes.Repeat([]byte("no JPEG "), 4), ErrNoJPEGMarker, ErrNoJPEGMarker, nil, false, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := ScanJPEG(bytes.NewReader(test.data), nil, nil); err != test.scanErr {
+				t.Errorf("Incorrect ScanJPEG error wanted %v got %v", test.scanErr, err)
+			}
+			for _, r := range []meta.Reader{bytes.NewReader(test.data), readerOnly{bytes.NewReader(test.data)}} {
+				m, err := ScanJPEGTolerant(r, nil, nil)
+				if err != test.err {
+					t.Fatalf("Incorrect error wanted %v got %v", test.err, err)
+				}
+				warnings := m.Warnings()
+				if len(warnings) != len(test.warnings) {
+					t.Fatalf("Incorrect warnings wanted %v got %v", test.warnings, warnings)
+				}
+				for i := range warnings {
+					if !errors.Is(warnings[i], test.warnings[i]) {
+						t.Errorf("Incorrect warning wanted %v got %v", test.warnings[i], warnings[i])
+					}
+				}
+				if m.ExifHeader.IsValid() != test.exif {
+					t.Errorf("Incorrect Exif header wanted %t got %t", test.exif, m.ExifHeader.IsValid())
+				}
+				if w, h := m.Dimensions().Size(); w != test.w || h != test.h {
+					t.Errorf("Incorrect Jpeg Image size wanted %dx%d got %dx%d", test.w, test.h, w, h)
+				}
+			}
+		})
+	}
+}
+
+func TestScanJPEGUnknownAPP1(t *testing.T) {
+	app1 := []byte{markerFirstByte, markerAPP1, 0, 8, 'O', 't', 'h', 'e', 'r', 0}
+	m, err := ScanJPEG(bytes.NewReader(mpfTestImage(app1, 64, 48)), nil, nil)
+	if err != ErrNoExif {
+		t.Fatalf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+	if w, h := m.Dimensions().Size(); w != 64 || h != 48 {
+		t.Errorf("Incorrect Jpeg Image size wanted %dx%d got %dx%d", 64, 48, w, h)
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// Trailer identifiers
+const (
+	// samsungMotionPhotoTag precedes the video of a Samsung motion photo.
+	samsungMotionPhotoTag = "MotionPhoto_Data"
+
+	// sefHeader and sefFooter enclose the directory of a Samsung SEF trailer.
+	sefHeader = "SEFH"
+	sefFooter = "SEFT"
+
+	// maxXMPTrailerLength is the length that is searched for the end of an XMP trailer.
+	maxXMPTrailerLength = 4 << 20
+)
+
+// TrailerKind is the kind of data that follows the EOI marker of a JPEG image.
+type TrailerKind uint8
+
+// Trailer Kinds
+const (
+	TrailerUnknown TrailerKind = iota
+	TrailerPadding
+	TrailerJPEG
+	TrailerVideo
+	TrailerXMP
+	TrailerSamsung
+)
+
+func (k TrailerKind) String() string {
+	switch k {
+	case TrailerPadding:
+		return "Padding"
+	case TrailerJPEG:
+		return "JPEG"
+	case TrailerVideo:
+		return "Video"
+	case TrailerXMP:
+		return "XMP"
+	case TrailerSamsung:
+		return "Samsung"
+	}
+	return "Unknown"
+}
+
+// Trailer is data that follows the EOI marker of a JPEG image, ie. the images
+// of a Multi-Picture Format file, the video of a motion photo or an XMP packet.
+type Trailer struct {
+	Kind TrailerKind
+
+	// Offset from the start of the reader and Length of the data in bytes
+	Offset, Length uint32
+
+	r io.ReaderAt
+}
+
+// Reader returns a reader of the data of the trailer.
+func (t Trailer) Reader() io.Reader {
+	return io.NewSectionReader(t.r, int64(t.Offset), int64(t.Length))
+}
+
+// Trailers returns the data that follows the EOI marker of the JPEG image in r,
+// which has size bytes. The data is split into trailers in file order:
+//   - TrailerJPEG: a JPEG image, ie. an image of a Multi-Picture Format file.
+//   - TrailerVideo: an MP4 or QuickTime video of a motion photo.
+//   - TrailerXMP: an XMP packet.
+//   - TrailerSamsung: the "MotionPhoto_Data" tag that precedes the video of a
+//     Samsung motion photo, and the SEF trailer at the end of Samsung files.
+//   - TrailerPadding: zero bytes.
+//   - TrailerUnknown: the remaining data up to the SEF trailer or the end of r.
+//
+// The entropy coded image data is read to find the EOI marker of the image.
+// Returns nil if the image does not have data after its EOI marker.
+//
+// Returns the errors of Segments, and ErrUnexpectedEOF if r ends before the
+// EOI marker of the image.
+func Trailers(r io.ReaderAt, size int64) ([]Trailer, error) {
+	end, err := imageEnd(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	limit := size
+	if start, ok := sefTrailer(r, end, size); ok {
+		limit = start
+	}
+	var trailers []Trailer
+	for pos := end; pos < limit; pos += int64(trailers[len(trailers)-1].Length) {
+		trailers = append(trailers, readTrailer(r, pos, limit))
+	}
+	if limit < size {
+		trailers = append(trailers, Trailer{Kind: TrailerSamsung, Offset: uint32(limit), Length: uint32(size - limit), r: r})
+	}
+	return trailers, nil
+}
+
+// imageEnd returns the offset after the EOI marker of the JPEG image at offset of r.
+func imageEnd(r io.ReaderAt, offset, size int64) (int64, error) {
+	segments, err := Segments(io.NewSectionReader(r, offset, size-offset))
+	if err != nil {
+		return 0, err
+	}
+	last := segments[len(segments)-1]
+	if last.Marker == markerEOI {
+		return offset + int64(last.Offset) + 2, nil
+	}
+	// Image data after the SOS segment
+	start := offset + int64(last.PayloadOffset()+last.Length)
+	sr := &streamReader{br: bufio.NewReader(io.NewSectionReader(r, start, size-start)), pos: start}
+	if err = sr.skipToEOI(); err != nil {
+		return 0, err
+	}
+	return sr.pos, nil
+}
+
+// readTrailer returns the trailer at pos of r that ends before limit.
+func readTrailer(r io.ReaderAt, pos, limit int64) Trailer {
+	t := Trailer{Kind: TrailerUnknown, Offset: uint32(pos), Length: uint32(limit - pos), r: r}
+	var buf [16]byte
+	n, _ := r.ReadAt(buf[:], pos)
+	if int64(n) > limit-pos {
+		n = int(limit - pos)
+	}
+	b := buf[:n]
+	switch {
+	case len(b) >= 3 && isSOIMarker(b) && b[2] == markerFirstByte:
+		if end, err := imageEnd(r, pos, limit); err == nil {
+			t.Kind, t.Length = TrailerJPEG, uint32(end-pos)
+		}
+	case len(b) >= 8 && string(b[4:8]) == "ftyp":
+		if length := videoLength(r, pos, limit); length > 0 {
+			t.Kind, t.Length = TrailerVideo, uint32(length)
+		}
+	case string(b) == samsungMotionPhotoTag:
+		t.Kind, t.Length = TrailerSamsung, uint32(len(samsungMotionPhotoTag))
+	case bytes.HasPrefix(b, []byte("<?xpacket")) || bytes.HasPrefix(b, []byte("<x:xmpmeta")):
+		t.Kind, t.Length = TrailerXMP, uint32(xmpTrailerLength(r, pos, limit))
+	case len(b) > 0 && b[0] == 0:
+		t.Kind, t.Length = TrailerPadding, uint32(paddingLength(r, pos, limit))
+	}
+	return t
+}
+
+// videoLength returns the length of the top-level boxes of an MP4
+// or QuickTime video at pos of r, which end before limit.
+func videoLength(r io.ReaderAt, pos, limit int64) int64 {
+	var buf [16]byte
+	p := pos
+	for p+8 <= limit {
+		if n, _ := r.ReadAt(buf[:], p); n < 8 || !isBoxType(buf[4:8]) {
+			break
+		}
+		size := int64(binary.BigEndian.Uint32(buf[0:4]))
+		switch size {
+		case 0:
+			// The last box extends to the end of the file
+			size = limit - p
+		case 1:
+			// 64 bit box size
+			size = int64(binary.BigEndian.Uint64(buf[8:16]))
+		}
+		if size < 8 || size > limit-p {
+			break
+		}
+		p += size
+	}
+	return p - pos
+}
+
+// isBoxType returns true if the type of a box is printable ASCII.
+func isBoxType(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// xmpTrailerLength returns the length of the XMP packet at pos of r up to and
+// including the xpacket end processing instruction or the x:xmpmeta end tag.
+func xmpTrailerLength(r io.ReaderAt, pos, limit int64) int64 {
+	length := limit - pos
+	if length > maxXMPTrailerLength {
+		length = maxXMPTrailerLength
+	}
+	buf := make([]byte, length)
+	n, _ := r.ReadAt(buf, pos)
+	buf = buf[:n]
+	if i := bytes.Index(buf, []byte("<?xpacket end=")); i >= 0 {
+		if j := bytes.Index(buf[i:], []byte("?>")); j >= 0 {
+			return int64(i + j + 2)
+		}
+	}
+	if i := bytes.Index(buf, []byte("</x:xmpmeta>")); i >= 0 {
+		return int64(i + len("</x:xmpmeta>"))
+	}
+	return limit - pos
+}
+
+// paddingLength returns the number of zero bytes at pos of r before limit.
+func paddingLength(r io.ReaderAt, pos, limit int64) int64 {
+	buf := make([]byte, 4096)
+	p := pos
+	for p < limit {
+		n, _ := r.ReadAt(buf, p)
+		if int64(n) > limit-p {
+			n = int(limit - p)
+		}
+		if n == 0 {
+			break
+		}
+		for _, c := range buf[:n] {
+			if c != 0 {
+				return p - pos
+			}
+			p++
+		}
+	}
+	return p - pos
+}
+
+// sefTrailer returns the offset of the Samsung SEF trailer at the end of r,
+// which has size bytes, and true if the trailer follows end.
+// The trailer ends with the length of the trailer and "SEFT".
+func sefTrailer(r io.ReaderAt, end, size int64) (int64, bool) {
+	var buf [8]byte
+	if size-end < 16 {
+		return 0, false
+	}
+	if n, _ := r.ReadAt(buf[:], size-8); n < 8 || string(buf[4:8]) != sefFooter {
+		return 0, false
+	}
+	start := size - 8 - int64(binary.LittleEndian.Uint32(buf[0:4]))
+	if start < end {
+		return 0, false
+	}
+	if n, _ := r.ReadAt(buf[:4], start); n < 4 || string(buf[:4]) != sefHeader {
+		return 0, false
+	}
+	return start, true
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestTrailers(t *testing.T) {
+	a2 := readFile(t, "../assets/a2.jpg")
+	img := mpfTestImage(nil, 64, 48)
+	video := []byte{0, 0, 0, 16, 'f', 't', 'y', 'p', 'i', 's', 'o', 'm', 0, 0, 0, 0}
+	video = append(video, 0, 0, 0, 12, 'm', 'd', 'a', 't', 1, 2, 3, 4)
+	sef := []byte("SEFH\x01\x00\x00\x00")
+	sef = append(sef, byte(len(sef)), 0, 0, 0, 'S', 'E', 'F', 'T')
+	xmpPacket := []byte("<?xpacket begin=''?><x:xmpmeta></x:xmpmeta><?xpacket end='w'?>")
+
+	type part struct {
+		kind TrailerKind
+		data []byte
+	}
+	tests := []struct {
+		name  string
+		image []byte
+		parts []part
+	}{
+		{"NoTrailer", img, nil},
+		{"Progressive", a2, nil},
+		{"SamsungMotionPhoto", a2, []part{{TrailerSamsung, []byte(samsungMotionPhotoTag)}, {TrailerVideo, video}, {TrailerSamsung, sef}}},
+		{"MotionPhoto", img, []part{{TrailerVideo, video}}},
+		{"MPF", img, []part{{TrailerJPEG, a2}, {TrailerJPEG, img}, {TrailerPadding, make([]byte, 100)}}},
+		{"XMP", img, []part{{TrailerXMP, xmpPacket}, {TrailerUnknown, []byte("unknown")}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := append([]byte{}, test.image...)
+			for _, p := range test.parts {
+				data = append(data, p.data...)
+			}
+			trailers, err := Trailers(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(trailers) != len(test.parts) {
+				t.Fatalf("Incorrect number of trailers wanted %d got %d: %v", len(test.parts), len(trailers), trailers)
+			}
+			offset := uint32(len(test.image))
+			for i, p := range test.parts {
+				tr := trailers[i]
+				if tr.Kind != p.kind || tr.Offset != offset || tr.Length != uint32(len(p.data)) {
+					t.Errorf("Incorrect trailer %d wanted %s %d %d got %s %d %d", i, p.kind, offset, len(p.data), tr.Kind, tr.Offset, tr.Length)
+				}
+				b, err := io.ReadAll(tr.Reader())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(b, p.data) {
+					t.Errorf("Incorrect trailer %d data", i)
+				}
+				offset += uint32(len(p.data))
+			}
+		})
+	}
+
+	// The image ends before its EOI marker
+	if _, err := Trailers(bytes.NewReader(a2[:len(a2)-2]), int64(len(a2)-2)); err != ErrUnexpectedEOF {
+		t.Errorf("Incorrect error wanted %v got %v", ErrUnexpectedEOF, err)
+	}
+	if _, err := Trailers(bytes.NewReader(video), int64(len(video))); err != ErrNoJPEGMarker {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoJPEGMarker, err)
+	}
+}
@@ -0,0 +1,501 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Segment prefixes of APP segments after the marker and length bytes.
+var (
+	exifSegmentPrefix         = []byte("Exif\x00\x00")
+	xmpSegmentPrefix          = []byte("http://ns.adobe.com/xap/1.0/\x00")
+	xmpExtensionSegmentPrefix = []byte("http://ns.adobe.com/xmp/extension/\x00")
+	iccSegmentPrefix          = []byte("ICC_PROFILE\x00")
+	mpfSegmentPrefix          = []byte("MPF\x00")
+)
+
+// segmentFn is called by copySegments with each segment of a JPEG. seg includes the
+// marker and length bytes. segmentFn writes the segment to w to keep it.
+type segmentFn func(w io.Writer, marker byte, seg []byte) error
+
+// copySegments reads the JPEG segments from r and calls fn with each segment
+// from the SOI marker to the first SOS marker. The image data after the SOS
+// segment is copied to w unchanged up to and including the EOI marker of the
+// image. The trailers after the EOI marker, ie. the images of a Multi-Picture
+// Format file or the video of a motion photo, are copied when trailers is true.
+//
+// Returns ErrNoJPEGMarker if r does not start with a SOI marker, ErrUnexpectedEOF
+// if r ends before the EOI marker and ErrCorruptSegment for an invalid segment length.
+func copySegments(r io.Reader, w io.Writer, fn segmentFn, trailers bool) error {
+	br := bufio.NewReader(r)
+
+	soi := make([]byte, 2)
+	if _, err := io.ReadFull(br, soi); err != nil || !isSOIMarker(soi) {
+		return ErrNoJPEGMarker
+	}
+	if err := fn(w, markerSOI, soi); err != nil {
+		return err
+	}
+
+	for {
+		marker, err := readMarker(br)
+		if err != nil {
+			return err
+		}
+		var seg []byte
+		switch {
+		case marker == markerEOI, marker == 0x01, marker >= 0xD0 && marker <= 0xD7:
+			// Markers without a length
+			seg = []byte{markerFirstByte, marker}
+		default:
+			if seg, err = readSegment(br, marker); err != nil {
+				return err
+			}
+		}
+		if err = fn(w, marker, seg); err != nil {
+			return err
+		}
+		switch marker {
+		case markerSOS:
+			if err = copyImageData(br, w); err != nil || !trailers {
+				return err
+			}
+			_, err = io.Copy(w, br)
+			return err
+		case markerEOI:
+			return nil
+		}
+	}
+}
+
+// copyImageData copies the entropy coded image data and the segments between
+// the scans of progressive images from br to w up to and including the EOI marker.
+func copyImageData(br *bufio.Reader, w io.Writer) error {
+	for {
+		data, err := br.ReadSlice(markerFirstByte)
+		if _, werr := w.Write(data); werr != nil {
+			return werr
+		}
+		switch err {
+		case nil:
+		case bufio.ErrBufferFull:
+			continue
+		default:
+			return ErrUnexpectedEOF
+		}
+
+		// Fill bytes before a marker
+		marker := byte(markerFirstByte)
+		for marker == markerFirstByte {
+			if marker, err = br.ReadByte(); err != nil {
+				return ErrUnexpectedEOF
+			}
+			if _, err = w.Write([]byte{marker}); err != nil {
+				return err
+			}
+		}
+		switch {
+		case marker == markerEOI:
+			return nil
+		case marker == 0x00 || isStandaloneMarker(marker):
+			// Stuffed zero byte of the image data and restart markers
+		default:
+			// Segments between scans of progressive images
+			var length [2]byte
+			if _, err = io.ReadFull(br, length[:]); err != nil {
+				return ErrUnexpectedEOF
+			}
+			if _, err = w.Write(length[:]); err != nil {
+				return err
+			}
+			if n := int64(jpegByteOrder.Uint16(length[:])) - 2; n > 0 {
+				if _, err = io.CopyN(w, br, n); err == io.EOF {
+					return ErrUnexpectedEOF
+				} else if err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// readMarker reads the next marker from br. Fill bytes (0xFF) before the marker are skipped.
+func readMarker(br *bufio.Reader) (marker byte, err error) {
+	if marker, err = br.ReadByte(); err != nil {
+		return 0, ErrUnexpectedEOF
+	}
+	if marker != markerFirstByte {
+		return 0, ErrNoJPEGMarker
+	}
+	for marker == markerFirstByte {
+		if marker, err = br.ReadByte(); err != nil {
+			return 0, ErrUnexpectedEOF
+		}
+	}
+	return marker, nil
+}
+
+// readSegment reads a segment with a length from br. The returned segment
+// includes the marker and length bytes.
+func readSegment(br *bufio.Reader, marker byte) ([]byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, ErrUnexpectedEOF
+	}
+	length := int(jpegByteOrder.Uint16(header[:]))
+	if length < 2 {
+		return nil, ErrCorruptSegment
+	}
+	seg := make([]byte, length+2)
+	seg[0], seg[1], seg[2], seg[3] = markerFirstByte, marker, header[0], header[1]
+	if _, err := io.ReadFull(br, seg[4:]); err != nil {
+		return nil, ErrUnexpectedEOF
+	}
+	return seg, nil
+}
+
+// segmentHasPrefix returns true if the data of seg starts with prefix.
+func segmentHasPrefix(seg []byte, prefix []byte) bool {
+	return len(seg) >= 4 && bytes.HasPrefix(seg[4:], prefix)
+}
+
+// writeSegment writes seg to w.
+func writeSegment(w io.Writer, marker byte, seg []byte) error {
+	_, err := w.Write(seg)
+	return err
+}
+
+// StripOptions for Strip. The zero value removes all metadata segments.
+type StripOptions struct {
+	// KeepExif keeps the APP1 Exif segment.
+	KeepExif bool
+
+	// KeepXMP keeps the APP1 XMP segments.
+	KeepXMP bool
+
+	// KeepICC keeps the APP2 ICC profile segments. Without them
+	// colors can render differently for images that are not sRGB.
+	KeepICC bool
+
+	// KeepComments keeps COM segments.
+	KeepComments bool
+
+	// KeepTrailers keeps the data after the EOI marker of the image, ie. the
+	// images of a Multi-Picture Format file and the video of a motion photo,
+	// which can have their own metadata. See Trailers.
+	KeepTrailers bool
+}
+
+// Strip copies the JPEG from r to w without its metadata segments.
+// The APP1 Exif and XMP, APP2 ICC profile, APP13 Photoshop (IPTC), COM and
+// other application segments are removed unless they are kept with opts.
+// The APP0 JFIF and APP14 Adobe segments are needed to decode the image and are kept.
+// The image data is not re-encoded. The data after the EOI marker of the image is
+// removed unless it is kept with opts.
+func Strip(r io.Reader, w io.Writer, opts StripOptions) error {
+	bw := bufio.NewWriter(w)
+	err := copySegments(r, bw, func(w io.Writer, marker byte, seg []byte) error {
+		if stripSegment(marker, seg, opts) {
+			return nil
+		}
+		return writeSegment(w, marker, seg)
+	}, opts.KeepTrailers)
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// stripSegment returns true if the segment should be removed by Strip.
+func stripSegment(marker byte, seg []byte, opts StripOptions) bool {
+	switch {
+	case marker == markerAPP0, marker == markerAPP14:
+		return false
+	case marker == markerAPP1:
+		if segmentHasPrefix(seg, exifSegmentPrefix) {
+			return !opts.KeepExif
+		}
+		if segmentHasPrefix(seg, xmpSegmentPrefix) || segmentHasPrefix(seg, xmpExtensionSegmentPrefix) {
+			return !opts.KeepXMP
+		}
+		return true
+	case marker == markerAPP2 && segmentHasPrefix(seg, iccSegmentPrefix):
+		return !opts.KeepICC
+	case marker == markerCOM:
+		return !opts.KeepComments
+	case marker >= markerAPP0 && marker <= 0xEF:
+		return true
+	}
+	return false
+}
+
+// maxSegmentLength is the largest length of a JPEG segment. The length includes
+// the 2 length bytes.
+const maxSegmentLength = 0xFFFF
+
+// MaxExifLength is the largest Exif block that fits in the APP1 Exif segment
+// written by Rewrite.
+const MaxExifLength = maxSegmentLength - 2 - len("Exif\x00\x00")
+
+// RewriteOptions are the metadata segments written by Rewrite.
+// A nil field keeps the segments of the JPEG unchanged.
+type RewriteOptions struct {
+	// Exif is a Tiff/Exif block without the "Exif\x00\x00" prefix,
+	// as returned by exif.Builder.Encode.
+	Exif []byte
+
+	// XMP is an XMP packet, as returned by xmp.Marshal.
+	XMP []byte
+
+	// ExtendedXMP is an Extended XMP packet that is split across APP1 XMP
+	// Extension segments after the XMP segment. It is only written with XMP,
+	// which refers to it with the GUID of ExtendedXMPGUID in its
+	// xmpNote:HasExtendedXMP property.
+	ExtendedXMP []byte
+
+	// ICC is an ICC profile. Profiles larger than a segment are
+	// split across multiple APP2 segments.
+	ICC []byte
+
+	// IPTC is the IPTC-IIM data of the Photoshop APP13 segment,
+	// as returned by iptc.IPTC.Encode.
+	IPTC []byte
+
+	// RemoveTrailers removes the data after the EOI marker of the image and
+	// the APP2 Multi-Picture Format segment that indexes it. See Trailers.
+	RemoveTrailers bool
+}
+
+// Rewrite copies the JPEG from r to w with the APP1 Exif, APP1 XMP, APP2 ICC profile
+// and APP13 IPTC segments from opts. Existing segments are replaced in place, missing
+// segments are inserted after the SOI marker and APP0 JFIF segment in the order Exif,
+// XMP, ICC, IPTC. Extended XMP segments are replaced with the segments of ExtendedXMP
+// when the XMP is replaced.
+// The IPTC replaces the IPTC-NAA resource of the Photoshop segment, its other image
+// resources are kept.
+//
+// All other segments, the image data and the data after the EOI marker of the
+// image are copied unchanged, unless the data after the EOI marker is removed with opts.
+// Returns ErrSegmentTooLarge if the Exif, XMP or Photoshop image resources do not fit
+// in a single segment, the ICC profile does not fit in 255 segments or ExtendedXMP
+// is larger than 32MiB, and
+// ErrCorruptSegment if the existing Photoshop segment can not be parsed.
+func Rewrite(r io.Reader, w io.Writer, opts RewriteOptions) error {
+	var rw rewriter
+	var err error
+	if opts.Exif != nil {
+		if rw.exif, err = newSegment(markerAPP1, exifSegmentPrefix, opts.Exif); err != nil {
+			return err
+		}
+	}
+	if opts.XMP != nil {
+		seg, err := newSegment(markerAPP1, xmpSegmentPrefix, opts.XMP)
+		if err != nil {
+			return err
+		}
+		rw.xmp = [][]byte{seg}
+		if opts.ExtendedXMP != nil {
+			segs, err := xmpExtensionSegments(opts.ExtendedXMP)
+			if err != nil {
+				return err
+			}
+			rw.xmp = append(rw.xmp, segs...)
+		}
+	}
+	if opts.ICC != nil {
+		if rw.icc, err = iccSegments(opts.ICC); err != nil {
+			return err
+		}
+	}
+	rw.iptc = opts.IPTC
+	rw.removeMPF = opts.RemoveTrailers
+
+	bw := bufio.NewWriter(w)
+	if err = copySegments(r, bw, rw.segment, !opts.RemoveTrailers); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// newSegment returns a segment with marker and the data after prefix.
+func newSegment(marker byte, prefix []byte, data []byte) ([]byte, error) {
+	length := 2 + len(prefix) + len(data)
+	if length > maxSegmentLength {
+		return nil, ErrSegmentTooLarge
+	}
+	seg := make([]byte, 4, length+2)
+	seg[0], seg[1] = markerFirstByte, marker
+	jpegByteOrder.PutUint16(seg[2:4], uint16(length))
+	seg = append(seg, prefix...)
+	return append(seg, data...), nil
+}
+
+// rewriter buffers the segments before the SOS marker so that missing
+// segments can be inserted before they are written.
+type rewriter struct {
+	exif      []byte
+	xmp       [][]byte
+	icc       [][]byte
+	iptc      []byte
+	removeMPF bool
+	segs      [][]byte
+}
+
+// segment is a segmentFn for copySegments.
+func (rw *rewriter) segment(w io.Writer, marker byte, seg []byte) error {
+	if marker != markerSOS && marker != markerEOI {
+		rw.segs = append(rw.segs, seg)
+		return nil
+	}
+	if err := rw.flush(w); err != nil {
+		return err
+	}
+	return writeSegment(w, marker, seg)
+}
+
+// flush writes the buffered segments to w with the Exif, XMP, ICC profile and
+// Photoshop segments replaced or inserted.
+func (rw *rewriter) flush(w io.Writer) error {
+	lead := 0 // SOI
+	for lead+1 < len(rw.segs) && isJFIFSegment(rw.segs[lead+1]) {
+		lead++
+	}
+	// Index of the first and last segment of each kind
+	exifAt, xmpAt, iccAt, psAt := [2]int{-1, -1}, [2]int{-1, -1}, [2]int{-1, -1}, [2]int{-1, -1}
+	var psSegs [][]byte
+	for i, seg := range rw.segs {
+		switch {
+		case isExifSegment(seg):
+			exifAt = segmentIndex(exifAt, i)
+		case isXMPSegment(seg), isXMPExtensionSegment(seg):
+			xmpAt = segmentIndex(xmpAt, i)
+		case isICCSegment(seg):
+			iccAt = segmentIndex(iccAt, i)
+		case isPhotoshopSegment(seg):
+			psAt = segmentIndex(psAt, i)
+			psSegs = append(psSegs, seg)
+		}
+	}
+
+	// Missing segments are inserted after the segments before them
+	exifInsert, xmpInsert, iccInsert, psInsert := -1, -1, -1, -1
+	if rw.exif != nil && exifAt[0] < 0 {
+		exifInsert = lead
+	}
+	if rw.xmp != nil && xmpAt[0] < 0 {
+		xmpInsert = lastIndex(lead, exifAt[1])
+	}
+	if rw.icc != nil && iccAt[0] < 0 {
+		iccInsert = lastIndex(lead, exifAt[1], xmpAt[1])
+	}
+	var ps []byte
+	if rw.iptc != nil {
+		var err error
+		if ps, err = photoshopSegment(psSegs, rw.iptc); err != nil {
+			return err
+		}
+		if psAt[0] < 0 {
+			psInsert = lastIndex(lead, exifAt[1], xmpAt[1], iccAt[1])
+		}
+	}
+
+	out := make([][]byte, 0, len(rw.segs)+len(rw.xmp)+len(rw.icc)+2)
+	for i, seg := range rw.segs {
+		switch {
+		case rw.exif != nil && isExifSegment(seg):
+			if i == exifAt[0] {
+				out = append(out, rw.exif)
+			}
+		case rw.xmp != nil && (isXMPSegment(seg) || isXMPExtensionSegment(seg)):
+			if i == xmpAt[0] {
+				out = append(out, rw.xmp...)
+			}
+		case rw.icc != nil && isICCSegment(seg):
+			if i == iccAt[0] {
+				out = append(out, rw.icc...)
+			}
+		case ps != nil && isPhotoshopSegment(seg):
+			if i == psAt[0] {
+				out = append(out, ps)
+			}
+		case rw.removeMPF && isMPFSegment(seg):
+		default:
+			out = append(out, seg)
+		}
+		if i == exifInsert {
+			out = append(out, rw.exif)
+		}
+		if i == xmpInsert {
+			out = append(out, rw.xmp...)
+		}
+		if i == iccInsert {
+			out = append(out, rw.icc...)
+		}
+		if i == psInsert {
+			out = append(out, ps)
+		}
+	}
+	rw.segs = nil
+
+	for _, seg := range out {
+		if _, err := w.Write(seg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// segmentIndex returns the first and last index updated with i.
+func segmentIndex(at [2]int, i int) [2]int {
+	if at[0] < 0 {
+		at[0] = i
+	}
+	at[1] = i
+	return at
+}
+
+// lastIndex returns the largest of the indexes.
+func lastIndex(idx ...int) (last int) {
+	last = -1
+	for _, i := range idx {
+		if i > last {
+			last = i
+		}
+	}
+	return last
+}
+
+// isJFIFSegment returns true if seg is an APP0 segment.
+func isJFIFSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP0
+}
+
+// isExifSegment returns true if seg is an APP1 Exif segment.
+func isExifSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP1 && segmentHasPrefix(seg, exifSegmentPrefix)
+}
+
+// isXMPSegment returns true if seg is an APP1 XMP segment.
+func isXMPSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP1 && segmentHasPrefix(seg, xmpSegmentPrefix)
+}
+
+// isXMPExtensionSegment returns true if seg is an APP1 extended XMP segment.
+func isXMPExtensionSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP1 && segmentHasPrefix(seg, xmpExtensionSegmentPrefix)
+}
+
+// isICCSegment returns true if seg is an APP2 ICC profile segment.
+func isICCSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP2 && segmentHasPrefix(seg, iccSegmentPrefix)
+}
+
+// isMPFSegment returns true if seg is an APP2 Multi-Picture Format segment.
+func isMPFSegment(seg []byte) bool {
+	return len(seg) > 1 && seg[1] == markerAPP2 && segmentHasPrefix(seg, mpfSegmentPrefix)
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// segmentMarkers returns the markers of the segments of a JPEG up to the SOS marker.
+func segmentMarkers(t *testing.T, buf []byte) (markers []byte) {
+	t.Helper()
+	var out bytes.Buffer
+	err := copySegments(bytes.NewReader(buf), &out, func(w io.Writer, marker byte, seg []byte) error {
+		markers = append(markers, marker)
+		return writeSegment(w, marker, seg)
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, out.Bytes()) {
+		t.Fatalf("copySegments should copy the JPEG unchanged")
+	}
+	return markers
+}
+
+func TestStrip(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := segmentMarkers(t, buf)
+	if !bytes.Equal(want, []byte{markerSOI, markerAPP1, markerAPP1, markerAPP13, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}) {
+		t.Fatalf("Incorrect markers of JPEG.jpg got %x", want)
+	}
+
+	tests := []struct {
+		name    string
+		opts    StripOptions
+		markers []byte
+	}{
+		{"All", StripOptions{}, []byte{markerSOI, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}},
+		{"KeepExif", StripOptions{KeepExif: true}, []byte{markerSOI, markerAPP1, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}},
+		{"KeepXMPAndICC", StripOptions{KeepXMP: true, KeepICC: true}, []byte{markerSOI, markerAPP1, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}},
+	}
+	img, err := jpeg.Decode(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Strip(bytes.NewReader(buf), &out, test.opts); err != nil {
+				t.Fatal(err)
+			}
+			if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers, test.markers) {
+				t.Errorf("Incorrect markers wanted %x got %x", test.markers, markers)
+			}
+			_, err := ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+			if test.opts.KeepExif && err != nil {
+				t.Errorf("Exif should be kept got %v", err)
+			}
+			if !test.opts.KeepExif && err != ErrNoExif {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+			}
+
+			// The image data is unchanged
+			stripped, err := jpeg.Decode(bytes.NewReader(out.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(img.(*image.YCbCr).Y, stripped.(*image.YCbCr).Y) {
+				t.Errorf("Stripped image should decode to the same pixels")
+			}
+		})
+	}
+}
+
+func TestStripErrors(t *testing.T) {
+	var out bytes.Buffer
+	if err := Strip(bytes.NewReader([]byte("GIF89a")), &out, StripOptions{}); err != ErrNoJPEGMarker {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoJPEGMarker, err)
+	}
+	if err := Strip(bytes.NewReader([]byte{markerFirstByte, markerSOI, markerFirstByte, markerAPP1, 0, 16, 'E'}), &out, StripOptions{}); err != ErrUnexpectedEOF {
+		t.Errorf("Incorrect error wanted %v got %v", ErrUnexpectedEOF, err)
+	}
+	if err := Strip(bytes.NewReader([]byte{markerFirstByte, markerSOI, markerFirstByte, markerAPP1, 0, 1}), &out, StripOptions{}); err != ErrCorruptSegment {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptSegment, err)
+	}
+}
+
+func TestStripTrailers(t *testing.T) {
+	// An appended JPEG with Exif, ie. the second image of a Multi-Picture Format file
+	appended := readFile(t, "../testImages/JPEG.jpg")
+	for _, filename := range []string{"../testImages/JPEG.jpg", "../assets/a2.jpg"} {
+		t.Run(filename, func(t *testing.T) {
+			buf := readFile(t, filename)
+			var want bytes.Buffer
+			if err := Strip(bytes.NewReader(buf), &want, StripOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			data := append(append([]byte{}, buf...), appended...)
+
+			var out bytes.Buffer
+			if err := Strip(bytes.NewReader(data), &out, StripOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(want.Bytes(), out.Bytes()) {
+				t.Errorf("Strip should remove the appended JPEG")
+			}
+			if trailers, err := Trailers(bytes.NewReader(out.Bytes()), int64(out.Len())); err != nil || trailers != nil {
+				t.Errorf("Incorrect trailers wanted none got %v (%v)", trailers, err)
+			}
+
+			out.Reset()
+			if err := Strip(bytes.NewReader(data), &out, StripOptions{KeepTrailers: true}); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(append(want.Bytes(), appended...), out.Bytes()) {
+				t.Errorf("Strip with KeepTrailers should keep the appended JPEG")
+			}
+
+			// The image ends before its EOI marker
+			if err := Strip(bytes.NewReader(buf[:len(buf)-2]), &out, StripOptions{}); err != ErrUnexpectedEOF {
+				t.Errorf("Incorrect error wanted %v got %v", ErrUnexpectedEOF, err)
+			}
+		})
+	}
+}
+
+// otherSegments returns the segments of a JPEG up to the SOS marker that are not APP1 segments
+// and the image data after the SOS segment.
+func otherSegments(t *testing.T, buf []byte) (segs [][]byte, data []byte) {
+	t.Helper()
+	var out bytes.Buffer
+	err := copySegments(bytes.NewReader(buf), &out, func(w io.Writer, marker byte, seg []byte) error {
+		if marker != markerAPP1 {
+			segs = append(segs, seg)
+		}
+		if marker == markerSOS {
+			out.Reset()
+		}
+		return nil
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return segs, out.Bytes()
+}
+
+func TestRewrite(t *testing.T) {
+	buf, err := os.ReadFile("../testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := exif.NewBuilder(binary.LittleEndian)
+	if err = b.SetASCII(ifds.IFD0, 0, ifds.Make, "Rewrite"); err != nil {
+		t.Fatal(err)
+	}
+	exifData, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	xmpData, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 4}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stripped bytes.Buffer
+	if err = Strip(bytes.NewReader(buf), &stripped, StripOptions{KeepICC: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		src     []byte
+		opts    RewriteOptions
+		markers []byte
+	}{
+		{"Replace", buf, RewriteOptions{Exif: exifData, XMP: xmpData}, []byte{markerSOI, markerAPP1, markerAPP1, markerAPP13, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}},
+		{"Insert", stripped.Bytes(), RewriteOptions{Exif: exifData, XMP: xmpData}, []byte{markerSOI, markerAPP1, markerAPP1, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}},
+		{"InsertXMP", stripped.Bytes(), RewriteOptions{XMP: xmpData}, []byte{markerSOI, markerAPP1, markerAPP2, markerDQT, markerDRI, markerAPP14, markerSOF0, markerDHT, markerSOS}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Rewrite(bytes.NewReader(test.src), &out, test.opts); err != nil {
+				t.Fatal(err)
+			}
+			if markers := segmentMarkers(t, out.Bytes()); !bytes.Equal(markers, test.markers) {
+				t.Errorf("Incorrect markers wanted %x got %x", test.markers, markers)
+			}
+
+			// Other segments and the image data are unchanged
+			wantSegs, wantData := otherSegments(t, test.src)
+			segs, data := otherSegments(t, out.Bytes())
+			assert.Equal(t, wantSegs, segs)
+			assert.True(t, bytes.Equal(wantData, data), "image data should be unchanged")
+
+			var x xmp.XMP
+			xmpFn := func(r io.Reader, header meta.XmpHeader) (err error) {
+				x, err = xmp.ParseXmp(r)
+				return err
+			}
+			m, err := ScanJPEG(bytes.NewReader(out.Bytes()), nil, xmpFn)
+			if test.opts.Exif == nil {
+				assert.ErrorIs(t, err, ErrNoExif)
+			} else if assert.NoError(t, err) {
+				e, err := exif.ParseExif(bytes.NewReader(out.Bytes()), m.ExifHeader)
+				if assert.NoError(t, err) {
+					assert.Equal(t, "Rewrite", e.CameraMake())
+				}
+			}
+			assert.Equal(t, int8(4), x.Basic.Rating)
+		})
+	}
+
+	// Without metadata the JPEG is copied unchanged
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.Equal(buf, out.Bytes()), "Rewrite without metadata should copy the JPEG unchanged")
+}
+
+func TestRewriteJFIF(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stripped, out bytes.Buffer
+	if err = Strip(bytes.NewReader(buf), &stripped, StripOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err = Rewrite(bytes.NewReader(stripped.Bytes()), &out, RewriteOptions{XMP: []byte("<x:xmpmeta/>")}); err != nil {
+		t.Fatal(err)
+	}
+	// XMP is inserted after the APP0 JFIF segment
+	markers := segmentMarkers(t, out.Bytes())
+	assert.Equal(t, []byte{markerSOI, markerAPP0, markerAPP1, markerSOF0}, markers[:4])
+}
+
+func TestRewriteRemoveTrailers(t *testing.T) {
+	second := mpfTestImage(nil, 64, 48)
+	primary := mpfTestImage(mpfTestSegment(0, uint32(len(second)), 0), 640, 480)
+	data := append(append([]byte{}, primary...), second...)
+
+	var out bytes.Buffer
+	if err := Rewrite(bytes.NewReader(data), &out, RewriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, out.Bytes()) {
+		t.Errorf("Rewrite should keep the trailers")
+	}
+
+	out.Reset()
+	if err := Rewrite(bytes.NewReader(data), &out, RewriteOptions{RemoveTrailers: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := mpfTestImage(nil, 640, 480); !bytes.Equal(want, out.Bytes()) {
+		t.Errorf("Rewrite with RemoveTrailers should remove the trailers and the MPF segment")
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	buf, err := os.ReadFile("../assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{Exif: make([]byte, maxSegmentLength)}); err != ErrSegmentTooLarge {
+		t.Errorf("Incorrect error wanted %v got %v", ErrSegmentTooLarge, err)
+	}
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{XMP: make([]byte, maxSegmentLength-2-len(xmpSegmentPrefix)+1)}); err != ErrSegmentTooLarge {
+		t.Errorf("Incorrect error wanted %v got %v", ErrSegmentTooLarge, err)
+	}
+	if err = Rewrite(bytes.NewReader([]byte("GIF89a")), &out, RewriteOptions{}); err != ErrNoJPEGMarker {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoJPEGMarker, err)
+	}
+}
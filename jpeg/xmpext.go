@@ -0,0 +1,185 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Extended XMP segment header lengths
+const (
+	// xmpExtensionPrefixLength is the length of "http://ns.adobe.com/xmp/extension/\0"
+	xmpExtensionPrefixLength = 35
+
+	// xmpExtensionHeaderLength is the length of the GUID (32), the full
+	// length (4) and the offset (4) of the chunk.
+	xmpExtensionHeaderLength = 40
+
+	// maxExtendedXMPLength is the largest Extended XMP packet that is read.
+	maxExtendedXMPLength = 32 << 20
+
+	// maxXMPExtensionChunkLength is the largest chunk of an APP1 XMP Extension segment.
+	maxXMPExtensionChunkLength = maxSegmentLength - 2 - xmpExtensionPrefixLength - xmpExtensionHeaderLength
+)
+
+// extendedXMP is an Extended XMP packet that is assembled from the
+// chunks of the APP1 XMP Extension segments with the same GUID.
+type extendedXMP struct {
+	guid   string
+	offset uint32 // offset of the first chunk
+	data   []byte
+	read   uint32
+}
+
+// ExtendedXmp returns parsed Extended XMP data from JPEG. The Extended XMP
+// packet is assembled from the APP1 XMP Extension segments with the GUID of
+// the xmpNote:HasExtendedXMP property of the main XMP packet.
+//
+// Returns xmp.ErrNoXMP if the JPEG does not have an Extended XMP packet.
+func (m Metadata) ExtendedXmp() (xmp.XMP, error) {
+	if m.extendedXmp == nil {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	return xmp.ParseXmp(bytes.NewReader(m.extendedXmp))
+}
+
+// ExtendedXmpPacket returns the Extended XMP packet of the JPEG and its GUID
+// from the xmpNote:HasExtendedXMP property of the main XMP packet.
+//
+// Returns xmp.ErrNoXMP if the JPEG does not have an Extended XMP packet.
+func (m Metadata) ExtendedXmpPacket() (guid string, packet []byte, err error) {
+	if m.extendedXmp == nil {
+		return "", nil, xmp.ErrNoXMP
+	}
+	return m.extendedXmpGUID, m.extendedXmp, nil
+}
+
+// ExtendedXMPGUID returns the GUID of an Extended XMP packet, the MD5 digest
+// of the packet as 32 uppercase hexadecimal digits. The main XMP packet refers
+// to the Extended XMP packet with the GUID in its xmpNote:HasExtendedXMP property.
+func ExtendedXMPGUID(packet []byte) string {
+	sum := md5.Sum(packet)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// xmpExtensionSegments returns the APP1 XMP Extension segments of an Extended XMP packet.
+func xmpExtensionSegments(packet []byte) ([][]byte, error) {
+	if len(packet) > maxExtendedXMPLength {
+		return nil, ErrSegmentTooLarge
+	}
+	guid := ExtendedXMPGUID(packet)
+	segs := make([][]byte, 0, (len(packet)+maxXMPExtensionChunkLength-1)/maxXMPExtensionChunkLength)
+	prefix := make([]byte, xmpExtensionPrefixLength+xmpExtensionHeaderLength)
+	copy(prefix, xmpExtensionSegmentPrefix)
+	copy(prefix[xmpExtensionPrefixLength:], guid)
+	jpegByteOrder.PutUint32(prefix[xmpExtensionPrefixLength+32:], uint32(len(packet)))
+	for offset := 0; offset < len(packet); offset += maxXMPExtensionChunkLength {
+		chunk := packet[offset:]
+		if len(chunk) > maxXMPExtensionChunkLength {
+			chunk = chunk[:maxXMPExtensionChunkLength]
+		}
+		jpegByteOrder.PutUint32(prefix[xmpExtensionPrefixLength+36:], uint32(offset))
+		seg, err := newSegment(markerAPP1, prefix, chunk)
+		if err != nil {
+			return nil, err
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+// readXMPExtension reads a chunk of an APP1 XMP Extension segment
+// into the Extended XMP packet with the same GUID.
+func (m *Metadata) readXMPExtension(buf []byte) (err error) {
+	length := int(jpegByteOrder.Uint16(buf[2:4]))
+	if length < 2+xmpExtensionPrefixLength+xmpExtensionHeaderLength || m.pos != 1 {
+		return m.ignoreMarker(buf)
+	}
+	size := length - 2 - xmpExtensionPrefixLength - xmpExtensionHeaderLength
+
+	// Discard App Marker bytes, header length bytes and the prefix
+	if err = m.discard(4 + xmpExtensionPrefixLength); err != nil {
+		return err
+	}
+	if buf, err = m.br.Peek(xmpExtensionHeaderLength); err != nil {
+		return err
+	}
+	guid := string(buf[:32])
+	fullLength := jpegByteOrder.Uint32(buf[32:36])
+	chunkOffset := jpegByteOrder.Uint32(buf[36:40])
+	if err = m.discard(xmpExtensionHeaderLength); err != nil {
+		return err
+	}
+
+	ext := m.xmpExtension(guid, fullLength)
+	if ext == nil || uint64(chunkOffset)+uint64(size) > uint64(fullLength) {
+		// Ignore invalid chunks
+		return m.discard(size)
+	}
+	if chunkOffset == 0 {
+		ext.offset = m.offset()
+	}
+	n, err := io.ReadFull(m.br, ext.data[chunkOffset:chunkOffset+uint32(size)])
+	m.discarded += uint32(n)
+	ext.read += uint32(n)
+	return err
+}
+
+// xmpExtension returns the Extended XMP packet with guid. Returns nil if the
+// length of the packet is too large or differs from the previous chunks.
+func (m *Metadata) xmpExtension(guid string, fullLength uint32) *extendedXMP {
+	for i := range m.xmpExtensions {
+		if m.xmpExtensions[i].guid == guid {
+			if uint32(len(m.xmpExtensions[i].data)) != fullLength {
+				return nil
+			}
+			return &m.xmpExtensions[i]
+		}
+	}
+	if fullLength == 0 || fullLength > maxExtendedXMPLength {
+		return nil
+	}
+	m.xmpExtensions = append(m.xmpExtensions, extendedXMP{guid: guid, data: make([]byte, fullLength)})
+	return &m.xmpExtensions[len(m.xmpExtensions)-1]
+}
+
+// readExtendedXMP selects the complete Extended XMP packet with the GUID of
+// the main XMP packet, and runs xmpFn with the packet. The header of the packet
+// has the offset of the first chunk and the length of the packet.
+func (m *Metadata) readExtendedXMP() error {
+	if len(m.xmpExtensions) == 0 || m.XmpHeader.Length == 0 {
+		return nil
+	}
+	packet := make([]byte, m.XmpHeader.Length)
+	if _, err := m.mr.ReadAt(packet, int64(m.XmpHeader.Offset)); err != nil {
+		return nil
+	}
+	for _, ext := range m.xmpExtensions {
+		if ext.read < uint32(len(ext.data)) || !bytes.Contains(packet, []byte(ext.guid)) {
+			continue
+		}
+		m.extendedXmp, m.extendedXmpGUID = ext.data, ext.guid
+		if m.xmpFn != nil {
+			return m.xmpFn(bytes.NewReader(ext.data), meta.NewXMPHeader(ext.offset, uint32(len(ext.data))))
+		}
+		return nil
+	}
+	return nil
+}
+
+// isXMPExtensionPrefix returns true if
+// buf[4:39] equals "http://ns.adobe.com/xmp/extension/\0",
+// buf[0:2] is AppMarker, buf[2:4] is HeaderLength
+func isXMPExtensionPrefix(buf []byte) bool {
+	return len(buf) >= 4+xmpExtensionPrefixLength &&
+		bytes.Equal(buf[4:4+xmpExtensionPrefixLength], xmpExtensionSegmentPrefix)
+}
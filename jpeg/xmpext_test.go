@@ -0,0 +1,138 @@
+// Copyright (c) 2018-2022 Evan Oberholster. All rights reserved.
+// Use of this source code is governed by a license that can be
+// found in the LICENSE file.
+
+package jpeg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+const testXMPGUID = "0123456789ABCDEF0123456789ABCDEF"
+
+// xmpSegment returns an APP1 segment with the prefix and data.
+func xmpSegment(prefix []byte, data []byte) []byte {
+	seg := []byte{markerFirstByte, markerAPP1, 0, 0}
+	seg = append(seg, prefix...)
+	seg = append(seg, data...)
+	binary.BigEndian.PutUint16(seg[2:4], uint16(len(seg)-2))
+	return seg
+}
+
+// xmpExtensionSegment returns an APP1 XMP Extension segment with the chunk of packet at offset.
+func xmpExtensionSegment(guid string, packet []byte, offset, length int) []byte {
+	data := append([]byte(guid), 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[32:36], uint32(len(packet)))
+	binary.BigEndian.PutUint32(data[36:40], uint32(offset))
+	return xmpSegment(xmpExtensionSegmentPrefix, append(data, packet[offset:offset+length]...))
+}
+
+func TestScanJPEGExtendedXMP(t *testing.T) {
+	main := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:tiff="http://ns.adobe.com/tiff/1.0/" xmlns:xmpNote="http://ns.adobe.com/xmp/note/" tiff:Make="Canon" xmpNote:HasExtendedXMP="` + testXMPGUID + `"/></rdf:RDF></x:xmpmeta>`)
+	extended := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:tiff="http://ns.adobe.com/tiff/1.0/" tiff:Model="` + strings.Repeat("A", 100) + `"/></rdf:RDF></x:xmpmeta>`)
+	other := []byte("<x:xmpmeta/>")
+
+	// Chunks are out of order, and a packet with a different GUID is ignored
+	var segs []byte
+	segs = append(segs, xmpSegment(xmpSegmentPrefix, main)...)
+	segs = append(segs, xmpExtensionSegment(testXMPGUID, extended, 100, len(extended)-100)...)
+	segs = append(segs, xmpExtensionSegment("FEDCBA9876543210FEDCBA9876543210", other, 0, len(other))...)
+	segs = append(segs, xmpExtensionSegment(testXMPGUID, extended, 0, 100)...)
+	data := mpfTestImage(segs, 64, 48)
+
+	for _, r := range []meta.Reader{bytes.NewReader(data), readerOnly{bytes.NewReader(data)}} {
+		var packets [][]byte
+		var headers []meta.XmpHeader
+		xmpFn := func(r io.Reader, header meta.XmpHeader) error {
+			b, err := io.ReadAll(r)
+			packets = append(packets, b)
+			headers = append(headers, header)
+			return err
+		}
+		m, err := ScanJPEG(r, nil, xmpFn)
+		if err != nil && err != ErrNoExif {
+			t.Fatal(err)
+		}
+		if len(packets) != 2 {
+			t.Fatalf("Incorrect number of XMP packets wanted %d got %d", 2, len(packets))
+		}
+		if !bytes.Equal(packets[0], main) || !bytes.Equal(packets[1], extended) {
+			t.Errorf("Incorrect XMP packets got %q and %q", packets[0], packets[1])
+		}
+		// The first chunk follows the main segment and the first extension segments
+		offset := uint32(bytes.LastIndex(data, extended[:100]))
+		if want := meta.NewXMPHeader(offset, uint32(len(extended))); headers[1] != want {
+			t.Errorf("Incorrect Extended XMP header wanted %v got %v", want, headers[1])
+		}
+		if m.XmpHeader != headers[0] {
+			t.Errorf("Incorrect XMP header wanted %v got %v", headers[0], m.XmpHeader)
+		}
+		x, err := m.Xmp()
+		if err != nil || x.Tiff.Make != "Canon" {
+			t.Errorf("Incorrect Xmp got %q (%v)", x.Tiff.Make, err)
+		}
+		x, err = m.ExtendedXmp()
+		if err != nil || x.Tiff.Model != strings.Repeat("A", 100) {
+			t.Errorf("Incorrect ExtendedXmp got %q (%v)", x.Tiff.Model, err)
+		}
+	}
+
+	// An incomplete Extended XMP packet is not delivered
+	segs = append(xmpSegment(xmpSegmentPrefix, main), xmpExtensionSegment(testXMPGUID, extended, 0, 100)...)
+	calls := 0
+	xmpFn := func(r io.Reader, header meta.XmpHeader) error {
+		calls++
+		return nil
+	}
+	m, err := ScanJPEG(bytes.NewReader(mpfTestImage(segs, 64, 48)), nil, xmpFn)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("Incorrect number of XMP packets wanted %d got %d", 1, calls)
+	}
+	if _, err = m.ExtendedXmp(); err == nil {
+		t.Errorf("Incorrect ExtendedXmp error for an incomplete packet")
+	}
+}
+
+func TestRewriteExtendedXMP(t *testing.T) {
+	// The Extended XMP packet is larger than a segment
+	extended := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:tiff="http://ns.adobe.com/tiff/1.0/" tiff:Model="` + strings.Repeat("A", 70000) + `"/></rdf:RDF></x:xmpmeta>`)
+	guid := ExtendedXMPGUID(extended)
+	main := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:xmpNote="http://ns.adobe.com/xmp/note/" xmpNote:HasExtendedXMP="` + guid + `"/></rdf:RDF></x:xmpmeta>`)
+
+	// The existing Extended XMP segments are replaced
+	segs := append(xmpSegment(xmpSegmentPrefix, []byte("<x:xmpmeta/>")), xmpExtensionSegment(testXMPGUID, []byte("<x:xmpmeta/>"), 0, 12)...)
+	var out bytes.Buffer
+	if err := Rewrite(bytes.NewReader(mpfTestImage(segs, 64, 48)), &out, RewriteOptions{XMP: main, ExtendedXMP: extended}); err != nil {
+		t.Fatal(err)
+	}
+	if n := bytes.Count(out.Bytes(), xmpExtensionSegmentPrefix); n != 2 {
+		t.Errorf("Incorrect number of XMP Extension segments wanted %d got %d", 2, n)
+	}
+	m, err := ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+	if err != nil && err != ErrNoExif {
+		t.Fatal(err)
+	}
+	g, packet, err := m.ExtendedXmpPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != guid || !bytes.Equal(packet, extended) {
+		t.Errorf("Incorrect Extended XMP packet wanted %s got %s", guid, g)
+	}
+
+	if _, _, err = (Metadata{}).ExtendedXmpPacket(); err == nil {
+		t.Errorf("Incorrect ExtendedXmpPacket error for a JPEG without Extended XMP")
+	}
+	if len(guid) != 32 || strings.ToUpper(guid) != guid {
+		t.Errorf("Incorrect ExtendedXMPGUID got %s", guid)
+	}
+}
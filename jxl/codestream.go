@@ -0,0 +1,63 @@
+package jxl
+
+import "bytes"
+
+// aspectRatios are the width to height ratios of the size header ratio field,
+// the width is height * ratio[0] / ratio[1].
+var aspectRatios = [8][2]uint64{{0, 0}, {1, 1}, {12, 10}, {4, 3}, {3, 2}, {16, 9}, {5, 4}, {2, 1}}
+
+// bitReader reads the fields of a codestream, least significant bit first.
+type bitReader struct {
+	buf []byte
+	pos uint
+	err error
+}
+
+// u reads an n bit unsigned integer
+func (br *bitReader) u(n uint) (v uint32) {
+	for i := uint(0); i < n; i++ {
+		if br.pos>>3 >= uint(len(br.buf)) {
+			br.err = ErrCorruptCodestream
+			return 0
+		}
+		v |= uint32(br.buf[br.pos>>3]>>(br.pos&7)&1) << i
+		br.pos++
+	}
+	return v
+}
+
+// size reads a dimension of the size header, 1 + U32(Bits(9), Bits(13), Bits(18), Bits(30)).
+func (br *bitReader) size() uint32 {
+	return 1 + br.u([4]uint{9, 13, 18, 30}[br.u(2)])
+}
+
+// readSizeHeader reads the image dimensions from the size header that follows
+// the codestream signature at offset.
+//
+// SizeHeader: div8, height (8 * (1 + u(5)) or size), ratio and width
+// (8 * (1 + u(5)), size or height * ratio).
+func (m *Metadata) readSizeHeader(offset int64) error {
+	buf := make([]byte, sizeHeaderLength)
+	n, _ := m.mr.ReadAt(buf, offset)
+	if !bytes.HasPrefix(buf[:n], codestreamSignature) {
+		return ErrCorruptCodestream
+	}
+	br := bitReader{buf: buf[len(codestreamSignature):n]}
+	div8 := br.u(1) == 1
+	if div8 {
+		m.height = 8 * (1 + br.u(5))
+	} else {
+		m.height = br.size()
+	}
+	if ratio := br.u(3); ratio != 0 {
+		m.width = uint32(uint64(m.height) * aspectRatios[ratio][0] / aspectRatios[ratio][1])
+	} else if div8 {
+		m.width = 8 * (1 + br.u(5))
+	} else {
+		m.width = br.size()
+	}
+	if br.err != nil {
+		m.width, m.height = 0, 0
+	}
+	return br.err
+}
@@ -0,0 +1,323 @@
+// Package jxl reads the dimensions and the Exif and XMP metadata of a JPEG XL Image.
+//
+// A JPEG XL Image is a bare codestream or an ISO-BMFF container with the codestream in
+// "jxlc" or "jxlp" boxes followed or preceded by "Exif" and "xml " metadata boxes. The
+// metadata boxes can be Brotli compressed in "brob" boxes.
+package jxl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif         = meta.ErrNoExif
+	ErrNoJXLSignature = errors.New("no JPEG XL Signature")
+
+	// ErrCorruptBox is returned when a box is truncated or its size is invalid.
+	ErrCorruptBox = errors.New("corrupt JPEG XL box")
+
+	// ErrCorruptCodestream is returned when the size header of the codestream is truncated.
+	ErrCorruptCodestream = errors.New("corrupt JPEG XL codestream")
+)
+
+// Signatures
+var (
+	// codestreamSignature are the first 2 bytes of a bare codestream
+	codestreamSignature = []byte{0xFF, 0x0A}
+
+	// containerSignature is the "JXL " signature box of a container
+	containerSignature = []byte{0x00, 0x00, 0x00, 0x0C, 'J', 'X', 'L', ' ', 0x0D, 0x0A, 0x87, 0x0A}
+)
+
+// jxlByteOrder is the byte order of box sizes and the Exif box Tiff Header offset
+var jxlByteOrder = binary.BigEndian
+
+// Box types
+var (
+	boxJXLC = [4]byte{'j', 'x', 'l', 'c'}
+	boxJXLP = [4]byte{'j', 'x', 'l', 'p'}
+	boxEXIF = [4]byte{'E', 'x', 'i', 'f'}
+	boxXML  = [4]byte{'x', 'm', 'l', ' '}
+	boxBROB = [4]byte{'b', 'r', 'o', 'b'}
+)
+
+// Lengths
+const (
+	boxHeaderLength      = 8
+	boxLargeHeaderLength = 16
+
+	// sizeHeaderLength is the largest length of the codestream signature and size header
+	sizeHeaderLength = 2 + 9
+
+	// maxBoxLength is the largest metadata box and decompressed "brob" box that is read
+	maxBoxLength = 16 << 20
+)
+
+// Metadata from a JPEG XL file
+type Metadata struct {
+	mr meta.Reader
+
+	// ExifHeader is relative to the decompressed box for a Brotli compressed Exif box.
+	ExifHeader meta.ExifHeader
+
+	// XmpHeader is only set for an uncompressed XMP box.
+	XmpHeader meta.XmpHeader
+
+	// Container is true for an ISO-BMFF container and false for a bare codestream
+	Container bool
+
+	// Decode Functions for EXIF and XMP metadata
+	exifFn func(r io.Reader, header meta.ExifHeader) error
+	xmpFn  func(r io.Reader, header meta.XmpHeader) error
+
+	// exif and xmp are the decompressed boxes of Brotli compressed boxes
+	exif []byte
+	xmp  []byte
+
+	width  uint32
+	height uint32
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.width, m.height)
+}
+
+// ImageType returns imagetype.ImageJXL for JPEG XL image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageJXL
+}
+
+// PreviewImage returns a JPEG XL preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from JPEG XL
+func (m Metadata) Exif() (exif.Exif, error) {
+	if !m.ExifHeader.IsValid() {
+		return nil, ErrNoExif
+	}
+	if m.exif != nil {
+		return exif.ParseExif(bytes.NewReader(m.exif), m.ExifHeader)
+	}
+	return exif.ParseExif(m.mr, m.ExifHeader)
+}
+
+// Xmp returns parsed Xmp data from JPEG XL
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	if m.xmp != nil {
+		return xmp.ParseXmp(bytes.NewReader(m.xmp))
+	}
+	if m.XmpHeader.Length == 0 {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+// ScanJXL scans a reader for the JPEG XL size header and the metadata boxes. xmpFn and
+// exifFn are run at their respective positions during the scan. Returns Metadata.
+//
+// Brotli compressed "brob" Exif and XMP boxes are decompressed, exifFn and xmpFn read
+// the decompressed boxes.
+//
+// Returns the error ErrNoJXLSignature if r is not a JPEG XL Image, ErrCorruptBox if a box
+// is truncated and ErrCorruptCodestream if the size header is truncated.
+func ScanJXL(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = Metadata{mr: mr, exifFn: exifFn, xmpFn: xmpFn}
+
+	buf := make([]byte, len(containerSignature))
+	n, _ := mr.ReadAt(buf, 0)
+	switch {
+	case bytes.HasPrefix(buf[:n], codestreamSignature):
+		err = m.readSizeHeader(0)
+	case bytes.Equal(buf[:n], containerSignature):
+		m.Container = true
+		err = m.readBoxes(int64(len(containerSignature)))
+	default:
+		err = ErrNoJXLSignature
+	}
+	return
+}
+
+// boxHeader is the type, data offset and data length of a box
+type boxHeader struct {
+	typ    [4]byte
+	offset int64
+	length int64
+}
+
+// readBoxHeader reads the header of the box at offset. Returns io.EOF at the end of the file.
+func (m *Metadata) readBoxHeader(offset int64) (h boxHeader, err error) {
+	var buf [boxLargeHeaderLength]byte
+	n, err := m.mr.ReadAt(buf[:], offset)
+	if n == 0 && err == io.EOF {
+		return h, io.EOF
+	}
+	if n < boxHeaderLength {
+		return h, ErrCorruptBox
+	}
+	copy(h.typ[:], buf[4:8])
+	size := int64(jxlByteOrder.Uint32(buf[:4]))
+	h.offset = offset + boxHeaderLength
+	switch size {
+	case 0:
+		// The last box extends to the end of the file
+		end, err := m.mr.Seek(0, io.SeekEnd)
+		if err != nil || end < h.offset {
+			return h, ErrCorruptBox
+		}
+		h.length = end - h.offset
+	case 1:
+		// 64 bit size
+		if n < boxLargeHeaderLength {
+			return h, ErrCorruptBox
+		}
+		large := jxlByteOrder.Uint64(buf[8:16])
+		if large < boxLargeHeaderLength || large > 1<<62 {
+			return h, ErrCorruptBox
+		}
+		h.offset = offset + boxLargeHeaderLength
+		h.length = int64(large) - boxLargeHeaderLength
+	default:
+		if size < boxHeaderLength {
+			return h, ErrCorruptBox
+		}
+		h.length = size - boxHeaderLength
+	}
+	return h, nil
+}
+
+// readBoxes reads the boxes of a container from offset.
+func (m *Metadata) readBoxes(offset int64) (err error) {
+	var sizeHeader bool
+	for {
+		var h boxHeader
+		if h, err = m.readBoxHeader(offset); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+		switch h.typ {
+		case boxJXLC:
+			if !sizeHeader {
+				sizeHeader = true
+				err = m.readSizeHeader(h.offset)
+			}
+		case boxJXLP:
+			// The first partial codestream box has the size header after its 4 byte index
+			if !sizeHeader {
+				sizeHeader = true
+				err = m.readSizeHeader(h.offset + 4)
+			}
+		case boxEXIF:
+			err = m.readExif(h, nil)
+		case boxXML:
+			err = m.readXMP(h, nil)
+		case boxBROB:
+			err = m.readBrob(h)
+		}
+		if err != nil {
+			return
+		}
+		offset = h.offset + h.length
+	}
+}
+
+// readBrob decompresses a Brotli compressed Exif or XMP box. A "brob" box has the
+// type of the compressed box followed by the Brotli compressed data.
+func (m *Metadata) readBrob(h boxHeader) error {
+	var typ [4]byte
+	if _, err := m.mr.ReadAt(typ[:], h.offset); err != nil {
+		return ErrCorruptBox
+	}
+	if typ != boxEXIF && typ != boxXML {
+		return nil
+	}
+	if h.length < 4 {
+		return ErrCorruptBox
+	}
+	r := brotli.NewReader(io.NewSectionReader(m.mr, h.offset+4, h.length-4))
+	data, err := io.ReadAll(io.LimitReader(r, maxBoxLength+1))
+	if err != nil || len(data) > maxBoxLength {
+		// Boxes that can not be decompressed are skipped
+		return nil
+	}
+	if typ == boxEXIF {
+		return m.readExif(boxHeader{typ: typ, length: int64(len(data))}, data)
+	}
+	return m.readXMP(boxHeader{typ: typ, length: int64(len(data))}, data)
+}
+
+// readExif reads the Exif header from the Exif box h with the attached metadata exifFn.
+// data is the decompressed box of a "brob" box, offsets are relative to data.
+//
+// An Exif box has a 4 byte offset to the Tiff Header followed by the Exif data.
+func (m *Metadata) readExif(h boxHeader, data []byte) error {
+	var r io.ReaderAt = m.mr
+	if data != nil {
+		r = bytes.NewReader(data)
+	}
+	if h.length < 4+8 {
+		return nil
+	}
+	var buf [4]byte
+	if _, err := r.ReadAt(buf[:], h.offset); err != nil {
+		return ErrCorruptBox
+	}
+	offset := h.offset + 4 + int64(jxlByteOrder.Uint32(buf[:]))
+	length := h.length - (offset - h.offset)
+	var header [8]byte
+	if length < 8 {
+		return nil
+	}
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return ErrCorruptBox
+	}
+	byteOrder := meta.BinaryOrder(header[:])
+	if byteOrder == nil {
+		return nil
+	}
+	firstIfdOffset := byteOrder.Uint32(header[4:8])
+	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, uint32(offset), uint32(length), imagetype.ImageJXL)
+	m.exif = data
+
+	// Read Exif
+	if m.exifFn != nil {
+		return m.exifFn(io.NewSectionReader(r, offset, length), m.ExifHeader)
+	}
+	return nil
+}
+
+// readXMP reads the XMP packet of the "xml " box h with the attached metadata xmpFn.
+// data is the decompressed box of a "brob" box.
+func (m *Metadata) readXMP(h boxHeader, data []byte) error {
+	if data != nil {
+		m.xmp = data
+		if m.xmpFn != nil {
+			return m.xmpFn(bytes.NewReader(data), meta.NewXMPHeader(0, uint32(len(data))))
+		}
+		return nil
+	}
+	if h.length > maxBoxLength {
+		return nil
+	}
+	m.XmpHeader = meta.NewXMPHeader(uint32(h.offset), uint32(h.length))
+	if m.xmpFn != nil {
+		return m.xmpFn(io.NewSectionReader(m.mr, h.offset, h.length), m.XmpHeader)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package jxl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// bitWriter writes the fields of a codestream, least significant bit first.
+type bitWriter struct {
+	buf []byte
+	pos uint
+}
+
+func (bw *bitWriter) u(n uint, v uint32) {
+	for i := uint(0); i < n; i++ {
+		if bw.pos>>3 >= uint(len(bw.buf)) {
+			bw.buf = append(bw.buf, 0)
+		}
+		bw.buf[bw.pos>>3] |= byte(v>>i&1) << (bw.pos & 7)
+		bw.pos++
+	}
+}
+
+// size writes a dimension with the U32 distribution selector
+func (bw *bitWriter) size(v uint32) {
+	for sel, bits := range []uint{9, 13, 18, 30} {
+		if v-1 < 1<<bits {
+			bw.u(2, uint32(sel))
+			bw.u(bits, v-1)
+			return
+		}
+	}
+}
+
+// newCodestream returns a codestream signature and size header from fn,
+// followed by empty image data.
+func newCodestream(fn func(bw *bitWriter)) []byte {
+	bw := bitWriter{buf: []byte{}}
+	fn(&bw)
+	// ImageMetadata all_default
+	bw.u(1, 1)
+	return append(append([]byte{0xFF, 0x0A}, bw.buf...), make([]byte, 64)...)
+}
+
+func newBox(typ string, data []byte) []byte {
+	buf := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(8+len(data)))
+	copy(buf[4:], typ)
+	return append(buf, data...)
+}
+
+func newBrob(t *testing.T, typ string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString(typ)
+	w := brotli.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return newBox("brob", buf.Bytes())
+}
+
+func newExifBox(t *testing.T) []byte {
+	t.Helper()
+	b := exif.NewBuilder(nil)
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Model, "Canon EOS R5"); err != nil {
+		t.Fatal(err)
+	}
+	e, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tiff Header offset, after the "Exif\0\0" prefix
+	return append([]byte{0, 0, 0, 6, 'E', 'x', 'i', 'f', 0, 0}, e...)
+}
+
+func TestSizeHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		fn            func(bw *bitWriter)
+		width, height uint32
+	}{
+		{"div8", func(bw *bitWriter) { bw.u(1, 1); bw.u(5, 31); bw.u(3, 0); bw.u(5, 3) }, 32, 256},
+		{"ratio", func(bw *bitWriter) { bw.u(1, 0); bw.size(1080); bw.u(3, 5) }, 1920, 1080},
+		{"div8 ratio", func(bw *bitWriter) { bw.u(1, 1); bw.u(5, 7); bw.u(3, 3) }, 85, 64},
+		{"size", func(bw *bitWriter) { bw.u(1, 0); bw.size(503); bw.u(3, 0); bw.size(100001) }, 100001, 503},
+		{"large", func(bw *bitWriter) { bw.u(1, 0); bw.size(1 << 20); bw.u(3, 0); bw.size(1 << 30) }, 1 << 30, 1 << 20},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := newCodestream(test.fn)
+			if it, err := imagetype.ReadAt(bytes.NewReader(buf)); err != nil || it != imagetype.ImageJXL {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageJXL, it, err)
+			}
+			m, err := ScanJXL(bytes.NewReader(buf), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Container || m.Dimensions() != meta.NewDimensions(test.width, test.height) {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", test.width, test.height, m.Dimensions())
+			}
+			if _, err = m.Exif(); err != ErrNoExif {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+			}
+			if _, err = m.Xmp(); err != xmp.ErrNoXMP {
+				t.Errorf("Incorrect error wanted %v got %v", xmp.ErrNoXMP, err)
+			}
+		})
+	}
+}
+
+func TestContainer(t *testing.T) {
+	codestream := newCodestream(func(bw *bitWriter) { bw.u(1, 0); bw.size(3000); bw.u(3, 4) })
+	packet, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 4, Label: "Red"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ftyp := newBox("ftyp", []byte("jxl \x00\x00\x00\x00jxl "))
+
+	tests := []struct {
+		name  string
+		boxes [][]byte
+	}{
+		{"jxlc", [][]byte{ftyp, newBox("Exif", newExifBox(t)), newBox("xml ", packet), newBox("jxlc", codestream)}},
+		{"jxlp", [][]byte{ftyp, newBox("jxlp", append([]byte{0, 0, 0, 0}, codestream[:6]...)), newBox("jxlp", append([]byte{0x80, 0, 0, 1}, codestream[6:]...)), newBox("Exif", newExifBox(t)), newBox("xml ", packet)}},
+		{"brob", [][]byte{ftyp, newBrob(t, "Exif", newExifBox(t)), newBrob(t, "xml ", packet), newBox("jxlc", codestream)}},
+		// The last box has a size of 0 and extends to the end of the file
+		{"last box", [][]byte{ftyp, newBox("Exif", newExifBox(t)), newBox("xml ", packet), append([]byte{0, 0, 0, 0, 'j', 'x', 'l', 'c'}, codestream...)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := append([]byte{}, containerSignature...)
+			for _, box := range test.boxes {
+				buf = append(buf, box...)
+			}
+			if it, err := imagetype.ReadAt(bytes.NewReader(buf)); err != nil || it != imagetype.ImageJXL {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageJXL, it, err)
+			}
+			m, err := ScanJXL(bytes.NewReader(buf), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !m.Container || m.Dimensions() != meta.NewDimensions(4500, 3000) {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 4500, 3000, m.Dimensions())
+			}
+			e, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.CameraModel() != "Canon EOS R5" {
+				t.Errorf("Incorrect CameraModel wanted %s got %s", "Canon EOS R5", e.CameraModel())
+			}
+			x, err := m.Xmp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Basic.Rating != 4 || x.Basic.Label != "Red" {
+				t.Errorf("Incorrect Xmp got %+v", x.Basic)
+			}
+		})
+	}
+}
+
+func TestScanJXLErrors(t *testing.T) {
+	if _, err := ScanJXL(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF}), nil, nil); err != ErrNoJXLSignature {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoJXLSignature, err)
+	}
+	// Truncated size header
+	if _, err := ScanJXL(bytes.NewReader([]byte{0xFF, 0x0A, 0x00}), nil, nil); err != ErrCorruptCodestream {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptCodestream, err)
+	}
+	// Box size smaller than the box header
+	buf := append(append([]byte{}, containerSignature...), 0, 0, 0, 4, 'j', 'x', 'l', 'c')
+	if _, err := ScanJXL(bytes.NewReader(buf), nil, nil); err != ErrCorruptBox {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptBox, err)
+	}
+}
@@ -0,0 +1,54 @@
+// Package nikon reads the Nikon MakerNote of Exif metadata.
+package nikon
+
+import (
+	"errors"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/meta/nikon"
+)
+
+// ErrNotNikon is returned when the Exif metadata does not have a Nikon MakerNote
+var ErrNotNikon = errors.New("error makernote is not a Nikon makernote")
+
+// MakerNote is the Nikon MakerNote of Exif metadata.
+//
+// The MakerNote begins with the "Nikon\0" prefix followed by its own Tiff
+// header. The offsets of the MakerNote tags are rebased on this Tiff header
+// when the Exif metadata is parsed.
+type MakerNote struct {
+	e *exif.Data
+}
+
+// Parse returns the Nikon MakerNote of the Exif metadata e.
+// The MakerNote is detected by its "Nikon\0" header and embedded Tiff
+// header, the camera make is not used.
+// Returns ErrNotNikon if e is nil or the MakerNote is not a Nikon MakerNote.
+func Parse(e *exif.Data) (MakerNote, error) {
+	if e == nil || !e.IsNikonMakerNote() {
+		return MakerNote{}, ErrNotNikon
+	}
+	return MakerNote{e: e}, nil
+}
+
+// LensID returns the composite Nikon Lens ID from the LensData and
+// LensType tags. LensData versions later than "0101" are encrypted and
+// return exif.ErrNikonEncrypted.
+func (mn MakerNote) LensID() (nikon.LensID, error) {
+	return mn.e.NikonLensID()
+}
+
+// ShutterCount returns the camera shutter count.
+func (mn MakerNote) ShutterCount() (uint32, error) {
+	return mn.e.NikonShutterCount()
+}
+
+// ISO returns the ISO speed.
+func (mn MakerNote) ISO() (uint32, error) {
+	return mn.e.NikonISO()
+}
+
+// SerialNumber returns the camera serial number.
+func (mn MakerNote) SerialNumber() (string, error) {
+	return mn.e.NikonSerialNumber()
+}
@@ -0,0 +1,69 @@
+package nikon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	buf, err := os.ReadFile("../../testImages/NEF.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := exif.ParseExif(bytes.NewReader(buf), meta.NewExifHeader(binary.LittleEndian, 8, 0, 0, imagetype.ImageNEF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mn, err := Parse(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iso, err := mn.ISO()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(100), iso)
+
+	shutterCount, err := mn.ShutterCount()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(205), shutterCount)
+
+	serial, err := mn.SerialNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, "7302381", serial)
+
+	// LensData version "0204" is encrypted
+	_, err = mn.LensID()
+	assert.ErrorIs(t, err, exif.ErrNikonEncrypted)
+
+	// Coolpix cameras have the make "NIKON"
+	coolpix := bytes.Replace(buf, []byte("NIKON CORPORATION\x00"), []byte("NIKON\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"), 1)
+	if e, err = exif.ParseExif(bytes.NewReader(coolpix), meta.NewExifHeader(binary.LittleEndian, 8, 0, 0, imagetype.ImageNEF)); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "NIKON", e.CameraMake())
+	if mn, err = Parse(e); assert.NoError(t, err) {
+		shutterCount, err = mn.ShutterCount()
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(205), shutterCount)
+	}
+
+	_, err = Parse(nil)
+	assert.ErrorIs(t, err, ErrNotNikon)
+
+	// Not a Nikon
+	if buf, err = os.ReadFile("../../testImages/CR2.exif"); err != nil {
+		t.Fatal(err)
+	}
+	if e, err = exif.ParseExif(bytes.NewReader(buf), meta.NewExifHeader(binary.LittleEndian, 16, 0, 0, imagetype.ImageCR2)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Parse(e)
+	assert.ErrorIs(t, err, ErrNotNikon)
+}
@@ -56,3 +56,30 @@ type AFPoint [4]int16
 func NewAFPoint(w, h, x, y int16) AFPoint {
 	return AFPoint{w, h, x, y}
 }
+
+// SensorInfo is Canon Makernote Sensor Information.
+// The borders are the inclusive pixel coordinates of the image area of the sensor.
+type SensorInfo struct {
+	Width        uint16 // [1] SensorWidth
+	Height       uint16 // [2] SensorHeight
+	LeftBorder   uint16 // [5] SensorLeftBorder
+	TopBorder    uint16 // [6] SensorTopBorder
+	RightBorder  uint16 // [7] SensorRightBorder
+	BottomBorder uint16 // [8] SensorBottomBorder
+}
+
+// ImageWidth returns the width of the image area of the sensor
+func (si SensorInfo) ImageWidth() uint16 {
+	if si.RightBorder < si.LeftBorder {
+		return 0
+	}
+	return si.RightBorder - si.LeftBorder + 1
+}
+
+// ImageHeight returns the height of the image area of the sensor
+func (si SensorInfo) ImageHeight() uint16 {
+	if si.BottomBorder < si.TopBorder {
+		return 0
+	}
+	return si.BottomBorder - si.TopBorder + 1
+}
@@ -525,6 +525,23 @@ const (
 	FlashModeAuto FlashMode = 24
 )
 
+// String returns a FlashMode as a string
+func (fm FlashMode) String() string {
+	switch fm {
+	case FlashNoReturn:
+		return "Return not detected"
+	case FlashReturn:
+		return "Return detected"
+	case FlashModeOn:
+		return "On"
+	case FlashModeOff:
+		return "Off"
+	case FlashModeAuto:
+		return "Auto"
+	}
+	return "None"
+}
+
 // Fired is bit 0, returns true if Flash was fired.
 func (f Flash) Fired() bool {
 	return 0b00000001&f == 0b00000001
@@ -424,6 +424,22 @@ func TestFlash(t *testing.T) {
 		}
 	}
 
+	// Test FlashMode Stringer
+	flashModeStrings := map[FlashMode]string{
+		FlashModeNone: "None",
+		FlashNoReturn: "Return not detected",
+		FlashReturn:   "Return detected",
+		FlashModeOn:   "On",
+		FlashModeOff:  "Off",
+		FlashModeAuto: "Auto",
+		FlashMode(2):  "None",
+	}
+	for fm, str := range flashModeStrings {
+		if fm.String() != str {
+			t.Errorf("Incorrect FlashMode String on %d wanted %v got %v", uint8(fm), str, fm.String())
+		}
+	}
+
 	// Test TextMarshall
 	// Test TextUnMarshall
 
@@ -2,6 +2,7 @@
 package meta
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -70,13 +71,17 @@ func (m Metadata) ImageType() imagetype.ImageType {
 // Tiff Header offset, Exif Length (0 if unknown) and
 // Image type for the parsing of the Exif information from
 // a Tiff Directory.
+//
+// BigTiff is true when the Tiff Directory uses the BigTiff
+// (version 0x002B) format with 64-bit offsets and counts.
 type ExifHeader struct {
 	ByteOrder        binary.ByteOrder
-	FirstIfdOffset   uint32
+	FirstIfdOffset   uint64
 	TiffHeaderOffset uint32
 	ExifLength       uint32
 	FirstIfd         ifds.IfdType
 	ImageType        imagetype.ImageType
+	BigTiff          bool
 }
 
 // IsValid returns true if the ExifHeader ByteOrder is not nil and
@@ -94,13 +99,22 @@ func NewExifHeader(byteOrder binary.ByteOrder, firstIfdOffset, tiffHeaderOffset
 	return ExifHeader{
 		ByteOrder:        byteOrder,
 		FirstIfd:         ifds.IFD0,
-		FirstIfdOffset:   firstIfdOffset,
+		FirstIfdOffset:   uint64(firstIfdOffset),
 		TiffHeaderOffset: tiffHeaderOffset,
 		ExifLength:       exifLength,
 		ImageType:        imageType,
 	}
 }
 
+// NewBigTiffExifHeader returns a new ExifHeader for a BigTiff
+// Directory with a 64-bit first Ifd offset.
+func NewBigTiffExifHeader(byteOrder binary.ByteOrder, firstIfdOffset uint64, imageType imagetype.ImageType) ExifHeader {
+	h := NewExifHeader(byteOrder, 0, 0, 0, imageType)
+	h.FirstIfdOffset = firstIfdOffset
+	h.BigTiff = true
+	return h
+}
+
 // XmpHeader is an XMP header of an image file.
 // Contains Offset and Length of XMP metadata.
 type XmpHeader struct {
@@ -129,6 +143,45 @@ func BinaryOrder(buf []byte) binary.ByteOrder {
 	return nil
 }
 
+// BigTiffBinaryOrder returns the binary.ByteOrder for a BigTiff Header
+// based on 8 bytes from the buf. The BigTiff Header has the version 0x002B,
+// an offset bytesize of 8 and a constant of 0.
+//
+// Reference: https://www.awaresystems.be/imaging/tiff/bigtiff.html
+func BigTiffBinaryOrder(buf []byte) binary.ByteOrder {
+	if len(buf) < 8 {
+		return nil
+	}
+	if buf[0] == 0x4d && buf[1] == 0x4d &&
+		binary.BigEndian.Uint16(buf[2:4]) == 0x002b &&
+		binary.BigEndian.Uint16(buf[4:6]) == 0x0008 &&
+		binary.BigEndian.Uint16(buf[6:8]) == 0x0000 {
+		return binary.BigEndian
+	}
+	if buf[0] == 0x49 && buf[1] == 0x49 &&
+		binary.LittleEndian.Uint16(buf[2:4]) == 0x002b &&
+		binary.LittleEndian.Uint16(buf[4:6]) == 0x0008 &&
+		binary.LittleEndian.Uint16(buf[6:8]) == 0x0000 {
+		return binary.LittleEndian
+	}
+	return nil
+}
+
+// imageResourceSignatures are the signatures of Photoshop Image Resource Blocks.
+// Photoshop writes "8BIM", the others are written by older applications.
+var imageResourceSignatures = [][]byte{[]byte("8BIM"), []byte("MeSa"), []byte("AgHg"), []byte("PHUT"), []byte("DCSR")}
+
+// IsImageResourceSignature returns true if buf is the signature of a Photoshop
+// Image Resource Block, as found in PSD files and the APP13 segment of JPEG files.
+func IsImageResourceSignature(buf []byte) bool {
+	for _, sig := range imageResourceSignatures {
+		if bytes.Equal(buf, sig) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsTiffLittleEndian checks the buf for the Tiff LittleEndian Signature
 func isTiffLittleEndian(buf []byte) bool {
 	return buf[0] == 0x49 &&
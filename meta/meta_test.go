@@ -29,6 +29,22 @@ func TestBinaryOrder(t *testing.T) {
 	}
 }
 
+func TestBigTiffBinaryOrder(t *testing.T) {
+	bigTiffTests := []struct {
+		buf []byte
+		bo  binary.ByteOrder
+	}{
+		{[]byte{0x49, 0x49, 0x2b, 0, 8, 0, 0, 0}, binary.LittleEndian},
+		{[]byte{0x4d, 0x4d, 0, 0x2b, 0, 8, 0, 0}, binary.BigEndian},
+		{[]byte{0x49, 0x49, 0x2a, 0, 8, 0, 0, 0}, nil},
+		{[]byte{0x49, 0x49, 0x2b, 0, 4, 0, 0, 0}, nil},
+		{[]byte{0x4d, 0x4d, 0, 0x2b}, nil},
+	}
+	for _, bt := range bigTiffTests {
+		assert.Equal(t, bt.bo, BigTiffBinaryOrder(bt.buf))
+	}
+}
+
 func TestXmpHeader(t *testing.T) {
 	h1 := XmpHeader{1, 2}
 	h2 := NewXMPHeader(1, 2)
@@ -0,0 +1,23 @@
+// Package nikon provides data types and functions for representing Nikon Camera Makernote values
+package nikon
+
+import "fmt"
+
+// LensID is the composite Nikon Lens ID. It is made up of the LensIDNumber,
+// LensFStops, MinFocalLength, MaxFocalLength, MaxApertureAtMinFocal,
+// MaxApertureAtMaxFocal and MCUVersion values from the Makernote LensData
+// followed by the Makernote LensType.
+//
+// The string representation is the same as the key used by
+// Exiftool's Nikon Lens ID database. ie. "06 3C 5C 5C 30 30 06 06"
+type LensID [8]byte
+
+func (id LensID) String() string {
+	return fmt.Sprintf("%02X %02X %02X %02X %02X %02X %02X %02X", id[0], id[1], id[2], id[3], id[4], id[5], id[6], id[7])
+}
+
+// IsZero returns true if the LensID is empty.
+// Non-CPU lenses report an empty LensID.
+func (id LensID) IsZero() bool {
+	return id == LensID{}
+}
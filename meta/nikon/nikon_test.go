@@ -0,0 +1,16 @@
+package nikon
+
+import "testing"
+
+func TestLensID(t *testing.T) {
+	id := LensID{0x06, 0x3c, 0x5c, 0x5c, 0x30, 0x30, 0x06, 0x06}
+	if id.String() != "06 3C 5C 5C 30 30 06 06" {
+		t.Errorf("Incorrect LensID String wanted %s got %s", "06 3C 5C 5C 30 30 06 06", id.String())
+	}
+	if id.IsZero() {
+		t.Errorf("Incorrect LensID IsZero wanted %t got %t", false, true)
+	}
+	if !(LensID{}).IsZero() {
+		t.Errorf("Incorrect LensID IsZero wanted %t got %t", true, false)
+	}
+}
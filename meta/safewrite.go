@@ -0,0 +1,94 @@
+package meta
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SafeWriteOptions are the options of SafeWrite.
+type SafeWriteOptions struct {
+	// Mode is the file mode of the file. If Mode is 0 the mode of the
+	// existing file is kept, a new file has the mode 0644.
+	Mode os.FileMode
+
+	// PreserveModTime keeps the modification time of the existing file.
+	PreserveModTime bool
+
+	// ModTime sets the modification time of the file, such as the
+	// DateTimeOriginal of the image. ModTime is used instead of
+	// PreserveModTime when it is not zero.
+	ModTime time.Time
+}
+
+// SafeWrite replaces the file at path with the file written by fn, so that
+// metadata edits do not leave a corrupt original when writing fails or the
+// system crashes.
+//
+// fn writes to a temporary file in the same directory as path. The temporary
+// file is synced to disk and renamed to path when fn returns without an error,
+// otherwise it is removed and path is not changed. The file is created if it
+// does not exist.
+func SafeWrite(path string, fn func(w io.Writer) error, opts SafeWriteOptions) (err error) {
+	mode, modTime := opts.Mode, opts.ModTime
+	fi, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if mode == 0 {
+			mode = fi.Mode().Perm()
+		}
+		if modTime.IsZero() && opts.PreserveModTime {
+			modTime = fi.ModTime()
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if mode == 0 {
+			mode = 0o644
+		}
+	default:
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err = fn(f); err != nil {
+		return err
+	}
+	if err = f.Chmod(mode); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if !modTime.IsZero() {
+		if err = os.Chtimes(f.Name(), time.Now(), modTime); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// syncDir syncs the directory dir so that a rename in dir is on disk.
+// Errors are ignored, directories can not be synced on all platforms.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
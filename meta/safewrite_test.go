@@ -0,0 +1,81 @@
+package meta
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "image.jpg")
+	write := func(s string) func(w io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+	readFile := func() string {
+		t.Helper()
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(buf)
+	}
+
+	// New file
+	if err := SafeWrite(path, write("new"), SafeWriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "new", readFile())
+	if fi, err := os.Stat(path); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o644), fi.Mode().Perm())
+	}
+
+	// The mode of the existing file is kept
+	modTime := time.Date(2019, 5, 4, 10, 30, 0, 0, time.UTC)
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := SafeWrite(path, write("keep"), SafeWriteOptions{PreserveModTime: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "keep", readFile())
+	if fi, err := os.Stat(path); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+		assert.True(t, modTime.Equal(fi.ModTime()), "modification time should be kept")
+	}
+
+	// ModTime and Mode
+	dateTimeOriginal := time.Date(2021, 1, 10, 17, 30, 57, 0, time.UTC)
+	if err := SafeWrite(path, write("set"), SafeWriteOptions{Mode: 0o640, PreserveModTime: true, ModTime: dateTimeOriginal}); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(path); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o640), fi.Mode().Perm())
+		assert.True(t, dateTimeOriginal.Equal(fi.ModTime()), "modification time should be set")
+	}
+
+	// A failed write does not change the file
+	errWrite := errors.New("write error")
+	err := SafeWrite(path, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errWrite
+	}, SafeWriteOptions{})
+	assert.ErrorIs(t, err, errWrite)
+	assert.Equal(t, "set", readFile())
+	if entries, err := os.ReadDir(dir); assert.NoError(t, err) {
+		assert.Len(t, entries, 1)
+	}
+
+	assert.Error(t, SafeWrite(filepath.Join(dir, "missing", "image.jpg"), write(""), SafeWriteOptions{}))
+}
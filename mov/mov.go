@@ -0,0 +1,335 @@
+// Package mov reads the metadata of a QuickTime movie (MOV) and of an MPEG-4 or
+// CMAF movie (MP4) using the bmff package.
+//
+// The 'moov' box has the movie header with the creation time and the duration, the track
+// headers with the dimensions, and the 'udta' and 'meta' boxes with the metadata items
+// such as the creation date, the GPS location and the camera make and model.
+// Fragmented movies have the duration of the fragments in the 'mvex' box.
+package mov
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/bmff"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif = meta.ErrNoExif
+	ErrNoMoov = errors.New("no QuickTime 'moov' box")
+
+	// ErrCorruptBox is returned when a top level box is truncated or its size is invalid.
+	ErrCorruptBox = errors.New("corrupt QuickTime box")
+)
+
+// Metadata item keys of the 'udta' box and of the 'meta' box
+const (
+	keyDate     = "©day"
+	keyLocation = "©xyz"
+	keyLoci     = "loci"
+	keyMake     = "©mak"
+	keyModel    = "©mod"
+	keySoftware = "©swr"
+
+	keyQuickTimeContentID = "com.apple.quicktime.content.identifier"
+	keyQuickTimeDate      = "com.apple.quicktime.creationdate"
+	keyQuickTimeLocation  = "com.apple.quicktime.location.ISO6709"
+	keyQuickTimeMake      = "com.apple.quicktime.make"
+	keyQuickTimeModel     = "com.apple.quicktime.model"
+	keyQuickTimeSoftware  = "com.apple.quicktime.software"
+)
+
+// xmpUUID is the uuid of a top level 'uuid' box with an XMP packet
+var xmpUUID = []byte{0xBE, 0x7A, 0xCF, 0xCB, 0x97, 0xA9, 0x42, 0xE8, 0x9C, 0x71, 0x99, 0x94, 0x91, 0xE3, 0xAF, 0xAC}
+
+// dateLayouts are the layouts of the creation date items
+var dateLayouts = []string{
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Lengths
+const (
+	boxHeaderLength      = 8
+	boxLargeHeaderLength = 16
+	uuidLength           = 16
+
+	// bufferSize is the buffer of the bmff reader, metadata items larger
+	// than the buffer are skipped.
+	bufferSize = 64 << 10
+)
+
+// Metadata from a QuickTime or MP4 movie
+type Metadata struct {
+	mr        meta.Reader
+	XmpHeader meta.XmpHeader
+	imageType imagetype.ImageType
+
+	Moov bmff.MovMoovBox
+
+	// Make, Model and Software of the camera
+	Make, Model, Software string
+
+	// CreationDate is the creation date item, or the creation time
+	// of the movie header in UTC.
+	CreationDate time.Time
+
+	// Latitude and Longitude in degrees, Altitude in meters of the location item
+	Latitude, Longitude, Altitude float64
+
+	// ContentIdentifier pairs the movie of an Apple Live Photo with its image.
+	ContentIdentifier string
+
+	hasGPS      bool
+	hasAltitude bool
+}
+
+// Dimensions returns the dimensions (width and height) of the largest track
+func (m Metadata) Dimensions() meta.Dimensions {
+	var width, height uint32
+	for _, t := range m.Moov.Tracks {
+		if uint64(t.Width)*uint64(t.Height) > uint64(width)*uint64(height) {
+			width, height = t.Width, t.Height
+		}
+	}
+	return meta.NewDimensions(width, height)
+}
+
+// ImageType returns imagetype.ImageMOV for QuickTime movie and
+// imagetype.ImageMP4 for MP4 movie
+func (m Metadata) ImageType() imagetype.ImageType {
+	return m.imageType
+}
+
+// PreviewImage returns the movie
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Duration returns the duration of the movie
+func (m Metadata) Duration() time.Duration {
+	return m.Moov.Header.Duration
+}
+
+// Exif returns Exif data that is built from the camera make and model, the
+// creation date and the location.
+//
+// Returns ErrNoExif if the movie does not have these metadata items.
+func (m Metadata) Exif() (exif.Exif, error) {
+	if m.Make == "" && m.Model == "" && m.Software == "" && m.CreationDate.IsZero() && !m.hasGPS {
+		return nil, ErrNoExif
+	}
+	buf, err := m.buildExif()
+	if err != nil {
+		return nil, err
+	}
+	return exif.ParseTIFF(bytes.NewReader(buf))
+}
+
+// Xmp returns parsed Xmp data from the 'XMP_' user data item or the XMP 'uuid' box.
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	if m.XmpHeader.Length == 0 {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+// buildExif returns a Tiff structured Exif block with the metadata items.
+func (m Metadata) buildExif() ([]byte, error) {
+	b := exif.NewBuilder(binary.LittleEndian)
+	var errs []error
+	if m.Make != "" {
+		errs = append(errs, b.SetASCII(ifds.IFD0, 0, ifds.Make, m.Make))
+	}
+	if m.Model != "" {
+		errs = append(errs, b.SetASCII(ifds.IFD0, 0, ifds.Model, m.Model))
+	}
+	if m.Software != "" {
+		errs = append(errs, b.SetSoftware(m.Software))
+	}
+	if !m.CreationDate.IsZero() {
+		errs = append(errs, b.SetDateTime(m.CreationDate))
+	}
+	if m.hasGPS {
+		errs = append(errs, b.SetGPSCoords(m.Latitude, m.Longitude))
+	}
+	if m.hasAltitude {
+		errs = append(errs, b.SetGPSAltitude(m.Altitude))
+	}
+	dim := m.Dimensions()
+	errs = append(errs, b.SetDimensions(dim.Width, dim.Height))
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.Encode()
+}
+
+// Parse reads the 'moov' box and the XMP 'uuid' box of a QuickTime or MP4 movie from mr.
+// Movies with an ftyp box that does not have the 'qt  ' major brand are MP4 movies.
+// Returns Metadata.
+//
+// Returns the error ErrNoMoov if the movie does not have a 'moov' box and
+// ErrCorruptBox if a top level box is truncated.
+func Parse(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr, imageType: imagetype.ImageMOV}
+
+	// Top level boxes are read with ReadAt, 'mdat' boxes are often before the 'moov' box.
+	var moovOffset, moovLength int64
+	for offset := int64(0); ; {
+		typ, dataOffset, length, err := readBoxHeader(mr, offset)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return m, err
+		}
+		switch typ {
+		case "ftyp":
+			var brand [4]byte
+			if _, err = mr.ReadAt(brand[:], dataOffset); err == nil && offset == 0 && string(brand[:]) != "qt  " {
+				m.imageType = imagetype.ImageMP4
+			}
+		case "moov":
+			if moovLength == 0 {
+				moovOffset, moovLength = offset, dataOffset-offset+length
+			}
+		case "uuid":
+			if m.XmpHeader.Length == 0 && length > uuidLength {
+				var uuid [uuidLength]byte
+				if _, err = mr.ReadAt(uuid[:], dataOffset); err == nil && bytes.Equal(uuid[:], xmpUUID) {
+					m.XmpHeader = meta.NewXMPHeader(uint32(dataOffset+uuidLength), uint32(length-uuidLength))
+				}
+			}
+		}
+		offset = dataOffset + length
+	}
+	if moovLength == 0 {
+		return m, ErrNoMoov
+	}
+
+	br := bufio.NewReaderSize(io.NewSectionReader(mr, moovOffset, moovLength), bufferSize)
+	bmr := bmff.NewReader(br)
+	if m.Moov, err = bmr.ReadMovMoovBox(); err != nil {
+		return m, err
+	}
+	if m.Moov.XmpLength > 0 {
+		m.XmpHeader = meta.NewXMPHeader(uint32(moovOffset)+m.Moov.XmpOffset, m.Moov.XmpLength)
+	}
+	m.readItems()
+	return m, nil
+}
+
+// readItems reads the camera, the creation date and the location from the metadata items.
+// Items of the 'meta' box are preferred to items of the 'udta' box.
+func (m *Metadata) readItems() {
+	m.Make = m.item(keyQuickTimeMake, keyMake)
+	m.Model = m.item(keyQuickTimeModel, keyModel)
+	m.Software = m.item(keyQuickTimeSoftware, keySoftware)
+	m.ContentIdentifier = m.item(keyQuickTimeContentID)
+
+	m.CreationDate = m.Moov.Header.Created
+	if v := m.item(keyQuickTimeDate, keyDate); v != "" {
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				m.CreationDate = t
+				break
+			}
+		}
+	}
+	if v := m.item(keyQuickTimeLocation, keyLocation, keyLoci); v != "" {
+		var n int
+		m.Latitude, m.Longitude, m.Altitude, n = parseISO6709(v)
+		m.hasGPS = n >= 2
+		m.hasAltitude = n == 3
+	}
+}
+
+// item returns the value of the first item of keys.
+func (m Metadata) item(keys ...string) string {
+	for _, key := range keys {
+		if v, ok := m.Moov.Item(key); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseISO6709 parses the latitude, longitude and altitude of an ISO 6709 location
+// in decimal degrees such as "+48.8584+002.2945+035.000/". Returns the number of values.
+func parseISO6709(s string) (lat, lng, alt float64, n int) {
+	var values [3]float64
+	for n < len(values) && len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		end := 1
+		for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.') {
+			end++
+		}
+		v, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			break
+		}
+		values[n] = v
+		n++
+		s = s[end:]
+	}
+	if n >= 2 && (math.Abs(values[0]) > 90 || math.Abs(values[1]) > 180) {
+		return 0, 0, 0, 0
+	}
+	return values[0], values[1], values[2], n
+}
+
+// readBoxHeader reads the header of the box at offset. Returns the box type, the offset and
+// length of the box data. Returns io.EOF at the end of the file.
+func readBoxHeader(mr meta.Reader, offset int64) (typ string, dataOffset, length int64, err error) {
+	var buf [boxLargeHeaderLength]byte
+	n, err := mr.ReadAt(buf[:], offset)
+	if n == 0 && err == io.EOF {
+		return "", 0, 0, io.EOF
+	}
+	if n < boxHeaderLength {
+		return "", 0, 0, ErrCorruptBox
+	}
+	typ = string(buf[4:8])
+	size := int64(binary.BigEndian.Uint32(buf[:4]))
+	dataOffset = offset + boxHeaderLength
+	switch size {
+	case 0:
+		// The last box extends to the end of the file
+		end, err := mr.Seek(0, io.SeekEnd)
+		if err != nil || end < dataOffset {
+			return "", 0, 0, ErrCorruptBox
+		}
+		return typ, dataOffset, end - dataOffset, nil
+	case 1:
+		// 64 bit size
+		if n < boxLargeHeaderLength {
+			return "", 0, 0, ErrCorruptBox
+		}
+		large := binary.BigEndian.Uint64(buf[8:16])
+		if large < boxLargeHeaderLength || large > 1<<62 {
+			return "", 0, 0, ErrCorruptBox
+		}
+		return typ, offset + boxLargeHeaderLength, int64(large) - boxLargeHeaderLength, nil
+	}
+	if size < boxHeaderLength {
+		return "", 0, 0, ErrCorruptBox
+	}
+	return typ, dataOffset, size - boxHeaderLength, nil
+}
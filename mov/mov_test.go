@@ -0,0 +1,282 @@
+package mov
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+func newBox(typ string, data ...[]byte) []byte {
+	buf := make([]byte, 8)
+	copy(buf[4:], typ)
+	for _, d := range data {
+		buf = append(buf, d...)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)))
+	return buf
+}
+
+func u32(v ...uint32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i := range v {
+		binary.BigEndian.PutUint32(buf[i*4:], v[i])
+	}
+	return buf
+}
+
+// secondsSince1904 returns t in seconds since the QuickTime epoch
+func secondsSince1904(t time.Time) uint32 {
+	return uint32(t.Sub(time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)) / time.Second)
+}
+
+func newMvhd(created time.Time, timeScale, duration uint32) []byte {
+	return newBox("mvhd", u32(0, secondsSince1904(created), secondsSince1904(created), timeScale, duration), make([]byte, 80))
+}
+
+func newTkhd(version uint8, id, width, height uint32, rotate bool) []byte {
+	matrix := u32(1<<16, 0, 0, 0, 1<<16, 0, 0, 0, 1<<30)
+	if rotate {
+		matrix = u32(0, 1<<16, 0, 0xFFFF0000, 0, 0, 0, 0, 1<<30)
+	}
+	if version == 1 {
+		return newBox("tkhd", u32(1<<24|3, 0, 0, 0, 0, id, 0, 0, 0, 0, 0, 0, 0), matrix, u32(width<<16, height<<16))
+	}
+	return newBox("tkhd", u32(3, 0, 0, id, 0, 0, 0, 0, 0, 0), matrix, u32(width<<16, height<<16))
+}
+
+func newText(key, value string) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint16(buf, uint16(len(value)))
+	return newBox("\xa9"+key, buf, []byte(value))
+}
+
+func newData(typ uint32, value []byte) []byte {
+	return newBox("data", u32(typ, 0), value)
+}
+
+// newMeta returns a QuickTime 'meta' box with the 'mdta' keys and their UTF-8 values
+func newMeta(kv ...string) []byte {
+	var keys, items []byte
+	for i := 0; i < len(kv); i += 2 {
+		keys = append(keys, newBox("mdta", []byte(kv[i]))...)
+		items = append(items, newBox(string(u32(uint32(i/2+1))), newData(1, []byte(kv[i+1])))...)
+	}
+	hdlr := newBox("hdlr", u32(0, 0), []byte("mdta"), make([]byte, 13))
+	return newBox("meta", hdlr, newBox("keys", u32(0, uint32(len(kv)/2)), keys), newBox("ilst", items))
+}
+
+func TestParse(t *testing.T) {
+	packet, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 4, Label: "Red"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2021, 6, 14, 8, 0, 0, 0, time.UTC)
+	ftyp := newBox("ftyp", []byte("qt  \x00\x00\x00\x00qt  "))
+	mdat := newBox("mdat", make([]byte, 256))
+
+	tests := []struct {
+		name      string
+		buf       []byte
+		make      string
+		model     string
+		date      time.Time
+		rotation  uint16
+		contentID string
+	}{
+		{"udta", bytes.Join([][]byte{ftyp, newBox("wide"), mdat, newBox("moov",
+			newMvhd(created, 600, 600*90),
+			newBox("trak", newTkhd(0, 1, 1920, 1080, true)),
+			newBox("trak", newTkhd(0, 2, 0, 0, false)),
+			newBox("udta",
+				newText("day", "2021-06-14T10:30:00+0200"),
+				newText("xyz", "+48.8584+002.2945+035.000/"),
+				newText("mak", "Canon"),
+				newText("mod", "Canon EOS R5"),
+				newBox("XMP_", packet),
+				make([]byte, 4)),
+		)}, nil), "Canon", "Canon EOS R5", time.Date(2021, 6, 14, 8, 30, 0, 0, time.UTC), 90, ""},
+		{"meta", bytes.Join([][]byte{ftyp, newBox("moov",
+			newMvhd(created, 1000, 90000),
+			newBox("trak", newTkhd(1, 1, 1920, 1080, false)),
+			newMeta(
+				keyQuickTimeMake, "Apple",
+				keyQuickTimeModel, "iPhone 12",
+				keyQuickTimeDate, "2021-06-14T10:30:00+0200",
+				keyQuickTimeLocation, "+48.8584+002.2945+035.000/",
+				keyQuickTimeContentID, "6A7B6B05-8E6B-4F6A-9C1B-2A3C4D5E6F70"),
+			// iTunes style items are overridden by the 'mdta' items
+			newBox("udta", newBox("meta", u32(0), newBox("ilst", newBox("\xa9mak", newData(1, []byte("Unknown")))))),
+		), newBox("uuid", xmpUUID, packet), mdat}, nil), "Apple", "iPhone 12", time.Date(2021, 6, 14, 8, 30, 0, 0, time.UTC), 0, "6A7B6B05-8E6B-4F6A-9C1B-2A3C4D5E6F70"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if it, err := imagetype.ReadAt(bytes.NewReader(test.buf)); err != nil || it != imagetype.ImageMOV {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageMOV, it, err)
+			}
+			m, err := Parse(bytes.NewReader(test.buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Dimensions() != meta.NewDimensions(1920, 1080) {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 1920, 1080, m.Dimensions())
+			}
+			if m.Duration() != 90*time.Second || !m.Moov.Header.Created.Equal(created) {
+				t.Errorf("Incorrect movie header got %+v", m.Moov.Header)
+			}
+			if len(m.Moov.Tracks) == 0 || m.Moov.Tracks[0].TrackID != 1 || m.Moov.Tracks[0].Rotation != test.rotation {
+				t.Errorf("Incorrect Tracks got %+v", m.Moov.Tracks)
+			}
+			if m.Make != test.make || m.Model != test.model {
+				t.Errorf("Incorrect Camera wanted %s %s got %s %s", test.make, test.model, m.Make, m.Model)
+			}
+			if m.ContentIdentifier != test.contentID {
+				t.Errorf("Incorrect ContentIdentifier wanted %q got %q", test.contentID, m.ContentIdentifier)
+			}
+			if !m.CreationDate.Equal(test.date) {
+				t.Errorf("Incorrect CreationDate wanted %s got %s", test.date, m.CreationDate)
+			}
+
+			e, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.CameraModel() != test.model {
+				t.Errorf("Incorrect CameraModel wanted %s got %s", test.model, e.CameraModel())
+			}
+			if lat, lng, err := e.GPSCoords(); err != nil || math.Abs(lat-48.8584) > 1e-4 || math.Abs(lng-2.2945) > 1e-4 {
+				t.Errorf("Incorrect GPSCoords got %f %f (%v)", lat, lng, err)
+			}
+			if alt, err := e.GPSAltitude(); err != nil || alt != 35 {
+				t.Errorf("Incorrect GPSAltitude wanted %d got %f (%v)", 35, alt, err)
+			}
+			x, err := m.Xmp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Basic.Rating != 4 || x.Basic.Label != "Red" {
+				t.Errorf("Incorrect Xmp got %+v", x.Basic)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(bytes.NewReader(newBox("mdat", make([]byte, 64)))); err != ErrNoMoov {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoMoov, err)
+	}
+	// Box size smaller than the box header
+	if _, err := Parse(bytes.NewReader([]byte{0, 0, 0, 4, 'm', 'o', 'o', 'v'})); err != ErrCorruptBox {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptBox, err)
+	}
+}
+
+func TestParseISO6709(t *testing.T) {
+	tests := []struct {
+		s             string
+		lat, lng, alt float64
+		n             int
+	}{
+		{"+48.8584+002.2945+035.000/", 48.8584, 2.2945, 35, 3},
+		{"-33.8568+151.2153/", -33.8568, 151.2153, 0, 2},
+		{"+27.5916+086.5640+8850CRSWGS_84/", 27.5916, 86.564, 8850, 3},
+		{"+95.0000+000.0000/", 0, 0, 0, 0},
+		{"", 0, 0, 0, 0},
+	}
+	for _, test := range tests {
+		lat, lng, alt, n := parseISO6709(test.s)
+		if lat != test.lat || lng != test.lng || alt != test.alt || n != test.n {
+			t.Errorf("Incorrect ISO6709 %q wanted %v %v %v %d got %v %v %v %d", test.s, test.lat, test.lng, test.alt, test.n, lat, lng, alt, n)
+		}
+	}
+}
+
+func newLoci(lat, lng, alt float64) []byte {
+	fixed := func(v float64) uint32 { return uint32(int32(math.Round(v * (1 << 16)))) }
+	buf := append(u32(0), 0x15, 0xC7)
+	buf = append(buf, "Home\x00"...)
+	buf = append(buf, 0)
+	buf = append(buf, u32(fixed(lng), fixed(lat), fixed(alt))...)
+	return newBox("loci", buf, []byte("earth\x00\x00"))
+}
+
+func TestParseMP4(t *testing.T) {
+	packet, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		buf      []byte
+		duration time.Duration
+		lat, lng float64
+	}{
+		{"mp4", bytes.Join([][]byte{
+			newBox("ftyp", []byte("isom\x00\x00\x02\x00isomiso2avc1mp41")),
+			newBox("moov",
+				newMvhd(created, 1000, 12500),
+				newBox("trak", newTkhd(0, 1, 3840, 2160, false)),
+				newBox("trak", newTkhd(0, 2, 0, 0, false)),
+				newBox("udta", newText("xyz", "-33.8568+151.2153/"))),
+			newBox("uuid", xmpUUID, packet),
+			newBox("mdat", make([]byte, 64)),
+		}, nil), 12500 * time.Millisecond, -33.8568, 151.2153},
+		{"cmaf", bytes.Join([][]byte{
+			newBox("ftyp", []byte("cmfc\x00\x00\x00\x00cmfciso6")),
+			newBox("moov",
+				newMvhd(created, 90000, 0),
+				newBox("trak", newTkhd(0, 1, 3840, 2160, false)),
+				newBox("mvex", newBox("mehd", u32(0, 90000*30)), newBox("trex", make([]byte, 24))),
+				newBox("udta", newLoci(48.8584, 2.2945, 35))),
+			newBox("uuid", xmpUUID, packet),
+			newBox("moof", make([]byte, 32)),
+			newBox("mdat", make([]byte, 64)),
+		}, nil), 30 * time.Second, 48.8584, 2.2945},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if it, err := imagetype.ReadAt(bytes.NewReader(test.buf)); err != nil || it != imagetype.ImageMP4 {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageMP4, it, err)
+			}
+			m, err := Parse(bytes.NewReader(test.buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.ImageType() != imagetype.ImageMP4 {
+				t.Errorf("Incorrect ImageType wanted %s got %s", imagetype.ImageMP4, m.ImageType())
+			}
+			if m.Dimensions() != meta.NewDimensions(3840, 2160) {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 3840, 2160, m.Dimensions())
+			}
+			if m.Duration() != test.duration {
+				t.Errorf("Incorrect Duration wanted %s got %s", test.duration, m.Duration())
+			}
+			if !m.CreationDate.Equal(created) {
+				t.Errorf("Incorrect CreationDate wanted %s got %s", created, m.CreationDate)
+			}
+			if math.Abs(m.Latitude-test.lat) > 1e-4 || math.Abs(m.Longitude-test.lng) > 1e-4 {
+				t.Errorf("Incorrect Location wanted %f %f got %f %f", test.lat, test.lng, m.Latitude, m.Longitude)
+			}
+			e, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tm, err := e.DateTime(nil); err != nil || !tm.Equal(created) {
+				t.Errorf("Incorrect DateTime wanted %s got %s (%v)", created, tm, err)
+			}
+			x, err := m.Xmp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Basic.Rating != 2 {
+				t.Errorf("Incorrect Xmp got %+v", x.Basic)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+// Package orf decodes (ORF) Olympus and OM System Raw Metadata. ORF is a Tiff based format
+// with a modified magic number in the Tiff Header.
+//
+// The largest JPEG preview is located in the CameraSettings Ifd of the Olympus Makernote.
+package orf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoORFHeader = errors.New("no ORF Header")
+	ErrNoPreview   = exif.ErrNoPreview
+)
+
+// Olympus Makernote tags
+const (
+	tagCameraSettings     = 0x2020
+	tagPreviewImageStart  = 0x0101
+	tagPreviewImageLength = 0x0102
+)
+
+// Olympus Makernote Headers
+var (
+	// "OLYMPUS\0" followed by the byte order and version. Offsets are relative to the
+	// start of the Makernote.
+	mkNoteHeaderOlympus = []byte("OLYMPUS\x00")
+	// "OLYMP\0" followed by the version. Offsets are relative to the Tiff Header.
+	mkNoteHeaderOlymp = []byte("OLYMP\x00")
+)
+
+// Lengths
+const (
+	headerLength              = 8
+	mkNoteHeaderLengthOlympus = 12
+	mkNoteHeaderLengthOlymp   = 8
+	ifdEntryLength            = 12
+	maxMkNoteIfdEntries       = 512
+)
+
+// Metadata is an ORF file's Metadata
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+
+	e       *exif.Data
+	preview exif.Preview
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return m.e.Dimensions()
+}
+
+// ImageType returns imagetype.ImageORF for Olympus ORF image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageORF
+}
+
+// PreviewImage returns the largest JPEG preview image. Returns the
+// ORF file if it does not have a JPEG preview.
+func (m Metadata) PreviewImage() io.Reader {
+	if p, err := m.LargestPreview(); err == nil {
+		return io.NewSectionReader(m.mr, int64(p.Offset), int64(p.Length))
+	}
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from ORF
+func (m Metadata) Exif() (exif.Exif, error) {
+	return m.e, nil
+}
+
+// Xmp returns parsed Xmp data from the "IFD" XMLPacket of an ORF.
+// Returns xmp.ErrNoXMP if the ORF does not have XMP metadata.
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	t, err := m.e.GetTag(ifds.IFD0, 0, ifds.XMLPacket)
+	if err != nil {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	buf, err := m.e.RawTagBytes(t)
+	if err != nil {
+		return xmp.XMP{}, err
+	}
+	return xmp.ParseXmp(bytes.NewReader(buf))
+}
+
+// Previews returns the JPEG preview of the Olympus Makernote followed by the
+// JPEG previews of the Tiff Ifds.
+func (m Metadata) Previews() []exif.Preview {
+	var previews []exif.Preview
+	if m.preview.Length > 0 {
+		previews = append(previews, m.preview)
+	}
+	return append(previews, m.e.Previews()...)
+}
+
+// LargestPreview returns the JPEG preview of the Olympus Makernote, or the
+// first JPEG preview of the Tiff Ifds when the Makernote does not have one.
+//
+// Returns ErrNoPreview if the ORF does not have a JPEG preview.
+func (m Metadata) LargestPreview() (exif.Preview, error) {
+	previews := m.Previews()
+	if len(previews) == 0 {
+		return exif.Preview{}, ErrNoPreview
+	}
+	return previews[0], nil
+}
+
+// Parse reads the ORF Header, the Exif metadata and the location of the
+// JPEG preview of the Olympus Makernote from mr. Returns Metadata.
+//
+// Returns the error ErrNoORFHeader if mr does not begin with an ORF Header.
+func Parse(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if _, err = mr.ReadAt(buf[:], 0); err != nil {
+		return m, ErrNoORFHeader
+	}
+	if !isORFHeader(buf[:]) {
+		return m, ErrNoORFHeader
+	}
+	byteOrder := orfByteOrder(buf[0])
+	m.ExifHeader = meta.NewExifHeader(byteOrder, byteOrder.Uint32(buf[4:8]), 0, 0, imagetype.ImageORF)
+
+	if m.e, err = exif.ParseExif(mr, m.ExifHeader); err != nil {
+		return m, err
+	}
+	m.preview, _ = m.readMkNotePreview()
+	return m, nil
+}
+
+// readMkNotePreview reads the PreviewImageStart and PreviewImageLength of the
+// CameraSettings Ifd of the Olympus Makernote.
+func (m Metadata) readMkNotePreview() (p exif.Preview, err error) {
+	offset, length, err := m.e.MakerNote()
+	if err != nil {
+		return p, err
+	}
+	header := make([]byte, mkNoteHeaderLengthOlympus)
+	if length < uint32(len(header)) {
+		return p, ErrNoPreview
+	}
+	if _, err = m.mr.ReadAt(header, int64(offset)); err != nil {
+		return p, err
+	}
+
+	// base is the offset that the Makernote offsets are relative to.
+	// ORF is a classic Tiff with 32-bit offsets.
+	var base, ifdOffset uint32
+	mkNoteOffset := uint32(offset)
+	byteOrder := m.ExifHeader.ByteOrder
+	switch {
+	case bytes.HasPrefix(header, mkNoteHeaderOlympus):
+		// The byte order ("II" or "MM") follows the header
+		base, ifdOffset = mkNoteOffset, mkNoteOffset+mkNoteHeaderLengthOlympus
+		byteOrder = orfByteOrder(header[8])
+	case bytes.HasPrefix(header, mkNoteHeaderOlymp):
+		base, ifdOffset = m.ExifHeader.TiffHeaderOffset, mkNoteOffset+mkNoteHeaderLengthOlymp
+	default:
+		return p, ErrNoPreview
+	}
+
+	// CameraSettings Ifd
+	settings, ok := m.findEntry(byteOrder, ifdOffset, tagCameraSettings)
+	if !ok {
+		return p, ErrNoPreview
+	}
+	settingsOffset := base + settings
+	start, ok := m.findEntry(byteOrder, settingsOffset, tagPreviewImageStart)
+	if !ok {
+		return p, ErrNoPreview
+	}
+	if p.Length, ok = m.findEntry(byteOrder, settingsOffset, tagPreviewImageLength); !ok || p.Length == 0 {
+		return p, ErrNoPreview
+	}
+	p.Ifd = ifds.MknoteIFD
+	p.Offset = base + start
+	return p, nil
+}
+
+// findEntry returns the 32bit value of the entry of the Ifd at offset with the tag id.
+// Returns false if the Ifd does not have the entry.
+func (m Metadata) findEntry(byteOrder binary.ByteOrder, offset uint32, id uint16) (uint32, bool) {
+	var buf [2]byte
+	if _, err := m.mr.ReadAt(buf[:], int64(offset)); err != nil {
+		return 0, false
+	}
+	count := int(byteOrder.Uint16(buf[:]))
+	if count > maxMkNoteIfdEntries {
+		return 0, false
+	}
+	entries := make([]byte, count*ifdEntryLength)
+	if _, err := m.mr.ReadAt(entries, int64(offset)+2); err != nil {
+		return 0, false
+	}
+	for i := 0; i < count; i++ {
+		entry := entries[i*ifdEntryLength:]
+		if byteOrder.Uint16(entry[:2]) == id {
+			return byteOrder.Uint32(entry[8:12]), true
+		}
+	}
+	return 0, false
+}
+
+// isORFHeader returns true if buf begins with the byte order and the ORF magic
+// number "RO" or "RS" (LittleEndian) or "OR" (BigEndian).
+func isORFHeader(buf []byte) bool {
+	return len(buf) >= 4 && (string(buf[:4]) == "IIRO" || string(buf[:4]) == "IIRS" || string(buf[:4]) == "MMOR")
+}
+
+// orfByteOrder returns the byte order of the first byte of "II" or "MM".
+func orfByteOrder(b byte) binary.ByteOrder {
+	if b == 'M' {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
@@ -0,0 +1,130 @@
+package orf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+var testPreview = []byte{0xFF, 0xD8, 0xFF, 0xDB, 0x00, 0x00, 0xFF, 0xD9}
+
+// olympusMakerNote returns an "OLYMPUS\0" Makernote with a CameraSettings Ifd
+// that has the location of preview, which follows the Ifds in the Makernote.
+func olympusMakerNote(bo binary.ByteOrder, preview []byte) []byte {
+	entry := func(id, t uint16, count, value uint32) []byte {
+		b := make([]byte, ifdEntryLength)
+		bo.PutUint16(b[0:], id)
+		bo.PutUint16(b[2:], t)
+		bo.PutUint32(b[4:], count)
+		bo.PutUint32(b[8:], value)
+		return b
+	}
+	count := func(n uint16) []byte { b := make([]byte, 2); bo.PutUint16(b, n); return b }
+
+	buf := append([]byte{}, mkNoteHeaderOlympus...)
+	if bo == binary.BigEndian {
+		buf = append(buf, 'M', 'M', 0x03, 0x00)
+	} else {
+		buf = append(buf, 'I', 'I', 0x03, 0x00)
+	}
+	// Makernote Ifd at 12 with 1 entry, CameraSettings Ifd at 12+2+12+4 = 30 with 3 entries,
+	// preview at 30+2+3*12+4 = 72
+	buf = append(buf, count(1)...)
+	buf = append(buf, entry(tagCameraSettings, 13, 1, 30)...)
+	buf = append(buf, 0, 0, 0, 0)
+	buf = append(buf, count(3)...)
+	buf = append(buf, entry(0x0100, 7, 4, 1)...)
+	buf = append(buf, entry(tagPreviewImageStart, 4, 1, 72)...)
+	buf = append(buf, entry(tagPreviewImageLength, 4, 1, uint32(len(preview)))...)
+	buf = append(buf, 0, 0, 0, 0)
+	return append(buf, preview...)
+}
+
+// newORF returns an ORF with the Olympus Makernote mkNote.
+func newORF(t *testing.T, bo binary.ByteOrder, mkNote []byte) []byte {
+	t.Helper()
+	b := exif.NewBuilder(bo)
+	for _, err := range []error{
+		b.SetCamera("OLYMPUS CORPORATION", "E-M1MarkII"),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 5240),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 3912),
+		b.SetLong(ifds.ExifIFD, 0, exififd.PixelXDimension, 5184),
+		b.SetLong(ifds.ExifIFD, 0, exififd.PixelYDimension, 3888),
+		b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, mkNote),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ORF magic number
+	if bo == binary.BigEndian {
+		copy(buf[:4], "MMOR")
+	} else {
+		copy(buf[:4], "IIRO")
+	}
+	return buf
+}
+
+func TestParse(t *testing.T) {
+	for _, bo := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		t.Run(bo.String(), func(t *testing.T) {
+			buf := newORF(t, bo, olympusMakerNote(bo, testPreview))
+			if it, err := imagetype.ReadAt(bytes.NewReader(buf)); err != nil || it != imagetype.ImageORF {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageORF, it, err)
+			}
+			m, err := Parse(bytes.NewReader(buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.ImageType() != imagetype.ImageORF || m.Dimensions() != meta.NewDimensions(5184, 3888) {
+				t.Errorf("Incorrect Metadata got %s %s", m.ImageType(), m.Dimensions())
+			}
+			e, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.CameraMake() != "OLYMPUS CORPORATION" || e.CameraModel() != "E-M1MarkII" {
+				t.Errorf("Incorrect camera got %s %s", e.CameraMake(), e.CameraModel())
+			}
+			p, err := m.LargestPreview()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Ifd != ifds.MknoteIFD || p.Length != uint32(len(testPreview)) {
+				t.Errorf("Incorrect Preview got %+v", p)
+			}
+			preview, err := io.ReadAll(m.PreviewImage())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(preview, testPreview) {
+				t.Errorf("Incorrect PreviewImage wanted %x got %x", testPreview, preview)
+			}
+		})
+	}
+
+	// Makernote without a preview
+	m, err := Parse(bytes.NewReader(newORF(t, binary.LittleEndian, []byte("OLYMPUS\x00II\x03\x00\x00\x00"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.LargestPreview(); err != ErrNoPreview {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoPreview, err)
+	}
+
+	// Tiff Header
+	if _, err = Parse(bytes.NewReader([]byte{'I', 'I', 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00})); err != ErrNoORFHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoORFHeader, err)
+	}
+}
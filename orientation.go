@@ -0,0 +1,81 @@
+package imagemeta
+
+import (
+	"io"
+	"regexp"
+
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// xmpOrientation matches the value of tiff:Orientation as an attribute or an element.
+var xmpOrientation = regexp.MustCompile(`tiff:Orientation\s*=\s*["'][1-8]["']|<tiff:Orientation>\s*[1-8]\s*<`)
+
+// NormalizeOrientation copies the image from r to w with the IFD0 Orientation
+// set to 1 (Horizontal), for images with pixels that have been rotated.
+// If updateXMP is true the XMP tiff:Orientation is also set to 1.
+//
+// Only the bytes of the orientation values are changed, the rest of the image
+// is copied unchanged. Images without an Orientation are copied unchanged.
+// Supports JPEG and Tiff based images. Returns ErrMetadataNotSupported for
+// other image types and BigTiff.
+func NormalizeOrientation(r meta.Reader, w io.Writer, updateXMP bool) error {
+	e, exifHeader, xmpHeader, err := patchHeaders(r)
+	if err != nil {
+		return err
+	}
+
+	var patches []patch
+	if e != nil {
+		if p, ok, err := orientationPatch(r, exifHeader); err != nil {
+			return err
+		} else if ok {
+			patches = append(patches, p)
+		}
+	}
+	if updateXMP && xmpHeader.Length > 0 {
+		buf, err := readXMPPacket(r, xmpHeader)
+		if err != nil {
+			return err
+		}
+		for _, loc := range xmpOrientation.FindAllIndex(buf, -1) {
+			// The value is the last digit of the match
+			for i := loc[1] - 1; i >= loc[0]; i-- {
+				if buf[i] >= '1' && buf[i] <= '8' {
+					patches = append(patches, patch{offset: int64(xmpHeader.Offset) + int64(i), value: []byte{'1'}})
+					break
+				}
+			}
+		}
+	}
+	return copyPatched(r, w, patches)
+}
+
+// orientationPatch returns the patch of the Orientation value in the IFD0 entries.
+// Returns false if IFD0 does not have an Orientation of type Short.
+func orientationPatch(r io.ReaderAt, header meta.ExifHeader) (p patch, ok bool, err error) {
+	byteOrder := header.ByteOrder
+	offset := int64(header.TiffHeaderOffset) + int64(header.FirstIfdOffset)
+	var buf [12]byte
+	if _, err = r.ReadAt(buf[:2], offset); err != nil {
+		return p, false, err
+	}
+	count := int64(byteOrder.Uint16(buf[:2]))
+	for i := int64(0); i < count; i++ {
+		entry := offset + 2 + i*12
+		if _, err = r.ReadAt(buf[:], entry); err != nil {
+			return p, false, err
+		}
+		if tag.ID(byteOrder.Uint16(buf[:2])) != ifds.Orientation {
+			continue
+		}
+		if tag.Type(byteOrder.Uint16(buf[2:4])) != tag.TypeShort || byteOrder.Uint32(buf[4:8]) != 1 {
+			return p, false, nil
+		}
+		value := make([]byte, 2)
+		byteOrder.PutUint16(value, uint16(meta.OrientationHorizontal))
+		return patch{offset: entry + 8, value: value}, true, nil
+	}
+	return p, false, nil
+}
@@ -0,0 +1,123 @@
+package imagemeta
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/tiff"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// diffBytes returns the number of bytes that are different in a and b.
+func diffBytes(a, b []byte) (n int) {
+	for i := range a {
+		if a[i] != b[i] {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNormalizeOrientation(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JPEG with Exif and XMP orientation 6
+	var src bytes.Buffer
+	err = rewriteExif(bytes.NewReader(buf), &src, func(b *exif.Builder) error {
+		return b.SetShort(ifds.IFD0, 0, ifds.Orientation, uint16(meta.OrientationRotate90))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, err := xmp.Marshal(xmp.XMP{Tiff: xmp.Tiff{Make: "Canon", Orientation: meta.OrientationRotate90}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orig bytes.Buffer
+	if err = jpeg.Rewrite(bytes.NewReader(src.Bytes()), &orig, jpeg.RewriteOptions{XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, updateXMP := range []bool{true, false} {
+		var out bytes.Buffer
+		if err = NormalizeOrientation(bytes.NewReader(orig.Bytes()), &out, updateXMP); err != nil {
+			t.Fatal(err)
+		}
+		if !assert.Equal(t, orig.Len(), out.Len()) {
+			continue
+		}
+		m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := exif.ParseExif(bytes.NewReader(out.Bytes()), m.ExifHeader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, meta.OrientationHorizontal, e.Orientation())
+		x, err := m.Xmp()
+		assert.NoError(t, err)
+		if updateXMP {
+			assert.Equal(t, meta.OrientationHorizontal, x.Tiff.Orientation)
+			assert.Equal(t, 2, diffBytes(orig.Bytes(), out.Bytes()))
+		} else {
+			assert.Equal(t, meta.OrientationRotate90, x.Tiff.Orientation)
+			assert.Equal(t, 1, diffBytes(orig.Bytes(), out.Bytes()))
+		}
+	}
+}
+
+func TestNormalizeOrientationTiff(t *testing.T) {
+	orig, err := os.ReadFile("testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// CR2 with orientation 8
+	header, err := tiff.ScanTiffHeader(bytes.NewReader(orig), imagetype.ImageCR2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok, err := orientationPatch(bytes.NewReader(orig), header)
+	if err != nil || !ok {
+		t.Fatalf("Orientation of IFD0 not found: %v", err)
+	}
+	header.ByteOrder.PutUint16(p.value, uint16(meta.OrientationRotate270))
+	var src bytes.Buffer
+	if err = copyPatched(bytes.NewReader(orig), &src, []patch{p}); err != nil {
+		t.Fatal(err)
+	}
+	buf := src.Bytes()
+	assert.Equal(t, 1, diffBytes(orig, buf))
+
+	var out bytes.Buffer
+	if err = NormalizeOrientation(bytes.NewReader(buf), &out, true); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(buf), out.Len())
+	m, err := Parse(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, meta.OrientationHorizontal, e.Orientation())
+	assert.Equal(t, orig, out.Bytes())
+
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, NormalizeOrientation(f, &out, false), ErrMetadataNotSupported)
+}
@@ -0,0 +1,87 @@
+package imagemeta
+
+import (
+	"errors"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/tiff"
+)
+
+// patch replaces the bytes at offset with value.
+type patch struct {
+	offset int64
+	value  []byte
+}
+
+// patchHeaders returns the parsed Exif and the Exif and XMP headers of a JPEG or
+// Tiff based image, for images that are changed in place with patches.
+// e is nil if the image does not have Exif. The XMP of Tiff based images is in IFD0.
+//
+// Returns ErrMetadataNotSupported for other image types and BigTiff.
+func patchHeaders(r meta.Reader) (e *exif.Data, exifHeader meta.ExifHeader, xmpHeader meta.XmpHeader, err error) {
+	t, err := imagetype.ReadAt(r)
+	if err != nil {
+		return nil, exifHeader, xmpHeader, err
+	}
+	switch t {
+	case imagetype.ImageJPEG:
+		m, err := jpeg.ScanJPEG(r, nil, nil)
+		if err != nil && !errors.Is(err, ErrNoExif) {
+			return nil, exifHeader, xmpHeader, err
+		}
+		exifHeader, xmpHeader = m.ExifHeader, m.XmpHeader
+	case imagetype.ImageTiff, imagetype.ImageCR2, imagetype.ImageARW, imagetype.ImageNEF, imagetype.ImagePanaRAW, imagetype.ImageDNG:
+		if exifHeader, err = tiff.ScanTiffHeader(r, t); err != nil {
+			return nil, exifHeader, xmpHeader, err
+		}
+	default:
+		return nil, exifHeader, xmpHeader, ErrMetadataNotSupported
+	}
+	if exifHeader.BigTiff {
+		return nil, exifHeader, xmpHeader, ErrMetadataNotSupported
+	}
+	if !exifHeader.IsValid() {
+		return nil, exifHeader, xmpHeader, nil
+	}
+
+	if e, err = exif.ParseExif(r, exifHeader); err != nil {
+		return nil, exifHeader, xmpHeader, err
+	}
+	if xt, err := e.GetTag(ifds.IFD0, 0, ifds.XMLPacket); err == nil && xt.Size() > 4 {
+		xmpHeader = meta.NewXMPHeader(exifHeader.TiffHeaderOffset+uint32(xt.ValueOffset), xt.Size())
+	}
+	return e, exifHeader, xmpHeader, nil
+}
+
+// readXMPPacket returns the XMP packet of header.
+func readXMPPacket(r io.ReaderAt, header meta.XmpHeader) ([]byte, error) {
+	buf := make([]byte, header.Length)
+	if _, err := r.ReadAt(buf, int64(header.Offset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// copyPatched copies r to w with the patches applied.
+func copyPatched(r io.ReaderAt, w io.Writer, patches []patch) error {
+	sort.Slice(patches, func(i, j int) bool { return patches[i].offset < patches[j].offset })
+	var pos int64
+	for _, p := range patches {
+		if _, err := io.Copy(w, io.NewSectionReader(r, pos, p.offset-pos)); err != nil {
+			return err
+		}
+		if _, err := w.Write(p.value); err != nil {
+			return err
+		}
+		pos = p.offset + int64(len(p.value))
+	}
+	_, err := io.Copy(w, io.NewSectionReader(r, pos, math.MaxInt64-pos))
+	return err
+}
@@ -0,0 +1,131 @@
+// Package pef decodes (PEF) Pentax Electronic File Metadata. PEF is a Tiff based format
+// that is identified by the Pentax Makernote of its Exif.
+//
+// The largest JPEG preview is located by the PreviewImageStart and PreviewImageLength
+// tags of the Pentax Makernote.
+package pef
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoPEFHeader    = errors.New("no PEF Header")
+	ErrNoPentaxMkNote = errors.New("error PEF does not have a Pentax makernote")
+	ErrNoPreview      = exif.ErrNoPreview
+)
+
+// headerLength is the length of the Tiff header
+const headerLength = 8
+
+// Metadata is a PEF file's Metadata
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+
+	e *exif.Data
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return m.e.Dimensions()
+}
+
+// ImageType returns imagetype.ImagePEF for Pentax PEF image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImagePEF
+}
+
+// PreviewImage returns the largest JPEG preview image. Returns the
+// PEF file if it does not have a JPEG preview.
+func (m Metadata) PreviewImage() io.Reader {
+	if p, err := m.LargestPreview(); err == nil {
+		return io.NewSectionReader(m.mr, int64(m.ExifHeader.TiffHeaderOffset+p.Offset), int64(p.Length))
+	}
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from PEF
+func (m Metadata) Exif() (exif.Exif, error) {
+	return m.e, nil
+}
+
+// Xmp returns parsed Xmp data from the "IFD" XMLPacket of a PEF.
+// Returns xmp.ErrNoXMP if the PEF does not have XMP metadata.
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	t, err := m.e.GetTag(ifds.IFD0, 0, ifds.XMLPacket)
+	if err != nil {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	buf, err := m.e.RawTagBytes(t)
+	if err != nil {
+		return xmp.XMP{}, err
+	}
+	return xmp.ParseXmp(bytes.NewReader(buf))
+}
+
+// Previews returns the JPEG preview of the Pentax Makernote followed by the
+// JPEG previews of the Tiff Ifds.
+func (m Metadata) Previews() []exif.Preview {
+	var previews []exif.Preview
+	if p, err := m.e.PentaxPreview(); err == nil {
+		previews = append(previews, p)
+	}
+	return append(previews, m.e.Previews()...)
+}
+
+// LargestPreview returns the JPEG preview with the largest dimensions, the
+// JPEG preview of the Pentax Makernote for most PEF files.
+//
+// Returns ErrNoPreview if the PEF does not have a JPEG preview.
+func (m Metadata) LargestPreview() (p exif.Preview, err error) {
+	previews := m.Previews()
+	if len(previews) == 0 {
+		return p, ErrNoPreview
+	}
+	p = previews[0]
+	for _, pp := range previews[1:] {
+		if uint64(pp.Width)*uint64(pp.Height) > uint64(p.Width)*uint64(p.Height) {
+			p = pp
+		}
+	}
+	return p, nil
+}
+
+// Parse reads the Tiff Header and the Exif metadata of a PEF from mr. Returns Metadata.
+//
+// Returns the error ErrNoPEFHeader if mr does not begin with a Tiff Header, and
+// ErrNoPentaxMkNote if the Exif does not have a Pentax Makernote.
+func Parse(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if _, err = mr.ReadAt(buf[:], 0); err != nil {
+		return m, ErrNoPEFHeader
+	}
+	byteOrder := meta.BinaryOrder(buf[:])
+	if byteOrder == nil {
+		return m, ErrNoPEFHeader
+	}
+	m.ExifHeader = meta.NewExifHeader(byteOrder, byteOrder.Uint32(buf[4:8]), 0, 0, imagetype.ImageTiff)
+
+	if m.e, err = exif.ParseExif(mr, m.ExifHeader); err != nil {
+		return m, err
+	}
+	// The imagetype is updated by the Pentax Makernote
+	if m.e.ImageType() != imagetype.ImagePEF {
+		return m, ErrNoPentaxMkNote
+	}
+	m.ExifHeader.ImageType = imagetype.ImagePEF
+	return m, nil
+}
@@ -0,0 +1,104 @@
+package pef
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+var testPreview = []byte{0xFF, 0xD8, 0xFF, 0xDB, 0x00, 0x00, 0xFF, 0xD9}
+
+// newPEF returns a PEF with a Pentax Makernote with mkNoteHeader. The Makernote
+// Ifd has the PreviewImageSize, PreviewImageLength and PreviewImageStart of
+// the preview that follows the Ifd.
+func newPEF(t *testing.T, cameraMake string, mkNoteHeader string) []byte {
+	t.Helper()
+	bo := binary.BigEndian
+	entry := func(id, t uint16, count, value uint32) []byte {
+		b := make([]byte, 12)
+		bo.PutUint16(b[0:], id)
+		bo.PutUint16(b[2:], t)
+		bo.PutUint32(b[4:], count)
+		bo.PutUint32(b[8:], value)
+		return b
+	}
+	mkNote := append([]byte(mkNoteHeader), 0, 3)
+	mkNote = append(mkNote, entry(0x0002, 3, 2, 640<<16|480)...)
+	mkNote = append(mkNote, entry(0x0003, 4, 1, uint32(len(testPreview)))...)
+	mkNote = append(mkNote, entry(0x0004, 4, 1, 0)...)
+	mkNote = append(mkNote, 0, 0, 0, 0)
+	mkNote = append(mkNote, testPreview...)
+
+	b := exif.NewBuilder(bo)
+	for _, err := range []error{
+		b.SetCamera(cameraMake, "PENTAX K-1"),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 7392),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 4950),
+		b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, mkNote),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// PreviewImageStart is relative to the Tiff Header
+	start := bytes.Index(buf, []byte(mkNoteHeader))
+	if start < 0 {
+		t.Fatal("Makernote not found")
+	}
+	ifdOffset := start + len(mkNoteHeader)
+	bo.PutUint32(buf[ifdOffset+2+2*12+8:], uint32(ifdOffset+2+3*12+4))
+	return buf
+}
+
+func TestParse(t *testing.T) {
+	for _, header := range []string{"AOC\x00MM", "PENTAX \x00MM"} {
+		t.Run(header[:3], func(t *testing.T) {
+			m, err := Parse(bytes.NewReader(newPEF(t, "PENTAX Corporation", header)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.ImageType() != imagetype.ImagePEF || m.Dimensions() != meta.NewDimensions(7392, 4950) {
+				t.Errorf("Incorrect Metadata got %s %s", m.ImageType(), m.Dimensions())
+			}
+			p, err := m.LargestPreview()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Ifd != ifds.MknoteIFD || p.Width != 640 || p.Height != 480 || p.Length != uint32(len(testPreview)) {
+				t.Errorf("Incorrect Preview got %+v", p)
+			}
+			preview, err := io.ReadAll(m.PreviewImage())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(preview, testPreview) {
+				t.Errorf("Incorrect PreviewImage wanted %x got %x", testPreview, preview)
+			}
+			if _, err = m.Xmp(); err != xmp.ErrNoXMP {
+				t.Errorf("Incorrect error wanted %v got %v", xmp.ErrNoXMP, err)
+			}
+		})
+	}
+
+	// Tiff without a Pentax Makernote
+	if _, err := Parse(bytes.NewReader(newPEF(t, "NIKON CORPORATION", "AOC\x00MM"))); err != ErrNoPentaxMkNote {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoPentaxMkNote, err)
+	}
+
+	// ORF Header
+	if _, err := Parse(bytes.NewReader([]byte{'I', 'I', 'R', 'O', 0x08, 0x00, 0x00, 0x00})); err != ErrNoPEFHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoPEFHeader, err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package png reads the metadata chunks (Exif, XMP and text) and writes the Exif
+// and XMP chunks of a PNG Image.
+package png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// Errors
+var (
+	ErrNoExif         = meta.ErrNoExif
+	ErrNoPNGSignature = errors.New("no PNG Signature")
+
+	// ErrCorruptChunk is returned when a chunk is truncated or
+	// its length is larger than the PNG maximum.
+	ErrCorruptChunk = errors.New("corrupt PNG chunk")
+
+	// ErrChunkTooLarge is returned when metadata does not fit in a PNG chunk.
+	ErrChunkTooLarge = errors.New("PNG chunk too large")
+)
+
+// pngSignature are the first 8 bytes of a PNG
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+// pngByteOrder is the byte order of PNG chunk lengths and CRCs
+var pngByteOrder = binary.BigEndian
+
+// maxChunkLength is the largest length of the data of a PNG chunk.
+const maxChunkLength = 1<<31 - 1
+
+// Chunk types
+var (
+	chunkIDAT = [4]byte{'I', 'D', 'A', 'T'}
+	chunkIEND = [4]byte{'I', 'E', 'N', 'D'}
+	chunkEXIF = [4]byte{'e', 'X', 'I', 'f'}
+	chunkITXT = [4]byte{'i', 'T', 'X', 't'}
+)
+
+// exifPrefix is the JPEG APP1 Exif prefix written before the Tiff Header by some encoders.
+var exifPrefix = []byte("Exif\x00\x00")
+
+// exifPrefixLength is the length of exifPrefix
+const exifPrefixLength = 6
+
+// xmpKeyword is the iTXt keyword of an XMP packet followed by its NUL separator.
+var xmpKeyword = []byte("XML:com.adobe.xmp\x00")
+
+// chunkHeader is the length and type of a chunk.
+type chunkHeader struct {
+	length uint32
+	typ    [4]byte
+}
+
+// readChunkHeader reads the length and type of the next chunk.
+func readChunkHeader(r io.Reader) (h chunkHeader, err error) {
+	var buf [8]byte
+	if _, err = io.ReadFull(r, buf[:]); err != nil {
+		if err == io.EOF {
+			return h, err
+		}
+		return h, ErrCorruptChunk
+	}
+	h.length = pngByteOrder.Uint32(buf[:4])
+	if h.length > maxChunkLength {
+		return h, ErrCorruptChunk
+	}
+	copy(h.typ[:], buf[4:])
+	return h, nil
+}
+
+// newChunk returns the chunk of typ with data, with its length and CRC.
+func newChunk(typ [4]byte, data []byte) ([]byte, error) {
+	if len(data) > maxChunkLength {
+		return nil, ErrChunkTooLarge
+	}
+	buf := make([]byte, 12+len(data))
+	pngByteOrder.PutUint32(buf[:4], uint32(len(data)))
+	copy(buf[4:8], typ[:])
+	copy(buf[8:], data)
+	pngByteOrder.PutUint32(buf[8+len(data):], crc32.ChecksumIEEE(buf[4:8+len(data)]))
+	return buf, nil
+}
+
+// xmpChunkData returns the data of an uncompressed iTXt chunk with an XMP packet.
+// The language tag and translated keyword are empty.
+func xmpChunkData(packet []byte) []byte {
+	buf := make([]byte, 0, len(xmpKeyword)+4+len(packet))
+	buf = append(buf, xmpKeyword...)
+	// Compression flag, compression method, language tag and translated keyword
+	buf = append(buf, 0, 0, 0, 0)
+	return append(buf, packet...)
+}
+
+// isXMPChunk returns true if data is the data of an iTXt chunk with an XMP packet.
+func isXMPChunk(data []byte) bool {
+	return bytes.HasPrefix(data, xmpKeyword)
+}
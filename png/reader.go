@@ -0,0 +1,305 @@
+package png
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Chunk types
+var (
+	chunkIHDR = [4]byte{'I', 'H', 'D', 'R'}
+	chunkTEXT = [4]byte{'t', 'E', 'X', 't'}
+	chunkZTXT = [4]byte{'z', 'T', 'X', 't'}
+)
+
+// maxTextLength is the largest text chunk and decompressed text that is read.
+// Larger text chunks are skipped.
+const maxTextLength = 16 << 20
+
+// TextChunk is the keyword and text of a tEXt, zTXt or iTXt chunk.
+// Compressed text is decompressed and Latin-1 text is converted to UTF-8.
+type TextChunk struct {
+	Keyword string
+	Text    string
+}
+
+// Metadata from a PNG file
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+	XmpHeader  meta.XmpHeader
+
+	// Text are the text chunks other than XMP in the order of the PNG.
+	Text []TextChunk
+
+	// Decode Functions for EXIF and XMP metadata
+	exifFn func(r io.Reader, header meta.ExifHeader) error
+	xmpFn  func(r io.Reader, header meta.XmpHeader) error
+
+	// xmp is the decompressed XMP packet of a compressed iTXt chunk
+	xmp []byte
+
+	width  uint32
+	height uint32
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.width, m.height)
+}
+
+// ImageType returns imagetype.ImagePNG for PNG image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImagePNG
+}
+
+// PreviewImage returns a PNG preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from PNG
+func (m Metadata) Exif() (exif.Exif, error) {
+	return exif.ParseExif(m.mr, m.ExifHeader)
+}
+
+// Xmp returns parsed Xmp data from PNG
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	if m.xmp != nil {
+		return xmp.ParseXmp(bytes.NewReader(m.xmp))
+	}
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+// ScanPNG scans a reader for PNG chunks. xmpFn and exifFn are run at their respective
+// positions during the scan. Returns Metadata.
+//
+// The XmpHeader is only set for an uncompressed XMP packet, xmpFn reads the
+// decompressed packet of a compressed iTXt chunk. Text chunks that can not be
+// decompressed are skipped.
+//
+// Returns the error ErrNoPNGSignature if r is not a PNG, ErrCorruptChunk if a chunk
+// is truncated and ErrNoExif if the eXIf chunk was not found.
+func ScanPNG(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = Metadata{mr: mr, exifFn: exifFn, xmpFn: xmpFn}
+
+	signature := make([]byte, len(pngSignature))
+	if _, err = mr.ReadAt(signature, 0); err != nil || !bytes.Equal(signature, pngSignature) {
+		return m, ErrNoPNGSignature
+	}
+
+	offset := int64(len(pngSignature))
+	for {
+		var h chunkHeader
+		if h, err = readChunkHeader(io.NewSectionReader(mr, offset, 8)); err != nil {
+			if err == io.EOF {
+				// PNG without an IEND chunk
+				break
+			}
+			return
+		}
+		offset += 8
+		if err = m.readChunk(h, offset); err != nil || h.typ == chunkIEND {
+			break
+		}
+		// Chunk data and CRC
+		offset += int64(h.length) + 4
+	}
+	if err == nil && !m.ExifHeader.IsValid() {
+		err = ErrNoExif
+	}
+	return
+}
+
+// readChunk reads the chunk with header h and data at offset.
+func (m *Metadata) readChunk(h chunkHeader, offset int64) error {
+	switch h.typ {
+	case chunkIHDR:
+		return m.readIHDR(h, offset)
+	case chunkEXIF:
+		return m.readExif(h, offset)
+	case chunkITXT:
+		return m.readITXt(h, offset)
+	case chunkTEXT, chunkZTXT:
+		return m.readText(h, offset)
+	}
+	return nil
+}
+
+// readIHDR reads the image width and height from the IHDR chunk.
+func (m *Metadata) readIHDR(h chunkHeader, offset int64) error {
+	if h.length < 8 {
+		return ErrCorruptChunk
+	}
+	buf, err := m.readData(offset, 8)
+	if err != nil {
+		return err
+	}
+	m.width = pngByteOrder.Uint32(buf[:4])
+	m.height = pngByteOrder.Uint32(buf[4:8])
+	return nil
+}
+
+// readExif reads the Exif header from the eXIf chunk with the attached
+// metadata exifFn.
+func (m *Metadata) readExif(h chunkHeader, offset int64) error {
+	length := int64(h.length)
+	if length < exifPrefixLength+8 {
+		return nil
+	}
+	buf, err := m.readData(offset, exifPrefixLength+8)
+	if err != nil {
+		return err
+	}
+	// Some encoders write the JPEG APP1 "Exif\0\0" prefix before the Tiff Header
+	if bytes.HasPrefix(buf, exifPrefix) {
+		offset += exifPrefixLength
+		length -= exifPrefixLength
+		buf = buf[exifPrefixLength:]
+	}
+
+	// Create a TiffHeader from the Tiff directory ByteOrder, root IFD Offset,
+	// the tiff Header Offset, and the length of the exif information.
+	byteOrder := meta.BinaryOrder(buf)
+	if byteOrder == nil {
+		return nil
+	}
+	firstIfdOffset := byteOrder.Uint32(buf[4:8])
+	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, uint32(offset), uint32(length), imagetype.ImagePNG)
+
+	// Read Exif
+	if m.exifFn != nil {
+		return m.exifFn(io.NewSectionReader(m.mr, offset, length), m.ExifHeader)
+	}
+	return nil
+}
+
+// readITXt reads an iTXt chunk. The XMP packet is read with the attached
+// metadata xmpFn, other iTXt chunks are added to Text.
+//
+// iTXt: keyword, NUL, compression flag, compression method, language tag, NUL,
+// translated keyword, NUL, text.
+func (m *Metadata) readITXt(h chunkHeader, offset int64) error {
+	if h.length > maxTextLength {
+		return nil
+	}
+	data, err := m.readData(offset, int(h.length))
+	if err != nil {
+		return err
+	}
+	keyword, rest, ok := cutNul(data)
+	if !ok || len(rest) < 2 {
+		return nil
+	}
+	compressed := rest[0] == 1
+	_, rest, ok = cutNul(rest[2:]) // language tag
+	if ok {
+		_, rest, ok = cutNul(rest) // translated keyword
+	}
+	if !ok {
+		return nil
+	}
+	textOffset := offset + int64(len(data)-len(rest))
+	text := rest
+	if compressed {
+		if text, err = inflate(text); err != nil {
+			return nil
+		}
+	}
+
+	if !bytes.Equal(keyword, xmpKeyword[:len(xmpKeyword)-1]) {
+		m.Text = append(m.Text, TextChunk{Keyword: string(keyword), Text: string(text)})
+		return nil
+	}
+	header := meta.NewXMPHeader(uint32(textOffset), uint32(len(text)))
+	if compressed {
+		m.xmp = text
+		header = meta.NewXMPHeader(0, uint32(len(text)))
+	} else {
+		m.XmpHeader = header
+	}
+
+	// Read XMP Decode Function here
+	if m.xmpFn != nil {
+		return m.xmpFn(bytes.NewReader(text), header)
+	}
+	return nil
+}
+
+// readText reads a tEXt or zTXt chunk and adds it to Text.
+//
+// tEXt: keyword, NUL, Latin-1 text.
+// zTXt: keyword, NUL, compression method, compressed Latin-1 text.
+func (m *Metadata) readText(h chunkHeader, offset int64) error {
+	if h.length > maxTextLength {
+		return nil
+	}
+	data, err := m.readData(offset, int(h.length))
+	if err != nil {
+		return err
+	}
+	keyword, text, ok := cutNul(data)
+	if !ok {
+		return nil
+	}
+	if h.typ == chunkZTXT {
+		if len(text) < 1 {
+			return nil
+		}
+		if text, err = inflate(text[1:]); err != nil {
+			return nil
+		}
+	}
+	m.Text = append(m.Text, TextChunk{Keyword: latin1String(keyword), Text: latin1String(text)})
+	return nil
+}
+
+// readData reads n bytes of chunk data at offset.
+func (m *Metadata) readData(offset int64, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if nn, _ := m.mr.ReadAt(buf, offset); nn < n {
+		return nil, ErrCorruptChunk
+	}
+	return buf, nil
+}
+
+// cutNul returns the bytes of buf before and after the first NUL byte.
+// Returns false if buf does not have a NUL byte.
+func cutNul(buf []byte) (before, after []byte, ok bool) {
+	if i := bytes.IndexByte(buf, 0); i >= 0 {
+		return buf[:i], buf[i+1:], true
+	}
+	return buf, nil, false
+}
+
+// inflate returns the zlib decompressed buf. Returns ErrCorruptChunk if buf
+// is not valid or is larger than maxTextLength when decompressed.
+func inflate(buf []byte) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, ErrCorruptChunk
+	}
+	defer zr.Close()
+	text, err := io.ReadAll(io.LimitReader(zr, maxTextLength+1))
+	if err != nil || len(text) > maxTextLength {
+		return nil, ErrCorruptChunk
+	}
+	return text, nil
+}
+
+// latin1String returns the Latin-1 (ISO 8859-1) buf as an UTF-8 string.
+func latin1String(buf []byte) string {
+	runes := make([]rune, len(buf))
+	for i, b := range buf {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -0,0 +1,107 @@
+package png
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// deflate returns the zlib compressed buf.
+func deflate(t *testing.T, buf []byte) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	zw := zlib.NewWriter(&out)
+	if _, err := zw.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestScanPNG(t *testing.T) {
+	b := exif.NewBuilder(binary.LittleEndian)
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Make, "Canon"); err != nil {
+		t.Fatal(err)
+	}
+	exifData, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 4, Label: "Red"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err = Rewrite(bytes.NewReader(testPNG(t)), &out, RewriteOptions{Exif: exifData, XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+	buf := insertChunk(t, out.Bytes(), 1, "tEXt", []byte("Title\x00Caf\xe9"))
+	buf = insertChunk(t, buf, 2, "zTXt", append([]byte("Comment\x00\x00"), deflate(t, []byte("compressed"))...))
+	buf = insertChunk(t, buf, 3, "iTXt", append([]byte("Description\x00\x01\x00en\x00Beschreibung\x00"), deflate(t, []byte("Käse"))...))
+	buf = insertChunk(t, buf, 4, "zTXt", []byte("Corrupt\x00\x00not zlib"))
+
+	var exifHeader meta.ExifHeader
+	var xmpPacket []byte
+	m, err := ScanPNG(bytes.NewReader(buf), func(r io.Reader, header meta.ExifHeader) error {
+		exifHeader = header
+		return nil
+	}, func(r io.Reader, header meta.XmpHeader) (err error) {
+		xmpPacket, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, meta.NewDimensions(16, 8), m.Dimensions())
+	assert.Equal(t, m.ExifHeader, exifHeader)
+	assert.Equal(t, packet, xmpPacket)
+	assert.Equal(t, []TextChunk{{"Title", "Café"}, {"Comment", "compressed"}, {"Description", "Käse"}}, m.Text)
+
+	e, err := m.Exif()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Canon", e.CameraMake())
+	}
+	x, err := m.Xmp()
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, xmp.Basic{Rating: 4, Label: "Red"}, x.Basic)
+
+	// Compressed XMP and the Exif prefix
+	src := insertChunk(t, testPNG(t), 1, "eXIf", append([]byte("Exif\x00\x00"), exifData...))
+	buf = insertChunk(t, src, 2, "iTXt", append([]byte("XML:com.adobe.xmp\x00\x01\x00\x00\x00"), deflate(t, packet)...))
+	if m, err = ScanPNG(bytes.NewReader(buf), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, meta.XmpHeader{}, m.XmpHeader)
+	if x, err = m.Xmp(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, xmp.Basic{Rating: 4, Label: "Red"}, x.Basic)
+	if e, err = m.Exif(); assert.NoError(t, err) {
+		assert.Equal(t, "Canon", e.CameraMake())
+	}
+}
+
+func TestScanPNGErrors(t *testing.T) {
+	src := testPNG(t)
+	m, err := ScanPNG(bytes.NewReader(src), nil, nil)
+	assert.ErrorIs(t, err, ErrNoExif)
+	assert.Equal(t, meta.NewDimensions(16, 8), m.Dimensions())
+
+	_, err = ScanPNG(bytes.NewReader([]byte("GIF89a")), nil, nil)
+	assert.ErrorIs(t, err, ErrNoPNGSignature)
+	_, err = ScanPNG(bytes.NewReader(src[:len(pngSignature)+12]), nil, nil)
+	assert.ErrorIs(t, err, ErrCorruptChunk)
+}
@@ -0,0 +1,159 @@
+package png
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// RewriteOptions are the metadata chunks written by Rewrite.
+// A nil field keeps the chunks of the PNG unchanged.
+type RewriteOptions struct {
+	// Exif is a Tiff/Exif block, as returned by exif.Builder.Encode.
+	Exif []byte
+
+	// XMP is an XMP packet, as returned by xmp.Marshal.
+	// It is written uncompressed in an iTXt chunk.
+	XMP []byte
+}
+
+// Rewrite copies the PNG from r to w with the eXIf chunk and the iTXt chunk with
+// the "XML:com.adobe.xmp" keyword from opts. Existing chunks before the first IDAT
+// chunk are replaced in place, missing chunks are inserted before the first IDAT
+// chunk in the order Exif, XMP. Existing chunks after the IDAT chunks are removed.
+//
+// All other chunks, including the image data, are copied unchanged.
+// Returns ErrNoPNGSignature if r is not a PNG, ErrCorruptChunk if a chunk is
+// truncated and ErrChunkTooLarge if the metadata does not fit in a chunk.
+func Rewrite(r io.Reader, w io.Writer, opts RewriteOptions) (err error) {
+	rw := rewriter{replaceExif: opts.Exif != nil, replaceXMP: opts.XMP != nil}
+	if opts.Exif != nil {
+		if rw.exif, err = newChunk(chunkEXIF, opts.Exif); err != nil {
+			return err
+		}
+	}
+	if opts.XMP != nil {
+		if rw.xmp, err = newChunk(chunkITXT, xmpChunkData(opts.XMP)); err != nil {
+			return err
+		}
+	}
+
+	br := bufio.NewReader(r)
+	signature := make([]byte, len(pngSignature))
+	if _, err = io.ReadFull(br, signature); err != nil || !bytes.Equal(signature, pngSignature) {
+		return ErrNoPNGSignature
+	}
+	bw := bufio.NewWriter(w)
+	if _, err = bw.Write(signature); err != nil {
+		return err
+	}
+	if err = rw.copyChunks(br, bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// rewriter copies chunks with the Exif and XMP chunks replaced or inserted.
+// A chunk is set to nil once it is written.
+type rewriter struct {
+	exif, xmp               []byte
+	replaceExif, replaceXMP bool
+	idat                    bool
+}
+
+// copyChunks copies the chunks from br to w up to the IEND chunk.
+// The bytes after the IEND chunk are copied unchanged.
+func (rw *rewriter) copyChunks(br *bufio.Reader, w io.Writer) error {
+	for {
+		h, err := readChunkHeader(br)
+		if err != nil {
+			if err == io.EOF {
+				return ErrCorruptChunk
+			}
+			return err
+		}
+		switch {
+		case h.typ == chunkIDAT || h.typ == chunkIEND:
+			if err = rw.writePending(w); err != nil {
+				return err
+			}
+			rw.idat = true
+		case h.typ == chunkEXIF && rw.replaceExif:
+			if _, err = io.CopyN(io.Discard, br, int64(h.length)+4); err != nil {
+				return ErrCorruptChunk
+			}
+			if err = rw.replace(w, &rw.exif); err != nil {
+				return err
+			}
+			continue
+		case h.typ == chunkITXT && rw.replaceXMP:
+			// The keyword is read to find the XMP chunk
+			data := make([]byte, int(h.length)+4)
+			if _, err = io.ReadFull(br, data); err != nil {
+				return ErrCorruptChunk
+			}
+			if isXMPChunk(data[:h.length]) {
+				if err = rw.replace(w, &rw.xmp); err != nil {
+					return err
+				}
+				continue
+			}
+			if err = writeChunkHeader(w, h); err != nil {
+				return err
+			}
+			if _, err = w.Write(data); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Chunk data and CRC
+		if err = writeChunkHeader(w, h); err != nil {
+			return err
+		}
+		if _, err = io.CopyN(w, br, int64(h.length)+4); err != nil {
+			if err == io.EOF {
+				return ErrCorruptChunk
+			}
+			return err
+		}
+		if h.typ == chunkIEND {
+			_, err = io.Copy(w, br)
+			return err
+		}
+	}
+}
+
+// replace writes chunk in place of an existing chunk if it is before
+// the IDAT chunks and has not been written.
+func (rw *rewriter) replace(w io.Writer, chunk *[]byte) error {
+	if rw.idat || *chunk == nil {
+		return nil
+	}
+	_, err := w.Write(*chunk)
+	*chunk = nil
+	return err
+}
+
+// writePending writes the Exif and XMP chunks that have not been written.
+func (rw *rewriter) writePending(w io.Writer) error {
+	for _, chunk := range []*[]byte{&rw.exif, &rw.xmp} {
+		if *chunk == nil {
+			continue
+		}
+		if _, err := w.Write(*chunk); err != nil {
+			return err
+		}
+		*chunk = nil
+	}
+	return nil
+}
+
+// writeChunkHeader writes the length and type of h to w.
+func writeChunkHeader(w io.Writer, h chunkHeader) error {
+	var buf [8]byte
+	pngByteOrder.PutUint32(buf[:4], h.length)
+	copy(buf[4:], h.typ[:])
+	_, err := w.Write(buf[:])
+	return err
+}
@@ -0,0 +1,143 @@
+package png
+
+import (
+	"bytes"
+	"hash/crc32"
+	"image"
+	"image/color"
+	stdpng "image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPNG returns a small encoded PNG image.
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	for x := 0; x < 16; x++ {
+		img.Set(x, x%8, color.RGBA{R: uint8(x * 16), A: 0xff})
+	}
+	var buf bytes.Buffer
+	if err := stdpng.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testChunk is a chunk read by readChunks
+type testChunk struct {
+	typ  string
+	data []byte
+}
+
+// readChunks returns the chunks of a PNG and checks their CRCs.
+func readChunks(t *testing.T, buf []byte) (chunks []testChunk) {
+	t.Helper()
+	if !bytes.HasPrefix(buf, pngSignature) {
+		t.Fatal("PNG signature is missing")
+	}
+	r := bytes.NewReader(buf[len(pngSignature):])
+	for {
+		h, err := readChunkHeader(r)
+		if err == io.EOF {
+			return chunks
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := make([]byte, h.length+4)
+		if _, err = io.ReadFull(r, data); err != nil {
+			t.Fatal(err)
+		}
+		crc := crc32.NewIEEE()
+		crc.Write(h.typ[:])
+		crc.Write(data[:h.length])
+		if crc.Sum32() != pngByteOrder.Uint32(data[h.length:]) {
+			t.Errorf("Incorrect CRC of chunk %s", h.typ)
+		}
+		chunks = append(chunks, testChunk{string(h.typ[:]), data[:h.length]})
+	}
+}
+
+// chunkTypes returns the types of chunks.
+func chunkTypes(chunks []testChunk) (types []string) {
+	for _, c := range chunks {
+		types = append(types, c.typ)
+	}
+	return types
+}
+
+// insertChunk returns buf with chunk inserted before the chunk at index i.
+func insertChunk(t *testing.T, buf []byte, i int, typ string, data []byte) []byte {
+	t.Helper()
+	var chunkType [4]byte
+	copy(chunkType[:], typ)
+	chunk, err := newChunk(chunkType, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := len(pngSignature)
+	for _, c := range readChunks(t, buf)[:i] {
+		pos += 12 + len(c.data)
+	}
+	out := append([]byte(nil), buf[:pos]...)
+	out = append(out, chunk...)
+	return append(out, buf[pos:]...)
+}
+
+func TestRewrite(t *testing.T) {
+	src := testPNG(t)
+	assert.Equal(t, []string{"IHDR", "IDAT", "IEND"}, chunkTypes(readChunks(t, src)))
+	text := append([]byte("Comment\x00"), 0, 0, 0, 0, 'a')
+
+	exifData := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x00\x00\x00\x00\x00")
+	var out bytes.Buffer
+	if err := Rewrite(bytes.NewReader(src), &out, RewriteOptions{Exif: exifData, XMP: []byte("<x:xmpmeta/>")}); err != nil {
+		t.Fatal(err)
+	}
+	chunks := readChunks(t, out.Bytes())
+	assert.Equal(t, []string{"IHDR", "eXIf", "iTXt", "IDAT", "IEND"}, chunkTypes(chunks))
+	assert.Equal(t, exifData, chunks[1].data)
+	assert.Equal(t, xmpChunkData([]byte("<x:xmpmeta/>")), chunks[2].data)
+
+	// The image is decoded unchanged
+	want, err := stdpng.Decode(bytes.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := stdpng.Decode(bytes.NewReader(out.Bytes()))
+	if assert.NoError(t, err) {
+		assert.Equal(t, want, img)
+	}
+
+	// Replaced in place, other iTXt chunks are kept and chunks after IDAT are removed
+	buf := insertChunk(t, out.Bytes(), 2, "iTXt", text)
+	buf = insertChunk(t, buf, 5, "eXIf", exifData)
+	assert.Equal(t, []string{"IHDR", "eXIf", "iTXt", "iTXt", "IDAT", "eXIf", "IEND"}, chunkTypes(readChunks(t, buf)))
+	out.Reset()
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{Exif: exifData[:8], XMP: []byte("<x:xmpmeta></x:xmpmeta>")}); err != nil {
+		t.Fatal(err)
+	}
+	chunks = readChunks(t, out.Bytes())
+	assert.Equal(t, []string{"IHDR", "eXIf", "iTXt", "iTXt", "IDAT", "IEND"}, chunkTypes(chunks))
+	assert.Equal(t, exifData[:8], chunks[1].data)
+	assert.Equal(t, text, chunks[2].data)
+	assert.Equal(t, xmpChunkData([]byte("<x:xmpmeta></x:xmpmeta>")), chunks[3].data)
+
+	// Without metadata the PNG is copied unchanged
+	out.Reset()
+	if err = Rewrite(bytes.NewReader(buf), &out, RewriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.Equal(buf, out.Bytes()), "Rewrite without metadata should copy the PNG unchanged")
+}
+
+func TestRewriteErrors(t *testing.T) {
+	src := testPNG(t)
+	var out bytes.Buffer
+	assert.ErrorIs(t, Rewrite(bytes.NewReader([]byte("GIF89a")), &out, RewriteOptions{}), ErrNoPNGSignature)
+	assert.ErrorIs(t, Rewrite(bytes.NewReader(src[:len(src)-6]), &out, RewriteOptions{}), ErrCorruptChunk)
+	assert.ErrorIs(t, Rewrite(bytes.NewReader(src[:len(pngSignature)]), &out, RewriteOptions{}), ErrCorruptChunk)
+}
@@ -0,0 +1,129 @@
+package imagemeta
+
+import (
+	"io"
+	"sort"
+
+	"github.com/evanoberholster/imagemeta/cr2"
+	"github.com/evanoberholster/imagemeta/cr3"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/orf"
+	"github.com/evanoberholster/imagemeta/pef"
+	"github.com/evanoberholster/imagemeta/srw"
+	"github.com/evanoberholster/imagemeta/tiff"
+	"github.com/evanoberholster/imagemeta/x3f"
+)
+
+// ErrNoPreview is returned when an image does not have an embedded JPEG preview.
+var ErrNoPreview = exif.ErrNoPreview
+
+// Preview is an embedded JPEG preview image of a camera raw or Tiff based image.
+type Preview struct {
+	Width, Height uint32
+
+	// Offset from the start of the file and Length of the JPEG image in bytes
+	Offset, Length int64
+}
+
+// Previews returns the embedded JPEG previews of the camera raw or Tiff based
+// image from r, largest first. CR2, CR3, DNG, NEF, ARW, RW2, ORF, PEF, SRW,
+// X3F and Tiff images are supported. The raw image is not decoded.
+//
+// The dimensions of a preview without a width and a height, such as the Exif
+// thumbnail, are read from its JPEG header.
+//
+// Returns ErrNoPreview if the image does not have a JPEG preview, and
+// ErrMetadataNotSupported if the image type is not supported.
+func Previews(r meta.Reader) ([]Preview, error) {
+	m, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var previews []Preview
+	switch m := m.(type) {
+	case cr2.Metadata:
+		previews = exifPreviews(m.ExifHeader, m.Previews())
+	case pef.Metadata:
+		previews = exifPreviews(m.ExifHeader, m.Previews())
+	case orf.Metadata:
+		previews = exifPreviews(m.ExifHeader, m.Previews())
+	case srw.Metadata:
+		previews = exifPreviews(m.ExifHeader, m.Previews())
+	case tiff.Metadata:
+		if e, _ := m.Exif(); e != nil {
+			previews = exifPreviews(m.ExifHeader, e.Previews())
+		}
+	case cr3.Metadata:
+		for _, p := range m.Previews() {
+			previews = append(previews, Preview{Width: uint32(p.Width), Height: uint32(p.Height), Offset: int64(p.Offset), Length: int64(p.Length)})
+		}
+	case x3f.Metadata:
+		for _, img := range m.Images {
+			if img.IsJPEG() {
+				previews = append(previews, Preview{Width: img.Width, Height: img.Height, Offset: int64(img.Offset), Length: int64(img.Length)})
+			}
+		}
+	default:
+		return nil, ErrMetadataNotSupported
+	}
+	if len(previews) == 0 {
+		return nil, ErrNoPreview
+	}
+	for i, p := range previews {
+		if p.Width == 0 || p.Height == 0 {
+			if j, err := jpeg.ScanJPEG(io.NewSectionReader(r, p.Offset, p.Length), nil, nil); err == nil || err == jpeg.ErrNoExif {
+				dim := j.Dimensions()
+				previews[i].Width, previews[i].Height = dim.Width, dim.Height
+			}
+		}
+	}
+	sort.SliceStable(previews, func(i, j int) bool {
+		return uint64(previews[i].Width)*uint64(previews[i].Height) > uint64(previews[j].Width)*uint64(previews[j].Height)
+	})
+	return previews, nil
+}
+
+// exifPreviews returns the Previews of the Exif previews with offsets
+// relative to the Tiff Header of header. Duplicate previews are removed.
+func exifPreviews(header meta.ExifHeader, exifPreviews []exif.Preview) (previews []Preview) {
+	seen := make(map[int64]bool, len(exifPreviews))
+	for _, p := range exifPreviews {
+		offset := int64(header.TiffHeaderOffset) + int64(p.Offset)
+		if p.Length == 0 || seen[offset] {
+			continue
+		}
+		seen[offset] = true
+		previews = append(previews, Preview{Width: p.Width, Height: p.Height, Offset: offset, Length: int64(p.Length)})
+	}
+	return previews
+}
+
+// PreviewReader returns a reader of the JPEG image of the Preview p from r.
+func PreviewReader(r io.ReaderAt, p Preview) io.Reader {
+	return io.NewSectionReader(r, p.Offset, p.Length)
+}
+
+// SelectPreview returns the smallest preview with a width and a height of at
+// least minSize, or the largest preview when none are large enough. Useful for
+// making thumbnails from the previews returned by Previews.
+//
+// Returns false if there are no previews.
+func SelectPreview(previews []Preview, minSize uint32) (Preview, bool) {
+	if len(previews) == 0 {
+		return Preview{}, false
+	}
+	large := func(p Preview) bool { return p.Width >= minSize && p.Height >= minSize }
+	area := func(p Preview) uint64 { return uint64(p.Width) * uint64(p.Height) }
+	best := previews[0]
+	for _, p := range previews[1:] {
+		switch {
+		case large(p) && (!large(best) || area(p) < area(best)):
+			best = p
+		case !large(p) && !large(best) && area(p) > area(best):
+			best = p
+		}
+	}
+	return best, true
+}
@@ -0,0 +1,157 @@
+package imagemeta
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/bmff"
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviews(t *testing.T) {
+	// 160x120 JPEG header with the SOF0 and SOS segments
+	thumbnail := []byte{0xFF, 0xD8, 0xFF, 0xC0, 0, 17, 8, 0, 120, 0, 160, 3, 1, 0x11, 0, 2, 0x11, 1, 3, 0x11, 1, 0xFF, 0xDA}
+	thumbnail = append(thumbnail, make([]byte, 16)...)
+
+	b := exif.NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("Canon", "Canon EOS 6D"))
+	assert.NoError(t, b.SetThumbnail(thumbnail))
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	previews, err := Previews(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, previews, 1) {
+		assert.Equal(t, uint32(160), previews[0].Width)
+		assert.Equal(t, uint32(120), previews[0].Height)
+		jpg, err := io.ReadAll(PreviewReader(bytes.NewReader(buf), previews[0]))
+		assert.NoError(t, err)
+		assert.Equal(t, thumbnail, jpg)
+	}
+
+	// Tiff without a preview
+	b = exif.NewBuilder(nil)
+	assert.NoError(t, b.SetCamera("Canon", "Canon EOS 6D"))
+	if buf, err = b.Encode(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Previews(bytes.NewReader(buf))
+	assert.ErrorIs(t, err, ErrNoPreview)
+
+	// Not a camera raw or Tiff based image
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = Previews(f)
+	assert.ErrorIs(t, err, ErrMetadataNotSupported)
+}
+
+func TestSelectPreview(t *testing.T) {
+	previews := []Preview{
+		{Width: 6000, Height: 4000, Offset: 1},
+		{Width: 1620, Height: 1080, Offset: 2},
+		{Width: 160, Height: 120, Offset: 3},
+	}
+	for _, v := range []struct {
+		minSize uint32
+		offset  int64
+	}{
+		{0, 3},
+		{120, 3},
+		{256, 2},
+		{1080, 2},
+		{2000, 1},
+		{8000, 1},
+	} {
+		p, ok := SelectPreview(previews, v.minSize)
+		assert.True(t, ok)
+		assert.Equal(t, v.offset, p.Offset, "minSize %d", v.minSize)
+	}
+	_, ok := SelectPreview(nil, 256)
+	assert.False(t, ok)
+}
+
+// cr3Box returns a box of typ with data.
+func cr3Box(typ string, data ...[]byte) []byte {
+	buf := make([]byte, 8)
+	copy(buf[4:], typ)
+	for _, d := range data {
+		buf = append(buf, d...)
+	}
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)))
+	return buf
+}
+
+func cr3Uint32(v ...uint32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i := range v {
+		binary.BigEndian.PutUint32(buf[i*4:], v[i])
+	}
+	return buf
+}
+
+// newCR3Previews returns a CR3 with a 6000x4000 JPEG in the first trak, a
+// 1620x1080 PRVW preview found with the CTBO box and a 160x120 THMB thumbnail.
+func newCR3Previews(full, prvw, thmb []byte) []byte {
+	metaUUID, _ := bmff.CR3MetaBoxUUID.MarshalBinary()
+	prvwUUID, _ := bmff.CR3PreviewDataUUID.MarshalBinary()
+	ftyp := cr3Box("ftyp", []byte("crx "), cr3Uint32(1), []byte("crx isom"))
+	prvwBox := cr3Box("uuid", prvwUUID, make([]byte, 8), cr3Box("PRVW", cr3Uint32(0), []byte{0, 1, 0x06, 0x54, 0x04, 0x38, 0, 1}, cr3Uint32(uint32(len(prvw))), prvw))
+	stsd := make([]byte, 88)
+	copy(stsd[4:], "CRAW")
+	binary.BigEndian.PutUint16(stsd[32:], 6000)
+	binary.BigEndian.PutUint16(stsd[34:], 4000)
+	build := func(prvwOffset, fullOffset uint32) []byte {
+		trak := cr3Box("trak", cr3Box("mdia", cr3Box("minf", cr3Box("stbl",
+			cr3Box("stsd", cr3Uint32(0, 1), stsd),
+			cr3Box("stsz", cr3Uint32(0, uint32(len(full)), 1)),
+			cr3Box("co64", cr3Uint32(0, 1, 0, fullOffset))))))
+		moov := cr3Box("moov",
+			cr3Box("uuid", metaUUID,
+				cr3Box("THMB", cr3Uint32(0), []byte{0, 160, 0, 120}, cr3Uint32(uint32(len(thmb)), 0), thmb),
+				cr3Box("CTBO", cr3Uint32(1, 2, 0, prvwOffset, 0, uint32(len(prvwBox))))),
+			trak)
+		buf := append(append([]byte{}, ftyp...), moov...)
+		return append(append(buf, prvwBox...), full...)
+	}
+	buf := build(0, 0)
+	return build(uint32(len(buf)-len(prvwBox)-len(full)), uint32(len(buf)-len(full)))
+}
+
+func TestSelectPreviewCR3(t *testing.T) {
+	full, prvw, thmb := []byte("full size jpeg"), []byte("prvw jpeg"), []byte("thmb jpeg")
+	buf := newCR3Previews(full, prvw, thmb)
+	previews, err := Previews(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, previews, 3) {
+		return
+	}
+	for _, v := range []struct {
+		minSize uint32
+		data    []byte
+	}{
+		{0, thmb},
+		{120, thmb},
+		{256, prvw},
+		{1080, prvw},
+		{2000, full},
+	} {
+		p, ok := SelectPreview(previews, v.minSize)
+		if assert.True(t, ok) {
+			jpg, err := io.ReadAll(PreviewReader(bytes.NewReader(buf), p))
+			assert.NoError(t, err)
+			assert.Equal(t, string(v.data), string(jpg), "minSize %d", v.minSize)
+		}
+	}
+}
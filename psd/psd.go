@@ -0,0 +1,372 @@
+// Package psd reads the header and the Image Resources of a Photoshop
+// Document (PSD) or a Photoshop Large Document (PSB) without decoding the image.
+//
+// The header has the dimensions, the number of channels, the bit depth and the color mode.
+// The Image Resources section has the IPTC-NAA record, the XMP packet, the Exif data,
+// the ICC profile and the JPEG thumbnail.
+package psd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif       = meta.ErrNoExif
+	ErrNoPSDHeader  = errors.New("no PSD Header")
+	ErrNoIPTC       = errors.New("no IPTC record")
+	ErrNoICCProfile = errors.New("no ICC profile")
+	ErrNoThumbnail  = errors.New("error PSD does not have a jpeg thumbnail")
+
+	// ErrCorruptResource is returned when an Image Resource Block is truncated.
+	ErrCorruptResource = errors.New("corrupt PSD image resource")
+)
+
+// psdByteOrder is the byte order of PSD and PSB files
+var psdByteOrder = binary.BigEndian
+
+// Signatures
+var (
+	psdSignature = []byte("8BPS")
+)
+
+// Versions
+const (
+	VersionPSD uint16 = 1
+	VersionPSB uint16 = 2
+)
+
+// Lengths
+const (
+	headerLength          = 26
+	thumbnailHeaderLength = 28
+
+	// thumbnailFormatJPEG is the kJpegRGB format of a thumbnail resource
+	thumbnailFormatJPEG = 1
+)
+
+// Image Resource IDs
+const (
+	resourceThumbnailPS4 uint16 = 0x0409 // Thumbnail (Photoshop 4.0, BGR)
+	resourceIPTC         uint16 = 0x0404 // IPTC-NAA record
+	resourceThumbnail    uint16 = 0x040C // Thumbnail (Photoshop 5.0)
+	resourceICCProfile   uint16 = 0x040F // ICC profile
+	resourceExif         uint16 = 0x0422 // Exif data 1
+	resourceXMP          uint16 = 0x0424 // XMP metadata
+)
+
+// ColorMode is the color mode of a Photoshop Document
+type ColorMode uint16
+
+// Color Modes
+const (
+	ColorModeBitmap       ColorMode = 0
+	ColorModeGrayscale    ColorMode = 1
+	ColorModeIndexed      ColorMode = 2
+	ColorModeRGB          ColorMode = 3
+	ColorModeCMYK         ColorMode = 4
+	ColorModeMultichannel ColorMode = 7
+	ColorModeDuotone      ColorMode = 8
+	ColorModeLab          ColorMode = 9
+)
+
+func (cm ColorMode) String() string {
+	switch cm {
+	case ColorModeBitmap:
+		return "Bitmap"
+	case ColorModeGrayscale:
+		return "Grayscale"
+	case ColorModeIndexed:
+		return "Indexed"
+	case ColorModeRGB:
+		return "RGB"
+	case ColorModeCMYK:
+		return "CMYK"
+	case ColorModeMultichannel:
+		return "Multichannel"
+	case ColorModeDuotone:
+		return "Duotone"
+	case ColorModeLab:
+		return "Lab"
+	}
+	return "Unknown"
+}
+
+// Header is the file header of a Photoshop Document
+type Header struct {
+	Version   uint16
+	Channels  uint16
+	Height    uint32
+	Width     uint32
+	Depth     uint16
+	ColorMode ColorMode
+}
+
+// IsPSB returns true if the header is a Photoshop Large Document header
+func (h Header) IsPSB() bool {
+	return h.Version == VersionPSB
+}
+
+// section is the offset and length of an Image Resource
+type section struct {
+	offset uint32
+	length uint32
+}
+
+// Metadata from a PSD or PSB file
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+	XmpHeader  meta.XmpHeader
+	Header     Header
+
+	// Decode Functions for EXIF and XMP metadata
+	exifFn func(r io.Reader, header meta.ExifHeader) error
+	xmpFn  func(r io.Reader, header meta.XmpHeader) error
+
+	iptc      section
+	icc       section
+	thumbnail section
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.Header.Width, m.Header.Height)
+}
+
+// ImageType returns imagetype.ImagePSD for PSD and PSB images
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImagePSD
+}
+
+// PreviewImage returns the JPEG thumbnail. Returns the PSD
+// file if it does not have a JPEG thumbnail.
+func (m Metadata) PreviewImage() io.Reader {
+	if r, err := m.Thumbnail(); err == nil {
+		return r
+	}
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Thumbnail returns the JPEG thumbnail of the thumbnail resource.
+//
+// Returns ErrNoThumbnail if the PSD does not have a JPEG thumbnail.
+func (m Metadata) Thumbnail() (io.Reader, error) {
+	if m.thumbnail.length == 0 {
+		return nil, ErrNoThumbnail
+	}
+	return io.NewSectionReader(m.mr, int64(m.thumbnail.offset), int64(m.thumbnail.length)), nil
+}
+
+// Exif returns parsed Exif data from PSD
+func (m Metadata) Exif() (exif.Exif, error) {
+	if !m.ExifHeader.IsValid() {
+		return nil, ErrNoExif
+	}
+	return exif.ParseExif(m.mr, m.ExifHeader)
+}
+
+// Xmp returns parsed Xmp data from PSD
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	if m.XmpHeader.Length == 0 {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+// IPTC returns the decoded IPTC-NAA record.
+//
+// Returns ErrNoIPTC if the PSD does not have an IPTC-NAA record.
+func (m Metadata) IPTC() (iptc.IPTC, error) {
+	buf, err := m.readSection(m.iptc, ErrNoIPTC)
+	if err != nil {
+		return iptc.IPTC{}, err
+	}
+	return iptc.Decode(buf)
+}
+
+// ICCProfile returns the ICC profile.
+//
+// Returns ErrNoICCProfile if the PSD does not have an ICC profile.
+func (m Metadata) ICCProfile() ([]byte, error) {
+	return m.readSection(m.icc, ErrNoICCProfile)
+}
+
+// readSection returns the data of s, or errNotFound if s is empty.
+func (m Metadata) readSection(s section, errNotFound error) ([]byte, error) {
+	if s.length == 0 {
+		return nil, errNotFound
+	}
+	buf := make([]byte, s.length)
+	if _, err := m.mr.ReadAt(buf, int64(s.offset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// ScanPSD reads the header and the Image Resources of a PSD or PSB from mr. xmpFn and
+// exifFn are run at their respective positions during the scan. Returns Metadata.
+//
+// Returns the error ErrNoPSDHeader if mr is not a PSD or PSB and ErrCorruptResource if
+// an Image Resource Block is truncated.
+func ScanPSD(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = Metadata{mr: mr, exifFn: exifFn, xmpFn: xmpFn}
+
+	var buf [headerLength]byte
+	if n, _ := mr.ReadAt(buf[:], 0); n < headerLength || !bytes.Equal(buf[:4], psdSignature) {
+		return m, ErrNoPSDHeader
+	}
+	m.Header = Header{
+		Version:   psdByteOrder.Uint16(buf[4:6]),
+		Channels:  psdByteOrder.Uint16(buf[12:14]),
+		Height:    psdByteOrder.Uint32(buf[14:18]),
+		Width:     psdByteOrder.Uint32(buf[18:22]),
+		Depth:     psdByteOrder.Uint16(buf[22:24]),
+		ColorMode: ColorMode(psdByteOrder.Uint16(buf[24:26])),
+	}
+	if m.Header.Version != VersionPSD && m.Header.Version != VersionPSB {
+		return m, ErrNoPSDHeader
+	}
+
+	// The Color Mode Data section is followed by the Image Resources section.
+	// Both sections have a 4 byte length in PSD and PSB files.
+	offset := int64(headerLength)
+	length, err := m.readUint32(offset)
+	if err != nil {
+		return m, err
+	}
+	offset += 4 + int64(length)
+	if length, err = m.readUint32(offset); err != nil {
+		return m, err
+	}
+	offset += 4
+	return m, m.readResources(offset, offset+int64(length))
+}
+
+// readUint32 reads a big endian uint32 at offset.
+func (m *Metadata) readUint32(offset int64) (uint32, error) {
+	var buf [4]byte
+	if _, err := m.mr.ReadAt(buf[:], offset); err != nil {
+		return 0, ErrCorruptResource
+	}
+	return psdByteOrder.Uint32(buf[:]), nil
+}
+
+// readResources reads the Image Resource Blocks from offset to end.
+//
+// Image Resource Block: signature, resource ID, name as a Pascal string padded
+// to an even length, data length and data padded to an even length.
+func (m *Metadata) readResources(offset, end int64) (err error) {
+	// Block header with the longest resource name
+	buf := make([]byte, 4+2+256+4)
+	for offset < end {
+		n, _ := m.mr.ReadAt(buf, offset)
+		if n < 4+2+2+4 || !meta.IsImageResourceSignature(buf[:4]) {
+			return ErrCorruptResource
+		}
+		id := psdByteOrder.Uint16(buf[4:6])
+		nameLength := 1 + int(buf[6])
+		nameLength += nameLength % 2
+		if n < 6+nameLength+4 {
+			return ErrCorruptResource
+		}
+		s := section{
+			offset: uint32(offset) + 6 + uint32(nameLength) + 4,
+			length: psdByteOrder.Uint32(buf[6+nameLength:]),
+		}
+		if int64(s.offset)+int64(s.length) > end {
+			return ErrCorruptResource
+		}
+		if err = m.readResource(id, s); err != nil {
+			return err
+		}
+		offset = int64(s.offset) + int64(s.length) + int64(s.length%2)
+	}
+	return nil
+}
+
+// readResource records the location of the Image Resource id with data s.
+func (m *Metadata) readResource(id uint16, s section) error {
+	if s.length == 0 {
+		return nil
+	}
+	switch id {
+	case resourceIPTC:
+		m.iptc = s
+	case resourceICCProfile:
+		m.icc = s
+	case resourceThumbnail, resourceThumbnailPS4:
+		// The Photoshop 5.0 thumbnail is preferred
+		if m.thumbnail.length == 0 || id == resourceThumbnail {
+			return m.readThumbnail(s)
+		}
+	case resourceExif:
+		return m.readExif(s)
+	case resourceXMP:
+		m.XmpHeader = meta.NewXMPHeader(s.offset, s.length)
+		if m.xmpFn != nil {
+			return m.xmpFn(io.NewSectionReader(m.mr, int64(s.offset), int64(s.length)), m.XmpHeader)
+		}
+	}
+	return nil
+}
+
+// readThumbnail reads the thumbnail resource header.
+//
+// Thumbnail resource: format, width, height, widthbytes, total size,
+// compressed size, bits per pixel and number of planes followed by the JFIF data.
+func (m *Metadata) readThumbnail(s section) error {
+	if s.length < thumbnailHeaderLength {
+		return nil
+	}
+	var buf [thumbnailHeaderLength]byte
+	if _, err := m.mr.ReadAt(buf[:], int64(s.offset)); err != nil {
+		return ErrCorruptResource
+	}
+	if psdByteOrder.Uint32(buf[0:4]) != thumbnailFormatJPEG {
+		return nil
+	}
+	length := psdByteOrder.Uint32(buf[20:24])
+	if length == 0 || length > s.length-thumbnailHeaderLength {
+		length = s.length - thumbnailHeaderLength
+	}
+	m.thumbnail = section{offset: s.offset + thumbnailHeaderLength, length: length}
+	return nil
+}
+
+// readExif reads the Tiff header of the Exif data resource with the attached metadata exifFn.
+func (m *Metadata) readExif(s section) error {
+	if s.length < 8 {
+		return nil
+	}
+	var buf [8]byte
+	if _, err := m.mr.ReadAt(buf[:], int64(s.offset)); err != nil {
+		return ErrCorruptResource
+	}
+
+	// Create a TiffHeader from the Tiff directory ByteOrder, root IFD Offset,
+	// the tiff Header Offset, and the length of the exif information.
+	byteOrder := meta.BinaryOrder(buf[:])
+	if byteOrder == nil {
+		return nil
+	}
+	firstIfdOffset := byteOrder.Uint32(buf[4:8])
+	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, s.offset, s.length, imagetype.ImagePSD)
+
+	// Read Exif
+	if m.exifFn != nil {
+		return m.exifFn(io.NewSectionReader(m.mr, int64(s.offset), int64(s.length)), m.ExifHeader)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package psd
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+func newResource(id uint16, name string, data []byte) []byte {
+	buf := append([]byte("8BIM"), byte(id>>8), byte(id), byte(len(name)))
+	buf = append(buf, name...)
+	if len(buf)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+	buf = append(append(buf, size[:]...), data...)
+	if len(data)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func newThumbnail(jpeg []byte) []byte {
+	buf := make([]byte, thumbnailHeaderLength)
+	binary.BigEndian.PutUint32(buf[0:], thumbnailFormatJPEG)
+	binary.BigEndian.PutUint32(buf[4:], 160)
+	binary.BigEndian.PutUint32(buf[8:], 120)
+	binary.BigEndian.PutUint32(buf[12:], 480)
+	binary.BigEndian.PutUint32(buf[16:], 480*120)
+	binary.BigEndian.PutUint32(buf[20:], uint32(len(jpeg)))
+	binary.BigEndian.PutUint16(buf[24:], 24)
+	binary.BigEndian.PutUint16(buf[26:], 1)
+	return append(buf, jpeg...)
+}
+
+func newPSD(version uint16, width, height uint32, colorModeData []byte, resources ...[]byte) []byte {
+	buf := make([]byte, headerLength)
+	copy(buf, psdSignature)
+	binary.BigEndian.PutUint16(buf[4:], version)
+	binary.BigEndian.PutUint16(buf[12:], 3)
+	binary.BigEndian.PutUint32(buf[14:], height)
+	binary.BigEndian.PutUint32(buf[18:], width)
+	binary.BigEndian.PutUint16(buf[22:], 16)
+	binary.BigEndian.PutUint16(buf[24:], uint16(ColorModeRGB))
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(colorModeData)))
+	buf = append(append(buf, size[:]...), colorModeData...)
+	var irbs []byte
+	for _, r := range resources {
+		irbs = append(irbs, r...)
+	}
+	binary.BigEndian.PutUint32(size[:], uint32(len(irbs)))
+	buf = append(append(buf, size[:]...), irbs...)
+	// Empty Layer and Mask Information section and image data
+	return append(buf, make([]byte, 16)...)
+}
+
+func TestScanPSD(t *testing.T) {
+	b := exif.NewBuilder(nil)
+	if err := b.SetASCII(ifds.IFD0, 0, ifds.Model, "Canon EOS R5"); err != nil {
+		t.Fatal(err)
+	}
+	e, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, err := xmp.Marshal(xmp.XMP{Basic: xmp.Basic{Rating: 4, Label: "Red"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := iptc.IPTC{Caption: "A caption", Keywords: []string{"news"}}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	icc := []byte("icc profile")
+	thumbnail := []byte{0xFF, 0xD8, 0xFF, 0xD9}
+	resources := [][]byte{
+		newResource(0x03ED, "", make([]byte, 16)),
+		newResource(resourceIPTC, "", record),
+		newResource(resourceThumbnailPS4, "", newThumbnail([]byte{0xFF, 0xD8, 0x00, 0xFF, 0xD9})),
+		newResource(resourceThumbnail, "thumb", newThumbnail(thumbnail)),
+		newResource(resourceICCProfile, "", icc),
+		newResource(resourceExif, "", e),
+		newResource(resourceXMP, "", packet),
+	}
+
+	tests := []struct {
+		name    string
+		version uint16
+	}{
+		{"psd", VersionPSD},
+		{"psb", VersionPSB},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := newPSD(test.version, 4000, 3000, []byte{1, 2, 3}, resources...)
+			if it, err := imagetype.ReadAt(bytes.NewReader(buf)); err != nil || it != imagetype.ImagePSD {
+				t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImagePSD, it, err)
+			}
+			var exifCalled, xmpCalled bool
+			m, err := ScanPSD(bytes.NewReader(buf), func(r io.Reader, header meta.ExifHeader) error {
+				exifCalled = true
+				return nil
+			}, func(r io.Reader, header meta.XmpHeader) error {
+				xmpCalled = true
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !exifCalled || !xmpCalled {
+				t.Errorf("Incorrect decode functions called exif %t xmp %t", exifCalled, xmpCalled)
+			}
+			if m.Dimensions() != meta.NewDimensions(4000, 3000) {
+				t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 4000, 3000, m.Dimensions())
+			}
+			if m.Header.IsPSB() != (test.version == VersionPSB) || m.Header.Channels != 3 || m.Header.Depth != 16 || m.Header.ColorMode != ColorModeRGB {
+				t.Errorf("Incorrect Header got %+v", m.Header)
+			}
+
+			x, err := m.Xmp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if x.Basic.Rating != 4 || x.Basic.Label != "Red" {
+				t.Errorf("Incorrect Xmp got %+v", x.Basic)
+			}
+			ex, err := m.Exif()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ex.CameraModel() != "Canon EOS R5" {
+				t.Errorf("Incorrect CameraModel wanted %s got %s", "Canon EOS R5", ex.CameraModel())
+			}
+			i, err := m.IPTC()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.Caption != "A caption" || len(i.Keywords) != 1 || i.Keywords[0] != "news" {
+				t.Errorf("Incorrect IPTC got %+v", i)
+			}
+			if profile, err := m.ICCProfile(); err != nil || !bytes.Equal(profile, icc) {
+				t.Errorf("Incorrect ICCProfile wanted %q got %q (%v)", icc, profile, err)
+			}
+			if preview, err := io.ReadAll(m.PreviewImage()); err != nil || !bytes.Equal(preview, thumbnail) {
+				t.Errorf("Incorrect PreviewImage wanted %x got %x (%v)", thumbnail, preview, err)
+			}
+		})
+	}
+}
+
+func TestScanPSDNoResources(t *testing.T) {
+	buf := newPSD(VersionPSD, 64, 32, nil)
+	m, err := ScanPSD(bytes.NewReader(buf), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Dimensions() != meta.NewDimensions(64, 32) {
+		t.Errorf("Incorrect Dimensions wanted %dx%d got %s", 64, 32, m.Dimensions())
+	}
+	if _, err = m.Exif(); err != ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+	if _, err = m.Xmp(); err != xmp.ErrNoXMP {
+		t.Errorf("Incorrect error wanted %v got %v", xmp.ErrNoXMP, err)
+	}
+	if _, err = m.IPTC(); err != ErrNoIPTC {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoIPTC, err)
+	}
+	if _, err = m.ICCProfile(); err != ErrNoICCProfile {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoICCProfile, err)
+	}
+	if _, err = m.Thumbnail(); err != ErrNoThumbnail {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoThumbnail, err)
+	}
+}
+
+func TestScanPSDErrors(t *testing.T) {
+	if _, err := ScanPSD(bytes.NewReader(make([]byte, 64)), nil, nil); err != ErrNoPSDHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoPSDHeader, err)
+	}
+	// Unknown version
+	if _, err := ScanPSD(bytes.NewReader(newPSD(3, 1, 1, nil)), nil, nil); err != ErrNoPSDHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoPSDHeader, err)
+	}
+	// Truncated Image Resource Block
+	buf := newPSD(VersionPSD, 1, 1, nil, newResource(resourceIPTC, "", make([]byte, 8)))
+	binary.BigEndian.PutUint32(buf[headerLength+4+4+8:], 64)
+	if _, err := ScanPSD(bytes.NewReader(buf), nil, nil); err != ErrCorruptResource {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptResource, err)
+	}
+	// Invalid signature
+	buf = newPSD(VersionPSD, 1, 1, nil, newResource(resourceIPTC, "", make([]byte, 8)))
+	copy(buf[headerLength+4+4:], "XXXX")
+	if _, err := ScanPSD(bytes.NewReader(buf), nil, nil); err != ErrCorruptResource {
+		t.Errorf("Incorrect error wanted %v got %v", ErrCorruptResource, err)
+	}
+}
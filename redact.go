@@ -0,0 +1,107 @@
+package imagemeta
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// redactedTags are the Exif tags with personal data removed by Redact.
+var redactedTags = []struct {
+	ifd ifds.IfdType
+	id  tag.ID
+}{
+	{ifds.IFD0, ifds.CameraSerialNumber},
+	{ifds.ExifIFD, exififd.BodySerialNumber},
+	{ifds.ExifIFD, exififd.LensSerialNumber},
+	{ifds.ExifIFD, exififd.CameraOwnerName},
+	{ifds.ExifIFD, exififd.ImageUniqueID},
+	{ifds.ExifIFD, exififd.MakerNote},
+}
+
+// Redact copies the JPEG from r to w without the personal data in its Exif and XMP,
+// so that shared images keep their camera data. The GPS IFD, the serial numbers,
+// CameraOwnerName and ImageUniqueID are removed from the Exif. The MakerNote is
+// removed as MakerNotes have camera serial numbers. The other Exif tags are kept.
+// The XMP and Extended XMP are redacted with xmp.Redact. The data after the EOI
+// marker of the image, ie. the images of a Multi-Picture Format file and the video
+// of a motion photo, is removed with the APP2 MPF segment, as it has its own Exif,
+// XMP and GPS data.
+//
+// The Exif is encoded again with exif.NewBuilderFromData. The Exif and XMP are
+// only written again when they have personal data, the rest of the JPEG is copied
+// unchanged. Returns ErrMetadataNotSupported if r is not a JPEG.
+func Redact(r meta.Reader, w io.Writer) error {
+	t, err := imagetype.ReadAt(r)
+	if err != nil {
+		return err
+	}
+	if t != imagetype.ImageJPEG {
+		return ErrMetadataNotSupported
+	}
+	m, err := jpeg.ScanJPEG(r, nil, nil)
+	if err != nil && !errors.Is(err, ErrNoExif) {
+		return err
+	}
+
+	opts := jpeg.RewriteOptions{RemoveTrailers: true}
+	if err == nil {
+		e, err := exif.ParseExif(r, m.ExifHeader)
+		if err != nil {
+			return err
+		}
+		b, err := exif.NewBuilderFromData(e)
+		if err != nil {
+			return err
+		}
+		if redactExif(b) {
+			if opts.Exif, err = b.Encode(); err != nil {
+				return err
+			}
+		}
+	}
+	if m.XmpHeader.Length > 0 {
+		packet, err := readXMPPacket(r, m.XmpHeader)
+		if err != nil {
+			return err
+		}
+		redacted := xmp.Redact(packet)
+		if guid, ext, err := m.ExtendedXmpPacket(); err == nil {
+			// The main packet refers to the redacted Extended XMP packet with its new GUID
+			opts.ExtendedXMP = xmp.Redact(ext)
+			if !bytes.Equal(opts.ExtendedXMP, ext) {
+				redacted = bytes.ReplaceAll(redacted, []byte(guid), []byte(jpeg.ExtendedXMPGUID(opts.ExtendedXMP)))
+			}
+		}
+		if !bytes.Equal(redacted, packet) {
+			opts.XMP = redacted
+		}
+	}
+	return jpeg.Rewrite(io.NewSectionReader(r, 0, math.MaxInt64), w, opts)
+}
+
+// redactExif removes the tags with personal data from b.
+// Returns true if b had tags with personal data.
+func redactExif(b *exif.Builder) (redacted bool) {
+	for _, t := range redactedTags {
+		if b.HasTag(t.ifd, 0, t.id) {
+			b.RemoveTag(t.ifd, 0, t.id)
+			redacted = true
+		}
+	}
+	if b.HasIfd(ifds.GPSIFD, 0) {
+		b.RemoveIfd(ifds.GPSIFD, 0)
+		redacted = true
+	}
+	return redacted
+}
@@ -0,0 +1,201 @@
+package imagemeta
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An image with personal data in its Exif and XMP
+	var private bytes.Buffer
+	err = rewriteExif(bytes.NewReader(buf), &private, func(b *exif.Builder) error {
+		for _, err := range []error{
+			b.SetGPSCoords(-33.8568, 151.2153),
+			b.SetASCII(ifds.IFD0, 0, ifds.CameraSerialNumber, "C3221"),
+			b.SetASCII(ifds.ExifIFD, 0, exififd.BodySerialNumber, "2031614"),
+			b.SetASCII(ifds.ExifIFD, 0, exififd.CameraOwnerName, "Owner"),
+			b.SetASCII(ifds.ExifIFD, 0, exififd.ImageUniqueID, "0123456789abcdef0123456789abcdef"),
+		} {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := xmp.XMP{
+		Aux:  xmp.Aux{SerialNumber: "2031614", Lens: "EF50mm f/1.2L USM"},
+		Exif: xmp.Exif{GPSLatitude: -33.8568, GPSLongitude: 151.2153},
+		DC:   xmp.DublinCore{Creator: []string{"Evan Oberholster"}},
+	}
+	packet, err := xmp.Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var src bytes.Buffer
+	if err = jpeg.Rewrite(bytes.NewReader(private.Bytes()), &src, jpeg.RewriteOptions{XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+
+	if e, err := parseJPEGExif(src.Bytes()); assert.NoError(t, err) {
+		_, err = e.GetTag(ifds.ExifIFD, 0, exififd.BodySerialNumber)
+		assert.NoError(t, err)
+	}
+
+	var out bytes.Buffer
+	if err = Redact(bytes.NewReader(src.Bytes()), &out); err != nil {
+		t.Fatal(err)
+	}
+	redacted := append([]byte(nil), out.Bytes()...)
+	e, err := parseJPEGExif(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = e.GPSCoords()
+	assert.Error(t, err)
+	for _, k := range []struct {
+		ifd ifds.IfdType
+		id  tag.ID
+	}{
+		{ifds.IFD0, ifds.CameraSerialNumber},
+		{ifds.ExifIFD, exififd.BodySerialNumber},
+		{ifds.ExifIFD, exififd.CameraOwnerName},
+		{ifds.ExifIFD, exififd.ImageUniqueID},
+	} {
+		_, err = e.GetTag(k.ifd, 0, k.id)
+		assert.ErrorIs(t, err, exif.ErrEmptyTag, "%s %s", k.ifd, k.id)
+	}
+
+	// The camera data is kept
+	want, err := parseJPEGExif(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, want.CameraMake(), e.CameraMake())
+	assert.Equal(t, want.CameraModel(), e.CameraModel())
+	wantISO, _ := want.ISOSpeed()
+	gotISO, err := e.ISOSpeed()
+	assert.NoError(t, err)
+	assert.Equal(t, wantISO, gotISO)
+	wantTime, _ := want.DateTime(nil)
+	gotTime, err := e.DateTime(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, wantTime, gotTime)
+
+	m, err := jpeg.ScanJPEG(bytes.NewReader(redacted), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x2, err := m.Xmp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, xmp.Aux{Lens: x.Aux.Lens}, x2.Aux)
+	assert.Equal(t, xmp.Exif{}, x2.Exif)
+	assert.Equal(t, x.DC.Creator, x2.DC.Creator)
+
+	// An image without personal data is copied unchanged
+	out.Reset()
+	if err = Redact(bytes.NewReader(redacted), &out); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.Equal(redacted, out.Bytes()), "Redact without personal data should copy the image unchanged")
+
+	// Exif is not created
+	var stripped bytes.Buffer
+	if err = jpeg.Strip(bytes.NewReader(buf), &stripped, jpeg.StripOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err = Redact(bytes.NewReader(stripped.Bytes()), &out); assert.NoError(t, err) {
+		assert.True(t, bytes.Equal(stripped.Bytes(), out.Bytes()), "Redact should copy an image without Exif unchanged")
+	}
+
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, Redact(f, &out), ErrMetadataNotSupported)
+}
+
+func TestRedactTrailers(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The appended image of a Multi-Picture Format file has its own GPS data
+	var private bytes.Buffer
+	if err = rewriteExif(bytes.NewReader(buf), &private, func(b *exif.Builder) error {
+		return b.SetGPSCoords(-33.8568, 151.2153)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var want bytes.Buffer
+	if err = Redact(bytes.NewReader(buf), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	src := append(append([]byte{}, buf...), private.Bytes()...)
+	var out bytes.Buffer
+	if err = Redact(bytes.NewReader(src), &out); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.Equal(want.Bytes(), out.Bytes()), "Redact should remove the appended image")
+	trailers, err := jpeg.Trailers(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	assert.NoError(t, err)
+	assert.Empty(t, trailers)
+}
+
+func TestRedactExtendedXMP(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The main packet does not have personal data
+	extended := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:tiff="http://ns.adobe.com/tiff/1.0/" xmlns:exif="http://ns.adobe.com/exif/1.0/" tiff:Model="Canon EOS 7D" exif:GPSLatitude="33,51.408S" exif:GPSLongitude="151,12.918E"/></rdf:RDF></x:xmpmeta>`)
+	guid := jpeg.ExtendedXMPGUID(extended)
+	main := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"><rdf:Description rdf:about="" xmlns:tiff="http://ns.adobe.com/tiff/1.0/" xmlns:xmpNote="http://ns.adobe.com/xmp/note/" tiff:Make="Canon" xmpNote:HasExtendedXMP="` + guid + `"/></rdf:RDF></x:xmpmeta>`)
+	var src bytes.Buffer
+	if err = jpeg.Rewrite(bytes.NewReader(buf), &src, jpeg.RewriteOptions{XMP: main, ExtendedXMP: extended}); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err = Redact(bytes.NewReader(src.Bytes()), &out); err != nil {
+		t.Fatal(err)
+	}
+	m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, packet, err := m.ExtendedXmpPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, string(packet), "exif:GPS")
+	assert.Equal(t, jpeg.ExtendedXMPGUID(packet), g)
+	x, err := m.ExtendedXmp()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Canon EOS 7D", x.Tiff.Model)
+	}
+	x, err = m.Xmp()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Canon", x.Tiff.Make)
+	}
+}
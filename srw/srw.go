@@ -0,0 +1,122 @@
+// Package srw decodes (SRW) Samsung Raw Metadata. SRW is a Tiff based format
+// that is identified by the Samsung Makernote of its Exif.
+package srw
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoSRWHeader     = errors.New("no SRW Header")
+	ErrNoSamsungMkNote = errors.New("error SRW does not have a Samsung makernote")
+	ErrNoPreview       = exif.ErrNoPreview
+)
+
+// headerLength is the length of the Tiff header
+const headerLength = 8
+
+// Metadata is a SRW file's Metadata
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+
+	e *exif.Data
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return m.e.Dimensions()
+}
+
+// ImageType returns imagetype.ImageSRW for Samsung SRW image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageSRW
+}
+
+// PreviewImage returns the largest JPEG preview image. Returns the
+// SRW file if it does not have a JPEG preview.
+func (m Metadata) PreviewImage() io.Reader {
+	if p, err := m.LargestPreview(); err == nil {
+		return io.NewSectionReader(m.mr, int64(m.ExifHeader.TiffHeaderOffset+p.Offset), int64(p.Length))
+	}
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns parsed Exif data from SRW
+func (m Metadata) Exif() (exif.Exif, error) {
+	return m.e, nil
+}
+
+// Xmp returns parsed Xmp data from the "IFD" XMLPacket of a SRW.
+// Returns xmp.ErrNoXMP if the SRW does not have XMP metadata.
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	t, err := m.e.GetTag(ifds.IFD0, 0, ifds.XMLPacket)
+	if err != nil {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	buf, err := m.e.RawTagBytes(t)
+	if err != nil {
+		return xmp.XMP{}, err
+	}
+	return xmp.ParseXmp(bytes.NewReader(buf))
+}
+
+// Previews returns the JPEG previews of the IFD0 chain and the SubIFDs.
+func (m Metadata) Previews() []exif.Preview {
+	return m.e.Previews()
+}
+
+// LargestPreview returns the JPEG preview with the largest dimensions.
+//
+// Returns ErrNoPreview if the SRW does not have a JPEG preview.
+func (m Metadata) LargestPreview() (p exif.Preview, err error) {
+	previews := m.Previews()
+	if len(previews) == 0 {
+		return p, ErrNoPreview
+	}
+	p = previews[0]
+	for _, pp := range previews[1:] {
+		if uint64(pp.Width)*uint64(pp.Height) > uint64(p.Width)*uint64(p.Height) {
+			p = pp
+		}
+	}
+	return p, nil
+}
+
+// Parse reads the Tiff Header and the Exif metadata of a SRW from mr. Returns Metadata.
+//
+// Returns the error ErrNoSRWHeader if mr does not begin with a Tiff Header, and
+// ErrNoSamsungMkNote if the Exif does not have a Samsung Makernote.
+func Parse(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if _, err = mr.ReadAt(buf[:], 0); err != nil {
+		return m, ErrNoSRWHeader
+	}
+	byteOrder := meta.BinaryOrder(buf[:])
+	if byteOrder == nil {
+		return m, ErrNoSRWHeader
+	}
+	m.ExifHeader = meta.NewExifHeader(byteOrder, byteOrder.Uint32(buf[4:8]), 0, 0, imagetype.ImageTiff)
+
+	if m.e, err = exif.ParseExif(mr, m.ExifHeader); err != nil {
+		return m, err
+	}
+	// The imagetype is updated by the Samsung Makernote
+	if m.e.ImageType() != imagetype.ImageSRW {
+		return m, ErrNoSamsungMkNote
+	}
+	m.ExifHeader.ImageType = imagetype.ImageSRW
+	return m, nil
+}
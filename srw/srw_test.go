@@ -0,0 +1,113 @@
+package srw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+var (
+	testThumbnail = []byte{0xFF, 0xD8, 0xFF, 0xDB, 0x00, 0x00, 0xFF, 0xD9}
+	testSerial    = "SN123456789"
+)
+
+// newSRW returns a SRW with a Samsung Type2 Makernote. The SerialNumber follows the
+// Makernote Ifd, its offset is relative to the Makernote or to the Tiff Header.
+func newSRW(t *testing.T, cameraMake string, relative bool) []byte {
+	t.Helper()
+	bo := binary.LittleEndian
+	entry := func(id, t uint16, count uint32, value []byte) []byte {
+		b := make([]byte, 8, 12)
+		bo.PutUint16(b[0:], id)
+		bo.PutUint16(b[2:], t)
+		bo.PutUint32(b[4:], count)
+		return append(b, value...)
+	}
+	// SerialNumber at 2+2*12+4 = 30 from the start of the Makernote
+	serialOffset := make([]byte, 4)
+	bo.PutUint32(serialOffset, 30)
+	mkNote := []byte{2, 0}
+	mkNote = append(mkNote, entry(0x0001, 7, 4, []byte("0100"))...)
+	mkNote = append(mkNote, entry(0xa002, 2, uint32(len(testSerial)+1), serialOffset)...)
+	mkNote = append(mkNote, 0, 0, 0, 0)
+	mkNote = append(mkNote, testSerial...)
+	mkNote = append(mkNote, 0)
+
+	b := exif.NewBuilder(bo)
+	for _, err := range []error{
+		b.SetCamera(cameraMake, "NX1"),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 6592),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 4366),
+		b.SetUndefined(ifds.ExifIFD, 0, exififd.MakerNote, mkNote),
+		b.SetLong(ifds.IFD0, 1, ifds.ImageWidth, 160),
+		b.SetLong(ifds.IFD0, 1, ifds.ImageLength, 120),
+		b.SetThumbnail(testThumbnail),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !relative {
+		start := bytes.Index(buf, mkNote[:14])
+		if start < 0 {
+			t.Fatal("Makernote not found")
+		}
+		bo.PutUint32(buf[start+2+12+8:], uint32(start+30))
+	}
+	return buf
+}
+
+func TestParse(t *testing.T) {
+	for _, relative := range []bool{true, false} {
+		buf := newSRW(t, "SAMSUNG", relative)
+		m, err := Parse(bytes.NewReader(buf))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ImageType() != imagetype.ImageSRW || m.Dimensions() != meta.NewDimensions(6592, 4366) {
+			t.Errorf("Incorrect Metadata got %s %s", m.ImageType(), m.Dimensions())
+		}
+		e, err := m.Exif()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if serial, err := e.SamsungSerialNumber(); err != nil || serial != testSerial {
+			t.Errorf("Incorrect SamsungSerialNumber (relative %t) wanted %s got %s (%v)", relative, testSerial, serial, err)
+		}
+		p, err := m.LargestPreview()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Width != 160 || p.Height != 120 {
+			t.Errorf("Incorrect Preview got %+v", p)
+		}
+		preview, err := io.ReadAll(m.PreviewImage())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(preview, testThumbnail) {
+			t.Errorf("Incorrect PreviewImage wanted %x got %x", testThumbnail, preview)
+		}
+	}
+
+	// Tiff without a Samsung Makernote
+	if _, err := Parse(bytes.NewReader(newSRW(t, "Canon", true))); err != ErrNoSamsungMkNote {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoSamsungMkNote, err)
+	}
+
+	// ORF Header
+	if _, err := Parse(bytes.NewReader([]byte{'I', 'I', 'R', 'O', 0x08, 0x00, 0x00, 0x00})); err != ErrNoSRWHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoSRWHeader, err)
+	}
+}
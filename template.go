@@ -0,0 +1,212 @@
+package imagemeta
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+// MergePolicy is how ApplyTemplate merges the values of a Template with the metadata of an image.
+type MergePolicy uint8
+
+// Merge Policies
+const (
+	// Overwrite replaces the values of the image with the values of the Template.
+	Overwrite MergePolicy = iota
+
+	// FillMissing only sets the values of the Template that the image does not have.
+	FillMissing
+)
+
+// Template is the rights and contact metadata applied to images by ApplyTemplate.
+// Empty values are not applied.
+type Template struct {
+	Artist       string          // Exif Artist, XMP dc:creator and IPTC By-line
+	Copyright    string          // Exif Copyright, XMP dc:rights and IPTC Copyright Notice
+	Contact      xmp.ContactInfo // XMP Iptc4xmpCore:CreatorContactInfo and IPTC Contact
+	UsageTerms   string          // XMP xmpRights:UsageTerms
+	WebStatement string          // XMP xmpRights:WebStatement
+}
+
+// ApplyTemplate copies the JPEG from r to w with the values of t set in its Exif,
+// XMP and IPTC as merged by policy. xmpRights:Marked is set with the Copyright.
+// The Exif, XMP and IPTC are created if the JPEG does not have them.
+//
+// The Exif, XMP and IPTC are only written again when a value is set. The Exif is
+// encoded again with exif.NewBuilderFromData. Only the changed properties of the
+// XMP packet are set with xmp.SetProperties, and the IPTC is merged into the
+// existing record with iptc.Merge, so that the properties and DataSets that are
+// not supported by packages xmp and iptc are kept. The Extended XMP is kept.
+//
+// Returns ErrMetadataNotSupported if r is not a JPEG.
+func ApplyTemplate(r meta.Reader, w io.Writer, t Template, policy MergePolicy) error {
+	b, err := jpegExifBuilder(r)
+	if err != nil {
+		return err
+	}
+	m, err := jpeg.ScanJPEG(r, nil, nil)
+	if err != nil && !errors.Is(err, ErrNoExif) {
+		return err
+	}
+	var x xmp.XMP
+	var packet []byte
+	if m.XmpHeader.Length > 0 {
+		if x, err = m.Xmp(); err != nil && err != io.EOF {
+			return err
+		}
+		if packet, err = readXMPPacket(r, m.XmpHeader); err != nil {
+			return err
+		}
+	}
+	sr := io.NewSectionReader(r, 0, math.MaxInt64)
+	data, err := jpeg.ReadIPTC(sr)
+	if err != nil && err != jpeg.ErrNoIPTC {
+		return err
+	}
+	i, err := iptc.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	tm := templateMerge{policy: policy}
+	var opts jpeg.RewriteOptions
+	if tm.exif(b, t) {
+		if opts.Exif, err = b.Encode(); err != nil {
+			return err
+		}
+	}
+	if props := tm.xmp(&x, t); len(props) > 0 {
+		if opts.XMP, err = xmp.SetProperties(packet, x, props...); err != nil {
+			return err
+		}
+		// The Extended XMP segments are replaced with the XMP
+		if _, ext, err := m.ExtendedXmpPacket(); err == nil {
+			opts.ExtendedXMP = ext
+		}
+	}
+	if tm.iptc(&i, t) {
+		if opts.IPTC, err = iptc.Merge(data, i); err != nil {
+			return err
+		}
+	}
+	if _, err = sr.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return jpeg.Rewrite(sr, w, opts)
+}
+
+// ApplyTemplateFiles applies t to the JPEG files at paths with ApplyTemplate with at
+// most workers files open at the same time, and sends a Result with the Path and Err
+// of each file on the returned channel. Each file is replaced after the new file is
+// written completely. A file that returns an error is not changed and does not stop the batch.
+//
+// The channel is closed after all files have been written or ctx is cancelled.
+// Files that have not been started when ctx is cancelled are skipped.
+func ApplyTemplateFiles(ctx context.Context, paths []string, t Template, policy MergePolicy, workers int) <-chan Result {
+	return processFiles(ctx, paths, workers, func(path string) Result {
+		return Result{Path: path, Err: rewriteFile(path, func(r meta.Reader, w io.Writer) error {
+			return ApplyTemplate(r, w, t, policy)
+		})}
+	})
+}
+
+// templateMerge sets the values of a Template by its policy.
+// The methods return true if a value was changed.
+type templateMerge struct {
+	policy MergePolicy
+}
+
+// string sets dst to value if value is not empty and dst is empty or the policy is Overwrite.
+func (tm templateMerge) string(dst *string, value string) bool {
+	if value == "" || value == *dst || (tm.policy == FillMissing && *dst != "") {
+		return false
+	}
+	*dst = value
+	return true
+}
+
+// strings sets dst to value like string.
+func (tm templateMerge) strings(dst *[]string, value ...string) bool {
+	var values []string
+	for _, v := range value {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 || equalStrings(*dst, values) || (tm.policy == FillMissing && len(*dst) > 0) {
+		return false
+	}
+	*dst = values
+	return true
+}
+
+func (tm templateMerge) exif(b *exif.Builder, t Template) (changed bool) {
+	for _, v := range []struct {
+		id    tag.ID
+		value string
+		set   func(string) error
+	}{
+		{ifds.Artist, t.Artist, b.SetArtist},
+		{ifds.Copyright, t.Copyright, b.SetCopyright},
+	} {
+		if v.value == "" || (tm.policy == FillMissing && b.HasTag(ifds.IFD0, 0, v.id)) {
+			continue
+		}
+		if v.set(v.value) == nil {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// xmp returns the XMP properties that were changed.
+func (tm templateMerge) xmp(x *xmp.XMP, t Template) (props []xmpns.Property) {
+	set := func(changed bool, ns xmpns.Namespace, name xmpns.Name) {
+		if changed {
+			props = append(props, xmpns.NewProperty(ns, name))
+		}
+	}
+	set(tm.strings(&x.DC.Creator, t.Artist), xmpns.DcNS, xmpns.Creator)
+	if tm.strings(&x.DC.Rights, t.Copyright) {
+		x.Rights.Marked = true
+		set(true, xmpns.DcNS, xmpns.Rights)
+		set(true, xmpns.XmpRightsNS, xmpns.Marked)
+	}
+	ci := &x.IPTCCore.CreatorContactInfo
+	contact := tm.string(&ci.Email, t.Contact.Email)
+	contact = tm.string(&ci.URL, t.Contact.URL) || contact
+	contact = tm.string(&ci.Phone, t.Contact.Phone) || contact
+	set(contact, xmpns.Iptc4xmpCoreNS, xmpns.CreatorContactInfo)
+	set(tm.strings(&x.Rights.UsageTerms, t.UsageTerms), xmpns.XmpRightsNS, xmpns.UsageTerms)
+	set(tm.string(&x.Rights.WebStatement, t.WebStatement), xmpns.XmpRightsNS, xmpns.WebStatement)
+	return props
+}
+
+func (tm templateMerge) iptc(i *iptc.IPTC, t Template) (changed bool) {
+	changed = tm.strings(&i.Byline, t.Artist)
+	changed = tm.string(&i.Copyright, t.Copyright) || changed
+	return tm.strings(&i.Contact, t.Contact.Email, t.Contact.URL, t.Contact.Phone) || changed
+}
+
+// equalStrings returns true if a and b have the same values.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package imagemeta
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+// templateValues returns the Exif, XMP and IPTC values of a JPEG set by ApplyTemplate.
+func templateValues(t *testing.T, buf []byte) (artist, copyright string, x xmp.XMP, i iptc.IPTC) {
+	t.Helper()
+	e, err := parseJPEGExif(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := jpeg.ScanJPEG(bytes.NewReader(buf), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x, err = m.Xmp(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := jpeg.ReadIPTC(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, err = iptc.Decode(data); err != nil {
+		t.Fatal(err)
+	}
+	artist, _ = e.Artist()
+	copyright, _ = e.Copyright()
+	return artist, copyright, x, i
+}
+
+func TestApplyTemplate(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := Template{
+		Artist:       "Studio Photographer",
+		Copyright:    "© 2022 Studio",
+		Contact:      xmp.ContactInfo{Email: "studio@example.com", URL: "https://example.com"},
+		UsageTerms:   "Editorial use only",
+		WebStatement: "https://example.com/license",
+	}
+
+	var out bytes.Buffer
+	if err = ApplyTemplate(bytes.NewReader(buf), &out, tmpl, Overwrite); err != nil {
+		t.Fatal(err)
+	}
+	applied := append([]byte(nil), out.Bytes()...)
+	artist, copyright, x, i := templateValues(t, applied)
+	assert.Equal(t, tmpl.Artist, artist)
+	assert.Equal(t, tmpl.Copyright, copyright)
+	assert.Equal(t, []string{tmpl.Artist}, x.DC.Creator)
+	assert.Equal(t, []string{tmpl.Copyright}, x.DC.Rights)
+	assert.Equal(t, xmp.Rights{Marked: true, UsageTerms: []string{tmpl.UsageTerms}, WebStatement: tmpl.WebStatement}, x.Rights)
+	assert.Equal(t, tmpl.Contact, x.IPTCCore.CreatorContactInfo)
+	assert.Equal(t, []string{tmpl.Artist}, i.Byline)
+	assert.Equal(t, tmpl.Copyright, i.Copyright)
+	assert.Equal(t, []string{tmpl.Contact.Email, tmpl.Contact.URL}, i.Contact)
+
+	// FillMissing keeps the values of the image
+	fill := Template{Artist: "Other", Copyright: "Other", Contact: xmp.ContactInfo{Email: "other@example.com", Phone: "+1 555 0100"}}
+	out.Reset()
+	if err = ApplyTemplate(bytes.NewReader(applied), &out, fill, FillMissing); err != nil {
+		t.Fatal(err)
+	}
+	artist, copyright, x, i = templateValues(t, out.Bytes())
+	assert.Equal(t, tmpl.Artist, artist)
+	assert.Equal(t, tmpl.Copyright, copyright)
+	assert.Equal(t, []string{tmpl.Artist}, x.DC.Creator)
+	assert.Equal(t, xmp.ContactInfo{Email: tmpl.Contact.Email, URL: tmpl.Contact.URL, Phone: fill.Contact.Phone}, x.IPTCCore.CreatorContactInfo)
+	assert.Equal(t, []string{tmpl.Contact.Email, tmpl.Contact.URL}, i.Contact)
+
+	// A Template without changes copies the image unchanged
+	out.Reset()
+	if err = ApplyTemplate(bytes.NewReader(applied), &out, tmpl, Overwrite); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.Equal(applied, out.Bytes()), "ApplyTemplate without changes should copy the image unchanged")
+
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, ApplyTemplate(f, &out, tmpl, Overwrite), ErrMetadataNotSupported)
+}
+
+func TestApplyTemplateKeepsMetadata(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Properties and DataSets that are not supported by packages xmp and iptc
+	packet := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description rdf:about="" xmlns:custom="http://example.com/ns/" custom:Value="kept"/></rdf:RDF></x:xmpmeta>`)
+	var src bytes.Buffer
+	if err = jpeg.Rewrite(bytes.NewReader(buf), &src, jpeg.RewriteOptions{XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+	dateCreated := []byte("\x1c\x02\x37\x00\x0820161011")
+	if data, err := jpeg.ReadIPTC(bytes.NewReader(src.Bytes())); err != nil || !bytes.Contains(data, dateCreated) {
+		t.Fatalf("JPEG.jpg should have the IPTC Date Created (%v)", err)
+	}
+
+	tmpl := Template{Artist: "Studio Photographer", Copyright: "© 2022 Studio"}
+	var out bytes.Buffer
+	if err = ApplyTemplate(bytes.NewReader(src.Bytes()), &out, tmpl, Overwrite); err != nil {
+		t.Fatal(err)
+	}
+	_, _, x, i := templateValues(t, out.Bytes())
+	assert.Equal(t, []string{tmpl.Artist}, x.DC.Creator)
+	assert.Equal(t, tmpl.Copyright, i.Copyright)
+
+	m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := readXMPPacket(bytes.NewReader(out.Bytes()), m.XmpHeader); assert.NoError(t, err) {
+		assert.Contains(t, string(applied), `custom:Value="kept"`)
+	}
+	if data, err := jpeg.ReadIPTC(bytes.NewReader(out.Bytes())); assert.NoError(t, err) {
+		assert.True(t, bytes.Contains(data, dateCreated), "ApplyTemplate should keep the IPTC Date Created")
+	}
+}
+
+func TestApplyTemplateFiles(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"testImages/JPEG.jpg", "assets/a1.jpg", "testImages/GIF.gif"} {
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, filepath.Base(name))
+		if err = os.WriteFile(path, buf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	gif, err := os.ReadFile(paths[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := Template{Artist: "Studio Photographer", Copyright: "© 2022 Studio"}
+	results := make(map[string]error)
+	for res := range ApplyTemplateFiles(context.Background(), paths, tmpl, FillMissing, 2) {
+		results[res.Path] = res.Err
+	}
+	if len(results) != len(paths) {
+		t.Fatalf("Incorrect number of results wanted %d got %d", len(paths), len(results))
+	}
+	for _, path := range paths[:2] {
+		if !assert.NoError(t, results[path], path) {
+			continue
+		}
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, copyright, _, i := templateValues(t, buf)
+		assert.Equal(t, tmpl.Copyright, copyright, path)
+		assert.Equal(t, tmpl.Copyright, i.Copyright, path)
+		if fi, err := os.Stat(path); assert.NoError(t, err) {
+			assert.Equal(t, os.FileMode(0o644), fi.Mode().Perm())
+		}
+	}
+
+	// Files with errors are not changed and temporary files are removed
+	assert.ErrorIs(t, results[paths[2]], ErrMetadataNotSupported)
+	if buf, err := os.ReadFile(paths[2]); assert.NoError(t, err) {
+		assert.True(t, bytes.Equal(gif, buf), "GIF should not be changed")
+	}
+	if entries, err := os.ReadDir(dir); assert.NoError(t, err) {
+		assert.Len(t, entries, len(paths))
+	}
+}
@@ -0,0 +1,154 @@
+// Package tga reads the dimensions and bit depth from the header of a Truevision TGA Image
+// without decoding the image.
+//
+// TGA images do not have a signature, the header is identified by the validity of its fields.
+package tga
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif      = meta.ErrNoExif
+	ErrNoTGAHeader = errors.New("no TGA Header")
+)
+
+// Metadata from a TGA file
+type Metadata struct {
+	mr meta.Reader
+
+	width     uint16
+	height    uint16
+	bitDepth  uint8
+	alphaBits uint8
+	topDown   bool
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(uint32(m.width), uint32(m.height))
+}
+
+// ImageType returns imagetype.ImageTGA for TGA image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageTGA
+}
+
+// BitDepth returns the number of bits per pixel
+func (m Metadata) BitDepth() uint8 {
+	return m.bitDepth
+}
+
+// AlphaBits returns the number of alpha channel bits per pixel
+func (m Metadata) AlphaBits() uint8 {
+	return m.alphaBits
+}
+
+// TopDown returns true if the rows of the image are stored from top to bottom.
+func (m Metadata) TopDown() bool {
+	return m.topDown
+}
+
+// PreviewImage returns a TGA preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Exif returns ErrNoExif, TGA images do not have Exif metadata
+func (m Metadata) Exif() (exif.Exif, error) {
+	return nil, ErrNoExif
+}
+
+// Xmp returns xmp.ErrNoXMP, TGA images do not have XMP metadata
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	return xmp.XMP{}, xmp.ErrNoXMP
+}
+
+// ScanTGA reads the TGA header from mr. Returns Metadata.
+//
+// Returns the error ErrNoTGAHeader if the header is not a valid TGA header.
+func ScanTGA(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if n, _ := mr.ReadAt(buf[:], 0); n < headerLength || !isTGAHeader(buf[:]) {
+		return m, ErrNoTGAHeader
+	}
+	m.width = tgaByteOrder.Uint16(buf[12:14])
+	m.height = tgaByteOrder.Uint16(buf[14:16])
+	m.bitDepth = buf[16]
+	m.alphaBits = buf[17] & descriptorAlphaBits
+	m.topDown = buf[17]&descriptorTopDown != 0
+	return m, nil
+}
+
+// isTGAHeader returns true if the color map, image type, dimensions and pixel depth
+// of the header are valid.
+func isTGAHeader(buf []byte) bool {
+	colorMapType, imageType := buf[1], buf[2]
+	colorMapLength, colorMapEntrySize := tgaByteOrder.Uint16(buf[5:7]), buf[7]
+	width, height := tgaByteOrder.Uint16(buf[12:14]), tgaByteOrder.Uint16(buf[14:16])
+	pixelDepth, descriptor := buf[16], buf[17]
+
+	switch imageType {
+	case imageColorMapped, imageColorMappedRLE:
+		if colorMapType != 1 {
+			return false
+		}
+	case imageTrueColor, imageGrayscale, imageTrueColorRLE, imageGrayscaleRLE:
+		if colorMapType > 1 {
+			return false
+		}
+	default:
+		return false
+	}
+	if colorMapType == 1 {
+		if colorMapLength == 0 || !isDepth(colorMapEntrySize) {
+			return false
+		}
+	} else if colorMapLength != 0 {
+		return false
+	}
+	return width > 0 && height > 0 && isDepth(pixelDepth) && descriptor&descriptorReserved == 0
+}
+
+// isDepth returns true if depth is a TGA pixel or color map entry depth.
+func isDepth(depth uint8) bool {
+	switch depth {
+	case 8, 15, 16, 24, 32:
+		return true
+	}
+	return false
+}
+
+// Image types
+const (
+	imageColorMapped    = 1
+	imageTrueColor      = 2
+	imageGrayscale      = 3
+	imageColorMappedRLE = 9
+	imageTrueColorRLE   = 10
+	imageGrayscaleRLE   = 11
+)
+
+// Image descriptor bits
+const (
+	descriptorAlphaBits = 0x0F
+	descriptorTopDown   = 0x20
+	descriptorReserved  = 0xC0
+)
+
+// headerLength is the length of the TGA header
+const headerLength = 18
+
+// tgaByteOrder TGA always uses a LittleEndian byteorder.
+var tgaByteOrder = binary.LittleEndian
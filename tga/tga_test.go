@@ -0,0 +1,84 @@
+package tga
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// tgaHeader returns a TGA header with the image type, dimensions, pixel depth and descriptor.
+// Color mapped images have a 256 entry 24bit color map.
+func tgaHeader(imageType uint8, width, height uint16, pixelDepth, descriptor uint8) []byte {
+	buf := make([]byte, headerLength)
+	buf[2] = imageType
+	if imageType == imageColorMapped || imageType == imageColorMappedRLE {
+		buf[1] = 1
+		binary.LittleEndian.PutUint16(buf[5:7], 256)
+		buf[7] = 24
+	}
+	binary.LittleEndian.PutUint16(buf[12:14], width)
+	binary.LittleEndian.PutUint16(buf[14:16], height)
+	buf[16] = pixelDepth
+	buf[17] = descriptor
+	return buf
+}
+
+func TestScanTGA(t *testing.T) {
+	testTGAs := []struct {
+		name      string
+		buf       []byte
+		dim       meta.Dimensions
+		bitDepth  uint8
+		alphaBits uint8
+		topDown   bool
+	}{
+		{"TrueColor", tgaHeader(imageTrueColor, 640, 480, 24, 0), meta.NewDimensions(640, 480), 24, 0, false},
+		{"TrueColorAlpha", tgaHeader(imageTrueColorRLE, 64, 32, 32, 0x28), meta.NewDimensions(64, 32), 32, 8, true},
+		{"ColorMapped", tgaHeader(imageColorMapped, 10, 20, 8, 0), meta.NewDimensions(10, 20), 8, 0, false},
+		{"Grayscale", tgaHeader(imageGrayscale, 1, 1, 8, 0), meta.NewDimensions(1, 1), 8, 0, false},
+	}
+	for _, tga := range testTGAs {
+		t.Run(tga.name, func(t *testing.T) {
+			m, err := ScanTGA(bytes.NewReader(tga.buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m.Dimensions() != tga.dim || m.ImageType() != imagetype.ImageTGA {
+				t.Errorf("Incorrect Metadata wanted %s got %s %s", tga.dim, m.Dimensions(), m.ImageType())
+			}
+			if m.BitDepth() != tga.bitDepth || m.AlphaBits() != tga.alphaBits || m.TopDown() != tga.topDown {
+				t.Errorf("Incorrect header got BitDepth %d AlphaBits %d TopDown %t", m.BitDepth(), m.AlphaBits(), m.TopDown())
+			}
+			if _, err = m.Exif(); err != ErrNoExif {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+			}
+		})
+	}
+}
+
+func TestScanTGAErrors(t *testing.T) {
+	noColorMap := tgaHeader(imageColorMapped, 1, 1, 8, 0)
+	noColorMap[1] = 0
+	testErrors := []struct {
+		name string
+		buf  []byte
+	}{
+		{"Short", tgaHeader(imageTrueColor, 1, 1, 24, 0)[:10]},
+		{"ImageType", tgaHeader(4, 1, 1, 24, 0)},
+		{"NoColorMap", noColorMap},
+		{"PixelDepth", tgaHeader(imageTrueColor, 1, 1, 12, 0)},
+		{"ZeroWidth", tgaHeader(imageTrueColor, 0, 1, 24, 0)},
+		{"Reserved", tgaHeader(imageTrueColor, 1, 1, 24, 0x40)},
+		{"Text", []byte("ThisIsAnUnknownfile")},
+	}
+	for _, te := range testErrors {
+		t.Run(te.name, func(t *testing.T) {
+			if _, err := ScanTGA(bytes.NewReader(te.buf)); err != ErrNoTGAHeader {
+				t.Errorf("Incorrect error wanted %v got %v", ErrNoTGAHeader, err)
+			}
+		})
+	}
+}
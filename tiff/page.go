@@ -0,0 +1,126 @@
+package tiff
+
+import (
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// subfileReducedResolution is the reduced resolution flag of the NewSubfileType tag
+const subfileReducedResolution = 0x01
+
+// Page is an image of the IFD0 chain of a Tiff file. Multi-page Tiff files have
+// a Page for each Ifd of the chain, and the thumbnail of a camera file is a Page
+// with a reduced resolution.
+//
+// The image data is either divided into strips of RowsPerStrip rows or into tiles
+// of TileWidth x TileLength pixels.
+type Page struct {
+	Width, Height   uint32
+	BitsPerSample   uint16
+	SamplesPerPixel uint16
+	Compression     uint16
+	Photometric     uint16
+	SubfileType     uint32
+
+	// Strip layout
+	RowsPerStrip uint32
+
+	// Tile layout
+	TileWidth, TileLength uint32
+
+	// Chunks is the number of strips or tiles of the image data.
+	Chunks uint32
+}
+
+// Dimensions returns the dimensions (width and height) of the Page
+func (p Page) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(p.Width, p.Height)
+}
+
+// Tiled returns true if the image data of the Page is divided into tiles.
+func (p Page) Tiled() bool {
+	return p.TileWidth > 0 && p.TileLength > 0
+}
+
+// ReducedResolution returns true if the Page is a reduced resolution version
+// of another Page, such as a thumbnail or a pyramid level.
+func (p Page) ReducedResolution() bool {
+	return p.SubfileType&subfileReducedResolution != 0
+}
+
+// readPages reads the Page of each Ifd of the IFD0 chain from e.
+func readPages(e *exif.Data) (pages []Page) {
+	for i := 0; i <= 0xFF; i++ {
+		p, ok := readPage(e, ifds.IFD0, uint8(i))
+		if !ok {
+			break
+		}
+		pages = append(pages, p)
+	}
+	return pages
+}
+
+// readRawPage reads the first full resolution Page of the SubIFDs from e,
+// the raw image of a DNG file. Returns false if there is none.
+func readRawPage(e *exif.Data) (Page, bool) {
+	for i := 0; i <= 0xFF; i++ {
+		p, ok := readPage(e, ifds.SubIFD, uint8(i))
+		if !ok {
+			break
+		}
+		if !p.ReducedResolution() {
+			return p, true
+		}
+	}
+	return Page{}, false
+}
+
+// readPage reads the Page of the Ifd of ifdType with index.
+// Returns false if the Ifd does not have image dimensions.
+func readPage(e *exif.Data, ifdType ifds.IfdType, index uint8) (p Page, ok bool) {
+	uint32Value := func(id tag.ID) uint32 {
+		t, err := e.GetTag(ifdType, index, id)
+		if err != nil {
+			return 0
+		}
+		v, _ := e.ParseUint32Value(t)
+		return v
+	}
+	if p.Width, p.Height = uint32Value(ifds.ImageWidth), uint32Value(ifds.ImageLength); p.Width == 0 || p.Height == 0 {
+		return p, false
+	}
+	if t, err := e.GetTag(ifdType, index, ifds.BitsPerSample); err == nil {
+		// BitsPerSample has a value for each sample, the first is used
+		if v, err := e.ParseUint16Values(t); err == nil && len(v) > 0 {
+			p.BitsPerSample = v[0]
+		}
+	}
+	p.SamplesPerPixel = uint16(uint32Value(ifds.SamplesPerPixel))
+	p.Compression = uint16(uint32Value(ifds.Compression))
+	p.Photometric = uint16(uint32Value(ifds.PhotometricInterpretation))
+	p.SubfileType = uint32Value(ifds.NewSubfileType)
+	p.TileWidth = uint32Value(ifds.TileWidth)
+	p.TileLength = uint32Value(ifds.TileLength)
+	if t, err := e.GetTag(ifdType, index, ifds.TileOffsets); err == nil && p.Tiled() {
+		p.Chunks = t.UnitCount
+	} else if t, err = e.GetTag(ifdType, index, ifds.StripOffsets); err == nil {
+		p.Chunks = t.UnitCount
+		p.RowsPerStrip = uint32Value(ifds.RowsPerStrip)
+		if p.RowsPerStrip == 0 || p.RowsPerStrip > p.Height {
+			// A missing RowsPerStrip is a single strip
+			p.RowsPerStrip = p.Height
+		}
+	}
+	if p.SamplesPerPixel == 0 {
+		p.SamplesPerPixel = 1
+	}
+	if p.BitsPerSample == 0 {
+		p.BitsPerSample = 1
+	}
+	if p.Compression == 0 {
+		p.Compression = 1
+	}
+	return p, true
+}
@@ -28,6 +28,7 @@ type Metadata struct {
 	width      uint32
 	height     uint32
 	e          exif.Exif
+	pages      []Page
 }
 
 // Dimensions returns the dimensions (width and height) of the image
@@ -46,6 +47,12 @@ func (m Metadata) PreviewImage() io.Reader {
 	return m.mr
 }
 
+// Pages returns the Pages of the IFD0 chain. A multi-page Tiff has a Page
+// for each image.
+func (m Metadata) Pages() []Page {
+	return m.pages
+}
+
 // Exif returns parsed Exif data from JPEG
 func (m Metadata) Exif() (exif.Exif, error) {
 	return m.e, nil
@@ -57,6 +64,13 @@ func (m Metadata) Xmp() (xmp.XMP, error) {
 	return xmp.ParseXmp(sr)
 }
 
+// Parse reads the Tiff Header and Exif metadata from mr. Tiff, BigTiff and the Tiff
+// based camera raw files are supported. Returns Metadata.
+//
+// The Pages of the IFD0 chain are read with their strip or tile layout. The dimensions
+// of a Tiff image are from the first Page when it is not a reduced resolution image.
+// A Tiff with a DNGVersion is a DNG, its dimensions are from the raw image of the SubIFDs
+// when the first Page is a reduced resolution image.
 func Parse(mr meta.Reader, t imagetype.ImageType) (Metadata, error) {
 	exifHeader, err := ScanTiffHeader(mr, t)
 	if err != nil {
@@ -66,13 +80,29 @@ func Parse(mr meta.Reader, t imagetype.ImageType) (Metadata, error) {
 	if err != nil {
 		return Metadata{}, err
 	}
-	return Metadata{
+	m := Metadata{
 		mr:         mr,
 		e:          e,
 		ExifHeader: exifHeader,
 		height:     uint32(e.ImageHeight()),
 		width:      uint32(e.ImageWidth()),
-	}, nil
+		pages:      readPages(e),
+	}
+	if _, err = e.DNGVersion(); err == nil && exifHeader.ImageType == imagetype.ImageTiff {
+		m.ExifHeader.ImageType = imagetype.ImageDNG
+	} else if it := e.ImageType(); (it == imagetype.ImagePEF || it == imagetype.ImageSRW) && exifHeader.ImageType == imagetype.ImageTiff {
+		// PEF and SRW have a Tiff Header and are identified by their Makernote
+		m.ExifHeader.ImageType = it
+	}
+	switch m.ExifHeader.ImageType {
+	case imagetype.ImageTiff, imagetype.ImageDNG:
+		if len(m.pages) > 0 && !m.pages[0].ReducedResolution() {
+			m.width, m.height = m.pages[0].Width, m.pages[0].Height
+		} else if p, ok := readRawPage(e); ok && m.ExifHeader.ImageType == imagetype.ImageDNG {
+			m.width, m.height = p.Width, p.Height
+		}
+	}
+	return m, nil
 }
 
 // Scan scans a reader for Tiff Image markers then xmpDecodeFn and exifDecodeFn are run at their respective
@@ -116,6 +146,13 @@ func scan(br *bufio.Reader, it imagetype.ImageType) (header meta.ExifHeader, err
 		}
 
 		byteOrder := meta.BinaryOrder(buf)
+		if byteOrder == nil && discarded == 0 {
+			// BigTiff Header
+			if byteOrder = meta.BigTiffBinaryOrder(buf); byteOrder != nil {
+				firstIfdOffset := byteOrder.Uint64(buf[8:16])
+				return meta.NewBigTiffExifHeader(byteOrder, firstIfdOffset, it), nil
+			}
+		}
 		if byteOrder == nil {
 			// Exif not identified. Move forward by one byte.
 			if buf[1] == 0x49 || buf[1] == 0x4d {
@@ -7,6 +7,9 @@ import (
 	"os"
 	"testing"
 
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/tag"
 	"github.com/evanoberholster/imagemeta/imagetype"
 	"github.com/evanoberholster/imagemeta/meta"
 )
@@ -16,7 +19,7 @@ func TestTiff(t *testing.T) {
 	exifHeaderTests := []struct {
 		filename         string
 		byteOrder        binary.ByteOrder
-		firstIfdOffset   uint32
+		firstIfdOffset   uint64
 		tiffHeaderOffset uint32
 		imageType        imagetype.ImageType
 	}{
@@ -57,10 +60,237 @@ func TestTiff(t *testing.T) {
 		})
 	}
 
+	// BigTiff Header
+	buf := make([]byte, 32)
+	copy(buf, []byte{'I', 'I', 0x2b, 0, 8, 0, 0, 0, 0x10, 0, 0, 0, 0, 0, 0, 0})
+	h, err := ScanTiffHeader(bytes.NewReader(buf), imagetype.ImageUnknown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.BigTiff || h.ByteOrder != binary.LittleEndian || h.FirstIfdOffset != 0x10 || h.ImageType != imagetype.ImageTiff {
+		t.Errorf("Incorrect BigTiff Header got %s", h)
+	}
+
 	// Error No Tiff Header
-	buf := []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
-	_, err := ScanTiffHeader(bytes.NewReader(buf), imagetype.ImageTiff)
+	buf = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	_, err = ScanTiffHeader(bytes.NewReader(buf), imagetype.ImageTiff)
 	if err != meta.ErrNoExif {
 		t.Errorf("Incorrect err wanted %s got %s ", meta.ErrNoExif, err)
 	}
 }
+
+func TestParseCR2(t *testing.T) {
+	f, err := os.Open("../testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	m, err := Parse(f, imagetype.ImageCR2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ExifHeader.ImageType != imagetype.ImageCR2 || m.ExifHeader.FirstIfd != ifds.IFD0 {
+		t.Errorf("Incorrect CR2 Exif Header got %s", m.ExifHeader)
+	}
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraMake() != "Canon" || e.CameraModel() != "Canon EOS-1Ds Mark III" {
+		t.Errorf("Incorrect CR2 camera wanted %s %s got %s %s", "Canon", "Canon EOS-1Ds Mark III", e.CameraMake(), e.CameraModel())
+	}
+	if iso, err := e.ISOSpeed(); err != nil || iso != 100 {
+		t.Errorf("Incorrect CR2 ISO wanted %d got %d (%v)", 100, iso, err)
+	}
+	if ss, err := e.ShutterSpeed(); err != nil || ss.String() != "1/40" {
+		t.Errorf("Incorrect CR2 ShutterSpeed wanted %s got %s (%v)", "1/40", ss, err)
+	}
+	// Exif and Makernote pointers are followed
+	if _, err = e.CanonCameraSettings(); err != nil {
+		t.Errorf("Incorrect CR2 Makernote error %v", err)
+	}
+	if w, h := m.Dimensions().Size(); w != 5616 || h != 3744 {
+		t.Errorf("Incorrect CR2 image size wanted %dx%d got %dx%d", 5616, 3744, w, h)
+	}
+}
+
+// tiffEntry is a Short or Long entry of a Tiff Ifd with values that fit within the entry
+type tiffEntry struct {
+	id    tag.ID
+	t     tag.Type
+	count uint32
+	value uint32
+}
+
+// newTiff returns a Tiff or BigTiff file with an Ifd for each page in the IFD0 chain.
+// The last Ifd points to the Ifd at loopIndex, or ends the chain when loopIndex is -1.
+func newTiff(bigTiff bool, pages [][]tiffEntry, loopIndex int) []byte {
+	bo := binary.LittleEndian
+	headerLength, countLength, entryLength, offsetLength := 8, 2, 12, 4
+	if bigTiff {
+		headerLength, countLength, entryLength, offsetLength = 16, 8, 20, 8
+	}
+	putOffset := func(b []byte, v int) {
+		if bigTiff {
+			bo.PutUint64(b, uint64(v))
+			return
+		}
+		bo.PutUint32(b, uint32(v))
+	}
+	offsets := make([]int, len(pages))
+	offset := headerLength
+	for i, entries := range pages {
+		offsets[i] = offset
+		offset += countLength + len(entries)*entryLength + offsetLength
+	}
+
+	buf := make([]byte, offset)
+	copy(buf, "II")
+	if bigTiff {
+		bo.PutUint16(buf[2:], 0x002b)
+		bo.PutUint16(buf[4:], 8)
+		putOffset(buf[8:], offsets[0])
+	} else {
+		bo.PutUint16(buf[2:], 0x002a)
+		putOffset(buf[4:], offsets[0])
+	}
+	for i, entries := range pages {
+		b := buf[offsets[i]:]
+		putOffset(b, len(entries))
+		if !bigTiff {
+			bo.PutUint16(b, uint16(len(entries)))
+		}
+		b = b[countLength:]
+		for _, e := range entries {
+			bo.PutUint16(b[0:], uint16(e.id))
+			bo.PutUint16(b[2:], uint16(e.t))
+			if bigTiff {
+				bo.PutUint64(b[4:], uint64(e.count))
+				bo.PutUint32(b[12:], e.value)
+			} else {
+				bo.PutUint32(b[4:], e.count)
+				bo.PutUint32(b[8:], e.value)
+			}
+			b = b[entryLength:]
+		}
+		switch {
+		case i+1 < len(pages):
+			putOffset(b, offsets[i+1])
+		case loopIndex >= 0:
+			putOffset(b, offsets[loopIndex])
+		}
+	}
+	return buf
+}
+
+func TestParseTiffPages(t *testing.T) {
+	pages := [][]tiffEntry{
+		{
+			{ifds.NewSubfileType, tag.TypeLong, 1, 0},
+			{ifds.ImageWidth, tag.TypeLong, 1, 70000},
+			{ifds.ImageLength, tag.TypeShort, 1, 300},
+			{ifds.BitsPerSample, tag.TypeShort, 1, 16},
+			{ifds.Compression, tag.TypeShort, 1, 5},
+			{ifds.PhotometricInterpretation, tag.TypeShort, 1, 1},
+			{ifds.StripOffsets, tag.TypeLong, 1, 0},
+			{ifds.RowsPerStrip, tag.TypeLong, 1, 0xFFFFFFFF},
+		},
+		{
+			{ifds.NewSubfileType, tag.TypeLong, 1, 2},
+			{ifds.ImageWidth, tag.TypeShort, 1, 512},
+			{ifds.ImageLength, tag.TypeShort, 1, 256},
+			{ifds.SamplesPerPixel, tag.TypeShort, 1, 3},
+			{ifds.TileWidth, tag.TypeShort, 1, 256},
+			{ifds.TileLength, tag.TypeShort, 1, 256},
+			{ifds.TileOffsets, tag.TypeLong, 1, 0},
+		},
+		{
+			{ifds.NewSubfileType, tag.TypeLong, 1, 1},
+			{ifds.ImageWidth, tag.TypeShort, 1, 160},
+			{ifds.ImageLength, tag.TypeShort, 1, 120},
+		},
+	}
+	for _, bigTiff := range []bool{false, true} {
+		// The last Ifd loops back to the second Ifd
+		m, err := Parse(bytes.NewReader(newTiff(bigTiff, pages, 1)), imagetype.ImageTiff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ExifHeader.BigTiff != bigTiff || m.ImageType() != imagetype.ImageTiff {
+			t.Errorf("Incorrect Exif Header got %s BigTiff %t", m.ExifHeader, m.ExifHeader.BigTiff)
+		}
+		if m.Dimensions() != meta.NewDimensions(70000, 300) {
+			t.Errorf("Incorrect Dimensions wanted %s got %s", meta.NewDimensions(70000, 300), m.Dimensions())
+		}
+		p := m.Pages()
+		if len(p) != 3 {
+			t.Fatalf("Incorrect number of Pages wanted %d got %d", 3, len(p))
+		}
+		if p[0].Tiled() || p[0].RowsPerStrip != 300 || p[0].Chunks != 1 || p[0].BitsPerSample != 16 || p[0].SamplesPerPixel != 1 || p[0].Compression != 5 {
+			t.Errorf("Incorrect striped Page got %+v", p[0])
+		}
+		if !p[1].Tiled() || p[1].TileWidth != 256 || p[1].TileLength != 256 || p[1].BitsPerSample != 1 || p[1].SamplesPerPixel != 3 || p[1].Compression != 1 || p[1].ReducedResolution() {
+			t.Errorf("Incorrect tiled Page got %+v", p[1])
+		}
+		if !p[2].ReducedResolution() || p[2].Dimensions() != meta.NewDimensions(160, 120) || p[2].Chunks != 0 {
+			t.Errorf("Incorrect reduced resolution Page got %+v", p[2])
+		}
+	}
+
+	// A single Page without a strip or tile layout
+	m, err := Parse(bytes.NewReader(newTiff(false, pages[2:], -1)), imagetype.ImageTiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Pages()) != 1 || m.Dimensions() != meta.NewDimensions(160, 120) {
+		t.Errorf("Incorrect Pages got %+v", m.Pages())
+	}
+}
+
+func TestParseDNG(t *testing.T) {
+	f, err := os.Open("../testImages/Hero8.GPR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := Parse(f, imagetype.ImageTiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ImageType() != imagetype.ImageDNG || m.Dimensions() != meta.NewDimensions(4000, 3000) {
+		t.Errorf("Incorrect GPR Metadata got %s %s", m.ImageType(), m.Dimensions())
+	}
+	if p := m.Pages(); len(p) != 1 || !p[0].Tiled() || p[0].BitsPerSample != 16 {
+		t.Errorf("Incorrect GPR Pages got %+v", p)
+	}
+
+	// DNG with a thumbnail in IFD0 and the raw image in the SubIFDs
+	b := exif.NewBuilder(binary.LittleEndian)
+	for _, err = range []error{
+		b.SetByte(ifds.IFD0, 0, ifds.DNGVersion, 1, 6, 0, 0),
+		b.SetLong(ifds.IFD0, 0, ifds.NewSubfileType, 1),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageWidth, 256),
+		b.SetLong(ifds.IFD0, 0, ifds.ImageLength, 192),
+		b.SetLong(ifds.SubIFD, 0, ifds.NewSubfileType, 1),
+		b.SetLong(ifds.SubIFD, 0, ifds.ImageWidth, 1024),
+		b.SetLong(ifds.SubIFD, 0, ifds.ImageLength, 768),
+		b.SetLong(ifds.SubIFD, 1, ifds.NewSubfileType, 0),
+		b.SetLong(ifds.SubIFD, 1, ifds.ImageWidth, 6000),
+		b.SetLong(ifds.SubIFD, 1, ifds.ImageLength, 4000),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err = Parse(bytes.NewReader(buf), imagetype.ImageTiff); err != nil {
+		t.Fatal(err)
+	}
+	if m.ImageType() != imagetype.ImageDNG || m.Dimensions() != meta.NewDimensions(6000, 4000) {
+		t.Errorf("Incorrect DNG Metadata got %s %s", m.ImageType(), m.Dimensions())
+	}
+}
@@ -0,0 +1,172 @@
+package imagemeta
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// Exif date and time layouts
+const (
+	exifDateTimeLayout = "2006:01:02 15:04:05"
+	exifDateLayout     = "2006:01:02"
+)
+
+// exifDateTags are the Exif date and time tags changed by ShiftTime.
+var exifDateTags = []struct {
+	ifd ifds.IfdType
+	id  tag.ID
+}{
+	{ifds.IFD0, ifds.DateTime},
+	{ifds.ExifIFD, exififd.DateTimeOriginal},
+	{ifds.ExifIFD, exififd.DateTimeDigitized},
+}
+
+// xmpDate matches the value of the XMP date properties changed by ShiftTime
+// as an attribute or an element. Values without a time are not matched.
+var xmpDate = regexp.MustCompile(`(?:xmp:CreateDate|xmp:ModifyDate|xmp:MetadataDate|exif:DateTimeOriginal|exif:DateTimeDigitized|exif:GPSTimeStamp|photoshop:DateCreated|tiff:DateTime)(?:\s*=\s*["']|>\s*)(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(?::\d{2}(?:\.\d+)?)?(?:Z|[+-]\d{2}:\d{2})?)`)
+
+// ShiftTime copies the image from r to w with its date and time values shifted by d,
+// for images taken with a camera clock that was not set correctly.
+//
+// The Exif DateTime, DateTimeOriginal, DateTimeDigitized, GPSDateStamp and
+// GPSTimeStamp, and the XMP xmp:CreateDate, xmp:ModifyDate, xmp:MetadataDate,
+// exif:DateTimeOriginal, exif:DateTimeDigitized, exif:GPSTimeStamp,
+// photoshop:DateCreated and tiff:DateTime are shifted. Only the bytes of the values
+// are changed, the rest of the image is copied unchanged. Values that can not be
+// parsed are not changed. XMP values keep their precision and time zone.
+//
+// Supports JPEG and Tiff based images. Returns ErrMetadataNotSupported for
+// other image types and BigTiff.
+func ShiftTime(r meta.Reader, w io.Writer, d time.Duration) error {
+	e, exifHeader, xmpHeader, err := patchHeaders(r)
+	if err != nil {
+		return err
+	}
+
+	var patches []patch
+	if e != nil {
+		if patches, err = exifTimePatches(e, exifHeader, d); err != nil {
+			return err
+		}
+	}
+	if xmpHeader.Length > 0 {
+		buf, err := readXMPPacket(r, xmpHeader)
+		if err != nil {
+			return err
+		}
+		for _, loc := range xmpDate.FindAllSubmatchIndex(buf, -1) {
+			if value, ok := shiftXMPDate(string(buf[loc[2]:loc[3]]), d); ok {
+				patches = append(patches, patch{offset: int64(xmpHeader.Offset) + int64(loc[2]), value: []byte(value)})
+			}
+		}
+	}
+	return copyPatched(r, w, patches)
+}
+
+// exifTimePatches returns the patches of the Exif date and time values shifted by d.
+func exifTimePatches(e *exif.Data, header meta.ExifHeader, d time.Duration) (patches []patch, err error) {
+	offset := func(t tag.Tag) int64 {
+		return int64(header.TiffHeaderOffset) + int64(t.ValueOffset)
+	}
+	for _, dt := range exifDateTags {
+		t, err := e.GetTag(dt.ifd, 0, dt.id)
+		if err != nil || t.Type() != tag.TypeASCII || t.Size() < uint32(len(exifDateTimeLayout)) {
+			continue
+		}
+		buf, err := e.RawTagBytes(t)
+		if err != nil {
+			return nil, err
+		}
+		tm, err := time.Parse(exifDateTimeLayout, string(buf[:len(exifDateTimeLayout)]))
+		if err != nil {
+			continue
+		}
+		if value := tm.Add(d).Format(exifDateTimeLayout); len(value) == len(exifDateTimeLayout) {
+			patches = append(patches, patch{offset: offset(t), value: []byte(value)})
+		}
+	}
+
+	// GPSDateStamp and GPSTimeStamp are shifted together
+	ds, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSDateStamp)
+	if err != nil || ds.Type() != tag.TypeASCII || ds.Size() < uint32(len(exifDateLayout)) {
+		return patches, nil
+	}
+	ts, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSTimeStamp)
+	if err != nil || ts.Type() != tag.TypeRational || ts.UnitCount != 3 {
+		return patches, nil
+	}
+	date, err := e.RawTagBytes(ds)
+	if err != nil {
+		return nil, err
+	}
+	hms, err := e.ParseRationalValues(ts)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := time.Parse(exifDateLayout, string(date[:len(exifDateLayout)]))
+	if err != nil {
+		return patches, nil
+	}
+	for i, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		if hms[i].Denominator == 0 {
+			return patches, nil
+		}
+		tm = tm.Add(time.Duration(hms[i].Numerator) * unit / time.Duration(hms[i].Denominator))
+	}
+	tm = tm.Add(d)
+	value := tm.Format(exifDateLayout)
+	if len(value) != len(exifDateLayout) {
+		return patches, nil
+	}
+	// The seconds keep their denominator
+	den := uint64(hms[2].Denominator)
+	secs := uint64(tm.Second())*den + uint64(tm.Nanosecond())*den/uint64(time.Second)
+	rationals := make([]byte, tag.TypeRationalSize*3)
+	for i, v := range [][2]uint64{{uint64(tm.Hour()), 1}, {uint64(tm.Minute()), 1}, {secs, den}} {
+		header.ByteOrder.PutUint32(rationals[i*8:], uint32(v[0]))
+		header.ByteOrder.PutUint32(rationals[i*8+4:], uint32(v[1]))
+	}
+	return append(patches,
+		patch{offset: offset(ds), value: []byte(value)},
+		patch{offset: offset(ts), value: rationals}), nil
+}
+
+// shiftXMPDate returns the XMP date value shifted by d in the layout and time zone of value.
+// Returns false if value can not be parsed or the shifted value does not have the same length.
+func shiftXMPDate(value string, d time.Duration) (string, bool) {
+	layout := "2006-01-02T15:04"
+	rest := value[len(layout):]
+	if strings.HasPrefix(rest, ":") {
+		layout += ":05"
+		rest = rest[3:]
+		if strings.HasPrefix(rest, ".") {
+			n := 1
+			for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+				n++
+			}
+			layout += "." + strings.Repeat("0", n-1)
+			rest = rest[n:]
+		}
+	}
+	switch {
+	case rest == "Z":
+		layout += "Z07:00"
+	case rest != "":
+		layout += "-07:00"
+	}
+	tm, err := time.Parse(layout, value)
+	if err != nil {
+		return "", false
+	}
+	shifted := tm.Add(d).Format(layout)
+	return shifted, len(shifted) == len(value)
+}
@@ -0,0 +1,163 @@
+package imagemeta
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/gpsifd"
+	"github.com/evanoberholster/imagemeta/exif/tag"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftTime(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// JPEG with GPS and XMP dates
+	var src bytes.Buffer
+	err = rewriteExif(bytes.NewReader(buf), &src, func(b *exif.Builder) error {
+		if err := b.SetASCII(ifds.GPSIFD, 0, gpsifd.GPSDateStamp, "2016:10:11"); err != nil {
+			return err
+		}
+		return b.SetRational(ifds.GPSIFD, 0, gpsifd.GPSTimeStamp, tag.Rational{Numerator: 23, Denominator: 1}, tag.Rational{Numerator: 59, Denominator: 1}, tag.Rational{Numerator: 505, Denominator: 10})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tz := time.FixedZone("", -4*60*60)
+	x := xmp.XMP{}
+	x.Basic.CreateDate = time.Date(2016, 10, 11, 16, 59, 50, 0, tz)
+	x.Basic.ModifyDate = time.Date(2016, 10, 11, 20, 59, 50, 0, time.UTC)
+	packet, err := xmp.Marshal(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var orig bytes.Buffer
+	if err = jpeg.Rewrite(bytes.NewReader(src.Bytes()), &orig, jpeg.RewriteOptions{XMP: packet}); err != nil {
+		t.Fatal(err)
+	}
+	want, err := parseJPEGExif(orig.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := 26*time.Hour + 30*time.Minute
+	var out bytes.Buffer
+	if err = ShiftTime(bytes.NewReader(orig.Bytes()), &out, d); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, orig.Len(), out.Len())
+	e, err := parseJPEGExif(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dt, err := want.DateTime(time.UTC)
+	assert.NoError(t, err)
+	shifted, err := e.DateTime(time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, dt.Add(d), shifted)
+	gps, err := e.GPSDate(time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2016, 10, 13, 2, 29, 50, 0, time.UTC), gps)
+	ts, err := e.GetTag(ifds.GPSIFD, 0, gpsifd.GPSTimeStamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hms, err := e.ParseRationalValues(ts)
+	assert.NoError(t, err)
+	assert.Equal(t, []tag.Rational{{Numerator: 2, Denominator: 1}, {Numerator: 29, Denominator: 1}, {Numerator: 505, Denominator: 10}}, hms)
+
+	m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x2, err := m.Xmp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, x.Basic.CreateDate.Add(d).Equal(x2.Basic.CreateDate), "xmp:CreateDate should be shifted")
+	_, offset := x2.Basic.CreateDate.Zone()
+	assert.Equal(t, -4*60*60, offset)
+	assert.True(t, x.Basic.ModifyDate.Add(d).Equal(x2.Basic.ModifyDate), "xmp:ModifyDate should be shifted")
+
+	// A zero duration copies the image unchanged
+	out.Reset()
+	if err = ShiftTime(bytes.NewReader(orig.Bytes()), &out, 0); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.Equal(orig.Bytes(), out.Bytes()), "ShiftTime by 0 should copy the image unchanged")
+
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, ShiftTime(f, &out, d), ErrMetadataNotSupported)
+}
+
+func TestShiftTimeTiff(t *testing.T) {
+	buf, err := os.ReadFile("testImages/CR2.exif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = ShiftTime(bytes.NewReader(buf), &out, -time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(buf), out.Len())
+	want, _, _, err := patchHeaders(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, _, _, err := patchHeaders(bytes.NewReader(out.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range []func(*exif.Data) (time.Time, error){
+		func(e *exif.Data) (time.Time, error) { return e.DateTime(time.UTC) },
+		func(e *exif.Data) (time.Time, error) { return e.ModifyDate(time.UTC) },
+	} {
+		dt, err := fn(want)
+		assert.NoError(t, err)
+		shifted, err := fn(e)
+		assert.NoError(t, err)
+		assert.Equal(t, dt.Add(-time.Minute), shifted)
+	}
+}
+
+func TestShiftXMPDate(t *testing.T) {
+	tests := []struct {
+		value, shifted string
+		ok             bool
+	}{
+		{"2016-10-11T16:59:50-04:00", "2016-10-11T18:00:50-04:00", true},
+		{"2016-10-11T16:59:50.25+02:00", "2016-10-11T18:00:50.25+02:00", true},
+		{"2016-10-11T23:59:50Z", "2016-10-12T01:00:50Z", true},
+		{"2016-10-11T23:59:50+00:00", "2016-10-12T01:00:50+00:00", true},
+		{"2016-10-11T16:59", "2016-10-11T18:00", true},
+		{"2016-10-11T16:59:50", "2016-10-11T18:00:50", true},
+		{"2016-13-11T16:59:50", "", false},
+	}
+	for _, test := range tests {
+		shifted, ok := shiftXMPDate(test.value, time.Hour+time.Minute)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.shifted, shifted, test.value)
+	}
+}
+
+// parseJPEGExif returns the parsed Exif of a JPEG.
+func parseJPEGExif(buf []byte) (*exif.Data, error) {
+	m, err := jpeg.ScanJPEG(bytes.NewReader(buf), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return exif.ParseExif(bytes.NewReader(buf), m.ExifHeader)
+}
@@ -0,0 +1,352 @@
+// Package webp reads metadata information (Exif, XMP and ICC profile) from a WebP Image,
+// including animated WebP images.
+package webp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoExif       = meta.ErrNoExif
+	ErrNoRIFFHeader = errors.New("no RIFF WebP Header")
+
+	// ErrNoICCProfile is returned when the WebP does not have an ICCP chunk.
+	ErrNoICCProfile = errors.New("no ICC profile")
+)
+
+// Metadata from a WebP file
+type Metadata struct {
+	mr         meta.Reader
+	ExifHeader meta.ExifHeader
+	XmpHeader  meta.XmpHeader
+
+	// Decode Functions for EXIF and XMP metadata
+	exifFn func(r io.Reader, header meta.ExifHeader) error
+	xmpFn  func(r io.Reader, header meta.XmpHeader) error
+
+	width  uint32
+	height uint32
+
+	// ICCP chunk payload
+	iccOffset uint32
+	iccLength uint32
+
+	// Animation
+	animated bool
+	frames   uint32
+
+	// Reader
+	br        *bufio.Reader
+	discarded uint32
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.width, m.height)
+}
+
+// ImageType returns imagetype.ImageWebP for WebP image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageWebP
+}
+
+// PreviewImage returns a WebP preview image
+func (m Metadata) PreviewImage() io.Reader {
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// Animated returns true if the WebP is an animated image.
+func (m Metadata) Animated() bool {
+	return m.animated
+}
+
+// Frames returns the number of ANMF frames of an animated WebP.
+// Returns 0 for a still image.
+func (m Metadata) Frames() int {
+	return int(m.frames)
+}
+
+// ICCProfile returns the ICC profile of the ICCP chunk.
+// Returns ErrNoICCProfile if the WebP does not have an ICC profile.
+func (m Metadata) ICCProfile() ([]byte, error) {
+	if m.iccLength == 0 {
+		return nil, ErrNoICCProfile
+	}
+	buf := make([]byte, m.iccLength)
+	if _, err := m.mr.ReadAt(buf, int64(m.iccOffset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Exif returns parsed Exif data from WebP
+func (m Metadata) Exif() (exif.Exif, error) {
+	return exif.ParseExif(m.mr, m.ExifHeader)
+}
+
+// Xmp returns parsed Xmp data from WebP
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	sr := io.NewSectionReader(m.mr, int64(m.XmpHeader.Offset), int64(m.XmpHeader.Length))
+	return xmp.ParseXmp(sr)
+}
+
+func newMetadata(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) Metadata {
+	br := bufio.NewReaderSize(mr, 64)
+
+	return Metadata{mr: mr, br: br, exifFn: exifFn, xmpFn: xmpFn}
+}
+
+// ScanWebP scans a reader for WebP RIFF chunks. xmpFn and exifFn are run at their respective
+// positions during the scan. Returns Metadata.
+//
+// Returns the error ErrNoRIFFHeader if the RIFF WebP header was not found, and ErrNoExif
+// if the EXIF chunk was not found.
+func ScanWebP(mr meta.Reader, exifFn func(r io.Reader, header meta.ExifHeader) error, xmpFn func(r io.Reader, header meta.XmpHeader) error) (m Metadata, err error) {
+	m = newMetadata(mr, exifFn, xmpFn)
+
+	var buf []byte
+	if buf, err = m.br.Peek(riffHeaderLength); err != nil || !isRIFFWebPHeader(buf) {
+		err = ErrNoRIFFHeader
+		return
+	}
+	if err = m.discard(riffHeaderLength); err != nil {
+		return
+	}
+
+	for {
+		if buf, err = m.br.Peek(chunkHeaderLength); err != nil {
+			break
+		}
+		if err = m.readChunk(buf); err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		err = nil
+	}
+	if err == nil && !m.ExifHeader.IsValid() {
+		err = ErrNoExif
+	}
+	return
+}
+
+// readChunk reads a RIFF chunk header and dispatches the chunk
+// payload to the corresponding reader.
+func (m *Metadata) readChunk(buf []byte) (err error) {
+	fourCC := chunkFourCC{buf[0], buf[1], buf[2], buf[3]}
+	size := riffByteOrder.Uint32(buf[4:8])
+
+	if err = m.discard(chunkHeaderLength); err != nil {
+		return
+	}
+
+	switch fourCC {
+	case fourCCVP8X:
+		return m.readVP8X(size)
+	case fourCCVP8:
+		return m.readVP8(size)
+	case fourCCVP8L:
+		return m.readVP8L(size)
+	case fourCCICCP:
+		m.iccOffset, m.iccLength = m.discarded, size
+	case fourCCANIM:
+		m.animated = true
+	case fourCCANMF:
+		m.frames++
+	case fourCCEXIF:
+		return m.readExif(size)
+	case fourCCXMP:
+		return m.readXMP(size)
+	}
+	return m.discardChunk(size)
+}
+
+// readVP8X reads the canvas size from the extended format VP8X chunk.
+func (m *Metadata) readVP8X(size uint32) (err error) {
+	if size < vp8xChunkLength {
+		return m.discardChunk(size)
+	}
+	var buf []byte
+	if buf, err = m.br.Peek(vp8xChunkLength); err != nil {
+		return
+	}
+	m.animated = buf[0]&vp8xFlagAnimation != 0
+	// Canvas Width Minus One and Canvas Height Minus One are 24bit values
+	m.width = (uint32(buf[4]) | uint32(buf[5])<<8 | uint32(buf[6])<<16) + 1
+	m.height = (uint32(buf[7]) | uint32(buf[8])<<8 | uint32(buf[9])<<16) + 1
+	return m.discardChunk(size)
+}
+
+// readVP8 reads the frame size from a lossy VP8 bitstream chunk.
+// The VP8X canvas size takes precedence when present.
+func (m *Metadata) readVP8(size uint32) (err error) {
+	if size < vp8FrameHeaderLength || m.width > 0 {
+		return m.discardChunk(size)
+	}
+	var buf []byte
+	if buf, err = m.br.Peek(vp8FrameHeaderLength); err != nil {
+		return
+	}
+	// Frame tag (3 bytes) followed by the start code 0x9d 0x01 0x2a
+	if buf[3] == 0x9d && buf[4] == 0x01 && buf[5] == 0x2a {
+		m.width = uint32(riffByteOrder.Uint16(buf[6:8]) & 0x3fff)
+		m.height = uint32(riffByteOrder.Uint16(buf[8:10]) & 0x3fff)
+	}
+	return m.discardChunk(size)
+}
+
+// readVP8L reads the image size from a lossless VP8L bitstream chunk.
+// The VP8X canvas size takes precedence when present.
+func (m *Metadata) readVP8L(size uint32) (err error) {
+	if size < vp8lHeaderLength || m.width > 0 {
+		return m.discardChunk(size)
+	}
+	var buf []byte
+	if buf, err = m.br.Peek(vp8lHeaderLength); err != nil {
+		return
+	}
+	// Signature 0x2f followed by 14bit width minus one and 14bit height minus one
+	if buf[0] == 0x2f {
+		bits := riffByteOrder.Uint32(buf[1:5])
+		m.width = bits&0x3fff + 1
+		m.height = (bits>>14)&0x3fff + 1
+	}
+	return m.discardChunk(size)
+}
+
+// readExif reads the Exif header from the EXIF chunk with the attached
+// metadata exifFn. If the function is nil it discards the chunk.
+func (m *Metadata) readExif(size uint32) (err error) {
+	remain := int(size)
+	var buf []byte
+	if buf, err = m.br.Peek(exifPrefixLength + 8); err != nil {
+		return
+	}
+
+	// Some encoders write the JPEG APP1 "Exif\0\0" prefix before the Tiff Header
+	if isExifPrefix(buf) && remain > exifPrefixLength {
+		if err = m.discard(exifPrefixLength); err != nil {
+			return
+		}
+		remain -= exifPrefixLength
+		buf = buf[exifPrefixLength:]
+	}
+
+	// Create a TiffHeader from the Tiff directory ByteOrder, root IFD Offset,
+	// the tiff Header Offset, and the length of the exif information.
+	byteOrder := meta.BinaryOrder(buf)
+	if byteOrder == nil {
+		return m.discard(remain + int(size&1))
+	}
+	firstIfdOffset := byteOrder.Uint32(buf[4:8])
+	exifLength := uint32(remain)
+
+	m.ExifHeader = meta.NewExifHeader(byteOrder, firstIfdOffset, m.discarded, exifLength, imagetype.ImageWebP)
+
+	// Read Exif
+	if m.exifFn != nil {
+		r := io.LimitReader(m.br, int64(exifLength))
+		if err = m.exifFn(r, m.ExifHeader); err != nil {
+			return err
+		}
+		// Discard remaining bytes
+		remain = int(r.(*io.LimitedReader).N)
+		m.discarded += exifLength - uint32(remain)
+	}
+	return m.discard(remain + int(size&1))
+}
+
+// readXMP reads the XMP chunk with the attached metadata xmpFn.
+// If the function is nil it discards the chunk.
+func (m *Metadata) readXMP(size uint32) (err error) {
+	remain := int(size)
+	m.XmpHeader = meta.NewXMPHeader(m.discarded, size)
+
+	// Read XMP Decode Function here
+	if m.xmpFn != nil {
+		r := io.LimitReader(m.br, int64(remain))
+		if err = m.xmpFn(r, m.XmpHeader); err != nil {
+			return err
+		}
+		// Discard remaining bytes
+		remain = int(r.(*io.LimitedReader).N)
+		m.discarded += size - uint32(remain)
+	}
+	return m.discard(remain + int(size&1))
+}
+
+// discardChunk discards the remaining chunk payload. RIFF chunks are padded
+// to an even length, the padding byte is not included in the chunk size.
+func (m *Metadata) discardChunk(size uint32) error {
+	return m.discard(int(size + size&1))
+}
+
+// discard adds to m.discarded and discards from the underlying bufio.Reader
+func (m *Metadata) discard(i int) (err error) {
+	if i == 0 {
+		return
+	}
+	i, err = m.br.Discard(i)
+	m.discarded += uint32(i)
+	return
+}
+
+// chunkFourCC is the 4 byte identifier of a RIFF chunk
+type chunkFourCC [4]byte
+
+// WebP Chunk FourCCs
+var (
+	fourCCVP8X = chunkFourCC{'V', 'P', '8', 'X'}
+	fourCCVP8  = chunkFourCC{'V', 'P', '8', ' '}
+	fourCCVP8L = chunkFourCC{'V', 'P', '8', 'L'}
+	fourCCEXIF = chunkFourCC{'E', 'X', 'I', 'F'}
+	fourCCXMP  = chunkFourCC{'X', 'M', 'P', ' '}
+)
+
+// Header and chunk lengths
+const (
+	riffHeaderLength     = 12
+	chunkHeaderLength    = 8
+	vp8xChunkLength      = 10
+	vp8FrameHeaderLength = 10
+	vp8lHeaderLength     = 5
+	exifPrefixLength     = 6
+)
+
+// riffByteOrder RIFF always uses a LittleEndian byteorder for chunk sizes.
+// Can use either byteorder for Exif Information inside the EXIF chunk.
+var riffByteOrder = binary.LittleEndian
+
+// isRIFFWebPHeader returns true if
+// buf[0:4] equals "RIFF" and buf[8:12] equals "WEBP"
+func isRIFFWebPHeader(buf []byte) bool {
+	return buf[0] == 'R' &&
+		buf[1] == 'I' &&
+		buf[2] == 'F' &&
+		buf[3] == 'F' &&
+		buf[8] == 'W' &&
+		buf[9] == 'E' &&
+		buf[10] == 'B' &&
+		buf[11] == 'P'
+}
+
+// isExifPrefix returns true if
+// buf[0:6] equals "Exif" and '0', '0'
+func isExifPrefix(buf []byte) bool {
+	return buf[0] == 'E' &&
+		buf[1] == 'x' &&
+		buf[2] == 'i' &&
+		buf[3] == 'f' &&
+		buf[4] == 0x00 &&
+		buf[5] == 0x00
+}
@@ -0,0 +1,143 @@
+package webp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// tiffMake is a minimal LittleEndian Tiff directory with a single
+// IFD0 Make tag ("abc") embedded in the value offset.
+var tiffMake = []byte{
+	'I', 'I', 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00, // Tiff Header
+	0x01, 0x00, // Tag Count
+	0x0f, 0x01, 0x02, 0x00, 0x04, 0x00, 0x00, 0x00, 'a', 'b', 'c', 0x00, // Make
+	0x00, 0x00, 0x00, 0x00, // Next Ifd
+}
+
+func chunk(fourCC string, payload []byte) []byte {
+	buf := make([]byte, 8, 8+len(payload)+1)
+	copy(buf, fourCC)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(len(payload)))
+	buf = append(buf, payload...)
+	if len(payload)%2 == 1 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+func riff(chunks ...[]byte) []byte {
+	buf := []byte{'R', 'I', 'F', 'F', 0, 0, 0, 0, 'W', 'E', 'B', 'P'}
+	for _, c := range chunks {
+		buf = append(buf, c...)
+	}
+	binary.LittleEndian.PutUint32(buf[4:], uint32(len(buf)-8))
+	return buf
+}
+
+func TestScanWebP(t *testing.T) {
+	vp8x := []byte{0x08, 0, 0, 0, 0x1f, 0x00, 0x00, 0x0f, 0x00, 0x00} // 32x16
+	vp8l := []byte{0x2f, 0x3f, 0xc0, 0x0f, 0x00}                      // 64x64
+	vp8 := []byte{0, 0, 0, 0x9d, 0x01, 0x2a, 0x80, 0x00, 0x40, 0x00}  // 128x64
+	xmpPacket := []byte("<x:xmpmeta></x:xmpmeta>")
+
+	testWebPs := []struct {
+		name   string
+		data   []byte
+		err    error
+		width  uint32
+		height uint32
+		xmp    bool
+	}{
+		{"Extended", riff(chunk("VP8X", vp8x), chunk("ICCP", []byte{1, 2, 3}), chunk("VP8L", vp8l), chunk("EXIF", tiffMake), chunk("XMP ", xmpPacket)), nil, 32, 16, true},
+		{"ExtendedExifPrefix", riff(chunk("VP8X", vp8x), chunk("VP8 ", vp8), chunk("EXIF", append([]byte("Exif\x00\x00"), tiffMake...))), nil, 32, 16, false},
+		{"Lossless", riff(chunk("VP8L", vp8l)), ErrNoExif, 64, 64, false},
+		{"Lossy", riff(chunk("VP8 ", vp8)), ErrNoExif, 128, 64, false},
+		{"NoRIFF", []byte("RIFX0000WEBPVP8 0000"), ErrNoRIFFHeader, 0, 0, false},
+	}
+
+	for _, wp := range testWebPs {
+		t.Run(wp.name, func(t *testing.T) {
+			var xmpData []byte
+			exifFn := func(r io.Reader, header meta.ExifHeader) error {
+				if header.ImageType != imagetype.ImageWebP {
+					t.Errorf("Incorrect Exif Header Imagetype wanted %s got %s", imagetype.ImageWebP, header.ImageType)
+				}
+				return nil
+			}
+			xmpFn := func(r io.Reader, header meta.XmpHeader) (err error) {
+				xmpData, err = io.ReadAll(r)
+				return err
+			}
+
+			m, err := ScanWebP(bytes.NewReader(wp.data), exifFn, xmpFn)
+			if err != wp.err {
+				t.Fatalf("Incorrect error wanted %v got %v", wp.err, err)
+			}
+			if w, h := m.Dimensions().Size(); w != wp.width || h != wp.height {
+				t.Errorf("Incorrect WebP Image size wanted %dx%d got %dx%d", wp.width, wp.height, w, h)
+			}
+			if wp.xmp && !bytes.Equal(xmpData, xmpPacket) {
+				t.Errorf("Incorrect XMP wanted %q got %q", xmpPacket, xmpData)
+			}
+			if err != nil {
+				return
+			}
+
+			e, err := exif.ParseExif(bytes.NewReader(wp.data), m.ExifHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.CameraMake() != "abc" {
+				t.Errorf("Incorrect Camera Make wanted %s got %s", "abc", e.CameraMake())
+			}
+		})
+	}
+}
+
+func TestScanWebPAnimated(t *testing.T) {
+	vp8x := []byte{0x2a, 0, 0, 0, 0x1f, 0x00, 0x00, 0x0f, 0x00, 0x00} // 32x16, ICC, Exif and Animation flags
+	vp8l := []byte{0x2f, 0x3f, 0xc0, 0x0f, 0x00}                      // 64x64
+	anmf := append([]byte{0, 0, 0, 0, 0, 0, 0x1f, 0, 0, 0x0f, 0, 0, 0x64, 0, 0, 0}, chunk("VP8L", vp8l)...)
+	icc := []byte("ICC profile")
+
+	data := riff(chunk("VP8X", vp8x), chunk("ICCP", icc), chunk("ANIM", []byte{0, 0, 0, 0, 0, 0}),
+		chunk("ANMF", anmf), chunk("ANMF", anmf), chunk("EXIF", tiffMake))
+	m, err := ScanWebP(bytes.NewReader(data), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w, h := m.Dimensions().Size(); w != 32 || h != 16 {
+		t.Errorf("Incorrect WebP Image size wanted %dx%d got %dx%d", 32, 16, w, h)
+	}
+	if !m.Animated() || m.Frames() != 2 {
+		t.Errorf("Incorrect animation wanted %t with %d frames got %t with %d frames", true, 2, m.Animated(), m.Frames())
+	}
+	if buf, err := m.ICCProfile(); err != nil || !bytes.Equal(buf, icc) {
+		t.Errorf("Incorrect ICC profile wanted %q got %q (%v)", icc, buf, err)
+	}
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraMake() != "abc" {
+		t.Errorf("Incorrect Camera Make wanted %s got %s", "abc", e.CameraMake())
+	}
+
+	// Still image
+	m, err = ScanWebP(bytes.NewReader(riff(chunk("VP8L", vp8l))), nil, nil)
+	if err != ErrNoExif {
+		t.Fatalf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+	if m.Animated() || m.Frames() != 0 {
+		t.Errorf("Incorrect animation wanted %t with %d frames got %t with %d frames", false, 0, m.Animated(), m.Frames())
+	}
+	if _, err = m.ICCProfile(); err != ErrNoICCProfile {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoICCProfile, err)
+	}
+}
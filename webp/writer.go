@@ -0,0 +1,252 @@
+package webp
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+)
+
+// Writer errors
+var (
+	// ErrCorruptChunk is returned when a chunk is truncated or a simple
+	// format WebP does not have a VP8 or VP8L chunk with the image size.
+	ErrCorruptChunk = errors.New("corrupt WebP chunk")
+
+	// ErrChunkTooLarge is returned when the WebP is larger than a RIFF container.
+	ErrChunkTooLarge = errors.New("WebP too large")
+)
+
+// Additional WebP Chunk FourCCs
+var (
+	fourCCICCP = chunkFourCC{'I', 'C', 'C', 'P'}
+	fourCCANIM = chunkFourCC{'A', 'N', 'I', 'M'}
+	fourCCALPH = chunkFourCC{'A', 'L', 'P', 'H'}
+	fourCCANMF = chunkFourCC{'A', 'N', 'M', 'F'}
+)
+
+// VP8X flags
+const (
+	vp8xFlagAnimation = 0x02
+	vp8xFlagXMP       = 0x04
+	vp8xFlagExif      = 0x08
+	vp8xFlagAlpha     = 0x10
+)
+
+// RewriteOptions are the metadata chunks written by Rewrite.
+// A nil field keeps the chunks of the WebP unchanged.
+type RewriteOptions struct {
+	// Exif is a Tiff/Exif block, as returned by exif.Builder.Encode.
+	Exif []byte
+
+	// XMP is an XMP packet, as returned by xmp.Marshal.
+	XMP []byte
+}
+
+// riffChunk is a RIFF chunk without its padding byte.
+type riffChunk struct {
+	fourCC chunkFourCC
+	data   []byte
+}
+
+// Rewrite copies the WebP from r to w with the EXIF and XMP chunks from opts.
+// Existing chunks are replaced in place, missing chunks are inserted after the
+// image data in the order EXIF, XMP. The Exif and XMP flags of the VP8X chunk are
+// set, a simple format WebP is converted to the extended format with a VP8X chunk
+// with the canvas size of the image. The RIFF size is updated.
+//
+// The WebP is read into memory. All other chunks are copied unchanged.
+// Returns ErrNoRIFFHeader if r is not a WebP and ErrCorruptChunk if a chunk is truncated.
+func Rewrite(r io.Reader, w io.Writer, opts RewriteOptions) error {
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(buf) < riffHeaderLength || !isRIFFWebPHeader(buf) {
+		return ErrNoRIFFHeader
+	}
+	chunks, err := readChunks(buf[riffHeaderLength:])
+	if err != nil {
+		return err
+	}
+	if opts.Exif == nil && opts.XMP == nil {
+		_, err = w.Write(buf)
+		return err
+	}
+
+	if opts.Exif != nil {
+		chunks = setChunk(chunks, riffChunk{fourCCEXIF, opts.Exif})
+	}
+	if opts.XMP != nil {
+		chunks = setChunk(chunks, riffChunk{fourCCXMP, opts.XMP}, fourCCEXIF)
+	}
+	if chunks[0].fourCC != fourCCVP8X {
+		vp8x, err := newVP8X(chunks)
+		if err != nil {
+			return err
+		}
+		chunks = append([]riffChunk{vp8x}, chunks...)
+	}
+	// The VP8X chunk is copied so that buf is not changed
+	vp8x := append([]byte(nil), chunks[0].data...)
+	if len(vp8x) < vp8xChunkLength {
+		return ErrCorruptChunk
+	}
+	for _, c := range chunks {
+		switch c.fourCC {
+		case fourCCEXIF:
+			vp8x[0] |= vp8xFlagExif
+		case fourCCXMP:
+			vp8x[0] |= vp8xFlagXMP
+		}
+	}
+	chunks[0].data = vp8x
+
+	return writeChunks(w, chunks)
+}
+
+// readChunks returns the chunks of the RIFF payload in buf.
+func readChunks(buf []byte) (chunks []riffChunk, err error) {
+	for len(buf) > 0 {
+		if len(buf) < chunkHeaderLength {
+			return nil, ErrCorruptChunk
+		}
+		size := uint64(riffByteOrder.Uint32(buf[4:8]))
+		if uint64(len(buf)-chunkHeaderLength) < size {
+			return nil, ErrCorruptChunk
+		}
+		chunks = append(chunks, riffChunk{
+			fourCC: chunkFourCC{buf[0], buf[1], buf[2], buf[3]},
+			data:   buf[chunkHeaderLength : chunkHeaderLength+size],
+		})
+		// The padding byte of the last chunk may be missing
+		size += size & 1
+		if size > uint64(len(buf)-chunkHeaderLength) {
+			size = uint64(len(buf) - chunkHeaderLength)
+		}
+		buf = buf[chunkHeaderLength+size:]
+	}
+	if len(chunks) == 0 {
+		return nil, ErrCorruptChunk
+	}
+	return chunks, nil
+}
+
+// setChunk replaces the first chunk with the FourCC of c and removes the others.
+// If there is no chunk with the FourCC of c, c is inserted after the last header
+// or image data chunk or the last chunk with a FourCC of after.
+func setChunk(chunks []riffChunk, c riffChunk, after ...chunkFourCC) []riffChunk {
+	out := make([]riffChunk, 0, len(chunks)+1)
+	insert, replaced := 0, false
+	for _, chunk := range chunks {
+		if chunk.fourCC == c.fourCC {
+			if !replaced {
+				out = append(out, c)
+				replaced = true
+			}
+			continue
+		}
+		out = append(out, chunk)
+		if isImageChunk(chunk.fourCC) || hasFourCC(after, chunk.fourCC) {
+			insert = len(out)
+		}
+	}
+	if replaced {
+		return out
+	}
+	out = append(out, riffChunk{})
+	copy(out[insert+1:], out[insert:])
+	out[insert] = c
+	return out
+}
+
+// newVP8X returns the VP8X chunk of a simple format WebP with the canvas size
+// and alpha of the VP8 or VP8L chunk.
+func newVP8X(chunks []riffChunk) (riffChunk, error) {
+	var width, height uint32
+	var flags byte
+	for _, c := range chunks {
+		switch {
+		case c.fourCC == fourCCVP8 && len(c.data) >= vp8FrameHeaderLength &&
+			c.data[3] == 0x9d && c.data[4] == 0x01 && c.data[5] == 0x2a:
+			width = uint32(riffByteOrder.Uint16(c.data[6:8]) & 0x3fff)
+			height = uint32(riffByteOrder.Uint16(c.data[8:10]) & 0x3fff)
+		case c.fourCC == fourCCVP8L && len(c.data) >= vp8lHeaderLength && c.data[0] == 0x2f:
+			bits := riffByteOrder.Uint32(c.data[1:5])
+			width = bits&0x3fff + 1
+			height = (bits>>14)&0x3fff + 1
+			if bits>>28&1 == 1 {
+				flags |= vp8xFlagAlpha
+			}
+		case c.fourCC == fourCCALPH:
+			flags |= vp8xFlagAlpha
+		}
+	}
+	if width == 0 || height == 0 {
+		return riffChunk{}, ErrCorruptChunk
+	}
+	data := make([]byte, vp8xChunkLength)
+	data[0] = flags
+	putUint24(data[4:7], width-1)
+	putUint24(data[7:10], height-1)
+	return riffChunk{fourCCVP8X, data}, nil
+}
+
+// writeChunks writes the RIFF header and chunks to w.
+func writeChunks(w io.Writer, chunks []riffChunk) error {
+	size := uint64(4) // "WEBP"
+	for _, c := range chunks {
+		size += chunkHeaderLength + uint64(len(c.data)+len(c.data)&1)
+	}
+	if size > math.MaxUint32-1 {
+		return ErrChunkTooLarge
+	}
+	bw := bufio.NewWriter(w)
+	header := []byte{'R', 'I', 'F', 'F', 0, 0, 0, 0, 'W', 'E', 'B', 'P'}
+	riffByteOrder.PutUint32(header[4:8], uint32(size))
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		var h [chunkHeaderLength]byte
+		copy(h[:4], c.fourCC[:])
+		riffByteOrder.PutUint32(h[4:], uint32(len(c.data)))
+		if _, err := bw.Write(h[:]); err != nil {
+			return err
+		}
+		if _, err := bw.Write(c.data); err != nil {
+			return err
+		}
+		if len(c.data)&1 == 1 {
+			if err := bw.WriteByte(0); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// putUint24 puts the 24bit value v in buf in the RIFF byte order.
+func putUint24(buf []byte, v uint32) {
+	buf[0], buf[1], buf[2] = byte(v), byte(v>>8), byte(v>>16)
+}
+
+// isImageChunk returns true if fourCC is a header or image data chunk.
+// These chunks come before the metadata chunks.
+func isImageChunk(fourCC chunkFourCC) bool {
+	switch fourCC {
+	case fourCCVP8X, fourCCICCP, fourCCANIM, fourCCVP8, fourCCVP8L, fourCCALPH, fourCCANMF:
+		return true
+	}
+	return false
+}
+
+// hasFourCC returns true if list has fourCC.
+func hasFourCC(list []chunkFourCC, fourCC chunkFourCC) bool {
+	for _, f := range list {
+		if f == fourCC {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package webp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// fourCCs returns the FourCCs of the chunks of a WebP.
+func fourCCs(t *testing.T, buf []byte) (list []string) {
+	t.Helper()
+	if size := riffByteOrder.Uint32(buf[4:8]); int(size) != len(buf)-8 {
+		t.Errorf("Incorrect RIFF size wanted %d got %d", len(buf)-8, size)
+	}
+	chunks, err := readChunks(buf[riffHeaderLength:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range chunks {
+		list = append(list, string(c.fourCC[:]))
+	}
+	return list
+}
+
+func TestRewrite(t *testing.T) {
+	vp8x := []byte{0x20, 0, 0, 0, 0x1f, 0x00, 0x00, 0x0f, 0x00, 0x00} // 32x16 ICC
+	vp8l := []byte{0x2f, 0x3f, 0xc0, 0x0f, 0x10}                      // 64x64 alpha
+	vp8 := []byte{0, 0, 0, 0x9d, 0x01, 0x2a, 0x80, 0x00, 0x40, 0x00}  // 128x64
+	xmpPacket := []byte("<x:xmpmeta></x:xmpmeta>")                    // odd length
+
+	tests := []struct {
+		name    string
+		data    []byte
+		fourCCs []string
+		flags   byte
+		width   uint32
+		height  uint32
+	}{
+		{"Lossless", riff(chunk("VP8L", vp8l)), []string{"VP8X", "VP8L", "EXIF", "XMP "}, vp8xFlagAlpha | vp8xFlagExif | vp8xFlagXMP, 64, 64},
+		{"Lossy", riff(chunk("VP8 ", vp8)), []string{"VP8X", "VP8 ", "EXIF", "XMP "}, vp8xFlagExif | vp8xFlagXMP, 128, 64},
+		{"Extended", riff(chunk("VP8X", vp8x), chunk("ICCP", []byte{1, 2, 3}), chunk("VP8L", vp8l), chunk("ABCD", []byte{1})), []string{"VP8X", "ICCP", "VP8L", "EXIF", "XMP ", "ABCD"}, 0x20 | vp8xFlagExif | vp8xFlagXMP, 32, 16},
+		{"Replace", riff(chunk("VP8X", vp8x), chunk("VP8L", vp8l), chunk("XMP ", []byte("x")), chunk("EXIF", []byte{1, 2, 3}), chunk("EXIF", nil)), []string{"VP8X", "VP8L", "XMP ", "EXIF"}, 0x20 | vp8xFlagExif | vp8xFlagXMP, 32, 16},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Rewrite(bytes.NewReader(test.data), &out, RewriteOptions{Exif: tiffMake, XMP: xmpPacket}); err != nil {
+				t.Fatal(err)
+			}
+			buf := out.Bytes()
+			if list := fourCCs(t, buf); len(list) != len(test.fourCCs) {
+				t.Errorf("Incorrect chunks wanted %q got %q", test.fourCCs, list)
+			} else {
+				for i := range list {
+					if list[i] != test.fourCCs[i] {
+						t.Errorf("Incorrect chunks wanted %q got %q", test.fourCCs, list)
+						break
+					}
+				}
+			}
+			if flags := buf[riffHeaderLength+chunkHeaderLength]; flags != test.flags {
+				t.Errorf("Incorrect VP8X flags wanted %08b got %08b", test.flags, flags)
+			}
+
+			var xmpData []byte
+			xmpFn := func(r io.Reader, header meta.XmpHeader) (err error) {
+				xmpData, err = io.ReadAll(r)
+				return err
+			}
+			m, err := ScanWebP(bytes.NewReader(buf), nil, xmpFn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w, h := m.Dimensions().Size(); w != test.width || h != test.height {
+				t.Errorf("Incorrect WebP Image size wanted %dx%d got %dx%d", test.width, test.height, w, h)
+			}
+			if !bytes.Equal(xmpData, xmpPacket) {
+				t.Errorf("Incorrect XMP wanted %q got %q", xmpPacket, xmpData)
+			}
+			e, err := exif.ParseExif(bytes.NewReader(buf), m.ExifHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.CameraMake() != "abc" {
+				t.Errorf("Incorrect Camera Make wanted %s got %s", "abc", e.CameraMake())
+			}
+		})
+	}
+
+	// Without metadata the WebP is copied unchanged
+	src := riff(chunk("VP8L", vp8l))
+	var out bytes.Buffer
+	if err := Rewrite(bytes.NewReader(src), &out, RewriteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, out.Bytes()) {
+		t.Errorf("Rewrite without metadata should copy the WebP unchanged")
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"NoRIFF", []byte("RIFX0000WEBPVP8 0000"), ErrNoRIFFHeader},
+		{"Truncated", riff(chunk("VP8L", []byte{0x2f, 0x3f, 0xc0, 0x0f, 0x10}))[:18], ErrCorruptChunk},
+		{"Empty", riff(), ErrCorruptChunk},
+		{"NoImageSize", riff(chunk("VP8 ", []byte{0, 0, 0})), ErrCorruptChunk},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Rewrite(bytes.NewReader(test.data), &out, RewriteOptions{Exif: tiffMake}); err != test.err {
+				t.Errorf("Incorrect error wanted %v got %v", test.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package imagemeta
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// WriteGPS copies the JPEG from r to w with the GPS coordinates lat and lng in
+// decimal degrees and the altitude alt in meters set in its Exif. The GPS IFD and
+// the Exif are created if the JPEG does not have them.
+//
+// The Exif is encoded again with exif.NewBuilderFromData.
+// Returns ErrMetadataNotSupported if r is not a JPEG and exif.ErrGpsCoordsNotValid
+// for invalid coordinates.
+func WriteGPS(r meta.Reader, w io.Writer, lat, lng, alt float64) error {
+	return rewriteExif(r, w, func(b *exif.Builder) error {
+		if err := b.SetGPSCoords(lat, lng); err != nil {
+			return err
+		}
+		return b.SetGPSAltitude(alt)
+	})
+}
+
+// WriteThumbnail copies the JPEG from r to w with thumbnail set as the JPEG
+// thumbnail of IFD1 in its Exif. The Exif is created if the JPEG does not have it.
+//
+// The Exif is encoded again with exif.NewBuilderFromData.
+// Returns ErrMetadataNotSupported if r is not a JPEG, exif.ErrThumbnailNotJPEG if
+// thumbnail is not a JPEG and jpeg.ErrSegmentTooLarge if the Exif with the thumbnail
+// is larger than a JPEG segment.
+func WriteThumbnail(r meta.Reader, w io.Writer, thumbnail []byte) error {
+	return rewriteExif(r, w, func(b *exif.Builder) error {
+		return b.SetThumbnail(thumbnail)
+	})
+}
+
+// WriteIPTC copies the JPEG from r to w with the IPTC-IIM DataSets of its Photoshop
+// APP13 segment set to i with iptc.Merge. DataSets that are not in iptc.IPTC are kept.
+// The segment is created if the JPEG does not have it. The other Photoshop image
+// resources and the rest of the JPEG are copied unchanged.
+//
+// Returns ErrMetadataNotSupported if r is not a JPEG and iptc.ErrDataSetTooLarge
+// if a value of i is too large.
+func WriteIPTC(r meta.Reader, w io.Writer, i iptc.IPTC) error {
+	t, err := imagetype.ReadAt(r)
+	if err != nil {
+		return err
+	}
+	if t != imagetype.ImageJPEG {
+		return ErrMetadataNotSupported
+	}
+	record, err := jpeg.ReadIPTC(io.NewSectionReader(r, 0, math.MaxInt64))
+	if err != nil && err != jpeg.ErrNoIPTC {
+		return err
+	}
+	buf, err := iptc.Merge(record, i)
+	if err != nil {
+		return err
+	}
+	return jpeg.Rewrite(io.NewSectionReader(r, 0, math.MaxInt64), w, jpeg.RewriteOptions{IPTC: buf})
+}
+
+// WriteRating copies the JPEG from r to w with xmp:Rating set to rating and xmp:Label
+// set to label in its XMP, for culling tools. An empty label removes xmp:Label.
+// The XMP is created if the JPEG does not have it. The other XMP properties and
+// the rest of the JPEG are copied unchanged.
+//
+// Use xmp.WriteSidecarRating for images, such as camera raw files, with an XMP sidecar.
+// Returns ErrMetadataNotSupported if r is not a JPEG and xmp.ErrRatingNotValid
+// for an invalid rating.
+func WriteRating(r meta.Reader, w io.Writer, rating int8, label string) error {
+	t, err := imagetype.ReadAt(r)
+	if err != nil {
+		return err
+	}
+	if t != imagetype.ImageJPEG {
+		return ErrMetadataNotSupported
+	}
+	m, err := jpeg.ScanJPEG(r, nil, nil)
+	if err != nil && !errors.Is(err, ErrNoExif) {
+		return err
+	}
+	var packet []byte
+	if m.XmpHeader.Length > 0 {
+		if packet, err = readXMPPacket(r, m.XmpHeader); err != nil {
+			return err
+		}
+	}
+	if packet, err = xmp.SetRating(packet, rating, label); err != nil {
+		return err
+	}
+	return jpeg.Rewrite(io.NewSectionReader(r, 0, math.MaxInt64), w, jpeg.RewriteOptions{XMP: packet})
+}
+
+// rewriteExif copies the JPEG from r to w with its Exif changed by fn.
+// fn is called with an empty Builder if the JPEG does not have Exif.
+func rewriteExif(r meta.Reader, w io.Writer, fn func(b *exif.Builder) error) error {
+	b, err := jpegExifBuilder(r)
+	if err != nil {
+		return err
+	}
+	if err = fn(b); err != nil {
+		return err
+	}
+	buf, err := b.Encode()
+	if err != nil {
+		return err
+	}
+	return jpeg.Rewrite(io.NewSectionReader(r, 0, math.MaxInt64), w, jpeg.RewriteOptions{Exif: buf})
+}
+
+// jpegExifBuilder returns a Builder with the Exif of the JPEG in r.
+func jpegExifBuilder(r meta.Reader) (*exif.Builder, error) {
+	t, err := imagetype.ReadAt(r)
+	if err != nil {
+		return nil, err
+	}
+	if t != imagetype.ImageJPEG {
+		return nil, ErrMetadataNotSupported
+	}
+	m, err := jpeg.ScanJPEG(r, nil, nil)
+	if errors.Is(err, ErrNoExif) {
+		return exif.NewBuilder(nil), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e, err := exif.ParseExif(r, m.ExifHeader)
+	if err != nil {
+		return nil, err
+	}
+	return exif.NewBuilderFromData(e)
+}
@@ -0,0 +1,194 @@
+package imagemeta
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/iptc"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/xmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteGPS(t *testing.T) {
+	for _, filename := range []string{"testImages/JPEG.jpg", "testImages/NoExif.jpg"} {
+		t.Run(filename, func(t *testing.T) {
+			buf, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err = WriteGPS(bytes.NewReader(buf), &out, -33.8568, 151.2153, 58.5); err != nil {
+				t.Fatal(err)
+			}
+			m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := exif.ParseExif(bytes.NewReader(out.Bytes()), m.ExifHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lat, lng, err := e.GPSCoords()
+			assert.NoError(t, err)
+			assert.InDelta(t, -33.8568, lat, 1e-7)
+			assert.InDelta(t, 151.2153, lng, 1e-7)
+			alt, err := e.GPSAltitude()
+			assert.NoError(t, err)
+			assert.InDelta(t, 58.5, alt, 1e-3)
+
+			// Other Exif tags are kept
+			src, err := Parse(bytes.NewReader(buf))
+			if err == nil {
+				want, err := src.Exif()
+				if assert.NoError(t, err) {
+					assert.Equal(t, want.CameraModel(), e.CameraModel())
+				}
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.ErrorIs(t, WriteGPS(bytes.NewReader(buf), &out, 91, 0, 0), exif.ErrGpsCoordsNotValid)
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, WriteGPS(f, &out, 0, 0, 0), ErrMetadataNotSupported)
+}
+
+func TestWriteIPTC(t *testing.T) {
+	i := iptc.IPTC{Caption: "Caption", Keywords: []string{"news"}, Credit: "Credit", Copyright: "Copyright"}
+	for _, filename := range []string{"testImages/JPEG.jpg", "testImages/NoExif.jpg"} {
+		t.Run(filename, func(t *testing.T) {
+			buf, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err = WriteIPTC(bytes.NewReader(buf), &out, i); err != nil {
+				t.Fatal(err)
+			}
+			record, err := jpeg.ReadIPTC(bytes.NewReader(out.Bytes()))
+			if assert.NoError(t, err, "IPTC records should be written") {
+				got, err := iptc.Decode(record)
+				assert.NoError(t, err)
+				assert.Equal(t, i, got)
+			}
+
+			// DataSets that are not in iptc.IPTC are kept, ie. 2:55 DateCreated
+			if src, err := jpeg.ReadIPTC(bytes.NewReader(buf)); err == nil {
+				dateCreated := []byte("\x1c\x02\x37\x00\x0820161011")
+				assert.True(t, bytes.Contains(src, dateCreated))
+				assert.True(t, bytes.Contains(record, dateCreated), "DateCreated should be kept")
+			}
+
+			// Exif is kept
+			_, wantErr := jpeg.ScanJPEG(bytes.NewReader(buf), nil, nil)
+			_, err = jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+			assert.Equal(t, wantErr, err)
+		})
+	}
+
+	var out bytes.Buffer
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, WriteIPTC(f, &out, i), ErrMetadataNotSupported)
+}
+
+func TestWriteRating(t *testing.T) {
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var noXMP bytes.Buffer
+	if err = jpeg.Strip(bytes.NewReader(buf), &noXMP, jpeg.StripOptions{KeepExif: true}); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range map[string][]byte{"XMP": buf, "NoXMP": noXMP.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteRating(bytes.NewReader(src), &out, 4, xmp.LabelRed); err != nil {
+				t.Fatal(err)
+			}
+			want, err := jpeg.ScanJPEG(bytes.NewReader(src), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantXMP, _ := want.Xmp()
+			wantXMP.Basic.Rating, wantXMP.Basic.Label = 4, xmp.LabelRed
+			x, err := m.Xmp()
+			assert.NoError(t, err)
+			assert.Equal(t, wantXMP, x)
+
+			// Exif is kept
+			e, err := exif.ParseExif(bytes.NewReader(out.Bytes()), m.ExifHeader)
+			if assert.NoError(t, err) {
+				assert.Equal(t, "GoPro", e.CameraMake())
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	assert.ErrorIs(t, WriteRating(bytes.NewReader(buf), &out, 6, ""), xmp.ErrRatingNotValid)
+	f, err := os.Open("testImages/GIF.gif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	assert.ErrorIs(t, WriteRating(f, &out, 1, ""), ErrMetadataNotSupported)
+}
+
+func TestWriteThumbnail(t *testing.T) {
+	thumbnail, err := os.ReadFile("assets/a1.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumbnail = thumbnail[:2048]
+	for _, filename := range []string{"testImages/JPEG.jpg", "testImages/NoExif.jpg"} {
+		t.Run(filename, func(t *testing.T) {
+			buf, err := os.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err = WriteThumbnail(bytes.NewReader(buf), &out, thumbnail); err != nil {
+				t.Fatal(err)
+			}
+			m, err := jpeg.ScanJPEG(bytes.NewReader(out.Bytes()), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := exif.ParseExif(bytes.NewReader(out.Bytes()), m.ExifHeader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tn, err := e.Thumbnail()
+			if assert.NoError(t, err) {
+				assert.Equal(t, thumbnail, tn)
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	buf, err := os.ReadFile("testImages/JPEG.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.ErrorIs(t, WriteThumbnail(bytes.NewReader(buf), &out, []byte("GIF89a")), exif.ErrThumbnailNotJPEG)
+	assert.ErrorIs(t, WriteThumbnail(bytes.NewReader(buf), &out, append([]byte{0xFF, 0xD8}, make([]byte, 0xFFFF)...)), jpeg.ErrSegmentTooLarge)
+}
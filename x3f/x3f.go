@@ -0,0 +1,393 @@
+// Package x3f decodes (X3F) Sigma Foveon Raw Metadata. An X3F file has a header with
+// the image dimensions and a directory of sections at the end of the file. The
+// sections are the image properties ("PROP"), the images ("IMAG" and "IMA2") and
+// the camera calibration ("CAMF"). All values are LittleEndian.
+//
+// Based on: the X3F file format specification of Sigma and Foveon (version 2.1 and 3.0)
+package x3f
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf16"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/exif/ifds"
+	"github.com/evanoberholster/imagemeta/exif/ifds/exififd"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp"
+)
+
+// Errors
+var (
+	ErrNoX3FHeader  = errors.New("no X3F Header")
+	ErrNoDirectory  = errors.New("error X3F directory not found")
+	ErrNoPreview    = errors.New("error X3F does not have a jpeg preview")
+	ErrNoExif       = meta.ErrNoExif
+	errSectionShort = errors.New("error X3F section is too short")
+)
+
+// Section identifiers
+var (
+	fileIdentifier        = []byte("FOVb")
+	directoryIdentifier   = []byte("SECd")
+	propertiesIdentifier  = []byte("SECp")
+	imageIdentifier       = []byte("SECi")
+	sectionTypeProperties = SectionType{'P', 'R', 'O', 'P'}
+	sectionTypeImage      = SectionType{'I', 'M', 'A', 'G'}
+	sectionTypeImage2     = SectionType{'I', 'M', 'A', '2'}
+)
+
+// Lengths
+const (
+	headerLength           = 40
+	directoryHeaderLength  = 12
+	directoryEntryLength   = 12
+	propertiesHeaderLength = 24
+	propertyEntryLength    = 8
+	imageHeaderLength      = 28
+
+	// maxDirectoryEntries limits the entries of a damaged directory
+	maxDirectoryEntries = 1024
+
+	// maxPropertiesLength limits the length of a damaged properties section
+	maxPropertiesLength = 1 << 20
+)
+
+// Image data formats
+const (
+	// FormatRGB is uncompressed 8 bit RGB image data
+	FormatRGB = 3
+	// FormatHuffman is Huffman encoded DPCM 8 bit RGB image data
+	FormatHuffman = 11
+	// FormatJPEG is JPEG compressed image data
+	FormatJPEG = 18
+)
+
+// Header is the header of an X3F file
+type Header struct {
+	// Version is the major and minor version of the X3F format
+	Version [2]uint16
+
+	// Columns and Rows are the dimensions of the image
+	Columns, Rows uint32
+
+	// Rotation is the clockwise rotation of the image in degrees
+	Rotation uint32
+}
+
+// SectionType is the 4 character type of a section of the X3F directory,
+// ie. "PROP", "IMAG", "IMA2" and "CAMF".
+type SectionType [4]byte
+
+func (st SectionType) String() string {
+	return string(st[:])
+}
+
+// Section is an entry of the X3F directory
+type Section struct {
+	Type   SectionType
+	Offset uint32
+	Length uint32
+}
+
+// Image is an image section of an X3F. Offset and Length are the location of the
+// image data.
+type Image struct {
+	Type          uint32
+	Format        uint32
+	Width, Height uint32
+	Offset        uint32
+	Length        uint32
+}
+
+// IsJPEG returns true if the image data of the Image is JPEG compressed
+func (img Image) IsJPEG() bool {
+	return img.Format == FormatJPEG
+}
+
+// Metadata is an X3F file's Metadata
+type Metadata struct {
+	mr     meta.Reader
+	Header Header
+
+	// Sections are the entries of the X3F directory
+	Sections []Section
+
+	// Images are the image sections of the X3F directory
+	Images []Image
+
+	properties map[string]string
+}
+
+// Dimensions returns the dimensions (width and height) of the image
+func (m Metadata) Dimensions() meta.Dimensions {
+	return meta.NewDimensions(m.Header.Columns, m.Header.Rows)
+}
+
+// ImageType returns imagetype.ImageX3F for Sigma X3F image
+func (m Metadata) ImageType() imagetype.ImageType {
+	return imagetype.ImageX3F
+}
+
+// PreviewImage returns the largest JPEG preview image. Returns the
+// X3F file if it does not have a JPEG preview.
+func (m Metadata) PreviewImage() io.Reader {
+	if img, err := m.LargestPreview(); err == nil {
+		return io.NewSectionReader(m.mr, int64(img.Offset), int64(img.Length))
+	}
+	_, _ = m.mr.Seek(0, 0)
+	return m.mr
+}
+
+// LargestPreview returns the JPEG compressed Image with the largest dimensions.
+//
+// Returns ErrNoPreview if the X3F does not have a JPEG preview.
+func (m Metadata) LargestPreview() (img Image, err error) {
+	err = ErrNoPreview
+	for _, i := range m.Images {
+		if i.IsJPEG() && uint64(i.Width)*uint64(i.Height) >= uint64(img.Width)*uint64(img.Height) {
+			img, err = i, nil
+		}
+	}
+	return img, err
+}
+
+// Exif returns the Exif data of the JPEG preview. X3F files without Exif in the
+// JPEG preview have Exif data that is built from the properties.
+//
+// Returns ErrNoExif if the X3F does not have Exif data or properties.
+func (m Metadata) Exif() (exif.Exif, error) {
+	if img, err := m.LargestPreview(); err == nil {
+		if j, err := jpeg.ScanJPEG(io.NewSectionReader(m.mr, int64(img.Offset), int64(img.Length)), nil, nil); err == nil {
+			if e, err := j.Exif(); err == nil {
+				return e, nil
+			}
+		}
+	}
+	if len(m.properties) == 0 {
+		return nil, ErrNoExif
+	}
+	buf, err := m.buildExif()
+	if err != nil {
+		return nil, err
+	}
+	return exif.ParseTIFF(bytes.NewReader(buf))
+}
+
+// Xmp returns parsed Xmp data from the JPEG preview.
+// Returns xmp.ErrNoXMP if the X3F does not have XMP metadata.
+func (m Metadata) Xmp() (xmp.XMP, error) {
+	img, err := m.LargestPreview()
+	if err != nil {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	j, err := jpeg.ScanJPEG(io.NewSectionReader(m.mr, int64(img.Offset), int64(img.Length)), nil, nil)
+	if err != nil || j.XmpHeader.Length == 0 {
+		return xmp.XMP{}, xmp.ErrNoXMP
+	}
+	return j.Xmp()
+}
+
+// Property returns the value of the property name of the "PROP" section,
+// ie. "CAMMODEL" or "EXPTIME". Returns false if the X3F does not have the property.
+func (m Metadata) Property(name string) (string, bool) {
+	v, ok := m.properties[name]
+	return v, ok
+}
+
+// Properties returns the properties of the "PROP" section
+func (m Metadata) Properties() map[string]string {
+	return m.properties
+}
+
+// buildExif returns a Tiff structured Exif block with the camera and exposure properties.
+func (m Metadata) buildExif() ([]byte, error) {
+	b := exif.NewBuilder(binary.LittleEndian)
+	var errs []error
+	if make, ok := m.properties["CAMMANUF"]; ok {
+		errs = append(errs, b.SetASCII(ifds.IFD0, 0, ifds.Make, make))
+	}
+	if model, ok := m.properties["CAMMODEL"]; ok {
+		errs = append(errs, b.SetASCII(ifds.IFD0, 0, ifds.Model, model))
+	}
+	if serial, ok := m.properties["CAMSERIAL"]; ok {
+		errs = append(errs, b.SetASCII(ifds.ExifIFD, 0, exififd.BodySerialNumber, serial))
+	}
+	if firmware, ok := m.properties["FIRMVERS"]; ok {
+		errs = append(errs, b.SetSoftware(firmware))
+	}
+	// Exposure time in microseconds
+	if v, err := strconv.ParseUint(m.properties["EXPTIME"], 10, 32); err == nil && v > 0 {
+		n, d := reduce(uint32(v), 1000000)
+		errs = append(errs, b.SetShutterSpeed(meta.NewShutterSpeed(n, d)))
+	}
+	if v, err := strconv.ParseFloat(m.properties["APERTURE"], 32); err == nil && v > 0 {
+		errs = append(errs, b.SetAperture(meta.Aperture(v)))
+	}
+	if v, err := strconv.ParseFloat(m.properties["FLENGTH"], 32); err == nil && v > 0 {
+		errs = append(errs, b.SetFocalLength(meta.FocalLength(v)))
+	}
+	if v, err := strconv.ParseUint(m.properties["ISO"], 10, 32); err == nil {
+		errs = append(errs, b.SetISOSpeed(uint32(v)))
+	}
+	// Capture time in seconds since the Unix epoch, in local time
+	if v, err := strconv.ParseInt(m.properties["TIME"], 10, 64); err == nil && v > 0 {
+		errs = append(errs, b.SetDateTime(time.Unix(v, 0).UTC()))
+	}
+	errs = append(errs, b.SetDimensions(m.Header.Columns, m.Header.Rows))
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return b.Encode()
+}
+
+// reduce returns the fraction n/d with the greatest common divisor removed
+func reduce(n, d uint32) (uint32, uint32) {
+	a, b := n, d
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return n / a, d / a
+}
+
+// Parse reads the X3F header, the directory, the image sections and the
+// properties of an X3F from mr. Returns Metadata.
+//
+// Returns the error ErrNoX3FHeader if mr does not begin with an X3F header, and
+// ErrNoDirectory if the directory at the end of the file is not valid. Damaged image
+// and properties sections are skipped.
+func Parse(mr meta.Reader) (m Metadata, err error) {
+	m = Metadata{mr: mr}
+
+	var buf [headerLength]byte
+	if _, err = mr.ReadAt(buf[:], 0); err != nil || !bytes.HasPrefix(buf[:], fileIdentifier) {
+		return m, ErrNoX3FHeader
+	}
+	m.Header.Version = [2]uint16{binary.LittleEndian.Uint16(buf[6:8]), binary.LittleEndian.Uint16(buf[4:6])}
+	// The unique identifier and mark bits follow the version
+	m.Header.Columns = binary.LittleEndian.Uint32(buf[28:32])
+	m.Header.Rows = binary.LittleEndian.Uint32(buf[32:36])
+	m.Header.Rotation = binary.LittleEndian.Uint32(buf[36:40])
+
+	if m.Sections, err = readDirectory(mr); err != nil {
+		return m, err
+	}
+	for _, s := range m.Sections {
+		switch s.Type {
+		case sectionTypeImage, sectionTypeImage2:
+			if img, err := readImage(mr, s); err == nil {
+				m.Images = append(m.Images, img)
+			}
+		case sectionTypeProperties:
+			m.properties, _ = readProperties(mr, s)
+		}
+	}
+	return m, nil
+}
+
+// readDirectory reads the directory, the offset of the directory is the last
+// 4 bytes of the file.
+func readDirectory(mr meta.Reader) ([]Section, error) {
+	size, err := mr.Seek(0, io.SeekEnd)
+	if err != nil || size < headerLength+4 {
+		return nil, ErrNoDirectory
+	}
+	var buf [directoryHeaderLength]byte
+	if _, err = mr.ReadAt(buf[:4], size-4); err != nil {
+		return nil, ErrNoDirectory
+	}
+	offset := int64(binary.LittleEndian.Uint32(buf[:4]))
+	if _, err = mr.ReadAt(buf[:], offset); err != nil || !bytes.HasPrefix(buf[:], directoryIdentifier) {
+		return nil, ErrNoDirectory
+	}
+	count := binary.LittleEndian.Uint32(buf[8:12])
+	if count > maxDirectoryEntries {
+		return nil, ErrNoDirectory
+	}
+	entries := make([]byte, count*directoryEntryLength)
+	if _, err = mr.ReadAt(entries, offset+directoryHeaderLength); err != nil {
+		return nil, ErrNoDirectory
+	}
+	sections := make([]Section, count)
+	for i := range sections {
+		entry := entries[i*directoryEntryLength:]
+		sections[i].Offset = binary.LittleEndian.Uint32(entry[0:4])
+		sections[i].Length = binary.LittleEndian.Uint32(entry[4:8])
+		copy(sections[i].Type[:], entry[8:12])
+	}
+	return sections, nil
+}
+
+// readImage reads the header of the image section s
+func readImage(mr meta.Reader, s Section) (img Image, err error) {
+	if s.Length < imageHeaderLength {
+		return img, errSectionShort
+	}
+	var buf [imageHeaderLength]byte
+	if _, err = mr.ReadAt(buf[:], int64(s.Offset)); err != nil {
+		return img, err
+	}
+	if !bytes.HasPrefix(buf[:], imageIdentifier) {
+		return img, errSectionShort
+	}
+	img.Type = binary.LittleEndian.Uint32(buf[8:12])
+	img.Format = binary.LittleEndian.Uint32(buf[12:16])
+	img.Width = binary.LittleEndian.Uint32(buf[16:20])
+	img.Height = binary.LittleEndian.Uint32(buf[20:24])
+	img.Offset = s.Offset + imageHeaderLength
+	img.Length = s.Length - imageHeaderLength
+	return img, nil
+}
+
+// readProperties reads the name and value pairs of the properties section s.
+// The names and values are null terminated UTF-16 strings.
+func readProperties(mr meta.Reader, s Section) (map[string]string, error) {
+	if s.Length < propertiesHeaderLength || s.Length > maxPropertiesLength {
+		return nil, errSectionShort
+	}
+	buf := make([]byte, s.Length)
+	if _, err := mr.ReadAt(buf, int64(s.Offset)); err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(buf, propertiesIdentifier) {
+		return nil, errSectionShort
+	}
+	count := binary.LittleEndian.Uint32(buf[8:12])
+	if uint64(count)*propertyEntryLength > uint64(len(buf)-propertiesHeaderLength) {
+		return nil, errSectionShort
+	}
+	// Character data (UTF-16) follows the entries
+	data := buf[propertiesHeaderLength+count*propertyEntryLength:]
+	chars := make([]uint16, len(data)/2)
+	for i := range chars {
+		chars[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	str := func(offset uint32) string {
+		if offset >= uint32(len(chars)) {
+			return ""
+		}
+		end := offset
+		for end < uint32(len(chars)) && chars[end] != 0 {
+			end++
+		}
+		return string(utf16.Decode(chars[offset:end]))
+	}
+	properties := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		entry := buf[propertiesHeaderLength+i*propertyEntryLength:]
+		name := str(binary.LittleEndian.Uint32(entry[0:4]))
+		if name != "" {
+			properties[name] = str(binary.LittleEndian.Uint32(entry[4:8]))
+		}
+	}
+	return properties, nil
+}
@@ -0,0 +1,201 @@
+package x3f
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	stdjpeg "image/jpeg"
+	"io"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/evanoberholster/imagemeta/exif"
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/jpeg"
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+var testProperties = [][2]string{
+	{"CAMMANUF", "SIGMA"},
+	{"CAMMODEL", "SIGMA DP2 Merrill"},
+	{"EXPTIME", "4000"},
+	{"APERTURE", "5.600"},
+	{"ISO", "200"},
+	{"FLENGTH", "30.000000"},
+	{"TIME", "1356998400"},
+}
+
+// newJPEG returns a JPEG of width x height with the Exif block e.
+func newJPEG(t *testing.T, width, height int, e []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := stdjpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	if e == nil {
+		return buf.Bytes()
+	}
+	var out bytes.Buffer
+	if err := jpeg.Rewrite(&buf, &out, jpeg.RewriteOptions{Exif: e}); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// newX3F returns an X3F with a JPEG preview image section, the properties section
+// and the directory.
+func newX3F(t *testing.T, preview []byte, properties [][2]string) []byte {
+	t.Helper()
+	le := binary.LittleEndian
+	u32 := func(buf []byte, vv ...uint32) []byte {
+		for _, v := range vv {
+			buf = append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+		}
+		return buf
+	}
+	// Header: version 2.3, columns, rows
+	buf := append([]byte("FOVb"), 3, 0, 2, 0)
+	buf = append(buf, make([]byte, 20)...)
+	buf = u32(buf, 2640, 1760, 0)
+
+	var sections [][3]uint32
+	// Preview image section
+	offset := uint32(len(buf))
+	buf = append(buf, "SECi"...)
+	buf = u32(buf, 0x00020000, 2, FormatJPEG, 640, 426, 0)
+	buf = append(buf, preview...)
+	sections = append(sections, [3]uint32{offset, uint32(len(buf)) - offset, le.Uint32([]byte("IMA2"))})
+
+	// Properties section
+	if properties != nil {
+		var chars []uint16
+		var entries []byte
+		for _, p := range properties {
+			entries = u32(entries, uint32(len(chars)))
+			chars = append(append(chars, utf16.Encode([]rune(p[0]))...), 0)
+			entries = u32(entries, uint32(len(chars)))
+			chars = append(append(chars, utf16.Encode([]rune(p[1]))...), 0)
+		}
+		offset = uint32(len(buf))
+		buf = append(buf, "SECp"...)
+		buf = u32(buf, 0x00020000, uint32(len(properties)), 0, 0, uint32(len(chars)))
+		buf = append(buf, entries...)
+		for _, c := range chars {
+			buf = append(buf, byte(c), byte(c>>8))
+		}
+		sections = append(sections, [3]uint32{offset, uint32(len(buf)) - offset, le.Uint32([]byte("PROP"))})
+	}
+
+	// Directory
+	offset = uint32(len(buf))
+	buf = append(buf, "SECd"...)
+	buf = u32(buf, 0x00020000, uint32(len(sections)))
+	for _, s := range sections {
+		buf = u32(buf, s[:]...)
+	}
+	return u32(buf, offset)
+}
+
+func TestParse(t *testing.T) {
+	preview := newJPEG(t, 640, 426, nil)
+	buf := newX3F(t, preview, testProperties)
+	if it, err := imagetype.ReadAt(bytes.NewReader(buf)); err != nil || it != imagetype.ImageX3F {
+		t.Errorf("Incorrect Imagetype wanted %s got %s (%v)", imagetype.ImageX3F, it, err)
+	}
+	m, err := Parse(bytes.NewReader(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Header.Version != [2]uint16{2, 3} || m.Dimensions() != meta.NewDimensions(2640, 1760) {
+		t.Errorf("Incorrect Header got %+v", m.Header)
+	}
+	if len(m.Sections) != 2 || m.Sections[0].Type.String() != "IMA2" || m.Sections[1].Type.String() != "PROP" {
+		t.Errorf("Incorrect Sections got %+v", m.Sections)
+	}
+	if model, ok := m.Property("CAMMODEL"); !ok || model != "SIGMA DP2 Merrill" {
+		t.Errorf("Incorrect Property wanted %s got %s", "SIGMA DP2 Merrill", model)
+	}
+
+	// Preview
+	img, err := m.LargestPreview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Width != 640 || img.Height != 426 || !img.IsJPEG() {
+		t.Errorf("Incorrect Preview got %+v", img)
+	}
+	b, err := io.ReadAll(m.PreviewImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, preview) {
+		t.Errorf("Incorrect PreviewImage")
+	}
+
+	// Exif from properties
+	e, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.CameraMake() != "SIGMA" || e.CameraModel() != "SIGMA DP2 Merrill" {
+		t.Errorf("Incorrect Camera got %s %s", e.CameraMake(), e.CameraModel())
+	}
+	if ss, err := e.ShutterSpeed(); err != nil || ss != meta.NewShutterSpeed(1, 250) {
+		t.Errorf("Incorrect ShutterSpeed wanted %s got %s (%v)", meta.NewShutterSpeed(1, 250), ss, err)
+	}
+	if a, err := e.Aperture(); err != nil || a != 5.6 {
+		t.Errorf("Incorrect Aperture wanted %v got %v (%v)", 5.6, a, err)
+	}
+	if iso, err := e.ISOSpeed(); err != nil || iso != 200 {
+		t.Errorf("Incorrect ISOSpeed wanted %d got %d (%v)", 200, iso, err)
+	}
+	if fl, err := e.FocalLength(); err != nil || fl != 30 {
+		t.Errorf("Incorrect FocalLength wanted %v got %v (%v)", 30, fl, err)
+	}
+	if tm, err := e.DateTime(nil); err != nil || !tm.Equal(time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Incorrect DateTime got %s (%v)", tm, err)
+	}
+}
+
+func TestParsePreviewExif(t *testing.T) {
+	b := exif.NewBuilder(nil)
+	if err := b.SetCamera("SIGMA", "sd Quattro H"); err != nil {
+		t.Fatal(err)
+	}
+	e, err := b.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Parse(bytes.NewReader(newX3F(t, newJPEG(t, 64, 48, e), testProperties)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Exif of the JPEG preview
+	ex, err := m.Exif()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.CameraModel() != "sd Quattro H" {
+		t.Errorf("Incorrect CameraModel wanted %s got %s", "sd Quattro H", ex.CameraModel())
+	}
+
+	// Without properties or Exif
+	if m, err = Parse(bytes.NewReader(newX3F(t, newJPEG(t, 64, 48, nil), nil))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.Exif(); err != ErrNoExif {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoExif, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	if _, err := Parse(bytes.NewReader([]byte("II*\x00\x08\x00\x00\x00"))); err != ErrNoX3FHeader {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoX3FHeader, err)
+	}
+	buf := newX3F(t, newJPEG(t, 8, 8, nil), nil)
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], 0)
+	if _, err := Parse(bytes.NewReader(buf)); err != ErrNoDirectory {
+		t.Errorf("Incorrect error wanted %v got %v", ErrNoDirectory, err)
+	}
+}
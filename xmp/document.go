@@ -0,0 +1,41 @@
+package xmp
+
+import (
+	"io"
+	"time"
+)
+
+// Document is a typed representation of the most common XMP properties.
+type Document struct {
+	Title       []string  // dc:title
+	Creator     []string  // dc:creator
+	Subject     []string  // dc:subject
+	Rating      int8      // xmp:Rating
+	CreateDate  time.Time // xmp:CreateDate
+	DateCreated time.Time // photoshop:DateCreated
+
+	// GPSLatitude and GPSLongitude in decimal degrees.
+	// exif:GPSLatitude and exif:GPSLongitude
+	GPSLatitude  float64
+	GPSLongitude float64
+}
+
+// Decode reads XMP Metadata from the given reader and returns a Document
+// with the common XMP properties. Repeated elements (rdf:Bag, rdf:Seq, rdf:Alt)
+// are returned as slices.
+func Decode(r io.Reader) (*Document, error) {
+	x, err := ParseXmp(r)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return &Document{
+		Title:        x.DC.Title,
+		Creator:      x.DC.Creator,
+		Subject:      x.DC.Subject,
+		Rating:       x.Basic.Rating,
+		CreateDate:   x.Basic.CreateDate,
+		DateCreated:  x.Photoshop.DateCreated,
+		GPSLatitude:  x.Exif.GPSLatitude,
+		GPSLongitude: x.Exif.GPSLongitude,
+	}, nil
+}
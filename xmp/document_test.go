@@ -0,0 +1,70 @@
+package xmp
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	f, err := os.Open("test" + string(os.PathSeparator) + "1.xmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := Decode(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"Evan Oberholster"}, doc.Creator)
+	assert.Equal(t, []string{"2021", "Buswanga", "Coron", "Coron Island", "Holiday", "Palawan", "Philippines", "Sea", "Snorkeling", "Travel"}, doc.Subject)
+	assert.Equal(t, time.Date(2021, 1, 10, 17, 30, 57, 0, time.UTC), doc.CreateDate)
+	assert.Equal(t, time.Date(2021, 1, 10, 17, 30, 57, 0, time.UTC), doc.DateCreated)
+	assert.InDelta(t, 11.952186, doc.GPSLatitude, 0.000001)
+	assert.InDelta(t, 120.192883, doc.GPSLongitude, 0.000001)
+
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:exif="http://ns.adobe.com/exif/1.0/"
+   xmp:Rating="4"
+   exif:GPSLatitude="33,51,54S"
+   exif:GPSLongitude="151,12,36W">
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	doc, err = Decode(strings.NewReader(packet))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, int8(4), doc.Rating)
+	assert.InDelta(t, -33.865, doc.GPSLatitude, 0.000001)
+	assert.InDelta(t, -151.21, doc.GPSLongitude, 0.000001)
+
+	_, err = Decode(strings.NewReader("no xmp"))
+	assert.Error(t, err)
+}
+
+func TestParseGPSCoord(t *testing.T) {
+	for _, v := range []struct {
+		str string
+		f   float64
+	}{
+		{"11,57.1312N", 11.952186666},
+		{"120,11.573E", 120.192883333},
+		{"33,51,54S", -33.865},
+		{"151,12,36W", -151.21},
+		{"-33.865", -33.865},
+		{"", 0},
+	} {
+		if f := parseGPSCoord([]byte(v.str)); math.Abs(f-v.f) > 0.000001 {
+			t.Errorf("Incorrect GPS Coordinate for %s wanted %f got %f", v.str, v.f, f)
+		}
+	}
+}
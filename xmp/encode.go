@@ -0,0 +1,444 @@
+package xmp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+const (
+	// xpacketBegin is the XMP packet header. The begin attribute is the UTF-8 byte order mark.
+	xpacketBegin = "<?xpacket begin=\"\xef\xbb\xbf\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n"
+	// xpacketEnd is the XMP packet trailer of a writable packet.
+	xpacketEnd = "<?xpacket end=\"w\"?>"
+
+	// packetPadding is the whitespace added before the packet trailer so that
+	// the packet can be edited in place. The XMP spec recommends 2KB.
+	packetPadding = 2048
+	// paddingLineLength is the length of a line of padding including the newline.
+	paddingLineLength = 100
+
+	// xmpDateFormat is the date format of XMP date properties.
+	xmpDateFormat = "2006-01-02T15:04:05Z07:00"
+)
+
+// rdfArray is the RDF container type of an array property.
+type rdfArray uint8
+
+const (
+	rdfSeq rdfArray = iota
+	rdfBag
+	rdfAlt
+)
+
+func (a rdfArray) String() string {
+	switch a {
+	case rdfBag:
+		return "rdf:Bag"
+	case rdfAlt:
+		return "rdf:Alt"
+	}
+	return "rdf:Seq"
+}
+
+// namespaceOrder is the order the namespaces of XMP are written in.
+var namespaceOrder = []xmpns.Namespace{
+	xmpns.TiffNS, xmpns.ExifNS, xmpns.AuxNS, xmpns.XmpNS,
+	xmpns.DcNS, xmpns.CrsNS, xmpns.XmpMMNS, xmpns.PhotoshopNS,
+	xmpns.XmpRightsNS, xmpns.Iptc4xmpCoreNS,
+}
+
+// Marshal returns the XMP packet encoding of x.
+//
+// See Encode for details about the encoding.
+func Marshal(x XMP) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, x); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the XMP packet encoding of x to w.
+//
+// The packet is RDF/XML with a single rdf:Description wrapped in
+// the xpacket header and trailer, with 2KB of padding so that the packet
+// can be edited in place. Simple properties are written as attributes
+// and arrays as rdf:Seq, rdf:Bag or rdf:Alt elements.
+// Properties with a zero value are not written.
+func Encode(w io.Writer, x XMP) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xpacketBegin)
+	writeXMPMeta(bw, x)
+	writePadding(bw, packetPadding)
+	bw.WriteString(xpacketEnd)
+	return bw.Flush()
+}
+
+// writeXMPMeta writes the x:xmpmeta element of x to bw.
+func writeXMPMeta(bw *bufio.Writer, x XMP) {
+	e := newEncoder(bw)
+	e.encode(x)
+	e.writeTo()
+}
+
+// encode adds the properties of x.
+func (e *encoder) encode(x XMP) {
+	x.Tiff.encode(e)
+	x.Exif.encode(e)
+	x.Aux.encode(e)
+	x.Basic.encode(e)
+	x.DC.encode(e, x.Tiff)
+	x.CRS.encode(e)
+	x.MM.encode(e)
+	x.Photoshop.encode(e)
+	x.Rights.encode(e)
+	x.IPTCCore.encode(e)
+}
+
+// writePadding writes n bytes of whitespace in lines of paddingLineLength.
+func writePadding(bw *bufio.Writer, n int) {
+	line := bytes.Repeat([]byte{' '}, paddingLineLength)
+	line[paddingLineLength-1] = '\n'
+	for ; n >= paddingLineLength; n -= paddingLineLength {
+		bw.Write(line)
+	}
+	bw.Write(line[paddingLineLength-n:])
+}
+
+// encoder collects the properties of an XMP packet and writes them
+// as a single rdf:Description.
+type encoder struct {
+	bw    *bufio.Writer
+	attrs map[xmpns.Namespace][]attribute
+	elems map[xmpns.Namespace][]element
+}
+
+// attribute is a simple property.
+type attribute struct {
+	name string
+	val  string
+}
+
+// element is a property with a value that is not written as an attribute.
+// Arrays have items, structs have fields.
+type element struct {
+	name   string
+	array  rdfArray
+	items  []string
+	langs  []string
+	fields []attribute
+}
+
+func newEncoder(bw *bufio.Writer) *encoder {
+	return &encoder{
+		bw:    bw,
+		attrs: make(map[xmpns.Namespace][]attribute),
+		elems: make(map[xmpns.Namespace][]element),
+	}
+}
+
+// attr adds a simple property. Empty values are not added.
+func (e *encoder) attr(ns xmpns.Namespace, name string, val string) {
+	if val != "" {
+		e.attrs[ns] = append(e.attrs[ns], attribute{name: name, val: val})
+	}
+}
+
+func (e *encoder) attrUint(ns xmpns.Namespace, name string, u uint64) {
+	if u != 0 {
+		e.attr(ns, name, strconv.FormatUint(u, 10))
+	}
+}
+
+func (e *encoder) attrDate(ns xmpns.Namespace, name string, t time.Time) {
+	if !t.IsZero() {
+		e.attr(ns, name, t.Format(xmpDateFormat))
+	}
+}
+
+// attrRational adds f as a rational with a denominator of up to 1000.
+func (e *encoder) attrRational(ns xmpns.Namespace, name string, f float64) {
+	if f != 0 && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		e.attr(ns, name, formatRational(f))
+	}
+}
+
+// array adds an array property. Empty arrays are not added.
+// langs are the xml:lang qualifiers of the items of an rdf:Alt.
+func (e *encoder) array(ns xmpns.Namespace, name string, array rdfArray, items []string, langs []string) {
+	if len(items) > 0 {
+		e.elems[ns] = append(e.elems[ns], element{name: name, array: array, items: items, langs: langs})
+	}
+}
+
+// resource adds a struct property with fields in the same namespace.
+func (e *encoder) resource(ns xmpns.Namespace, name string, fields []attribute) {
+	e.elems[ns] = append(e.elems[ns], element{name: name, fields: fields})
+}
+
+// writeTo writes the x:xmpmeta element with the collected properties.
+func (e *encoder) writeTo() {
+	bw := e.bw
+	bw.WriteString("<x:xmpmeta xmlns:x=\"")
+	bw.WriteString(xmpns.XNS.URI())
+	bw.WriteString("\">\n <rdf:RDF xmlns:rdf=\"")
+	bw.WriteString(xmpns.RdfNS.URI())
+	bw.WriteString("\">\n  <rdf:Description rdf:about=\"\"")
+	for _, ns := range namespaceOrder {
+		if len(e.attrs[ns]) > 0 || len(e.elems[ns]) > 0 {
+			bw.WriteString("\n    xmlns:")
+			bw.WriteString(ns.String())
+			bw.WriteString("=\"")
+			bw.WriteString(ns.URI())
+			bw.WriteByte('"')
+		}
+	}
+	for _, ns := range namespaceOrder {
+		for _, a := range e.attrs[ns] {
+			writeAttribute(bw, ns, a)
+		}
+	}
+	bw.WriteString(">\n")
+	for _, ns := range namespaceOrder {
+		for _, el := range e.elems[ns] {
+			writeElement(bw, ns, el)
+		}
+	}
+	bw.WriteString("  </rdf:Description>\n </rdf:RDF>\n</x:xmpmeta>\n")
+}
+
+// writeAttribute writes a simple property on a new line.
+func writeAttribute(bw *bufio.Writer, ns xmpns.Namespace, a attribute) {
+	bw.WriteString("\n    ")
+	writeName(bw, ns, a.name)
+	bw.WriteString("=\"")
+	xml.EscapeText(bw, []byte(a.val))
+	bw.WriteByte('"')
+}
+
+// writeElement writes an array or struct property.
+func writeElement(bw *bufio.Writer, ns xmpns.Namespace, el element) {
+	bw.WriteString("   <")
+	writeName(bw, ns, el.name)
+	if el.fields != nil {
+		bw.WriteString(" rdf:parseType=\"Resource\">\n")
+		for _, f := range el.fields {
+			bw.WriteString("    <")
+			writeName(bw, ns, f.name)
+			bw.WriteByte('>')
+			xml.EscapeText(bw, []byte(f.val))
+			bw.WriteString("</")
+			writeName(bw, ns, f.name)
+			bw.WriteString(">\n")
+		}
+	} else {
+		bw.WriteString(">\n    <")
+		bw.WriteString(el.array.String())
+		bw.WriteString(">\n")
+		for i, item := range el.items {
+			bw.WriteString("     <rdf:li")
+			if el.array == rdfAlt {
+				lang := "x-default"
+				if i < len(el.langs) && el.langs[i] != "" {
+					lang = el.langs[i]
+				}
+				bw.WriteString(" xml:lang=\"")
+				xml.EscapeText(bw, []byte(lang))
+				bw.WriteByte('"')
+			}
+			bw.WriteByte('>')
+			xml.EscapeText(bw, []byte(item))
+			bw.WriteString("</rdf:li>\n")
+		}
+		bw.WriteString("    </")
+		bw.WriteString(el.array.String())
+		bw.WriteString(">\n")
+	}
+	bw.WriteString("   </")
+	writeName(bw, ns, el.name)
+	bw.WriteString(">\n")
+}
+
+func writeName(bw *bufio.Writer, ns xmpns.Namespace, name string) {
+	bw.WriteString(ns.String())
+	bw.WriteByte(':')
+	bw.WriteString(name)
+}
+
+// formatRational returns f as "n/d" with a denominator of up to 1000.
+func formatRational(f float64) string {
+	d := int64(1)
+	for d < 1000 && f*float64(d) != math.Trunc(f*float64(d)) {
+		d *= 10
+	}
+	n := int64(math.Round(f * float64(d)))
+	if g := gcd(n, d); g > 1 {
+		n, d = n/g, d/g
+	}
+	return strconv.FormatInt(n, 10) + "/" + strconv.FormatInt(d, 10)
+}
+
+func gcd(a, b int64) int64 {
+	if a < 0 {
+		a = -a
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// formatGPSCoord returns the XMP GPSCoordinate "DDD,MM.mmk" of f in decimal degrees.
+// pos and neg are the direction suffixes of positive and negative values.
+func formatGPSCoord(f float64, pos, neg byte) string {
+	ref := pos
+	if f < 0 {
+		ref = neg
+		f = -f
+	}
+	deg, min := math.Modf(f)
+	buf := strconv.AppendInt(nil, int64(deg), 10)
+	buf = append(buf, ',')
+	buf = strconv.AppendFloat(buf, min*60, 'f', 6, 64)
+	return string(append(buf, ref))
+}
+
+// formatBias returns an ExposureBias as text. Zero values return an empty string.
+func formatBias(eb meta.ExposureBias) string {
+	if eb == 0 {
+		return ""
+	}
+	return eb.String()
+}
+
+func (t Tiff) encode(e *encoder) {
+	e.attr(xmpns.TiffNS, "Make", t.Make)
+	e.attr(xmpns.TiffNS, "Model", t.Model)
+	e.attr(xmpns.TiffNS, "Software", t.Software)
+	e.attrUint(xmpns.TiffNS, "ImageWidth", uint64(t.ImageWidth))
+	e.attrUint(xmpns.TiffNS, "ImageLength", uint64(t.ImageLength))
+	e.attrUint(xmpns.TiffNS, "Orientation", uint64(t.Orientation))
+}
+
+func (exif Exif) encode(e *encoder) {
+	e.attr(xmpns.ExifNS, "ExifVersion", exif.ExifVersion)
+	e.attrUint(xmpns.ExifNS, "PixelXDimension", uint64(exif.PixelXDimension))
+	e.attrUint(xmpns.ExifNS, "PixelYDimension", uint64(exif.PixelYDimension))
+	e.attrDate(xmpns.ExifNS, "DateTimeOriginal", exif.DateTimeOriginal)
+	e.attrDate(xmpns.ExifNS, "DateTimeDigitized", exif.CreateDate)
+	if exif.ExposureTime[1] != 0 {
+		e.attr(xmpns.ExifNS, "ExposureTime", strconv.FormatUint(uint64(exif.ExposureTime[0]), 10)+"/"+strconv.FormatUint(uint64(exif.ExposureTime[1]), 10))
+	}
+	e.attrUint(xmpns.ExifNS, "ExposureProgram", uint64(exif.ExposureProgram))
+	e.attrUint(xmpns.ExifNS, "ExposureMode", uint64(exif.ExposureMode))
+	e.attr(xmpns.ExifNS, "ExposureBiasValue", formatBias(exif.ExposureBias))
+	e.attrUint(xmpns.ExifNS, "MeteringMode", uint64(exif.MeteringMode))
+	e.attrRational(xmpns.ExifNS, "FNumber", float64(exif.Aperture))
+	e.attrRational(xmpns.ExifNS, "FocalLength", float64(exif.FocalLength))
+	e.attrRational(xmpns.ExifNS, "SubjectDistance", float64(exif.SubjectDistance))
+	if exif.GPSLatitude != 0 || exif.GPSLongitude != 0 {
+		e.attr(xmpns.ExifNS, "GPSLatitude", formatGPSCoord(exif.GPSLatitude, 'N', 'S'))
+		e.attr(xmpns.ExifNS, "GPSLongitude", formatGPSCoord(exif.GPSLongitude, 'E', 'W'))
+	}
+	e.attrRational(xmpns.ExifNS, "GPSAltitude", float64(exif.GPSAltitude))
+	e.attrDate(xmpns.ExifNS, "GPSTimeStamp", exif.GPSTimestamp)
+	if exif.ISOSpeedRatings != 0 {
+		e.array(xmpns.ExifNS, "ISOSpeedRatings", rdfSeq, []string{strconv.FormatUint(uint64(exif.ISOSpeedRatings), 10)}, nil)
+	}
+	if exif.Flash != (Flash{}) {
+		e.resource(xmpns.ExifNS, "Flash", []attribute{
+			{"Fired", formatBool(exif.Flash.Fired)},
+			{"Return", strconv.FormatUint(uint64(exif.Flash.Return), 10)},
+			{"Mode", strconv.FormatUint(uint64(exif.Flash.Mode), 10)},
+			{"Function", formatBool(exif.Flash.Function)},
+			{"RedEyeMode", formatBool(exif.Flash.RedEyeMode)},
+		})
+	}
+}
+
+// formatBool returns an XMP Boolean.
+func formatBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+func (aux Aux) encode(e *encoder) {
+	e.attr(xmpns.AuxNS, "SerialNumber", aux.SerialNumber)
+	e.attr(xmpns.AuxNS, "LensInfo", aux.LensInfo)
+	e.attr(xmpns.AuxNS, "Lens", aux.Lens)
+	e.attrUint(xmpns.AuxNS, "LensID", uint64(aux.LensID))
+	e.attr(xmpns.AuxNS, "LensSerialNumber", aux.LensSerialNumber)
+	e.attrUint(xmpns.AuxNS, "ImageNumber", uint64(aux.ImageNumber))
+	e.attr(xmpns.AuxNS, "ApproximateFocusDistance", aux.ApproximateFocusDistance)
+	e.attr(xmpns.AuxNS, "FlashCompensation", formatBias(aux.FlashCompensation))
+	e.attr(xmpns.AuxNS, "Firmware", aux.Firmware)
+}
+
+func (basic Basic) encode(e *encoder) {
+	e.attrDate(xmpns.XmpNS, "CreateDate", basic.CreateDate)
+	e.attr(xmpns.XmpNS, "CreatorTool", basic.CreatorTool)
+	e.attr(xmpns.XmpNS, "Label", basic.Label)
+	e.attrDate(xmpns.XmpNS, "MetadataDate", basic.MetadataDate)
+	e.attrDate(xmpns.XmpNS, "ModifyDate", basic.ModifyDate)
+	if basic.Rating != 0 {
+		e.attr(xmpns.XmpNS, "Rating", strconv.Itoa(int(basic.Rating)))
+	}
+}
+
+// encode adds the Dublin Core properties. Tiff Copyright and ImageDescription
+// are written as dc:rights and dc:description when DC does not have them.
+func (dc DublinCore) encode(e *encoder, t Tiff) {
+	if dc.Format != 0 {
+		e.attr(xmpns.DcNS, "format", dc.Format.String())
+	}
+	e.attr(xmpns.DcNS, "coverage", dc.Coverage)
+	e.attrDate(xmpns.DcNS, "date", dc.Date)
+	e.attr(xmpns.DcNS, "identifier", dc.Identifier)
+	e.attr(xmpns.DcNS, "source", dc.Source)
+	e.array(xmpns.DcNS, "contributor", rdfBag, dc.Contributor, nil)
+	e.array(xmpns.DcNS, "creator", rdfSeq, dc.Creator, nil)
+	e.array(xmpns.DcNS, "language", rdfBag, dc.Language, nil)
+	e.array(xmpns.DcNS, "subject", rdfBag, dc.Subject, nil)
+	e.array(xmpns.DcNS, "title", rdfAlt, dc.Title, dc.TitleLang)
+	if rights := dc.Rights; len(rights) > 0 {
+		e.array(xmpns.DcNS, "rights", rdfAlt, rights, nil)
+	} else {
+		e.array(xmpns.DcNS, "rights", rdfAlt, t.Copyright, nil)
+	}
+	if description := dc.Description; len(description) > 0 {
+		e.array(xmpns.DcNS, "description", rdfAlt, description, nil)
+	} else {
+		e.array(xmpns.DcNS, "description", rdfAlt, t.ImageDescription, nil)
+	}
+}
+
+func (crs CRS) encode(e *encoder) {
+	e.attr(xmpns.CrsNS, "RawFileName", crs.RawFileName)
+}
+
+func (mm XMPMM) encode(e *encoder) {
+	if mm.DocumentID != (meta.UUID{}) {
+		e.attr(xmpns.XmpMMNS, "DocumentID", "xmp.did:"+mm.DocumentID.String())
+	}
+	if mm.InstanceID != (meta.UUID{}) {
+		e.attr(xmpns.XmpMMNS, "InstanceID", "xmp.iid:"+mm.InstanceID.String())
+	}
+	if mm.OriginalDocumentID != (meta.UUID{}) {
+		e.attr(xmpns.XmpMMNS, "OriginalDocumentID", "xmp.did:"+mm.OriginalDocumentID.String())
+	}
+	e.attr(xmpns.XmpMMNS, "PreservedFileName", mm.PreservedFileName)
+}
+
+func (ps Photoshop) encode(e *encoder) {
+	e.attrDate(xmpns.PhotoshopNS, "DateCreated", ps.DateCreated)
+}
@@ -0,0 +1,162 @@
+package xmp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/evanoberholster/imagemeta/imagetype"
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	date := time.Date(2021, 1, 10, 17, 30, 57, 0, time.UTC)
+	x := XMP{
+		Tiff: Tiff{Make: "Canon", Model: "Canon EOS 6D", ImageWidth: 5472, ImageLength: 3648, Orientation: 6},
+		Exif: Exif{
+			PixelXDimension:  5472,
+			PixelYDimension:  3648,
+			DateTimeOriginal: date,
+			ExposureTime:     meta.NewShutterSpeed(1, 250),
+			ExposureProgram:  meta.ExposureProgram(1),
+			ExposureMode:     meta.ExposureMode(1),
+			ExposureBias:     meta.NewExposureBias(-2, 3),
+			ISOSpeedRatings:  100,
+			MeteringMode:     meta.MeteringMode(5),
+			Aperture:         meta.NewAperture(32, 10),
+			FocalLength:      meta.NewFocalLength(208, 10),
+			GPSLatitude:      11.952186666666666,
+			GPSLongitude:     -120.19288333333333,
+			GPSAltitude:      12.5,
+			Flash:            Flash{Fired: true, Mode: 1},
+		},
+		Aux: Aux{SerialNumber: "412052000727", Lens: "50mm", LensID: 180, FlashCompensation: meta.NewExposureBias(1, 3)},
+		Basic: Basic{
+			CreateDate:   date,
+			MetadataDate: time.Date(2021, 2, 3, 17, 34, 4, 0, time.FixedZone("", 8*60*60)),
+			Label:        "Red",
+			Rating:       -1,
+		},
+		DC: DublinCore{
+			Creator: []string{"Evan Oberholster"},
+			Subject: []string{"Coron", "Sea & Sky", "<Travel>"},
+			Title:   []string{"Title \"quoted\""},
+			Rights:  []string{"© Evan Oberholster"},
+			Format:  imagetype.ImageCR2,
+		},
+		CRS:       CRS{RawFileName: "_MG_1563.CR2"},
+		MM:        XMPMM{DocumentID: meta.UUIDFromString("d5aa8a7a-f3c5-4cbe-8a2b-3b1f5ec5d0e1"), PreservedFileName: "_MG_1563.CR2"},
+		Photoshop: Photoshop{DateCreated: date},
+		Rights:    Rights{Marked: true, UsageTerms: []string{"Licensed for web use"}, WebStatement: "https://example.com/license"},
+		IPTCCore:  IPTCCore{CreatorContactInfo: ContactInfo{Email: "studio@example.com", URL: "https://example.com", Phone: "+1 555 0100"}},
+	}
+
+	buf, err := Marshal(x)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, bytes.HasPrefix(buf, []byte(xpacketBegin)))
+	assert.True(t, bytes.HasSuffix(buf, []byte(xpacketEnd)))
+	assert.True(t, bytes.Contains(buf, bytes.Repeat([]byte{' '}, 99)), "packet padding")
+	assert.False(t, bytes.Contains(buf, []byte("xmlns:dc=\"http://purl.org/dc/elements/1.1/\"\n    xmlns:dc")), "duplicate namespace")
+
+	x2, err := ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, x.Tiff, x2.Tiff)
+	assert.Equal(t, x.CRS, x2.CRS)
+	assert.Equal(t, x.MM, x2.MM)
+	assert.Equal(t, x.Aux, x2.Aux)
+	assert.Equal(t, x.Basic.Rating, x2.Basic.Rating)
+	assert.Equal(t, x.Basic.Label, x2.Basic.Label)
+	assert.True(t, x.Basic.CreateDate.Equal(x2.Basic.CreateDate))
+	assert.True(t, x.Basic.MetadataDate.Equal(x2.Basic.MetadataDate))
+	assert.True(t, x.Photoshop.DateCreated.Equal(x2.Photoshop.DateCreated))
+	assert.Equal(t, x.Rights, x2.Rights)
+	assert.Equal(t, x.IPTCCore, x2.IPTCCore)
+
+	assert.Equal(t, x.DC.Creator, x2.DC.Creator)
+	assert.Equal(t, x.DC.Subject, x2.DC.Subject)
+	assert.Equal(t, x.DC.Title, x2.DC.Title)
+	assert.Equal(t, []string{"x-default"}, x2.DC.TitleLang)
+	assert.Equal(t, x.DC.Rights, x2.DC.Rights)
+	assert.Equal(t, x.DC.Format, x2.DC.Format)
+
+	assert.Equal(t, x.Exif.PixelXDimension, x2.Exif.PixelXDimension)
+	assert.Equal(t, x.Exif.PixelYDimension, x2.Exif.PixelYDimension)
+	assert.True(t, x.Exif.DateTimeOriginal.Equal(x2.Exif.DateTimeOriginal))
+	assert.Equal(t, x.Exif.ExposureTime, x2.Exif.ExposureTime)
+	assert.Equal(t, x.Exif.ExposureProgram, x2.Exif.ExposureProgram)
+	assert.Equal(t, x.Exif.ExposureMode, x2.Exif.ExposureMode)
+	assert.Equal(t, x.Exif.ExposureBias, x2.Exif.ExposureBias)
+	assert.Equal(t, x.Exif.ISOSpeedRatings, x2.Exif.ISOSpeedRatings)
+	assert.Equal(t, x.Exif.MeteringMode, x2.Exif.MeteringMode)
+	assert.Equal(t, x.Exif.Aperture, x2.Exif.Aperture)
+	assert.Equal(t, x.Exif.FocalLength, x2.Exif.FocalLength)
+	assert.Equal(t, x.Exif.GPSAltitude, x2.Exif.GPSAltitude)
+	assert.InDelta(t, x.Exif.GPSLatitude, x2.Exif.GPSLatitude, 1e-7)
+	assert.InDelta(t, x.Exif.GPSLongitude, x2.Exif.GPSLongitude, 1e-7)
+}
+
+func TestMarshalEmpty(t *testing.T) {
+	buf, err := Marshal(XMP{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	x, err := ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, XMP{}, x)
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	for _, v := range testXmp {
+		t.Run(v.filename, func(t *testing.T) {
+			f, err := os.Open("test" + string(os.PathSeparator) + v.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			x, err := ParseXmp(f)
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			buf, err := Marshal(x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			x2, err := ParseXmp(bytes.NewReader(buf))
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			assert.Equal(t, x, x2)
+		})
+	}
+}
+
+func TestFormatRational(t *testing.T) {
+	tests := []struct {
+		f   float64
+		str string
+	}{
+		{3.2, "16/5"},
+		{float64(float32(3.2)), "16/5"},
+		{50, "50/1"},
+		{0.125, "1/8"},
+		{-0.5, "-1/2"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.str, formatRational(test.f))
+	}
+}
+
+func TestFormatGPSCoord(t *testing.T) {
+	assert.Equal(t, "11,57.131200N", formatGPSCoord(11.952186666666666, 'N', 'S'))
+	assert.Equal(t, "120,11.573000W", formatGPSCoord(-120.19288333333333, 'E', 'W'))
+}
@@ -95,11 +95,15 @@ func (exif *Exif) parse(p property) (err error) {
 	case xmpns.ISOSpeedRatings:
 		exif.ISOSpeedRatings = parseUint32(p.val)
 	case xmpns.GPSLatitude:
-		exif.GPSLatitude = parseFloat64(p.Value())
+		exif.GPSLatitude = parseGPSCoord(p.Value())
 	case xmpns.GPSLongitude:
-		exif.GPSLongitude = parseFloat64(p.Value())
+		exif.GPSLongitude = parseGPSCoord(p.Value())
 	case xmpns.GPSAltitude:
-		exif.GPSAltitude = float32(parseFloat64(p.Value()))
+		if n, d := parseRational(p.Value()); d != 0 {
+			exif.GPSAltitude = float32(n) / float32(d)
+		} else {
+			exif.GPSAltitude = float32(parseFloat64(p.Value()))
+		}
 	//case xmpns.Flash:
 	default:
 		return ErrPropertyNotSet
@@ -0,0 +1,122 @@
+package xmp
+
+import (
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+// GCamera is the Google Camera namespace of motion photos, and the items of the
+// Container directory of the file.
+//
+//	xmlns:GCamera="http://ns.google.com/photos/1.0/camera/"
+//	xmlns:Container="http://ns.google.com/photos/1.0/container/"
+//	xmlns:Item="http://ns.google.com/photos/1.0/container/item/"
+//
+// This implementation is incomplete and based on https://developer.android.com/media/platform/motion-photo-format
+type GCamera struct {
+	// MicroVideo is 1 if the file is a motion photo of the Micro Video format.
+	// The video is at MicroVideoOffset bytes from the end of the file.
+	MicroVideo        uint8
+	MicroVideoVersion uint8
+	MicroVideoOffset  int64
+
+	// MotionPhoto is 1 if the file is a motion photo. The video is the
+	// item of the Container directory with the "MotionPhoto" semantic.
+	MotionPhoto        uint8
+	MotionPhotoVersion uint8
+
+	// Items of the Container directory in file order.
+	Items []ContainerItem
+}
+
+// ContainerItem is a media item of the Container directory. The items are
+// concatenated in the order of the directory, the first item is the primary image.
+type ContainerItem struct {
+	Mime     string
+	Semantic string // "Primary", "MotionPhoto", "GainMap" or "Depth"
+
+	// Length of the item in bytes, and Padding after the item. The length
+	// of the primary image is 0.
+	Length  int64
+	Padding int64
+}
+
+// MotionPhotoItem returns the item with the "MotionPhoto" semantic and
+// true if the item is present.
+func (gc GCamera) MotionPhotoItem() (ContainerItem, bool) {
+	for _, item := range gc.Items {
+		if item.Semantic == "MotionPhoto" {
+			return item, true
+		}
+	}
+	return ContainerItem{}, false
+}
+
+func (gc *GCamera) parse(p property) (err error) {
+	switch p.Name() {
+	case xmpns.MicroVideo:
+		gc.MicroVideo = parseUint8(p.Value())
+	case xmpns.MicroVideoVersion:
+		gc.MicroVideoVersion = parseUint8(p.Value())
+	case xmpns.MicroVideoOffset:
+		gc.MicroVideoOffset = parseInt(p.Value())
+	case xmpns.MotionPhoto:
+		gc.MotionPhoto = parseUint8(p.Value())
+	case xmpns.MotionPhotoVersion:
+		gc.MotionPhotoVersion = parseUint8(p.Value())
+	default:
+		return ErrPropertyNotSet
+	}
+	return nil
+}
+
+// parseItem parses a property of an item of the Container directory. A property
+// that is already set on the last item starts the next item.
+func (gc *GCamera) parseItem(p property) (err error) {
+	name := p.Name()
+	if p.Namespace() == xmpns.ContainerNS {
+		// Attributes of the items of the Container:Directory sequence
+		// have the attribute as their parent.
+		if p.Parent().Namespace() != xmpns.ItemNS {
+			return ErrPropertyNotSet
+		}
+		name = p.Parent().Name()
+	}
+	switch name {
+	case xmpns.Mime, xmpns.Semantic, xmpns.Length, xmpns.Padding:
+	default:
+		return ErrPropertyNotSet
+	}
+	item := gc.lastItem(func(item ContainerItem) bool {
+		switch name {
+		case xmpns.Mime:
+			return item.Mime != ""
+		case xmpns.Semantic:
+			return item.Semantic != ""
+		case xmpns.Length:
+			return item.Length != 0
+		case xmpns.Padding:
+			return item.Padding != 0
+		}
+		return false
+	})
+	switch name {
+	case xmpns.Mime:
+		item.Mime = parseString(p.Value())
+	case xmpns.Semantic:
+		item.Semantic = parseString(p.Value())
+	case xmpns.Length:
+		item.Length = parseInt(p.Value())
+	case xmpns.Padding:
+		item.Padding = parseInt(p.Value())
+	}
+	return nil
+}
+
+// lastItem returns the last item of the Container directory, or a new item
+// if there are no items or isSet returns true for the last item.
+func (gc *GCamera) lastItem(isSet func(item ContainerItem) bool) *ContainerItem {
+	if len(gc.Items) == 0 || isSet(gc.Items[len(gc.Items)-1]) {
+		gc.Items = append(gc.Items, ContainerItem{})
+	}
+	return &gc.Items[len(gc.Items)-1]
+}
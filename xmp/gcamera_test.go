@@ -0,0 +1,73 @@
+package xmp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGCamera(t *testing.T) {
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:GCamera="http://ns.google.com/photos/1.0/camera/"
+    xmlns:Container="http://ns.google.com/photos/1.0/container/"
+    xmlns:Item="http://ns.google.com/photos/1.0/container/item/"
+    GCamera:MotionPhoto="1"
+    GCamera:MotionPhotoVersion="1">
+   <Container:Directory>
+    <rdf:Seq>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="image/jpeg" Item:Semantic="Primary" Item:Length="0" Item:Padding="0"/>
+     </rdf:li>
+     <rdf:li rdf:parseType="Resource">
+      <Container:Item Item:Mime="video/mp4" Item:Semantic="MotionPhoto" Item:Length="1024" Item:Padding="0"/>
+     </rdf:li>
+    </rdf:Seq>
+   </Container:Directory>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	x, err := ParseXmp(strings.NewReader(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gc := x.GCamera
+	if gc.MotionPhoto != 1 || gc.MotionPhotoVersion != 1 {
+		t.Errorf("Incorrect MotionPhoto wanted %d %d got %d %d", 1, 1, gc.MotionPhoto, gc.MotionPhotoVersion)
+	}
+	want := []ContainerItem{
+		{Mime: "image/jpeg", Semantic: "Primary"},
+		{Mime: "video/mp4", Semantic: "MotionPhoto", Length: 1024},
+	}
+	if len(gc.Items) != len(want) {
+		t.Fatalf("Incorrect Container items wanted %v got %v", want, gc.Items)
+	}
+	for i := range want {
+		if gc.Items[i] != want[i] {
+			t.Errorf("Incorrect Container item %d wanted %v got %v", i, want[i], gc.Items[i])
+		}
+	}
+	if item, ok := gc.MotionPhotoItem(); !ok || item != want[1] {
+		t.Errorf("Incorrect MotionPhoto item wanted %v got %v", want[1], item)
+	}
+
+	// Micro Video as elements with the Camera prefix
+	packet = `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about="" xmlns:Camera="http://ns.google.com/photos/1.0/camera/">
+   <Camera:MicroVideo>1</Camera:MicroVideo>
+   <Camera:MicroVideoVersion>1</Camera:MicroVideoVersion>
+   <Camera:MicroVideoOffset>2048</Camera:MicroVideoOffset>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	if x, err = ParseXmp(strings.NewReader(packet)); err != nil {
+		t.Fatal(err)
+	}
+	if gc = x.GCamera; gc.MicroVideo != 1 || gc.MicroVideoVersion != 1 || gc.MicroVideoOffset != 2048 {
+		t.Errorf("Incorrect MicroVideo got %d %d %d", gc.MicroVideo, gc.MicroVideoVersion, gc.MicroVideoOffset)
+	}
+	if _, ok := gc.MotionPhotoItem(); ok {
+		t.Errorf("Incorrect MotionPhoto item wanted %t got %t", false, ok)
+	}
+}
@@ -1,7 +1,9 @@
 package xmp
 
 import (
+	"bytes"
 	"fmt"
+	"html"
 	"math"
 	"strconv"
 	"time"
@@ -31,6 +33,16 @@ func (xmp *XMP) parser(p property) (err error) {
 		err = xmp.CRS.parse(p)
 	case xmpns.XmpMMNS, xmpns.XapMMNS:
 		err = xmp.MM.parse(p)
+	case xmpns.PhotoshopNS:
+		err = xmp.Photoshop.parse(p)
+	case xmpns.XmpRightsNS:
+		err = xmp.Rights.parse(p)
+	case xmpns.Iptc4xmpCoreNS:
+		err = xmp.IPTCCore.parse(p)
+	case xmpns.GCameraNS:
+		err = xmp.GCamera.parse(p)
+	case xmpns.ContainerNS, xmpns.ItemNS:
+		err = xmp.GCamera.parseItem(p)
 	default:
 		//fmt.Println(p, ns)
 		return
@@ -70,7 +82,8 @@ func parseUUID(buf []byte) (uuid meta.UUID) {
 
 // parseInt parses a []byte of a string representation of an int64 value and returns the value
 func parseInt(buf []byte) (i int64) {
-	if buf[0] == '-' {
+	i = 1
+	if len(buf) > 0 && buf[0] == '-' {
 		buf = buf[1:]
 		i = -1
 	}
@@ -114,8 +127,39 @@ func parseFloat64(buf []byte) (f float64) {
 	return
 }
 
-// parseString parses a []byte and returns a string
+// parseGPSCoord parses a XMP GPSCoordinate and returns the value in decimal degrees.
+// The XMP format is "DDD,MM,SSk" or "DDD,MM.mmk" where k is N, S, E or W.
+// Falls back to parsing a decimal value.
+func parseGPSCoord(buf []byte) (f float64) {
+	if len(buf) < 2 {
+		return parseFloat64(buf)
+	}
+	sign := 1.0
+	switch buf[len(buf)-1] {
+	case 'S', 's', 'W', 'w':
+		sign = -1.0
+	case 'N', 'n', 'E', 'e':
+	default:
+		return parseFloat64(buf)
+	}
+	buf = buf[:len(buf)-1]
+
+	div := 1.0
+	for len(buf) > 0 {
+		var v []byte
+		v, buf = readUntil(buf, ',')
+		f += parseFloat64(v) / div
+		div *= 60
+	}
+	return sign * f
+}
+
+// parseString parses a []byte and returns a string.
+// XML character and entity references are unescaped.
 func parseString(buf []byte) string {
+	if bytes.IndexByte(buf, '&') >= 0 {
+		return html.UnescapeString(string(buf))
+	}
 	return string(buf)
 }
 
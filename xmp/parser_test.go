@@ -0,0 +1,51 @@
+package xmp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:exif="http://ns.adobe.com/exif/1.0/"
+    exif:GPSAltitude="12345/100">
+   <dc:description>
+    <rdf:Alt>
+     <rdf:li xml:lang="x-default">Fish &amp; Chips &#x263A;</rdf:li>
+    </rdf:Alt>
+   </dc:description>
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>`
+	x, err := ParseXmp(strings.NewReader(packet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The xml:lang attribute of the rdf:li is not a description
+	if len(x.DC.Description) != 1 || x.DC.Description[0] != "Fish & Chips ☺" {
+		t.Errorf("Incorrect Description wanted %q got %q", []string{"Fish & Chips ☺"}, x.DC.Description)
+	}
+	if x.Exif.GPSAltitude != 123.45 {
+		t.Errorf("Incorrect GPSAltitude wanted %f got %f", 123.45, x.Exif.GPSAltitude)
+	}
+
+	for _, test := range []struct {
+		value string
+		alt   float32
+	}{
+		{"1000", 1000},
+		{"3/2", 1.5},
+		{"5/0", 0},
+	} {
+		x, err = ParseXmp(strings.NewReader(strings.Replace(packet, "12345/100", test.value, 1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Exif.GPSAltitude != test.alt {
+			t.Errorf("Incorrect GPSAltitude of %q wanted %f got %f", test.value, test.alt, x.Exif.GPSAltitude)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package xmp
+
+import (
+	"time"
+
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+// Photoshop namespace tags.
+//
+//	xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/"
+//
+// This implementation is incomplete and based on https://exiftool.org/TagNames/XMP.html#photoshop
+type Photoshop struct {
+	DateCreated time.Time
+}
+
+func (ps *Photoshop) parse(p property) (err error) {
+	switch p.Name() {
+	case xmpns.DateCreated:
+		ps.DateCreated, err = parseDate(p.Value())
+	default:
+		return ErrPropertyNotSet
+	}
+	return
+}
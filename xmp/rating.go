@@ -0,0 +1,195 @@
+package xmp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+
+	"github.com/evanoberholster/imagemeta/meta"
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+// ErrRatingNotValid is returned when a rating is not -1 or in the range [0..5].
+var ErrRatingNotValid = errors.New("xmp: error rating not valid")
+
+// Color labels as named by Lightroom and Bridge. xmp:Label is free text,
+// other applications may use other names.
+const (
+	LabelRed    = "Red"
+	LabelYellow = "Yellow"
+	LabelGreen  = "Green"
+	LabelBlue   = "Blue"
+	LabelPurple = "Purple"
+)
+
+// Rating values
+const (
+	RatingRejected int8 = -1
+	RatingUnrated  int8 = 0
+	RatingMax      int8 = 5
+)
+
+// xmp:Rating and xmp:Label as an attribute or an element, with the xmp or the older xap prefix.
+var (
+	ratingProperty = newBasicProperty("Rating")
+	labelProperty  = newBasicProperty("Label")
+)
+
+// basicProperty matches a simple property of the XMP basic namespace in an XMP packet.
+type basicProperty struct {
+	name string
+	attr *regexp.Regexp
+	elem *regexp.Regexp
+}
+
+func newBasicProperty(name string) basicProperty {
+	return basicProperty{
+		name: name,
+		attr: regexp.MustCompile(`\s(?:xmp|xap):` + name + `\s*=\s*(?:"[^"]*"|'[^']*')`),
+		elem: regexp.MustCompile(`<(?:xmp|xap):` + name + `>[^<]*</(?:xmp|xap):` + name + `>`),
+	}
+}
+
+// SetRating sets xmp:Rating to rating. Returns ErrRatingNotValid if rating is
+// not -1 for rejected, 0 for unrated or in the range [1..5].
+func (basic *Basic) SetRating(rating int8) error {
+	if rating < RatingRejected || rating > RatingMax {
+		return ErrRatingNotValid
+	}
+	basic.Rating = rating
+	return nil
+}
+
+// SetRating returns packet with xmp:Rating set to rating and xmp:Label set to label,
+// for culling tools that write ratings and color labels back to images and sidecars.
+// An empty label removes xmp:Label.
+//
+// Only the xmp:Rating and xmp:Label properties of packet are changed, all other
+// properties and the layout of packet are kept. The properties are added to the
+// start tag of the first rdf:Description if packet does not have them. If packet is empty a new
+// XMP packet is returned.
+//
+// Returns ErrRatingNotValid for an invalid rating and ErrNoXMP if packet does
+// not have an rdf:Description.
+func SetRating(packet []byte, rating int8, label string) ([]byte, error) {
+	var basic Basic
+	if err := basic.SetRating(rating); err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(packet)) == 0 {
+		var err error
+		if packet, err = Marshal(XMP{}); err != nil {
+			return nil, err
+		}
+	}
+	buf, err := setBasicProperty(packet, ratingProperty, strconv.Itoa(int(rating)))
+	if err != nil {
+		return nil, err
+	}
+	return setBasicProperty(buf, labelProperty, label)
+}
+
+// setBasicProperty returns packet with the property p set to value.
+// An empty value removes the property.
+func setBasicProperty(packet []byte, p basicProperty, value string) ([]byte, error) {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(value))
+
+	if loc := p.attr.FindIndex(packet); loc != nil {
+		if value == "" {
+			return removeBytes(packet, loc), nil
+		}
+		// Keep the whitespace and prefix of the attribute
+		attr := packet[loc[0]:loc[1]]
+		i := bytes.IndexByte(attr, '=')
+		return replaceBytes(packet, loc, append(append([]byte(nil), attr[:i]...), []byte(`="`+escaped.String()+`"`)...)), nil
+	}
+	if loc := p.elem.FindIndex(packet); loc != nil {
+		if value == "" {
+			return removeBytes(packet, loc), nil
+		}
+		elem := packet[loc[0]:loc[1]]
+		start := bytes.IndexByte(elem, '>') + 1
+		end := bytes.LastIndex(elem, []byte("</"))
+		return replaceBytes(packet, []int{loc[0] + start, loc[0] + end}, escaped.Bytes()), nil
+	}
+	if value == "" {
+		return packet, nil
+	}
+
+	i := startTagEnd(packet, bytes.Index(packet, []byte("<rdf:Description")))
+	if i < 0 {
+		return nil, ErrNoXMP
+	}
+	attr := "\n    " + xmpns.XmpNS.String() + ":" + p.name + `="` + escaped.String() + `"`
+	if !bytes.Contains(packet, []byte("xmlns:"+xmpns.XmpNS.String()+"=")) {
+		attr = "\n    xmlns:" + xmpns.XmpNS.String() + `="` + xmpns.XmpNS.URI() + `"` + attr
+	}
+	return replaceBytes(packet, []int{i, i}, []byte(attr)), nil
+}
+
+// startTagEnd returns the index of the end ">" or "/>" of the start tag at i
+// in buf. Returns -1 if i is -1 or the start tag does not end.
+func startTagEnd(buf []byte, i int) int {
+	if i < 0 {
+		return -1
+	}
+	var quote byte
+	for ; i < len(buf); i++ {
+		switch c := buf[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			if buf[i-1] == '/' {
+				return i - 1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceBytes returns a copy of buf with buf[loc[0]:loc[1]] replaced by value.
+func replaceBytes(buf []byte, loc []int, value []byte) []byte {
+	out := make([]byte, 0, len(buf)-(loc[1]-loc[0])+len(value))
+	out = append(out, buf[:loc[0]]...)
+	out = append(out, value...)
+	return append(out, buf[loc[1]:]...)
+}
+
+// removeBytes returns a copy of buf without buf[loc[0]:loc[1]].
+func removeBytes(buf []byte, loc []int) []byte {
+	return replaceBytes(buf, loc, nil)
+}
+
+// WriteSidecarRating sets xmp:Rating and xmp:Label of the XMP sidecar file at path
+// with SetRating. The sidecar is created if it does not exist.
+// The other properties of the sidecar are kept. See WriteSidecar for how the file is written.
+func WriteSidecarRating(path string, rating int8, label string) error {
+	packet, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if len(bytes.TrimSpace(packet)) == 0 {
+		var buf bytes.Buffer
+		if err = EncodeSidecar(&buf, XMP{}); err != nil {
+			return err
+		}
+		packet = buf.Bytes()
+	}
+	if packet, err = SetRating(packet, rating, label); err != nil {
+		return err
+	}
+	return meta.SafeWrite(path, func(w io.Writer) error {
+		_, err := w.Write(packet)
+		return err
+	}, sidecarWriteOptions)
+}
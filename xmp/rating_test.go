@@ -0,0 +1,120 @@
+package xmp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetRating(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet string
+		rating int8
+		label  string
+		want   string
+	}{
+		{"Attribute",
+			`<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:Rating="1" xmp:Label='Red' crs:Exposure2012="+0.50"/>`,
+			5, LabelGreen,
+			`<rdf:Description rdf:about="" xmlns:xmp="http://ns.adobe.com/xap/1.0/" xmp:Rating="5" xmp:Label="Green" crs:Exposure2012="+0.50"/>`},
+		{"Element",
+			"<rdf:Description rdf:about=\"\">\n <xap:Rating>3</xap:Rating>\n <xap:Label>Red</xap:Label>\n</rdf:Description>",
+			RatingRejected, "",
+			"<rdf:Description rdf:about=\"\">\n <xap:Rating>-1</xap:Rating>\n \n</rdf:Description>"},
+		{"Insert",
+			`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"/>`,
+			2, "To Do & Check",
+			"<rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\"\n    xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\"\n    xmp:Rating=\"2\"\n    xmp:Label=\"To Do &amp; Check\"/>"},
+		{"InsertQuoted",
+			"<rdf:Description rdf:about=\"\" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\" dc:title=\"a > b\">\n</rdf:Description>",
+			1, "",
+			"<rdf:Description rdf:about=\"\" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\" dc:title=\"a > b\"\n    xmp:Rating=\"1\">\n</rdf:Description>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := SetRating([]byte(test.packet), test.rating, test.label)
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.want, string(buf))
+			}
+		})
+	}
+
+	// A new packet
+	buf, err := SetRating(nil, RatingUnrated, LabelPurple)
+	if !assert.NoError(t, err) {
+		return
+	}
+	x, err := ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Basic{Label: LabelPurple}, x.Basic)
+	assert.Contains(t, string(buf), `xmp:Rating="0"`)
+
+	_, err = SetRating(buf, 6, "")
+	assert.ErrorIs(t, err, ErrRatingNotValid)
+	_, err = SetRating([]byte("<x:xmpmeta/>"), 1, "")
+	assert.ErrorIs(t, err, ErrNoXMP)
+}
+
+func TestWriteSidecarRating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "_MG_1563.xmp")
+	if err := WriteSidecarRating(path, 3, LabelRed); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSidecarRating(path, 4, LabelBlue); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.HasPrefix(buf, []byte("<x:xmpmeta")), "sidecar should start with x:xmpmeta")
+	x, err := ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Basic{Rating: 4, Label: LabelBlue}, x.Basic)
+
+	// The other properties of a sidecar are kept
+	orig, err := os.ReadFile(filepath.Join("test", "1.xmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(path, orig, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = WriteSidecarRating(path, RatingRejected, ""); err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseXmp(bytes.NewReader(orig))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	want.Basic.Rating, want.Basic.Label = RatingRejected, ""
+	if buf, err = os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	x, err = ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, want, x)
+}
+
+func TestBasicSetRating(t *testing.T) {
+	var basic Basic
+	for _, rating := range []int8{RatingRejected, RatingUnrated, 1, RatingMax} {
+		if assert.NoError(t, basic.SetRating(rating)) {
+			assert.Equal(t, rating, basic.Rating)
+		}
+	}
+	for _, rating := range []int8{-2, 6} {
+		assert.ErrorIs(t, basic.SetRating(rating), ErrRatingNotValid)
+	}
+}
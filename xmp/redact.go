@@ -0,0 +1,21 @@
+package xmp
+
+import "regexp"
+
+// privateProperties are the names of the XMP properties with personal data removed by Redact:
+// the exif GPS properties, serial numbers, owner names and ImageUniqueID.
+const privateProperties = `(?:exif:GPS\w+|aux:(?:SerialNumber|LensSerialNumber|OwnerName)|` +
+	`exifEX:(?:BodySerialNumber|LensSerialNumber|CameraOwnerName|ImageUniqueID))`
+
+// privateProperty matches a private property as an attribute or an element with its leading whitespace.
+var privateProperty = regexp.MustCompile(`\s+` + privateProperties + `\s*=\s*(?:"[^"]*"|'[^']*')|` +
+	`\s*<` + privateProperties + `(?:\s[^>]*)?(?:/>|>(?s:.*?)</` + privateProperties + `>)`)
+
+// Redact returns packet without the properties with personal data: the exif GPS
+// properties, the aux and exifEX serial numbers and owner names and exifEX:ImageUniqueID.
+// The other properties and the layout of packet are kept.
+//
+// Properties are matched by the prefixes exif, aux and exifEX.
+func Redact(packet []byte) []byte {
+	return privateProperty.ReplaceAll(packet, nil)
+}
@@ -0,0 +1,30 @@
+package xmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet string
+		want   string
+	}{
+		{"Attribute",
+			`<rdf:Description rdf:about="" exif:GPSLatitude="33,51.408S" exif:GPSLongitude='151,12.918E' exif:ExposureTime="1/60" aux:SerialNumber="2031614"/>`,
+			`<rdf:Description rdf:about="" exif:ExposureTime="1/60"/>`},
+		{"Element",
+			"<rdf:Description rdf:about=\"\">\n <aux:Lens>EF50mm f/1.2L USM</aux:Lens>\n <aux:LensSerialNumber>0000c15998</aux:LensSerialNumber>\n <exifEX:CameraOwnerName>Owner</exifEX:CameraOwnerName>\n <exif:GPSVersionID>2.3.0.0</exif:GPSVersionID>\n</rdf:Description>",
+			"<rdf:Description rdf:about=\"\">\n <aux:Lens>EF50mm f/1.2L USM</aux:Lens>\n</rdf:Description>"},
+		{"Unchanged",
+			`<rdf:Description rdf:about="" aux:Lens="EF50mm f/1.2L USM" exif:ExifVersion="0231"/>`,
+			`<rdf:Description rdf:about="" aux:Lens="EF50mm f/1.2L USM" exif:ExifVersion="0231"/>`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, string(Redact([]byte(test.packet))))
+		})
+	}
+}
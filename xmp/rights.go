@@ -0,0 +1,86 @@
+package xmp
+
+import (
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+// Rights is the XMP Rights Management namespace.
+//
+//	xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/"
+type Rights struct {
+	// Marked is true if the resource is rights-managed. Only written when true.
+	Marked bool
+	// UsageTerms are the instructions on how the resource can be legally used.
+	// XMP usage is a language alternative.
+	UsageTerms []string
+	// WebStatement is the URL of a web page with a statement of the ownership and usage rights.
+	WebStatement string
+}
+
+func (r *Rights) parse(p property) (err error) {
+	switch p.Name() {
+	case xmpns.Marked:
+		r.Marked = parseString(p.Value()) == "True"
+	case xmpns.UsageTerms:
+		if p.pt == tagPType {
+			r.UsageTerms = append(r.UsageTerms, parseString(p.Value()))
+		}
+	case xmpns.WebStatement:
+		r.WebStatement = parseString(p.Value())
+	default:
+		return ErrPropertyNotSet
+	}
+	return nil
+}
+
+func (r Rights) encode(e *encoder) {
+	if r.Marked {
+		e.attr(xmpns.XmpRightsNS, "Marked", formatBool(r.Marked))
+	}
+	e.attr(xmpns.XmpRightsNS, "WebStatement", r.WebStatement)
+	e.array(xmpns.XmpRightsNS, "UsageTerms", rdfAlt, r.UsageTerms, nil)
+}
+
+// IPTCCore is the IPTC Core namespace.
+//
+//	xmlns:Iptc4xmpCore="http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/"
+//
+// This implementation is incomplete and only has the contact information of the creator.
+type IPTCCore struct {
+	// CreatorContactInfo is the contact information of the creator of the resource.
+	CreatorContactInfo ContactInfo
+}
+
+// ContactInfo is the Iptc4xmpCore:CreatorContactInfo struct.
+type ContactInfo struct {
+	Email string // Iptc4xmpCore:CiEmailWork
+	URL   string // Iptc4xmpCore:CiUrlWork
+	Phone string // Iptc4xmpCore:CiTelWork
+}
+
+func (core *IPTCCore) parse(p property) (err error) {
+	switch p.Name() {
+	case xmpns.CiEmailWork:
+		core.CreatorContactInfo.Email = parseString(p.Value())
+	case xmpns.CiUrlWork:
+		core.CreatorContactInfo.URL = parseString(p.Value())
+	case xmpns.CiTelWork:
+		core.CreatorContactInfo.Phone = parseString(p.Value())
+	default:
+		return ErrPropertyNotSet
+	}
+	return nil
+}
+
+func (core IPTCCore) encode(e *encoder) {
+	ci := core.CreatorContactInfo
+	var fields []attribute
+	for _, f := range []attribute{{"CiEmailWork", ci.Email}, {"CiTelWork", ci.Phone}, {"CiUrlWork", ci.URL}} {
+		if f.val != "" {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) > 0 {
+		e.resource(xmpns.Iptc4xmpCoreNS, "CreatorContactInfo", fields)
+	}
+}
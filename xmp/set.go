@@ -0,0 +1,105 @@
+package xmp
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+)
+
+// SetProperties returns packet with the properties props set to their values in x,
+// as they are written by Marshal. Properties with a zero value in x are removed.
+//
+// Only the props of packet are changed, all other properties, including the
+// properties that are not supported by package xmp, and the layout of packet are
+// kept. Simple properties are added as attributes of the first rdf:Description,
+// array and struct properties as its first elements, with the declaration of their
+// namespace if packet does not have it. If packet is empty a new XMP packet is returned.
+//
+// Properties are matched by the prefixes of package xmpns. Returns ErrNoXMP if
+// packet does not have an rdf:Description.
+func SetProperties(packet []byte, x XMP, props ...xmpns.Property) ([]byte, error) {
+	if len(bytes.TrimSpace(packet)) == 0 {
+		var err error
+		if packet, err = Marshal(XMP{}); err != nil {
+			return nil, err
+		}
+	}
+	e := newEncoder(nil)
+	e.encode(x)
+	for _, p := range props {
+		ns, name := p.Namespace(), p.Name().String()
+		packet = removeProperty(packet, ns.String()+":"+name)
+		value, isAttr := e.property(ns, name)
+		if value == nil {
+			continue
+		}
+		var err error
+		if packet, err = insertProperty(packet, ns, value, isAttr); err != nil {
+			return nil, err
+		}
+	}
+	return packet, nil
+}
+
+// property returns the encoding of the property name of ns, and true if it is
+// an attribute. Returns nil if e does not have the property.
+func (e *encoder) property(ns xmpns.Namespace, name string) ([]byte, bool) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	for _, a := range e.attrs[ns] {
+		if a.name == name {
+			writeAttribute(bw, ns, a)
+			bw.Flush()
+			return buf.Bytes(), true
+		}
+	}
+	for _, el := range e.elems[ns] {
+		if el.name == name {
+			writeElement(bw, ns, el)
+			bw.Flush()
+			return buf.Bytes(), false
+		}
+	}
+	return nil, false
+}
+
+// removeProperty returns packet without the property with the qualified name,
+// as an attribute or an element of any rdf:Description.
+func removeProperty(packet []byte, name string) []byte {
+	name = regexp.QuoteMeta(name)
+	attr := regexp.MustCompile(`\s+` + name + `\s*=\s*(?:"[^"]*"|'[^']*')`)
+	elem := regexp.MustCompile(`[ \t]*<` + name + `(?:\s[^>]*)?(?:/>|>(?s:.*?)</` + name + `>)[ \t]*\r?\n?`)
+	return elem.ReplaceAll(attr.ReplaceAll(packet, nil), nil)
+}
+
+// insertProperty returns packet with the encoded property value added to the first
+// rdf:Description, as an attribute if isAttr is true or else as its first element.
+func insertProperty(packet []byte, ns xmpns.Namespace, value []byte, isAttr bool) ([]byte, error) {
+	i := startTagEnd(packet, bytes.Index(packet, []byte("<rdf:Description")))
+	if i < 0 {
+		return nil, ErrNoXMP
+	}
+	// The namespace is declared by the rdf:Description or its parents
+	if !bytes.Contains(packet[:i], []byte("xmlns:"+ns.String()+"=")) {
+		decl := []byte("\n    xmlns:" + ns.String() + `="` + ns.URI() + `"`)
+		packet = replaceBytes(packet, []int{i, i}, decl)
+		i += len(decl)
+	}
+	if isAttr {
+		return replaceBytes(packet, []int{i, i}, value), nil
+	}
+	if packet[i] == '/' {
+		// Empty element
+		value = append(append([]byte(">\n"), value...), "  </rdf:Description>"...)
+		return replaceBytes(packet, []int{i, i + 2}, value), nil
+	}
+	i++
+	if i < len(packet) && packet[i] == '\n' {
+		i++
+	} else {
+		value = append([]byte("\n"), value...)
+	}
+	return replaceBytes(packet, []int{i, i}, value), nil
+}
@@ -0,0 +1,80 @@
+package xmp
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/evanoberholster/imagemeta/xmp/xmpns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetProperties(t *testing.T) {
+	creator := xmpns.NewProperty(xmpns.DcNS, xmpns.Creator)
+	marked := xmpns.NewProperty(xmpns.XmpRightsNS, xmpns.Marked)
+	webStatement := xmpns.NewProperty(xmpns.XmpRightsNS, xmpns.WebStatement)
+	contact := xmpns.NewProperty(xmpns.Iptc4xmpCoreNS, xmpns.CreatorContactInfo)
+	x := XMP{
+		DC:       DublinCore{Creator: []string{"Studio"}},
+		Rights:   Rights{WebStatement: "https://example.com/license"},
+		IPTCCore: IPTCCore{CreatorContactInfo: ContactInfo{Email: "studio@example.com"}},
+	}
+
+	tests := []struct {
+		name   string
+		packet string
+		props  []xmpns.Property
+		want   string
+	}{
+		{"Attribute",
+			`<rdf:Description rdf:about="" xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/" xmpRights:WebStatement='old' xmpRights:Marked="True" custom:Value="1"/>`,
+			[]xmpns.Property{webStatement, marked},
+			`<rdf:Description rdf:about="" xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/" custom:Value="1"` + "\n    xmpRights:WebStatement=\"https://example.com/license\"/>"},
+		{"Element",
+			"<rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n   <dc:creator>\n    <rdf:Seq>\n     <rdf:li>Old</rdf:li>\n    </rdf:Seq>\n   </dc:creator>\n   <custom:Value>1</custom:Value>\n</rdf:Description>",
+			[]xmpns.Property{creator},
+			"<rdf:Description rdf:about=\"\" xmlns:dc=\"http://purl.org/dc/elements/1.1/\">\n   <dc:creator>\n    <rdf:Seq>\n     <rdf:li>Studio</rdf:li>\n    </rdf:Seq>\n   </dc:creator>\n   <custom:Value>1</custom:Value>\n</rdf:Description>"},
+		{"Insert",
+			`<rdf:Description rdf:about=""/>`,
+			[]xmpns.Property{contact},
+			"<rdf:Description rdf:about=\"\"\n    xmlns:Iptc4xmpCore=\"http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/\">\n   <Iptc4xmpCore:CreatorContactInfo rdf:parseType=\"Resource\">\n    <Iptc4xmpCore:CiEmailWork>studio@example.com</Iptc4xmpCore:CiEmailWork>\n   </Iptc4xmpCore:CreatorContactInfo>\n  </rdf:Description>"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf, err := SetProperties([]byte(test.packet), x, test.props...)
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.want, string(buf))
+			}
+		})
+	}
+
+	// Properties of other rdf:Descriptions are replaced
+	packet := []byte(`<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` +
+		`<rdf:Description rdf:about="" xmlns:tiff="http://ns.adobe.com/tiff/1.0/" tiff:Make="Canon"/>` +
+		`<rdf:Description rdf:about="" xmlns:dc="http://purl.org/dc/elements/1.1/"><dc:creator><rdf:Seq><rdf:li>Old</rdf:li></rdf:Seq></dc:creator></rdf:Description>` +
+		`</rdf:RDF></x:xmpmeta>`)
+	buf, err := SetProperties(packet, x, creator, contact)
+	if !assert.NoError(t, err) {
+		return
+	}
+	parsed, err := ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Canon", parsed.Tiff.Make)
+	assert.Equal(t, x.DC.Creator, parsed.DC.Creator)
+	assert.Equal(t, x.IPTCCore, parsed.IPTCCore)
+	assert.NotContains(t, string(buf), "Old")
+
+	// A new packet
+	if buf, err = SetProperties(nil, x, webStatement); assert.NoError(t, err) {
+		parsed, err = ParseXmp(bytes.NewReader(buf))
+		if err != nil && err != io.EOF {
+			t.Fatal(err)
+		}
+		assert.Equal(t, x.Rights, parsed.Rights)
+	}
+
+	_, err = SetProperties([]byte("<x:xmpmeta/>"), x, creator)
+	assert.ErrorIs(t, err, ErrNoXMP)
+}
@@ -0,0 +1,45 @@
+package xmp
+
+import (
+	"bufio"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/evanoberholster/imagemeta/meta"
+)
+
+// sidecarExt is the file extension of XMP sidecar files.
+const sidecarExt = ".xmp"
+
+// sidecarWriteOptions are the options of sidecar files written with meta.SafeWrite.
+var sidecarWriteOptions = meta.SafeWriteOptions{Mode: 0o644}
+
+// SidecarPath returns the path of the XMP sidecar file of the image at path.
+// The extension of path is replaced with ".xmp", as Lightroom and Camera Raw
+// name sidecar files: "IMG_0001.CR2" has the sidecar "IMG_0001.xmp".
+func SidecarPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + sidecarExt
+}
+
+// EncodeSidecar writes the XMP sidecar encoding of x to w.
+//
+// A sidecar is the x:xmpmeta element without the xpacket header, trailer
+// and padding of an embedded XMP packet, as written by Lightroom.
+// See Encode for details about the encoding of the properties.
+func EncodeSidecar(w io.Writer, x XMP) error {
+	bw := bufio.NewWriter(w)
+	writeXMPMeta(bw, x)
+	return bw.Flush()
+}
+
+// WriteSidecar writes x as an XMP sidecar file at path, for images such as
+// camera raw files that should not be changed. The sidecar is written with
+// meta.SafeWrite, so an existing sidecar is not left incomplete if writing fails.
+//
+// See SidecarPath for the path of the sidecar of an image.
+func WriteSidecar(path string, x XMP) error {
+	return meta.SafeWrite(path, func(w io.Writer) error {
+		return EncodeSidecar(w, x)
+	}, sidecarWriteOptions)
+}
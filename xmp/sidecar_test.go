@@ -0,0 +1,70 @@
+package xmp
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSidecarPath(t *testing.T) {
+	tests := []struct {
+		path, sidecar string
+	}{
+		{"IMG_0001.CR2", "IMG_0001.xmp"},
+		{filepath.Join("photos", "2021.01", "DSC_0001.NEF"), filepath.Join("photos", "2021.01", "DSC_0001.xmp")},
+		{"image", "image.xmp"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.sidecar, SidecarPath(test.path))
+	}
+}
+
+func TestWriteSidecar(t *testing.T) {
+	x := XMP{
+		Tiff:  Tiff{Make: "Canon", Model: "Canon EOS 6D", Orientation: 6},
+		Basic: Basic{CreateDate: time.Date(2021, 1, 10, 17, 30, 57, 0, time.UTC), Rating: 4, Label: "Red"},
+		DC:    DublinCore{Creator: []string{"Evan Oberholster"}, Subject: []string{"Coron"}},
+		CRS:   CRS{RawFileName: "_MG_1563.CR2"},
+	}
+	dir := t.TempDir()
+	path := SidecarPath(filepath.Join(dir, "_MG_1563.CR2"))
+	if err := os.WriteFile(path, []byte("old sidecar"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSidecar(path, x); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, bytes.HasPrefix(buf, []byte("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">")), "sidecar should start with x:xmpmeta")
+	assert.False(t, bytes.Contains(buf, []byte("<?xpacket")), "sidecar should not have an xpacket")
+	assert.True(t, bytes.HasSuffix(buf, []byte("</x:xmpmeta>\n")))
+
+	x2, err := ParseXmp(bytes.NewReader(buf))
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+	assert.Equal(t, x.Tiff, x2.Tiff)
+	assert.Equal(t, x.CRS, x2.CRS)
+	assert.Equal(t, x.DC.Creator, x2.DC.Creator)
+	assert.Equal(t, x.Basic.Rating, x2.Basic.Rating)
+	assert.True(t, x.Basic.CreateDate.Equal(x2.Basic.CreateDate))
+
+	if fi, err := os.Stat(path); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0o644), fi.Mode().Perm())
+	}
+	entries, err := os.ReadDir(dir)
+	if assert.NoError(t, err) {
+		assert.Len(t, entries, 1)
+	}
+
+	assert.Error(t, WriteSidecar(filepath.Join(dir, "missing", "a.xmp"), x))
+}
@@ -1,4 +1,4 @@
-// Package xmp provides functions for decoding .xmp sidecar files and XMP embedded within image files
+// Package xmp provides functions for decoding and encoding .xmp sidecar files and XMP embedded within image files
 package xmp
 
 import (
@@ -26,6 +26,14 @@ type XMP struct {
 	DC    DublinCore // xmlns:dc="http://purl.org/dc/elements/1.1/"
 	CRS   CRS
 	MM    XMPMM
+	// xmlns:photoshop="http://ns.adobe.com/photoshop/1.0/"
+	Photoshop Photoshop
+	// xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/"
+	Rights Rights
+	// xmlns:Iptc4xmpCore="http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/"
+	IPTCCore IPTCCore
+	// xmlns:GCamera="http://ns.google.com/photos/1.0/camera/"
+	GCamera GCamera
 }
 
 // ParseXmp reads XMP Metadata from the given reader and returns XMP.
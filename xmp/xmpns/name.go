@@ -33,6 +33,9 @@ const (
 	BrightnessValue
 	CameraOwnerName
 	Changed
+	CiEmailWork
+	CiTelWork
+	CiUrlWork
 	ColorMode
 	ColorSpace
 	ComponentsConfiguration
@@ -41,6 +44,7 @@ const (
 	Contrast
 	CreateDate
 	Creator
+	CreatorContactInfo
 	CreatorTool
 	CustomRendered
 	DateCreated
@@ -50,6 +54,7 @@ const (
 	DerivedFrom
 	Description
 	DigitalZoomRatio
+	Directory
 	DocumentID
 	EmbeddedXMPDigest
 	ExifVersion
@@ -92,9 +97,11 @@ const (
 	InstanceID
 	InteroperabilityIndex
 	ISOSpeedRatings
+	Item
 	Label
 	Lang
 	LegacyIPTCDigest
+	Length
 	Lens
 	LensID
 	LensInfo
@@ -103,15 +110,23 @@ const (
 	Li
 	LightSource
 	Make
+	Marked
 	MaxApertureValue
 	MetadataDate
 	MeteringMode
+	MicroVideo
+	MicroVideoOffset
+	MicroVideoVersion
+	Mime
 	Mode
 	Model
 	ModifyDate
+	MotionPhoto
+	MotionPhotoVersion
 	NativeDigest
 	Orientation
 	OriginalDocumentID
+	Padding
 	ParseType // parseType
 	PhotometricInterpretation
 	PixelXDimension
@@ -130,6 +145,7 @@ const (
 	Saturation
 	SceneCaptureType
 	SceneType
+	Semantic
 	SensitivityType
 	Seq
 	SerialNumber
@@ -154,6 +170,7 @@ const (
 	ToneCurvePV2012Green
 	ToneCurvePV2012Red
 	ToneCurveRed
+	UsageTerms
 	UserComment
 	VideoFieldOrder
 	VideoFrameRate
@@ -161,6 +178,7 @@ const (
 	VideoPixelAspectRatio
 	VideoPixelDepth
 	W
+	WebStatement
 	When
 	WhiteBalance
 	Xap
@@ -188,6 +206,9 @@ var mapNameString = map[Name]string{
 	BrightnessValue:           "BrightnessValue",
 	CameraOwnerName:           "CameraOwnerName",
 	Changed:                   "changed",
+	CiEmailWork:               "CiEmailWork",
+	CiTelWork:                 "CiTelWork",
+	CiUrlWork:                 "CiUrlWork",
 	ColorMode:                 "ColorMode",
 	ColorSpace:                "ColorSpace",
 	ComponentsConfiguration:   "ComponentsConfiguration",
@@ -196,6 +217,7 @@ var mapNameString = map[Name]string{
 	Contrast:                  "Contrast",
 	CreateDate:                "CreateDate",
 	Creator:                   "creator",
+	CreatorContactInfo:        "CreatorContactInfo",
 	CreatorTool:               "CreatorTool",
 	CustomRendered:            "CustomRendered",
 	DateCreated:               "DateCreated",
@@ -205,6 +227,7 @@ var mapNameString = map[Name]string{
 	DerivedFrom:               "DerivedFrom",
 	Description:               "Description",
 	DigitalZoomRatio:          "DigitalZoomRatio",
+	Directory:                 "Directory",
 	DocumentID:                "DocumentID",
 	EmbeddedXMPDigest:         "EmbeddedXMPDigest",
 	ExifVersion:               "ExifVersion",
@@ -247,9 +270,11 @@ var mapNameString = map[Name]string{
 	InstanceID:                "InstanceID",
 	InteroperabilityIndex:     "InteroperabilityIndex",
 	ISOSpeedRatings:           "ISOSpeedRatings",
+	Item:                      "Item",
 	Label:                     "Label",
 	Lang:                      "lang",
 	LegacyIPTCDigest:          "LegacyIPTCDigest",
+	Length:                    "Length",
 	Lens:                      "Lens",
 	LensID:                    "LensID",
 	LensInfo:                  "LensInfo",
@@ -258,15 +283,23 @@ var mapNameString = map[Name]string{
 	Li:                        "li",
 	LightSource:               "LightSource",
 	Make:                      "Make",
+	Marked:                    "Marked",
 	MaxApertureValue:          "MaxApertureValue",
 	MetadataDate:              "MetadataDate",
 	MeteringMode:              "MeteringMode",
+	MicroVideo:                "MicroVideo",
+	MicroVideoOffset:          "MicroVideoOffset",
+	MicroVideoVersion:         "MicroVideoVersion",
+	Mime:                      "Mime",
 	Mode:                      "Mode",
 	Model:                     "Model",
 	ModifyDate:                "ModifyDate",
+	MotionPhoto:               "MotionPhoto",
+	MotionPhotoVersion:        "MotionPhotoVersion",
 	NativeDigest:              "NativeDigest",
 	Orientation:               "Orientation",
 	OriginalDocumentID:        "OriginalDocumentID",
+	Padding:                   "Padding",
 	ParseType:                 "parseType",
 	PhotometricInterpretation: "PhotometricInterpretation",
 	PixelXDimension:           "PixelXDimension",
@@ -285,6 +318,7 @@ var mapNameString = map[Name]string{
 	Saturation:                "Saturation",
 	SceneCaptureType:          "SceneCaptureType",
 	SceneType:                 "SceneType",
+	Semantic:                  "Semantic",
 	SensitivityType:           "SensitivityType",
 	Seq:                       "Seq",
 	SerialNumber:              "SerialNumber",
@@ -309,6 +343,7 @@ var mapNameString = map[Name]string{
 	ToneCurvePV2012Green:      "ToneCurvePV2012Green",
 	ToneCurvePV2012Red:        "ToneCurvePV2012Red",
 	ToneCurveRed:              "ToneCurveRed",
+	UsageTerms:                "UsageTerms",
 	UserComment:               "UserComment",
 	VideoFieldOrder:           "videoFieldOrder",
 	VideoFrameRate:            "videoFrameRate",
@@ -316,6 +351,7 @@ var mapNameString = map[Name]string{
 	VideoPixelAspectRatio:     "videoPixelAspectRatio",
 	VideoPixelDepth:           "videoPixelDepth",
 	W:                         "w",
+	WebStatement:              "WebStatement",
 	When:                      "when",
 	WhiteBalance:              "WhiteBalance",
 	Xap:                       "xap",
@@ -342,6 +378,9 @@ var mapStringName = map[string]Name{
 	"BrightnessValue":           BrightnessValue,
 	"CameraOwnerName":           CameraOwnerName,
 	"changed":                   Changed,
+	"CiEmailWork":               CiEmailWork,
+	"CiTelWork":                 CiTelWork,
+	"CiUrlWork":                 CiUrlWork,
 	"ColorMode":                 ColorMode,
 	"ColorSpace":                ColorSpace,
 	"ComponentsConfiguration":   ComponentsConfiguration,
@@ -350,6 +389,7 @@ var mapStringName = map[string]Name{
 	"Contrast":                  Contrast,
 	"CreateDate":                CreateDate,
 	"creator":                   Creator,
+	"CreatorContactInfo":        CreatorContactInfo,
 	"CreatorTool":               CreatorTool,
 	"CustomRendered":            CustomRendered,
 	"DateCreated":               DateCreated,
@@ -360,6 +400,7 @@ var mapStringName = map[string]Name{
 	"Description":               Description,
 	"description":               Description,
 	"DigitalZoomRatio":          DigitalZoomRatio,
+	"Directory":                 Directory,
 	"DocumentID":                DocumentID,
 	"EmbeddedXMPDigest":         EmbeddedXMPDigest,
 	"ExifVersion":               ExifVersion,
@@ -403,9 +444,11 @@ var mapStringName = map[string]Name{
 	"InstanceID":                InstanceID,
 	"InteroperabilityIndex":     InteroperabilityIndex,
 	"ISOSpeedRatings":           ISOSpeedRatings,
+	"Item":                      Item,
 	"Label":                     Label,
 	"lang":                      Lang,
 	"LegacyIPTCDigest":          LegacyIPTCDigest,
+	"Length":                    Length,
 	"Lens":                      Lens,
 	"LensID":                    LensID,
 	"LensInfo":                  LensInfo,
@@ -414,15 +457,23 @@ var mapStringName = map[string]Name{
 	"li":                        Li,
 	"LightSource":               LightSource,
 	"Make":                      Make,
+	"Marked":                    Marked,
 	"MaxApertureValue":          MaxApertureValue,
 	"MetadataDate":              MetadataDate,
 	"MeteringMode":              MeteringMode,
+	"MicroVideo":                MicroVideo,
+	"MicroVideoOffset":          MicroVideoOffset,
+	"MicroVideoVersion":         MicroVideoVersion,
+	"Mime":                      Mime,
 	"Mode":                      Mode,
 	"Model":                     Model,
 	"ModifyDate":                ModifyDate,
+	"MotionPhoto":               MotionPhoto,
+	"MotionPhotoVersion":        MotionPhotoVersion,
 	"NativeDigest":              NativeDigest,
 	"Orientation":               Orientation,
 	"OriginalDocumentID":        OriginalDocumentID,
+	"Padding":                   Padding,
 	"parseType":                 ParseType,
 	"PhotometricInterpretation": PhotometricInterpretation,
 	"PixelXDimension":           PixelXDimension,
@@ -442,6 +493,7 @@ var mapStringName = map[string]Name{
 	"Saturation":                Saturation,
 	"SceneCaptureType":          SceneCaptureType,
 	"SceneType":                 SceneType,
+	"Semantic":                  Semantic,
 	"SensitivityType":           SensitivityType,
 	"Seq":                       Seq,
 	"SerialNumber":              SerialNumber,
@@ -468,6 +520,7 @@ var mapStringName = map[string]Name{
 	"ToneCurvePV2012Red":        ToneCurvePV2012Red,
 	"ToneCurveRed":              ToneCurveRed,
 	"Unknown":                   UnknownPropertyName,
+	"UsageTerms":                UsageTerms,
 	"UserComment":               UserComment,
 	"videoFieldOrder":           VideoFieldOrder,
 	"videoFrameRate":            VideoFrameRate,
@@ -475,6 +528,7 @@ var mapStringName = map[string]Name{
 	"videoPixelAspectRatio":     VideoPixelAspectRatio,
 	"videoPixelDepth":           VideoPixelDepth,
 	"w":                         W,
+	"WebStatement":              WebStatement,
 	"when":                      When,
 	"WhiteBalance":              WhiteBalance,
 	"xap":                       Xap,
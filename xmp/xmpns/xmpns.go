@@ -59,56 +59,112 @@ const (
 	XmpDMNS
 	// xmlns:xmpMM="http://ns.adobe.com/xap/1.0/mm/"
 	XmpMMNS
+	// xmlns:xmpRights="http://ns.adobe.com/xap/1.0/rights/"
+	XmpRightsNS
+	// xmlns:Iptc4xmpCore="http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/"
+	Iptc4xmpCoreNS
+	// xmlns:Container="http://ns.google.com/photos/1.0/container/"
+	ContainerNS
+	// xmlns:GCamera="http://ns.google.com/photos/1.0/camera/"
+	GCameraNS
+	// xmlns:Item="http://ns.google.com/photos/1.0/container/item/"
+	ItemNS
 )
 
 var mapStringNS = map[string]Namespace{
-	"Unknown":   UnknownNS,
-	"aux":       AuxNS,
-	"crs":       CrsNS,
-	"darktable": DarktableNS,
-	"dc":        DcNS,
-	"exif":      ExifNS,
-	"exifEX":    ExifEXNS,
-	"lr":        LrNS,
-	"photoshop": PhotoshopNS,
-	"pmi":       PmiNS,
-	"rdf":       RdfNS,
-	"stDim":     StDimNS,
-	"stEvt":     StEvtNS,
-	"stRef":     StRefNS,
-	"tiff":      TiffNS,
-	"x":         XNS,
-	"xap":       XapNS,
-	"xapMM":     XapMMNS,
-	"xml":       XMLNS,
-	"xmlns":     XMLnsNS,
-	"xmp":       XmpNS,
-	"xmpDM":     XmpDMNS,
-	"xmpMM":     XmpMMNS,
+	"Unknown":      UnknownNS,
+	"aux":          AuxNS,
+	"crs":          CrsNS,
+	"Container":    ContainerNS,
+	"darktable":    DarktableNS,
+	"dc":           DcNS,
+	"exif":         ExifNS,
+	"exifEX":       ExifEXNS,
+	"Camera":       GCameraNS,
+	"GCamera":      GCameraNS,
+	"Iptc4xmpCore": Iptc4xmpCoreNS,
+	"Item":         ItemNS,
+	"lr":           LrNS,
+	"photoshop":    PhotoshopNS,
+	"pmi":          PmiNS,
+	"rdf":          RdfNS,
+	"stDim":        StDimNS,
+	"stEvt":        StEvtNS,
+	"stRef":        StRefNS,
+	"tiff":         TiffNS,
+	"x":            XNS,
+	"xap":          XapNS,
+	"xapMM":        XapMMNS,
+	"xml":          XMLNS,
+	"xmlns":        XMLnsNS,
+	"xmp":          XmpNS,
+	"xmpDM":        XmpDMNS,
+	"xmpMM":        XmpMMNS,
+	"xmpRights":    XmpRightsNS,
 }
 
 var mapNSString = map[Namespace]string{
-	UnknownNS:   "Unknown",
-	AuxNS:       "aux",
-	CrsNS:       "crs",
-	DarktableNS: "darktable",
-	DcNS:        "dc",
-	ExifNS:      "exif",
-	ExifEXNS:    "exifEX",
-	LrNS:        "lr",
-	PhotoshopNS: "photoshop",
-	PmiNS:       "pmi",
-	RdfNS:       "rdf",
-	StDimNS:     "stDim",
-	StEvtNS:     "stEvt",
-	StRefNS:     "stRef",
-	TiffNS:      "tiff",
-	XNS:         "x",
-	XapNS:       "xap",
-	XapMMNS:     "xapMM",
-	XMLNS:       "xml",
-	XMLnsNS:     "xmlns",
-	XmpNS:       "xmp",
-	XmpDMNS:     "xmpDM",
-	XmpMMNS:     "xmpMM",
+	UnknownNS:      "Unknown",
+	AuxNS:          "aux",
+	CrsNS:          "crs",
+	ContainerNS:    "Container",
+	DarktableNS:    "darktable",
+	DcNS:           "dc",
+	ExifNS:         "exif",
+	ExifEXNS:       "exifEX",
+	GCameraNS:      "GCamera",
+	Iptc4xmpCoreNS: "Iptc4xmpCore",
+	ItemNS:         "Item",
+	LrNS:           "lr",
+	PhotoshopNS:    "photoshop",
+	PmiNS:          "pmi",
+	RdfNS:          "rdf",
+	StDimNS:        "stDim",
+	StEvtNS:        "stEvt",
+	StRefNS:        "stRef",
+	TiffNS:         "tiff",
+	XNS:            "x",
+	XapNS:          "xap",
+	XapMMNS:        "xapMM",
+	XMLNS:          "xml",
+	XMLnsNS:        "xmlns",
+	XmpNS:          "xmp",
+	XmpDMNS:        "xmpDM",
+	XmpMMNS:        "xmpMM",
+	XmpRightsNS:    "xmpRights",
+}
+
+// URI returns the XML Namespace URI of ns.
+// Returns an empty string if the URI is not known.
+func (ns Namespace) URI() string {
+	return mapNSURI[ns]
+}
+
+var mapNSURI = map[Namespace]string{
+	AuxNS:          "http://ns.adobe.com/exif/1.0/aux/",
+	CrsNS:          "http://ns.adobe.com/camera-raw-settings/1.0/",
+	ContainerNS:    "http://ns.google.com/photos/1.0/container/",
+	DarktableNS:    "http://darktable.sf.net/",
+	DcNS:           "http://purl.org/dc/elements/1.1/",
+	ExifNS:         "http://ns.adobe.com/exif/1.0/",
+	ExifEXNS:       "http://cipa.jp/exif/1.0/",
+	GCameraNS:      "http://ns.google.com/photos/1.0/camera/",
+	Iptc4xmpCoreNS: "http://iptc.org/std/Iptc4xmpCore/1.0/xmlns/",
+	ItemNS:         "http://ns.google.com/photos/1.0/container/item/",
+	LrNS:           "http://ns.adobe.com/lightroom/1.0/",
+	PhotoshopNS:    "http://ns.adobe.com/photoshop/1.0/",
+	PmiNS:          "http://prismstandard.org/namespaces/pmi/2.2/",
+	RdfNS:          "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	StDimNS:        "http://ns.adobe.com/xap/1.0/sType/Dimensions#",
+	StEvtNS:        "http://ns.adobe.com/xap/1.0/sType/ResourceEvent#",
+	StRefNS:        "http://ns.adobe.com/xap/1.0/sType/ResourceRef#",
+	TiffNS:         "http://ns.adobe.com/tiff/1.0/",
+	XNS:            "adobe:ns:meta/",
+	XapNS:          "http://ns.adobe.com/xap/1.0/",
+	XapMMNS:        "http://ns.adobe.com/xap/1.0/mm/",
+	XMLNS:          "http://www.w3.org/XML/1998/namespace",
+	XmpNS:          "http://ns.adobe.com/xap/1.0/",
+	XmpDMNS:        "http://ns.adobe.com/xmp/1.0/DynamicMedia/",
+	XmpMMNS:        "http://ns.adobe.com/xap/1.0/mm/",
+	XmpRightsNS:    "http://ns.adobe.com/xap/1.0/rights/",
 }
@@ -43,3 +43,12 @@ func TestNamespace(t *testing.T) {
 		t.Errorf("Incorrect Name String wanted %s got %s", "exif", ns.String())
 	}
 }
+
+func TestNamespaceURI(t *testing.T) {
+	if uri := TiffNS.URI(); uri != "http://ns.adobe.com/tiff/1.0/" {
+		t.Errorf("Incorrect Namespace URI wanted %s got %s", "http://ns.adobe.com/tiff/1.0/", uri)
+	}
+	if uri := UnknownNS.URI(); uri != "" {
+		t.Errorf("Incorrect Namespace URI wanted empty string got %s", uri)
+	}
+}